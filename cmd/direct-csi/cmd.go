@@ -21,13 +21,24 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	ctrl "github.com/minio/direct-csi/pkg/controller"
+	"github.com/minio/direct-csi/pkg/drive"
+	"github.com/minio/direct-csi/pkg/listener"
+	"github.com/minio/direct-csi/pkg/metrics"
+	"github.com/minio/direct-csi/pkg/node"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/loopback"
 	"github.com/minio/direct-csi/pkg/utils"
+	"github.com/minio/direct-csi/pkg/utils/grpc"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 var Version string
@@ -39,17 +50,107 @@ var (
 	rack                 = "default"
 	zone                 = "default"
 	region               = "default"
-	endpoint             = "unix://csi/csi.sock"
+	topologyNodeLabels   = []string{}
+	maxVolumesPerNode    = int64(0)
+	endpoint             = "unix:///csi/csi.sock"
 	kubeconfig           = ""
 	controller           = false
 	driver               = false
 	procfs               = "/proc"
+	mountInfoPath        = sys.MountInfoPath
+	mountRoot            = sys.MountRoot
 	conversionWebhook    = false
 	conversionWebhookURL = ""
 	loopBackOnly         = false
+	loopBackCount        = loopback.DefaultDeviceCount
+	includeDevices       = []string{}
+	excludeDevices       = []string{}
+	autoTier             = false
+	allowRemovable       = false
+	minDriveSize         = humanize.IBytes(sys.DefaultMinDriveSize)
 	showVersion          = false
+	logFormat            = utils.LogFormatText
+	provisioningStrategy = string(ctrl.ProvisioningStrategyMostFree)
+	previewDrives        = false
+	previewTrace         = false
+	healthPort           = 8081
+	// discoveryTimeout bounds a drive discovery cycle so that a stuck device cannot block it forever
+	discoveryTimeout = 5 * time.Minute
+	resyncPeriod     = listener.DefaultControllerTimings.ResyncPeriod
+	leaseDuration    = listener.DefaultControllerTimings.LeaseDuration
+	renewDeadline    = listener.DefaultControllerTimings.RenewDeadline
+	retryPeriod      = listener.DefaultControllerTimings.RetryPeriod
+	lockTimeout      = node.DefaultLockTimings.Timeout
+	lockPollInterval = node.DefaultLockTimings.PollInterval
+	// driveFinalizerGracePeriod is the duration after which the deleted drives of lost nodes are released
+	driveFinalizerGracePeriod = ctrl.DefaultDriveFinalizerGracePeriod
+	// smartInterval is the interval between the reads of the SMART health of the drives, 0 disables it
+	smartInterval = time.Duration(0)
+	// reprobeInterval is the interval between the re-probes of the devices of the drives, 0 disables it
+	reprobeInterval = time.Duration(0)
+	// maxConcurrentFormats is the number of drives formatted and mounted at once by the node
+	maxConcurrentFormats = drive.DefaultMaxConcurrentFormats
+	// kubeletDir is the root directory of the kubelet, the node server rejects the paths outside it
+	kubeletDir = node.DefaultKubeletDir
+	// metricsTokenFile is the file with the bearer token required from the scrapers of the metrics endpoint
+	metricsTokenFile = ""
+	// metricsTLSCert and metricsTLSKey serve the metrics endpoint over TLS
+	metricsTLSCert = ""
+	metricsTLSKey  = ""
+	// metricsClientCA is the CA bundle verifying the client certificates of the scrapers
+	metricsClientCA = ""
+	// metricsPVCLabels labels the volume stats by the claims of the volumes
+	metricsPVCLabels = false
+	// detachBusyMounts lazily detaches the mount of a released drive which stays busy
+	detachBusyMounts = false
 )
 
+// controllerTimings returns the timings of the drive and volume controllers set by the flags
+func controllerTimings() listener.ControllerTimings {
+	return listener.ControllerTimings{
+		ResyncPeriod:  viper.GetDuration("resync-period"),
+		LeaseDuration: viper.GetDuration("lease-duration"),
+		RenewDeadline: viper.GetDuration("renew-deadline"),
+		RetryPeriod:   viper.GetDuration("retry-period"),
+	}
+}
+
+func lockTimings() node.LockTimings {
+	return node.LockTimings{
+		Timeout:      viper.GetDuration("lock-timeout"),
+		PollInterval: viper.GetDuration("lock-poll-interval"),
+	}
+}
+
+func metricsConfig() metrics.ServerConfig {
+	return metrics.ServerConfig{
+		TokenFile:    viper.GetString("metrics-token-file"),
+		CertFile:     viper.GetString("metrics-tls-cert"),
+		KeyFile:      viper.GetString("metrics-tls-key"),
+		ClientCAFile: viper.GetString("metrics-client-ca"),
+		PVCLabels:    viper.GetBool("metrics-pvc-labels"),
+	}
+}
+
+// deviceFilter returns the device path globs and the minimum drive size set by the flags
+func deviceFilter() (sys.DeviceFilter, error) {
+	filter := sys.DeviceFilter{
+		Include: viper.GetStringSlice("include-devices"),
+		Exclude: viper.GetStringSlice("exclude-devices"),
+	}
+	// the loopback devices are smaller than any sensible minimum size
+	if !loopBackOnly {
+		minSize, err := humanize.ParseBytes(viper.GetString("min-drive-size"))
+		if err != nil {
+			return filter, fmt.Errorf("invalid --min-drive-size: %v", err)
+		}
+		filter.MinSize = minSize
+	}
+	return filter, filter.Validate()
+}
+
+var klogFlags = flag.NewFlagSet("klog", flag.ExitOnError)
+
 var driverCmd = &cobra.Command{
 	Use:   os.Args[0],
 	Short: "CSI driver for provisioning from JBOD(s) directly",
@@ -61,16 +162,70 @@ This driver is rack, region and zone aware i.e., a workload requesting volumes w
 For more information, use '%s man [sched | examples | ...]'
 `, os.Args[0]),
 	SilenceUsage: true,
-	PersistentPreRun: func(c *cobra.Command, args []string) {
-		utils.Init()
+	PersistentPreRunE: func(c *cobra.Command, args []string) error {
+		if err := utils.SetLogFormat(logFormat); err != nil {
+			return err
+		}
+		// the drive preview only probes the local drives
+		if !previewDrives {
+			utils.Init()
+		}
+		return nil
 	},
 	RunE: func(c *cobra.Command, args []string) error {
 		if showVersion {
 			fmt.Println(Version)
 			return nil
 		}
+		if c.Flags().Changed("loopback-count") && !loopBackOnly {
+			return fmt.Errorf("--loopback-count is only valid with --loopback-only")
+		}
+		if previewTrace && !previewDrives {
+			return fmt.Errorf("--preview-trace is only valid with --preview-drives")
+		}
+		if _, err := deviceFilter(); err != nil {
+			return err
+		}
+		if driveFinalizerGracePeriod < 0 {
+			return fmt.Errorf("--drive-finalizer-grace-period must not be negative")
+		}
+		if maxVolumesPerNode < 0 {
+			return fmt.Errorf("--max-volumes-per-node must not be negative")
+		}
+		if smartInterval < 0 {
+			return fmt.Errorf("--smart-interval must not be negative")
+		}
+		if reprobeInterval < 0 {
+			return fmt.Errorf("--reprobe-interval must not be negative")
+		}
+		if maxConcurrentFormats < 1 {
+			return fmt.Errorf("--max-concurrent-formats must be at least 1")
+		}
+		if !filepath.IsAbs(kubeletDir) {
+			return fmt.Errorf("--kubelet-dir must be an absolute path")
+		}
+		if err := metricsConfig().Validate(); err != nil {
+			return err
+		}
+		if _, _, err := grpc.ParseEndpoint(endpoint); err != nil {
+			return fmt.Errorf("invalid --endpoint: %v", err)
+		}
+		sys.MountInfoPath = mountInfoPath
+		if !filepath.IsAbs(mountRoot) {
+			return fmt.Errorf("--mount-root must be an absolute path")
+		}
+		sys.MountRoot = filepath.Clean(mountRoot)
+		if previewDrives {
+			return runDrivePreview(c.Context())
+		}
+		if err := controllerTimings().Validate(); err != nil {
+			return fmt.Errorf("invalid controller timings: %v", err)
+		}
+		if err := lockTimings().Validate(); err != nil {
+			return fmt.Errorf("invalid lock timings: %v", err)
+		}
 		if !controller && !driver && !conversionWebhook {
-			return fmt.Errorf("one among [--controller, --driver, --conversion-webhook] should be set")
+			return fmt.Errorf("one among [--controller, --driver, --conversion-webhook, --preview-drives] should be set")
 		}
 		return run(c.Context(), args)
 	},
@@ -84,29 +239,61 @@ func init() {
 	viper.AutomaticEnv()
 
 	flag.Set("alsologtostderr", "true")
-	kflags := flag.NewFlagSet("klog", flag.ExitOnError)
-	klog.InitFlags(kflags)
+	klog.InitFlags(klogFlags)
 
 	// parse the go default flagset to get flags for glog and other packages in future
 	driverCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
-	driverCmd.PersistentFlags().AddGoFlagSet(kflags)
+	driverCmd.PersistentFlags().AddGoFlagSet(klogFlags)
 	// defaulting this to true so that logs are printed to console
 	flag.Set("logtostderr", "true")
 
 	driverCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", kubeconfig, "path to kubeconfig")
+	driverCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "", logFormat, "format of the logs, should be one of text|json")
 	driverCmd.Flags().StringVarP(&identity, "identity", "i", identity, "identity of this direct-csi")
 	driverCmd.Flags().BoolVarP(&showVersion, "version", "", showVersion, "version of direct-csi")
-	driverCmd.Flags().StringVarP(&endpoint, "endpoint", "e", endpoint, "endpoint at which direct-csi is listening")
+	driverCmd.Flags().StringVarP(&endpoint, "endpoint", "e", endpoint, "endpoint at which direct-csi is listening, one of unix:///path, unix://@name, @name or tcp://host:port")
 	driverCmd.Flags().StringVarP(&nodeID, "node-id", "n", nodeID, "identity of the node in which direct-csi is running")
 	driverCmd.Flags().StringVarP(&rack, "rack", "", rack, "identity of the rack in which this direct-csi is running")
 	driverCmd.Flags().StringVarP(&zone, "zone", "", zone, "identity of the zone in which this direct-csi is running")
 	driverCmd.Flags().StringVarP(&region, "region", "", region, "identity of the region in which this direct-csi is running")
+	driverCmd.Flags().StringSliceVarP(&topologyNodeLabels, "topology-node-labels", "", topologyNodeLabels, "labels of the node added to the topology of the node and its drives, e.g. topology.kubernetes.io/zone")
+	driverCmd.Flags().Int64VarP(&maxVolumesPerNode, "max-volumes-per-node", "", maxVolumesPerNode, "maximum number of volumes the scheduler may assign to this node, 0 is unlimited")
 	driverCmd.Flags().StringVarP(&procfs, "procfs", "", procfs, "path to host /proc for accessing mount information")
+	driverCmd.Flags().StringVarP(&mountInfoPath, "mountinfo-path", "", mountInfoPath, "path to the mountinfo of the host mount namespace")
+	driverCmd.Flags().StringVarP(&mountRoot, "mount-root", "", mountRoot, "directory under which the drives are mounted")
 	driverCmd.Flags().BoolVarP(&controller, "controller", "", controller, "running in controller mode")
 	driverCmd.Flags().BoolVarP(&driver, "driver", "", driver, "run in driver mode")
 	driverCmd.Flags().BoolVarP(&conversionWebhook, "conversion-webhook", "", conversionWebhook, "start and serve conversion webhook")
 	driverCmd.Flags().StringVarP(&conversionWebhookURL, "conversion-webhook-url", "", conversionWebhookURL, "The URL of the conversion webhook")
 	driverCmd.Flags().BoolVarP(&loopBackOnly, "loopback-only", "", loopBackOnly, "Create and uses loopback devices only")
+	driverCmd.Flags().IntVarP(&loopBackCount, "loopback-count", "", loopBackCount, "number of loopback devices to create, used with --loopback-only")
+	driverCmd.Flags().StringSliceVarP(&includeDevices, "include-devices", "", includeDevices, "glob patterns of the device paths to be managed, e.g. /dev/sd[b-z]; all devices are managed if empty")
+	driverCmd.Flags().StringSliceVarP(&excludeDevices, "exclude-devices", "", excludeDevices, "glob patterns of the device paths to be ignored, e.g. /dev/nvme0n1*")
+	driverCmd.Flags().BoolVarP(&autoTier, "auto-tier", "", autoTier, "assign the access-tier of the new drives, hot for SSD/NVMe and cold for rotational drives")
+	driverCmd.Flags().BoolVarP(&allowRemovable, "allow-removable", "", allowRemovable, "manage the removable drives like USB sticks and SD cards, they are unavailable by default")
+	driverCmd.Flags().StringVarP(&minDriveSize, "min-drive-size", "", minDriveSize, "size below which the devices are not managed, e.g. 512MiB; ignored with --loopback-only")
+	driverCmd.Flags().StringVarP(&provisioningStrategy, "provisioning-strategy", "", provisioningStrategy, "strategy to select the drive for a volume, should be one of most-free|least-free|round-robin")
+	driverCmd.Flags().BoolVarP(&previewDrives, "preview-drives", "", previewDrives, "print the drives found on this node and whether they can be managed, without registering them")
+	driverCmd.Flags().BoolVarP(&previewTrace, "preview-trace", "", previewTrace, "record the attributes read and the checks applied to each drive in the preview, used with --preview-drives")
+	driverCmd.Flags().IntVarP(&healthPort, "health-port", "", healthPort, "port of the /healthz and /readyz endpoints of the node daemon")
+	driverCmd.Flags().DurationVarP(&discoveryTimeout, "discovery-timeout", "", discoveryTimeout, "maximum duration of a drive discovery cycle")
+	driverCmd.Flags().DurationVarP(&resyncPeriod, "resync-period", "", resyncPeriod, "resync period of the drive and volume controllers")
+	driverCmd.Flags().DurationVarP(&leaseDuration, "lease-duration", "", leaseDuration, "duration of the leader election lease of the drive and volume controllers")
+	driverCmd.Flags().DurationVarP(&renewDeadline, "renew-deadline", "", renewDeadline, "deadline for the leader to renew the lease, must be less than --lease-duration")
+	driverCmd.Flags().DurationVarP(&retryPeriod, "retry-period", "", retryPeriod, "interval between the leader election attempts, must be less than --renew-deadline")
+	driverCmd.Flags().DurationVarP(&lockTimeout, "lock-timeout", "", lockTimeout, "maximum duration a volume publish or unpublish waits for an in-flight operation on the same target path")
+	driverCmd.Flags().DurationVarP(&lockPollInterval, "lock-poll-interval", "", lockPollInterval, "interval between the attempts to lock the target path of a volume publish or unpublish")
+	driverCmd.Flags().DurationVarP(&driveFinalizerGracePeriod, "drive-finalizer-grace-period", "", driveFinalizerGracePeriod, "duration after which the controller removes the finalizers of a deleted drive whose node is deleted or not ready, 0 disables it")
+	driverCmd.Flags().DurationVarP(&smartInterval, "smart-interval", "", smartInterval, "interval between the reads of the SMART health of the drives by smartctl, 0 disables it")
+	driverCmd.Flags().DurationVarP(&reprobeInterval, "reprobe-interval", "", reprobeInterval, "interval between the re-probes of the devices of the drives in sysfs, 0 disables it")
+	driverCmd.Flags().IntVarP(&maxConcurrentFormats, "max-concurrent-formats", "", maxConcurrentFormats, "maximum number of drives formatted and mounted at once in the node")
+	driverCmd.Flags().StringVarP(&kubeletDir, "kubelet-dir", "", kubeletDir, "root directory of the kubelet, the staging and target paths of the volumes must be within it")
+	driverCmd.Flags().StringVarP(&metricsTokenFile, "metrics-token-file", "", metricsTokenFile, "file with the bearer token the scrapers of the metrics endpoint must present")
+	driverCmd.Flags().StringVarP(&metricsTLSCert, "metrics-tls-cert", "", metricsTLSCert, "certificate to serve the metrics endpoint over TLS")
+	driverCmd.Flags().StringVarP(&metricsTLSKey, "metrics-tls-key", "", metricsTLSKey, "private key of --metrics-tls-cert")
+	driverCmd.Flags().StringVarP(&metricsClientCA, "metrics-client-ca", "", metricsClientCA, "CA bundle to verify the client certificates of the scrapers of the metrics endpoint, requires --metrics-tls-cert")
+	driverCmd.Flags().BoolVarP(&metricsPVCLabels, "metrics-pvc-labels", "", metricsPVCLabels, "label the volume stats by the name and namespace of the claims of the volumes, adds a series per claim")
+	driverCmd.Flags().BoolVarP(&detachBusyMounts, "detach-busy-mounts", "", detachBusyMounts, "lazily detach the mount of a released drive if it stays busy, the release fails while it is busy otherwise")
 
 	driverCmd.PersistentFlags().MarkHidden("alsologtostderr")
 	driverCmd.PersistentFlags().MarkHidden("log_backtrace_at")
@@ -119,6 +306,9 @@ func init() {
 	// suppress the incorrect prefix in glog output
 	flag.CommandLine.Parse([]string{})
 	viper.BindPFlags(driverCmd.PersistentFlags())
+	for _, name := range []string{"resync-period", "lease-duration", "renew-deadline", "retry-period", "lock-timeout", "lock-poll-interval", "include-devices", "exclude-devices", "min-drive-size", "metrics-token-file", "metrics-tls-cert", "metrics-tls-key", "metrics-client-ca", "metrics-pvc-labels"} {
+		viper.BindPFlag(name, driverCmd.Flags().Lookup(name))
+	}
 }
 
 func Execute(ctx context.Context) error {
@@ -0,0 +1,48 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/minio/direct-csi/pkg/node/discovery"
+)
+
+// runDrivePreview prints the drives found on this node as JSON, no drive objects are created
+func runDrivePreview(ctx context.Context) error {
+	filter, err := deviceFilter()
+	if err != nil {
+		return err
+	}
+
+	discoveryCtx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	previews, err := discovery.Preview(discoveryCtx, loopBackOnly, loopBackCount, filter, allowRemovable, previewTrace)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("Drive discovery did not finish within %v: %v", discoveryTimeout, err)
+		}
+		return fmt.Errorf("Error while previewing the drives: %v", err)
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(previews)
+}
@@ -24,18 +24,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	ctrl "github.com/minio/direct-csi/pkg/controller"
 	"github.com/minio/direct-csi/pkg/converter"
+	"github.com/minio/direct-csi/pkg/drive"
+	"github.com/minio/direct-csi/pkg/health"
 	id "github.com/minio/direct-csi/pkg/identity"
 	"github.com/minio/direct-csi/pkg/node"
 	"github.com/minio/direct-csi/pkg/node/discovery"
+	"github.com/minio/direct-csi/pkg/topology"
+	"github.com/minio/direct-csi/pkg/utils"
 	"github.com/minio/direct-csi/pkg/utils/grpc"
 	"github.com/minio/direct-csi/pkg/volume"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -87,7 +92,7 @@ func run(ctx context.Context, args []string) error {
 
 	if conversionWebhook {
 		// Start conversion webserver
-		if err := converter.ServeConversionWebhook(ctx); err != nil {
+		if err := converter.ServeConversionWebhook(ctx, Version); err != nil {
 			return err
 		}
 		// Do not start node server and central controller in conversion mode
@@ -106,11 +111,40 @@ func run(ctx context.Context, args []string) error {
 
 	var nodeSrv csi.NodeServer
 	if driver {
-		discovery, err := discovery.NewDiscovery(ctx, identity, nodeID, rack, zone, region)
+		// the node is ready once the drives are discovered and the volumes are synced
+		var discovered int32
+		health.RegisterReadinessCheck("discovery", func() error {
+			if atomic.LoadInt32(&discovered) == 0 {
+				return errors.New("drive discovery is in progress")
+			}
+			return nil
+		})
+		go func() {
+			if err := health.ServeHealth(ctx, healthPort); err != nil {
+				klog.Errorf("Failed to serve the health endpoints: %v", err)
+			}
+		}()
+
+		nodeTopology, err := topology.NodeLabelTopology(ctx, utils.GetKubeClient(), nodeID, topologyNodeLabels)
+		if err != nil {
+			return fmt.Errorf("Error while reading the topology labels of node %s: %v", nodeID, err)
+		}
+
+		discovery, err := discovery.NewDiscovery(ctx, identity, nodeID, rack, zone, region, nodeTopology, autoTier, allowRemovable)
+		if err != nil {
+			return err
+		}
+		filter, err := deviceFilter()
 		if err != nil {
 			return err
 		}
-		if err := discovery.Init(ctx, loopBackOnly); err != nil {
+		discoveryCtx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+		err = discovery.Init(discoveryCtx, loopBackOnly, loopBackCount, filter)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("Drive discovery did not finish within %v: %v", discoveryTimeout, err)
+			}
 			return fmt.Errorf("Error while initializing drive discovery: %v", err)
 		}
 		klog.V(5).Infof("Drive discovery finished")
@@ -118,17 +152,25 @@ func run(ctx context.Context, args []string) error {
 		// Check if the volume objects are migrated and CRDs versions are in-sync
 		volume.SyncVolumes(ctx, nodeID)
 		klog.V(5).Infof("Volumes sync completed")
+		atomic.StoreInt32(&discovered, 1)
 
-		nodeSrv, err = node.NewNodeServer(ctx, identity, nodeID, rack, zone, region)
+		nodeSrv, err = node.NewNodeServer(ctx, identity, nodeID, rack, zone, region, nodeTopology, maxVolumesPerNode, maxConcurrentFormats, detachBusyMounts, kubeletDir, metricsConfig(), controllerTimings(), lockTimings())
 		if err != nil {
 			return err
 		}
 		klog.V(5).Infof("node server started")
+
+		if smartInterval > 0 {
+			go drive.StartSMARTCollector(ctx, nodeID, smartInterval)
+		}
+		if reprobeInterval > 0 {
+			go discovery.StartReprobe(ctx, reprobeInterval, discoveryTimeout, loopBackOnly)
+		}
 	}
 
 	var ctrlServer csi.ControllerServer
 	if controller {
-		ctrlServer, err = ctrl.NewControllerServer(ctx, identity, nodeID, rack, zone, region)
+		ctrlServer, err = ctrl.NewControllerServer(ctx, identity, nodeID, rack, zone, region, ctrl.ProvisioningStrategy(provisioningStrategy), driveFinalizerGracePeriod)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,185 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "migrate the stored drives and volumes to the latest version",
+	Long:  "",
+	Example: `
+ # Rewrite all the drives and volumes in the latest version
+ $ kubectl direct-csi admin migrate
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return migrateObjects(c.Context())
+	},
+}
+
+// migrationFailure - an object which could not be migrated
+type migrationFailure struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// migrationSummary - outcome of migrating the objects of a kind
+type migrationSummary struct {
+	Kind     string             `json:"kind"`
+	Migrated int                `json:"migrated"`
+	Failed   []migrationFailure `json:"failed,omitempty"`
+}
+
+func (s *migrationSummary) record(name string, err error) {
+	if err != nil {
+		klog.V(3).Infof("Error while migrating %s %s: %v", s.Kind, name, err)
+		s.Failed = append(s.Failed, migrationFailure{Name: name, Reason: err.Error()})
+		return
+	}
+	s.Migrated++
+}
+
+// migrateDrives rewrites every drive so that it is stored in the latest version
+func migrateDrives(ctx context.Context) (migrationSummary, error) {
+	summary := migrationSummary{Kind: "DirectCSIDrive"}
+	driveClient := utils.GetDirectCSIClient().DirectCSIDrives()
+	driveList, err := driveClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return summary, err
+	}
+	for _, drive := range driveList.Items {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			d, err := driveClient.Get(ctx, drive.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = driveClient.Update(ctx, d, metav1.UpdateOptions{})
+			return err
+		})
+		summary.record(drive.Name, err)
+	}
+	return summary, nil
+}
+
+// migrateVolumes rewrites every volume so that it is stored in the latest version
+func migrateVolumes(ctx context.Context) (migrationSummary, error) {
+	summary := migrationSummary{Kind: "DirectCSIVolume"}
+	volumeClient := utils.GetDirectCSIClient().DirectCSIVolumes()
+	volumeList, err := volumeClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return summary, err
+	}
+	for _, volume := range volumeList.Items {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			v, err := volumeClient.Get(ctx, volume.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			_, err = volumeClient.Update(ctx, v, metav1.UpdateOptions{})
+			return err
+		})
+		summary.record(volume.Name, err)
+	}
+	return summary, nil
+}
+
+func printMigrationSummary(summaries []migrationSummary) error {
+	if yaml || json {
+		if err := printer(summaries); err != nil {
+			klog.ErrorS(err, "error marshaling migration summary", "format", outputMode)
+			return err
+		}
+		return nil
+	}
+
+	text.DisableColors()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{
+		"KIND",
+		"MIGRATED",
+		"FAILED",
+	})
+
+	style := table.StyleColoredDark
+	style.Color.IndexColumn = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	style.Color.Header = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	t.SetStyle(style)
+
+	for _, summary := range summaries {
+		t.AppendRow([]interface{}{
+			summary.Kind,
+			summary.Migrated,
+			len(summary.Failed),
+		})
+	}
+	t.Render()
+
+	for _, summary := range summaries {
+		for _, failure := range summary.Failed {
+			fmt.Printf("%s %s %s: %s\n", red("FAILED"), summary.Kind, bold(failure.Name), failure.Reason)
+		}
+	}
+	return nil
+}
+
+// migrateObjects migrates all the drives and volumes, reports the outcome and
+// fails if any of the objects could not be migrated
+func migrateObjects(ctx context.Context) error {
+	summaries := []migrationSummary{}
+	for _, migrate := range []func(context.Context) (migrationSummary, error){migrateDrives, migrateVolumes} {
+		summary, err := migrate(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to list %s objects for migration: %v", summary.Kind, err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := printMigrationSummary(summaries); err != nil {
+		return err
+	}
+
+	failed := []string{}
+	for _, summary := range summaries {
+		for _, failure := range summary.Failed {
+			failed = append(failed, summary.Kind+"/"+failure.Name)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%d object(s) failed to migrate: [%s]; re-run '%s' after fixing them", len(failed), strings.Join(failed, ", "), utils.Bold("kubectl direct-csi admin migrate"))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/minio/direct-csi/pkg/utils"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestMigrateDrives(t1 *testing.T) {
+	createTestDrive := func(name string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		failedDrives     []string
+		expectedMigrated int
+	}{
+		{
+			name:             "test1",
+			expectedMigrated: 3,
+		},
+		{
+			name:             "test2",
+			failedDrives:     []string{"drive-2"},
+			expectedMigrated: 2,
+		},
+		{
+			name:             "test3",
+			failedDrives:     []string{"drive-1", "drive-2", "drive-3"},
+			expectedMigrated: 0,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			testClientSet := fakedirect.NewSimpleClientset([]runtime.Object{
+				createTestDrive("drive-1"),
+				createTestDrive("drive-2"),
+				createTestDrive("drive-3"),
+			}...)
+			testClientSet.PrependReactor("update", "directcsidrives", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				drive := action.(clienttesting.UpdateAction).GetObject().(*directcsi.DirectCSIDrive)
+				for _, name := range tt.failedDrives {
+					if drive.Name == name {
+						return true, nil, errors.New("conversion failed")
+					}
+				}
+				return false, nil, nil
+			})
+			utils.SetFakeDirectCSIClient(testClientSet.DirectV1beta2())
+
+			summary, err := migrateDrives(context.Background())
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if summary.Migrated != tt.expectedMigrated {
+				t1.Errorf("Test case name %s: expected %d migrated drives but got %d", tt.name, tt.expectedMigrated, summary.Migrated)
+			}
+			if len(summary.Failed) != len(tt.failedDrives) {
+				t1.Fatalf("Test case name %s: expected %d failed drives but got %d", tt.name, len(tt.failedDrives), len(summary.Failed))
+			}
+			failed := map[string]string{}
+			for _, failure := range summary.Failed {
+				failed[failure.Name] = failure.Reason
+			}
+			for _, name := range tt.failedDrives {
+				if reason, ok := failed[name]; !ok || reason != "conversion failed" {
+					t1.Errorf("Test case name %s: expected drive %s to fail with 'conversion failed' but got '%s'", tt.name, name, reason)
+				}
+			}
+		})
+	}
+}
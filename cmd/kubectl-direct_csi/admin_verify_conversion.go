@@ -0,0 +1,124 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/converter"
+	"github.com/minio/direct-csi/pkg/installer"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var verifyConversionCmd = &cobra.Command{
+	Use:   "verify-conversion",
+	Short: "verify that the stored CRD versions migrate to the latest version without loss",
+	Long: `
+The conversion of the stored versions is verified locally. If the CRDs are installed,
+the running conversion webhook is also checked to support the stored versions and the
+latest version, so that it matches the installed CRDs before migrating the objects.`,
+	Example: `
+ # Verify the conversion of all the versions stored in the cluster
+ $ kubectl direct-csi admin verify-conversion
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return verifyConversion(c.Context(), args)
+	},
+}
+
+// getStoredVersions returns the versions persisted for the DirectCSI CRDs,
+// falling back to all the known versions if the CRDs are not installed
+func getStoredVersions(ctx context.Context) ([]string, error) {
+	versionSet := map[string]struct{}{}
+	crdClient := utils.GetCRDClient()
+	for _, crdName := range []string{driveCRDName, volumeCRDName} {
+		crd, err := crdClient.Get(ctx, crdName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				klog.V(3).Infof("crd %s not found, verifying all the known versions", crdName)
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, version := range crd.Status.StoredVersions {
+			versionSet[directcsi.Group+"/"+version] = struct{}{}
+		}
+	}
+
+	versions := []string{}
+	for version := range versionSet {
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// verifyConversionWebhook checks that the running conversion webhook converts the stored versions to the latest version
+func verifyConversionWebhook(ctx context.Context, versions []string) error {
+	webhookVersion, err := installer.GetConversionWebhookVersion(ctx, identity)
+	if err != nil {
+		return fmt.Errorf("unable to get the version of the conversion webhook: %v", err)
+	}
+	if err := webhookVersion.CheckCompatibility(versions); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s: version %s, [%s]\n", green("OK"), bold("conversion webhook"), webhookVersion.Version, strings.Join(webhookVersion.GroupVersions, ", "))
+	return nil
+}
+
+func verifyConversion(ctx context.Context, args []string) error {
+	versions, err := getStoredVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	if versions != nil {
+		if err := verifyConversionWebhook(ctx, versions); err != nil {
+			failed = true
+			fmt.Printf("%s %s: %v\n", red("FAILED"), bold("conversion webhook"), err)
+		}
+	}
+	for _, result := range converter.VerifyConversion(versions...) {
+		header := fmt.Sprintf("%s %s -> %s", result.Kind, result.FromVersion, result.ToVersion)
+		switch {
+		case result.Err != nil:
+			failed = true
+			fmt.Printf("%s %s: %v\n", red("FAILED"), bold(header), result.Err)
+		case len(result.LostFields) > 0:
+			failed = true
+			fmt.Printf("%s %s: lost fields [%s]\n", red("FAILED"), bold(header), strings.Join(result.LostFields, ", "))
+		default:
+			fmt.Printf("%s %s\n", green("OK"), bold(header))
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("conversion verification failed")
+	}
+	return nil
+}
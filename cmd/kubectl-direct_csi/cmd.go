@@ -18,7 +18,6 @@ package main
 
 import (
 	"context"
-	"errors"
 	"flag"
 
 	"github.com/spf13/cobra"
@@ -33,26 +32,34 @@ var Version string
 
 // flags
 var (
-	kubeconfig = ""
-	identity   = "direct.csi.min.io"
-	dryRun     = false
+	kubeconfig  = ""
+	kubeContext = ""
+	identity    = "direct.csi.min.io"
+	dryRun      = false
 	//output modes
 	outputMode = ""
 	wide       = false
 	json       = false
 	yaml       = false
+	logFormat  = utils.LogFormatText
 )
 
+var klogFlags = flag.NewFlagSet("klog", flag.ExitOnError)
+
 var printer func(interface{}) error
 var threadiness chan struct{}
 
 var pluginCmd = &cobra.Command{
-	Use:           "direct-csi",
-	Short:         "Plugin for managing Direct CSI drives and volumes",
-	SilenceUsage:  true,
-	SilenceErrors: false,
+	Use:          "direct-csi",
+	Short:        "Plugin for managing Direct CSI drives and volumes",
+	SilenceUsage: true,
+	// the errors are printed by printError along with the exit code
+	SilenceErrors: true,
 	Version:       Version,
 	PersistentPreRunE: func(c *cobra.Command, args []string) error {
+		if err := utils.SetLogFormat(logFormat); err != nil {
+			return err
+		}
 		utils.Init()
 
 		switch outputMode {
@@ -64,7 +71,7 @@ var pluginCmd = &cobra.Command{
 		case "json":
 			json = true
 		default:
-			return errors.New("output should be one of wide|json|yaml or empty")
+			return newUsageError("output should be one of wide|json|yaml or empty")
 		}
 
 		printer = printYAML
@@ -83,20 +90,21 @@ func init() {
 
 	viper.AutomaticEnv()
 
-	kflags := flag.NewFlagSet("klog", flag.ExitOnError)
-	klog.InitFlags(kflags)
+	klog.InitFlags(klogFlags)
 
 	// parse the go default flagset to get flags for glog and other packages in future
 	pluginCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
-	pluginCmd.PersistentFlags().AddGoFlagSet(kflags)
+	pluginCmd.PersistentFlags().AddGoFlagSet(klogFlags)
 
 	flag.Set("logtostderr", "true")
 	flag.Set("alsologtostderr", "true")
 
 	pluginCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", kubeconfig, "path to kubeconfig")
+	pluginCmd.PersistentFlags().StringVarP(&kubeContext, "context", "", kubeContext, "name of the kubeconfig context to use")
 	pluginCmd.PersistentFlags().StringVarP(&outputMode, "output", "o", outputMode,
 		"output format should be one of wide|json|yaml or empty")
 	pluginCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", dryRun, "prints the installation yaml")
+	pluginCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "", logFormat, "format of the logs, should be one of text|json")
 
 	pluginCmd.PersistentFlags().MarkHidden("alsologtostderr")
 	pluginCmd.PersistentFlags().MarkHidden("log_backtrace_at")
@@ -110,11 +118,18 @@ func init() {
 	flag.CommandLine.Parse([]string{})
 	viper.BindPFlags(pluginCmd.PersistentFlags())
 
+	pluginCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+
 	pluginCmd.AddCommand(infoCmd)
 	pluginCmd.AddCommand(installCmd)
 	pluginCmd.AddCommand(uninstallCmd)
 	pluginCmd.AddCommand(drivesCmd)
 	pluginCmd.AddCommand(volumesCmd)
+	pluginCmd.AddCommand(nodesCmd)
+	pluginCmd.AddCommand(adminCmd)
+	pluginCmd.AddCommand(eventsCmd)
 	//pluginCmd.AddCommand(newVolumesCmd())
 
 	threadiness = make(chan struct{}, utils.MaxThreadCount)
@@ -41,7 +41,7 @@ func bindata_read(data []byte, name string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-var _go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x5c\x6d\x6f\xdc\xb8\xf1\x7f\xbf\x9f\x62\xb0\xff\x3f\x10\x3b\x5d\xc9\xb1\x53\xa4\x77\x0b\x04\x41\xea\xd4\x85\x91\x4b\xce\x88\x9d\xbc\xa8\xd7\xed\xcd\x4a\xb3\x5a\xc6\x12\xa9\x23\x29\xdb\x9b\xa2\xdf\xbd\x18\x52\xda\x47\x49\x7e\x68\x73\x40\x0f\xe4\xab\x15\x1f\x86\xc3\x79\x26\x7f\xc0\x0e\xa2\x28\x1a\x60\x29\xbe\x90\x36\x42\xc9\x31\x60\x29\xe8\xce\x92\xe4\x2f\x13\x5f\xff\x60\x62\xa1\x0e\x6e\x0e\x07\xd7\x42\xa6\x63\x38\xae\x8c\x55\xc5\x27\x32\xaa\xd2\x09\xbd\xa3\x99\x90\xc2\x0a\x25\x07\x05\x59\x4c\xd1\xe2\x78\x00\x80\x52\x2a\x8b\xdc\x6d\xf8\x13\x20\x51\xd2\x6a\x95\xe7\xa4\xa3\x8c\x64\x7c\x5d\x4d\x69\x5a\x89\x3c\x25\xed\x88\x37\x5b\xdf\xbc\x88\x5f\xc5\x87\x03\x80\x44\x93\x5b\x7e\x21\x0a\x32\x16\x8b\x72\x0c\xb2\xca\xf3\x01\x80\xc4\x82\xc6\x90\x0a\x4d\x89\x4d\x8c\x48\xb5\xb8\x21\x13\xfb\xef\x38\x31\x22\x2e\x84\x8c\x85\x1a\x98\x92\x12\xde\x3b\xd3\xaa\x2a\x9b\x05\xeb\x13\x3c\xa9\x9a\x3f\x7f\xb6\x77\x6e\xd2\xf1\xf9\xe9\x3b\xa6\xea\x06\x72\x61\xec\xfb\x96\xc1\x9f\x84\xb1\x6e\x42\x99\x57\x1a\xf3\x1d\x8e\xdc\x98\x11\x32\xab\x72\xd4\xdb\xa3\x03\x00\x93\xa8\x92\xc6\x70\x9c\x57\xc6\x92\x1e\x00\xd4\x32\x70\xfc\x44\xf5\x29\x6f\x0e\x31\x2f\xe7\x78\xe8\x89\x25\x73\x2a\xd0\xb3\x0b\xa0\x4a\x92\x6f\xcf\x4e\xbf\xbc\x3c\xdf\xe8\x06\x28\xb5\x2a\x49\x5b\xd1\x9c\xcc\xb7\x35\xfd\xae\xf5\x02\xa4\x64\x12\x2d\x4a\xeb\xa4\xff\x8c\x09\xfa\x59\x90\xb2\x62\xc9\x80\x9d\x53\xc3\x1a\xa5\x35\x0f\xa0\x66\x60\xe7\xc2\x80\xa6\x52\x93\x21\xe9\x55\xbd\x41\x18\x78\x12\x4a\x50\xd3\xaf\x2c\x77\x38\x27\xcd\x64\xc0\xcc\x55\x95\xa7\x6c\x0f\x37\xa4\x2d\x68\x4a\x54\x26\xc5\xb7\x25\x6d\x03\x56\xb9\x4d\x73\xb4\x54\x8b\x78\xd5\x84\xb4\xa4\x25\xe6\x70\x83\x79\x45\x23\x40\x99\x42\x81\x0b\xd0\xc4\xbb\x40\x25\xd7\xe8\xb9\x29\x26\x86\x0f\x4a\x13\x08\x39\x53\x63\x98\x5b\x5b\x9a\xf1\xc1\x41\x26\x6c\x63\xd7\x89\x2a\x8a\x4a\x0a\xbb\x38\x70\x26\x2a\xa6\x95\x55\xda\x1c\xa4\x74\x43\xf9\x81\x11\x59\x84\x3a\x99\x0b\x4b\x89\xad\x34\x1d\x60\x29\x22\xc7\xba\x74\xb6\x1d\x17\xe9\xff\xe9\xda\x13\xcc\xb3\x0d\x5e\xed\x82\xd5\x6b\xac\x16\x32\x5b\x1b\x70\x76\xd6\xa3\x01\x36\x35\x10\x06\xb0\x5e\xea\x4f\xb1\x12\x34\x77\xb1\x74\x3e\xfd\xe5\xfc\x02\x9a\xad\x9d\x32\xb6\xa5\xef\xe4\xbe\x5a\x68\x56\x2a\x60\x81\x09\x39\x23\xed\x95\x38\xd3\xaa\x70\x34\x49\xa6\xa5\x12\xd2\xba\x8f\x24\x17\x24\xb7\xc5\x6f\xaa\x69\x21\x2c\xeb\xfd\xd7\x8a\x8c\x65\x5d\xc5\x70\xec\x9c\x1d\xa6\x04\x55\x99\xa2\xa5\x34\x86\x53\x09\xc7\x58\x50\x7e\x8c\x86\xbe\xbb\x02\x58\xd2\x26\x62\xc1\x3e\x4c\x05\xeb\x71\x6a\x7b\xb2\x97\xda\xda\x40\x13\x45\x56\xad\xdd\xbf\x9c\x26\x9b\x00\xf1\xf3\xad\xa4\x74\x7b\x74\x4b\xd3\x2c\x42\xa1\x29\xdd\x99\xe5\x19\x99\x2a\x95\x13\x6e\xbb\x94\x0b\x1e\x17\x28\xa4\xdd\xa5\x8e\x69\xea\xe2\x30\xe6\x67\x9d\x1c\xf6\x48\xa5\x57\x0a\xdc\x6a\x9d\x53\x7a\xa2\x74\x81\x2d\x0c\x74\x0b\x86\xdb\x4c\xe4\x64\x16\xc6\x52\xd1\x36\x7a\x0f\x5b\x00\x33\xa5\x13\xea\x5b\xd9\x2e\x30\x6e\x85\xaa\xa4\xfd\xb9\x5c\x4b\x46\xdb\x4d\x58\x2a\x3a\x86\xee\x65\xac\x99\x80\x5a\xe3\xa2\x75\xfc\x2e\xe2\x6c\xa7\x25\x59\x32\x11\xa7\x93\xa8\x5e\x61\x55\x21\x92\x2e\x86\x9d\x27\x3e\x49\x54\x65\xa5\xb3\x27\x89\xaa\x53\xf9\x8d\xad\x6e\x12\x8d\xb6\x0c\xfe\x41\xee\x64\xd1\x56\xe6\xa1\x0e\x85\x79\xae\x12\x8e\x28\xc7\x58\x62\x22\xec\x62\xf7\x54\x33\x6f\x8c\x9c\x18\x5e\xfd\xb1\xe3\x44\x9c\x34\x32\x97\x63\xd7\x5b\xa2\xa4\x77\x98\x16\xcd\x77\x1a\xc4\x86\x0b\x0f\x8f\x1b\x12\xae\xbc\x41\x21\x0d\xa4\x64\x51\xe4\x86\xf9\x02\x25\x09\x90\x03\x88\xf5\x09\x93\x20\xa9\xb4\xde\x8d\xaa\x2b\xd1\xd0\x32\xb3\xbe\x3d\x3b\x85\xa6\xc6\x8a\x21\x8a\x22\xb8\xe0\x6e\x63\x75\x95\x58\x4e\x10\x7c\x28\x99\x52\xea\x76\xf2\x8a\x68\x25\x5b\x19\x66\x82\x33\xb1\xb3\x50\x40\x1f\xde\x67\x82\xf2\x14\x4a\xb4\x73\x88\xbd\x52\xe2\x95\x40\x62\x80\x13\xa5\x81\xee\xb0\x28\x73\x1a\x75\x9a\x12\x9c\x28\x75\xee\x16\xd7\x8c\xfd\xd3\x0d\x1d\x1c\xc0\xa7\x65\xda\x71\xbb\xa9\xa9\x21\x7d\xe3\xeb\x41\x57\x17\xb4\x92\x9c\x29\xf5\xcc\x34\x32\xf2\xf2\x88\x1b\x82\xef\xa5\xba\x95\x6d\xac\x3a\x3e\x50\x77\x18\xfc\x64\xf8\xf6\x06\x45\x8e\xd3\x9c\x26\xc3\x11\x4c\x86\x67\x5a\x65\x9a\x0c\x17\x66\xdc\xc1\xf5\xc3\x64\xf8\x8e\x32\x8d\x29\xa5\x93\x61\xb3\xdd\x1f\x4a\xb4\xc9\xfc\x03\xe9\x8c\xde\xd3\xe2\x35\x6f\xd2\x4e\x7f\x63\xfe\xb9\xd5\x68\x29\x5b\xbc\x2e\x78\xe1\x92\x16\xfb\xfc\xc5\xa2\xa4\xd7\x05\x96\x1b\x9d\x1f\xb0\xbc\x9f\xfa\xd2\xc8\x0c\x5c\x5e\x71\xee\xba\x39\x8c\x57\x86\xf7\xcb\x57\xa3\xe4\x78\x32\x5c\x49\x64\xa4\x0a\x36\xdf\xd2\x2e\x26\xc3\x56\xaa\x1b\xac\x8e\x27\x43\xc7\xec\x64\x08\x1b\x47\x1e\x4f\x86\xcc\x16\x77\x6b\x65\xd5\xb4\x9a\x8d\x27\xc3\xe9\xc2\x92\x19\x1d\x8e\x34\x95\x23\x2e\x50\x5f\xaf\x76\x9d\x0c\x7f\x69\x3f\x82\x6c\x4e\xac\xec\x9c\xb4\xb7\x3b\x03\xff\x6a\x63\xad\x3f\x81\x00\xe4\x68\xec\x85\x46\x69\x44\x73\x33\xe8\x8a\xd9\x1b\x6e\xba\xbb\x8c\xfd\xc7\x97\x98\xc6\x82\xe5\x0e\xe7\x9c\xcd\x61\x3a\x88\x02\xd8\x25\x15\xf6\x3b\x2e\x9b\xd8\xc5\xbd\x4d\x72\xd9\x8a\xd2\x1d\x32\xae\x7d\xd5\x57\xba\x53\x82\xdb\x39\xf5\x10\x9d\x13\x54\x32\x25\x9d\x2f\xb8\xb8\x4b\x56\x31\x65\x8e\x32\xe3\x6a\x0a\x4e\x39\x28\xa0\x73\x7b\xae\xb4\xae\xd9\x17\x46\xbc\xb0\x9b\x6a\x65\x9a\x4a\xd1\x9d\x8f\x39\x70\x5f\x1c\x57\xbc\xef\xd7\xe4\x5d\xb1\x99\x24\x54\x5a\x76\x92\xb8\x83\x60\x13\x66\xb9\xbe\x8b\x98\xe2\x53\x93\x65\x41\xc6\x60\x57\x7a\xda\x52\x5c\x3d\xd7\x97\xc3\xf3\xaa\x40\x09\x9a\x30\x65\x3e\x57\x63\x32\x15\x09\xda\xae\xed\x3c\x4d\x1f\x92\x71\xaa\x2a\x1f\xfc\x56\x7a\xac\x55\xc5\x15\xf1\x94\x38\x48\x3a\xc7\xa9\x0f\xd0\x25\x8c\x02\xef\x7e\x22\x99\xd9\xf9\x18\x5e\x1e\xfd\xe9\xd5\x0f\x4f\x95\x85\x8f\x8a\x94\xfe\x95\x24\x69\x17\x1c\x1f\x24\x96\xdd\x65\x6b\x55\xbe\x3b\x5f\xdc\x94\xb8\x71\xb6\x9c\xd3\x63\x7f\x75\x4a\x58\x59\xde\x2d\x1a\x30\x64\x61\x8a\x86\x52\xa8\x4a\x96\x13\x27\x04\x21\x8d\x45\x99\xd0\x08\xc4\xec\x71\x9b\x88\x65\x5c\xcf\x17\x70\x78\x34\x82\x69\xad\x8a\xdd\x88\x7e\x79\x77\x15\xef\x1e\xb1\x8f\xf2\x8f\xa3\x2d\xfe\x85\x01\x56\xb5\x9a\x39\x7b\x85\x5b\x61\xe7\x7c\x57\x72\x99\xb8\xbe\x5d\xf6\x65\x62\xd8\xcc\xc6\xb4\x3c\xf7\x7d\xde\xd1\x5e\x84\xf8\x56\x08\x29\x8a\xaa\x18\xc3\x8b\x5e\x73\x69\xaf\x55\x7c\xd3\x84\xe6\x81\x36\xe2\xa7\xae\xca\x12\xe4\xe0\x9a\x69\x2c\x0a\xb4\x22\x01\x91\xf2\xfd\x69\x26\x48\x3f\xc4\x81\x58\x04\x35\x41\x2e\x36\x36\x64\xfd\xcc\xd4\x51\x74\xcd\xa5\xce\xb4\x4a\xab\x84\xf4\xf6\x95\x74\xd5\xd4\xcc\x5d\xac\xc4\x4c\x24\x6b\x6a\x73\x17\x39\xe7\x8b\xfe\xf1\x01\xe8\x8e\x55\xb6\xbc\xca\x73\xb6\xee\x24\x59\x10\x4a\x21\x33\x53\xb3\xc8\xf7\x5a\x0e\x73\x3e\xc5\xdf\xce\xc9\x65\x1f\xf7\x98\x51\xd3\xd2\xee\x14\x46\xa4\xd4\x76\x0b\x6b\x1a\x42\x56\xa1\x46\x69\x89\x52\x0e\x9e\x1c\x30\x6a\x1a\x6b\x01\x1e\x57\xd7\xdd\x7b\x62\x07\xf8\x80\xe3\x43\x30\x1f\xb5\xbe\x3a\xbb\xb8\xf3\x80\x80\x73\xf8\xe2\xa8\xc7\xc2\x96\xb3\x3a\xa6\x94\x68\x2d\x69\x39\x86\xbf\x5f\xbe\x8d\xfe\x86\xd1\xb7\xab\xbd\xfa\xc7\x8b\xe8\xc7\x7f\x8c\xc6\x57\xcf\xd7\x3e\xaf\xf6\xdf\xfc\xff\x53\x43\x5b\x5b\x9d\xbf\x6a\x1b\xa6\x5a\xa7\xcf\xa6\x42\x6e\xac\x61\xe4\x72\xab\x9a\xc1\x85\xae\x68\x04\x27\x98\x1b\x1a\xc1\x67\xe9\x92\x5f\x97\xa0\x48\x56\x1d\xd7\x4b\xbe\xae\x0c\x99\x54\x7b\x4d\xe4\x86\xdd\x1e\xdd\xe3\xf5\xde\xff\xd1\x35\xf1\x21\x02\x71\x15\xad\x9a\xad\xc7\xb3\xb5\xe7\x14\x70\x71\x98\x6b\xe5\xb8\xae\xcf\xe3\x44\x15\x07\xab\xe7\x96\x4e\xc3\xe3\x4b\xc4\x07\x94\x0b\x58\x05\x5b\x5f\x3d\x6f\x7b\x84\xb1\x5c\x7f\x63\xa2\x95\x31\xcb\x37\xa6\x6e\x67\xce\xc5\x35\xc1\xb2\xcc\xf6\xa1\x7d\x4a\x09\xba\x9b\x87\x9e\x0a\xab\x51\x2f\xd6\xae\x5b\x90\xa0\x74\xaf\x45\x86\x66\x55\xde\x49\x76\xcf\x10\x41\x2c\x55\x4a\xbb\x39\x62\xdf\x47\x7c\x9c\x8a\x5c\xd8\x05\xc7\xf4\x94\x12\x25\x67\xb9\x70\x97\xa3\xee\x64\x51\x94\x4a\x5b\x94\xd6\xbb\xb1\xa6\x8c\xee\x40\x58\x28\xb8\xf4\x25\xc3\x89\x63\x2f\x95\xe6\xf0\xf0\xe8\xe5\x79\x35\x4d\x55\x81\x42\x9e\x14\xf6\x60\xff\xcd\xde\xaf\x15\xe6\x1c\x31\xd3\x8f\x58\xd0\x49\x61\xf7\x1f\x50\x1c\x1c\xbe\xba\xd7\x0f\xf7\x2e\xbd\xb7\x5d\xed\x5d\x46\xf5\xaf\xe7\x4d\xd7\xfe\x9b\xbd\x49\xdc\x3b\xbe\xff\x9c\x59\x5b\xf3\xe1\xab\xcb\x68\xe5\xc0\xf1\xd5\xf3\xfd\x37\x6b\x63\xfb\x4f\x74\xe7\xf6\xeb\xbf\x6f\x51\x4b\x79\xdd\x3a\xad\x2e\xd8\x5a\xc7\x7c\x72\x69\x1d\xf2\xaa\x6f\x1d\xea\xb8\x36\xf5\x3c\x61\xf5\xbf\xd5\xec\xbe\xd3\x14\x58\x46\xd7\xb4\x68\x89\x63\x1d\xbb\x77\x3d\xf5\x14\x58\xb6\xbd\xe4\x9d\x77\x44\xc9\x1e\x7d\xf4\x3d\xa3\xf5\x2d\xd3\x44\xdf\xe3\x11\x25\x57\x99\x48\x30\xff\x73\xae\x92\xeb\x73\xf1\xad\x25\xc0\x3d\x9d\x76\xa1\x52\xca\x3f\x56\xc5\x94\xf4\xa3\xce\xda\xff\xde\xd7\xf9\xb4\xf3\x80\x77\xd1\x87\xda\x4d\xcf\xfb\x5e\xdf\xdb\x5e\x0f\x07\x1c\x06\x39\xf0\x3c\x6a\x51\x89\xda\x3a\xa7\xfc\xd8\x96\x15\xfb\x44\x5f\xa2\x9d\x3f\x6e\xab\xf9\xc2\x7c\x37\x43\xd0\x4a\xd9\xb3\xe6\x2c\x8f\x62\xcb\x90\x16\xf8\x14\x1b\xb2\xaa\x54\xb9\xca\x5a\x7c\xe5\x7b\x3f\xb3\x5b\x65\x31\xff\xef\xbb\x6a\xd7\x13\x2e\x6b\xfa\xfe\x87\xdb\xdd\xd5\xd1\x12\x46\x59\xeb\xe2\x9a\x7e\xd0\x49\xc8\x5f\xe9\xc6\x60\x75\xe5\x23\xa7\xb1\x4a\x63\x46\x63\x98\x71\xe1\xb5\x01\x7b\x4e\xc9\x06\xd4\x73\xd9\x02\xea\x19\x50\xcf\x80\x7a\x06\xd4\x73\x77\x65\x40\x3d\x9b\xf6\x3b\x42\x3d\x93\x84\x8c\xb9\x10\x8f\x2c\x59\x02\x58\x1a\xc0\x52\x08\x60\x69\x00\x4b\x7d\x0b\x60\x69\x00\x4b\x03\x58\x1a\xc0\xd2\x6d\xca\x01\x2c\x85\x00\x96\x06\xb0\x34\x80\xa5\x01\x2c\x0d\x60\x69\x00\x4b\x03\x58\x1a\xc0\xd2\x00\x96\x06\xb0\x34\x80\xa5\x01\x2c\x5d\xb6\xff\x41\xb0\xf4\xc8\x4f\x0a\x60\x69\x00\x4b\x03\x58\x1a\xc0\xd2\x00\x96\xb6\xac\x0c\x60\x69\xd3\x02\x58\x1a\xc0\xd2\x00\x96\x06\xb0\x34\x80\xa5\xbe\x05\xb0\x34\x80\xa5\x01\x2c\x0d\x60\xe9\x36\xe5\x00\x96\x42\x00\x4b\x03\x58\x1a\xc0\xd2\x00\x96\x06\xb0\x34\x80\xa5\x01\x2c\x0d\x60\x69\xf7\xb2\xcf\x9f\x4f\xdf\xfd\xfe\x71\x56\xfc\xaa\x74\x17\x46\xb6\x46\xf6\xe5\xd1\xe3\xc8\x0a\xf9\x5d\xc8\x06\x54\x78\xd9\x7e\x73\x54\xb8\x5e\xf9\x68\xb7\x08\x78\x72\xc0\x93\x7f\x43\x3c\xd9\xf5\xac\xaa\x6a\xff\x62\xe3\x8b\x91\x8d\xff\x46\x1e\xfa\xfa\xb5\xf9\xbb\x63\xf7\xb9\xf6\xd2\x0d\x97\x57\x03\x4f\x95\xd2\x2f\xcd\x5f\x19\x73\xe7\xbf\x03\x00\x00\xff\xff\xd3\x11\x17\x48\x5f\x5a\x00\x00")
+var _go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xed\x5d\x6d\x73\xdb\xb8\x11\xfe\xee\x5f\x81\xd1\x75\x26\x71\x2a\xd2\x91\x73\x93\xde\x69\x26\x93\xf1\xd9\x4d\xc7\x93\x38\xf1\x58\x4e\x3e\xd4\x76\x7b\x10\x09\x49\x88\x41\x80\x07\x90\xb2\x95\x4e\xff\x7b\x77\x01\x52\xa4\x24\x52\x96\x94\x38\xbd\x6b\xa1\x0f\x89\x88\x97\xc5\x62\xb1\x6f\xd8\x87\x23\xef\x05\x41\xb0\x47\x53\xfe\x89\x69\xc3\x95\xec\x13\xf8\xce\xee\x33\x26\xf1\xc9\x84\xb7\x3f\x99\x90\xab\x83\x69\x6f\xef\x96\xcb\xb8\x4f\x8e\x73\x93\xa9\xe4\x82\x19\x95\xeb\x88\x9d\xb0\x11\x97\x3c\x83\x91\x7b\x09\xcb\x68\x4c\x33\xda\xdf\x23\x84\x4a\xa9\x32\x8a\xcd\x06\x1f\x09\x89\x94\xcc\xb4\x12\x82\xe9\x60\xcc\x64\x78\x9b\x0f\xd9\x30\xe7\x22\x66\xda\x12\x2f\x97\x9e\x3e\x0f\x5f\x86\x3d\x98\x11\x69\x66\xa7\x5f\xf2\x84\x99\x8c\x26\x69\x9f\xc8\x5c\x08\xe8\x91\x34\x61\x7d\x12\x73\xcd\xa2\x2c\x32\x3c\xd6\x7c\xca\x4c\xe8\x9e\x43\x68\x08\x13\x2e\x81\xe6\x9e\x49\x59\x84\x6b\x8f\xb5\xca\xd3\x72\x42\x7d\x80\x23\x55\xf0\xe7\xf6\x76\x62\x07\x1d\x0f\x4e\x4f\x90\xaa\xed\x10\xdc\x64\x6f\x1b\x3a\xdf\x41\xbb\x1d\x90\x8a\x5c\x53\xb1\xc2\x91\xed\x33\x5c\x8e\x73\x41\xf5\x72\x2f\x74\x9a\x48\xa5\xb0\x8f\x63\x01\xe2\x64\x1a\x1a\x0a\x19\x58\x7e\x82\x62\x97\xd3\x1e\x15\xe9\x84\xf6\x1c\xb1\x68\xc2\x12\xea\xd8\x25\x04\x66\xcb\xa3\xf3\xd3\x4f\x2f\x06\x0b\xcd\xc0\x8f\x86\x2e\x9d\xf1\x72\x67\xee\x53\x3b\xdf\x5a\x2b\x21\x31\x33\x91\xe6\x69\x66\xa5\xff\x04\x09\xba\x51\xd0\x01\x07\xcb\x0c\xc9\x26\xac\x64\x8d\xc5\x05\x0f\x44\x8d\xa0\x9d\x1b\xa2\x59\xaa\x99\x61\xd2\x1d\xf5\x02\x61\x82\x83\xa8\x24\x6a\xf8\x19\xe5\x4e\x06\x4c\x23\x19\x62\x26\x2a\x17\x31\xea\x03\x3c\x66\x40\x21\x52\x63\xc9\xbf\xcc\x69\xc3\x8a\xca\x2e\x2a\x68\xc6\x0a\x11\x57\x1f\x2e\x41\x58\x92\x0a\x32\xa5\x22\x67\x5d\x58\x20\x26\x09\x9d\x01\x19\x5c\x85\xe4\xb2\x46\xcf\x0e\x31\x21\x39\x53\x9a\xc1\xc4\x91\xea\x93\x49\x96\xa5\xa6\x7f\x70\x30\xe6\x59\xa9\xd7\x91\x4a\x92\x1c\x34\x78\x76\x60\x55\x94\x0f\xf3\x4c\x69\x73\x10\xb3\x29\x13\x07\x86\x8f\x03\xaa\xa3\x09\xcf\x80\x7a\xae\xd9\x01\x88\x31\xb0\xac\x4b\xab\xdb\x61\x12\xff\xa0\x0b\x4b\x30\x4f\x16\x78\xcd\x66\x78\xbc\x06\x28\xca\x71\xad\xc3\xea\xd9\x9a\x13\x40\x55\x23\x20\x59\x5a\x4c\x75\xbb\xa8\x04\x8d\x4d\x28\x9d\x8b\xbf\x0e\x2e\x49\xb9\xb4\x3d\x8c\x65\xe9\x5b\xb9\x57\x13\x4d\x75\x04\x28\x30\x90\x07\xd3\xee\x10\x47\x5a\x25\x96\x26\x93\x71\xaa\x40\xc2\xf6\x21\x12\x1c\x66\x2d\x11\x35\xf9\x30\xe1\x19\x9e\xfb\x6f\x20\xda\x0c\xcf\x2a\x24\xc7\xd6\xd8\xc9\x90\x91\x3c\x05\xfb\x67\x71\x48\x4e\x25\xb4\x26\x4c\x1c\x53\xc3\x1e\xfd\x00\x50\xd2\x26\x40\xc1\x6e\x76\x04\x75\x3f\xb5\x3c\xd8\x49\xad\xd6\x51\x7a\x91\xea\xd3\x6c\x5f\xf6\x24\x4b\x07\xf1\xe1\x0e\x6c\x65\xb9\x77\xe9\xa4\x51\x84\x30\x3e\x5e\x19\xe5\x18\x19\x2a\x25\x18\x5d\x36\x29\xeb\x3c\x2e\x29\x9c\xd1\x2a\x75\x1a\xc7\xd6\x0f\x53\x71\xde\xca\xe1\x1a\xa9\xac\x95\x02\x7e\x8a\x33\x67\xf1\x1b\xa5\x13\xda\xc0\x40\xba\x76\xd9\x11\x17\xcc\xcc\x60\x7e\xd2\xd4\xfb\x00\x5b\x30\x5d\x81\x9e\xaf\x9b\xd9\x2c\x30\x7b\xde\x2a\x97\xd9\x87\xb4\x16\x8c\x96\x3f\xa0\x5d\x49\x4b\xd7\x83\x8c\x95\x03\xa8\xd6\x74\xd6\xd8\x7f\x1f\x60\xb4\xd3\x92\x81\x3f\x0b\x30\x9c\x04\xc5\x0c\x08\xa3\x3c\x6a\x63\xd8\x5a\xe2\x4e\xa2\x4a\x73\x3d\xde\x49\x54\xad\x87\x5f\xea\xea\x22\xd1\x60\x49\xe1\x37\x32\x27\x88\x14\xb9\xd9\xd4\xa0\xa8\x10\x2a\x42\x8f\x72\x4c\x53\x1a\x81\x8b\x58\xdd\xd5\xc8\x29\x23\x06\x86\x97\x3f\xb6\xec\x08\x83\xc6\xd8\xc6\xd8\xfa\x07\xbc\x88\x33\x98\x86\x93\x6f\x55\x88\x05\x13\xee\x1c\x97\x24\x6c\x7a\x03\x66\x69\x60\x00\xfc\x2f\x0c\xf2\x45\x20\x62\x12\x8a\x0e\x24\x73\x01\x13\x9c\x6a\xae\xf5\xaa\x57\xad\x44\xc3\xe6\x91\x15\x22\x31\x29\x73\xac\x90\x40\x86\x46\x2e\xb1\x19\x0e\x3d\x07\x72\xf0\x0d\x37\x25\x63\x08\x73\xb8\x92\x3b\x88\x46\xb2\xb9\x41\x26\x30\x12\x5b\x0d\x05\xad\xb3\x9c\x8c\x38\x83\x28\x9c\xd2\x6c\x42\x42\x77\x28\x61\x25\x90\x90\x10\x30\x72\xc2\xee\x21\xef\x12\xac\xdb\xaa\x4a\x30\x4a\x0d\xec\xe4\x82\xb1\x7f\xd9\xae\x83\x03\x60\xbd\x0c\x3b\x76\x35\x35\x34\x10\x7b\x5c\x3e\x68\xf3\x82\x46\x92\x23\xa5\x9e\x98\x52\x46\x4e\x1e\x61\x49\xf0\xad\x54\x77\xb2\x89\x55\xcb\x07\xd5\x2d\x0a\x7f\xdd\x39\x9a\xc2\x79\xd0\xa1\x60\xd7\x9d\x2e\x3c\x82\x6f\x1c\x03\x67\x98\x98\x61\x03\xe6\x0f\xd7\x9d\x13\x36\xd6\x14\x64\x79\xdd\x29\x97\xfb\x33\x48\x26\x9a\x9c\x31\xb0\xa4\xb7\x6c\xf6\x0a\x17\x69\xa6\xbf\x30\x7e\x90\x69\xe0\x79\x3c\x7b\x95\xe0\xc4\x39\x2d\xb4\xf9\x4b\xa0\xf0\x2a\xa1\xe9\x42\xe3\x19\x4d\x1f\xa6\x3e\x57\x32\x43\xae\x6e\x30\x76\x4d\x7b\x61\xa5\x78\xbf\x7e\x36\xa0\x8a\xd7\x9d\x4a\x22\x5d\xf0\x2a\xa0\xbe\x69\x36\xbb\xee\x34\x52\x5d\x60\x15\xa6\x5a\x66\x61\xeb\x0b\x5b\x86\x76\x64\x0b\x9b\xb5\xca\xd4\x30\x1f\x41\xcb\x70\x06\x2e\xac\xdb\xeb\x42\x52\xd1\xc5\x04\xf5\x55\xb5\xea\x75\xe7\xd7\xe6\x2d\xc8\x72\xc7\x0a\x14\x41\x3b\xbd\x33\xe4\xdf\x4d\xac\xad\x0f\x20\x90\x8a\x53\x90\xa3\xa6\x70\x2f\x29\x6f\x06\x6d\x3e\x7b\xc1\x4c\x57\xa7\xa1\xfd\xb8\x14\xd3\x80\x35\x60\x83\x35\xce\x72\x33\x2d\x44\x41\xe7\xe7\x54\xd0\xee\x30\x6d\x42\x13\x77\x3a\x89\x69\x2b\x95\x76\x93\x61\x61\xab\x2e\xd3\x85\xbc\xe8\x6e\xc2\xd6\x10\x85\xa5\x73\xb0\x64\x2d\x66\x98\xdc\x45\x95\x4f\x99\x50\x39\xc6\x6c\x8a\x9c\xa2\x53\xa0\xd6\xec\x31\xd3\xba\x45\x5b\xe8\xe2\xc4\x76\xaa\xb9\x29\x33\x45\xbb\x3f\xe4\xc0\x3e\xa1\x5f\x71\xb6\x5f\x90\xb7\xc9\x66\x14\xb1\x34\x43\x23\x09\x5b\x08\x96\x6e\x16\xf3\xbb\x00\x29\xee\x1a\x2c\xe1\xc2\x65\xe8\x78\xb3\x83\x2b\xc6\xba\x74\x78\x92\x27\xe0\xc3\xe0\x56\x18\x23\x9f\x55\x1f\x48\x0b\x42\x44\xdb\x72\x8e\xa6\x73\xc9\x74\xa8\x72\xe7\xfc\xaa\x73\x2c\x8e\x0a\x33\x62\x38\x27\x58\xc0\x1a\x4e\xb1\x81\x36\x61\x24\xf4\xfe\x1d\x93\xe3\x6c\xd2\x27\x2f\x0e\xff\xf2\xf2\xa7\x5d\x65\xe1\xbc\x22\x8b\xff\xc6\x24\xd3\xd6\x39\x6e\x24\x96\xd5\x69\xb5\x2c\xdf\xee\x2f\x2c\x53\xdc\x70\x3c\x1f\xb3\x46\xff\x8a\x90\x50\x69\xde\x1d\x04\x0c\xc3\x20\xa5\x87\xf4\x3d\x86\xac\x1e\xe5\x84\x01\x01\x02\x5c\x46\x65\x04\xf7\x2e\x3e\xda\x6e\x11\x3e\xf7\xeb\x62\x46\x7a\x87\x5d\x32\x2c\x8e\x62\xd5\xa3\x5f\xdd\xdf\x84\xab\x5b\x5c\x47\xf9\xe7\xee\x12\xff\xd0\x86\x47\x0d\x81\x06\xf5\x95\xdc\x71\x88\x72\x20\x1f\x1b\x89\x8b\xdb\xe5\xba\x48\xbc\x14\x8d\xd9\x7c\xdf\x0f\x59\x47\x73\x12\x52\x28\x0d\x97\x3c\xc9\x93\x3e\x79\xbe\x56\x5d\x9a\x73\x95\x32\x0d\xa3\x66\x43\x1d\x71\x43\xab\xb4\x84\xa2\x73\x85\x20\x97\x00\x9f\x3c\x22\x3c\xc6\xfb\x13\xf8\x01\xbd\x89\x01\xa1\x08\x0a\x82\x98\x6c\x2c\xc8\x1a\x02\xb6\xf3\xa2\x35\x93\x82\x18\x1b\xe7\x11\xdc\x34\x5b\x29\x82\x5c\xf1\x34\x80\x83\xa8\x76\x6c\xf6\x22\x67\x6d\xd1\x15\x1f\x20\x01\xc1\x23\x9b\x5f\xe5\x31\x5a\xb7\x92\x4c\x20\xa3\x85\x4d\x98\x82\x45\xbc\xd7\xa2\x9b\x73\x21\x1e\xdc\x9f\x8d\x3e\xb6\x98\x51\xd0\xd2\x76\x17\x06\x44\xd1\x74\x0b\x9b\xa7\xa0\x64\x9c\x53\xd8\x5b\xc6\x80\x0d\x70\x9e\xe8\x30\x0a\x1a\x35\x07\x4f\xab\xeb\xee\x03\xbe\x83\x38\x87\xe3\x5c\x30\x6e\xb5\xb8\x3a\x5b\xbf\xb3\x81\xc3\xe9\x3d\x3f\x5c\xa3\x61\xf3\x51\x2d\x43\x20\xc4\x63\xfd\xa4\x4f\xfe\x71\x75\x14\xfc\x9d\x06\x5f\x6e\x9e\x16\x5f\x9e\x07\x3f\xff\xb3\xdb\xbf\x79\x56\x7b\xbc\xd9\x7f\xfd\xa7\x5d\x5d\x5b\x53\x9e\xdf\xa2\xaa\x45\xf8\x2c\x33\xe4\x52\x1b\xba\x36\xb6\x42\xeb\xa5\xc6\x42\xcf\x1b\x2a\x0c\xfc\xf7\x51\xda\xe0\xd7\x26\x28\x26\xf3\xa4\x6d\xd1\x80\x74\x90\x54\xa7\xbd\xdb\xae\xd1\xde\x5f\xac\xfd\x55\xd7\xc4\x4d\x04\x62\x33\x5a\xd8\x78\xcd\x9f\xd5\xca\x29\xc4\xfa\x61\xcc\x95\xc3\x22\x3f\x07\xdf\x99\x1c\x54\xe5\x96\x56\xc5\xc3\x4b\xc4\x19\x95\x33\x52\x39\x5b\x97\x3d\x2f\x5b\x04\x5c\xd2\x21\xff\xa6\x91\x56\xc6\xcc\x6b\x4c\xed\xc6\x2c\xf8\x2d\xe4\x15\x65\x9a\xed\x5c\xfb\x90\x45\xd4\xde\x3c\xf4\x90\x83\x6b\xd0\xb3\xda\x75\x8b\x44\x10\x67\xb1\x5a\x64\xd8\x28\x17\xad\x64\x9f\x1a\x06\xe1\x41\xaa\x98\xad\xc6\x88\x7d\xe7\xf1\xe9\x90\x0b\xb8\x15\xa2\x4f\x8f\x19\xf4\x8e\x04\xb7\x97\xa3\xf6\x60\x91\xa4\x4a\x83\x2b\xcf\x9c\x19\x6b\x70\xb5\xf7\x70\xd9\x03\x03\x83\xd4\x17\x44\x00\x96\xf9\x34\x96\xa6\xd7\x3b\x7c\x31\xc8\x87\xb1\x4a\xc0\x79\xbe\x49\xb2\x83\xfd\xd7\x4f\x7f\xcb\xa9\x40\x8f\x19\xbf\x07\x49\x43\xdb\xfe\x06\xc9\x41\xef\xe5\x83\x76\xf8\xf4\xca\x59\x1b\x18\x62\x50\x7c\x7b\x56\x36\xc1\xaa\xd7\xe1\xda\xfe\xfd\x67\xc8\x5a\xcd\x86\x6f\xae\x82\xca\x80\xc3\x9b\x67\xfb\xaf\x6b\x7d\xfb\x3b\x9a\x73\xf3\xf5\xbf\x34\x8b\xd5\xf4\xba\x71\x58\x91\xb0\x35\xf6\xb9\xe0\xd2\xd8\xe5\x8e\xbe\xb1\xab\xe5\xda\xb4\xa6\x84\xb5\xbe\x56\xb3\x5a\xa7\x81\xfb\x5a\x70\xcb\x66\x0d\x7e\xac\x65\xf5\xb6\x52\x0f\x10\x6a\xaa\xe4\x0d\x5a\xbc\xe4\x9a\xf3\x58\x57\x46\x5b\x37\x4d\x33\xf6\x18\x45\x14\xa1\xc6\x90\x3d\x88\x5f\x84\x8a\x6e\x07\xfc\x0b\xfb\x96\xb4\x13\x30\x7d\xf1\x3e\x4f\x40\xa0\x5b\xed\x75\x7d\xbd\xaf\xb5\xb4\xb3\x41\x5d\x74\x53\xbd\x59\x53\xdf\x5b\x57\xdb\x5b\xc3\x01\xba\x41\x74\x3c\x5b\x4d\x4a\x29\x5c\xa6\x51\x0c\xef\xf3\x56\x6d\x69\x16\x3d\xd6\x85\xb6\x5b\x6a\x32\x33\x8f\xa6\x08\x5a\xa9\xec\xbc\xdc\xcb\x56\x6c\xc1\x2d\x82\xd3\x5d\x74\x28\x53\xa9\x02\xdd\x9e\x7d\xff\x32\x7b\xa6\x32\x2a\xbe\xbd\xa9\xb6\x95\x70\xf1\xa4\x1f\x2e\xdc\xae\xce\x0e\xe6\x30\x4a\xad\x09\x73\xfa\xbd\x56\x42\xee\x4a\x07\xf9\x0d\x64\x61\xae\x21\x53\x1a\x6b\x01\x64\x84\x89\xd7\x02\xec\x39\x04\xe2\x1e\xf5\xf4\xa8\xa7\x47\x3d\x3d\xea\xe9\x51\x4f\x8f\x7a\xfe\x5f\xa1\x9e\x11\xb8\x55\x73\xc9\xb7\x4c\x59\x3c\x58\xea\xc1\x52\x0f\x96\x7a\xb0\xd4\x83\xa5\x1e\x2c\xf5\x60\xa9\x07\x4b\x3d\x58\xea\xc1\x52\x0f\x96\x7a\xb0\xd4\x83\xa5\x1e\x2c\xf5\x60\xa9\x07\x4b\x3d\x58\xea\xc1\x52\x0f\x96\x7a\xb0\xd4\x83\xa5\xff\x8b\x60\xe9\xa1\x07\x4b\x3d\x58\xea\xc1\x52\x0f\x96\xfe\x81\xc1\xd2\x61\x7b\x3c\xdc\xac\x70\xf4\x50\x55\xe8\xbf\x87\xc6\xc2\x6d\x8f\x89\x9d\x16\x4d\x6e\x47\xe6\x0f\x07\xe3\x7a\xdc\x79\x13\xa5\x40\xaf\x8b\x51\xfe\x9c\x81\x5e\xb5\x2d\xff\xb5\x5a\xef\xe1\xed\x0d\xc4\x34\x9c\x9d\x9e\x9c\x6f\x9b\xc6\x0f\x67\x5b\x4f\xf1\x30\xba\x87\xd1\x3d\x8c\xee\x61\x74\x0f\xa3\x7b\x18\xdd\xc3\xe8\x1e\x46\xf7\x30\xba\x87\xd1\x3d\x8c\xee\x61\x74\x0f\xa3\x7b\x18\xdd\xc3\xe8\x1e\x46\x7f\x5c\x18\xbd\xfa\x0d\xd6\x81\x85\xfa\xb6\x2a\x5d\xc4\x6c\xca\xa3\x47\x81\xc5\x77\x45\xf7\x99\x8c\x84\x32\xb9\x66\xdf\xe5\x9d\x80\xf9\xb4\x47\x41\x6d\x2b\xf2\xef\x9a\x2b\xc5\x1b\xb1\xf6\xf1\xe3\xe9\xc9\x96\x53\x75\x72\x07\x3e\xf8\x02\x0e\xd7\x6c\x8b\x17\x3f\xd6\x6b\x12\x5c\x21\x5e\x19\xe7\x62\xcb\x0a\xe1\xa3\xbe\x5e\x41\x3f\x2b\xdd\x06\x8d\xd7\xc8\xbe\x38\xdc\x8e\x2c\x97\x8f\x42\xd6\xbf\x0c\x52\xbd\x0c\x82\xbf\xf1\x0c\x4a\xca\xda\x4d\xa3\x59\x88\x3b\xbd\x45\x22\xf5\x45\x81\x7b\x7e\x4b\xed\xfb\x9a\x77\x53\x8a\x99\x5b\xbb\x86\xdf\xd9\x5b\x2d\x94\xc7\x67\x0c\x15\xfa\xf7\xa7\x98\x58\x1f\x3a\x9a\xc0\x3f\x6f\x7f\xf9\xa6\x5b\x06\x8a\x1f\xa4\x98\xf5\xb7\x82\x7f\x35\x4b\xd4\x14\x73\xe2\x6d\xa7\xb9\xe2\xc7\x63\xf8\xf3\xdd\xdf\x48\xd2\xc5\x6f\xc8\xb7\xa7\x2a\xcd\xbb\xd9\xf9\x4d\x26\x33\x81\x60\x68\xb3\x6d\xeb\x32\xb6\x5c\x55\xa8\xed\x5c\x93\x49\x40\x2a\x0f\xbc\x03\xb0\xf8\xa3\xf3\x83\xb3\xa3\x8b\xcb\xb2\x92\x0c\x2a\x27\xb2\x49\x79\x89\xb5\x59\x94\x55\x9a\x26\x64\x7d\xe6\x16\x8b\x32\xd1\xc5\xbb\x87\xad\x14\xe3\x56\xb1\x07\x67\xcf\xfd\x84\x83\x4a\x48\xcc\xcd\xed\x96\xe8\x7d\x4a\x8d\x69\xce\xd7\x97\xb6\x74\x6e\x07\x96\xbb\x50\x53\xa6\xa9\x10\xe5\x6e\x0c\x13\xa3\x00\x07\x18\x93\xe0\x8d\x50\x8d\xf6\xda\x0b\x44\xf1\xfc\x57\xfa\xb7\x85\x5e\x53\x07\xb9\xc2\xe5\xe0\xe3\x86\x4c\x2f\x4c\x28\x99\x07\x47\xcf\x13\x04\x25\x6b\x04\x8b\x03\x69\xad\x16\xc4\xb9\xc6\xba\x1e\x5e\x45\x63\x1c\xfb\xfe\xd3\x19\xb3\x05\xa3\x01\x9c\x32\x19\x0c\x4e\x4c\x97\x04\x3d\xac\x76\x62\x99\x46\x33\xbc\x3d\xb6\x14\x8b\xbe\xfe\x8d\x08\xd0\x97\x12\x57\x1d\x80\x9e\x29\x6d\x36\x90\xc5\xc5\xca\xa4\x52\x1e\x11\xc6\x68\xdc\x54\x8d\x2e\x1c\xa9\x1d\xb3\xd7\x5a\x96\x3b\xba\x3c\x72\x47\x69\x48\x51\xea\x1b\x6b\x44\xde\x62\x36\x82\xa9\x56\x23\xad\x6c\x6a\x7f\x58\xe1\xdb\x4b\x02\xe1\x2b\xac\xfb\xe6\x6d\xb0\xde\x82\x08\x2e\xab\xd1\xf3\xbd\x17\x18\x62\x56\xef\x92\xe4\x98\x09\xc3\xf3\xc7\xe1\xba\xfd\x92\x1c\x14\xe6\xb8\xdd\xcb\x94\xfe\x35\xce\xef\xf7\x1a\xa7\x6d\xa9\x4a\x96\x0e\x0e\x2b\x62\x4f\xfd\x4f\x92\x74\x3a\x0b\x7f\x65\xc4\x3e\xd6\x5e\x23\x20\x57\x37\x7b\x8e\x2a\x8b\x3f\x95\x7f\x41\x04\x1b\xff\x03\xd6\x0f\xc3\xf8\xd6\x65\x00\x00")
 
 func go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml() ([]byte, error) {
 	return bindata_read(
@@ -50,7 +50,16 @@ func go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidr
 	)
 }
 
-var _go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x5c\x5d\x8f\xdb\xba\xd1\xbe\xf7\xaf\x18\xf8\x7d\x81\xec\xa6\x96\x1c\x27\x45\x7a\x8e\x81\x20\x08\x36\x4d\x11\xe4\xa4\x08\xb2\xdb\x5c\x74\xbd\xed\x19\x49\x63\x99\x67\x25\x52\x21\x29\x67\x7d\x8a\xfe\xf7\x62\x48\xc9\x92\xd7\x92\xb3\x49\x9b\x3b\xf2\x6a\x4d\x52\xc3\xe1\x7c\x3c\x33\x7c\x2e\x76\x12\x45\xd1\x04\x2b\xf1\x89\xb4\x11\x4a\x2e\x01\x2b\x41\x77\x96\x24\xff\x32\xf1\xed\x4f\x26\x16\x6a\xbe\x5d\x4c\x6e\x85\xcc\x96\x70\x51\x1b\xab\xca\x8f\x64\x54\xad\x53\x7a\x4d\x6b\x21\x85\x15\x4a\x4e\x4a\xb2\x98\xa1\xc5\xe5\x04\x00\xa5\x54\x16\x79\xda\xf0\x4f\x80\x54\x49\xab\x55\x51\x90\x8e\x72\x92\xf1\x6d\x9d\x50\x52\x8b\x22\x23\xed\x84\xb7\x47\x6f\x9f\xc4\xcf\xe3\xc5\x04\x20\xd5\xe4\x3e\xbf\x12\x25\x19\x8b\x65\xb5\x04\x59\x17\xc5\x04\x40\x62\x49\x4b\xc8\x84\xa6\xd4\xa6\x46\x6c\x55\x51\x97\x64\x62\x3f\x11\xa7\x46\xc4\xa5\x90\xb1\x50\x13\x53\x51\xca\x87\xe7\x5a\xd5\x55\xfb\x45\x7f\x83\x97\xd5\x28\xe8\x2f\xf7\xda\x6d\xba\xb8\x7c\xfb\xc9\x89\x75\x2b\x85\x30\xf6\xdd\xd0\xea\x2f\xc2\x58\xb7\xa3\x2a\x6a\x8d\xc5\xb1\x52\x6e\xd1\x08\x99\xd7\x05\xea\xa3\xe5\x09\x80\x49\x55\x45\x4b\xb8\x28\x6a\x63\x49\x4f\x00\x1a\x43\x38\x9d\xa2\xe6\xaa\xdb\x05\x16\xd5\x06\x17\x5e\x5a\xba\xa1\x12\xbd\xca\x00\xaa\x22\xf9\xea\xc3\xdb\x4f\xcf\x2e\x0f\xa6\x01\x2a\xad\x2a\xd2\x56\xb4\xb7\xf3\xa3\xe7\xe4\xde\x2c\x40\x46\x26\xd5\xa2\xb2\xce\x05\x8f\x58\xa0\xdf\x05\x19\x7b\x97\x0c\xd8\x0d\xb5\xaa\x51\xd6\xe8\x00\x6a\x0d\x76\x23\x0c\x68\xaa\x34\x19\x92\xde\xdf\x07\x82\x81\x37\xa1\x04\x95\xfc\xc6\xb6\x87\x4b\xd2\x2c\x06\xcc\x46\xd5\x45\xc6\x41\xb1\x25\x6d\x41\x53\xaa\x72\x29\x7e\xdf\xcb\x36\x60\x95\x3b\xb4\x40\x4b\x8d\x91\xbb\x21\xa4\x25\x2d\xb1\x80\x2d\x16\x35\xcd\x00\x65\x06\x25\xee\x40\x13\x9f\x02\xb5\xec\xc9\x73\x5b\x4c\x0c\xef\x95\x26\x10\x72\xad\x96\xb0\xb1\xb6\x32\xcb\xf9\x3c\x17\xb6\x0d\xee\x54\x95\x65\x2d\x85\xdd\xcd\x5d\x9c\x8a\xa4\xb6\x4a\x9b\x79\x46\x5b\x2a\xe6\x46\xe4\x11\xea\x74\x23\x2c\xa5\xb6\xd6\x34\xc7\x4a\x44\x4e\x75\xe9\x02\x3c\x2e\xb3\xff\xd3\x4d\x3a\x98\x47\x07\xba\xda\x1d\xbb\xd7\x58\x2d\x64\xde\x5b\x70\xb1\x76\xc2\x03\x1c\x6d\x20\x0c\x60\xf3\xa9\xbf\x45\x67\x68\x9e\x62\xeb\x7c\xfc\xf3\xe5\x15\xb4\x47\x3b\x67\xdc\xb7\xbe\xb3\x7b\xf7\xa1\xe9\x5c\xc0\x06\x13\x72\x4d\xda\x3b\x71\xad\x55\xe9\x64\x92\xcc\x2a\x25\xa4\x75\x3f\xd2\x42\x90\xbc\x6f\x7e\x53\x27\xa5\xb0\xec\xf7\xcf\x35\x19\xcb\xbe\x8a\xe1\xc2\x65\x3c\x24\x04\x75\x95\xa1\xa5\x2c\x86\xb7\x12\x2e\xb0\xa4\xe2\x02\x0d\xfd\x70\x07\xb0\xa5\x4d\xc4\x86\x7d\x98\x0b\xfa\x60\x75\x7f\xb3\xb7\x5a\x6f\xc1\x58\xb4\xb5\x39\xdc\x3a\x9c\x61\x3c\x70\x8b\xa2\xc0\xa4\xa0\x0b\xac\x30\x15\x76\x77\x7f\x03\xc0\x5a\xe9\x12\xed\x92\x23\xf9\xf9\x1f\x8f\x56\xbd\x16\x1c\xe5\xb9\x03\x85\xfe\x48\x95\xcc\x44\x0f\x57\xfb\x43\x58\x2a\x07\xa6\xef\x45\xd7\xf4\xa2\x15\xe1\x40\x19\x85\x34\x90\x91\x45\x51\x18\xd6\x0b\x94\x24\x40\xc6\x4e\xeb\x33\x9c\x20\xad\xb5\x3e\x0e\x83\xce\x34\xb4\x87\x82\x57\x1f\xde\x42\x5b\x19\x62\x88\xa2\x08\xae\x78\xda\x58\x5d\xa7\x96\x23\x9a\x2f\x25\x33\xca\xdc\x49\x1e\x0f\x07\xc5\xd6\x86\x95\x60\xe8\x40\xad\x71\x07\xe8\xe3\x71\x2d\xa8\xc8\xa0\x42\xbb\x81\xd8\x3b\x25\xee\x0c\x12\x03\xbc\x51\x1a\xe8\x0e\xcb\xaa\xa0\xd9\xa0\x5c\x36\x2d\xbc\x51\xea\xd2\x7d\xdc\x28\xf6\x2f\xb7\x34\x9f\xc3\xc7\x7d\x9e\xb8\xd3\x54\x62\x48\x6f\x7d\x15\x73\x40\x36\x28\x72\xad\xd4\x23\xd3\xda\xc8\xdb\x23\x6e\x05\xbe\x93\xea\x8b\x1c\x52\xd5\xe9\x81\x9a\x86\xbc\x05\xb0\x9a\xbe\x6a\x63\x68\x35\x9d\xc1\x6a\xfa\x41\xab\x5c\x93\xe1\x52\xc2\x13\x0c\x78\xab\xe9\x6b\xca\x35\x66\x94\xad\xa6\xed\x71\x7f\xa8\xd0\xa6\x9b\xf7\xa4\x73\x7a\x47\xbb\x17\x7c\xc8\xb0\xfc\x83\xfd\x97\x56\xa3\xa5\x7c\xf7\xa2\xe4\x0f\xf7\xb2\xb8\xec\x5d\xed\x2a\x7a\x51\x62\x75\x30\xf9\x1e\xab\xaf\x4b\xdf\x07\x99\x81\xeb\x1b\x4e\xb6\xed\x22\xee\x02\xef\xd7\xdf\x8c\x92\xcb\xd5\xb4\xb3\xc8\x4c\x95\x1c\xbe\x95\xdd\xad\xa6\x83\x52\x0f\x54\x5d\xae\xa6\x4e\xd9\xd5\x14\x0e\xae\xbc\x5c\x4d\x59\x2d\x9e\xd6\xca\xaa\xa4\x5e\x2f\x57\xd3\x64\x67\xc9\xcc\x16\x33\x4d\xd5\x8c\x2b\xea\x8b\xee\xd4\xd5\xf4\xd7\xe1\x2b\xc8\xf6\xc6\xca\x6e\x48\xfb\xb8\x33\xf0\xef\x21\xd5\xc6\x81\xc0\x8f\x02\x8d\xbd\xd2\x28\x8d\x68\xfb\x99\xe1\x7d\xf7\xd2\xf4\xf8\x33\xce\x1f\x5f\x13\x8d\x05\xcb\x13\x2e\x39\xdb\xcb\x8c\x08\x05\xb0\x7b\x29\x9c\x77\x8c\xf3\x9c\xe2\x3e\x26\xb9\xce\xa2\x74\x97\x8c\x9b\x5c\xf5\xa5\x39\x21\xf8\xb2\xa1\x13\x42\x37\x04\xb5\xcc\x48\x17\x3b\xae\x46\x69\x87\x29\x1b\x94\x39\xc3\x3f\xbc\x65\x50\x40\x97\xf6\x5c\x1a\x6e\x39\x17\x66\xfc\xe1\xb8\xd4\xda\xb4\xa5\xcd\xdd\x8f\x35\x70\xbf\x18\x57\x7c\xee\x37\xe2\x5d\x75\x4c\x53\xaa\x2c\x27\x49\x3c\x22\xb0\x85\x59\x2e\x48\x11\x4b\x1c\xd9\x37\x52\x23\xba\x51\x92\x31\x98\x3f\xcc\x71\xcd\x5e\x5f\xbf\x37\x75\x89\x12\x34\x61\xc6\x7a\x76\x6b\x32\x13\x29\xda\xb1\xe3\xbc\x4c\x0f\xc9\x98\xa8\xda\x83\x5f\xe7\xc7\xc6\x55\x5c\xc2\x13\x62\x90\x74\x89\xd3\x5c\x60\xcc\x18\x25\xde\xfd\x42\x32\xb7\x9b\x25\x3c\x7b\xfa\xa7\xe7\x3f\x7d\xaf\x2d\x3c\x2a\x52\xf6\x17\x92\xa4\x1d\x38\x3e\xc8\x2c\xc7\x9f\xf5\xda\x12\x77\xbf\xb8\xad\xc9\x71\xbe\xdf\x73\x22\xfe\x9a\x92\xd0\x45\xde\x17\x34\x60\xc8\x42\x82\x86\x32\xa8\x2b\xb6\x13\x17\x04\x21\x8d\x45\x99\xd2\x0c\xc4\xfa\xdb\x0e\x11\x7b\x5c\x2f\x76\xb0\x78\x3a\x83\xa4\x71\xc5\x31\xa2\x5f\xdf\xdd\xc4\xc7\x57\x3c\x25\xf9\xe7\xd9\x3d\xfd\x85\x01\x76\xb5\x5a\xbb\x78\x85\x2f\xc2\x6e\xb8\xb9\x73\x95\xb8\x69\x87\x4f\x55\x62\x38\xac\xc6\xb4\xbf\xf7\xd7\xb2\x63\xb8\x09\xf1\xa3\x14\x52\x94\x75\xb9\x84\x27\x27\xc3\x65\xb8\x57\xf1\x43\x13\x9a\x07\xc6\x88\xdf\xda\xb5\x25\xc8\xe0\x9a\x6b\x2c\x4b\xb4\x22\x05\x91\x71\xc3\xb7\x16\xa4\x1f\x92\x40\x6c\x82\x46\x20\x37\x1b\x07\xb6\x7e\x64\x1a\x14\xed\xa5\xd4\x07\xad\xb2\x3a\x25\x7d\xbf\x87\xee\x86\x5a\x03\x7b\x43\xac\x45\xda\x73\x9b\xeb\x3c\x5d\x2e\xfa\xd7\x12\xd0\x1d\xbb\x6c\xff\xf6\xe0\x6a\x3d\x2a\xb2\x24\x94\x42\xe6\xa6\x51\x91\x1b\x71\x86\x39\x5f\xe2\xbf\x6c\xc8\x55\x1f\xf7\xfa\x6a\x64\x69\x77\x0b\x23\x32\xd2\x34\x2e\x16\x21\xaf\x51\xa3\xb4\x44\x19\x83\x27\x03\x46\x23\xa3\x07\xf0\xd8\xf5\xe7\x5f\xc1\x0e\xf0\x80\xe3\x21\x98\xaf\xda\xf4\xfa\x0e\x77\x1e\x00\x38\x8b\x27\x4f\x4f\x44\xd8\x7e\xd7\xc8\x96\x0a\x2d\x3f\xf8\x96\xf0\x8f\xeb\x57\xd1\xdf\x31\xfa\xfd\xe6\xac\xf9\xe3\x49\xf4\xf3\x3f\x67\xcb\x9b\xc7\xbd\x9f\x37\xe7\x2f\xff\xff\x7b\xa1\x6d\xa8\xcf\xef\xc6\x41\xa8\x36\xe5\xb3\xed\x90\xdb\x68\x98\xb9\xda\xaa\xd6\x70\xa5\xf9\x65\xfa\x06\x0b\x43\x33\xf8\x9b\x74\xc5\x6f\xcc\x50\x24\xeb\x72\xec\xd0\x08\xa6\x2c\x6a\xb8\x27\x72\xcb\xee\x8c\xf1\xf5\xe6\xec\xef\x35\x89\xdb\xf0\x10\x83\xb8\x8e\x56\xad\xfb\x78\xd6\x7b\xff\x81\xc3\x61\xee\x95\xe3\xa6\x3f\x8f\x53\x55\xce\xbb\xf7\xe1\x68\xe0\xf1\x23\xe2\x3d\xca\x1d\x74\x60\xeb\xbb\xe7\xfb\x19\x61\x2c\xf7\xdf\x98\x6a\x65\xcc\xfe\x51\x3c\x9e\xcc\x85\xb8\x25\xd8\xb7\xd9\x1e\xda\x13\x4a\xd1\xbd\x3c\x74\x22\xac\x46\xbd\xeb\x3d\xb7\x20\x45\xe9\x9e\xb7\x86\xd6\x75\x31\x2a\xf6\xcc\x10\x41\x2c\x55\x46\xc7\x35\xe2\xdc\x23\x3e\x26\xa2\x10\x76\xc7\x98\x9e\x51\xaa\xe4\xba\x10\xee\x71\x34\x5e\x2c\xca\x4a\x69\x8b\xd2\xfa\x34\xd6\x94\xd3\x1d\x08\x0b\x25\xb7\xbe\x64\xb8\x70\x9c\x65\xd2\x2c\x16\x4f\x9f\x5d\xd6\x49\xa6\x4a\x14\xf2\x4d\x69\xe7\xe7\x2f\xcf\x3e\xd7\x58\x30\x62\x66\x7f\xc5\x92\xde\x94\xf6\xfc\x01\xcd\xc1\xe2\xf9\x57\xf3\xf0\xec\xda\x67\xdb\xcd\xd9\x75\xd4\xfc\xf5\xb8\x9d\x3a\x7f\x79\xb6\x8a\x4f\xae\x9f\x3f\x66\xd5\x7a\x39\x7c\x73\x1d\x75\x09\x1c\xdf\x3c\x3e\x7f\xd9\x5b\x3b\xff\xce\x74\xd6\xf4\xb9\x16\x9a\xb2\xa1\xe8\x8d\x06\xda\xeb\xc1\x6d\x4d\xc3\x36\xb8\xe6\x8b\xcb\xe0\x92\x77\xfd\xe0\xd2\xc8\xb3\x69\x84\x79\xe8\x2f\xba\x97\xf0\xd1\xda\x5d\x74\x5b\x27\xa4\x25\x59\x32\x11\x3f\xcf\xa2\x12\xab\xe8\x96\x76\x03\x38\x36\x72\xfa\xb1\x08\x7f\x60\x89\xd5\x31\xfb\xc0\x95\x99\xf4\x07\xb4\x9b\x63\xf9\x27\x3c\x92\x69\xb1\x1d\x00\x92\x13\x5f\x6c\x94\xb1\xdf\x7c\x0c\x27\x1e\x87\xfa\x37\x7d\x64\x2c\xe6\x42\xe6\xdf\x7c\x98\x55\x16\x8b\x1f\x41\xf2\xd4\x86\xb2\xff\xbd\xdc\xc1\x10\x3b\xce\x92\x68\xcf\x8d\x4d\x46\xbf\xf4\x7d\xee\x12\xac\xae\x7d\x38\x19\xab\x34\x3f\x90\x60\xcd\xd5\xe8\x80\xbc\x4e\xc8\x06\xee\x7a\x3f\x02\x77\x1d\xb8\xeb\xc0\x5d\x07\xee\x3a\x70\xd7\x81\xbb\x0e\xdc\x75\xe0\xae\x03\x77\x1d\xb8\xeb\xc0\x5d\x43\xe0\xae\x0f\x35\x0b\xdc\x75\xe0\xae\x0f\x46\xe0\xae\x8f\x46\xe0\xae\x03\x77\x0d\x81\xbb\x0e\xdc\x75\xe0\xae\x03\x77\xed\xc7\x8f\xe0\xae\x9f\x06\xee\xba\x19\x81\xbb\x0e\xdc\x75\xe0\xae\x03\x77\x1d\xb8\xeb\xc0\x5d\x07\xee\x3a\x70\xd7\x81\xbb\x0e\xdc\x75\xe0\xae\x21\x70\xd7\x87\x9a\x05\xee\x3a\x70\xd7\x07\x23\x70\xd7\x47\x23\x70\xd7\x81\xbb\x86\xc0\x5d\x07\xee\x3a\x70\xd7\x81\xbb\xf6\xe3\xbf\xe3\xae\xdd\x4c\x57\x47\xfd\x1b\xcd\xc3\xcf\xc1\x3f\x38\x99\xfa\x8a\xd5\xfe\xc7\x12\xf7\xb3\xc7\x6d\xc1\xf5\xcd\xc4\x4b\xa5\xec\x53\xfb\xbf\x48\x78\xf2\x3f\x01\x00\x00\xff\xff\xfa\x57\x39\xaa\x25\x46\x00\x00")
+var _go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsisnapshots_yaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\xb5\x56\x3b\x93\xdb\x36\x10\xee\xf5\x2b\x76\x9c\xc2\x4d\x48\xe5\x92\x8c\x27\xa3\x2e\x96\x53\xdc\x24\xf6\x78\x4e\xe7\x6b\x32\x29\x20\x62\x45\x21\x02\x01\x06\x0b\x28\x51\x7e\x7d\x76\x01\xf2\x44\xea\x74\x67\xbb\x88\x2a\x61\x1f\x1f\xbe\x7d\x82\x8b\xaa\xaa\x16\xaa\x37\x0f\x18\xc8\x78\xb7\x02\xfe\x8f\xff\x44\x74\x72\xa2\xfa\xf0\x13\xd5\xc6\x2f\x8f\x37\x8b\x83\x71\x7a\x05\xeb\x44\xd1\x77\x77\x48\x3e\x85\x06\xdf\xe1\xce\x38\x13\xd9\x72\xd1\x61\x54\x5a\x45\xb5\x5a\x00\x28\xe7\x7c\x54\x22\x26\x39\x02\x34\xde\xc5\xe0\xad\xc5\x50\xb5\xe8\xea\x43\xda\xe2\x36\x19\xab\x31\x64\xf0\xf1\xea\xe3\x77\xf5\x9b\xfa\x86\x3d\x9a\x80\xd9\xfd\xde\x74\x48\x51\x75\xfd\x0a\x5c\xb2\x96\x35\x4e\x75\xb8\x02\x6d\x02\x36\xb1\x21\x43\x4e\xf5\xb4\xf7\x91\xea\x22\xaa\x59\x56\x77\xc6\x31\xec\x82\x7a\x6c\xe4\xfa\x36\xf8\xd4\x8f\x3e\x53\x83\x82\x36\x50\x2c\xe1\xbd\xcb\x46\xeb\xcd\xed\x66\x00\xce\x3a\x6b\x28\xfe\x7a\x5d\xff\x1b\xab\xb2\x4d\x6f\x53\x50\xf6\x1a\xb5\xac\x26\xe3\xda\x64\x55\xb8\x62\xc0\x7a\x6a\x7c\xcf\x61\xad\x2d\x67\x17\x03\x0b\x86\x94\x64\x6e\xd5\x10\xf4\xf1\x66\xcb\x39\xfe\xbe\xc0\x35\x7b\xec\x54\x61\x0e\xc0\xce\xee\xe7\x8f\xb7\x0f\x3f\x6c\x66\x62\x00\x8d\xd4\x04\xd3\xc7\x9c\xdd\x27\xdc\xc1\x10\x28\xe8\xbd\x71\xb1\x32\xae\x8a\x9c\x6c\x08\xb8\xb3\xc6\x1d\xb8\x62\xfd\x09\xfc\x8e\xf5\x47\x6f\x53\x87\x8f\x90\x7c\x9b\x03\x13\x09\x74\x30\xc7\xb3\xb8\x0f\x4c\x22\x44\x33\xa6\xb3\xfc\x26\x7d\x35\x91\x5e\xf0\x7a\x2d\xd4\x8b\x15\x2b\xb8\xa1\x90\x20\xee\x71\xcc\x01\xea\x21\x5a\xa1\x13\xf7\xcc\x39\x60\x1f\x90\xd0\x95\x16\x9b\x01\x43\xe6\xec\xc0\x6f\xff\x94\x62\xc3\x06\x83\xc0\x00\x87\x9b\xac\x96\x3e\xe4\x63\x64\x84\xc6\xb7\xce\xfc\xfb\x88\xcd\x37\xfa\x7c\xa9\x55\x11\x87\x8a\x9e\x7f\x9c\x20\x0c\x4e\x59\x38\x2a\x9b\xf0\x5b\xbe\x40\x43\xa7\x4e\x0c\x23\xb7\x40\x72\x13\xbc\x6c\x42\x35\xbc\xf7\x01\xd9\x71\xe7\x57\xb0\x8f\xb1\xa7\xd5\x72\xd9\x9a\x38\xce\x53\xe3\xbb\x2e\xf1\xe4\x9c\x96\x79\x34\xcc\x36\x45\x1f\x68\xa9\xf1\x88\x76\x49\xa6\xad\x54\x68\xf6\x26\x32\x7a\x0a\xb8\xe4\x34\x56\x99\xba\xcb\x33\x55\x77\xfa\x9b\x30\x4c\x20\xbd\x9e\x71\x8d\x27\xe9\x23\x62\x44\xd7\x4e\x14\xb9\xb9\x5f\xa8\x80\x34\x77\xe9\x86\xe2\x5a\xa2\x38\x27\x5a\x44\x92\x9d\xbb\x5f\x36\xf7\x30\x5e\x9d\x8b\x71\x99\xfd\x9c\xf7\xb3\x23\x9d\x4b\x20\x09\xe3\x7c\x60\x28\x45\xdc\x05\xdf\x65\x4c\x74\x3a\xb7\x60\x3e\x34\xd6\xb0\xd7\x05\x28\xa5\x6d\x27\x1d\x17\xf0\x2f\x4e\x6d\x94\x5a\xd5\xb0\xce\x4b\x06\xb6\x08\xa9\xe7\xbd\x83\xba\x86\x5b\xc7\xd2\x0e\xed\x5a\x11\xfe\xef\x05\x90\x4c\x53\x25\x89\xfd\xb2\x12\x4c\xf7\xe3\xa5\x71\xc9\xda\x44\xc1\x3b\x2f\x26\x9a\x9b\x5e\x9f\xb0\x5c\x4b\x99\xc4\x4b\xe1\xb3\x44\x0a\x19\x22\xd5\x5e\xf1\x99\xb5\xc5\xfb\x62\x25\x8d\x21\xb5\xe1\x9d\x4c\x3c\xa2\x7f\xef\x4f\xa5\x54\xb2\x21\x9a\x3c\x56\x43\x21\xa2\x3a\xe0\xe5\x38\xbe\x48\xc3\x79\x8d\x1f\x64\xb7\x7d\x8d\x13\xd3\xd0\xa7\x7b\xff\x89\x3e\x47\xff\xee\xd1\x50\x22\x20\x64\x8e\x85\xb9\x5c\xcb\x5b\x2c\x77\xf0\x10\x87\x84\xf8\x02\xfb\xad\xf7\x16\xd5\xa5\x96\x78\xe0\xdf\x9e\xe2\xd3\x82\x00\xec\x7c\xe8\x54\x5c\xc9\xe6\x78\xf3\xe3\x33\xa0\xb2\x55\xda\xbc\xed\x67\xa0\xc3\x72\xfe\xa8\xe2\xfe\x33\x01\x6e\x26\xa6\x63\x91\xca\xeb\xe2\xc3\x09\xf6\xde\xea\x71\x6e\xc7\x6d\xce\xff\x9f\x40\xc2\xb0\xe1\x65\xaf\x67\x84\xd9\x5e\x9f\x53\xbe\x5a\x90\xb2\x0d\x1e\x32\xc8\x57\x54\xf2\x6a\xeb\xcb\x88\x73\x04\x93\x65\x55\x3d\x4e\xce\xe2\x59\x4f\x92\x15\xc3\xaf\x73\x0c\xa9\x30\xe7\x6f\x94\x20\xfd\x5d\x24\xe7\x69\x52\x4d\x83\x3d\xef\x8a\x0f\x97\xef\xfe\xab\x57\xb3\x47\x3c\x1f\x79\xe0\xb5\x29\x1f\x31\xf0\xfb\x1f\x8b\x82\x8a\xfa\x61\x7c\x9a\x45\xf8\x1f\x8f\xbb\x45\x7d\x3e\x09\x00\x00")
+
+func go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsisnapshots_yaml() ([]byte, error) {
+	return bindata_read(
+		_go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsisnapshots_yaml,
+		"go/src/github.com/minio/direct-csi/config/crd/direct.csi.min.io_directcsisnapshots.yaml",
+	)
+}
+
+var _go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\x03\xed\x5c\x5b\x6f\x1b\xb9\x15\x7e\xf7\xaf\x20\xbc\x05\x12\xa7\x9a\x51\x94\x14\xe9\xae\x80\x20\x08\x9c\xa6\x08\xb2\x29\xd2\xb5\x37\x0f\xb5\xdd\x2e\x35\x43\x49\x5c\xcf\x90\x13\x92\xe3\x58\x5b\xf4\xbf\xef\x77\xc8\x19\xcd\xc8\x9a\x51\x9c\x60\xf3\x54\xf2\xc5\x12\x2f\x87\x87\xe7\xf2\x9d\x8b\x01\x1d\x25\x49\x72\xc4\x2b\xf9\x41\x18\x2b\xb5\x9a\x33\x7c\x16\xb7\x4e\x28\xfa\x66\xd3\xeb\xef\x6d\x2a\xf5\xf4\x66\x76\x74\x2d\x55\x3e\x67\xa7\xb5\x75\xba\xfc\x49\x58\x5d\x9b\x4c\xbc\x12\x4b\xa9\xa4\xc3\xce\xa3\x52\x38\x9e\x73\xc7\xe7\x47\x8c\x71\xa5\xb4\xe3\x34\x6d\xe9\x2b\x63\x99\x56\xce\xe8\xa2\x10\x26\x59\x09\x95\x5e\xd7\x0b\xb1\xa8\x65\x91\x0b\xe3\x89\xb7\x57\xdf\x3c\x4e\x9f\xa5\x33\x9c\xc8\x8c\xf0\xc7\xcf\x65\x29\xac\xe3\x65\x35\x67\xaa\x2e\x0a\xac\x28\x5e\x8a\x39\xcb\xa5\x11\x99\xcb\xac\xbc\xd1\x45\x8d\x2d\x69\x98\x48\x31\x93\x96\x52\x81\xe8\x91\xad\x44\x46\x97\xaf\x8c\xae\xab\xf6\x44\x7f\x43\xa0\xd5\x30\x18\x1e\xf7\xca\x6f\x3a\x3d\x7b\xf3\xc1\x93\xf5\x2b\x85\xb4\xee\xed\xd0\xea\x8f\x58\xf0\x3b\xaa\xa2\x36\xbc\xd8\x67\xca\x2f\x5a\xa9\x56\x75\xc1\xcd\xde\x32\x56\x6d\xa6\x2b\x3c\xe6\xb4\x80\x4c\x85\xc1\x44\x23\x08\xcf\x53\xd2\x3c\xf5\x66\xc6\x8b\x6a\xcd\x67\x81\x5a\xb6\x16\x25\x0f\x2c\x33\x86\xd3\xea\xe5\xfb\x37\x1f\x9e\x9e\xed\x4c\x83\x23\x83\x25\xe3\x64\xfb\xba\x30\x7a\x4a\xee\xcd\x32\x96\x0b\x9b\x19\x59\x39\xaf\x82\x07\x44\x30\xec\xc2\x02\xb4\x2b\x2c\x73\x6b\xd1\xb2\x26\xf2\x86\x07\xa6\x97\x98\x97\x96\x19\x51\x19\x61\x85\x0a\xfa\xde\x21\xcc\x68\x13\x57\x4c\x2f\x7e\x25\xd9\xb3\x33\x61\x88\x0c\xb3\x6b\x5d\x17\x39\x19\x05\xbe\x3a\x50\xc8\xf4\x4a\xc9\xdf\xb6\xb4\x71\xa3\xf6\x97\x16\xdc\x89\x46\xc8\xdd\x90\x0a\xc2\x52\xbc\x60\x37\xbc\xa8\xc5\x04\x17\xe4\xac\xe4\x1b\x90\xa1\x5b\x58\xad\x7a\xf4\xfc\x16\x9b\xb2\x77\xda\x08\x1c\x5c\xea\x39\x5b\x3b\x57\xd9\xf9\x74\xba\x92\xae\x35\xee\x4c\x97\x65\x0d\x33\xde\x4c\xbd\x9d\xca\x45\xed\xb4\xb1\xd3\x5c\xdc\x88\x62\x6a\xe5\x2a\xe1\x26\x5b\x4b\x07\xea\xb5\x11\x53\x88\x31\xf1\xac\x2b\x6f\xe0\x69\x99\x7f\x67\x1a\x77\xb0\x0f\x76\x78\x75\x1b\x52\xaf\x05\x45\xb5\xea\x2d\x78\x5b\x3b\xa0\x01\xb2\x36\x06\xc9\xf2\xe6\x68\x78\x45\x27\x68\x9a\x22\xe9\xfc\xf4\xb7\xb3\x73\xd6\x5e\xed\x95\x71\x57\xfa\x5e\xee\xdd\x41\xdb\xa9\x80\x04\x06\x79\x08\x13\x94\xb8\x34\xba\xf4\x34\x85\xca\x2b\x0d\x09\xfb\x2f\x59\x21\x71\xea\x0e\x51\x5b\x2f\x4a\xe9\x48\xef\x1f\x21\x5a\x47\xba\x4a\xd9\xa9\xf7\x78\xb6\x10\xac\xae\x00\x02\x22\x4f\xd9\x1b\x85\xd9\x52\x14\xa7\xdc\x8a\x6f\xae\x00\x92\xb4\x4d\x48\xb0\xf7\x53\x41\x1f\xac\xee\x6e\x0e\x52\xeb\x2d\x00\x80\x5c\x6d\x77\xb7\x0e\x7b\x18\x0d\x7e\xc3\x65\xc1\x17\x85\x38\xe5\x15\xcf\xf0\xa6\xbb\x1b\x18\x5b\x6a\x53\x72\x37\x27\x4b\x7e\xf6\x97\xbd\xd5\xc0\x05\x59\xf9\xca\x83\x42\x7f\xe0\xd9\xb9\xec\xe1\x6a\x7f\x40\x3e\xe5\xc0\xf4\x1d\xeb\x3a\x3e\x6d\x49\x78\x50\xe6\x52\x59\x6c\xc0\xdf\xc2\x12\x5f\x0c\x2e\xce\x38\x61\xa7\x0b\x1e\x0e\x2b\xa8\x8d\xd9\x37\x83\x4e\x34\x62\x0b\x05\x80\x0e\xd6\x46\x86\x94\x21\xae\xb0\x73\x9a\x86\xf4\x6b\x90\xc3\x27\x7a\x94\xca\xe1\x97\x74\x53\xc0\xc3\x41\xb2\xb5\x25\x26\x08\x3a\xb8\x31\xb0\x54\x1e\xec\x71\x29\x05\x60\xa3\xe2\x6e\xcd\xd2\xa0\x94\xb4\x13\x48\xca\xd8\x6b\x50\x15\xb7\x88\x16\x85\x98\x0c\xd2\x25\xd1\x62\x97\x3e\xf3\x87\x1b\xc6\xfe\xeb\x97\xa6\x53\xb0\xde\xfa\x89\xbf\x4d\x2f\x2c\x9c\x25\x44\x31\x0f\x64\x83\x24\x97\x5a\x3f\xb0\xad\x8c\x82\x3c\xd2\x96\xe0\x5b\xa5\x3f\xa9\x21\x56\x3d\x1f\xdc\x88\x21\x6d\x31\x76\x79\xfc\xb2\xb5\xa1\xcb\xe3\x09\xbe\xbe\x37\x7a\x05\xce\x28\x94\xd0\x04\x01\xde\xe5\xf1\x2b\xb1\x32\x1c\xb2\xbc\x3c\x6e\xaf\xfb\x33\x24\x93\xad\xdf\x09\xb3\x12\x6f\xc5\xe6\x39\x5d\x32\x4c\x7f\x67\xff\x99\x33\xe0\x79\xb5\x79\x5e\xd2\xc1\x2d\x2d\x0a\x7b\xe7\xa0\xf0\xbc\xe4\xd5\xce\xe4\x3b\x5e\x7d\x9e\xfa\xd6\xc8\x2c\xbb\xb8\x22\x67\xbb\x99\xa5\x9d\xe1\xfd\xf2\xab\x85\x29\x5e\x1e\x77\x12\x99\xe8\x92\xcc\xb7\x72\x9b\xcb\xe3\x41\xaa\x3b\xac\xe2\xa8\x67\x16\x4f\xdf\x79\x32\xe6\x89\x2d\x9a\x36\xda\xe9\x45\xbd\xc4\xcc\x62\x83\x18\x32\x99\x4d\x80\x82\x13\x8a\xa8\xcf\xbb\x5b\x2f\x8f\x7f\x19\x7e\x82\x6a\x5f\xac\x61\x08\x26\xd8\x9d\x65\xff\x1b\x62\x6d\x1c\x08\xc2\x28\x38\xe4\x68\x38\xb2\xa9\x36\x9f\x19\xde\x77\xc7\x4d\xf7\x8f\x91\xff\x84\x98\x68\xe1\x0d\x34\xe1\x9d\xb3\x7d\xcc\x08\x51\xd8\xfc\x96\x0a\xf9\x1d\xe1\x3c\xb9\x78\xb0\x49\x8a\xb3\x5c\xf9\x47\xa6\x8d\xaf\x86\xd0\x0c\x20\xff\xb4\x16\x07\x88\xe2\xea\x1a\x9e\x6c\x8a\x0d\x45\xa3\xac\xc3\x94\x35\x57\x2b\x82\x7f\xf6\x86\x40\x81\x7b\xb7\xa7\xd0\x70\x4d\xbe\x30\xa1\x83\xe3\x54\x6b\xdb\x86\x36\xff\x3e\xe2\xc0\x7f\x23\x5c\x09\xbe\xdf\x90\xf7\xd1\x31\xcb\x44\xe5\xc8\x49\xd2\x11\x82\x2d\xcc\x52\x40\x4a\x88\xe2\xc8\xbe\x91\x18\xd1\x0d\xe4\x72\x96\xaf\xee\xa7\xb8\x66\x6f\x88\xdf\xeb\xba\x04\x86\x21\x97\xcd\x89\xcf\x6e\x0d\xd2\xca\xb8\x1b\xbb\x2e\xd0\x0c\x90\xcc\x17\xba\x0e\xe0\xd7\xe9\xb1\x51\x15\x85\x70\xe8\x09\x17\x78\xc7\x69\x1e\x30\x26\x8c\x92\xdf\xfe\x28\xd4\xca\xad\xe7\xec\xe9\x93\xbf\x3e\xfb\xfe\x6b\x65\x11\x50\x51\xe4\x7f\x17\x4a\x18\x0f\x8e\xf7\x12\xcb\xfe\xb1\x5e\x5a\xe2\xdf\x97\xb6\x31\x39\x5d\x6d\xf7\x1c\xb0\xbf\x26\x24\x74\x96\xf7\x09\x01\xc3\x0a\xe4\x20\xc8\x37\x72\xa4\x21\x24\x27\x0a\x08\x08\x70\x8e\xab\x0c\x89\xa2\x5c\x7e\xd9\x25\x72\x8b\xeb\xc5\x86\xcd\x9e\x4c\xd8\xa2\x51\xc5\x3e\xa2\x5f\xdc\x5e\xa5\xfb\x4f\x3c\x44\xf9\x87\xc9\x1d\xfe\x31\x47\xaa\x46\xa0\x21\x7b\x65\x9f\x24\xa2\x1c\xe4\xe3\x23\x71\x93\x0e\x1f\x8a\xc4\x34\x7a\xd1\x58\x6c\xdf\xfd\x39\xef\x18\x4e\x42\xc2\x40\xa5\x24\xcb\xba\x9c\xb3\xc7\x07\xcd\x65\x38\x57\x09\x03\xc6\x6f\xef\x69\x23\x61\x6b\x97\x96\x70\x02\x57\x04\xb9\x12\x7c\xca\x8c\xc9\x9c\x12\x3e\xe0\x80\xb9\x8f\x03\x91\x08\x1a\x82\x94\x6c\xec\xc8\x1a\x01\x3b\xa0\x68\xcf\xa5\x10\x63\xf3\x3a\x43\x6a\x3c\x4a\x11\x72\x25\x6d\x80\x83\xac\xa7\x36\x9f\x79\x7a\x5f\x0c\xd5\x12\x12\x10\x52\xd9\xb6\xf6\xa0\x68\x3d\x4a\xb2\x14\x5c\xe1\x11\xb6\x61\x91\x12\x71\x82\xb9\x10\xe2\x01\x7f\x3e\xfa\xf8\xea\xab\xa1\x65\xfc\x2b\x2c\x44\x61\xc4\x38\x59\xce\x56\x35\xc7\xdb\x9c\x00\x1b\x00\x4f\x02\x8c\x86\x46\x0f\xe0\x79\x97\x9f\x7f\x06\x3b\x58\x00\x9c\x00\xc1\xf4\xd4\x26\xd7\xf7\xb8\x73\x0f\xc0\x99\x3d\x7e\x72\xc0\xc2\xb6\xbb\x46\xb6\x20\xc4\x53\xc1\x37\x67\xff\xbe\x78\x99\xfc\x8b\x27\xbf\x5d\x3d\x6c\x3e\x3c\x4e\x7e\xf8\xcf\x64\x7e\xf5\xa8\xf7\xf5\xea\xe4\xc5\x9f\xbe\x16\xda\x86\xf2\xfc\x6e\xec\x98\x6a\x13\x3e\xdb\x0c\xb9\xb5\x86\x89\x8f\xad\x98\x3d\x37\x54\x99\xbe\xe6\x85\xc5\x9f\x9f\x95\x0f\x7e\x63\x82\x12\x0a\x1e\x36\xb2\x96\xb0\x63\x22\x35\x9c\x13\xf9\x65\x7f\xc7\xf8\x7a\x73\xf7\xd7\x8a\xc4\x6f\xb8\x8f\x40\x7c\x46\x8b\x87\xf7\xf0\xac\x57\xff\x31\x8f\xc3\x94\x2b\xa7\x4d\x7e\x0e\xec\x2c\xa7\x5d\x7d\x38\x6a\x78\x54\x44\xbc\xe3\x6a\xc3\x3a\xb0\x0d\xd9\xf3\x5d\x8f\xb0\x8e\xf2\x6f\x9e\x19\x6d\xed\xb6\x28\x1e\x77\xe6\x42\x5e\x23\xaf\x68\xd3\xec\x00\xed\x0b\x91\x71\x5f\x79\x98\x85\x04\x34\x98\x4d\xaf\xdc\x62\x19\xe2\x2c\x95\xb7\x56\x2c\xeb\x62\x94\xec\x43\x2b\x10\x1e\x94\xce\xc5\x7e\x8c\x38\x09\x88\xcf\x17\xb2\x40\x55\x48\x98\x9e\x0b\xac\x2e\x0b\xe9\x8b\xa3\xf1\x60\x51\x56\xda\x00\xca\x5d\x70\x63\x03\xa8\xbd\x45\xb1\x07\x07\x43\xea\x0b\x11\xc0\x33\x1f\xe6\xca\xce\x66\x4f\x9e\x9e\xd5\x8b\x5c\x97\x00\xcf\xd7\xa5\x9b\x9e\xbc\x78\xf8\xb1\xe6\x05\x21\x66\xfe\x0f\x48\x1a\x73\x27\xf7\x48\x0e\x66\xcf\x3e\xeb\x87\x0f\x2f\x82\xb7\xc1\x11\x93\xe6\xd3\xa3\x76\x0a\xb7\x5e\xa6\x07\xd7\x4f\x1e\x11\x6b\x3d\x1f\xbe\xba\x48\x3a\x07\x4e\xaf\x1e\x9d\xbc\xe8\xad\x9d\x7c\xa5\x3b\x53\x7b\x02\x05\x66\x3e\x64\xbd\xc9\x40\x7a\x3d\xb8\xad\x49\xd8\x06\xd7\x42\x70\x19\x5c\x0a\xaa\x1f\x5c\x1a\x29\x9b\x46\x3a\x0f\xfd\x45\x5f\x09\xef\xad\xdd\x26\xd4\x4b\x35\x4a\xa0\xc8\x49\xa8\x3c\x4b\x50\xaf\x25\xd7\x62\x33\x80\x63\x23\xb7\xef\x93\x08\x17\x82\xd0\x7e\xf7\x81\x22\xb3\x30\xef\x51\x82\xef\xd3\x3f\xa0\x91\xdc\xc8\x9b\x01\x20\x39\x70\x62\xad\xad\xfb\xe2\x6b\xc8\xf1\xc8\xd4\xbf\xe8\x10\xb4\xb5\xc2\xec\x17\x5f\xe6\xb4\xe3\xc5\xb7\x68\xf2\x00\x63\xf2\x3f\x9e\xee\xa0\x89\xed\x7b\x49\xb2\xed\x8d\x1d\x8d\x9e\x0c\x79\x2e\x40\x1f\xa1\x29\x4c\x38\x6d\xa8\x40\x62\x4b\x8a\x46\x3b\xcd\xeb\x05\xa8\xc5\xde\x75\x3b\x62\xef\x3a\xf6\xae\x63\xef\x3a\xf6\xae\x63\xef\x3a\xf6\xae\x63\xef\x3a\xf6\xae\x63\xef\x3a\xf6\xae\x63\xef\x9a\x46\xec\x5d\xf7\x38\x8b\xbd\xeb\xd8\xbb\xde\x19\xb1\x77\xbd\x37\x62\xef\x3a\xf6\xae\x69\xc4\xde\x75\xec\x5d\xc7\xde\x75\xec\x5d\xb3\x6f\xd3\xbb\x7e\x12\x7b\xd7\xcd\x88\xbd\xeb\xd8\xbb\x8e\xbd\xeb\xd8\xbb\x8e\xbd\xeb\xd8\xbb\x8e\xbd\xeb\xd8\xbb\x8e\xbd\xeb\xd8\xbb\x8e\xbd\x6b\x1a\xb1\x77\xdd\xe3\x2c\xf6\xae\x63\xef\x7a\x67\xc4\xde\xf5\xde\x88\xbd\xeb\xd8\xbb\xa6\x11\x7b\xd7\xb1\x77\xfd\x7f\xdb\xbb\xfe\x58\x6b\xc7\x7f\x56\x42\x21\x08\x67\x43\x06\xb3\x03\x74\xff\xdc\xdd\x4d\xde\x47\x59\xe7\xb6\x56\x09\x3f\xaa\x11\xb2\x51\xc7\xa9\x56\xa1\x4c\x0e\x99\xdd\x70\xed\xe8\x1b\x74\x9e\x03\x66\xeb\x8a\x1c\x7d\xc2\x40\x2a\x43\xfe\x86\xbc\xb8\x6e\x4b\x09\x0a\xb8\x85\xa4\x1a\x39\x6f\x93\xc1\xa6\xff\x36\x98\x06\x64\x4d\xeb\x69\x44\x0c\x0b\xad\x0b\x64\x1e\x7f\x58\x0f\xbf\xd2\x85\x5e\x0d\xf4\xb9\x78\x1e\xa0\x8f\x17\xef\x0f\x56\xc9\x07\x5d\x79\x47\xfa\xe7\xcd\x5d\x30\x0f\xaa\xc8\x7d\x5d\xd4\x4e\x59\xb1\x2a\x7d\x35\x61\x91\x19\x5a\x42\x3d\xb6\xd8\x97\x40\xc8\x0c\xbd\x3d\x52\x5b\x09\x2a\xb8\x91\xd4\xd8\x1e\xba\xfa\x80\x57\xc6\x7f\x5c\xec\xff\xe3\xc2\xcf\x74\x49\x54\x28\xd0\x43\xec\xd9\xf9\x75\x9b\xe3\x90\xae\xb4\x3f\x57\xe3\xbf\xf6\x1a\x9b\xec\xe2\xea\x28\x50\x15\xf9\x87\xf6\x87\x68\x68\xf2\x77\x27\x97\xd9\xef\x22\x48\x00\x00")
 
 func go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml() ([]byte, error) {
 	return bindata_read(
@@ -81,8 +90,9 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() ([]byte, error){
-	"go/src/github.com/minio/direct-csi/config/crd/direct.csi.min.io_directcsidrives.yaml":  go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml,
-	"go/src/github.com/minio/direct-csi/config/crd/direct.csi.min.io_directcsivolumes.yaml": go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml,
+	"go/src/github.com/minio/direct-csi/config/crd/direct.csi.min.io_directcsidrives.yaml":    go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml,
+	"go/src/github.com/minio/direct-csi/config/crd/direct.csi.min.io_directcsisnapshots.yaml": go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsisnapshots_yaml,
+	"go/src/github.com/minio/direct-csi/config/crd/direct.csi.min.io_directcsivolumes.yaml":   go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml,
 }
 
 // AssetDir returns the file names below a certain
@@ -133,8 +143,9 @@ var _bintree = &_bintree_t{nil, map[string]*_bintree_t{
 					"direct-csi": {nil, map[string]*_bintree_t{
 						"config": {nil, map[string]*_bintree_t{
 							"crd": {nil, map[string]*_bintree_t{
-								"direct.csi.min.io_directcsidrives.yaml":  {go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml, map[string]*_bintree_t{}},
-								"direct.csi.min.io_directcsivolumes.yaml": {go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml, map[string]*_bintree_t{}},
+								"direct.csi.min.io_directcsidrives.yaml":    {go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsidrives_yaml, map[string]*_bintree_t{}},
+								"direct.csi.min.io_directcsisnapshots.yaml": {go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsisnapshots_yaml, map[string]*_bintree_t{}},
+								"direct.csi.min.io_directcsivolumes.yaml":   {go_src_github_com_minio_direct_csi_config_crd_direct_csi_min_io_directcsivolumes_yaml, map[string]*_bintree_t{}},
 							}},
 						}},
 					}},
@@ -42,7 +42,11 @@ var drivesCmd = &cobra.Command{
 func init() {
 	drivesCmd.AddCommand(listDrivesCmd)
 	drivesCmd.AddCommand(formatDrivesCmd)
+	drivesCmd.AddCommand(adoptDrivesCmd)
 	drivesCmd.AddCommand(drivesAccessTierCmd)
 	drivesCmd.AddCommand(releaseDrivesCmd)
 	drivesCmd.AddCommand(unreleaseDrivesCmd)
+	drivesCmd.AddCommand(cordonDrivesCmd)
+	drivesCmd.AddCommand(uncordonDrivesCmd)
+	drivesCmd.AddCommand(discoverDrivesCmd)
 }
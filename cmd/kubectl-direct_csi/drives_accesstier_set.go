@@ -20,7 +20,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/utils"
@@ -68,7 +67,7 @@ func init() {
 func setAccessTier(ctx context.Context, args []string) error {
 	if !all {
 		if len(drives) == 0 && len(nodes) == 0 && len(status) == 0 {
-			return fmt.Errorf("atleast one of '%s', '%s', '%s' or '%s' should be specified",
+			return newUsageError("atleast one of '%s', '%s', '%s' or '%s' should be specified",
 				utils.Bold("--all"),
 				utils.Bold("--drives"),
 				utils.Bold("--nodes"),
@@ -77,7 +76,7 @@ func setAccessTier(ctx context.Context, args []string) error {
 	}
 
 	if len(args) != 1 {
-		return fmt.Errorf("Invalid input arguments. Please use '%s' for examples to set access-tiers", utils.Bold("--help"))
+		return newUsageError("Invalid input arguments. Please use '%s' for examples to set access-tiers", utils.Bold("--help"))
 	}
 
 	accessT, err := utils.ValidateAccessTier(args[0])
@@ -88,12 +87,11 @@ func setAccessTier(ctx context.Context, args []string) error {
 	directClient := utils.GetDirectCSIClient()
 	driveList, err := directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newAPIError(err)
 	}
 
 	if len(driveList.Items) == 0 {
-		klog.Errorf("No resource of %s found\n", bold("DirectCSIDrive"))
-		return fmt.Errorf("No resources found")
+		return errNoDrives
 	}
 
 	filterDrives := []directcsi.DirectCSIDrive{}
@@ -20,9 +20,9 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -68,7 +68,7 @@ func init() {
 func unsetAccessTier(ctx context.Context, args []string) error {
 	if !all {
 		if len(drives) == 0 && len(nodes) == 0 && len(status) == 0 && len(accessTiers) == 0 {
-			return fmt.Errorf("atleast one of '%s', '%s', '%s', '%s', or '%s' should be specified",
+			return newUsageError("atleast one of '%s', '%s', '%s', '%s', or '%s' should be specified",
 				utils.Bold("--all"),
 				utils.Bold("--drives"),
 				utils.Bold("--nodes"),
@@ -80,15 +80,14 @@ func unsetAccessTier(ctx context.Context, args []string) error {
 	directClient := utils.GetDirectCSIClient()
 	driveList, err := directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newAPIError(err)
 	}
 
 	if len(driveList.Items) == 0 {
-		klog.Errorf("No resource of %s found\n", bold("DirectCSIDrive"))
-		return fmt.Errorf("No resources found")
+		return errNoDrives
 	}
 
-	accessTierSet, aErr := getAccessTierSet(accessTiers)
+	accessTierSet, aErr := client.ParseAccessTiers(accessTiers)
 	if aErr != nil {
 		return aErr
 	}
@@ -0,0 +1,215 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	checkFilesystem  = false
+	repairFilesystem = false
+)
+
+var adoptDrivesCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "adopt xfs formatted drives into the DirectCSI cluster without formatting them",
+	Long: `
+Available drives with an existing xfs filesystem are mounted by their node as is,
+the data on them is retained. The drives must not be mounted elsewhere.`,
+	Example: `
+# Adopt all xfs formatted drives in the cluster
+$ kubectl direct-csi drives adopt --all
+
+# Adopt all xfs formatted drives from a particular node
+$ kubectl direct-csi drives adopt --nodes=directcsi-1
+
+# Adopt a drive by it's drive-id
+$ kubectl direct-csi drives adopt <drive_id>
+
+# Adopt all xfs formatted drives in a node and wait for them to be ready
+$ kubectl direct-csi drives adopt --nodes=directcsi-1 --wait
+
+# Adopt a drive after an unclean shutdown only if its filesystem has no errors
+$ kubectl direct-csi drives adopt <drive_id> --check
+
+# Adopt a drive after repairing the errors of its filesystem
+$ kubectl direct-csi drives adopt <drive_id> --repair
+`,
+	RunE: func(c *cobra.Command, args []string) error {
+		return adoptDrives(c.Context(), args)
+	},
+	Aliases: []string{},
+}
+
+func init() {
+	adoptDrivesCmd.PersistentFlags().StringSliceVarP(&drives, "drives", "d", drives, "glog selector for drive paths")
+	adoptDrivesCmd.PersistentFlags().StringSliceVarP(&nodes, "nodes", "n", nodes, "glob selector for node names")
+	adoptDrivesCmd.PersistentFlags().BoolVarP(&all, "all", "a", all, "adopt all available xfs drives")
+	adoptDrivesCmd.PersistentFlags().StringSliceVarP(&accessTiers, "access-tier", "", accessTiers,
+		"adopt based on access-tier set. The possible values are hot|cold|warm")
+	adoptDrivesCmd.PersistentFlags().BoolVarP(&waitReady, "wait", "", waitReady, "wait for the drives to be mounted")
+	adoptDrivesCmd.PersistentFlags().DurationVarP(&waitTimeout, "timeout", "", waitTimeout, "maximum duration to wait for the drives, used with --wait")
+	adoptDrivesCmd.PersistentFlags().BoolVarP(&checkFilesystem, "check", "", checkFilesystem, "check the filesystem with 'xfs_repair -n' and do not mount it if it has errors")
+	adoptDrivesCmd.PersistentFlags().BoolVarP(&repairFilesystem, "repair", "", repairFilesystem, "check the filesystem and repair its errors with 'xfs_repair' before mounting it")
+	adoptDrivesCmd.PersistentFlags().Int64VarP(&reservedPercent, "reserved-percent", "", reservedPercent, "percentage of the drive capacity kept free of volumes as filesystem headroom, between 0 and 50")
+}
+
+// requestedRepair returns the check or the repair of the existing filesystems requested by the flags
+func requestedRepair() (string, error) {
+	if checkFilesystem && repairFilesystem {
+		return "", newUsageError("only one of '%s' or '%s' should be specified",
+			utils.Bold("--check"),
+			utils.Bold("--repair"))
+	}
+	switch {
+	case checkFilesystem:
+		return directcsi.DirectCSIDriveRepairCheck, nil
+	case repairFilesystem:
+		return directcsi.DirectCSIDriveRepairFix, nil
+	}
+	return "", nil
+}
+
+// checkAdoptable returns the reason for the drive not being adoptable
+func checkAdoptable(d directcsi.DirectCSIDrive) error {
+	switch d.Status.DriveStatus {
+	case directcsi.DriveStatusAvailable:
+	case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+		return fmt.Errorf("already owned and managed")
+	default:
+		return fmt.Errorf("in '%s' state. Only available drives can be adopted", d.Status.DriveStatus)
+	}
+	if d.Status.Filesystem != XFS {
+		return fmt.Errorf("does not have a xfs filesystem. Use 'kubectl direct-csi drives format' instead")
+	}
+	if d.Status.Mountpoint != "" {
+		return fmt.Errorf("is mounted at %s. Unmount it before adopting", d.Status.Mountpoint)
+	}
+	return nil
+}
+
+func adoptDrives(ctx context.Context, args []string) error {
+	if !all {
+		if len(drives) == 0 && len(nodes) == 0 && len(accessTiers) == 0 && len(args) == 0 {
+			return newUsageError("atleast one of '%s', '%s' or '%s' should be specified",
+				utils.Bold("--all"),
+				utils.Bold("--drives"),
+				utils.Bold("--nodes"))
+		}
+	}
+
+	repair, err := requestedRepair()
+	if err != nil {
+		return err
+	}
+	if err := validateReservedPercent(reservedPercent); err != nil {
+		return newUsageError("%v", err)
+	}
+
+	directClient := utils.GetDirectCSIClient()
+
+	var driveCh <-chan directcsi.DirectCSIDrive
+	if len(args) > 0 {
+		driveCh = getDrivesByIds(ctx, args)
+	} else {
+		driveCh = getDrives(ctx, nodes, drives, accessTiers)
+	}
+
+	wg := sync.WaitGroup{}
+	requestedDrives := map[string]string{}
+	var requestedDrivesMutex sync.Mutex
+	accessTierSet, aErr := client.ParseAccessTiers(accessTiers)
+	if aErr != nil {
+		return aErr
+	}
+	for d := range driveCh {
+		if !d.MatchGlob(nodes, drives, status) {
+			continue
+		}
+
+		if !d.MatchAccessTier(accessTierSet) {
+			continue
+		}
+
+		if d.Status.DriveStatus == directcsi.DriveStatusUnavailable {
+			continue
+		}
+
+		path := canonicalNameFromPath(d.Status.Path)
+		driveAddr := fmt.Sprintf("%s:/dev/%s", d.Status.NodeName, path)
+
+		if err := checkAdoptable(d); err != nil {
+			klog.Errorf("%s %v", utils.Bold(driveAddr), err)
+			continue
+		}
+
+		if d.Annotations == nil {
+			d.Annotations = map[string]string{}
+		}
+		d.Annotations[directcsi.DirectCSIDriveAnnotationAdopt] = "true"
+		if repair != "" {
+			d.Annotations[directcsi.DirectCSIDriveAnnotationRepair] = repair
+		}
+		d.Spec.DirectCSIOwned = true
+		d.Spec.RequestedFormat = &directcsi.RequestedFormat{
+			ReservedPercent: reservedPercent,
+		}
+		if dryRun {
+			if err := printer(d); err != nil {
+				klog.ErrorS(err, "error marshaling drives", "format", outputMode)
+			}
+		} else {
+			threadiness <- struct{}{}
+			wg.Add(1)
+			go func(d directcsi.DirectCSIDrive) {
+				defer func() {
+					wg.Done()
+					<-threadiness
+				}()
+
+				if _, err := directClient.DirectCSIDrives().Update(ctx, &d, metav1.UpdateOptions{}); err != nil {
+					klog.ErrorS(err, "failed to adopt drive", "drive", driveAddr)
+					return
+				}
+				requestedDrivesMutex.Lock()
+				requestedDrives[d.Name] = driveAddr
+				requestedDrivesMutex.Unlock()
+			}(d)
+		}
+	}
+	wg.Wait()
+
+	if waitReady && len(requestedDrives) > 0 {
+		return waitForDrivesReady(ctx, requestedDrives, waitTimeout)
+	}
+	return nil
+}
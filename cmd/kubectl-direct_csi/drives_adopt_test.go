@@ -0,0 +1,61 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+)
+
+func TestCheckAdoptable(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		driveStatus directcsi.DriveStatus
+		filesystem  string
+		mountpoint  string
+		expectedErr bool
+	}{
+		{"available-xfs", directcsi.DriveStatusAvailable, XFS, "", false},
+		{"available-ext4", directcsi.DriveStatusAvailable, "ext4", "", true},
+		{"available-unformatted", directcsi.DriveStatusAvailable, "", "", true},
+		{"available-mounted", directcsi.DriveStatusAvailable, XFS, "/mnt/disk1", true},
+		{"ready", directcsi.DriveStatusReady, XFS, "/var/lib/direct-csi/mnt/uuid", true},
+		{"released", directcsi.DriveStatusReleased, XFS, "", true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			drive := directcsi.DirectCSIDrive{
+				Status: directcsi.DirectCSIDriveStatus{
+					DriveStatus: tt.driveStatus,
+					Filesystem:  tt.filesystem,
+					Mountpoint:  tt.mountpoint,
+				},
+			}
+			err := checkAdoptable(drive)
+			if tt.expectedErr && err == nil {
+				t1.Errorf("Test case name %s: expected the drive not to be adoptable", tt.name)
+			}
+			if !tt.expectedErr && err != nil {
+				t1.Errorf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+		})
+	}
+}
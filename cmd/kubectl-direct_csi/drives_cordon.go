@@ -0,0 +1,163 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/spf13/cobra"
+)
+
+var cordonDrivesCmd = &cobra.Command{
+	Use:   "cordon",
+	Short: "mark drives as unschedulable",
+	Long: `
+No new volumes are scheduled on the cordoned drives. The volumes already on them
+keep working until they are deleted.`,
+	Example: `
+ # Cordon all nvme drives in a node
+ $ kubectl direct-csi drives cordon --nodes=directcsi-1 --drives '/dev/nvme*'
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return cordonDrives(c.Context(), true)
+	},
+}
+
+var uncordonDrivesCmd = &cobra.Command{
+	Use:   "uncordon",
+	Short: "mark drives as schedulable",
+	Example: `
+ # Uncordon all drives in a node
+ $ kubectl direct-csi drives uncordon --nodes=directcsi-1
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return cordonDrives(c.Context(), false)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{cordonDrivesCmd, uncordonDrivesCmd} {
+		c.PersistentFlags().StringSliceVarP(&drives, "drives", "d", drives, "glob selector for drive paths")
+		c.PersistentFlags().StringSliceVarP(&nodes, "nodes", "n", nodes, "glob selector for node names")
+		c.PersistentFlags().StringSliceVarP(&status, "status", "s", status, "glob prefix match for drive status")
+		c.PersistentFlags().BoolVarP(&all, "all", "a", all, "select all drives")
+	}
+}
+
+// isDriveCordoned checks whether the drive is excluded from the scheduling of new volumes
+func isDriveCordoned(drive directcsi.DirectCSIDrive) bool {
+	return drive.GetLabels()[utils.UnschedulableLabel] == "true"
+}
+
+// setCordonLabel sets or clears the label excluding the drive from the scheduling of new volumes
+func setCordonLabel(drive *directcsi.DirectCSIDrive, cordon bool) {
+	labels := drive.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if cordon {
+		labels[utils.UnschedulableLabel] = "true"
+	} else {
+		delete(labels, utils.UnschedulableLabel)
+	}
+	drive.SetLabels(labels)
+}
+
+// setDriveCordon sets or clears the cordon of the drive, it returns false if the drive is already in the requested state.
+// The latest drive is updated as the node drivers keep updating the status of the drives
+func setDriveCordon(ctx context.Context, drive directcsi.DirectCSIDrive, cordon bool) (bool, error) {
+	if isDriveCordoned(drive) == cordon {
+		return false, nil
+	}
+	if dryRun {
+		setCordonLabel(&drive, cordon)
+		return true, utils.LogYAML(drive)
+	}
+
+	directClient := utils.GetDirectCSIClient()
+	changed := false
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := directClient.DirectCSIDrives().Get(ctx, drive.Name, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		if err != nil {
+			return err
+		}
+		if changed = isDriveCordoned(*latest) != cordon; !changed {
+			return nil
+		}
+		setCordonLabel(latest, cordon)
+		_, err = directClient.DirectCSIDrives().Update(ctx, latest, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		return err
+	}); err != nil {
+		return false, newAPIError(err)
+	}
+	return changed, nil
+}
+
+func cordonDrives(ctx context.Context, cordon bool) error {
+	if !all {
+		if len(drives) == 0 && len(nodes) == 0 && len(status) == 0 {
+			return newUsageError("atleast one of '%s', '%s', '%s' or '%s' should be specified",
+				utils.Bold("--all"),
+				utils.Bold("--drives"),
+				utils.Bold("--nodes"),
+				utils.Bold("--status"))
+		}
+	}
+
+	driveList, err := utils.GetDirectCSIClient().DirectCSIDrives().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return newAPIError(err)
+	}
+	if len(driveList.Items) == 0 {
+		return errNoDrives
+	}
+
+	updated := 0
+	for _, d := range driveList.Items {
+		if !d.MatchGlob(nodes, drives, status) {
+			continue
+		}
+		changed, err := setDriveCordon(ctx, d, cordon)
+		if err != nil {
+			return err
+		}
+		if changed {
+			updated++
+		}
+	}
+	if !dryRun {
+		action := "cordoned"
+		if !cordon {
+			action = "uncordoned"
+		}
+		fmt.Printf("%d drive(s) %s\n", updated, action)
+	}
+	return nil
+}
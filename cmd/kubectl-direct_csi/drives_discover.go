@@ -0,0 +1,317 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	encodingjson "encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/direct-csi/pkg/installer"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dustin/go-humanize"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/mb0/glob"
+	"github.com/spf13/cobra"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	preview          = false
+	previewTrace     = false
+	previewNamespace = "default"
+	previewTimeout   = 5 * time.Minute
+	// the phase of the preview pod is polled this often until it succeeds or fails
+	previewPollInterval = 2 * time.Second
+)
+
+var discoverDrivesCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "preview the drives direct-csi would find in the nodes",
+	Long:  "",
+	Example: `
+# Preview the drives of all the nodes before installing direct-csi
+$ kubectl direct-csi drives discover --preview
+
+# Preview the drives of a particular node
+$ kubectl direct-csi drives discover --preview --nodes=directcsi-1
+
+# Show the checks which made the drives of a node unavailable
+$ kubectl direct-csi drives discover --preview --trace --nodes=directcsi-1
+
+# Preview the drives which direct-csi would manage with the device filters of the install
+$ kubectl direct-csi drives discover --preview --exclude-devices='/dev/nvme0n1*' --min-drive-size=10GiB
+
+# Run the preview pods from a private registry in a specific namespace
+$ kubectl direct-csi drives discover --preview --registry=registry.local:5000 --namespace=ops
+`,
+	RunE: func(c *cobra.Command, args []string) error {
+		return discoverDrives(c.Context())
+	},
+}
+
+func init() {
+	discoverDrivesCmd.PersistentFlags().BoolVarP(&preview, "preview", "", preview, "print the candidate drives without registering them")
+	discoverDrivesCmd.PersistentFlags().BoolVarP(&previewTrace, "trace", "", previewTrace, "print the attributes read and the checks applied to each drive, used with '--preview'")
+	discoverDrivesCmd.PersistentFlags().StringSliceVarP(&nodes, "nodes", "n", nodes, "glob selector for node names")
+	discoverDrivesCmd.PersistentFlags().StringVarP(&previewNamespace, "namespace", "", previewNamespace, "namespace to run the preview pods in")
+	discoverDrivesCmd.PersistentFlags().StringVarP(&image, "image", "i", image, "direct-csi image")
+	discoverDrivesCmd.PersistentFlags().StringVarP(&registry, "registry", "r", registry, "registry where direct-csi images are available")
+	discoverDrivesCmd.PersistentFlags().StringVarP(&org, "org", "g", org, "organization name where direct-csi images are available")
+	discoverDrivesCmd.PersistentFlags().DurationVarP(&previewTimeout, "timeout", "", previewTimeout, "maximum duration to wait for the preview of a node")
+	discoverDrivesCmd.PersistentFlags().StringSliceVarP(&includeDevices, "include-devices", "", includeDevices, "glob patterns of the device paths to be managed on the nodes, e.g. /dev/sd[b-z]")
+	discoverDrivesCmd.PersistentFlags().StringSliceVarP(&excludeDevices, "exclude-devices", "", excludeDevices, "glob patterns of the device paths to be ignored on the nodes, e.g. /dev/nvme0n1*")
+	discoverDrivesCmd.PersistentFlags().StringVarP(&minDriveSize, "min-drive-size", "", minDriveSize, "size below which the devices on the nodes are not managed, e.g. 512MiB")
+}
+
+type nodeDrivePreview struct {
+	Node string `json:"node"`
+	sys.DrivePreview
+}
+
+func discoverDrives(ctx context.Context) error {
+	if !preview {
+		return newUsageError("the drives are registered by the direct-csi daemon, only '--preview' is supported")
+	}
+	if err := (sys.DeviceFilter{Include: includeDevices, Exclude: excludeDevices}).Validate(); err != nil {
+		return newUsageError("invalid argument. '--include-devices' and '--exclude-devices' must be valid glob patterns err=%v", err)
+	}
+	if _, err := humanize.ParseBytes(minDriveSize); err != nil {
+		return newUsageError("invalid argument. '--min-drive-size' must be a valid size err=%v", err)
+	}
+
+	nodeList, err := utils.GetKubeClient().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	nodeNames := filterNodeNames(nodeList.Items, nodes)
+	if len(nodeNames) == 0 {
+		return newNotFoundError("no matching nodes found")
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	previews := []nodeDrivePreview{}
+	failedNodes := []string{}
+	for _, nodeName := range nodeNames {
+		threadiness <- struct{}{}
+		wg.Add(1)
+		go func(nodeName string) {
+			defer func() {
+				wg.Done()
+				<-threadiness
+			}()
+
+			drivePreviews, err := previewNodeDrives(ctx, nodeName)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				klog.ErrorS(err, "failed to preview the drives", "node", nodeName)
+				failedNodes = append(failedNodes, nodeName)
+				return
+			}
+			for _, drivePreview := range drivePreviews {
+				previews = append(previews, nodeDrivePreview{Node: nodeName, DrivePreview: drivePreview})
+			}
+		}(nodeName)
+	}
+	wg.Wait()
+
+	sort.SliceStable(previews, func(i, j int) bool {
+		if v := strings.Compare(previews[i].Node, previews[j].Node); v != 0 {
+			return v < 0
+		}
+		return strings.Compare(previews[i].Path, previews[j].Path) < 0
+	})
+
+	if err := printDrivePreviews(previews); err != nil {
+		return err
+	}
+
+	if len(failedNodes) > 0 {
+		sort.Strings(failedNodes)
+		return fmt.Errorf("failed to preview the drives of %d node(s): [%s]", len(failedNodes), strings.Join(failedNodes, ", "))
+	}
+	return nil
+}
+
+func filterNodeNames(nodeList []corev1.Node, patterns []string) []string {
+	nodeNames := []string{}
+	for _, node := range nodeList {
+		if len(patterns) == 0 {
+			nodeNames = append(nodeNames, node.Name)
+			continue
+		}
+		for _, pattern := range patterns {
+			if matched, _ := glob.Match(pattern, node.Name); matched {
+				nodeNames = append(nodeNames, node.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(nodeNames)
+	return nodeNames
+}
+
+// previewNodeDrives runs a short-lived pod on the node and reads the drives it found from its logs
+func previewNodeDrives(ctx context.Context, nodeName string) ([]sys.DrivePreview, error) {
+	podClient := utils.GetKubeClient().CoreV1().Pods(previewNamespace)
+	pod := installer.NewDrivePreviewPod("direct-csi-preview-"+nodeName, previewNamespace, nodeName, image, registry, org, includeDevices, excludeDevices, minDriveSize, previewTrace)
+	pod, err := podClient.Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := podClient.Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil {
+			klog.ErrorS(err, "failed to delete the preview pod", "pod", pod.Name)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, previewTimeout)
+	defer cancel()
+	ticker := time.NewTicker(previewPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pod, err = podClient.Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("preview pod %s did not complete within %v", pod.Name, previewTimeout)
+		case <-ticker.C:
+		}
+	}
+
+	logs, err := podClient.GetLogs(pod.Name, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		return nil, fmt.Errorf("preview pod %s failed: %s", pod.Name, lastLine(logs))
+	}
+	return parseDrivePreviews(logs)
+}
+
+// parseDrivePreviews reads the drives from the pod logs, the logs of the pod may be interleaved with the
+// preview which is printed as a single JSON line
+func parseDrivePreviews(logs []byte) ([]sys.DrivePreview, error) {
+	lines := bytes.Split(bytes.TrimSpace(logs), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := bytes.TrimSpace(lines[i])
+		if !bytes.HasPrefix(line, []byte("[")) {
+			continue
+		}
+		previews := []sys.DrivePreview{}
+		if err := encodingjson.Unmarshal(line, &previews); err != nil {
+			return nil, err
+		}
+		return previews, nil
+	}
+	return nil, fmt.Errorf("no drive preview found in the logs: %s", lastLine(logs))
+}
+
+func lastLine(logs []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(logs)), "\n")
+	return lines[len(lines)-1]
+}
+
+func printDrivePreviews(previews []nodeDrivePreview) error {
+	if yaml || json {
+		if err := printer(previews); err != nil {
+			klog.ErrorS(err, "error marshaling drive previews", "format", outputMode)
+			return err
+		}
+		return nil
+	}
+
+	text.DisableColors()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{
+		"NODE",
+		"DRIVE",
+		"CAPACITY",
+		"FILESYSTEM",
+		"LABEL",
+		"MOUNTPOINT",
+		"STATUS",
+		"REASON",
+	})
+
+	style := table.StyleColoredDark
+	style.Color.IndexColumn = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	style.Color.Header = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	t.SetStyle(style)
+
+	for _, p := range previews {
+		capacity := "-"
+		if p.TotalCapacity != 0 {
+			capacity = humanize.IBytes(p.TotalCapacity)
+		}
+		t.AppendRow([]interface{}{
+			p.Node,
+			p.Path,
+			capacity,
+			printableString(p.Filesystem),
+			printableString(p.Label),
+			printableString(p.Mountpoint),
+			utils.Bold(p.DriveStatus),
+			p.Reason,
+		})
+	}
+
+	t.Render()
+
+	if previewTrace {
+		printDriveTraces(previews)
+	}
+	return nil
+}
+
+// printDriveTraces prints the attributes read and the checks applied to each drive
+func printDriveTraces(previews []nodeDrivePreview) {
+	for _, p := range previews {
+		fmt.Printf("\n%s %s: %s\n", p.Node, p.Path, p.DriveStatus)
+		if len(p.Trace) == 0 {
+			fmt.Println("  no trace reported")
+			continue
+		}
+		for _, line := range p.Trace {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/minio/direct-csi/pkg/sys"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseDrivePreviews(t1 *testing.T) {
+	testCases := []struct {
+		name             string
+		logs             string
+		expectedPreviews []sys.DrivePreview
+		expectErr        bool
+	}{
+		{
+			name: "test1",
+			logs: `[{"path":"/dev/sdb","totalCapacity":1024,"driveStatus":"Available"},{"path":"/dev/sda1","driveStatus":"Unavailable","reason":"mounted as the root filesystem"}]` + "\n",
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdb", TotalCapacity: 1024, DriveStatus: "Available"},
+				{Path: "/dev/sda1", DriveStatus: "Unavailable", Reason: "mounted as the root filesystem"},
+			},
+		},
+		{
+			name: "test2",
+			logs: "I0101 00:00:00.000000       1 discovery.go:42] probing drives\n" +
+				`[{"path":"/dev/nvme0n1","driveStatus":"Available"}]` + "\n",
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/nvme0n1", DriveStatus: "Available"},
+			},
+		},
+		{
+			name:             "test3",
+			logs:             "[]\n",
+			expectedPreviews: []sys.DrivePreview{},
+		},
+		{
+			name:      "test4",
+			logs:      "Error: failed to read /sys/class/block\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			previews, err := parseDrivePreviews([]byte(tt.logs))
+			if tt.expectErr {
+				if err == nil {
+					t1.Fatalf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(previews, tt.expectedPreviews) {
+				t1.Errorf("Test case name %s: expected %+v but got %+v", tt.name, tt.expectedPreviews, previews)
+			}
+		})
+	}
+}
+
+func TestFilterNodeNames(t1 *testing.T) {
+	nodeList := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "master-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+	}
+
+	testCases := []struct {
+		name          string
+		patterns      []string
+		expectedNodes []string
+	}{
+		{
+			name:          "test1",
+			expectedNodes: []string{"master-1", "worker-1", "worker-2"},
+		},
+		{
+			name:          "test2",
+			patterns:      []string{"worker-*"},
+			expectedNodes: []string{"worker-1", "worker-2"},
+		},
+		{
+			name:          "test3",
+			patterns:      []string{"master-1", "worker-2"},
+			expectedNodes: []string{"master-1", "worker-2"},
+		},
+		{
+			name:          "test4",
+			patterns:      []string{"edge-*"},
+			expectedNodes: []string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			nodeNames := filterNodeNames(nodeList, tt.patterns)
+			if !reflect.DeepEqual(nodeNames, tt.expectedNodes) {
+				t1.Errorf("Test case name %s: expected %v but got %v", tt.name, tt.expectedNodes, nodeNames)
+			}
+		})
+	}
+}
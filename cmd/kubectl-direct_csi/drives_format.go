@@ -21,9 +21,14 @@ package main
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,7 +41,15 @@ import (
 const XFS = "xfs"
 
 var (
-	force = false
+	force       = false
+	waitReady   = false
+	waitTimeout = 5 * time.Minute
+	blockSize   int64
+	mkfsOptions = []string{}
+	// percentage of the capacity of the drives kept free of volumes
+	reservedPercent int64
+	// --wait re-reads the pending drives this often, a format usually takes a few seconds
+	formatPollInterval = 2 * time.Second
 )
 
 var formatDrivesCmd = &cobra.Command{
@@ -67,6 +80,18 @@ $ kubectl direct-csi drives format <drive_id>
 
 # Format more than one drive by their drive-ids
 $ kubectl direct-csi drives format <drive_id_1> <drive_id_2>
+
+# Format all available drives and wait up to 10 minutes for them to be ready
+$ kubectl direct-csi drives format --all --wait --timeout=10m
+
+# Format the RAID backed LUNs of a node with a 4KiB block size and the stripe geometry of the array
+$ kubectl direct-csi drives format --nodes=directcsi-1 --block-size=4096 --mkfs-options=su=64k,sw=4
+
+# Format all available drives keeping 10% of their capacity free of volumes
+$ kubectl direct-csi drives format --all --reserved-percent=10
+
+# Format the drives of a node, the drives with a xfs filesystem are repaired and mounted as is
+$ kubectl direct-csi drives format --nodes=directcsi-1 --repair
 `,
 	RunE: func(c *cobra.Command, args []string) error {
 		return formatDrives(c.Context(), args)
@@ -81,18 +106,45 @@ func init() {
 	formatDrivesCmd.PersistentFlags().BoolVarP(&force, "force", "f", force, "force format a drive even if a FS is already present")
 	formatDrivesCmd.PersistentFlags().StringSliceVarP(&accessTiers, "access-tier", "", accessTiers,
 		"format based on access-tier set. The possible values are hot|cold|warm")
+	formatDrivesCmd.PersistentFlags().BoolVarP(&waitReady, "wait", "", waitReady, "wait for the drives to be formatted and mounted")
+	formatDrivesCmd.PersistentFlags().DurationVarP(&waitTimeout, "timeout", "", waitTimeout, "maximum duration to wait for the drives, used with --wait")
+	formatDrivesCmd.PersistentFlags().Int64VarP(&blockSize, "block-size", "", blockSize, "filesystem block size in bytes, a power of two between 1024 and 65536 not larger than the page size of the nodes (default 4096)")
+	formatDrivesCmd.PersistentFlags().StringSliceVarP(&mkfsOptions, "mkfs-options", "", mkfsOptions, "mkfs.xfs data section options, one of su|sw|sunit|swidth|agcount=<value>")
+	formatDrivesCmd.PersistentFlags().Int64VarP(&reservedPercent, "reserved-percent", "", reservedPercent, "percentage of the drive capacity kept free of volumes as filesystem headroom, between 0 and 50")
+	formatDrivesCmd.PersistentFlags().BoolVarP(&checkFilesystem, "check", "", checkFilesystem, "mount the drives with a xfs filesystem as is after checking it with 'xfs_repair -n', if it has no errors")
+	formatDrivesCmd.PersistentFlags().BoolVarP(&repairFilesystem, "repair", "", repairFilesystem, "mount the drives with a xfs filesystem as is after repairing its errors with 'xfs_repair'")
 }
 
 func formatDrives(ctx context.Context, args []string) error {
 	if !all {
 		if len(drives) == 0 && len(nodes) == 0 && len(accessTiers) == 0 && len(args) == 0 {
-			return fmt.Errorf("atleast one of '%s', '%s' or '%s' should be specified",
+			return newUsageError("atleast one of '%s', '%s' or '%s' should be specified",
 				utils.Bold("--all"),
 				utils.Bold("--drives"),
 				utils.Bold("--nodes"))
 		}
 	}
 
+	if err := xfs.ValidateBlockSize(blockSize); err != nil {
+		return newUsageError("%v", err)
+	}
+	if err := xfs.ValidateMkfsOptions(mkfsOptions); err != nil {
+		return newUsageError("%v", err)
+	}
+	if err := validateReservedPercent(reservedPercent); err != nil {
+		return newUsageError("%v", err)
+	}
+	repair, err := requestedRepair()
+	if err != nil {
+		return err
+	}
+	if repair != "" && force {
+		return newUsageError("'%s' overwrites the filesystem, it cannot be specified with '%s' or '%s'",
+			utils.Bold("--force"),
+			utils.Bold("--check"),
+			utils.Bold("--repair"))
+	}
+
 	directClient := utils.GetDirectCSIClient()
 
 	var driveCh <-chan directcsi.DirectCSIDrive
@@ -103,7 +155,9 @@ func formatDrives(ctx context.Context, args []string) error {
 	}
 
 	wg := sync.WaitGroup{}
-	accessTierSet, aErr := getAccessTierSet(accessTiers)
+	requestedDrives := map[string]string{}
+	var requestedDrivesMutex sync.Mutex
+	accessTierSet, aErr := client.ParseAccessTiers(accessTiers)
 	if aErr != nil {
 		return aErr
 	}
@@ -135,7 +189,8 @@ func formatDrives(ctx context.Context, args []string) error {
 				utils.Bold(driveAddr))
 			continue
 		}
-		if d.Status.Filesystem != "" && !force {
+		// an existing xfs filesystem is mounted as is if it is checked or repaired
+		if d.Status.Filesystem != "" && !force && (repair == "" || d.Status.Filesystem != XFS) {
 			klog.Errorf("%s already has a fs. Use %s to overwrite",
 				utils.Bold(driveAddr), utils.Bold("--force"))
 			continue
@@ -146,10 +201,22 @@ func formatDrives(ctx context.Context, args []string) error {
 			continue
 		}
 
+		// the requests of an earlier adopt are overridden, the drive would be mounted without formatting it
+		delete(d.Annotations, directcsi.DirectCSIDriveAnnotationAdopt)
+		delete(d.Annotations, directcsi.DirectCSIDriveAnnotationRepair)
+		if d.Status.Filesystem != "" && repair != "" {
+			if d.Annotations == nil {
+				d.Annotations = map[string]string{}
+			}
+			d.Annotations[directcsi.DirectCSIDriveAnnotationRepair] = repair
+		}
 		d.Spec.DirectCSIOwned = true
 		d.Spec.RequestedFormat = &directcsi.RequestedFormat{
-			Filesystem: XFS,
-			Force:      force,
+			Filesystem:      XFS,
+			Force:           force,
+			BlockSize:       blockSize,
+			MkfsOptions:     mkfsOptions,
+			ReservedPercent: reservedPercent,
 		}
 		if dryRun {
 			if err := printer(d); err != nil {
@@ -166,11 +233,78 @@ func formatDrives(ctx context.Context, args []string) error {
 
 				if _, err := directClient.DirectCSIDrives().Update(ctx, &d, metav1.UpdateOptions{}); err != nil {
 					klog.ErrorS(err, "failed to format drive", "drive", driveAddr)
+					return
 				}
+				requestedDrivesMutex.Lock()
+				requestedDrives[d.Name] = driveAddr
+				requestedDrivesMutex.Unlock()
 			}(d)
 		}
 	}
 	wg.Wait()
 
+	if waitReady && len(requestedDrives) > 0 {
+		return waitForDrivesReady(ctx, requestedDrives, waitTimeout)
+	}
 	return nil
 }
+
+// isDriveFormatted checks if the node controller has formatted and mounted the drive
+func isDriveFormatted(drive *directcsi.DirectCSIDrive) bool {
+	for _, condType := range []directcsi.DirectCSIDriveCondition{
+		directcsi.DirectCSIDriveConditionOwned,
+		directcsi.DirectCSIDriveConditionFormatted,
+		directcsi.DirectCSIDriveConditionMounted,
+	} {
+		if !utils.IsConditionStatus(drive.Status.Conditions, string(condType), metav1.ConditionTrue) {
+			return false
+		}
+	}
+	return true
+}
+
+// waitForDrivesReady polls the drives (name to address) until all of them are formatted
+// and mounted, the drives still pending after the timeout are reported in the error
+func waitForDrivesReady(ctx context.Context, driveAddrs map[string]string, timeout time.Duration) error {
+	directClient := utils.GetDirectCSIClient()
+	pending := map[string]string{}
+	for name, addr := range driveAddrs {
+		pending[name] = addr
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(formatPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for name, addr := range pending {
+			drive, err := directClient.DirectCSIDrives().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				klog.V(3).Infof("Error while getting drive %s: %v", addr, err)
+				continue
+			}
+			if isDriveFormatted(drive) {
+				fmt.Printf("%s %s\n", green("READY"), bold(addr))
+				delete(pending, name)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			addrs := []string{}
+			for _, addr := range pending {
+				addrs = append(addrs, addr)
+			}
+			sort.Strings(addrs)
+			for _, addr := range addrs {
+				fmt.Printf("%s %s\n", red("PENDING"), bold(addr))
+			}
+			return fmt.Errorf("%d drive(s) not ready after %v: [%s]", len(addrs), timeout, strings.Join(addrs, ", "))
+		case <-ticker.C:
+		}
+	}
+}
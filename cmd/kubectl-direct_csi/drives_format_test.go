@@ -22,7 +22,9 @@ import (
 	"context"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
@@ -228,3 +230,60 @@ func TestFormatDrivesByAttributes(t1 *testing.T) {
 		})
 	}
 }
+
+func TestWaitForDrivesReady(t1 *testing.T) {
+	createTestDrive := func(name string, formatted bool) *directcsi.DirectCSIDrive {
+		condStatus := utils.BoolToCondition(formatted)
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				Conditions: []metav1.Condition{
+					{Type: string(directcsi.DirectCSIDriveConditionOwned), Status: condStatus},
+					{Type: string(directcsi.DirectCSIDriveConditionFormatted), Status: condStatus},
+					{Type: string(directcsi.DirectCSIDriveConditionMounted), Status: condStatus},
+				},
+			},
+		}
+	}
+
+	formatPollInterval = 10 * time.Millisecond
+	testClientSet := fakedirect.NewSimpleClientset(
+		createTestDrive("d1", true),
+		createTestDrive("d2", false),
+	)
+	utils.SetFakeDirectCSIClient(testClientSet.DirectV1beta2())
+
+	testCases := []struct {
+		name        string
+		driveAddrs  map[string]string
+		expectedErr bool
+	}{
+		{
+			name:       "test1",
+			driveAddrs: map[string]string{"d1": "n1:/dev/xvdb"},
+		},
+		{
+			name:        "test2",
+			driveAddrs:  map[string]string{"d1": "n1:/dev/xvdb", "d2": "n1:/dev/xvdc"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := waitForDrivesReady(context.Background(), tt.driveAddrs, 50*time.Millisecond)
+			if tt.expectedErr {
+				if err == nil || !strings.Contains(err.Error(), "n1:/dev/xvdc") || strings.Contains(err.Error(), "n1:/dev/xvdb") {
+					t1.Errorf("Test case name %s: expected only n1:/dev/xvdc to be pending, got %v", tt.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t1.Errorf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+		})
+	}
+}
@@ -18,15 +18,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
 	"github.com/minio/direct-csi/pkg/utils"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/dustin/go-humanize"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -61,6 +66,27 @@ $ kubectl direct-csi drives drives ls --access-tier="hot"
 
 # Filter all drives with access-tier being set
 $ kubectl direct-csi drives drives ls --access-tier="*"
+
+# Filter all drives reserved for a tenant
+$ kubectl direct-csi drives ls --reserved-for=tenant-1
+
+# Filter all drives reserved for any tenant
+$ kubectl direct-csi drives ls --reserved-for="*"
+
+# Summarize the drives per node
+$ kubectl direct-csi drives ls --all --node-summary
+
+# Summarize the capacity of the ready drives per access-tier
+$ kubectl direct-csi drives ls --by-tier
+
+# List the drives with the most free capacity first
+$ kubectl direct-csi drives ls --sort=-free
+
+# List only the unhealthy drives along with their full messages
+$ kubectl direct-csi drives ls --problems
+
+# Re-render the list whenever the drives change, e.g. while a bulk format is running
+$ kubectl direct-csi drives ls --watch
 `,
 	RunE: func(c *cobra.Command, args []string) error {
 		return listDrives(c.Context(), args)
@@ -70,7 +96,22 @@ $ kubectl direct-csi drives drives ls --access-tier="*"
 	},
 }
 
-var all bool
+const (
+	// clearScreen moves the cursor home and clears the terminal between the renders of --watch
+	clearScreen = "\033[H\033[2J"
+	// watchRenderDelay batches the drive events of a bulk operation into a single render
+	watchRenderDelay = 500 * time.Millisecond
+)
+
+var (
+	all         bool
+	nodeSummary bool
+	byTier      bool
+	problems    bool
+	reservedFor = []string{}
+	sortBy      string
+	watchMode   bool
+)
 
 func init() {
 	listDrivesCmd.PersistentFlags().StringSliceVarP(&drives, "drives", "d", drives, "glob prefix match for drive paths")
@@ -78,57 +119,208 @@ func init() {
 	listDrivesCmd.PersistentFlags().StringSliceVarP(&status, "status", "s", status, "glob prefix match for drive status")
 	listDrivesCmd.PersistentFlags().BoolVarP(&all, "all", "a", all, "list all drives (including unavailable)")
 	listDrivesCmd.PersistentFlags().StringSliceVarP(&accessTiers, "access-tier", "", accessTiers, "filter based on access-tier")
+	listDrivesCmd.PersistentFlags().BoolVarP(&nodeSummary, "node-summary", "", nodeSummary, "summarize the drives per node")
+	listDrivesCmd.PersistentFlags().BoolVarP(&byTier, "by-tier", "", byTier, "summarize the capacity of the ready drives per access-tier")
+	listDrivesCmd.PersistentFlags().StringSliceVarP(&reservedFor, "reserved-for", "", reservedFor, "filter based on the tenant the drives are reserved for")
+	listDrivesCmd.PersistentFlags().BoolVarP(&problems, "problems", "", problems, "list only the unhealthy drives (including unavailable) with their full messages")
+	listDrivesCmd.PersistentFlags().StringVarP(&sortBy, "sort", "", sortBy, "sort drives by one of node|path|capacity|free|allocated|status, prefix with '-' for descending order")
+	listDrivesCmd.PersistentFlags().BoolVarP(&watchMode, "watch", "w", watchMode, "re-render the list whenever the drives change, until interrupted")
+}
+
+// compareDrives orders the drives by node, path and status
+func compareDrives(d1, d2 *directcsi.DirectCSIDrive) int {
+	if v := strings.Compare(d1.Status.NodeName, d2.Status.NodeName); v != 0 {
+		return v
+	}
+	if v := strings.Compare(d1.Status.Path, d2.Status.Path); v != 0 {
+		return v
+	}
+	return strings.Compare(string(d1.Status.DriveStatus), string(d2.Status.DriveStatus))
+}
+
+func compareInt64(v1, v2 int64) int {
+	switch {
+	case v1 < v2:
+		return -1
+	case v1 > v2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortDrives sorts the drives by the given key, falling back to the default
+// ordering for equal values. A '-' prefix sorts the key in descending order.
+func sortDrives(drives []directcsi.DirectCSIDrive, key string) error {
+	descending := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	var compare func(d1, d2 *directcsi.DirectCSIDrive) int
+	switch key {
+	case "":
+	case "node":
+		compare = func(d1, d2 *directcsi.DirectCSIDrive) int {
+			return strings.Compare(d1.Status.NodeName, d2.Status.NodeName)
+		}
+	case "path":
+		compare = func(d1, d2 *directcsi.DirectCSIDrive) int {
+			return strings.Compare(d1.Status.Path, d2.Status.Path)
+		}
+	case "capacity":
+		compare = func(d1, d2 *directcsi.DirectCSIDrive) int {
+			return compareInt64(d1.Status.TotalCapacity, d2.Status.TotalCapacity)
+		}
+	case "free":
+		compare = func(d1, d2 *directcsi.DirectCSIDrive) int {
+			return compareInt64(d1.Status.FreeCapacity, d2.Status.FreeCapacity)
+		}
+	case "allocated":
+		compare = func(d1, d2 *directcsi.DirectCSIDrive) int {
+			return compareInt64(d1.Status.AllocatedCapacity, d2.Status.AllocatedCapacity)
+		}
+	case "status":
+		compare = func(d1, d2 *directcsi.DirectCSIDrive) int {
+			return strings.Compare(string(d1.Status.DriveStatus), string(d2.Status.DriveStatus))
+		}
+	default:
+		return newUsageError("unknown sort key %s; must be one of node|path|capacity|free|allocated|status", key)
+	}
+
+	sort.SliceStable(drives, func(i, j int) bool {
+		d1 := &drives[i]
+		d2 := &drives[j]
+
+		if compare != nil {
+			v := compare(d1, d2)
+			if descending {
+				v = -v
+			}
+			if v != 0 {
+				return v < 0
+			}
+		}
+
+		return compareDrives(d1, d2) < 0
+	})
+	return nil
 }
 
 func listDrives(ctx context.Context, args []string) error {
+	if watchMode {
+		return watchDriveList(ctx, printDrives)
+	}
+	return printDrives(ctx)
+}
+
+// watchDriveList renders the drives and renders them again on every batch of
+// drive events, until the context is canceled on an interrupt
+func watchDriveList(ctx context.Context, render func(ctx context.Context) error) error {
+	renderScreen := func() error {
+		fmt.Print(clearScreen)
+		err := render(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, errNoDrives):
+			// keep watching, the drives show up once the nodes discover them
+			fmt.Println(err)
+			return nil
+		}
+		return err
+	}
+
+	if err := renderScreen(); err != nil {
+		return err
+	}
+	directClient := utils.GetDirectCSIClient()
+	for {
+		watcher, err := directClient.DirectCSIDrives().Watch(ctx, metav1.ListOptions{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return newAPIError(err)
+		}
+		err = consumeDriveEvents(ctx, watcher.ResultChan(), renderScreen)
+		watcher.Stop()
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+		// the watch was closed by the apiserver, e.g. on its timeout, start a new one
+		klog.V(3).Infof("restarting the watch on the drives")
+	}
+}
+
+// consumeDriveEvents calls render once for the events received within
+// watchRenderDelay, so that a bulk update is rendered once instead of per
+// drive. It returns when the context is done or the events are closed.
+func consumeDriveEvents(ctx context.Context, events <-chan watch.Event, render func() error) error {
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				klog.V(3).Infof("error event on the drive watch: %v", apierrors.FromObject(event.Object))
+				return nil
+			}
+			if pending == nil {
+				pending = time.After(watchRenderDelay)
+			}
+		case <-pending:
+			pending = nil
+			if err := render(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// printDrives lists the drives matching the flags in the requested output format
+func printDrives(ctx context.Context) error {
 	directClient := utils.GetDirectCSIClient()
 	driveList, err := directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newAPIError(err)
 	}
 
 	if len(driveList.Items) == 0 {
-		klog.Errorf("No resource of %s found\n", bold("DirectCSIDrive"))
-		return fmt.Errorf("No resources found")
+		return errNoDrives
 	}
 
 	volList, err := directClient.DirectCSIVolumes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newAPIError(err)
 	}
 
-	accessTierSet, aErr := getAccessTierSet(accessTiers)
-	if aErr != nil {
-		return aErr
+	// the drives with problems are mostly unavailable
+	candidateDrives, err := client.FilterDrives(driveList.Items, client.DriveFilter{
+		Nodes:              nodes,
+		Drives:             drives,
+		Statuses:           status,
+		AccessTiers:        accessTiers,
+		IncludeUnavailable: all || problems,
+	})
+	if err != nil {
+		return err
 	}
 	filteredDrives := []directcsi.DirectCSIDrive{}
-	for _, d := range driveList.Items {
-		if !all {
-			if d.Status.DriveStatus == directcsi.DriveStatusUnavailable {
-				continue
-			}
+	for _, d := range candidateDrives {
+		if problems && len(driveProblems(d)) == 0 {
+			continue
 		}
-		if d.MatchGlob(nodes, drives, status) {
-			if d.MatchAccessTier(accessTierSet) {
-				filteredDrives = append(filteredDrives, d)
-			}
+		if matchReservedFor(d, reservedFor) {
+			filteredDrives = append(filteredDrives, d)
 		}
 	}
 
-	sort.SliceStable(filteredDrives, func(i, j int) bool {
-		d1 := filteredDrives[i]
-		d2 := filteredDrives[j]
-
-		if v := strings.Compare(d1.Status.NodeName, d2.Status.NodeName); v != 0 {
-			return v < 0
-		}
-
-		if v := strings.Compare(d1.Status.Path, d2.Status.Path); v != 0 {
-			return v < 0
-		}
-
-		return strings.Compare(string(d1.Status.DriveStatus), string(d2.Status.DriveStatus)) < 0
-	})
+	if err := sortDrives(filteredDrives, sortBy); err != nil {
+		return err
+	}
 
 	wrappedDriveList := directcsi.DirectCSIDriveList{
 		TypeMeta: metav1.TypeMeta{
@@ -137,6 +329,13 @@ func listDrives(ctx context.Context, args []string) error {
 		},
 		Items: filteredDrives,
 	}
+	if nodeSummary {
+		return printNodeSummary(filteredDrives)
+	}
+	if byTier {
+		return printTierSummary(filteredDrives)
+	}
+
 	if yaml || json {
 		if err := printer(wrappedDriveList); err != nil {
 			klog.ErrorS(err, "error marshaling drives", "format", outputMode)
@@ -144,6 +343,10 @@ func listDrives(ctx context.Context, args []string) error {
 		}
 		return nil
 	}
+	if problems {
+		printDriveProblems(filteredDrives)
+		return nil
+	}
 
 	headers := func() []interface{} {
 		header := []interface{}{
@@ -158,7 +361,7 @@ func listDrives(ctx context.Context, args []string) error {
 			"",
 		}
 		if wide {
-			header = append(header, "DRIVE ID")
+			header = append(header, "DRIVE ID", "LABEL", "FS-UUID", "REMOVABLE", "SCHEDULER", "NR-REQUESTS", "READ-AHEAD", "BY-ID", "BY-PATH", "ENCLOSURE", "SLOT", "RESERVED")
 		}
 		return header
 	}()
@@ -173,13 +376,9 @@ func listDrives(ctx context.Context, args []string) error {
 	style.Color.Header = text.Colors{text.FgHiBlue, text.BgHiBlack}
 	t.SetStyle(style)
 
+	volumeCounts := client.CountVolumes(volList.Items)
 	for _, d := range filteredDrives {
-		volumes := 0
-		for _, v := range volList.Items {
-			if v.Status.Drive == d.Name {
-				volumes++
-			}
-		}
+		volumes := volumeCounts[d.Name]
 
 		msg := ""
 		dr := func(val string) string {
@@ -201,12 +400,12 @@ func listDrives(ctx context.Context, args []string) error {
 			return strings.ReplaceAll("/dev/"+dr, directCSIPartitionInfix, "")
 		}(d.Status.Path)
 		drStatus := d.Status.DriveStatus
+		if isDriveCordoned(d) {
+			drStatus = drStatus + ",Cordoned"
+		}
 		if msg != "" {
 			drStatus = drStatus + "*"
-			msg = strings.ReplaceAll(msg, d.Name, "")
-			msg = strings.ReplaceAll(msg, "/var/lib/direct-csi/devices", "/dev")
-			msg = strings.ReplaceAll(msg, directCSIPartitionInfix, "")
-			msg = strings.Split(msg, "\n")[0]
+			msg = strings.Split(printableDriveMessage(d, msg), "\n")[0]
 		}
 
 		emptyOrVal := func(val int) string {
@@ -221,7 +420,7 @@ func listDrives(ctx context.Context, args []string) error {
 			}
 			return humanize.IBytes(uint64(val))
 		}
-		t.AppendRow([]interface{}{
+		row := []interface{}{
 			dr,                                       //DRIVE
 			emptyOrBytes(d.Status.TotalCapacity),     //CAPACITY
 			emptyOrBytes(d.Status.AllocatedCapacity), //ALLOCATED
@@ -242,9 +441,305 @@ func listDrives(ctx context.Context, args []string) error {
 				}
 				return ""
 			}(),
+		}
+		if wide {
+			row = append(row, printableString(d.Status.FilesystemLabel)) //LABEL
+			row = append(row, printableString(d.Status.FilesystemUUID))  //FS-UUID
+			row = append(row, func() string {
+				if d.Status.Removable {
+					return "yes"
+				}
+				return "-"
+			}()) //REMOVABLE
+			row = append(row, printableString(d.Status.IOScheduler)) //SCHEDULER
+			row = append(row, emptyOrVal(int(d.Status.NrRequests)))  //NR-REQUESTS
+			row = append(row, func() string {
+				if d.Status.ReadAheadKB == 0 {
+					return "-"
+				}
+				return humanize.IBytes(uint64(d.Status.ReadAheadKB) * 1024)
+			}()) //READ-AHEAD
+			row = append(row, printableString(d.Status.ByIDPath))      //BY-ID
+			row = append(row, printableString(d.Status.ByPath))        //BY-PATH
+			row = append(row, printableString(d.Status.Enclosure))     //ENCLOSURE
+			row = append(row, printableString(d.Status.Slot))          //SLOT
+			row = append(row, emptyOrBytes(d.Status.ReservedCapacity)) //RESERVED
+		}
+		t.AppendRow(row)
+	}
+
+	t.Render()
+	return nil
+}
+
+// printableDriveMessage strips the drive name and the internal device paths from the condition message
+func printableDriveMessage(drive directcsi.DirectCSIDrive, msg string) string {
+	msg = strings.ReplaceAll(msg, drive.Name, "")
+	msg = strings.ReplaceAll(msg, "/var/lib/direct-csi/devices", "/dev")
+	return strings.ReplaceAll(msg, directCSIPartitionInfix, "")
+}
+
+// isFailureReason checks if the reason of the condition records a failed step of the drive
+func isFailureReason(reason directcsi.DirectCSIDriveReason) bool {
+	switch reason {
+	case directcsi.DirectCSIDriveReasonFormatFailed,
+		directcsi.DirectCSIDriveReasonMountFailed,
+		directcsi.DirectCSIDriveReasonDeviceBusy,
+		directcsi.DirectCSIDriveReasonReadOnly,
+		directcsi.DirectCSIDriveReasonFilesystemCorrupted,
+		directcsi.DirectCSIDriveReasonRepairFailed:
+		return true
+	}
+	return false
+}
+
+// driveProblems returns the unhealthy conditions of the drive, i.e. the conditions with a failure
+// reason, initialized if not true, and owned or mounted if not true for a drive requested to be owned
+func driveProblems(drive directcsi.DirectCSIDrive) []string {
+	problems := []string{}
+	for _, c := range drive.Status.Conditions {
+		failed := isFailureReason(directcsi.DirectCSIDriveReason(c.Reason))
+		unhealthy := failed
+		switch c.Type {
+		case string(directcsi.DirectCSIDriveConditionInitialized):
+			unhealthy = unhealthy || c.Status != metav1.ConditionTrue
+		case string(directcsi.DirectCSIDriveConditionOwned),
+			string(directcsi.DirectCSIDriveConditionMounted):
+			// the drives which are not requested to be owned are neither owned nor mounted
+			unhealthy = unhealthy || (drive.Spec.DirectCSIOwned && c.Status != metav1.ConditionTrue)
+		}
+		if !unhealthy {
+			continue
+		}
+		msg := c.Message
+		if msg == "" {
+			msg = c.Reason
+		}
+		if failed && c.Message != "" {
+			problems = append(problems, fmt.Sprintf("%s (%s): %s", c.Type, c.Reason, printableDriveMessage(drive, msg)))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", c.Type, printableDriveMessage(drive, msg)))
+	}
+	return problems
+}
+
+func printDriveProblems(drives []directcsi.DirectCSIDrive) {
+	text.DisableColors()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{
+		"DRIVE",
+		"NODE",
+		"STATUS",
+		"PROBLEMS",
+	})
+
+	style := table.StyleColoredDark
+	style.Color.IndexColumn = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	style.Color.Header = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	t.SetStyle(style)
+
+	for _, d := range drives {
+		t.AppendRow([]interface{}{
+			strings.ReplaceAll("/dev/"+canonicalNameFromPath(d.Status.Path), directCSIPartitionInfix, ""),
+			d.Status.NodeName,
+			utils.Bold(d.Status.DriveStatus),
+			strings.Join(driveProblems(d), "\n"),
+		})
+	}
+
+	t.Render()
+}
+
+type nodeDriveSummary struct {
+	Node              string `json:"node"`
+	Drives            int    `json:"drives"`
+	Ready             int    `json:"ready"`
+	Available         int    `json:"available"`
+	Unavailable       int    `json:"unavailable"`
+	TotalCapacity     int64  `json:"totalCapacity"`
+	AllocatedCapacity int64  `json:"allocatedCapacity"`
+	ReservedCapacity  int64  `json:"reservedCapacity"`
+}
+
+func summarizeDrivesByNode(drives []directcsi.DirectCSIDrive) []nodeDriveSummary {
+	summaryMap := map[string]*nodeDriveSummary{}
+	for _, d := range drives {
+		summary, ok := summaryMap[d.Status.NodeName]
+		if !ok {
+			summary = &nodeDriveSummary{Node: d.Status.NodeName}
+			summaryMap[d.Status.NodeName] = summary
+		}
+		summary.Drives++
+		switch d.Status.DriveStatus {
+		case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+			summary.Ready++
+		case directcsi.DriveStatusAvailable:
+			summary.Available++
+		case directcsi.DriveStatusUnavailable:
+			summary.Unavailable++
+		}
+		summary.TotalCapacity += d.Status.TotalCapacity
+		summary.AllocatedCapacity += d.Status.AllocatedCapacity
+		summary.ReservedCapacity += d.Status.ReservedCapacity
+	}
+
+	summaries := []nodeDriveSummary{}
+	for _, summary := range summaryMap {
+		summaries = append(summaries, *summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return strings.Compare(summaries[i].Node, summaries[j].Node) < 0
+	})
+	return summaries
+}
+
+func printNodeSummary(drives []directcsi.DirectCSIDrive) error {
+	summaries := summarizeDrivesByNode(drives)
+	if yaml || json {
+		if err := printer(summaries); err != nil {
+			klog.ErrorS(err, "error marshaling node summary", "format", outputMode)
+			return err
+		}
+		return nil
+	}
+
+	text.DisableColors()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{
+		"NODE",
+		"DRIVES",
+		"READY",
+		"AVAILABLE",
+		"UNAVAILABLE",
+		"CAPACITY",
+		"ALLOCATED",
+		"RESERVED",
+	})
+
+	style := table.StyleColoredDark
+	style.Color.IndexColumn = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	style.Color.Header = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	t.SetStyle(style)
+
+	for _, summary := range summaries {
+		t.AppendRow([]interface{}{
+			summary.Node,
+			summary.Drives,
+			summary.Ready,
+			summary.Available,
+			summary.Unavailable,
+			humanize.IBytes(uint64(summary.TotalCapacity)),
+			humanize.IBytes(uint64(summary.AllocatedCapacity)),
+			humanize.IBytes(uint64(summary.ReservedCapacity)),
+		})
+	}
+
+	t.Render()
+	return nil
+}
+
+type tierDriveSummary struct {
+	Tier              string `json:"tier"`
+	Drives            int    `json:"drives"`
+	TotalCapacity     int64  `json:"totalCapacity"`
+	FreeCapacity      int64  `json:"freeCapacity"`
+	AllocatedCapacity int64  `json:"allocatedCapacity"`
+	ReservedCapacity  int64  `json:"reservedCapacity"`
+}
+
+// summarizeDrivesByTier groups the ready and inuse drives by their access-tier
+func summarizeDrivesByTier(drives []directcsi.DirectCSIDrive) []tierDriveSummary {
+	summaryMap := map[string]*tierDriveSummary{}
+	for _, d := range drives {
+		switch d.Status.DriveStatus {
+		case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+		default:
+			continue
+		}
+		tier := strings.ToLower(string(d.Status.AccessTier))
+		if tier == "" {
+			tier = strings.ToLower(string(directcsi.AccessTierUnknown))
+		}
+		summary, ok := summaryMap[tier]
+		if !ok {
+			summary = &tierDriveSummary{Tier: tier}
+			summaryMap[tier] = summary
+		}
+		summary.Drives++
+		summary.TotalCapacity += d.Status.TotalCapacity
+		summary.FreeCapacity += d.Status.FreeCapacity
+		summary.AllocatedCapacity += d.Status.AllocatedCapacity
+		summary.ReservedCapacity += d.Status.ReservedCapacity
+	}
+
+	summaries := []tierDriveSummary{}
+	for _, summary := range summaryMap {
+		summaries = append(summaries, *summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return strings.Compare(summaries[i].Tier, summaries[j].Tier) < 0
+	})
+	return summaries
+}
+
+func printTierSummary(drives []directcsi.DirectCSIDrive) error {
+	summaries := summarizeDrivesByTier(drives)
+	if yaml || json {
+		if err := printer(summaries); err != nil {
+			klog.ErrorS(err, "error marshaling tier summary", "format", outputMode)
+			return err
+		}
+		return nil
+	}
+
+	text.DisableColors()
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{
+		"ACCESS-TIER",
+		"DRIVES",
+		"CAPACITY",
+		"FREE",
+		"ALLOCATED",
+		"RESERVED",
+	})
+
+	style := table.StyleColoredDark
+	style.Color.IndexColumn = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	style.Color.Header = text.Colors{text.FgHiBlue, text.BgHiBlack}
+	t.SetStyle(style)
+
+	for _, summary := range summaries {
+		t.AppendRow([]interface{}{
+			summary.Tier,
+			summary.Drives,
+			humanize.IBytes(uint64(summary.TotalCapacity)),
+			humanize.IBytes(uint64(summary.FreeCapacity)),
+			humanize.IBytes(uint64(summary.AllocatedCapacity)),
+			humanize.IBytes(uint64(summary.ReservedCapacity)),
 		})
 	}
 
 	t.Render()
 	return nil
 }
+
+// matchReservedFor checks if the drive is reserved for any of the given tenants, "*" matches any reserved drive
+func matchReservedFor(drive directcsi.DirectCSIDrive, tenants []string) bool {
+	if len(tenants) == 0 {
+		return true
+	}
+	reserved := drive.GetLabels()[utils.ReservedForLabel]
+	if reserved == "" {
+		return false
+	}
+	for _, tenant := range tenants {
+		if tenant == "*" || utils.SanitizeLabelV(strings.TrimSpace(tenant)) == reserved {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestSummarizeDrivesByNode(t1 *testing.T) {
+	createTestDrive := func(node string, driveStatus directcsi.DriveStatus, total, allocated int64) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          node,
+				DriveStatus:       driveStatus,
+				TotalCapacity:     total,
+				AllocatedCapacity: allocated,
+			},
+		}
+	}
+
+	testDrives := []directcsi.DirectCSIDrive{
+		createTestDrive("N2", directcsi.DriveStatusAvailable, mb100, 0),
+		createTestDrive("N1", directcsi.DriveStatusReady, mb100, 10*MB),
+		createTestDrive("N1", directcsi.DriveStatusInUse, mb100, 50*MB),
+		createTestDrive("N1", directcsi.DriveStatusUnavailable, mb100, mb100),
+		createTestDrive("N2", directcsi.DriveStatusReleased, mb100, 0),
+	}
+
+	expected := []nodeDriveSummary{
+		{
+			Node:              "N1",
+			Drives:            3,
+			Ready:             2,
+			Unavailable:       1,
+			TotalCapacity:     3 * mb100,
+			AllocatedCapacity: 160 * MB,
+		},
+		{
+			Node:          "N2",
+			Drives:        2,
+			Available:     1,
+			TotalCapacity: 2 * mb100,
+		},
+	}
+
+	if summaries := summarizeDrivesByNode(testDrives); !reflect.DeepEqual(summaries, expected) {
+		t1.Errorf("Expected node summary = %+v, got %+v", expected, summaries)
+	}
+}
+
+func TestSummarizeDrivesByTier(t1 *testing.T) {
+	createTestDrive := func(tier directcsi.AccessTier, driveStatus directcsi.DriveStatus, total, free, allocated int64) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			Status: directcsi.DirectCSIDriveStatus{
+				AccessTier:        tier,
+				DriveStatus:       driveStatus,
+				TotalCapacity:     total,
+				FreeCapacity:      free,
+				AllocatedCapacity: allocated,
+			},
+		}
+	}
+
+	testDrives := []directcsi.DirectCSIDrive{
+		createTestDrive(directcsi.AccessTierHot, directcsi.DriveStatusReady, mb100, 90*MB, 10*MB),
+		createTestDrive(directcsi.AccessTierHot, directcsi.DriveStatusInUse, mb100, 50*MB, 50*MB),
+		createTestDrive(directcsi.AccessTierCold, directcsi.DriveStatusAvailable, mb100, mb100, 0),
+		createTestDrive(directcsi.AccessTierUnknown, directcsi.DriveStatusReady, mb100, mb100, 0),
+		createTestDrive(directcsi.AccessTierWarm, directcsi.DriveStatusUnavailable, mb100, mb100, 0),
+	}
+	// the headroom reserved at format is summed apart from the allocated capacity
+	testDrives[1].Status.ReservedCapacity = 10 * MB
+
+	expected := []tierDriveSummary{
+		{
+			Tier:              "hot",
+			Drives:            2,
+			TotalCapacity:     2 * mb100,
+			FreeCapacity:      140 * MB,
+			AllocatedCapacity: 60 * MB,
+			ReservedCapacity:  10 * MB,
+		},
+		{
+			Tier:          "unknown",
+			Drives:        1,
+			TotalCapacity: mb100,
+			FreeCapacity:  mb100,
+		},
+	}
+
+	if summaries := summarizeDrivesByTier(testDrives); !reflect.DeepEqual(summaries, expected) {
+		t1.Errorf("Expected tier summary = %+v, got %+v", expected, summaries)
+	}
+}
+
+func TestMatchReservedFor(t1 *testing.T) {
+	reservedDrive := directcsi.DirectCSIDrive{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				utils.ReservedForLabel: "tenant-1",
+			},
+		},
+	}
+	unreservedDrive := directcsi.DirectCSIDrive{}
+
+	testCases := []struct {
+		name     string
+		drive    directcsi.DirectCSIDrive
+		tenants  []string
+		expected bool
+	}{
+		{"noFilter", unreservedDrive, []string{}, true},
+		{"unreservedDrive", unreservedDrive, []string{"*"}, false},
+		{"anyTenant", reservedDrive, []string{"*"}, true},
+		{"matchingTenant", reservedDrive, []string{"tenant-2", "tenant-1"}, true},
+		{"otherTenant", reservedDrive, []string{"tenant-2"}, false},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if matched := matchReservedFor(tt.drive, tt.tenants); matched != tt.expected {
+				t1.Errorf("Test case name %s: Expected match = %v, got %v", tt.name, tt.expected, matched)
+			}
+		})
+	}
+}
+
+func TestSortDrives(t1 *testing.T) {
+	createTestDrive := func(node, path string, driveStatus directcsi.DriveStatus, total, free int64) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          node,
+				Path:              path,
+				DriveStatus:       driveStatus,
+				TotalCapacity:     total,
+				FreeCapacity:      free,
+				AllocatedCapacity: total - free,
+			},
+		}
+	}
+
+	d1 := createTestDrive("N1", "/dev/sdb", directcsi.DriveStatusReady, mb100, 10*MB)
+	d2 := createTestDrive("N1", "/dev/sda", directcsi.DriveStatusInUse, 2*mb100, 50*MB)
+	d3 := createTestDrive("N2", "/dev/sda", directcsi.DriveStatusAvailable, mb100, mb100)
+
+	testCases := []struct {
+		name        string
+		key         string
+		expected    []directcsi.DirectCSIDrive
+		expectedErr bool
+	}{
+		{"default", "", []directcsi.DirectCSIDrive{d2, d1, d3}, false},
+		{"path", "path", []directcsi.DirectCSIDrive{d2, d3, d1}, false},
+		{"descending-node", "-node", []directcsi.DirectCSIDrive{d3, d2, d1}, false},
+		{"capacity", "capacity", []directcsi.DirectCSIDrive{d1, d3, d2}, false},
+		{"free", "free", []directcsi.DirectCSIDrive{d1, d2, d3}, false},
+		{"descending-free", "-free", []directcsi.DirectCSIDrive{d3, d2, d1}, false},
+		{"allocated", "allocated", []directcsi.DirectCSIDrive{d3, d1, d2}, false},
+		{"status", "status", []directcsi.DirectCSIDrive{d3, d2, d1}, false},
+		{"invalid", "size", nil, true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			drives := []directcsi.DirectCSIDrive{d1, d2, d3}
+			err := sortDrives(drives, tt.key)
+			if tt.expectedErr {
+				if err == nil {
+					t1.Errorf("Test case name %s: expected error for sort key %s", tt.name, tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(drives, tt.expected) {
+				t1.Errorf("Test case name %s: expected order %+v, got %+v", tt.name, tt.expected, drives)
+			}
+		})
+	}
+}
+
+func TestDriveProblems(t1 *testing.T) {
+	createTestDrive := func(owned bool, conditions ...metav1.Condition) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "drive-1",
+			},
+			Spec: directcsi.DirectCSIDriveSpec{
+				DirectCSIOwned: owned,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				Conditions: conditions,
+			},
+		}
+	}
+	condition := func(conditionType directcsi.DirectCSIDriveCondition, status bool, reason directcsi.DirectCSIDriveReason, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:    string(conditionType),
+			Status:  utils.BoolToCondition(status),
+			Reason:  string(reason),
+			Message: message,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		drive    directcsi.DirectCSIDrive
+		expected []string
+	}{
+		{
+			name: "ready",
+			drive: createTestDrive(true,
+				condition(directcsi.DirectCSIDriveConditionInitialized, true, directcsi.DirectCSIDriveReasonInitialized, ""),
+				condition(directcsi.DirectCSIDriveConditionOwned, true, directcsi.DirectCSIDriveReasonAdded, ""),
+				condition(directcsi.DirectCSIDriveConditionMounted, true, directcsi.DirectCSIDriveReasonAdded, string(directcsi.DirectCSIDriveMessageMounted)),
+				condition(directcsi.DirectCSIDriveConditionFormatted, true, directcsi.DirectCSIDriveReasonAdded, string(directcsi.DirectCSIDriveMessageFormatted)),
+			),
+			expected: []string{},
+		},
+		{
+			name: "available",
+			drive: createTestDrive(false,
+				condition(directcsi.DirectCSIDriveConditionInitialized, true, directcsi.DirectCSIDriveReasonInitialized, ""),
+				condition(directcsi.DirectCSIDriveConditionOwned, false, directcsi.DirectCSIDriveReasonNotAdded, ""),
+				condition(directcsi.DirectCSIDriveConditionMounted, false, directcsi.DirectCSIDriveReasonNotAdded, string(directcsi.DirectCSIDriveMessageNotMounted)),
+				condition(directcsi.DirectCSIDriveConditionFormatted, true, directcsi.DirectCSIDriveReasonNotAdded, "xfs"),
+			),
+			expected: []string{},
+		},
+		{
+			name: "mount_failed",
+			drive: createTestDrive(true,
+				condition(directcsi.DirectCSIDriveConditionInitialized, false, directcsi.DirectCSIDriveReasonInitialized, "mount /var/lib/direct-csi/devices/sdb-part-1: no such device"),
+				condition(directcsi.DirectCSIDriveConditionOwned, true, directcsi.DirectCSIDriveReasonAdded, ""),
+				condition(directcsi.DirectCSIDriveConditionMounted, false, directcsi.DirectCSIDriveReasonMountFailed, "mount /var/lib/direct-csi/devices/sdb-part-1: no such device"),
+				condition(directcsi.DirectCSIDriveConditionFormatted, true, directcsi.DirectCSIDriveReasonAdded, string(directcsi.DirectCSIDriveMessageFormatted)),
+			),
+			expected: []string{
+				"Initialized: mount /dev/sdb1: no such device",
+				"Mounted (MountFailed): mount /dev/sdb1: no such device",
+			},
+		},
+		{
+			name: "multiline_message",
+			drive: createTestDrive(false,
+				condition(directcsi.DirectCSIDriveConditionInitialized, false, directcsi.DirectCSIDriveReasonInitialized, "failed to format drive-1 /var/lib/direct-csi/devices/sdb\nmkfs.xfs: device busy"),
+				condition(directcsi.DirectCSIDriveConditionFormatted, false, directcsi.DirectCSIDriveReasonNotAdded, string(directcsi.DirectCSIDriveMessageNotFormatted)),
+			),
+			expected: []string{
+				"Initialized: failed to format  /dev/sdb\nmkfs.xfs: device busy",
+			},
+		},
+		{
+			name: "failure_reason",
+			drive: createTestDrive(true,
+				condition(directcsi.DirectCSIDriveConditionInitialized, true, directcsi.DirectCSIDriveReasonInitialized, ""),
+				condition(directcsi.DirectCSIDriveConditionOwned, false, directcsi.DirectCSIDriveReasonDeviceBusy, "failed to format drive: drive-1 device busy"),
+				condition(directcsi.DirectCSIDriveConditionFormatted, false, directcsi.DirectCSIDriveReasonDeviceBusy, string(directcsi.DirectCSIDriveMessageNotFormatted)),
+				condition(directcsi.DirectCSIDriveConditionMounted, false, directcsi.DirectCSIDriveReasonAdded, string(directcsi.DirectCSIDriveMessageNotMounted)),
+			),
+			expected: []string{
+				"Owned (DeviceBusy): failed to format drive:  device busy",
+				"Formatted (DeviceBusy): NotFormatted",
+				"Mounted: NotMounted",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if problems := driveProblems(tt.drive); !reflect.DeepEqual(problems, tt.expected) {
+				t1.Errorf("Test case name %s: expected problems = %q, got %q", tt.name, tt.expected, problems)
+			}
+		})
+	}
+}
+
+func TestConsumeDriveEvents(t1 *testing.T) {
+	events := make(chan watch.Event, 3)
+	renders := make(chan struct{}, 3)
+	render := func() error {
+		renders <- struct{}{}
+		return nil
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- consumeDriveEvents(context.Background(), events, render)
+	}()
+
+	// a burst of events is rendered once
+	for i := 0; i < 3; i++ {
+		events <- watch.Event{Type: watch.Modified, Object: &directcsi.DirectCSIDrive{}}
+	}
+	<-renders
+
+	// closing the events returns to restart the watch
+	close(events)
+	if err := <-done; err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+	if len(renders) != 0 {
+		t1.Errorf("expected a single render for the burst of events, got %d more", len(renders))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := consumeDriveEvents(ctx, make(chan watch.Event), render); err != nil {
+		t1.Fatalf("unexpected error on interrupt %v", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
 	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
 
@@ -34,10 +35,17 @@ import (
 	"k8s.io/klog/v2"
 )
 
+var (
+	wipe = false
+)
+
 var releaseDrivesCmd = &cobra.Command{
 	Use:   "release",
 	Short: "release drives from the DirectCSI cluster",
-	Long:  "",
+	Long: `
+Ready drives without volumes are unmounted by their node and made available again.
+The filesystem on them is retained unless --wipe is specified.
+Available drives are marked as released so that they are not formatted.`,
 	Example: `
  # Release all drives in the cluster
  $ kubectl direct-csi drives release --all
@@ -56,6 +64,9 @@ var releaseDrivesCmd = &cobra.Command{
  
  # Combine multiple parameters using csv
  $ kubectl direct-csi drives release --nodes=directcsi-1,othernode-2 --status=ready
+
+ # Release all ready drives in a node and erase their filesystems
+ $ kubectl direct-csi drives release --nodes=directcsi-1 --status=ready --wipe
  `,
 	RunE: func(c *cobra.Command, args []string) error {
 		return releaseDrives(c.Context(), args)
@@ -67,6 +78,7 @@ func init() {
 	releaseDrivesCmd.PersistentFlags().StringSliceVarP(&drives, "drives", "d", drives, "glog selector for drive paths")
 	releaseDrivesCmd.PersistentFlags().StringSliceVarP(&nodes, "nodes", "n", nodes, "glob selector for node names")
 	releaseDrivesCmd.PersistentFlags().BoolVarP(&all, "all", "a", all, "release all available drives")
+	releaseDrivesCmd.PersistentFlags().BoolVarP(&wipe, "wipe", "", wipe, "erase the filesystem on the ready drives being released")
 
 	releaseDrivesCmd.PersistentFlags().StringSliceVarP(&accessTiers, "access-tier", "", accessTiers, "release based on access-tier set. The possible values are [hot,cold,warm] ")
 }
@@ -74,22 +86,26 @@ func init() {
 func releaseDrives(ctx context.Context, args []string) error {
 	if !all {
 		if len(drives) == 0 && len(nodes) == 0 && len(accessTiers) == 0 {
-			return fmt.Errorf("atleast one among ['%s','%s','%s','%s'] should be specified", utils.Bold("--all"), utils.Bold("--drives"), utils.Bold("--nodes"), utils.Bold("--access-tier"))
+			return newUsageError("atleast one among ['%s','%s','%s','%s'] should be specified", utils.Bold("--all"), utils.Bold("--drives"), utils.Bold("--nodes"), utils.Bold("--access-tier"))
 		}
 	}
 
 	directClient := utils.GetDirectCSIClient()
 	driveList, err := directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newAPIError(err)
 	}
 
 	if len(driveList.Items) == 0 {
-		klog.Errorf("No resource of %s found\n", bold("DirectCSIDrive"))
-		return fmt.Errorf("No resources found")
+		return errNoDrives
+	}
+
+	volumeList, err := directClient.DirectCSIVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return newAPIError(err)
 	}
 
-	accessTierSet, aErr := getAccessTierSet(accessTiers)
+	accessTierSet, aErr := client.ParseAccessTiers(accessTiers)
 	if aErr != nil {
 		return aErr
 	}
@@ -125,7 +141,23 @@ func releaseDrives(ctx context.Context, args []string) error {
 			continue
 		}
 
-		d.Status.DriveStatus = directcsi.DriveStatusReleased
+		if vols := ListVolumesInDrive(d, volumeList, []directcsi.DirectCSIVolume{}); len(vols) > 0 {
+			driveAddr := fmt.Sprintf("%s:/dev/%s", d.Status.NodeName, driveName(d.Status.Path))
+			klog.Errorf("%s has %d volume(s). Cannot be released", utils.Bold(driveAddr), len(vols))
+			continue
+		}
+
+		if d.Status.DriveStatus == directcsi.DriveStatusReady {
+			// the node unmounts the drive and resets it to 'available'
+			if wipe {
+				if d.Annotations == nil {
+					d.Annotations = map[string]string{}
+				}
+				d.Annotations[directcsi.DirectCSIDriveAnnotationWipe] = "true"
+			}
+		} else {
+			d.Status.DriveStatus = directcsi.DriveStatusReleased
+		}
 		d.Spec.DirectCSIOwned = false
 		d.Spec.RequestedFormat = nil
 		if dryRun {
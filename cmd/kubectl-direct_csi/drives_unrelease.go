@@ -24,6 +24,7 @@ import (
 	"sync"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -79,7 +80,7 @@ func init() {
 func unreleaseDrives(ctx context.Context, args []string) error {
 	if !all {
 		if len(drives) == 0 && len(nodes) == 0 && len(accessTiers) == 0 && len(args) == 0 {
-			return fmt.Errorf("atleast one of '%s', '%s' or '%s' should be specified",
+			return newUsageError("atleast one of '%s', '%s' or '%s' should be specified",
 				utils.Bold("--all"),
 				utils.Bold("--drives"),
 				utils.Bold("--nodes"))
@@ -96,7 +97,7 @@ func unreleaseDrives(ctx context.Context, args []string) error {
 	}
 
 	wg := sync.WaitGroup{}
-	accessTierSet, aErr := getAccessTierSet(accessTiers)
+	accessTierSet, aErr := client.ParseAccessTiers(accessTiers)
 	if aErr != nil {
 		return aErr
 	}
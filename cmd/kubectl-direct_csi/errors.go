@@ -0,0 +1,107 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// exit codes of the plugin, scripts can rely on them to tell the failures apart
+const (
+	exitCodeError    = 1
+	exitCodeUsage    = 2
+	exitCodeNotFound = 3
+	exitCodeAPIError = 4
+)
+
+// usageError is returned for invalid flags or arguments
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+func newUsageError(format string, args ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// notFoundError is returned when no resource matches the request
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string { return e.err.Error() }
+func (e *notFoundError) Unwrap() error { return e.err }
+
+func newNotFoundError(format string, args ...interface{}) error {
+	return &notFoundError{err: fmt.Errorf(format, args...)}
+}
+
+// errNoDrives is returned by the drive commands when no drive is found in the cluster
+var errNoDrives = newNotFoundError("no resources of %s found", bold("DirectCSIDrive"))
+
+// apiError is returned when a request to the kube apiserver fails
+type apiError struct {
+	err error
+}
+
+func (e *apiError) Error() string { return e.err.Error() }
+func (e *apiError) Unwrap() error { return e.err }
+
+func newAPIError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &apiError{err: err}
+}
+
+// exitCode maps the error returned by a command to the exit code of the plugin, the
+// errors returned by the kube clients as is are reported as API errors
+func exitCode(err error) int {
+	var (
+		uErr  *usageError
+		nfErr *notFoundError
+		aErr  *apiError
+		sErr  apierrors.APIStatus
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &uErr):
+		return exitCodeUsage
+	case errors.As(err, &nfErr):
+		return exitCodeNotFound
+	case apierrors.IsNotFound(err):
+		return exitCodeNotFound
+	case errors.As(err, &aErr), errors.As(err, &sErr):
+		return exitCodeAPIError
+	default:
+		return exitCodeError
+	}
+}
+
+// printError is the single place the errors of the commands are printed to the user
+func printError(w io.Writer, err error) {
+	fmt.Fprintln(w, utils.Bold(utils.Red("ERROR")), err)
+}
@@ -0,0 +1,51 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExitCode(t1 *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "test1", err: nil, expectedCode: 0},
+		{name: "test2", err: errors.New("unexpected"), expectedCode: exitCodeError},
+		{name: "test3", err: newUsageError("invalid flag %s", "--foo"), expectedCode: exitCodeUsage},
+		{name: "test4", err: errNoDrives, expectedCode: exitCodeNotFound},
+		{name: "test5", err: fmt.Errorf("wrapped: %w", errNoDrives), expectedCode: exitCodeNotFound},
+		{name: "test6", err: newAPIError(errors.New("connection refused")), expectedCode: exitCodeAPIError},
+		{name: "test7", err: apierrors.NewInternalError(errors.New("etcd unavailable")), expectedCode: exitCodeAPIError},
+		{name: "test8", err: apierrors.NewNotFound(schema.GroupResource{Resource: "directcsidrives"}, "drive-1"), expectedCode: exitCodeNotFound},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if code := exitCode(tt.err); code != tt.expectedCode {
+				t1.Errorf("Test case name %s: expected exit code %d but got %d", tt.name, tt.expectedCode, code)
+			}
+		})
+	}
+}
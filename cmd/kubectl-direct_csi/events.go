@@ -0,0 +1,290 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	driveEvents  = "drive"
+	volumeEvents = "volume"
+)
+
+var (
+	eventsWatch = false
+	eventsType  = ""
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "print the changes of the drives and volumes as JSON lines",
+	Long: `Print the drives and volumes as newline-delimited JSON events, one object per line.
+
+The matching objects are printed as ADDED events first. With --watch, their
+ADDED, MODIFIED and DELETED events are printed as they happen until interrupted.
+The objects are printed as ADDED again when the watch has to be re-established
+from a fresh list, consumers should handle them idempotently.`,
+	Example: `
+# Print the drives and volumes as ADDED events
+$ kubectl direct-csi events
+
+# Stream the changes of the drives of a node
+$ kubectl direct-csi events --watch --type=drive --nodes=directcsi-1
+
+# Stream the changes of the volumes on nvme drives to a pipeline
+$ kubectl direct-csi events --watch --type=volume --drives='/dev/nvme*' | jq -r .object.metadata.name
+`,
+	RunE: func(c *cobra.Command, args []string) error {
+		return printEvents(c.Context(), args)
+	},
+}
+
+func init() {
+	eventsCmd.PersistentFlags().BoolVarP(&eventsWatch, "watch", "w", eventsWatch, "stream the changes until interrupted")
+	eventsCmd.PersistentFlags().StringVarP(&eventsType, "type", "", eventsType, "print the events of only one type of objects, should be one of drive|volume")
+	eventsCmd.PersistentFlags().StringSliceVarP(&drives, "drives", "d", drives, "glob prefix match for drive paths")
+	eventsCmd.PersistentFlags().StringSliceVarP(&nodes, "nodes", "n", nodes, "glob prefix match for node names")
+}
+
+// objectEvent is a line of the events output
+type objectEvent struct {
+	Type   watch.EventType `json:"type"`
+	Kind   string          `json:"kind"`
+	Object runtime.Object  `json:"object"`
+}
+
+// eventWriter serializes the events of the drive and the volume streams
+type eventWriter struct {
+	mutex   sync.Mutex
+	encoder *encodingjson.Encoder
+}
+
+func newEventWriter(w io.Writer) *eventWriter {
+	return &eventWriter{encoder: encodingjson.NewEncoder(w)}
+}
+
+func (w *eventWriter) write(eventType watch.EventType, kind string, object runtime.Object) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.encoder.Encode(objectEvent{Type: eventType, Kind: kind, Object: object})
+}
+
+// eventSource lists and watches one type of objects
+type eventSource struct {
+	kind  string
+	list  func(ctx context.Context) ([]runtime.Object, string, error)
+	watch func(ctx context.Context, resourceVersion string) (watch.Interface, error)
+	match func(object runtime.Object) bool
+}
+
+func matchDriveEvent(object runtime.Object) bool {
+	drive, ok := object.(*directcsi.DirectCSIDrive)
+	return ok && drive.MatchGlob(nodes, drives, nil)
+}
+
+// matchVolumeEvent matches the volumes by their node and by the drive path
+// recorded in their labels, so that the drives need not be looked up
+func matchVolumeEvent(object runtime.Object) bool {
+	volume, ok := object.(*directcsi.DirectCSIVolume)
+	if !ok {
+		return false
+	}
+	drive := directcsi.DirectCSIDrive{
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName: volume.Status.NodeName,
+			Path:     volume.GetLabels()[directcsi.Group+"/drive-path"],
+		},
+	}
+	return drive.MatchGlob(nodes, drives, nil)
+}
+
+func driveEventSource() eventSource {
+	driveClient := utils.GetDirectCSIClient().DirectCSIDrives()
+	return eventSource{
+		kind: "DirectCSIDrive",
+		list: func(ctx context.Context) ([]runtime.Object, string, error) {
+			driveList, err := driveClient.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, "", err
+			}
+			objects := []runtime.Object{}
+			for i := range driveList.Items {
+				objects = append(objects, &driveList.Items[i])
+			}
+			return objects, driveList.ResourceVersion, nil
+		},
+		watch: func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+			return driveClient.Watch(ctx, metav1.ListOptions{ResourceVersion: resourceVersion})
+		},
+		match: matchDriveEvent,
+	}
+}
+
+func volumeEventSource() eventSource {
+	volumeClient := utils.GetDirectCSIClient().DirectCSIVolumes()
+	return eventSource{
+		kind: "DirectCSIVolume",
+		list: func(ctx context.Context) ([]runtime.Object, string, error) {
+			volumeList, err := volumeClient.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, "", err
+			}
+			objects := []runtime.Object{}
+			for i := range volumeList.Items {
+				objects = append(objects, &volumeList.Items[i])
+			}
+			return objects, volumeList.ResourceVersion, nil
+		},
+		watch: func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+			return volumeClient.Watch(ctx, metav1.ListOptions{ResourceVersion: resourceVersion})
+		},
+		match: matchVolumeEvent,
+	}
+}
+
+func printEvents(ctx context.Context, args []string) error {
+	var sources []eventSource
+	switch eventsType {
+	case "":
+		sources = []eventSource{driveEventSource(), volumeEventSource()}
+	case driveEvents:
+		sources = []eventSource{driveEventSource()}
+	case volumeEvents:
+		sources = []eventSource{volumeEventSource()}
+	default:
+		return newUsageError("unknown type %q, should be one of %s|%s", eventsType, driveEvents, volumeEvents)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := newEventWriter(os.Stdout)
+	errCh := make(chan error, len(sources))
+	for _, source := range sources {
+		go func(source eventSource) {
+			errCh <- streamEvents(ctx, source, writer, eventsWatch)
+		}(source)
+	}
+
+	var err error
+	for range sources {
+		if serr := <-errCh; serr != nil && err == nil {
+			err = serr
+			// stop the other stream as well
+			cancel()
+		}
+	}
+	return err
+}
+
+// streamEvents prints the matching objects of the source as ADDED events and,
+// if follow is set, their changes until the context is canceled
+func streamEvents(ctx context.Context, source eventSource, writer *eventWriter, follow bool) error {
+	for {
+		objects, resourceVersion, err := source.list(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return newAPIError(err)
+		}
+		for _, object := range objects {
+			if !source.match(object) {
+				continue
+			}
+			if err := writer.write(watch.Added, source.kind, object); err != nil {
+				return err
+			}
+		}
+		if !follow {
+			return nil
+		}
+
+		relist := false
+		for !relist {
+			watcher, err := source.watch(ctx, resourceVersion)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return newAPIError(err)
+			}
+			resourceVersion, relist, err = consumeObjectEvents(ctx, watcher.ResultChan(), source, writer, resourceVersion)
+			watcher.Stop()
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !relist {
+				// the watch was closed by the apiserver, e.g. on its timeout, resume from the last seen version
+				klog.V(3).Infof("restarting the watch on %s objects", source.kind)
+			}
+		}
+	}
+}
+
+// consumeObjectEvents prints the matching events until the channel is closed
+// or the context is canceled. It returns the resource version to resume the
+// watch from, and whether the objects must be listed again because the watch
+// failed, e.g. when the resource version is too old.
+func consumeObjectEvents(ctx context.Context, events <-chan watch.Event, source eventSource, writer *eventWriter, resourceVersion string) (string, bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, false, nil
+		case event, ok := <-events:
+			if !ok {
+				return resourceVersion, false, nil
+			}
+			if event.Type == watch.Error {
+				klog.V(3).Infof("error event on the %s watch: %v", source.kind, apierrors.FromObject(event.Object))
+				return resourceVersion, true, nil
+			}
+			if accessor, err := meta.Accessor(event.Object); err == nil {
+				resourceVersion = accessor.GetResourceVersion()
+			}
+			if event.Type == watch.Bookmark || !source.match(event.Object) {
+				continue
+			}
+			if err := writer.write(event.Type, source.kind, event.Object); err != nil {
+				return resourceVersion, false, err
+			}
+		}
+	}
+}
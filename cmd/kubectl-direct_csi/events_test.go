@@ -0,0 +1,149 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	encodingjson "encoding/json"
+	"reflect"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestConsumeObjectEvents(t *testing.T) {
+	createTestVolume := func(name, node, drivePath, resourceVersion string) *directcsi.DirectCSIVolume {
+		return &directcsi.DirectCSIVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				ResourceVersion: resourceVersion,
+				Labels:          map[string]string{directcsi.Group + "/drive-path": drivePath},
+			},
+			Status: directcsi.DirectCSIVolumeStatus{NodeName: node},
+		}
+	}
+
+	source := eventSource{kind: "DirectCSIVolume", match: matchVolumeEvent}
+	nodes, drives = []string{"node-1"}, []string{"/dev/nvme*"}
+	defer func() { nodes, drives = []string{}, []string{} }()
+
+	events := make(chan watch.Event, 5)
+	events <- watch.Event{Type: watch.Added, Object: createTestVolume("volume-1", "node-1", "nvme0n1", "10")}
+	events <- watch.Event{Type: watch.Modified, Object: createTestVolume("volume-2", "node-2", "nvme0n1", "11")}
+	events <- watch.Event{Type: watch.Modified, Object: createTestVolume("volume-3", "node-1", "sdb", "12")}
+	events <- watch.Event{Type: watch.Deleted, Object: createTestVolume("volume-1", "node-1", "nvme0n1", "13")}
+	events <- watch.Event{Type: watch.Bookmark, Object: createTestVolume("", "", "", "14")}
+	close(events)
+
+	var buf bytes.Buffer
+	resourceVersion, relist, err := consumeObjectEvents(context.Background(), events, source, newEventWriter(&buf), "9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if relist {
+		t.Errorf("expected the watch to be resumed, not relisted")
+	}
+	if resourceVersion != "14" {
+		t.Errorf("resource version: expected: 14, got: %v", resourceVersion)
+	}
+
+	type line struct {
+		Type   watch.EventType `json:"type"`
+		Kind   string          `json:"kind"`
+		Object struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"object"`
+	}
+	var lines []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var l line
+		if err := encodingjson.Unmarshal(scanner.Bytes(), &l); err != nil {
+			t.Fatalf("line %q is not a JSON object: %v", scanner.Text(), err)
+		}
+		lines = append(lines, string(l.Type)+" "+l.Kind+" "+l.Object.Metadata.Name)
+	}
+	expected := []string{
+		"ADDED DirectCSIVolume volume-1",
+		"DELETED DirectCSIVolume volume-1",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected: %v, got: %v", expected, lines)
+	}
+}
+
+func TestConsumeObjectEventsError(t *testing.T) {
+	source := eventSource{kind: "DirectCSIDrive", match: matchDriveEvent}
+	events := make(chan watch.Event, 1)
+	events <- watch.Event{Type: watch.Error, Object: &metav1.Status{Reason: metav1.StatusReasonExpired}}
+
+	var buf bytes.Buffer
+	resourceVersion, relist, err := consumeObjectEvents(context.Background(), events, source, newEventWriter(&buf), "9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !relist {
+		t.Errorf("expected the objects to be listed again after an error event")
+	}
+	if resourceVersion != "9" {
+		t.Errorf("resource version: expected: 9, got: %v", resourceVersion)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got: %s", buf.String())
+	}
+}
+
+func TestStreamEventsList(t *testing.T) {
+	source := eventSource{
+		kind: "DirectCSIDrive",
+		list: func(ctx context.Context) ([]runtime.Object, string, error) {
+			return []runtime.Object{
+				&directcsi.DirectCSIDrive{
+					ObjectMeta: metav1.ObjectMeta{Name: "drive-1"},
+					Status:     directcsi.DirectCSIDriveStatus{NodeName: "node-1", Path: "/dev/sda"},
+				},
+			}, "1", nil
+		},
+		watch: func(ctx context.Context, resourceVersion string) (watch.Interface, error) {
+			t.Fatalf("the objects must not be watched without follow")
+			return nil, nil
+		},
+		match: matchDriveEvent,
+	}
+
+	var buf bytes.Buffer
+	if err := streamEvents(context.Background(), source, newEventWriter(&buf), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var event map[string]interface{}
+	if err := encodingjson.Unmarshal(buf.Bytes(), &event); err != nil {
+		t.Fatalf("output %q is not a JSON object: %v", buf.String(), err)
+	}
+	if event["type"] != string(watch.Added) || event["kind"] != "DirectCSIDrive" {
+		t.Errorf("unexpected event: %v", event)
+	}
+}
@@ -19,13 +19,18 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
+	"path/filepath"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/minio/direct-csi/pkg/installer"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/loopback"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	"k8s.io/klog/v2"
@@ -37,22 +42,40 @@ var installCmd = &cobra.Command{
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(c *cobra.Command, args []string) error {
-		return install(c.Context(), args)
+		return install(c.Context(), args, c.Flags().Changed("loopback-count"))
 	},
 }
 
 var (
-	installCRD         = false
-	overwriteCRD       = false
-	admissionControl   = false
-	image              = "direct-csi:" + Version
-	registry           = "quay.io"
-	org                = "minio"
-	loopBackOnly       = false
-	nodeSelectorValues = []string{}
-	tolerationValues   = []string{}
-	seccompProfile     = ""
-	apparmorProfile    = ""
+	installCRD           = false
+	overwriteCRD         = false
+	admissionControl     = false
+	image                = "direct-csi:" + Version
+	registry             = "quay.io"
+	org                  = "minio"
+	loopBackOnly         = false
+	loopBackCount        = loopback.DefaultDeviceCount
+	includeDevices       = []string{}
+	excludeDevices       = []string{}
+	autoTier             = false
+	allowRemovable       = false
+	topologyNodeLabels   = []string{}
+	maxVolumesPerNode    = int64(0)
+	smartInterval        = time.Duration(0)
+	maxConcurrentFormats = 0
+	reprobeInterval      = time.Duration(0)
+	metricsTokenSecret   = ""
+	metricsPVCLabels     = false
+	detachBusyMounts     = false
+	imagePullSecrets     = []string{}
+	imagePullPolicy      = ""
+	minDriveSize         = humanize.IBytes(sys.DefaultMinDriveSize)
+	mountRoot            = sys.DefaultMountRoot
+	nodeSelectorValues   = []string{}
+	tolerationValues     = []string{}
+	seccompProfile       = ""
+	apparmorProfile      = ""
+	crdTimeout           = 2 * time.Minute
 )
 
 func init() {
@@ -61,34 +84,89 @@ func init() {
 	installCmd.PersistentFlags().StringVarP(&image, "image", "i", image, "direct-csi image")
 	installCmd.PersistentFlags().StringVarP(&registry, "registry", "r", registry, "registry where direct-csi images are available")
 	installCmd.PersistentFlags().StringVarP(&org, "org", "g", org, "organization name where direct-csi images are available")
+	installCmd.PersistentFlags().StringSliceVarP(&imagePullSecrets, "image-pull-secret", "", imagePullSecrets, "name of the secret in the direct-csi namespace to pull the images from a private registry")
+	installCmd.PersistentFlags().StringVarP(&imagePullPolicy, "image-pull-policy", "", imagePullPolicy, "pull policy of the images, should be one of Always|IfNotPresent|Never")
 	installCmd.PersistentFlags().BoolVarP(&admissionControl, "admission-control", "", admissionControl, "turn on direct-csi admission controller")
 	installCmd.PersistentFlags().MarkDeprecated("crd", "Will be removed in version 1.5 or greater")
-	installCmd.PersistentFlags().StringSliceVarP(&nodeSelectorValues, "node-selector", "n", nodeSelectorValues, "node selector parameters")
-	installCmd.PersistentFlags().StringSliceVarP(&tolerationValues, "tolerations", "t", tolerationValues, "tolerations parameters")
+	installCmd.PersistentFlags().StringSliceVarP(&nodeSelectorValues, "node-selector", "n", nodeSelectorValues, "labels of the nodes to run the node driver on, e.g. direct.csi.min.io/storage=true")
+	installCmd.PersistentFlags().StringSliceVarP(&tolerationValues, "tolerations", "t", tolerationValues, "taints of the nodes tolerated by the node driver, e.g. storage=jbod:NoSchedule")
 	installCmd.PersistentFlags().StringVarP(&seccompProfile, "seccomp-profile", "", seccompProfile, "set Seccomp profile")
 	installCmd.PersistentFlags().StringVarP(&apparmorProfile, "apparmor-profile", "", apparmorProfile, "set Apparmor profile")
+	installCmd.PersistentFlags().StringSliceVarP(&includeDevices, "include-devices", "", includeDevices, "glob patterns of the device paths to be managed on the nodes, e.g. /dev/sd[b-z]")
+	installCmd.PersistentFlags().StringSliceVarP(&excludeDevices, "exclude-devices", "", excludeDevices, "glob patterns of the device paths to be ignored on the nodes, e.g. /dev/nvme0n1*")
+	installCmd.PersistentFlags().BoolVarP(&autoTier, "auto-tier", "", autoTier, "assign the access-tier of the discovered drives, hot for SSD/NVMe and cold for rotational drives")
+	installCmd.PersistentFlags().BoolVarP(&allowRemovable, "allow-removable", "", allowRemovable, "manage the removable drives like USB sticks and SD cards on the nodes, they are unavailable by default")
+	installCmd.PersistentFlags().StringSliceVarP(&topologyNodeLabels, "topology-node-labels", "", topologyNodeLabels, "labels of the nodes added to the topology of the drives, e.g. topology.kubernetes.io/zone")
+	installCmd.PersistentFlags().Int64VarP(&maxVolumesPerNode, "max-volumes-per-node", "", maxVolumesPerNode, "maximum number of volumes the scheduler may assign to each node, 0 is unlimited")
+	installCmd.PersistentFlags().DurationVarP(&smartInterval, "smart-interval", "", smartInterval, "interval between the reads of the SMART health of the drives on the nodes by smartctl, e.g. 10m; 0 disables it")
+	installCmd.PersistentFlags().IntVarP(&maxConcurrentFormats, "max-concurrent-formats", "", maxConcurrentFormats, "maximum number of drives formatted and mounted at once in each node; 0 uses the default of the node driver")
+	installCmd.PersistentFlags().DurationVarP(&reprobeInterval, "reprobe-interval", "", reprobeInterval, "interval between the re-probes of the devices of the drives on the nodes, e.g. 5m; 0 disables it")
+	installCmd.PersistentFlags().StringVarP(&metricsTokenSecret, "metrics-token-secret", "", metricsTokenSecret, "name of the secret in the direct-csi namespace with the bearer 'token' required to scrape the metrics of the nodes")
+	installCmd.PersistentFlags().BoolVarP(&metricsPVCLabels, "metrics-pvc-labels", "", metricsPVCLabels, "label the volume stats of the nodes by the name and namespace of the claims of the volumes")
+	installCmd.PersistentFlags().BoolVarP(&detachBusyMounts, "detach-busy-mounts", "", detachBusyMounts, "lazily detach the mount of a released drive on the nodes if it stays busy")
+	installCmd.PersistentFlags().StringVarP(&minDriveSize, "min-drive-size", "", minDriveSize, "size below which the devices on the nodes are not managed, e.g. 512MiB")
+	installCmd.PersistentFlags().StringVarP(&mountRoot, "mount-root", "", mountRoot, "directory on the nodes under which the drives are mounted")
+	installCmd.PersistentFlags().DurationVarP(&crdTimeout, "crd-timeout", "", crdTimeout, "maximum duration to wait for the crds to be established")
 
-	installCmd.PersistentFlags().BoolVarP(&loopBackOnly, "loopback-only", "", loopBackOnly, "Uses 4 free loopback devices per node and treat them as DirectCSIDrive resources. This is recommended only for testing/development purposes")
+	installCmd.PersistentFlags().BoolVarP(&loopBackOnly, "loopback-only", "", loopBackOnly, "Uses free loopback devices per node and treat them as DirectCSIDrive resources. This is recommended only for testing/development purposes")
 	installCmd.PersistentFlags().MarkHidden("loopback-only")
+	installCmd.PersistentFlags().IntVarP(&loopBackCount, "loopback-count", "", loopBackCount, "number of loopback devices per node, used with --loopback-only")
+	installCmd.PersistentFlags().MarkHidden("loopback-count")
 }
 
-func install(ctx context.Context, args []string) error {
+func install(ctx context.Context, args []string, loopBackCountSet bool) error {
 	if err := validImage(image); err != nil {
-		return fmt.Errorf("invalid argument. format of '--image' must be [image:tag] err=%v", err)
+		return newUsageError("invalid argument. format of '--image' must be [image:tag] err=%v", err)
 	}
 	if err := validOrg(org); err != nil {
-		return fmt.Errorf("invalid org. format of '--org' must be [a-zA-Z][a-zA-Z0-9-.]* err=%v", err)
+		return newUsageError("invalid org. format of '--org' must be [a-zA-Z][a-zA-Z0-9-.]* err=%v", err)
 	}
 	if err := validRegistry(registry); err != nil {
-		return fmt.Errorf("invalid registry. format of '--registry' must be [host:port?]")
+		return newUsageError("invalid registry. format of '--registry' must be [host:port?]")
+	}
+	if err := validImagePullPolicy(imagePullPolicy); err != nil {
+		return newUsageError("invalid argument. '--image-pull-policy' %v", err)
+	}
+	if err := validImagePullSecrets(imagePullSecrets); err != nil {
+		return newUsageError("invalid argument. '--image-pull-secret' %v", err)
+	}
+	if loopBackCountSet && !loopBackOnly {
+		return newUsageError("'--loopback-count' is only valid with '--loopback-only'")
+	}
+	if loopBackCount < 1 {
+		return newUsageError("invalid loopback count. '--loopback-count' must be at least 1")
+	}
+	if err := (sys.DeviceFilter{Include: includeDevices, Exclude: excludeDevices}).Validate(); err != nil {
+		return newUsageError("invalid argument. '--include-devices' and '--exclude-devices' must be valid glob patterns err=%v", err)
+	}
+	if _, err := humanize.ParseBytes(minDriveSize); err != nil {
+		return newUsageError("invalid argument. '--min-drive-size' must be a valid size err=%v", err)
+	}
+	if !filepath.IsAbs(mountRoot) {
+		return newUsageError("invalid argument. '--mount-root' must be an absolute path")
+	}
+	if maxVolumesPerNode < 0 {
+		return newUsageError("invalid argument. '--max-volumes-per-node' must not be negative")
+	}
+	if smartInterval < 0 {
+		return newUsageError("invalid argument. '--smart-interval' must not be negative")
+	}
+	if maxConcurrentFormats < 0 {
+		return newUsageError("invalid argument. '--max-concurrent-formats' must not be negative")
+	}
+	if reprobeInterval < 0 {
+		return newUsageError("invalid argument. '--reprobe-interval' must not be negative")
+	}
+	if dryRun && json {
+		return newUsageError("'--dry-run' prints the manifests in yaml only")
 	}
 	nodeSelector, err := parseNodeSelector(nodeSelectorValues)
 	if err != nil {
-		return fmt.Errorf("invalid node selector. format of '--node-selector' must be [<key>=<value>]")
+		return newUsageError("invalid node selector. format of '--node-selector' must be [<key>=<value>]; %v", err)
 	}
 	tolerations, err := parseTolerations(tolerationValues)
 	if err != nil {
-		return fmt.Errorf("invalid tolerations. format of '--tolerations' must be <key>[=value]:<NoSchedule|PreferNoSchedule|NoExecute>")
+		return newUsageError("invalid tolerations. format of '--tolerations' must be <key>[=value]:<NoSchedule|PreferNoSchedule|NoExecute>; %v", err)
 	}
 
 	if err := installer.CreateNamespace(ctx, identity, dryRun); err != nil {
@@ -100,7 +178,7 @@ func install(ctx context.Context, args []string) error {
 		klog.Infof("'%s' namespace created", utils.Bold(identity))
 	}
 
-	if err := installer.CreatePodSecurityPolicy(ctx, identity, dryRun); err != nil {
+	if err := installer.CreatePodSecurityPolicy(ctx, identity, mountRoot, dryRun); err != nil {
 		switch {
 		case errors.Is(err, installer.ErrKubeVersionNotSupported):
 			klog.Infof("pod security policy is not supported in your kubernetes")
@@ -120,15 +198,17 @@ func install(ctx context.Context, args []string) error {
 		klog.Infof("'%s' rbac roles created", utils.Bold(identity))
 	}
 
-	if err := installer.CreateOrUpdateConversionDeployment(ctx, identity, image, dryRun, registry, org); err != nil {
+	if err := installer.CreateOrUpdateConversionDeployment(ctx, identity, image, dryRun, registry, org, imagePullSecrets, corev1.PullPolicy(imagePullPolicy)); err != nil {
 		return err
 	}
 	if !dryRun {
 		klog.Infof("'%s' conversion deployment created", utils.Bold(identity))
 	}
 
+	crdsUpgraded := false
 crdInstall:
-	if err := registerCRDs(ctx, identity); err != nil {
+	crdsUpgraded, err = registerCRDs(ctx, identity)
+	if err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			return err
 		}
@@ -145,6 +225,9 @@ crdInstall:
 		}
 	}
 	if !dryRun {
+		if err := waitForCRDsEstablished(ctx, utils.GetCRDClient(), []string{driveCRDName, volumeCRDName, snapshotCRDName}, crdTimeout); err != nil {
+			return err
+		}
 		klog.Infof("crds successfully registered")
 	}
 
@@ -175,7 +258,7 @@ crdInstall:
 		klog.Infof("'%s' service created", utils.Bold(identity))
 	}
 
-	if err := installer.CreateDaemonSet(ctx, identity, image, dryRun, registry, org, loopBackOnly, nodeSelector, tolerations, seccompProfile, apparmorProfile); err != nil {
+	if err := installer.CreateDaemonSet(ctx, identity, image, dryRun, registry, org, imagePullSecrets, corev1.PullPolicy(imagePullPolicy), loopBackOnly, loopBackCount, includeDevices, excludeDevices, minDriveSize, mountRoot, autoTier, allowRemovable, topologyNodeLabels, maxVolumesPerNode, smartInterval, maxConcurrentFormats, reprobeInterval, metricsTokenSecret, metricsPVCLabels, detachBusyMounts, nodeSelector, tolerations, seccompProfile, apparmorProfile); err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			return err
 		}
@@ -184,7 +267,7 @@ crdInstall:
 		klog.Infof("'%s' daemonset created", utils.Bold(identity))
 	}
 
-	if err := installer.CreateDeployment(ctx, identity, image, dryRun, registry, org); err != nil {
+	if err := installer.CreateDeployment(ctx, identity, image, dryRun, registry, org, imagePullSecrets, corev1.PullPolicy(imagePullPolicy)); err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			return err
 		}
@@ -204,5 +287,10 @@ crdInstall:
 		}
 	}
 
+	if crdsUpgraded && !dryRun {
+		// the existing objects are still stored in the older versions
+		return migrateObjects(ctx)
+	}
+
 	return nil
 }
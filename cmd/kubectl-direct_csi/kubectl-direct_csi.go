@@ -18,14 +18,11 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/minio/direct-csi/pkg/utils"
-
 	"k8s.io/klog/v2"
 )
 
@@ -43,7 +40,7 @@ func main() {
 	}()
 
 	if err := Execute(ctx); err != nil {
-		fmt.Println(utils.Bold(utils.Red("ERROR")), err)
-		os.Exit(1)
+		printError(os.Stderr, err)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,156 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+)
+
+var drainNodeCmd = &cobra.Command{
+	Use:   "drain <node>",
+	Short: "cordon all the drives of a node and list the volumes to relocate",
+	Long: `
+The ready and in-use drives of the node are cordoned, so that no new volumes are
+scheduled on them. The volumes remaining on the drives are listed, they must be
+relocated before the node is decommissioned. The node is drained once no volumes
+remain, running the command again reports the progress.`,
+	Example: `
+ # Drain the drives of a node
+ $ kubectl direct-csi nodes drain directcsi-1
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return drainNode(c.Context(), args)
+	},
+}
+
+var uncordonNodeCmd = &cobra.Command{
+	Use:   "uncordon <node>",
+	Short: "uncordon all the drives of a node",
+	Example: `
+ # Make the drives of a node schedulable again
+ $ kubectl direct-csi nodes uncordon directcsi-1
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return uncordonNode(c.Context(), args)
+	},
+}
+
+// listNodeDrives lists the drives of the node
+func listNodeDrives(ctx context.Context, node string) ([]directcsi.DirectCSIDrive, error) {
+	driveList, err := utils.GetDirectCSIClient().DirectCSIDrives().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	nodeDrives := []directcsi.DirectCSIDrive{}
+	for _, d := range driveList.Items {
+		if d.Status.NodeName == node {
+			nodeDrives = append(nodeDrives, d)
+		}
+	}
+	if len(nodeDrives) == 0 {
+		return nil, newNotFoundError("no drives found in node %s", bold(node))
+	}
+	return nodeDrives, nil
+}
+
+func drainNode(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return newUsageError("Invalid input arguments. Please use '%s' for examples to drain a node", utils.Bold("--help"))
+	}
+	node := args[0]
+
+	nodeDrives, err := listNodeDrives(ctx, node)
+	if err != nil {
+		return err
+	}
+	volumeList, err := utils.GetDirectCSIClient().DirectCSIVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return newAPIError(err)
+	}
+
+	cordoned := 0
+	volumes := []directcsi.DirectCSIVolume{}
+	for _, d := range nodeDrives {
+		switch d.Status.DriveStatus {
+		case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+			changed, err := setDriveCordon(ctx, d, true)
+			if err != nil {
+				return err
+			}
+			if changed {
+				cordoned++
+			}
+		}
+		volumes = ListVolumesInDrive(d, volumeList, volumes)
+	}
+	if dryRun {
+		return nil
+	}
+
+	fmt.Printf("%d drive(s) cordoned in node %s\n", cordoned, node)
+	if len(volumes) == 0 {
+		fmt.Printf("node %s is drained, no volumes remain on its drives\n", node)
+		return nil
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	fmt.Printf("%d volume(s) must be relocated before node %s is drained:\n", len(volumes), node)
+	for _, v := range volumes {
+		fmt.Printf("  %s on drive %s (pod %s/%s)\n",
+			v.Name,
+			v.Status.Drive,
+			printableString(v.GetLabels()[utils.PodNamespaceLabel]),
+			printableString(v.GetLabels()[utils.PodNameLabel]))
+	}
+	return nil
+}
+
+func uncordonNode(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return newUsageError("Invalid input arguments. Please use '%s' for examples to uncordon a node", utils.Bold("--help"))
+	}
+	node := args[0]
+
+	nodeDrives, err := listNodeDrives(ctx, node)
+	if err != nil {
+		return err
+	}
+	uncordoned := 0
+	for _, d := range nodeDrives {
+		changed, err := setDriveCordon(ctx, d, false)
+		if err != nil {
+			return err
+		}
+		if changed {
+			uncordoned++
+		}
+	}
+	if !dryRun {
+		fmt.Printf("%d drive(s) uncordoned in node %s\n", uncordoned, node)
+	}
+	return nil
+}
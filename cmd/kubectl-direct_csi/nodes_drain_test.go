@@ -0,0 +1,155 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDrainNode(t *testing.T) {
+	createTestDrive := func(node, name string, driveStatus directcsi.DriveStatus) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:    node,
+				DriveStatus: driveStatus,
+			},
+		}
+	}
+	testObjects := []runtime.Object{
+		createTestDrive("n1", "d1", directcsi.DriveStatusReady),
+		createTestDrive("n1", "d2", directcsi.DriveStatusInUse),
+		createTestDrive("n1", "d3", directcsi.DriveStatusAvailable),
+		createTestDrive("n2", "d4", directcsi.DriveStatusReady),
+		&directcsi.DirectCSIVolume{
+			TypeMeta:   utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: "v1"},
+			Status: directcsi.DirectCSIVolumeStatus{
+				NodeName: "n1",
+				Drive:    "d2",
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	testClient := fakedirect.NewSimpleClientset(testObjects...).DirectV1beta2()
+	utils.SetFakeDirectCSIClient(testClient)
+
+	getCordonedDrives := func() []string {
+		driveList, err := testClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Error while listing the drives: %v", err)
+		}
+		cordoned := []string{}
+		for _, d := range driveList.Items {
+			if isDriveCordoned(d) {
+				cordoned = append(cordoned, d.Name)
+			}
+		}
+		sort.Strings(cordoned)
+		return cordoned
+	}
+
+	// draining twice leaves the same drives cordoned
+	for i := 0; i < 2; i++ {
+		if err := drainNode(ctx, []string{"n1"}); err != nil {
+			t.Fatalf("Error while draining the node: %v", err)
+		}
+		if cordoned := getCordonedDrives(); !reflect.DeepEqual(cordoned, []string{"d1", "d2"}) {
+			t.Fatalf("Expected drives [d1 d2] to be cordoned, got %v", cordoned)
+		}
+	}
+
+	if err := drainNode(ctx, []string{"n3"}); err == nil {
+		t.Errorf("Expected an error while draining a node without drives")
+	}
+	if err := drainNode(ctx, []string{}); err == nil {
+		t.Errorf("Expected an error without a node")
+	}
+
+	if err := uncordonNode(ctx, []string{"n1"}); err != nil {
+		t.Fatalf("Error while uncordoning the node: %v", err)
+	}
+	if cordoned := getCordonedDrives(); len(cordoned) != 0 {
+		t.Errorf("Expected no cordoned drives, got %v", cordoned)
+	}
+}
+
+func TestSetDriveCordonConflict(t *testing.T) {
+	testDrive := &directcsi.DirectCSIDrive{
+		TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{Name: "d1"},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:    "n1",
+			DriveStatus: directcsi.DriveStatusReady,
+		},
+	}
+
+	ctx := context.TODO()
+	fakeClient := fakedirect.NewSimpleClientset(testDrive)
+	utils.SetFakeDirectCSIClient(fakeClient.DirectV1beta2())
+
+	// the node driver updates the drive after it was listed
+	conflicts := 0
+	fakeClient.PrependReactor("update", "directcsidrives", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		synced := testDrive.DeepCopy()
+		synced.Status.FreeCapacity = 10 << 20
+		if err := fakeClient.Tracker().Update(directcsi.SchemeGroupVersion.WithResource("directcsidrives"), synced, ""); err != nil {
+			t.Fatalf("Error while updating the drive object: %v", err)
+		}
+		return true, nil, apierrors.NewConflict(directcsi.Resource("directcsidrives"), testDrive.Name, errors.New("object was modified"))
+	})
+
+	changed, err := setDriveCordon(ctx, *testDrive, true)
+	if err != nil {
+		t.Fatalf("Error while cordoning the drive: %v", err)
+	}
+	if !changed {
+		t.Errorf("Expected the drive to be cordoned")
+	}
+
+	drive, err := fakeClient.DirectV1beta2().DirectCSIDrives().Get(ctx, testDrive.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error while getting the drive object: %v", err)
+	}
+	if !isDriveCordoned(*drive) {
+		t.Errorf("Drive %s is not cordoned", drive.Name)
+	}
+	if drive.Status.FreeCapacity != 10<<20 {
+		t.Errorf("Expected the synced free capacity to be kept, got %d", drive.Status.FreeCapacity)
+	}
+}
@@ -20,82 +20,193 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"k8s.io/apiextensions-apiserver/pkg/apihelpers"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
 
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/converter"
 	"github.com/minio/direct-csi/pkg/installer"
 	"github.com/minio/direct-csi/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
 	currentCRDStorageVersion = "v1beta2"
 	driveCRDName             = "directcsidrives.direct.csi.min.io"
 	volumeCRDName            = "directcsivolumes.direct.csi.min.io"
+	snapshotCRDName          = "directcsisnapshots.direct.csi.min.io"
+
+	crdPollInterval = time.Second
 )
 
-func registerCRDs(ctx context.Context, identity string) error {
+// registerCRDs creates or updates the CRDs and reports if the storage version of the existing CRDs was updated
+func registerCRDs(ctx context.Context, identity string) (bool, error) {
 	crdObjs := []runtime.Object{}
 	for _, asset := range AssetNames() {
 		crdBytes, err := Asset(asset)
 		if err != nil {
-			return err
+			return false, err
 		}
 		crdObj, err := utils.ParseSingleKubeNativeFromBytes(crdBytes)
 		if err != nil {
-			return err
+			return false, err
 		}
 		crdObjs = append(crdObjs, crdObj)
 	}
 
 	crdClient := utils.GetCRDClient()
+	upgraded := false
 	for _, crd := range crdObjs {
 		var crdObj apiextensions.CustomResourceDefinition
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(crd.(*unstructured.Unstructured).Object, &crdObj); err != nil {
-			return err
+			return false, err
 		}
+		setCRDVersionLabel(&crdObj)
 
 		existingCRD, err := crdClient.Get(ctx, crdObj.Name, metav1.GetOptions{})
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return err
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		registered := err == nil
+		if registered {
+			if err := checkCRDDowngrade(existingCRD); err != nil {
+				if !overwriteCRD {
+					return false, err
+				}
+				klog.Warningf("%v; proceeding as --force is set", err)
 			}
+		}
+		if !registered || dryRun {
 			if err := setConversionWebhook(ctx, &crdObj, identity); err != nil {
-				return err
+				return false, err
 			}
 			if dryRun {
+				// the bundled CRDs are printed whether they are registered or not
 				if err := utils.LogYAML(crdObj); err != nil {
-					return err
+					return false, err
 				}
 				continue
 			}
 			if _, err := crdClient.Create(ctx, &crdObj, metav1.CreateOptions{}); err != nil {
-				return err
+				return false, err
 			}
 			continue
 		}
-		if err := syncCRD(ctx, existingCRD, crdObj, identity); err != nil {
-			return err
+		updated, err := syncCRD(ctx, existingCRD, crdObj, identity)
+		if err != nil {
+			return false, err
 		}
+		upgraded = upgraded || updated
+	}
+	return upgraded, nil
+}
+
+// setCRDVersionLabel labels the CRD with the version of direct-csi registering it
+func setCRDVersionLabel(crd *apiextensions.CustomResourceDefinition) {
+	labels := crd.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[utils.VersionLabel] = directcsi.Version
+	crd.SetLabels(labels)
+}
+
+// checkCRDDowngrade returns an error if the existing CRD was registered by a newer version of direct-csi.
+// The CRDs registered before the version label was introduced are compared by their storage version.
+func checkCRDDowngrade(existingCRD *apiextensions.CustomResourceDefinition) error {
+	installedVersion := existingCRD.GetLabels()[utils.VersionLabel]
+	if installedVersion == "" {
+		storageVersion, err := apihelpers.GetCRDStorageVersion(existingCRD)
+		if err != nil {
+			return nil
+		}
+		installedVersion = storageVersion
+	}
+	if version.CompareKubeAwareVersionStrings(installedVersion, directcsi.Version) > 0 {
+		return fmt.Errorf("installed version '%s' of crd '%s' is newer than the version '%s' of this plugin; please use a newer plugin or set '--force' to downgrade",
+			installedVersion, existingCRD.Name, directcsi.Version)
 	}
 	return nil
 }
 
-func syncCRD(ctx context.Context, existingCRD *apiextensions.CustomResourceDefinition, newCRD apiextensions.CustomResourceDefinition, identity string) error {
+// crdNotEstablishedReason returns why the CRD is not served by the apiserver yet, empty if it is established
+func crdNotEstablishedReason(crd *apiextensions.CustomResourceDefinition) string {
+	if apihelpers.IsCRDConditionTrue(crd, apiextensions.Established) {
+		return ""
+	}
+	if condition := apihelpers.FindCRDCondition(crd, apiextensions.NamesAccepted); condition != nil && condition.Status == apiextensions.ConditionFalse {
+		return fmt.Sprintf("names not accepted: %s", condition.Message)
+	}
+	if condition := apihelpers.FindCRDCondition(crd, apiextensions.Established); condition != nil && condition.Message != "" {
+		return condition.Message
+	}
+	return "not established yet"
+}
+
+// waitForCRDsEstablished polls the CRDs until the apiserver has established all of them,
+// the CRDs still pending after the timeout are reported in the error along with the reason
+func waitForCRDsEstablished(ctx context.Context, crdClient apiextensionsclient.CustomResourceDefinitionInterface, crdNames []string, timeout time.Duration) error {
+	pending := map[string]string{}
+	for _, name := range crdNames {
+		pending[name] = "not found"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(crdPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for name := range pending {
+			crd, err := crdClient.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				klog.V(3).Infof("Error while getting crd %s: %v", name, err)
+				pending[name] = err.Error()
+				continue
+			}
+			if reason := crdNotEstablishedReason(crd); reason != "" {
+				pending[name] = reason
+				continue
+			}
+			klog.V(3).Infof("crd %s established", name)
+			delete(pending, name)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			failures := []string{}
+			for name, reason := range pending {
+				failures = append(failures, fmt.Sprintf("%s (%s)", name, reason))
+			}
+			sort.Strings(failures)
+			return fmt.Errorf("%d of %d crd(s) not established after %v: [%s]", len(failures), len(crdNames), timeout, strings.Join(failures, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncCRD updates the storage version of the existing CRD and reports if it was updated
+func syncCRD(ctx context.Context, existingCRD *apiextensions.CustomResourceDefinition, newCRD apiextensions.CustomResourceDefinition, identity string) (bool, error) {
 	existingCRDStorageVersion, err := apihelpers.GetCRDStorageVersion(existingCRD)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if existingCRDStorageVersion == currentCRDStorageVersion {
-		return nil // CRDs already updated and holds the latest version
+		return false, nil // CRDs already updated and holds the latest version
 	}
 
 	// Set all the existing versions to false
@@ -107,34 +218,39 @@ func syncCRD(ctx context.Context, existingCRD *apiextensions.CustomResourceDefin
 
 	latestVersionObject, err := getLatestCRDVersionObject(newCRD)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	existingCRD.Spec.Versions = append(existingCRD.Spec.Versions, latestVersionObject)
+	setCRDVersionLabel(existingCRD)
 
 	if err := setConversionWebhook(ctx, existingCRD, identity); err != nil {
-		return err
+		return false, err
 	}
 
 	if dryRun {
 		existingCRD.TypeMeta = newCRD.TypeMeta
 		if err := utils.LogYAML(existingCRD); err != nil {
-			return err
+			return false, err
 		}
-		return nil
+		return false, nil
 	}
 
 	crdClient := utils.GetCRDClient()
 	if _, err := crdClient.Update(ctx, existingCRD, metav1.UpdateOptions{}); err != nil {
-		return err
+		return false, err
 	}
 
 	klog.Infof("'%s' CRD succesfully updated to '%s'", existingCRD.Name, utils.Bold(currentCRDStorageVersion))
 
-	return nil
+	return true, nil
 }
 
 func setConversionWebhook(ctx context.Context, crdObj *apiextensions.CustomResourceDefinition, identity string) error {
+	if crdObj.Name == snapshotCRDName {
+		// snapshots are served in a single version, hence there is nothing to convert
+		return nil
+	}
 
 	if !dryRun {
 		// Wait for conversion deployment to be live
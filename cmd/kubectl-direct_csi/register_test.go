@@ -0,0 +1,154 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	fakeapiextensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWaitForCRDsEstablished(t1 *testing.T) {
+	newCRD := func(name string, conditions ...apiextensions.CustomResourceDefinitionCondition) *apiextensions.CustomResourceDefinition {
+		return &apiextensions.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     apiextensions.CustomResourceDefinitionStatus{Conditions: conditions},
+		}
+	}
+	established := apiextensions.CustomResourceDefinitionCondition{
+		Type:   apiextensions.Established,
+		Status: apiextensions.ConditionTrue,
+	}
+	namesNotAccepted := apiextensions.CustomResourceDefinitionCondition{
+		Type:    apiextensions.NamesAccepted,
+		Status:  apiextensions.ConditionFalse,
+		Message: "\"directcsivolumes\" is already in use",
+	}
+
+	testCases := []struct {
+		name             string
+		objects          []runtime.Object
+		expectedFailures []string
+	}{
+		{
+			name:    "test1",
+			objects: []runtime.Object{newCRD(driveCRDName, established), newCRD(volumeCRDName, established)},
+		},
+		{
+			name:             "test2",
+			objects:          []runtime.Object{newCRD(driveCRDName, established), newCRD(volumeCRDName)},
+			expectedFailures: []string{"1 of 2", volumeCRDName + " (not established yet)"},
+		},
+		{
+			name:             "test3",
+			objects:          []runtime.Object{newCRD(volumeCRDName, namesNotAccepted)},
+			expectedFailures: []string{"2 of 2", driveCRDName, "names not accepted"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			crdClient := fakeapiextensions.NewSimpleClientset(tt.objects...).ApiextensionsV1().CustomResourceDefinitions()
+			err := waitForCRDsEstablished(context.Background(), crdClient, []string{driveCRDName, volumeCRDName}, 100*time.Millisecond)
+			if len(tt.expectedFailures) == 0 {
+				if err != nil {
+					t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+				}
+				return
+			}
+			if err == nil {
+				t1.Fatalf("Test case name %s: expected error but got none", tt.name)
+			}
+			for _, failure := range tt.expectedFailures {
+				if !strings.Contains(err.Error(), failure) {
+					t1.Errorf("Test case name %s: expected %q in error %v", tt.name, failure, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCRDDowngrade(t1 *testing.T) {
+	newCRD := func(versionLabel string, storageVersions ...string) *apiextensions.CustomResourceDefinition {
+		crd := &apiextensions.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: driveCRDName},
+		}
+		if versionLabel != "" {
+			crd.Labels = map[string]string{utils.VersionLabel: versionLabel}
+		}
+		for _, storageVersion := range storageVersions {
+			crd.Spec.Versions = append(crd.Spec.Versions, apiextensions.CustomResourceDefinitionVersion{Name: storageVersion, Storage: true})
+		}
+		return crd
+	}
+
+	testCases := []struct {
+		name        string
+		crd         *apiextensions.CustomResourceDefinition
+		expectedErr bool
+	}{
+		{
+			name: "sameVersion",
+			crd:  newCRD(directcsi.Version),
+		},
+		{
+			name: "olderVersion",
+			crd:  newCRD("v1beta1"),
+		},
+		{
+			name:        "newerVersion",
+			crd:         newCRD("v1"),
+			expectedErr: true,
+		},
+		{
+			name: "olderStorageVersionWithoutLabel",
+			crd:  newCRD("", "v1beta1"),
+		},
+		{
+			name:        "newerStorageVersionWithoutLabel",
+			crd:         newCRD("", "v1beta3"),
+			expectedErr: true,
+		},
+		{
+			name: "noVersion",
+			crd:  newCRD(""),
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := checkCRDDowngrade(tt.crd)
+			if (err != nil) != tt.expectedErr {
+				t1.Fatalf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "is newer than") {
+				t1.Errorf("Test case name %s: unexpected error message %v", tt.name, err)
+			}
+		})
+	}
+}
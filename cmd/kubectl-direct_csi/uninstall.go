@@ -60,6 +60,30 @@ func uninstall(ctx context.Context, args []string) error {
 	directCSIClient := utils.GetDirectCSIClient()
 
 	if uninstallCRD {
+		snapshots, err := directCSIClient.DirectCSISnapshots().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		if len(snapshots.Items) > 0 && !forceRemove {
+			klog.Errorf("Cannot unregister CRDs. Please use `%s` to delete the resources", utils.Bold("--force"))
+			return nil
+		}
+
+		for _, s := range snapshots.Items {
+			s.ObjectMeta.SetFinalizers([]string{})
+			if _, err := directCSIClient.DirectCSISnapshots().Update(ctx, &s, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+			if err := directCSIClient.DirectCSISnapshots().Delete(ctx, s.Name, metav1.DeleteOptions{}); err != nil {
+				if !errors.IsNotFound(err) {
+					return err
+				}
+			}
+		}
+
 		volumes, err := directCSIClient.DirectCSIVolumes().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			if !errors.IsNotFound(err) {
@@ -49,25 +49,6 @@ func ListVolumesInDrive(drive directcsi.DirectCSIDrive, volumes *directcsi.Direc
 	return vols
 }
 
-func getAccessTierSet(accessTiers []string) ([]directcsi.AccessTier, error) {
-	var atSet []directcsi.AccessTier
-	for i := range accessTiers {
-		if accessTiers[i] == "*" {
-			return []directcsi.AccessTier{
-				directcsi.AccessTierHot,
-				directcsi.AccessTierWarm,
-				directcsi.AccessTierCold,
-			}, nil
-		}
-		at, err := utils.ValidateAccessTier(strings.TrimSpace(accessTiers[i]))
-		if err != nil {
-			return atSet, err
-		}
-		atSet = append(atSet, at)
-	}
-	return atSet, nil
-}
-
 func printableString(s string) string {
 	if s == "" {
 		return "-"
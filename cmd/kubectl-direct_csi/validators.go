@@ -24,7 +24,9 @@ import (
 	"strings"
 
 	"github.com/docker/distribution/reference"
+	"github.com/minio/direct-csi/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type parseFunc func(r rune) (interface{}, bool, error)
@@ -122,6 +124,44 @@ func ErrInvalid(expected string, r rune) error {
 	return fmt.Errorf("expected %s, found %q", expected, r)
 }
 
+// validImagePullPolicy checks if the pull policy is one of kubernetes, an empty policy is defaulted by kubernetes
+func validImagePullPolicy(policy string) error {
+	switch corev1.PullPolicy(policy) {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return nil
+	}
+	return fmt.Errorf("%q must be one of %v|%v|%v", policy, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+}
+
+// validImagePullSecrets checks if the names of the pull secrets are valid secret names
+func validImagePullSecrets(secrets []string) error {
+	for _, secret := range secrets {
+		if errs := validation.IsDNS1123Subdomain(secret); len(errs) > 0 {
+			return fmt.Errorf("%q must be a valid secret name; %v", secret, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// validateReservedPercent checks if the percentage of the drive capacity to reserve is within the limit
+func validateReservedPercent(percent int64) error {
+	if percent < 0 || percent > utils.MaxReservedPercent {
+		return fmt.Errorf("invalid reserved percent %d, it should be between 0 and %d", percent, utils.MaxReservedPercent)
+	}
+	return nil
+}
+
+// validateLabel checks if the key and the value are a valid label of a node
+func validateLabel(key, value string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid key %q; %v", key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid value %q; %v", value, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 func parseNodeSelector(values []string) (map[string]string, error) {
 	nodeSelector := map[string]string{}
 	for _, value := range values {
@@ -132,6 +172,9 @@ func parseNodeSelector(values []string) (map[string]string, error) {
 		if tokens[0] == "" {
 			return nil, fmt.Errorf("invalid key in node selector value %v", value)
 		}
+		if err := validateLabel(tokens[0], tokens[1]); err != nil {
+			return nil, fmt.Errorf("invalid node selector value %v; %v", value, err)
+		}
 		nodeSelector[tokens[0]] = tokens[1]
 	}
 	return nodeSelector, nil
@@ -171,6 +214,9 @@ func parseTolerations(values []string) ([]corev1.Toleration, error) {
 			}
 			v, e = tokens[0], tokens[1]
 		}
+		if err := validateLabel(k, v); err != nil {
+			return nil, fmt.Errorf("invalid toleration %v; %v", value, err)
+		}
 		effect := corev1.TaintEffect(e)
 		switch effect {
 		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
@@ -0,0 +1,118 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseNodeSelector(t *testing.T) {
+	testCases := []struct {
+		values       []string
+		expected     map[string]string
+		expectedFail bool
+	}{
+		{[]string{}, map[string]string{}, false},
+		{[]string{"direct.csi.min.io/storage=true", "zone=a"}, map[string]string{"direct.csi.min.io/storage": "true", "zone": "a"}, false},
+		{[]string{"storage="}, map[string]string{"storage": ""}, false},
+		{[]string{"storage"}, nil, true},
+		{[]string{"=true"}, nil, true},
+		{[]string{"storage=a=b"}, nil, true},
+		{[]string{"storage node=true"}, nil, true},
+		{[]string{"storage=true value"}, nil, true},
+		{[]string{"-storage=true"}, nil, true},
+	}
+	for i, testCase := range testCases {
+		result, err := parseNodeSelector(testCase.values)
+		if testCase.expectedFail {
+			if err == nil {
+				t.Errorf("case %v: expected error, got none", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %v: unexpected error: %v", i+1, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, testCase.expected) {
+			t.Errorf("case %v: expected: %v, got: %v", i+1, testCase.expected, result)
+		}
+	}
+}
+
+func TestParseTolerations(t *testing.T) {
+	testCases := []struct {
+		values       []string
+		expected     []corev1.Toleration
+		expectedFail bool
+	}{
+		{[]string{}, []corev1.Toleration{}, false},
+		{
+			[]string{"storage=jbod:NoSchedule", "maintenance:NoExecute"},
+			[]corev1.Toleration{
+				{Key: "storage", Operator: corev1.TolerationOpEqual, Value: "jbod", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "maintenance", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+			},
+			false,
+		},
+		{[]string{"storage=jbod"}, nil, true},
+		{[]string{"storage"}, nil, true},
+		{[]string{"storage:Never"}, nil, true},
+		{[]string{"=jbod:NoSchedule"}, nil, true},
+		{[]string{"storage node=jbod:NoSchedule"}, nil, true},
+		{[]string{"storage=jbod disk:NoSchedule"}, nil, true},
+	}
+	for i, testCase := range testCases {
+		result, err := parseTolerations(testCase.values)
+		if testCase.expectedFail {
+			if err == nil {
+				t.Errorf("case %v: expected error, got none", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %v: unexpected error: %v", i+1, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, testCase.expected) {
+			t.Errorf("case %v: expected: %v, got: %v", i+1, testCase.expected, result)
+		}
+	}
+}
+
+func TestValidImagePullOptions(t *testing.T) {
+	for _, policy := range []string{"", "Always", "IfNotPresent", "Never"} {
+		if err := validImagePullPolicy(policy); err != nil {
+			t.Errorf("policy %q: unexpected error: %v", policy, err)
+		}
+	}
+	for _, policy := range []string{"always", "IfPresent"} {
+		if err := validImagePullPolicy(policy); err == nil {
+			t.Errorf("policy %q: expected error, got none", policy)
+		}
+	}
+
+	if err := validImagePullSecrets([]string{"regcred", "quay.io-pull"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validImagePullSecrets([]string{"regcred", "Reg_Cred"}); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
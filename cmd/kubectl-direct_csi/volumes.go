@@ -38,5 +38,7 @@ var volumesCmd = &cobra.Command{
 
 func init() {
 	volumesCmd.AddCommand(listVolumesCmd)
-	//volumesCmd.AddCommand(purgeVolumesCmd)
+	volumesCmd.AddCommand(describeVolumesCmd)
+	volumesCmd.AddCommand(migrateVolumesCmd)
+	volumesCmd.AddCommand(purgeVolumesCmd)
 }
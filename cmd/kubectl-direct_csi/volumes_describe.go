@@ -0,0 +1,206 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var describeVolumesCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "describe a volume in the DirectCSI cluster",
+	Long:  "",
+	Example: `
+# Describe a volume with its conditions and the live quota usage
+$ kubectl direct-csi volumes describe pvc-0f2a3b1c-9d4e-4f5a-8b6c-7d8e9f0a1b2c
+`,
+	RunE: func(c *cobra.Command, args []string) error {
+		return describeVolume(c.Context(), args)
+	},
+}
+
+// volumeUsage is the live quota usage of a volume as reported by the kubelet
+type volumeUsage struct {
+	CapacityBytes  uint64
+	UsedBytes      uint64
+	AvailableBytes uint64
+}
+
+// kubeletStatsSummary is the subset of the kubelet stats summary holding the volume usage
+type kubeletStatsSummary struct {
+	Pods []struct {
+		Volume []struct {
+			PVCRef *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef,omitempty"`
+			CapacityBytes  *uint64 `json:"capacityBytes,omitempty"`
+			UsedBytes      *uint64 `json:"usedBytes,omitempty"`
+			AvailableBytes *uint64 `json:"availableBytes,omitempty"`
+		} `json:"volume,omitempty"`
+	} `json:"pods"`
+}
+
+// parseVolumeUsage finds the usage of the claim in the kubelet stats summary
+func parseVolumeUsage(summary []byte, namespace, claimName string) (*volumeUsage, error) {
+	var stats kubeletStatsSummary
+	if err := encodingjson.Unmarshal(summary, &stats); err != nil {
+		return nil, err
+	}
+
+	value := func(v *uint64) uint64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	for _, pod := range stats.Pods {
+		for _, volume := range pod.Volume {
+			if volume.PVCRef == nil || volume.PVCRef.Namespace != namespace || volume.PVCRef.Name != claimName {
+				continue
+			}
+			return &volumeUsage{
+				CapacityBytes:  value(volume.CapacityBytes),
+				UsedBytes:      value(volume.UsedBytes),
+				AvailableBytes: value(volume.AvailableBytes),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no stats reported for claim %s/%s; the volume may not be published", namespace, claimName)
+}
+
+// getVolumeUsage fetches the live usage of the volume from the kubelet of its node. The kubelet gathers
+// it through NodeGetVolumeStats, which reads the xfs quota of the volume on the drive.
+func getVolumeUsage(ctx context.Context, volume *directcsi.DirectCSIVolume) (*volumeUsage, error) {
+	pv, err := utils.GetKubeClient().CoreV1().PersistentVolumes().Get(ctx, volume.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pv.Spec.ClaimRef == nil {
+		return nil, fmt.Errorf("persistent volume %s is not bound to a claim", pv.Name)
+	}
+
+	summary, err := utils.GetKubeClient().CoreV1().RESTClient().
+		Get().
+		AbsPath("/api/v1/nodes", volume.Status.NodeName, "proxy", "stats", "summary").
+		Timeout(10 * time.Second).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+	return parseVolumeUsage(summary, pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+}
+
+func printVolumeDescription(w io.Writer, volume *directcsi.DirectCSIVolume, drive *directcsi.DirectCSIDrive, usage *volumeUsage, usageErr error) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	driveName := volume.Status.Drive
+	if drive != nil {
+		driveName = fmt.Sprintf("%s (%s)", drive.Name, canonicalNameFromPath(drive.Status.Path))
+	}
+	pod := "-"
+	if podName := volume.ObjectMeta.Labels[directcsi.Group+"/pod.name"]; podName != "" {
+		pod = volume.ObjectMeta.Labels[directcsi.Group+"/pod.namespace"] + "/" + podName
+	}
+	usageString := func() string {
+		if usageErr != nil {
+			return fmt.Sprintf("unavailable (%v)", usageErr)
+		}
+		return fmt.Sprintf("%s used, %s available of %s",
+			humanize.IBytes(usage.UsedBytes),
+			humanize.IBytes(usage.AvailableBytes),
+			humanize.IBytes(usage.CapacityBytes))
+	}
+
+	fmt.Fprintf(tw, "Name:\t%s\n", volume.Name)
+	fmt.Fprintf(tw, "Node:\t%s\n", printableString(volume.Status.NodeName))
+	fmt.Fprintf(tw, "Drive:\t%s\n", printableString(driveName))
+	fmt.Fprintf(tw, "Pod:\t%s\n", pod)
+	fmt.Fprintf(tw, "Capacity:\t%s\n", humanize.IBytes(uint64(volume.Status.TotalCapacity)))
+	fmt.Fprintf(tw, "Usage:\t%s\n", usageString())
+	fmt.Fprintf(tw, "Host Path:\t%s\n", printableString(volume.Status.HostPath))
+	fmt.Fprintf(tw, "Staging Path:\t%s\n", printableString(volume.Status.StagingPath))
+	fmt.Fprintf(tw, "Container Path:\t%s\n", printableString(volume.Status.ContainerPath))
+	fmt.Fprintf(tw, "Conditions:\n")
+	fmt.Fprintf(tw, "  TYPE\tSTATUS\tREASON\tMESSAGE\tLAST TRANSITION\n")
+	for _, condition := range volume.Status.Conditions {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+			condition.Type,
+			condition.Status,
+			printableString(condition.Reason),
+			printableString(condition.Message),
+			condition.LastTransitionTime.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func describeVolume(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return newUsageError("Invalid input arguments. Please use '%s' for examples to describe a volume", utils.Bold("--help"))
+	}
+
+	directClient := utils.GetDirectCSIClient()
+	volume, err := directClient.DirectCSIVolumes().Get(ctx, args[0], metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if yaml {
+		return printYAML(volume)
+	}
+	if json {
+		return printJSON(volume)
+	}
+
+	var drive *directcsi.DirectCSIDrive
+	if volume.Status.Drive != "" {
+		if drive, err = directClient.DirectCSIDrives().Get(ctx, volume.Status.Drive, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		}); err != nil {
+			drive = nil
+		}
+	}
+
+	var usage *volumeUsage
+	var usageErr error
+	if volume.Status.NodeName == "" {
+		usageErr = fmt.Errorf("volume is not scheduled on a node yet")
+	} else {
+		usage, usageErr = getVolumeUsage(ctx, volume)
+	}
+
+	return printVolumeDescription(os.Stdout, volume, drive, usage, usageErr)
+}
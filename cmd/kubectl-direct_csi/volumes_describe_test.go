@@ -0,0 +1,158 @@
+/*
+ * This file is part of MinIO Direct CSI
+ * Copyright (C) 2021, MinIO, Inc.
+ *
+ * This code is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License, version 3,
+ * as published by the Free Software Foundation.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License, version 3,
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseVolumeUsage(t1 *testing.T) {
+	summary := []byte(`{
+  "node": {"nodeName": "directcsi-1"},
+  "pods": [
+    {
+      "podRef": {"name": "minio-0", "namespace": "minio"},
+      "volume": [
+        {"name": "kube-api-access", "usedBytes": 12288},
+        {"name": "data", "capacityBytes": 1073741824, "usedBytes": 4096, "availableBytes": 1073737728, "pvcRef": {"name": "data-minio-0", "namespace": "minio"}}
+      ]
+    },
+    {
+      "podRef": {"name": "nginx", "namespace": "default"}
+    }
+  ]
+}`)
+
+	testCases := []struct {
+		name          string
+		namespace     string
+		claimName     string
+		expectedUsage *volumeUsage
+		expectErr     bool
+	}{
+		{
+			name:          "test1",
+			namespace:     "minio",
+			claimName:     "data-minio-0",
+			expectedUsage: &volumeUsage{CapacityBytes: 1073741824, UsedBytes: 4096, AvailableBytes: 1073737728},
+		},
+		{
+			name:      "test2",
+			namespace: "default",
+			claimName: "data-minio-0",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			usage, err := parseVolumeUsage(summary, tt.namespace, tt.claimName)
+			if tt.expectErr {
+				if err == nil {
+					t1.Fatalf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(usage, tt.expectedUsage) {
+				t1.Errorf("Test case name %s: expected usage %v but got %v", tt.name, tt.expectedUsage, usage)
+			}
+		})
+	}
+}
+
+func TestPrintVolumeDescription(t1 *testing.T) {
+	volume := &directcsi.DirectCSIVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pvc-1",
+			Labels: map[string]string{
+				directcsi.Group + "/pod.name":      "minio-0",
+				directcsi.Group + "/pod.namespace": "minio",
+			},
+		},
+		Status: directcsi.DirectCSIVolumeStatus{
+			Drive:         "drive-1",
+			NodeName:      "directcsi-1",
+			HostPath:      "/var/lib/direct-csi/mnt/drive-1/pvc-1",
+			StagingPath:   "/var/lib/kubelet/staging/pvc-1",
+			TotalCapacity: 1 << 30,
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(directcsi.DirectCSIVolumeConditionStaged),
+					Status: metav1.ConditionTrue,
+					Reason: string(directcsi.DirectCSIVolumeReasonInUse),
+				},
+			},
+		},
+	}
+	drive := &directcsi.DirectCSIDrive{
+		ObjectMeta: metav1.ObjectMeta{Name: "drive-1"},
+		Status:     directcsi.DirectCSIDriveStatus{Path: "/dev/xvdb"},
+	}
+
+	testCases := []struct {
+		name             string
+		usage            *volumeUsage
+		usageErr         error
+		expectedContents []string
+	}{
+		{
+			name:  "test1",
+			usage: &volumeUsage{CapacityBytes: 1 << 30, UsedBytes: 1 << 20, AvailableBytes: 1<<30 - 1<<20},
+			expectedContents: []string{
+				"drive-1 (xvdb)",
+				"minio/minio-0",
+				"1.0 MiB used, 1023 MiB available of 1.0 GiB",
+				"/var/lib/kubelet/staging/pvc-1",
+				"Staged",
+			},
+		},
+		{
+			name:     "test2",
+			usageErr: errors.New("volume is not published"),
+			expectedContents: []string{
+				"unavailable (volume is not published)",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			var buf bytes.Buffer
+			if err := printVolumeDescription(&buf, volume, drive, tt.usage, tt.usageErr); err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			for _, content := range tt.expectedContents {
+				if !strings.Contains(buf.String(), content) {
+					t1.Errorf("Test case name %s: expected %q in output\n%s", tt.name, content, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -18,11 +18,11 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"strings"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/client"
 	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
 
@@ -32,7 +32,6 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/spf13/cobra"
-	"k8s.io/klog"
 )
 
 var (
@@ -86,54 +85,41 @@ func listVolumes(ctx context.Context, args []string) error {
 
 	driveList, err := dclient.List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newAPIError(err)
 	}
 
 	if len(driveList.Items) == 0 {
-		klog.Errorf("No resource of %s found\n", bold("DirectCSIDrive"))
-		return fmt.Errorf("No resources found")
+		return errNoDrives
 	}
 
-	accessTierSet, aErr := getAccessTierSet(accessTiers)
-	if aErr != nil {
-		return aErr
+	volumeList, err := vclient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return newAPIError(err)
 	}
-	filterDrives := []directcsi.DirectCSIDrive{}
-	for _, d := range driveList.Items {
-		if d.MatchGlob(nodes, drives, status) {
-			if d.MatchAccessTier(accessTierSet) {
-				filterDrives = append(filterDrives, d)
-			}
-		}
+	vols, err := client.FilterVolumes(volumeList.Items, driveList.Items, client.VolumeFilter{
+		Drive: client.DriveFilter{
+			Nodes:       nodes,
+			Drives:      drives,
+			Statuses:    status,
+			AccessTiers: accessTiers,
+		},
+		Statuses:      volumeStatus,
+		PodNames:      podNames,
+		PodNamespaces: podNss,
+	})
+	if err != nil {
+		return err
 	}
 
-	vols := []directcsi.DirectCSIVolume{}
-
 	drivePaths := map[string]string{}
 	driveUUIDs := map[string]string{}
 	driveName := func(val string) string {
 		dr := strings.ReplaceAll(val, sys.DirectCSIDevRoot+"/", "")
 		return strings.ReplaceAll(dr, sys.HostDevRoot+"/", "")
 	}
-
-	for _, d := range filterDrives {
-		if d.Status.DriveStatus == directcsi.DriveStatusUnavailable {
-			continue
-		}
+	for _, d := range driveList.Items {
 		drivePaths[d.Name] = driveName(d.Status.Path)
 		driveUUIDs[d.Name] = d.Status.FilesystemUUID
-		for _, f := range d.GetFinalizers() {
-			if strings.HasPrefix(f, directcsi.DirectCSIDriveFinalizerPrefix) {
-				name := strings.ReplaceAll(f, directcsi.DirectCSIDriveFinalizerPrefix, "")
-				vol, err := vclient.Get(ctx, name, metav1.GetOptions{})
-				if err != nil {
-					return err
-				}
-				if vol.MatchStatus(volumeStatus) && vol.MatchPodName(podNames) && vol.MatchPodNamespace(podNss) {
-					vols = append(vols, *vol)
-				}
-			}
-		}
 	}
 
 	wrappedVolumeList := directcsi.DirectCSIVolumeList{
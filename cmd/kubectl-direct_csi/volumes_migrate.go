@@ -0,0 +1,98 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+	"github.com/minio/direct-csi/pkg/volume"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	toDrive = ""
+)
+
+var migrateVolumesCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "move the data of a volume to another drive on the same node",
+	Long: `
+The volume is moved by the node it is scheduled on. Its data and quota are copied
+to the target drive and the source drive is released once the copy succeeds.
+Only volumes not used by any pod can be moved.`,
+	Example: `
+ # Move a volume to another drive on its node
+ $ kubectl direct-csi volumes migrate pvc-0f2a3b1c-9d4e-4f5a-8b6c-7d8e9f0a1b2c --to-drive 3e5b6fd4-9c5b-4d3e-8c6a-1f2b3c4d5e6f
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return migrateVolume(c.Context(), args)
+	},
+}
+
+func init() {
+	migrateVolumesCmd.PersistentFlags().StringVarP(&toDrive, "to-drive", "", toDrive, "name of the drive to move the volume to")
+}
+
+func migrateVolume(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return newUsageError("Invalid input arguments. Please use '%s' for examples to migrate a volume", utils.Bold("--help"))
+	}
+	if toDrive == "" {
+		return newUsageError("'%s' should be specified", utils.Bold("--to-drive"))
+	}
+
+	directClient := utils.GetDirectCSIClient()
+	vol, err := directClient.DirectCSIVolumes().Get(ctx, args[0], metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	drive, err := directClient.DirectCSIDrives().Get(ctx, toDrive, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := volume.ValidateMigration(vol, drive); err != nil {
+		return err
+	}
+
+	annotations := vol.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[directcsi.DirectCSIVolumeAnnotationMigrateTo] = drive.Name
+	vol.SetAnnotations(annotations)
+
+	if dryRun {
+		return utils.LogYAML(vol)
+	}
+	if _, err := directClient.DirectCSIVolumes().Update(ctx, vol, metav1.UpdateOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("volume %s is being moved to drive %s; run 'kubectl direct-csi volumes describe %s' to follow it\n", vol.Name, drive.Name, vol.Name)
+	return nil
+}
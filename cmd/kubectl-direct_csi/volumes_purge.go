@@ -0,0 +1,224 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/client-go/util/retry"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var (
+	assumeYes = false
+)
+
+var purgeVolumesCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "delete the volumes whose drives are lost",
+	Long: `
+Volumes whose drive is deleted or unavailable, e.g. after a node or a drive failure,
+cannot be deleted as their node never removes their finalizers. Such volumes are
+purged by removing their finalizers and deleting them. Volumes used by a running
+pod are never purged.`,
+	Example: `
+ # List the volumes which would be purged
+ $ kubectl direct-csi volumes purge --dry-run
+
+ # Purge all the volumes whose drives are lost
+ $ kubectl direct-csi volumes purge
+
+ # Purge a volume without asking for confirmation
+ $ kubectl direct-csi volumes purge pvc-0f2a3b1c-9d4e-4f5a-8b6c-7d8e9f0a1b2c --yes
+ `,
+	RunE: func(c *cobra.Command, args []string) error {
+		return purgeVolumes(c.Context(), args)
+	},
+}
+
+func init() {
+	purgeVolumesCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", assumeYes, "purge the volumes without asking for confirmation")
+}
+
+// purgeReason returns why the volume can be purged, a volume is purgeable if its drive
+// is deleted or unavailable
+func purgeReason(vol directcsi.DirectCSIVolume, drives map[string]directcsi.DirectCSIDrive) (string, bool) {
+	drive, found := drives[vol.Status.Drive]
+	switch {
+	case !found:
+		return fmt.Sprintf("drive %s not found", vol.Status.Drive), true
+	case drive.Status.DriveStatus == directcsi.DriveStatusUnavailable:
+		return fmt.Sprintf("drive %s is unavailable", vol.Status.Drive), true
+	}
+	return "", false
+}
+
+// isPodLive returns true if the pod using the volume exists and did not terminate
+func isPodLive(ctx context.Context, vol directcsi.DirectCSIVolume) (bool, error) {
+	name := vol.GetLabels()[directcsi.Group+"/pod.name"]
+	namespace := vol.GetLabels()[directcsi.Group+"/pod.namespace"]
+	if name == "" || namespace == "" {
+		return false, nil
+	}
+	pod, err := utils.GetKubeClient().CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed, nil
+}
+
+// confirm asks the question on stdout and returns true if it is answered with yes
+func confirm(r io.Reader, question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func purgeVolumes(ctx context.Context, args []string) error {
+	directClient := utils.GetDirectCSIClient()
+
+	driveList, err := directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return newAPIError(err)
+	}
+	drives := map[string]directcsi.DirectCSIDrive{}
+	for _, d := range driveList.Items {
+		drives[d.Name] = d
+	}
+
+	volumeList, err := directClient.DirectCSIVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return newAPIError(err)
+	}
+	selected := map[string]bool{}
+	for _, name := range args {
+		selected[name] = true
+	}
+
+	purgeable := []directcsi.DirectCSIVolume{}
+	for _, vol := range volumeList.Items {
+		if len(selected) > 0 && !selected[vol.Name] {
+			continue
+		}
+		reason, ok := purgeReason(vol, drives)
+		if !ok {
+			if len(selected) > 0 {
+				klog.Errorf("%s is not purgeable, its drive %s is %s", bold(vol.Name), vol.Status.Drive, drives[vol.Status.Drive].Status.DriveStatus)
+			}
+			continue
+		}
+		live, err := isPodLive(ctx, vol)
+		if err != nil {
+			return newAPIError(err)
+		}
+		if live {
+			klog.Errorf("%s is not purgeable, it is used by the running pod %s/%s", bold(vol.Name),
+				vol.GetLabels()[directcsi.Group+"/pod.namespace"], vol.GetLabels()[directcsi.Group+"/pod.name"])
+			continue
+		}
+		fmt.Printf("%s: %s\n", vol.Name, reason)
+		purgeable = append(purgeable, vol)
+	}
+
+	if len(purgeable) == 0 {
+		fmt.Println("no volumes to purge")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+	if !assumeYes && !confirm(os.Stdin, fmt.Sprintf("Purge %d volume(s)? The data on them cannot be recovered by direct-csi", len(purgeable))) {
+		return nil
+	}
+
+	failed := 0
+	for _, vol := range purgeable {
+		if err := purgeVolume(ctx, vol); err != nil {
+			klog.ErrorS(err, "failed to purge volume", "volume", vol.Name)
+			failed++
+			continue
+		}
+		fmt.Printf("purged volume %s\n", vol.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to purge %d of %d volume(s)", failed, len(purgeable))
+	}
+	return nil
+}
+
+// purgeVolume removes the finalizer of the volume on its drive, if the drive still exists, and then
+// removes the finalizers of the volume and deletes it. The drive is updated first so that a failure
+// leaves the volume in place to be purged again.
+func purgeVolume(ctx context.Context, vol directcsi.DirectCSIVolume) error {
+	directClient := utils.GetDirectCSIClient()
+
+	finalizer := directcsi.DirectCSIDriveFinalizerPrefix + vol.Name
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		drive, err := directClient.DirectCSIDrives().Get(ctx, vol.Status.Drive, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		finalizers := utils.RemoveFinalizer(&drive.ObjectMeta, finalizer)
+		if len(finalizers) == len(drive.GetFinalizers()) {
+			return nil
+		}
+		drive.SetFinalizers(finalizers)
+		_, err = directClient.DirectCSIDrives().Update(ctx, drive, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		return err
+	}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	for _, finalizer := range vol.GetFinalizers() {
+		vol.SetFinalizers(utils.RemoveFinalizer(&vol.ObjectMeta, finalizer))
+	}
+	if _, err := directClient.DirectCSIVolumes().Update(ctx, &vol, metav1.UpdateOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := directClient.DirectCSIVolumes().Delete(ctx, vol.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
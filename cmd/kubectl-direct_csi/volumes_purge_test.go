@@ -0,0 +1,134 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPurgeReason(t1 *testing.T) {
+	drives := map[string]directcsi.DirectCSIDrive{
+		"ready": {
+			ObjectMeta: metav1.ObjectMeta{Name: "ready"},
+			Status:     directcsi.DirectCSIDriveStatus{DriveStatus: directcsi.DriveStatusInUse},
+		},
+		"unavailable": {
+			ObjectMeta: metav1.ObjectMeta{Name: "unavailable"},
+			Status:     directcsi.DirectCSIDriveStatus{DriveStatus: directcsi.DriveStatusUnavailable},
+		},
+	}
+	testCases := []struct {
+		name              string
+		drive             string
+		expectedPurgeable bool
+	}{
+		{"drive_in_use", "ready", false},
+		{"drive_unavailable", "unavailable", true},
+		{"drive_deleted", "deleted", true},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t *testing.T) {
+			vol := directcsi.DirectCSIVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "volume"},
+				Status:     directcsi.DirectCSIVolumeStatus{Drive: tt.drive},
+			}
+			reason, purgeable := purgeReason(vol, drives)
+			if purgeable != tt.expectedPurgeable {
+				t.Fatalf("expected purgeable %v, got %v", tt.expectedPurgeable, purgeable)
+			}
+			if purgeable && !strings.Contains(reason, tt.drive) {
+				t.Errorf("expected the reason to name the drive %s, got %q", tt.drive, reason)
+			}
+		})
+	}
+}
+
+func TestConfirm(t1 *testing.T) {
+	testCases := []struct {
+		answer   string
+		expected bool
+	}{
+		{"y\n", true},
+		{"Yes\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"yes", true},
+	}
+	for _, tt := range testCases {
+		if confirm(strings.NewReader(tt.answer), "purge?") != tt.expected {
+			t1.Errorf("expected %v for the answer %q", tt.expected, tt.answer)
+		}
+	}
+}
+
+func TestPurgeVolumesOnSameDrive(t *testing.T) {
+	createTestVolume := func(name string) *directcsi.DirectCSIVolume {
+		return &directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Finalizers: []string{string(directcsi.DirectCSIVolumeFinalizerPurgeProtection)},
+			},
+			Status: directcsi.DirectCSIVolumeStatus{Drive: "d1"},
+		}
+	}
+	drive := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "d1",
+			Finalizers: []string{
+				string(directcsi.DirectCSIDriveFinalizerDataProtection),
+				directcsi.DirectCSIDriveFinalizerPrefix + "v1",
+				directcsi.DirectCSIDriveFinalizerPrefix + "v2",
+			},
+		},
+		Status: directcsi.DirectCSIDriveStatus{DriveStatus: directcsi.DriveStatusUnavailable},
+	}
+	v1, v2 := createTestVolume("v1"), createTestVolume("v2")
+
+	ctx := context.TODO()
+	testClient := fakedirect.NewSimpleClientset(drive, v1, v2).DirectV1beta2()
+	utils.SetFakeDirectCSIClient(testClient)
+
+	// the volumes are purged from the same listing, the drive is updated by the first purge
+	for _, vol := range []*directcsi.DirectCSIVolume{v1, v2} {
+		if err := purgeVolume(ctx, *vol); err != nil {
+			t.Fatalf("Error while purging volume %s: %v", vol.Name, err)
+		}
+		if _, err := testClient.DirectCSIVolumes().Get(ctx, vol.Name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("Expected volume %s to be deleted, got %v", vol.Name, err)
+		}
+	}
+
+	driveObj, err := testClient.DirectCSIDrives().Get(ctx, "d1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error while getting the drive: %v", err)
+	}
+	if finalizers := driveObj.GetFinalizers(); len(finalizers) != 1 || finalizers[0] != directcsi.DirectCSIDriveFinalizerDataProtection {
+		t.Errorf("Expected only the data protection finalizer on the drive, got %v", finalizers)
+	}
+}
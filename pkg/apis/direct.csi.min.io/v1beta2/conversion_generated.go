@@ -194,7 +194,15 @@ func Convert_v1beta1_DirectCSIDriveList_To_v1beta2_DirectCSIDriveList(in *v1beta
 }
 
 func autoConvert_v1beta2_DirectCSIDriveSpec_To_v1beta1_DirectCSIDriveSpec(in *DirectCSIDriveSpec, out *v1beta1.DirectCSIDriveSpec, s conversion.Scope) error {
-	out.RequestedFormat = (*v1beta1.RequestedFormat)(unsafe.Pointer(in.RequestedFormat))
+	if in.RequestedFormat != nil {
+		in, out := &in.RequestedFormat, &out.RequestedFormat
+		*out = new(v1beta1.RequestedFormat)
+		if err := Convert_v1beta2_RequestedFormat_To_v1beta1_RequestedFormat(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.RequestedFormat = nil
+	}
 	out.DirectCSIOwned = in.DirectCSIOwned
 	out.DriveTaint = *(*map[string]string)(unsafe.Pointer(&in.DriveTaint))
 	return nil
@@ -206,7 +214,15 @@ func Convert_v1beta2_DirectCSIDriveSpec_To_v1beta1_DirectCSIDriveSpec(in *Direct
 }
 
 func autoConvert_v1beta1_DirectCSIDriveSpec_To_v1beta2_DirectCSIDriveSpec(in *v1beta1.DirectCSIDriveSpec, out *DirectCSIDriveSpec, s conversion.Scope) error {
-	out.RequestedFormat = (*RequestedFormat)(unsafe.Pointer(in.RequestedFormat))
+	if in.RequestedFormat != nil {
+		in, out := &in.RequestedFormat, &out.RequestedFormat
+		*out = new(RequestedFormat)
+		if err := Convert_v1beta1_RequestedFormat_To_v1beta2_RequestedFormat(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.RequestedFormat = nil
+	}
 	out.DirectCSIOwned = in.DirectCSIOwned
 	out.DriveTaint = *(*map[string]string)(unsafe.Pointer(&in.DriveTaint))
 	return nil
@@ -239,6 +255,26 @@ func autoConvert_v1beta2_DirectCSIDriveStatus_To_v1beta1_DirectCSIDriveStatus(in
 	// INFO: in.PartitionUUID opted out of conversion generation
 	// INFO: in.MajorNumber opted out of conversion generation
 	// INFO: in.MinorNumber opted out of conversion generation
+	// INFO: in.FirmwareRevision opted out of conversion generation
+	// INFO: in.NamespaceID opted out of conversion generation
+	// INFO: in.ControllerSerial opted out of conversion generation
+	// INFO: in.SharedNamespace opted out of conversion generation
+	// INFO: in.FilesystemLabel opted out of conversion generation
+	// INFO: in.RAIDMembers opted out of conversion generation
+	// INFO: in.Rotational opted out of conversion generation
+	// INFO: in.Removable opted out of conversion generation
+	// INFO: in.IOScheduler opted out of conversion generation
+	// INFO: in.NrRequests opted out of conversion generation
+	// INFO: in.ReadAheadKB opted out of conversion generation
+	// INFO: in.FilesystemBlockSize opted out of conversion generation
+	// INFO: in.ByIDPath opted out of conversion generation
+	// INFO: in.ByPath opted out of conversion generation
+	// INFO: in.Enclosure opted out of conversion generation
+	// INFO: in.Slot opted out of conversion generation
+	// INFO: in.ReservedCapacity opted out of conversion generation
+	// INFO: in.ReadOnly opted out of conversion generation
+	// INFO: in.SMART opted out of conversion generation
+	// INFO: in.DeviceCapacity opted out of conversion generation
 	out.Conditions = *(*[]v1.Condition)(unsafe.Pointer(&in.Conditions))
 	return nil
 }
@@ -303,7 +339,17 @@ func Convert_v1beta1_DirectCSIVolume_To_v1beta2_DirectCSIVolume(in *v1beta1.Dire
 
 func autoConvert_v1beta2_DirectCSIVolumeList_To_v1beta1_DirectCSIVolumeList(in *DirectCSIVolumeList, out *v1beta1.DirectCSIVolumeList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1beta1.DirectCSIVolume)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1beta1.DirectCSIVolume, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta2_DirectCSIVolume_To_v1beta1_DirectCSIVolume(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -314,7 +360,17 @@ func Convert_v1beta2_DirectCSIVolumeList_To_v1beta1_DirectCSIVolumeList(in *Dire
 
 func autoConvert_v1beta1_DirectCSIVolumeList_To_v1beta2_DirectCSIVolumeList(in *v1beta1.DirectCSIVolumeList, out *DirectCSIVolumeList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]DirectCSIVolume)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DirectCSIVolume, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_DirectCSIVolume_To_v1beta2_DirectCSIVolume(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -333,6 +389,8 @@ func autoConvert_v1beta2_DirectCSIVolumeStatus_To_v1beta1_DirectCSIVolumeStatus(
 	out.AvailableCapacity = in.AvailableCapacity
 	out.UsedCapacity = in.UsedCapacity
 	out.Conditions = *(*[]v1.Condition)(unsafe.Pointer(&in.Conditions))
+	// INFO: in.QuotaUnenforced opted out of conversion generation
+	// INFO: in.Topology opted out of conversion generation
 	return nil
 }
 
@@ -365,6 +423,10 @@ func autoConvert_v1beta2_RequestedFormat_To_v1beta1_RequestedFormat(in *Requeste
 	out.Filesystem = in.Filesystem
 	out.Mountpoint = in.Mountpoint
 	out.MountOptions = *(*[]string)(unsafe.Pointer(&in.MountOptions))
+	// INFO: in.Label opted out of conversion generation
+	// INFO: in.BlockSize opted out of conversion generation
+	// INFO: in.MkfsOptions opted out of conversion generation
+	// INFO: in.ReservedPercent opted out of conversion generation
 	return nil
 }
 
@@ -86,6 +86,22 @@ func (in *DirectCSIDriveList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectCSIDriveSMART) DeepCopyInto(out *DirectCSIDriveSMART) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectCSIDriveSMART.
+func (in *DirectCSIDriveSMART) DeepCopy() *DirectCSIDriveSMART {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectCSIDriveSMART)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DirectCSIDriveSpec) DeepCopyInto(out *DirectCSIDriveSpec) {
 	*out = *in
@@ -129,6 +145,16 @@ func (in *DirectCSIDriveStatus) DeepCopyInto(out *DirectCSIDriveStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.RAIDMembers != nil {
+		in, out := &in.RAIDMembers, &out.RAIDMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SMART != nil {
+		in, out := &in.SMART, &out.SMART
+		*out = new(DirectCSIDriveSMART)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -149,6 +175,82 @@ func (in *DirectCSIDriveStatus) DeepCopy() *DirectCSIDriveStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectCSISnapshot) DeepCopyInto(out *DirectCSISnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectCSISnapshot.
+func (in *DirectCSISnapshot) DeepCopy() *DirectCSISnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectCSISnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DirectCSISnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectCSISnapshotList) DeepCopyInto(out *DirectCSISnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DirectCSISnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectCSISnapshotList.
+func (in *DirectCSISnapshotList) DeepCopy() *DirectCSISnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectCSISnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DirectCSISnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectCSISnapshotStatus) DeepCopyInto(out *DirectCSISnapshotStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectCSISnapshotStatus.
+func (in *DirectCSISnapshotStatus) DeepCopy() *DirectCSISnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectCSISnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DirectCSIVolume) DeepCopyInto(out *DirectCSIVolume) {
 	*out = *in
@@ -219,6 +321,13 @@ func (in *DirectCSIVolumeStatus) DeepCopyInto(out *DirectCSIVolumeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -240,6 +349,11 @@ func (in *RequestedFormat) DeepCopyInto(out *RequestedFormat) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MkfsOptions != nil {
+		in, out := &in.MkfsOptions, &out.MkfsOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
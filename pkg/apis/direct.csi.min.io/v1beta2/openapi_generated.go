@@ -29,14 +29,18 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDrive":        schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDrive(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveList":    schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveList(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveSpec":    schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveSpec(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveStatus":  schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveStatus(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIVolume":       schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolume(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIVolumeList":   schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolumeList(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIVolumeStatus": schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolumeStatus(ref),
-		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.RequestedFormat":       schema_pkg_apis_directcsiminio_v1beta2_RequestedFormat(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDrive":          schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDrive(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveList":      schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveList(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveSMART":     schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveSMART(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveSpec":      schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveSpec(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveStatus":    schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveStatus(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshot":       schema_pkg_apis_directcsiminio_v1beta2_DirectCSISnapshot(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshotList":   schema_pkg_apis_directcsiminio_v1beta2_DirectCSISnapshotList(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshotStatus": schema_pkg_apis_directcsiminio_v1beta2_DirectCSISnapshotStatus(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIVolume":         schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolume(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIVolumeList":     schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolumeList(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIVolumeStatus":   schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolumeStatus(ref),
+		"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.RequestedFormat":         schema_pkg_apis_directcsiminio_v1beta2_RequestedFormat(ref),
 	}
 }
 
@@ -136,6 +140,49 @@ func schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveList(ref common.Refere
 	}
 }
 
+func schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveSMART(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DirectCSIDriveSMART is the health of the drive read by smartctl, it is shared by the partitions of a disk",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"passed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Passed is the overall health self-assessment of the drive",
+							Default:     false,
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"temperature": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Temperature is the current temperature in Celsius",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"reallocatedSectors": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReallocatedSectors is the count of reallocated sectors of ATA drives or the grown defects of SCSI drives",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"percentageUsed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PercentageUsed is the estimated percentage of the endurance used of NVMe and SCSI SSDs, -1 if not reported",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"passed"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -335,6 +382,138 @@ func schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveStatus(ref common.Refe
 							Format: "int64",
 						},
 					},
+					"firmwareRevision": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"namespaceID": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"controllerSerial": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"sharedNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"filesystemLabel": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"raidMembers": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"rotational": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"removable": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"ioScheduler": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"nrRequests": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"readAheadKB": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"filesystemBlockSize": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"byIDPath": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"byPath": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"enclosure": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"slot": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reservedCapacity": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"readOnly": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"smart": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveSMART"),
+						},
+					},
+					"deviceCapacity": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
 					"conditions": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -363,7 +542,157 @@ func schema_pkg_apis_directcsiminio_v1beta2_DirectCSIDriveStatus(ref common.Refe
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition"},
+			"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSIDriveSMART", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition"},
+	}
+}
+
+func schema_pkg_apis_directcsiminio_v1beta2_DirectCSISnapshot(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DirectCSISnapshot is a point-in-time reflink copy of a volume on its drive",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshotStatus"),
+						},
+					},
+				},
+				Required: []string{"metadata"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshotStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_directcsiminio_v1beta2_DirectCSISnapshotList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "metdata is the standard list metadata.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshot"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2.DirectCSISnapshot", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_directcsiminio_v1beta2_DirectCSISnapshotStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"sourceVolume": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"drive": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"nodeName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"snapshotPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SnapshotPath is the directory holding the copy of the volume on the drive",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sizeBytes": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+					"readyToUse": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadyToUse is set by the node once the copy is taken",
+							Default:     false,
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is the reason why the copy could not be taken",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -537,6 +866,29 @@ func schema_pkg_apis_directcsiminio_v1beta2_DirectCSIVolumeStatus(ref common.Ref
 							},
 						},
 					},
+					"quotaUnenforced": {
+						SchemaProps: spec.SchemaProps{
+							Description: "QuotaUnenforced is set when the volume was staged without project quota support, hence its usage is not limited to the requested capacity",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"topology": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Topology holds the topology segments satisfied by the drive at provisioning",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -594,6 +946,43 @@ func schema_pkg_apis_directcsiminio_v1beta2_RequestedFormat(ref common.Reference
 							},
 						},
 					},
+					"label": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"blockSize": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"mkfsOptions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"reservedPercent": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
 				},
 			},
 		},
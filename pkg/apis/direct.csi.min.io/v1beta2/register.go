@@ -57,6 +57,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DirectCSIDriveList{},
 		&DirectCSIVolume{},
 		&DirectCSIVolumeList{},
+		&DirectCSISnapshot{},
+		&DirectCSISnapshotList{},
 	)
 	v1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -26,6 +26,25 @@ const (
 
 	DirectCSIDriveFinalizerDataProtection = Group + "/data-protection"
 	DirectCSIDriveFinalizerPrefix         = Group + ".volume/"
+	// DirectCSIDriveFinalizerSnapshotPrefix followed by the snapshot name holds the drive while the copy is on it
+	DirectCSIDriveFinalizerSnapshotPrefix = Group + ".snapshot/"
+
+	DirectCSISnapshotFinalizerDataProtection = Group + "/snapshot-protection"
+
+	// DirectCSIDriveAnnotationWipe requests the node to erase the filesystem while releasing the drive
+	DirectCSIDriveAnnotationWipe = Group + "/wipe-on-release"
+	// DirectCSIDriveAnnotationAdopt requests the node to mount the existing xfs filesystem without formatting it
+	DirectCSIDriveAnnotationAdopt = Group + "/adopt"
+	// DirectCSIDriveAnnotationRepair requests the node to check the existing xfs filesystem before mounting it,
+	// the value DirectCSIDriveRepairCheck only detects the errors and DirectCSIDriveRepairFix also repairs them
+	DirectCSIDriveAnnotationRepair = Group + "/repair"
+	// DirectCSIVolumeAnnotationMigrateTo requests the node to move the volume to the named drive
+	DirectCSIVolumeAnnotationMigrateTo = Group + "/migrate-to"
+)
+
+const (
+	DirectCSIDriveRepairCheck = "check"
+	DirectCSIDriveRepairFix   = "repair"
 )
 
 // +genclient
@@ -108,6 +127,67 @@ type DirectCSIDriveStatus struct {
 	// +optional
 	// +k8s:conversion-gen=false
 	MinorNumber uint32 `json:"minorNumber,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	FirmwareRevision string `json:"firmwareRevision,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	NamespaceID int `json:"namespaceID,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ControllerSerial string `json:"controllerSerial,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	SharedNamespace bool `json:"sharedNamespace,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	FilesystemLabel string `json:"filesystemLabel,omitempty"`
+	// +listType=atomic
+	// +optional
+	// +k8s:conversion-gen=false
+	RAIDMembers []string `json:"raidMembers,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	Rotational bool `json:"rotational,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	Removable bool `json:"removable,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	IOScheduler string `json:"ioScheduler,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	NrRequests int64 `json:"nrRequests,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ReadAheadKB int64 `json:"readAheadKB,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	FilesystemBlockSize int64 `json:"filesystemBlockSize,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ByIDPath string `json:"byIDPath,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ByPath string `json:"byPath,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	Enclosure string `json:"enclosure,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	Slot string `json:"slot,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ReservedCapacity int64 `json:"reservedCapacity,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	SMART *DirectCSIDriveSMART `json:"smart,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	DeviceCapacity int64 `json:"deviceCapacity,omitempty"`
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	// +listType=map
@@ -127,9 +207,24 @@ const (
 type DirectCSIDriveReason string
 
 const (
-	DirectCSIDriveReasonNotAdded    DirectCSIDriveReason = "NotAdded"
-	DirectCSIDriveReasonAdded       DirectCSIDriveReason = "Added"
-	DirectCSIDriveReasonInitialized DirectCSIDriveReason = "Initialized"
+	DirectCSIDriveReasonNotAdded      DirectCSIDriveReason = "NotAdded"
+	DirectCSIDriveReasonAdded         DirectCSIDriveReason = "Added"
+	DirectCSIDriveReasonInitialized   DirectCSIDriveReason = "Initialized"
+	DirectCSIDriveReasonHasPartitions DirectCSIDriveReason = "HasPartitions"
+	DirectCSIDriveReasonSystemDisk    DirectCSIDriveReason = "SystemDisk"
+	DirectCSIDriveReasonCryptMember   DirectCSIDriveReason = "CryptMember"
+	DirectCSIDriveReasonRAIDMember    DirectCSIDriveReason = "RAIDMember"
+	DirectCSIDriveReasonRemovable     DirectCSIDriveReason = "Removable"
+	// the reasons of the failed steps while adding a drive
+	DirectCSIDriveReasonFormatFailed DirectCSIDriveReason = "FormatFailed"
+	DirectCSIDriveReasonMountFailed  DirectCSIDriveReason = "MountFailed"
+	DirectCSIDriveReasonDeviceBusy   DirectCSIDriveReason = "DeviceBusy"
+	DirectCSIDriveReasonReadOnly     DirectCSIDriveReason = "ReadOnly"
+	// the outcomes of the filesystem check before mounting an existing filesystem
+	DirectCSIDriveReasonFilesystemChecked   DirectCSIDriveReason = "FilesystemChecked"
+	DirectCSIDriveReasonFilesystemCorrupted DirectCSIDriveReason = "FilesystemCorrupted"
+	DirectCSIDriveReasonRepaired            DirectCSIDriveReason = "Repaired"
+	DirectCSIDriveReasonRepairFailed        DirectCSIDriveReason = "RepairFailed"
 )
 
 type DirectCSIDriveMessage string
@@ -141,6 +236,21 @@ const (
 	DirectCSIDriveMessageNotFormatted DirectCSIDriveMessage = "NotFormatted"
 )
 
+// DirectCSIDriveSMART is the health of the drive read by smartctl, it is shared by the partitions of a disk
+type DirectCSIDriveSMART struct {
+	// Passed is the overall health self-assessment of the drive
+	Passed bool `json:"passed"`
+	// Temperature is the current temperature in Celsius
+	// +optional
+	Temperature int64 `json:"temperature,omitempty"`
+	// ReallocatedSectors is the count of reallocated sectors of ATA drives or the grown defects of SCSI drives
+	// +optional
+	ReallocatedSectors int64 `json:"reallocatedSectors,omitempty"`
+	// PercentageUsed is the estimated percentage of the endurance used of NVMe and SCSI SSDs, -1 if not reported
+	// +optional
+	PercentageUsed int64 `json:"percentageUsed,omitempty"`
+}
+
 type RequestedFormat struct {
 	// +optional
 	Force bool `json:"force,omitempty"`
@@ -153,6 +263,19 @@ type RequestedFormat struct {
 	// +listType=atomic
 	// +optional
 	MountOptions []string `json:"mountOptions,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	Label string `json:"label,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	BlockSize int64 `json:"blockSize,omitempty"`
+	// +listType=atomic
+	// +optional
+	// +k8s:conversion-gen=false
+	MkfsOptions []string `json:"mkfsOptions,omitempty"`
+	// +optional
+	// +k8s:conversion-gen=false
+	ReservedPercent int64 `json:"reservedPercent,omitempty"`
 }
 
 type DriveStatus string
@@ -240,4 +363,60 @@ type DirectCSIVolumeStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// QuotaUnenforced is set when the volume was staged without project quota
+	// support, hence its usage is not limited to the requested capacity
+	// +optional
+	// +k8s:conversion-gen=false
+	QuotaUnenforced bool `json:"quotaUnenforced,omitempty"`
+
+	// Topology holds the topology segments satisfied by the drive at provisioning
+	// +optional
+	// +k8s:conversion-gen=false
+	Topology map[string]string `json:"topology,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DirectCSISnapshot is a point-in-time reflink copy of a volume on its drive
+type DirectCSISnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Status DirectCSISnapshotStatus `json:"status,omitempty"`
+}
+
+type DirectCSISnapshotStatus struct {
+	// +optional
+	SourceVolume string `json:"sourceVolume,omitempty"`
+	// +optional
+	Drive string `json:"drive,omitempty"`
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// SnapshotPath is the directory holding the copy of the volume on the drive
+	// +optional
+	SnapshotPath string `json:"snapshotPath,omitempty"`
+	// +optional
+	SizeBytes int64 `json:"sizeBytes"`
+	// ReadyToUse is set by the node once the copy is taken
+	// +optional
+	ReadyToUse bool `json:"readyToUse"`
+	// Message is the reason why the copy could not be taken
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type DirectCSISnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	// metdata is the standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata"`
+	Items           []DirectCSISnapshot `json:"items"`
 }
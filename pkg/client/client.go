@@ -0,0 +1,35 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package client provides the listing and filtering of the drives and volumes with
+// the same semantics as the kubectl plugin, for the controllers built on direct-csi.
+package client
+
+import (
+	directv1beta2 "github.com/minio/direct-csi/pkg/clientset/typed/direct.csi.min.io/v1beta2"
+)
+
+// Client lists the drives and volumes
+type Client struct {
+	directClient directv1beta2.DirectV1beta2Interface
+}
+
+// New returns a client using the given direct-csi clientset
+func New(directClient directv1beta2.DirectV1beta2Interface) *Client {
+	return &Client{
+		directClient: directClient,
+	}
+}
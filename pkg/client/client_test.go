@@ -0,0 +1,212 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newTestDrive(name, node, path string, driveStatus directcsi.DriveStatus, accessTier directcsi.AccessTier) *directcsi.DirectCSIDrive {
+	return &directcsi.DirectCSIDrive{
+		TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:    node,
+			Path:        path,
+			DriveStatus: driveStatus,
+			AccessTier:  accessTier,
+		},
+	}
+}
+
+func newTestVolume(name, drive, podName string, published bool) *directcsi.DirectCSIVolume {
+	return &directcsi.DirectCSIVolume{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				directcsi.Group + "/pod.name":      podName,
+				directcsi.Group + "/pod.namespace": "default",
+			},
+		},
+		Status: directcsi.DirectCSIVolumeStatus{
+			Drive: drive,
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(directcsi.DirectCSIVolumeConditionPublished),
+					Status: utils.BoolToCondition(published),
+				},
+			},
+		},
+	}
+}
+
+func testObjects() []runtime.Object {
+	return []runtime.Object{
+		newTestDrive("drive-1", "node-1", "/dev/nvme0n1", directcsi.DriveStatusInUse, directcsi.AccessTierHot),
+		newTestDrive("drive-2", "node-1", "/dev/sdb", directcsi.DriveStatusReady, directcsi.AccessTierCold),
+		newTestDrive("drive-3", "node-2", "/dev/nvme1n1", directcsi.DriveStatusAvailable, directcsi.AccessTierUnknown),
+		newTestDrive("drive-4", "node-2", "/dev/sda", directcsi.DriveStatusUnavailable, directcsi.AccessTierUnknown),
+		newTestVolume("volume-1", "drive-1", "minio-0", true),
+		newTestVolume("volume-2", "drive-1", "minio-1", false),
+		newTestVolume("volume-3", "drive-2", "postgres-0", true),
+	}
+}
+
+func TestListDrives(t1 *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        DriveFilter
+		expectedNames []string
+		expectedErr   bool
+	}{
+		{
+			name:          "all",
+			filter:        DriveFilter{},
+			expectedNames: []string{"drive-1", "drive-2", "drive-3"},
+		},
+		{
+			name:          "unavailable",
+			filter:        DriveFilter{IncludeUnavailable: true},
+			expectedNames: []string{"drive-1", "drive-2", "drive-3", "drive-4"},
+		},
+		{
+			name:          "nodes",
+			filter:        DriveFilter{Nodes: []string{"node-2"}, IncludeUnavailable: true},
+			expectedNames: []string{"drive-3", "drive-4"},
+		},
+		{
+			name:          "drives",
+			filter:        DriveFilter{Drives: []string{"/dev/nvme*"}},
+			expectedNames: []string{"drive-1", "drive-3"},
+		},
+		{
+			name:          "statuses",
+			filter:        DriveFilter{Statuses: []string{"ready", "inuse"}},
+			expectedNames: []string{"drive-1", "drive-2"},
+		},
+		{
+			name:          "accesstiers",
+			filter:        DriveFilter{AccessTiers: []string{"cold"}},
+			expectedNames: []string{"drive-2"},
+		},
+		{
+			name:          "anyaccesstier",
+			filter:        DriveFilter{AccessTiers: []string{"*"}},
+			expectedNames: []string{"drive-1", "drive-2"},
+		},
+		{
+			name:        "invalidaccesstier",
+			filter:      DriveFilter{AccessTiers: []string{"lukewarm"}},
+			expectedErr: true,
+		},
+	}
+
+	c := New(fakedirect.NewSimpleClientset(testObjects()...).DirectV1beta2())
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			drives, err := c.ListDrives(context.TODO(), tt.filter)
+			if tt.expectedErr {
+				if err == nil {
+					t1.Errorf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error: %v", tt.name, err)
+			}
+			names := []string{}
+			for _, d := range drives {
+				names = append(names, d.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.expectedNames) {
+				t1.Errorf("Test case name %s: expected drives %v, got %v", tt.name, tt.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestListVolumes(t1 *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        VolumeFilter
+		expectedNames []string
+	}{
+		{
+			name:          "all",
+			filter:        VolumeFilter{},
+			expectedNames: []string{"volume-1", "volume-2", "volume-3"},
+		},
+		{
+			name:          "drivefilter",
+			filter:        VolumeFilter{Drive: DriveFilter{AccessTiers: []string{"hot"}}},
+			expectedNames: []string{"volume-1", "volume-2"},
+		},
+		{
+			name:          "published",
+			filter:        VolumeFilter{Statuses: []string{"published"}},
+			expectedNames: []string{"volume-1", "volume-3"},
+		},
+		{
+			name:          "podnames",
+			filter:        VolumeFilter{PodNames: []string{"minio-*"}, PodNamespaces: []string{"default"}},
+			expectedNames: []string{"volume-1", "volume-2"},
+		},
+	}
+
+	c := New(fakedirect.NewSimpleClientset(testObjects()...).DirectV1beta2())
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			volumes, err := c.ListVolumes(context.TODO(), tt.filter)
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error: %v", tt.name, err)
+			}
+			names := []string{}
+			for _, v := range volumes {
+				names = append(names, v.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.expectedNames) {
+				t1.Errorf("Test case name %s: expected volumes %v, got %v", tt.name, tt.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestCountVolumes(t1 *testing.T) {
+	volumes := []directcsi.DirectCSIVolume{
+		*newTestVolume("volume-1", "drive-1", "minio-0", true),
+		*newTestVolume("volume-2", "drive-1", "minio-1", true),
+		*newTestVolume("volume-3", "drive-2", "minio-2", true),
+		*newTestVolume("volume-4", "", "minio-3", false),
+	}
+	expected := map[string]int{"drive-1": 2, "drive-2": 1}
+	if counts := CountVolumes(volumes); !reflect.DeepEqual(counts, expected) {
+		t1.Errorf("expected volume counts %v, got %v", expected, counts)
+	}
+}
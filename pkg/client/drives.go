@@ -0,0 +1,88 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DriveFilter selects the drives like the flags of `drives ls`. Empty lists match all the drives.
+type DriveFilter struct {
+	// Nodes are the glob patterns of the node names
+	Nodes []string
+	// Drives are the glob patterns of the drive paths, e.g. '/dev/nvme*'
+	Drives []string
+	// Statuses are the glob patterns of the drive statuses, e.g. 'ready'
+	Statuses []string
+	// AccessTiers are the access-tiers among hot|warm|cold, '*' matches any set access-tier
+	AccessTiers []string
+	// IncludeUnavailable selects the unavailable drives too
+	IncludeUnavailable bool
+}
+
+// ParseAccessTiers validates the access-tiers, '*' stands for all of them
+func ParseAccessTiers(accessTiers []string) ([]directcsi.AccessTier, error) {
+	var atSet []directcsi.AccessTier
+	for i := range accessTiers {
+		if accessTiers[i] == "*" {
+			return []directcsi.AccessTier{
+				directcsi.AccessTierHot,
+				directcsi.AccessTierWarm,
+				directcsi.AccessTierCold,
+			}, nil
+		}
+		at, err := utils.ValidateAccessTier(strings.TrimSpace(accessTiers[i]))
+		if err != nil {
+			return atSet, err
+		}
+		atSet = append(atSet, at)
+	}
+	return atSet, nil
+}
+
+// FilterDrives returns the drives selected by the filter
+func FilterDrives(drives []directcsi.DirectCSIDrive, filter DriveFilter) ([]directcsi.DirectCSIDrive, error) {
+	accessTierSet, err := ParseAccessTiers(filter.AccessTiers)
+	if err != nil {
+		return nil, err
+	}
+	filteredDrives := []directcsi.DirectCSIDrive{}
+	for _, d := range drives {
+		if !filter.IncludeUnavailable && d.Status.DriveStatus == directcsi.DriveStatusUnavailable {
+			continue
+		}
+		if d.MatchGlob(filter.Nodes, filter.Drives, filter.Statuses) && d.MatchAccessTier(accessTierSet) {
+			filteredDrives = append(filteredDrives, d)
+		}
+	}
+	return filteredDrives, nil
+}
+
+// ListDrives lists the drives selected by the filter
+func (c *Client) ListDrives(ctx context.Context, filter DriveFilter) ([]directcsi.DirectCSIDrive, error) {
+	driveList, err := c.directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return FilterDrives(driveList.Items, filter)
+}
@@ -0,0 +1,86 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeFilter selects the volumes like the flags of `volumes ls`. Empty lists match all the volumes.
+type VolumeFilter struct {
+	// Drive selects the drives holding the volumes, the volumes on unavailable drives are never selected
+	Drive DriveFilter
+	// Statuses are the conditions which must be true, among staged|published
+	Statuses []string
+	// PodNames are the glob patterns of the names of the pods using the volumes
+	PodNames []string
+	// PodNamespaces are the glob patterns of the namespaces of the pods using the volumes
+	PodNamespaces []string
+}
+
+// FilterVolumes returns the volumes selected by the filter
+func FilterVolumes(volumes []directcsi.DirectCSIVolume, drives []directcsi.DirectCSIDrive, filter VolumeFilter) ([]directcsi.DirectCSIVolume, error) {
+	driveFilter := filter.Drive
+	driveFilter.IncludeUnavailable = false
+	filteredDrives, err := FilterDrives(drives, driveFilter)
+	if err != nil {
+		return nil, err
+	}
+	driveNames := map[string]struct{}{}
+	for _, d := range filteredDrives {
+		driveNames[d.Name] = struct{}{}
+	}
+
+	filteredVolumes := []directcsi.DirectCSIVolume{}
+	for _, v := range volumes {
+		if _, found := driveNames[v.Status.Drive]; !found {
+			continue
+		}
+		if v.MatchStatus(filter.Statuses) && v.MatchPodName(filter.PodNames) && v.MatchPodNamespace(filter.PodNamespaces) {
+			filteredVolumes = append(filteredVolumes, v)
+		}
+	}
+	return filteredVolumes, nil
+}
+
+// CountVolumes returns the number of volumes on each drive by drive name
+func CountVolumes(volumes []directcsi.DirectCSIVolume) map[string]int {
+	counts := map[string]int{}
+	for _, v := range volumes {
+		if v.Status.Drive != "" {
+			counts[v.Status.Drive]++
+		}
+	}
+	return counts
+}
+
+// ListVolumes lists the volumes selected by the filter
+func (c *Client) ListVolumes(ctx context.Context, filter VolumeFilter) ([]directcsi.DirectCSIVolume, error) {
+	driveList, err := c.directClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	volumeList, err := c.directClient.DirectCSIVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return FilterVolumes(volumeList.Items, driveList.Items, filter)
+}
@@ -27,6 +27,7 @@ import (
 type DirectV1beta2Interface interface {
 	RESTClient() rest.Interface
 	DirectCSIDrivesGetter
+	DirectCSISnapshotsGetter
 	DirectCSIVolumesGetter
 }
 
@@ -39,6 +40,10 @@ func (c *DirectV1beta2Client) DirectCSIDrives() DirectCSIDriveInterface {
 	return newDirectCSIDrives(c)
 }
 
+func (c *DirectV1beta2Client) DirectCSISnapshots() DirectCSISnapshotInterface {
+	return newDirectCSISnapshots(c)
+}
+
 func (c *DirectV1beta2Client) DirectCSIVolumes() DirectCSIVolumeInterface {
 	return newDirectCSIVolumes(c)
 }
@@ -32,6 +32,10 @@ func (c *FakeDirectV1beta2) DirectCSIDrives() v1beta2.DirectCSIDriveInterface {
 	return &FakeDirectCSIDrives{c}
 }
 
+func (c *FakeDirectV1beta2) DirectCSISnapshots() v1beta2.DirectCSISnapshotInterface {
+	return &FakeDirectCSISnapshots{c}
+}
+
 func (c *FakeDirectV1beta2) DirectCSIVolumes() v1beta2.DirectCSIVolumeInterface {
 	return &FakeDirectCSIVolumes{c}
 }
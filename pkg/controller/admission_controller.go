@@ -23,7 +23,7 @@ import (
 	"net"
 	"net/http"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -32,9 +32,10 @@ const (
 	keyPath           = "/etc/certs/key.pem"
 	driveHandlerPath  = "/validatedrive"
 	volumeHandlerPath = "/validatevolume"
+	pvcHandlerPath    = "/validatepvc"
 )
 
-func serveAdmissionController(ctx context.Context) {
+func serveAdmissionController(ctx context.Context, identity string) {
 	certs, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
 		klog.Errorf("Filed to load key pair: %v", err)
@@ -49,10 +50,11 @@ func serveAdmissionController(ctx context.Context) {
 	}
 
 	// define http server and server handler
-	vh := ValidationHandler{}
+	vh := ValidationHandler{identity: identity}
 	mux := http.NewServeMux()
 	mux.HandleFunc(driveHandlerPath, vh.validateDrive)
 	mux.HandleFunc(volumeHandlerPath, vh.validateVolume)
+	mux.HandleFunc(pvcHandlerPath, vh.validatePVC)
 	server.Handler = mux
 
 	lc := net.ListenConfig{}
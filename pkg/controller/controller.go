@@ -19,6 +19,10 @@ package controller
 import (
 	"context"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/clientset"
@@ -33,6 +37,7 @@ import (
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"k8s.io/klog/v2"
 )
@@ -76,9 +81,15 @@ import (
  *
  */
 
-func NewControllerServer(ctx context.Context, identity, nodeID, rack, zone, region string) (*ControllerServer, error) {
+func NewControllerServer(ctx context.Context, identity, nodeID, rack, zone, region string, provisioningStrategy ProvisioningStrategy, driveFinalizerGracePeriod time.Duration) (*ControllerServer, error) {
+	if _, err := NewDriveSelector(provisioningStrategy); err != nil {
+		return &ControllerServer{}, err
+	}
+
 	// Start admission webhook server
-	go serveAdmissionController(ctx)
+	go serveAdmissionController(ctx, identity)
+	// Release the deleted drives of the lost nodes
+	go runOrphanedDriveReconciler(ctx, driveFinalizerGracePeriod)
 
 	kubeConfig := utils.GetKubeConfig()
 	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
@@ -101,6 +112,8 @@ func NewControllerServer(ctx context.Context, identity, nodeID, rack, zone, regi
 		Zone:            zone,
 		Region:          region,
 		directcsiClient: directClientset,
+
+		ProvisioningStrategy: provisioningStrategy,
 	}, nil
 }
 
@@ -111,6 +124,39 @@ type ControllerServer struct {
 	Zone            string
 	Region          string
 	directcsiClient clientset.Interface
+
+	// ProvisioningStrategy is used if the storage class does not set one
+	ProvisioningStrategy ProvisioningStrategy
+	// the selectors are shared across the requests to keep their state
+	driveSelectors      map[ProvisioningStrategy]DriveSelector
+	driveSelectorsMutex sync.Mutex
+}
+
+// getDriveSelector returns the drive selector of the provisioning strategy in the storage class parameters
+func (c *ControllerServer) getDriveSelector(parameters map[string]string) (DriveSelector, error) {
+	strategy := c.ProvisioningStrategy
+	if value, ok := parameters[provisioningStrategyParameter]; ok {
+		strategy = ProvisioningStrategy(value)
+	}
+	if strategy == "" {
+		strategy = ProvisioningStrategyMostFree
+	}
+
+	c.driveSelectorsMutex.Lock()
+	defer c.driveSelectorsMutex.Unlock()
+
+	if selector, ok := c.driveSelectors[strategy]; ok {
+		return selector, nil
+	}
+	selector, err := NewDriveSelector(strategy)
+	if err != nil {
+		return nil, err
+	}
+	if c.driveSelectors == nil {
+		c.driveSelectors = map[ProvisioningStrategy]DriveSelector{}
+	}
+	c.driveSelectors[strategy] = selector
+	return selector, nil
 }
 
 func (c *ControllerServer) ControllerGetCapabilities(ctx context.Context, req *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
@@ -129,37 +175,53 @@ func (c *ControllerServer) ControllerGetCapabilities(ctx context.Context, req *c
 	return &csi.ControllerGetCapabilitiesResponse{
 		Capabilities: []*csi.ControllerServiceCapability{
 			controllerCap(csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME),
+			controllerCap(csi.ControllerServiceCapability_RPC_LIST_VOLUMES),
+			controllerCap(csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES),
+			controllerCap(csi.ControllerServiceCapability_RPC_GET_VOLUME),
+			controllerCap(csi.ControllerServiceCapability_RPC_VOLUME_CONDITION),
+			controllerCap(csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT),
+			controllerCap(csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS),
+			controllerCap(csi.ControllerServiceCapability_RPC_GET_CAPACITY),
 		},
 	}, nil
 }
 
+// ValidateVolumeCapabilities confirms the capabilities of an existing volume, the
+// volumes are node local xfs mounts which are writable by a single node only
 func (c *ControllerServer) ValidateVolumeCapabilities(ctx context.Context, req *csi.ValidateVolumeCapabilitiesRequest) (*csi.ValidateVolumeCapabilitiesResponse, error) {
-	validateVolumeCapabilities := func() error {
-		vcaps := req.GetVolumeCapabilities()
-		for _, vcap := range vcaps {
-			access := vcap.GetAccessMode()
-			if access != nil {
-				mode := access.GetMode()
-				if mode != csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER {
-					return status.Errorf(codes.InvalidArgument, "unsupported access mode: %s", mode)
-				}
-			}
+	klog.V(4).Infof("ValidateVolumeCapabilitiesRequest: %v", req)
+	vID := req.GetVolumeId()
+	if vID == "" {
+		return nil, status.Error(codes.InvalidArgument, "volume ID missing in request")
+	}
+	vcaps := req.GetVolumeCapabilities()
+	if len(vcaps) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "volume capabilities missing in request")
+	}
+
+	vclient := c.directcsiClient.DirectV1beta2().DirectCSIVolumes()
+	if _, err := vclient.Get(ctx, vID, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "volume %s not found", vID)
 		}
-		return nil
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	var confirmed *csi.ValidateVolumeCapabilitiesResponse_Confirmed
-	message := ""
-	if err := validateVolumeCapabilities(); err != nil {
-		confirmed = &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
-			VolumeCapabilities: req.GetVolumeCapabilities(),
+	for _, vcap := range vcaps {
+		if err := validateVolumeCapability(vcap); err != nil {
+			return &csi.ValidateVolumeCapabilitiesResponse{
+				Message: err.Error(),
+			}, nil
 		}
-	} else {
-		message = err.Error()
 	}
 	return &csi.ValidateVolumeCapabilitiesResponse{
-		Confirmed: confirmed,
-		Message:   message,
+		Confirmed: &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
+			VolumeContext:      req.GetVolumeContext(),
+			VolumeCapabilities: vcaps,
+			Parameters:         req.GetParameters(),
+		},
 	}, nil
 }
 
@@ -171,19 +233,30 @@ func (c *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolu
 		return nil, status.Error(codes.InvalidArgument, "volume name cannot be empty")
 	}
 
+	if _, err := utils.ValidateMountPropagation(req.GetParameters()[utils.MountPropagationParameter]); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := utils.ParseInodeLimit(req.GetParameters()[utils.InodeLimitParameter]); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := utils.ParseFSGroup(req.GetParameters()[utils.FSGroupParameter]); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := utils.ParseReservedPercent(req.GetParameters()[utils.ReservedPercentParameter]); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := utils.ValidateFSGroupChangePolicy(req.GetParameters()[utils.FSGroupChangePolicyParameter]); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	directCSIClient := c.directcsiClient.DirectV1beta2()
 	dclient := directCSIClient.DirectCSIDrives()
 	vclient := directCSIClient.DirectCSIVolumes()
 
 	validateVolumeCapabilities := func() error {
-		vcaps := req.GetVolumeCapabilities()
-		for _, vcap := range vcaps {
-			access := vcap.GetAccessMode()
-			if access != nil {
-				mode := access.GetMode()
-				if mode != csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER {
-					return status.Errorf(codes.InvalidArgument, "unsupported access mode: %s", mode)
-				}
+		for _, vcap := range req.GetVolumeCapabilities() {
+			if err := validateVolumeCapability(vcap); err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
 			}
 		}
 		return nil
@@ -213,12 +286,23 @@ func (c *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolu
 			return nil, err
 		}
 
-		selectedDrive, err := FilterDrivesByTopologyRequirements(req, filteredDrives)
+		selector, err := c.getDriveSelector(req.GetParameters())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Error while selecting the provisioning strategy: %v", err)
+		}
+
+		selectedDrive, err := FilterDrivesByTopologyRequirements(req, filteredDrives, selector)
 		if err != nil {
 			return nil, err
 		}
 		klog.V(4).Infof("Selected DirectCSI drive: (Name: %s, NodeName: %s)", selectedDrive.Name, selectedDrive.Status.NodeName)
 
+		// the filtered drives carry the headroom of the storage class, the listed drive is reserved
+		for i := range drives {
+			if drives[i].Name == selectedDrive.Name {
+				return &drives[i], nil
+			}
+		}
 		return &selectedDrive, nil
 	}
 
@@ -229,7 +313,9 @@ func (c *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolu
 		}
 		// if no size requirement is specified, occupy all the free capacity on the drive
 		if size == 0 {
-			size = drive.Status.FreeCapacity
+			// the reserved percent is validated above
+			reservedPercent, _ := utils.ParseReservedPercent(req.GetParameters()[utils.ReservedPercentParameter])
+			size = schedulableCapacity(drive, reservedPercent)
 		}
 		return size
 	}
@@ -297,6 +383,7 @@ func (c *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolu
 			TotalCapacity:     size,
 			AvailableCapacity: size,
 			UsedCapacity:      0,
+			Topology:          drive.Status.Topology,
 			Conditions: []metav1.Condition{
 				{
 					Type:               string(directcsi.DirectCSIVolumeConditionStaged),
@@ -323,6 +410,16 @@ func (c *ControllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolu
 		},
 	}
 
+	if tenant := req.GetParameters()[tenantParameter]; tenant != "" {
+		vol.Labels[utils.TenantLabel] = utils.SanitizeLabelV(tenant)
+	}
+	if pvcName := req.GetParameters()[pvcNameParameter]; pvcName != "" {
+		vol.Labels[utils.PVCNameLabel] = utils.SanitizeLabelV(pvcName)
+	}
+	if pvcNamespace := req.GetParameters()[pvcNamespaceParameter]; pvcNamespace != "" {
+		vol.Labels[utils.PVCNamespaceLabel] = utils.SanitizeLabelV(pvcNamespace)
+	}
+
 	if _, err := vclient.Create(ctx, vol, metav1.CreateOptions{}); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			return nil, status.Errorf(codes.Internal, "could not create volume [%s]: %v", name, err)
@@ -405,8 +502,68 @@ func (c *ControllerServer) DeleteVolume(ctx context.Context, req *csi.DeleteVolu
 	return &csi.DeleteVolumeResponse{}, nil
 }
 
+// ListVolumes lists the volumes sorted by name, the starting token is the index of the next volume to be listed
 func (c *ControllerServer) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "unimplemented")
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid max entries %d", req.GetMaxEntries())
+	}
+
+	directCSIClient := c.directcsiClient.DirectV1beta2()
+	volumeList, err := directCSIClient.DirectCSIVolumes().List(ctx, metav1.ListOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not retreive directcsivolumes: %v", err)
+	}
+	volumes := volumeList.Items
+	sort.SliceStable(volumes, func(i, j int) bool {
+		return volumes[i].Name < volumes[j].Name
+	})
+
+	start := 0
+	if token := req.GetStartingToken(); token != "" {
+		if start, err = strconv.Atoi(token); err != nil || start < 0 || start > len(volumes) {
+			return nil, status.Errorf(codes.Aborted, "invalid starting token %q", token)
+		}
+	}
+	end := len(volumes)
+	if maxEntries := int(req.GetMaxEntries()); maxEntries > 0 && start+maxEntries < end {
+		end = start + maxEntries
+	}
+
+	driveList, err := directCSIClient.DirectCSIDrives().List(ctx, metav1.ListOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not retreive directcsidrives: %v", err)
+	}
+	drives := map[string]*directcsi.DirectCSIDrive{}
+	for i := range driveList.Items {
+		drives[driveList.Items[i].Name] = &driveList.Items[i]
+	}
+
+	entries := []*csi.ListVolumesResponse_Entry{}
+	for i := start; i < end; i++ {
+		volume := &volumes[i]
+		drive := drives[volume.Status.Drive]
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: getCSIVolume(volume, drive),
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: getPublishedNodeIDs(volume),
+				VolumeCondition:  getVolumeCondition(volume, drive),
+			},
+		})
+	}
+
+	nextToken := ""
+	if end < len(volumes) {
+		nextToken = strconv.Itoa(end)
+	}
+
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: nextToken,
+	}, nil
 }
 
 func (c *ControllerServer) ControllerPublishVolume(ctx context.Context, req *csi.ControllerPublishVolumeRequest) (*csi.ControllerPublishVolumeResponse, error) {
@@ -422,21 +579,247 @@ func (c *ControllerServer) ControllerExpandVolume(ctx context.Context, req *csi.
 }
 
 func (c *ControllerServer) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "unimplemented")
+	vID := req.GetVolumeId()
+	if vID == "" {
+		return nil, status.Error(codes.InvalidArgument, "volume ID missing in request")
+	}
+
+	directCSIClient := c.directcsiClient.DirectV1beta2()
+	volume, err := directCSIClient.DirectCSIVolumes().Get(ctx, vID, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "volume [%s] not found", vID)
+		}
+		return nil, status.Errorf(codes.Internal, "could not retreive volume [%s]: %v", vID, err)
+	}
+
+	var drive *directcsi.DirectCSIDrive
+	if volume.Status.Drive != "" {
+		drive, err = directCSIClient.DirectCSIDrives().Get(ctx, volume.Status.Drive, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, status.Errorf(codes.Internal, "could not retreive drive [%s]: %v", volume.Status.Drive, err)
+			}
+			drive = nil
+		}
+	}
+
+	return &csi.ControllerGetVolumeResponse{
+		Volume: getCSIVolume(volume, drive),
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: getPublishedNodeIDs(volume),
+			VolumeCondition:  getVolumeCondition(volume, drive),
+		},
+	}, nil
 }
 
+// ListSnapshots lists the snapshots by name, filtered by the snapshot ID or the source volume ID of the request
 func (c *ControllerServer) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "unimplemented")
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid max entries %d", req.GetMaxEntries())
+	}
+
+	snapshotList, err := c.directcsiClient.DirectV1beta2().DirectCSISnapshots().List(ctx, metav1.ListOptions{
+		TypeMeta: utils.DirectCSISnapshotTypeMeta(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not retreive directcsisnapshots: %v", err)
+	}
+	snapshots := []directcsi.DirectCSISnapshot{}
+	for _, snapshot := range snapshotList.Items {
+		// the failed snapshots hold no copy, CreateSnapshot reports them as failed
+		if snapshot.Status.Message != "" {
+			continue
+		}
+		if req.GetSnapshotId() != "" && snapshot.Name != req.GetSnapshotId() {
+			continue
+		}
+		if req.GetSourceVolumeId() != "" && snapshot.Status.SourceVolume != req.GetSourceVolumeId() {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Name < snapshots[j].Name
+	})
+
+	start := 0
+	if token := req.GetStartingToken(); token != "" {
+		if start, err = strconv.Atoi(token); err != nil || start < 0 || start > len(snapshots) {
+			return nil, status.Errorf(codes.Aborted, "invalid starting token %q", token)
+		}
+	}
+	end := len(snapshots)
+	if maxEntries := int(req.GetMaxEntries()); maxEntries > 0 && start+maxEntries < end {
+		end = start + maxEntries
+	}
+
+	entries := []*csi.ListSnapshotsResponse_Entry{}
+	for i := start; i < end; i++ {
+		entries = append(entries, &csi.ListSnapshotsResponse_Entry{
+			Snapshot: getCSISnapshot(&snapshots[i]),
+		})
+	}
+
+	nextToken := ""
+	if end < len(snapshots) {
+		nextToken = strconv.Itoa(end)
+	}
+
+	return &csi.ListSnapshotsResponse{
+		Entries:   entries,
+		NextToken: nextToken,
+	}, nil
 }
 
+// CreateSnapshot creates the snapshot object, the node of the source volume takes a reflink copy of the volume on its drive
 func (c *ControllerServer) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "unimplemented")
+	name := req.GetName()
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "snapshot name missing in request")
+	}
+	vID := req.GetSourceVolumeId()
+	if vID == "" {
+		return nil, status.Error(codes.InvalidArgument, "source volume ID missing in request")
+	}
+
+	directCSIClient := c.directcsiClient.DirectV1beta2()
+	sclient := directCSIClient.DirectCSISnapshots()
+	dclient := directCSIClient.DirectCSIDrives()
+
+	// the copy may grow up to the size of the volume, hence charge it to the drive like a volume
+	reserveDrive := func(driveName string, size int64) error {
+		drive, err := dclient.Get(ctx, driveName, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "could not retrieve drive [%s]: %v", driveName, err)
+		}
+		finalizer := directcsi.DirectCSIDriveFinalizerSnapshotPrefix + name
+		finalizers := drive.GetFinalizers()
+		for _, f := range finalizers {
+			if f == finalizer {
+				return nil
+			}
+		}
+		if drive.Status.FreeCapacity < size {
+			return status.Errorf(codes.ResourceExhausted, "drive [%s] has no free capacity for snapshot [%s]", driveName, name)
+		}
+
+		drive.Status.FreeCapacity = drive.Status.FreeCapacity - size
+		drive.Status.AllocatedCapacity = drive.Status.AllocatedCapacity + size
+		drive.SetFinalizers(append(finalizers, finalizer))
+
+		klog.V(4).Infof("Reserving DirectCSI drive for snapshot: (Name: %s, Snapshot: %s)", drive.Name, name)
+		if _, err := dclient.Update(ctx, drive, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		}); err != nil {
+			return status.Errorf(codes.Internal, "could not reserve drive[%s] %v", drive.Name, err)
+		}
+		return nil
+	}
+
+	snapshot, err := sclient.Get(ctx, name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSISnapshotTypeMeta(),
+	})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, status.Errorf(codes.Internal, "could not retrieve snapshot [%s]: %v", name, err)
+		}
+
+		vol, err := directCSIClient.DirectCSIVolumes().Get(ctx, vID, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil, status.Errorf(codes.NotFound, "source volume [%s] not found", vID)
+			}
+			return nil, status.Errorf(codes.Internal, "could not retrieve volume [%s]: %v", vID, err)
+		}
+
+		if err := reserveDrive(vol.Status.Drive, vol.Status.TotalCapacity); err != nil {
+			return nil, err
+		}
+
+		newSnapshot := &directcsi.DirectCSISnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Finalizers: []string{
+					directcsi.DirectCSISnapshotFinalizerDataProtection,
+				},
+			},
+			Status: directcsi.DirectCSISnapshotStatus{
+				SourceVolume: vID,
+				Drive:        vol.Status.Drive,
+				NodeName:     vol.Status.NodeName,
+				SizeBytes:    vol.Status.TotalCapacity,
+			},
+		}
+		snapshot, err = sclient.Create(ctx, newSnapshot, metav1.CreateOptions{})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "could not create snapshot [%s]: %v", name, err)
+		}
+		klog.V(4).Infof("Created DirectCSI snapshot: (Name: %s, Volume: %s, Drive: %s)", name, vID, vol.Status.Drive)
+	}
+
+	if snapshot.Status.SourceVolume != vID {
+		return nil, status.Errorf(codes.AlreadyExists, "snapshot [%s] already exists for volume [%s]", name, snapshot.Status.SourceVolume)
+	}
+	if snapshot.Status.Message != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "snapshot [%s] failed: %s", name, snapshot.Status.Message)
+	}
+
+	return &csi.CreateSnapshotResponse{
+		Snapshot: getCSISnapshot(snapshot),
+	}, nil
 }
 
+// DeleteSnapshot deletes the snapshot object, the copy is removed by the node before the object goes away
 func (c *ControllerServer) DeleteSnapshot(ctx context.Context, req *csi.DeleteSnapshotRequest) (*csi.DeleteSnapshotResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "unimplemented")
+	sID := req.GetSnapshotId()
+	if sID == "" {
+		return nil, status.Error(codes.InvalidArgument, "snapshot ID missing in request")
+	}
+
+	sclient := c.directcsiClient.DirectV1beta2().DirectCSISnapshots()
+	if err := sclient.Delete(ctx, sID, metav1.DeleteOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			return &csi.DeleteSnapshotResponse{}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "could not delete snapshot [%s]: %v", sID, err)
+	}
+
+	return &csi.DeleteSnapshotResponse{}, nil
 }
 
+// GetCapacity reports the free capacity of the ready drives matching the topology and the parameters
 func (c *ControllerServer) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "unimplemented")
+	driveList, err := c.directcsiClient.DirectV1beta2().DirectCSIDrives().List(ctx, metav1.ListOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not retreive directcsidrives: %v", err)
+	}
+
+	drives, err := FilterDrivesByCapacityRequest(req, driveList.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// a volume cannot span drives, the largest volume fits in the drive with the most free capacity
+	var availableCapacity, maximumVolumeSize int64
+	for _, drive := range drives {
+		availableCapacity += drive.Status.FreeCapacity
+		if drive.Status.FreeCapacity > maximumVolumeSize {
+			maximumVolumeSize = drive.Status.FreeCapacity
+		}
+	}
+	return &csi.GetCapacityResponse{
+		AvailableCapacity: availableCapacity,
+		MaximumVolumeSize: wrapperspb.Int64(maximumVolumeSize),
+	}, nil
 }
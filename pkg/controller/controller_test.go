@@ -27,8 +27,15 @@ import (
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -303,6 +310,59 @@ func TestFilterDrivesByCapacityRange(t1 *testing.T) {
 	}
 }
 
+func TestReserveDrivesHeadroom(t1 *testing.T) {
+	newDrive := func(name string, total, free, reserved int64) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				TotalCapacity:    total,
+				FreeCapacity:     free,
+				ReservedCapacity: reserved,
+			},
+		}
+	}
+	testDriveSet := []directcsi.DirectCSIDrive{
+		newDrive("drive1", 10000, 8000, 0),
+		newDrive("drive2", 10000, 7000, 2000),
+		newDrive("drive3", 10000, 500, 0),
+	}
+
+	testCases := []struct {
+		name              string
+		reservedPercent   int64
+		expectedDriveList []directcsi.DirectCSIDrive
+	}{
+		{
+			name:              "test1",
+			reservedPercent:   0,
+			expectedDriveList: testDriveSet,
+		},
+		{
+			name:            "test2",
+			reservedPercent: 10,
+			expectedDriveList: []directcsi.DirectCSIDrive{
+				newDrive("drive1", 10000, 7000, 0),
+				newDrive("drive2", 10000, 7000, 2000),
+				newDrive("drive3", 10000, 0, 0),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			driveList := ReserveDrivesHeadroom(tt.reservedPercent, testDriveSet)
+			if !reflect.DeepEqual(driveList, tt.expectedDriveList) {
+				t1.Errorf("Test case name %s: Expected drive list = %v, got %v", tt.name, tt.expectedDriveList, driveList)
+			}
+		})
+	}
+	if testDriveSet[0].Status.FreeCapacity != 8000 {
+		t1.Errorf("Expected the listed drives to be retained, got free capacity %d", testDriveSet[0].Status.FreeCapacity)
+	}
+}
+
 func TestFilterDrivesByFsType(t1 *testing.T) {
 	testDriveSet := []directcsi.DirectCSIDrive{
 		{
@@ -780,6 +840,77 @@ func createFakeController() *ControllerServer {
 	}
 }
 
+func TestFilterDrivesByTenant(t1 *testing.T) {
+	createTestDrive := func(name, reservedFor string) directcsi.DirectCSIDrive {
+		drive := directcsi.DirectCSIDrive{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		}
+		if reservedFor != "" {
+			drive.Labels = map[string]string{
+				utils.ReservedForLabel: reservedFor,
+			}
+		}
+		return drive
+	}
+	testDrives := []directcsi.DirectCSIDrive{
+		createTestDrive("drive1", ""),
+		createTestDrive("drive2", "tenant-1"),
+		createTestDrive("drive3", "tenant-2"),
+	}
+
+	testCases := []struct {
+		name           string
+		tenant         string
+		selectedDrives []string
+	}{
+		{
+			name:           "noTenant",
+			tenant:         "",
+			selectedDrives: []string{"drive1"},
+		},
+		{
+			name:           "matchingTenant",
+			tenant:         "tenant-1",
+			selectedDrives: []string{"drive1", "drive2"},
+		},
+		{
+			name:           "unknownTenant",
+			tenant:         "tenant-3",
+			selectedDrives: []string{"drive1"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			selectedDrives := []string{}
+			for _, drive := range FilterDrivesByTenant(tt.tenant, testDrives) {
+				selectedDrives = append(selectedDrives, drive.Name)
+			}
+			if !reflect.DeepEqual(selectedDrives, tt.selectedDrives) {
+				t1.Errorf("Test case name %s: Expected drives = %v, got %v", tt.name, tt.selectedDrives, selectedDrives)
+			}
+		})
+	}
+}
+
+func TestFilterDrivesBySchedulable(t1 *testing.T) {
+	testDrives := []directcsi.DirectCSIDrive{
+		{ObjectMeta: metav1.ObjectMeta{Name: "drive1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "drive2", Labels: map[string]string{utils.UnschedulableLabel: "true"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "drive3", Labels: map[string]string{utils.UnschedulableLabel: "false"}}},
+	}
+
+	selectedDrives := []string{}
+	for _, drive := range FilterDrivesBySchedulable(testDrives) {
+		selectedDrives = append(selectedDrives, drive.Name)
+	}
+	if expected := []string{"drive1", "drive3"}; !reflect.DeepEqual(selectedDrives, expected) {
+		t1.Errorf("Expected drives = %v, got %v", expected, selectedDrives)
+	}
+}
+
 func TestCreateAndDeleteVolumeRPCs(t *testing.T) {
 
 	getTopologySegmentsForNode := func(node string) map[string]string {
@@ -918,6 +1049,10 @@ func TestCreateAndDeleteVolumeRPCs(t *testing.T) {
 		if volObj.Status.TotalCapacity != cvReq.CapacityRange.RequiredBytes {
 			t.Errorf("[%s] Expected total capacity of the volume to be %d but got %d", volName, cvReq.CapacityRange.RequiredBytes, volObj.Status.TotalCapacity)
 		}
+		// Step 7: Check if the satisfied topology is recorded on the volume
+		if !reflect.DeepEqual(volObj.Status.Topology, vol.GetAccessibleTopology()[0].GetSegments()) {
+			t.Errorf("[%s] Expected topology of the volume to be %v but got %v", volName, vol.GetAccessibleTopology()[0].GetSegments(), volObj.Status.Topology)
+		}
 	}
 
 	// Fetch the drive objects
@@ -961,6 +1096,442 @@ func TestCreateAndDeleteVolumeRPCs(t *testing.T) {
 	}
 }
 
+func TestCreateVolumeReservedPercent(t1 *testing.T) {
+	topology := map[string]string{"node": "N1"}
+	testDrive := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "D1",
+			Finalizers: []string{directcsi.DirectCSIDriveFinalizerDataProtection},
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:      "N1",
+			Filesystem:    string(sys.FSTypeXFS),
+			DriveStatus:   directcsi.DriveStatusReady,
+			FreeCapacity:  mb100,
+			TotalCapacity: mb100,
+			Topology:      topology,
+		},
+	}
+	createRequest := func(name string, size int64) *csi.CreateVolumeRequest {
+		return &csi.CreateVolumeRequest{
+			Name:          name,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: size},
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{FsType: "xfs"},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+			},
+			AccessibilityRequirements: &csi.TopologyRequirement{
+				Requisite: []*csi.Topology{{Segments: topology}},
+			},
+			Parameters: map[string]string{utils.ReservedPercentParameter: "10"},
+		}
+	}
+
+	ctx := context.TODO()
+	cl := createFakeController()
+	cl.directcsiClient = fakedirect.NewSimpleClientset(testDrive)
+
+	// the headroom of the storage class leaves 90MB to the volumes
+	if _, err := cl.CreateVolume(ctx, createRequest("volume-1", mb100-5*MB)); status.Code(err) != codes.OutOfRange {
+		t1.Fatalf("Expected %v for a volume exceeding the headroom, got %v", codes.OutOfRange, err)
+	}
+	if _, err := cl.CreateVolume(ctx, createRequest("volume-2", mb20)); err != nil {
+		t1.Fatalf("CreateVolume failed: %v", err)
+	}
+
+	drive, err := cl.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(ctx, "D1", metav1.GetOptions{})
+	if err != nil {
+		t1.Fatalf("Unable to get drive D1: %v", err)
+	}
+	// only the volume is accounted on the drive, the headroom is not recorded
+	if drive.Status.FreeCapacity != mb100-mb20 || drive.Status.AllocatedCapacity != mb20 || drive.Status.ReservedCapacity != 0 {
+		t1.Errorf("Expected free %d, allocated %d and reserved 0, got %d, %d and %d", mb100-mb20, mb20,
+			drive.Status.FreeCapacity, drive.Status.AllocatedCapacity, drive.Status.ReservedCapacity)
+	}
+}
+
+func TestListAndGetVolumeRPCs(t1 *testing.T) {
+	createTestDrive := func(name string, driveStatus directcsi.DriveStatus) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:    "N1",
+				DriveStatus: driveStatus,
+				Topology:    map[string]string{"node": "N1"},
+			},
+		}
+	}
+	createTestVolume := func(name, drive string, staged, ready, published bool) *directcsi.DirectCSIVolume {
+		return &directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				Drive:         drive,
+				NodeName:      "N1",
+				TotalCapacity: mb20,
+				Conditions: []metav1.Condition{
+					{Type: string(directcsi.DirectCSIVolumeConditionStaged), Status: utils.BoolToCondition(staged)},
+					{Type: string(directcsi.DirectCSIVolumeConditionPublished), Status: utils.BoolToCondition(published)},
+					{Type: string(directcsi.DirectCSIVolumeConditionReady), Status: utils.BoolToCondition(ready)},
+				},
+			},
+		}
+	}
+
+	testObjects := []runtime.Object{
+		createTestDrive("D1", directcsi.DriveStatusInUse),
+		createTestDrive("D2", directcsi.DriveStatusUnavailable),
+		createTestVolume("volume-3", "D1", true, false, false),
+		createTestVolume("volume-1", "D1", true, true, true),
+		createTestVolume("volume-2", "D2", false, false, false),
+		createTestVolume("volume-4", "D3", false, false, false),
+	}
+
+	ctx := context.TODO()
+	cl := createFakeController()
+	cl.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+
+	expectedAbnormal := map[string]bool{
+		"volume-1": false,
+		"volume-2": true,
+		"volume-3": true,
+		"volume-4": true,
+	}
+
+	// List the volumes in pages of three
+	volumeIDs := []string{}
+	token := ""
+	for {
+		res, err := cl.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: 3, StartingToken: token})
+		if err != nil {
+			t1.Fatalf("ListVolumes failed: %v", err)
+		}
+		if len(res.GetEntries()) > 3 {
+			t1.Errorf("Expected at most 3 entries, got %d", len(res.GetEntries()))
+		}
+		for _, entry := range res.GetEntries() {
+			volumeID := entry.GetVolume().GetVolumeId()
+			volumeIDs = append(volumeIDs, volumeID)
+			if abnormal := entry.GetStatus().GetVolumeCondition().GetAbnormal(); abnormal != expectedAbnormal[volumeID] {
+				t1.Errorf("[%s] Expected abnormal = %v, got %v", volumeID, expectedAbnormal[volumeID], abnormal)
+			}
+		}
+		if token = res.GetNextToken(); token == "" {
+			break
+		}
+	}
+	if expected := []string{"volume-1", "volume-2", "volume-3", "volume-4"}; !reflect.DeepEqual(volumeIDs, expected) {
+		t1.Errorf("Expected volumes = %v, got %v", expected, volumeIDs)
+	}
+
+	if _, err := cl.ListVolumes(ctx, &csi.ListVolumesRequest{StartingToken: "invalid"}); status.Code(err) != codes.Aborted {
+		t1.Errorf("Expected %v for an invalid starting token, got %v", codes.Aborted, err)
+	}
+
+	res, err := cl.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: "volume-1"})
+	if err != nil {
+		t1.Fatalf("ControllerGetVolume failed: %v", err)
+	}
+	if res.GetVolume().GetCapacityBytes() != mb20 {
+		t1.Errorf("Expected capacity %d, got %d", mb20, res.GetVolume().GetCapacityBytes())
+	}
+	if nodeIDs := res.GetStatus().GetPublishedNodeIds(); !reflect.DeepEqual(nodeIDs, []string{"N1"}) {
+		t1.Errorf("Expected published node ids [N1], got %v", nodeIDs)
+	}
+	if res.GetStatus().GetVolumeCondition().GetAbnormal() {
+		t1.Errorf("Expected volume-1 to be healthy, got %v", res.GetStatus().GetVolumeCondition())
+	}
+
+	if _, err := cl.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: "volume-5"}); status.Code(err) != codes.NotFound {
+		t1.Errorf("Expected %v for an unknown volume, got %v", codes.NotFound, err)
+	}
+}
+
+func TestCreateAndDeleteSnapshot(t1 *testing.T) {
+	testObjects := []runtime.Object{
+		&directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "volume-1",
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				Drive:         "D1",
+				NodeName:      "N1",
+				TotalCapacity: mb20,
+			},
+		},
+		&directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "volume-3",
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				Drive:         "D2",
+				NodeName:      "N1",
+				TotalCapacity: mb20,
+			},
+		},
+		&directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "D1",
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          "N1",
+				DriveStatus:       directcsi.DriveStatusInUse,
+				TotalCapacity:     mb100,
+				FreeCapacity:      mb100 - mb20,
+				AllocatedCapacity: mb20,
+			},
+		},
+		&directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "D2",
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          "N1",
+				DriveStatus:       directcsi.DriveStatusInUse,
+				TotalCapacity:     mb20,
+				AllocatedCapacity: mb20,
+			},
+		},
+		&directcsi.DirectCSISnapshot{
+			TypeMeta: utils.DirectCSISnapshotTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "failed-snapshot",
+			},
+			Status: directcsi.DirectCSISnapshotStatus{
+				SourceVolume: "volume-1",
+				Drive:        "D1",
+				NodeName:     "N1",
+				Message:      "reflink is not enabled on the filesystem of drive D1",
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	cl := createFakeController()
+	cl.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+
+	testCases := []struct {
+		name         string
+		snapshotName string
+		volumeID     string
+		expectedCode codes.Code
+	}{
+		{
+			name:         "create",
+			snapshotName: "snapshot-1",
+			volumeID:     "volume-1",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "create_again",
+			snapshotName: "snapshot-1",
+			volumeID:     "volume-1",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "different_source",
+			snapshotName: "snapshot-1",
+			volumeID:     "volume-2",
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name:         "unknown_volume",
+			snapshotName: "snapshot-2",
+			volumeID:     "volume-2",
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "drive_full",
+			snapshotName: "snapshot-3",
+			volumeID:     "volume-3",
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "reflink_unsupported",
+			snapshotName: "failed-snapshot",
+			volumeID:     "volume-1",
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name:         "missing_name",
+			volumeID:     "volume-1",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			res, err := cl.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{Name: tt.snapshotName, SourceVolumeId: tt.volumeID})
+			if status.Code(err) != tt.expectedCode {
+				t1.Fatalf("Test case name %s: expected code %v, got %v", tt.name, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			snapshot := res.GetSnapshot()
+			if snapshot.GetSnapshotId() != tt.snapshotName || snapshot.GetSourceVolumeId() != tt.volumeID {
+				t1.Errorf("Test case name %s: unexpected snapshot %v", tt.name, snapshot)
+			}
+			if snapshot.GetSizeBytes() != mb20 {
+				t1.Errorf("Test case name %s: expected size %d, got %d", tt.name, mb20, snapshot.GetSizeBytes())
+			}
+			if snapshot.GetReadyToUse() {
+				t1.Errorf("Test case name %s: expected snapshot to be not ready before the node copies it", tt.name)
+			}
+		})
+	}
+
+	snapshot, err := cl.directcsiClient.DirectV1beta2().DirectCSISnapshots().Get(ctx, "snapshot-1", metav1.GetOptions{})
+	if err != nil {
+		t1.Fatalf("Snapshot snapshot-1 not created: %v", err)
+	}
+	if snapshot.Status.NodeName != "N1" || snapshot.Status.Drive != "D1" {
+		t1.Errorf("Expected snapshot on node N1 and drive D1, got %s and %s", snapshot.Status.NodeName, snapshot.Status.Drive)
+	}
+	if !reflect.DeepEqual(snapshot.GetFinalizers(), []string{directcsi.DirectCSISnapshotFinalizerDataProtection}) {
+		t1.Errorf("Expected finalizers %v, got %v", []string{directcsi.DirectCSISnapshotFinalizerDataProtection}, snapshot.GetFinalizers())
+	}
+
+	drive, err := cl.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(ctx, "D1", metav1.GetOptions{})
+	if err != nil {
+		t1.Fatalf("Unable to get drive D1: %v", err)
+	}
+	// the snapshot is charged once, creating it again is idempotent
+	if drive.Status.FreeCapacity != mb100-2*mb20 || drive.Status.AllocatedCapacity != 2*mb20 {
+		t1.Errorf("Expected free %d and allocated %d, got %d and %d", mb100-2*mb20, 2*mb20, drive.Status.FreeCapacity, drive.Status.AllocatedCapacity)
+	}
+	if !reflect.DeepEqual(drive.GetFinalizers(), []string{directcsi.DirectCSIDriveFinalizerSnapshotPrefix + "snapshot-1"}) {
+		t1.Errorf("Expected drive finalizers %v, got %v", []string{directcsi.DirectCSIDriveFinalizerSnapshotPrefix + "snapshot-1"}, drive.GetFinalizers())
+	}
+
+	if _, err := cl.DeleteSnapshot(ctx, &csi.DeleteSnapshotRequest{SnapshotId: "snapshot-1"}); err != nil {
+		t1.Errorf("DeleteSnapshot failed: %v", err)
+	}
+	if _, err := cl.DeleteSnapshot(ctx, &csi.DeleteSnapshotRequest{SnapshotId: "snapshot-3"}); err != nil {
+		t1.Errorf("Expected DeleteSnapshot of an unknown snapshot to succeed, got %v", err)
+	}
+}
+
+func TestListSnapshots(t1 *testing.T) {
+	createTestSnapshot := func(name, volume, message string) *directcsi.DirectCSISnapshot {
+		return &directcsi.DirectCSISnapshot{
+			TypeMeta: utils.DirectCSISnapshotTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: directcsi.DirectCSISnapshotStatus{
+				SourceVolume: volume,
+				Drive:        "D1",
+				NodeName:     "N1",
+				SizeBytes:    mb20,
+				ReadyToUse:   message == "",
+				Message:      message,
+			},
+		}
+	}
+
+	testObjects := []runtime.Object{
+		createTestSnapshot("snapshot-3", "volume-2", ""),
+		createTestSnapshot("snapshot-1", "volume-1", ""),
+		createTestSnapshot("snapshot-2", "volume-1", ""),
+		createTestSnapshot("failed-snapshot", "volume-1", "reflink is not enabled on the filesystem of drive D1"),
+	}
+
+	ctx := context.TODO()
+	cl := createFakeController()
+	cl.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+
+	testCases := []struct {
+		name     string
+		request  *csi.ListSnapshotsRequest
+		expected []string
+	}{
+		{
+			name:     "all",
+			request:  &csi.ListSnapshotsRequest{},
+			expected: []string{"snapshot-1", "snapshot-2", "snapshot-3"},
+		},
+		{
+			name:     "by_snapshot_id",
+			request:  &csi.ListSnapshotsRequest{SnapshotId: "snapshot-2"},
+			expected: []string{"snapshot-2"},
+		},
+		{
+			name:     "by_source_volume",
+			request:  &csi.ListSnapshotsRequest{SourceVolumeId: "volume-1"},
+			expected: []string{"snapshot-1", "snapshot-2"},
+		},
+		{
+			name:     "unknown_snapshot",
+			request:  &csi.ListSnapshotsRequest{SnapshotId: "snapshot-4"},
+			expected: []string{},
+		},
+		{
+			name:     "failed_snapshot",
+			request:  &csi.ListSnapshotsRequest{SnapshotId: "failed-snapshot"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			res, err := cl.ListSnapshots(ctx, tt.request)
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			snapshotIDs := []string{}
+			for _, entry := range res.GetEntries() {
+				snapshotIDs = append(snapshotIDs, entry.GetSnapshot().GetSnapshotId())
+			}
+			if !reflect.DeepEqual(snapshotIDs, tt.expected) {
+				t1.Errorf("Test case name %s: expected snapshots %v, got %v", tt.name, tt.expected, snapshotIDs)
+			}
+		})
+	}
+
+	// List the snapshots in pages of two
+	snapshotIDs := []string{}
+	token := ""
+	for {
+		res, err := cl.ListSnapshots(ctx, &csi.ListSnapshotsRequest{MaxEntries: 2, StartingToken: token})
+		if err != nil {
+			t1.Fatalf("ListSnapshots failed: %v", err)
+		}
+		if len(res.GetEntries()) > 2 {
+			t1.Errorf("Expected at most 2 entries, got %d", len(res.GetEntries()))
+		}
+		for _, entry := range res.GetEntries() {
+			snapshotIDs = append(snapshotIDs, entry.GetSnapshot().GetSnapshotId())
+		}
+		if token = res.GetNextToken(); token == "" {
+			break
+		}
+	}
+	if expected := []string{"snapshot-1", "snapshot-2", "snapshot-3"}; !reflect.DeepEqual(snapshotIDs, expected) {
+		t1.Errorf("Expected snapshots = %v, got %v", expected, snapshotIDs)
+	}
+
+	if _, err := cl.ListSnapshots(ctx, &csi.ListSnapshotsRequest{StartingToken: "invalid"}); status.Code(err) != codes.Aborted {
+		t1.Errorf("Expected %v for an invalid starting token, got %v", codes.Aborted, err)
+	}
+}
+
 func TestSelectDriveByFreeCapacity(t1 *testing.T) {
 	testCases := []struct {
 		name               string
@@ -1050,3 +1621,659 @@ func TestSelectDriveByFreeCapacity(t1 *testing.T) {
 		})
 	}
 }
+
+func TestMatchSegments(t1 *testing.T) {
+	driveSegments := map[string]string{"node": "N1", "rack": "RK1", "zone": "Z1", "region": "R1"}
+
+	testCases := []struct {
+		name        string
+		topSegments map[string]string
+		match       bool
+	}{
+		{
+			name:        "test1",
+			topSegments: map[string]string{"node": "N1", "rack": "RK1", "zone": "Z1", "region": "R1"},
+			match:       true,
+		},
+		{
+			name:        "test2",
+			topSegments: map[string]string{"node": "N1", "zone": "Z1"},
+			match:       true,
+		},
+		{
+			name:        "test3",
+			topSegments: map[string]string{"node": "N1", "rack": "RK2"},
+			match:       false,
+		},
+		{
+			name:        "test4",
+			topSegments: map[string]string{"node": "N2", "rack": "RK1", "zone": "Z1", "region": "R1"},
+			match:       false,
+		},
+		{
+			name:        "test5",
+			topSegments: map[string]string{"node": "N1", "disk": "D1"},
+			match:       false,
+		},
+		{
+			name:        "test6",
+			topSegments: map[string]string{},
+			match:       true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if match := matchSegments(tt.topSegments, driveSegments); match != tt.match {
+				t1.Errorf("Test case name %s: Expected match = %v, got %v", tt.name, tt.match, match)
+			}
+		})
+	}
+}
+
+func TestFilterDrivesByTopologyRequirements(t1 *testing.T) {
+	testDriveSet := []directcsi.DirectCSIDrive{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "drive1",
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				FreeCapacity: mb20,
+				Topology:     map[string]string{"node": "N1", "rack": "RK1", "zone": "Z1", "region": "R1"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "drive2",
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				FreeCapacity: mb50,
+				Topology:     map[string]string{"node": "N2", "rack": "RK1", "zone": "Z1", "region": "R1"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "drive3",
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				FreeCapacity: mb100,
+				Topology:     map[string]string{"node": "N3", "rack": "RK2", "zone": "Z1", "region": "R1"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		requiredBytes     int64
+		preferred         []*csi.Topology
+		requisite         []*csi.Topology
+		expectedDriveName string
+		expectedErrCode   codes.Code
+	}{
+		{
+			name:              "test1",
+			requiredBytes:     mb20,
+			preferred:         []*csi.Topology{{Segments: map[string]string{"node": "N1"}}},
+			requisite:         []*csi.Topology{{Segments: map[string]string{"zone": "Z1"}}},
+			expectedDriveName: "drive1",
+		},
+		{
+			name:              "test2",
+			requiredBytes:     mb30,
+			preferred:         []*csi.Topology{{Segments: map[string]string{"node": "N1"}}},
+			requisite:         []*csi.Topology{{Segments: map[string]string{"rack": "RK1"}}},
+			expectedDriveName: "drive2",
+		},
+		{
+			name:          "test3",
+			requiredBytes: mb30,
+			preferred:     []*csi.Topology{{Segments: map[string]string{"node": "N1"}}},
+			requisite: []*csi.Topology{
+				{Segments: map[string]string{"node": "N1", "rack": "RK1"}},
+				{Segments: map[string]string{"node": "N2", "rack": "RK1"}},
+				{Segments: map[string]string{"node": "N3", "rack": "RK2"}},
+			},
+			expectedDriveName: "drive3",
+		},
+		{
+			name:          "test4",
+			requiredBytes: mb30,
+			preferred:     []*csi.Topology{{Segments: map[string]string{"node": "N1"}}},
+			requisite: []*csi.Topology{
+				{Segments: map[string]string{"node": "N1", "rack": "RK2"}},
+				{Segments: map[string]string{"node": "N1", "rack": "RK1"}},
+			},
+			expectedErrCode: codes.ResourceExhausted,
+		},
+		{
+			name:              "test5",
+			requiredBytes:     mb20,
+			expectedDriveName: "drive3",
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			req := &csi.CreateVolumeRequest{
+				CapacityRange: &csi.CapacityRange{
+					RequiredBytes: tt.requiredBytes,
+				},
+				AccessibilityRequirements: &csi.TopologyRequirement{
+					Preferred: tt.preferred,
+					Requisite: tt.requisite,
+				},
+			}
+			selectedDrive, err := FilterDrivesByTopologyRequirements(req, testDriveSet, &mostFreeSelector{})
+			if tt.expectedErrCode != codes.OK {
+				if status.Code(err) != tt.expectedErrCode {
+					t1.Fatalf("Test case name %s: Expected error code %v, got %v", tt.name, tt.expectedErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: Unexpected error: %v", tt.name, err)
+			}
+			if selectedDrive.Name != tt.expectedDriveName {
+				t1.Errorf("Test case name %s: Expected drive %s, got %s", tt.name, tt.expectedDriveName, selectedDrive.Name)
+			}
+		})
+	}
+}
+
+func TestDriveSelectors(t1 *testing.T) {
+	createTestDrive := func(name, node string, freeCapacity int64) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:     node,
+				FreeCapacity: freeCapacity,
+			},
+		}
+	}
+	testDrives := []directcsi.DirectCSIDrive{
+		createTestDrive("drive1", "node1", 1000),
+		createTestDrive("drive2", "node1", 3000),
+		createTestDrive("drive3", "node1", 2000),
+	}
+	testMultiNodeDrives := []directcsi.DirectCSIDrive{
+		createTestDrive("drive1", "node2", 1000),
+		createTestDrive("drive2", "node1", 1000),
+		createTestDrive("drive3", "node2", 1000),
+		createTestDrive("drive4", "node1", 1000),
+	}
+
+	testCases := []struct {
+		name               string
+		strategy           ProvisioningStrategy
+		driveList          []directcsi.DirectCSIDrive
+		expectedDriveNames []string
+		expectErr          bool
+	}{
+		{
+			name:               "test1",
+			strategy:           ProvisioningStrategyMostFree,
+			driveList:          testDrives,
+			expectedDriveNames: []string{"drive2", "drive2", "drive2"},
+		},
+		{
+			name:               "test2",
+			strategy:           ProvisioningStrategyLeastFree,
+			driveList:          testDrives,
+			expectedDriveNames: []string{"drive1", "drive1", "drive1"},
+		},
+		{
+			name:               "test3",
+			strategy:           ProvisioningStrategyRoundRobin,
+			driveList:          testDrives,
+			expectedDriveNames: []string{"drive1", "drive2", "drive3", "drive1"},
+		},
+		{
+			name:               "test4",
+			strategy:           ProvisioningStrategyRoundRobin,
+			driveList:          testMultiNodeDrives,
+			expectedDriveNames: []string{"drive2", "drive1", "drive4", "drive3", "drive2"},
+		},
+		{
+			name:      "test5",
+			strategy:  ProvisioningStrategy("random"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			selector, err := NewDriveSelector(tt.strategy)
+			if tt.expectErr {
+				if err == nil {
+					t1.Errorf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			for i, expectedDriveName := range tt.expectedDriveNames {
+				driveList := make([]directcsi.DirectCSIDrive, len(tt.driveList))
+				copy(driveList, tt.driveList)
+				selectedDrive, err := selector.SelectDrive(driveList)
+				if err != nil {
+					t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+				}
+				if selectedDrive.Name != expectedDriveName {
+					t1.Errorf("Test case name %s: selection %d: expected drive %s but got %s", tt.name, i, expectedDriveName, selectedDrive.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestDriveSelectorsNoDrives(t1 *testing.T) {
+	for _, strategy := range []ProvisioningStrategy{ProvisioningStrategyMostFree, ProvisioningStrategyLeastFree, ProvisioningStrategyRoundRobin} {
+		t1.Run(string(strategy), func(t1 *testing.T) {
+			selector, err := NewDriveSelector(strategy)
+			if err != nil {
+				t1.Fatalf("unexpected error %v", err)
+			}
+			_, err = selector.SelectDrive([]directcsi.DirectCSIDrive{})
+			if status.Code(err) != codes.ResourceExhausted {
+				t1.Errorf("expected error code %v but got %v", codes.ResourceExhausted, err)
+			}
+		})
+	}
+}
+
+func TestGetDriveSelector(t1 *testing.T) {
+	cl := createFakeController()
+	cl.ProvisioningStrategy = ProvisioningStrategyRoundRobin
+
+	selector, err := cl.getDriveSelector(map[string]string{})
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := selector.(*roundRobinSelector); !ok {
+		t1.Errorf("expected the controller strategy to be used, got %T", selector)
+	}
+	if sameSelector, _ := cl.getDriveSelector(map[string]string{}); sameSelector != selector {
+		t1.Errorf("expected the selector to be reused across the requests")
+	}
+
+	selector, err = cl.getDriveSelector(map[string]string{provisioningStrategyParameter: string(ProvisioningStrategyLeastFree)})
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := selector.(*leastFreeSelector); !ok {
+		t1.Errorf("expected the storage class strategy to be used, got %T", selector)
+	}
+
+	if _, err := cl.getDriveSelector(map[string]string{provisioningStrategyParameter: "random"}); err == nil {
+		t1.Errorf("expected error for an unknown strategy")
+	}
+}
+
+func TestFilterDrivesByClaim(t1 *testing.T) {
+	newDrive := func(name, node string, driveStatus directcsi.DriveStatus, accessTier directcsi.AccessTier) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:    node,
+				DriveStatus: driveStatus,
+				AccessTier:  accessTier,
+				Topology:    map[string]string{"direct.csi.min.io/node": node},
+			},
+		}
+	}
+	csiDrives := []directcsi.DirectCSIDrive{
+		newDrive("drive1", "N1", directcsi.DriveStatusReady, directcsi.AccessTierHot),
+		newDrive("drive2", "N1", directcsi.DriveStatusInUse, directcsi.AccessTierUnknown),
+		newDrive("drive3", "N2", directcsi.DriveStatusReady, directcsi.AccessTierUnknown),
+		newDrive("drive4", "N2", directcsi.DriveStatusAvailable, directcsi.AccessTierUnknown),
+	}
+
+	testCases := []struct {
+		name           string
+		pvc            corev1.PersistentVolumeClaim
+		sc             storagev1.StorageClass
+		expectedDrives []string
+	}{
+		{
+			name:           "test1",
+			expectedDrives: []string{"drive1", "drive2", "drive3"},
+		},
+		{
+			name:           "test2",
+			sc:             storagev1.StorageClass{Parameters: map[string]string{"direct-csi-min-io/access-tier": "Hot"}},
+			expectedDrives: []string{"drive1"},
+		},
+		{
+			name: "test3",
+			pvc: corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{selectedNodeAnnotation: "N2"}},
+			},
+			expectedDrives: []string{"drive3"},
+		},
+		{
+			name: "test4",
+			sc: storagev1.StorageClass{
+				AllowedTopologies: []corev1.TopologySelectorTerm{
+					{
+						MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{
+							{Key: "direct.csi.min.io/node", Values: []string{"N1"}},
+						},
+					},
+				},
+			},
+			expectedDrives: []string{"drive1", "drive2"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			filteredDrives, err := FilterDrivesByClaim(tt.pvc, tt.sc, csiDrives)
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			driveNames := []string{}
+			for _, drive := range filteredDrives {
+				driveNames = append(driveNames, drive.Name)
+			}
+			if !reflect.DeepEqual(driveNames, tt.expectedDrives) {
+				t1.Errorf("Test case name %s: expected drives %v but got %v", tt.name, tt.expectedDrives, driveNames)
+			}
+		})
+	}
+}
+
+func TestValidateClaimCapacity(t1 *testing.T) {
+	newClaim := func(size string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		}
+	}
+	csiDrives := []directcsi.DirectCSIDrive{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "drive1"},
+			Status:     directcsi.DirectCSIDriveStatus{TotalCapacity: mb50, FreeCapacity: mb20},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "drive2"},
+			Status:     directcsi.DirectCSIDriveStatus{TotalCapacity: mb100, FreeCapacity: mb30},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		pvc             corev1.PersistentVolumeClaim
+		csiDrives       []directcsi.DirectCSIDrive
+		expectedAllowed bool
+	}{
+		{
+			name:            "test1",
+			pvc:             newClaim("100Mi"),
+			csiDrives:       csiDrives,
+			expectedAllowed: true,
+		},
+		{
+			name:            "test2",
+			pvc:             newClaim("101Mi"),
+			csiDrives:       csiDrives,
+			expectedAllowed: false,
+		},
+		{
+			name:            "test3",
+			pvc:             newClaim("1Ti"),
+			csiDrives:       []directcsi.DirectCSIDrive{},
+			expectedAllowed: true,
+		},
+		{
+			name:            "test4",
+			pvc:             corev1.PersistentVolumeClaim{},
+			csiDrives:       csiDrives,
+			expectedAllowed: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			admissionReview := admissionv1.AdmissionReview{
+				Response: &admissionv1.AdmissionResponse{Allowed: true},
+			}
+			allowed := validateClaimCapacity(tt.pvc, tt.csiDrives, &admissionReview)
+			if allowed != tt.expectedAllowed || admissionReview.Response.Allowed != tt.expectedAllowed {
+				t1.Errorf("Test case name %s: expected allowed = %v but got %v", tt.name, tt.expectedAllowed, allowed)
+			}
+			if !allowed && admissionReview.Response.Result == nil {
+				t1.Errorf("Test case name %s: expected the rejection reason to be set", tt.name)
+			}
+		})
+	}
+}
+
+func TestGetCapacity(t1 *testing.T) {
+	createTestDrive := func(name, node string, driveStatus directcsi.DriveStatus, accessTier directcsi.AccessTier, freeCapacity int64, reservedFor string) runtime.Object {
+		drive := &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:     node,
+				DriveStatus:  driveStatus,
+				AccessTier:   accessTier,
+				FreeCapacity: freeCapacity,
+				Topology:     map[string]string{"node": node},
+			},
+		}
+		if reservedFor != "" {
+			drive.Labels[utils.ReservedForLabel] = reservedFor
+		}
+		return drive
+	}
+
+	cl := createFakeController()
+	cl.directcsiClient = fakedirect.NewSimpleClientset(
+		createTestDrive("d1", "N1", directcsi.DriveStatusReady, directcsi.AccessTierHot, mb100, ""),
+		createTestDrive("d2", "N1", directcsi.DriveStatusInUse, directcsi.AccessTierCold, mb50, ""),
+		createTestDrive("d3", "N2", directcsi.DriveStatusReady, directcsi.AccessTierHot, mb20, ""),
+		createTestDrive("d4", "N1", directcsi.DriveStatusAvailable, directcsi.AccessTierHot, 10*mb100, ""),
+		createTestDrive("d5", "N2", directcsi.DriveStatusReady, directcsi.AccessTierHot, mb30, "tenant1"),
+	)
+
+	testCases := []struct {
+		name                  string
+		req                   *csi.GetCapacityRequest
+		expectedCapacity      int64
+		expectedMaxVolumeSize int64
+		expectedErrorCode     codes.Code
+	}{
+		{
+			name:                  "test1",
+			req:                   &csi.GetCapacityRequest{},
+			expectedCapacity:      mb100 + mb50 + mb20,
+			expectedMaxVolumeSize: mb100,
+		},
+		{
+			name: "test2",
+			req: &csi.GetCapacityRequest{
+				AccessibleTopology: &csi.Topology{Segments: map[string]string{"node": "N1"}},
+			},
+			expectedCapacity:      mb100 + mb50,
+			expectedMaxVolumeSize: mb100,
+		},
+		{
+			name: "test3",
+			req: &csi.GetCapacityRequest{
+				Parameters: map[string]string{"direct-csi-min-io/access-tier": "hot"},
+			},
+			expectedCapacity:      mb100 + mb20,
+			expectedMaxVolumeSize: mb100,
+		},
+		{
+			name: "test4",
+			req: &csi.GetCapacityRequest{
+				Parameters:         map[string]string{"direct-csi-min-io/tenant": "tenant1"},
+				AccessibleTopology: &csi.Topology{Segments: map[string]string{"node": "N2"}},
+			},
+			expectedCapacity:      mb20 + mb30,
+			expectedMaxVolumeSize: mb30,
+		},
+		{
+			name: "test5",
+			req: &csi.GetCapacityRequest{
+				AccessibleTopology: &csi.Topology{Segments: map[string]string{"node": "N3"}},
+			},
+			expectedCapacity: 0,
+		},
+		{
+			name: "test6",
+			req: &csi.GetCapacityRequest{
+				Parameters: map[string]string{"direct-csi-min-io/access-tier": "invalid"},
+			},
+			expectedErrorCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			res, err := cl.GetCapacity(context.TODO(), tt.req)
+			if tt.expectedErrorCode != codes.OK {
+				if status.Code(err) != tt.expectedErrorCode {
+					t1.Errorf("Test case name %s: expected error code %v but got %v", tt.name, tt.expectedErrorCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if res.GetAvailableCapacity() != tt.expectedCapacity {
+				t1.Errorf("Test case name %s: expected capacity %d but got %d", tt.name, tt.expectedCapacity, res.GetAvailableCapacity())
+			}
+			if res.GetMaximumVolumeSize().GetValue() != tt.expectedMaxVolumeSize {
+				t1.Errorf("Test case name %s: expected maximum volume size %d but got %d", tt.name, tt.expectedMaxVolumeSize, res.GetMaximumVolumeSize().GetValue())
+			}
+		})
+	}
+}
+
+func TestValidateVolumeCapabilities(t1 *testing.T) {
+	mountCap := func(fsType string, mode csi.VolumeCapability_AccessMode_Mode) *csi.VolumeCapability {
+		return &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{FsType: fsType},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: mode},
+		}
+	}
+	blockCap := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Block{
+			Block: &csi.VolumeCapability_BlockVolume{},
+		},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+	}
+
+	testVol := &directcsi.DirectCSIVolume{
+		TypeMeta:   utils.DirectCSIVolumeTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{Name: "volume-1"},
+	}
+
+	testCases := []struct {
+		name            string
+		volumeID        string
+		vcaps           []*csi.VolumeCapability
+		expectConfirmed bool
+		expectedCode    codes.Code
+	}{
+		{
+			name:            "singleNodeWriterXFS",
+			volumeID:        "volume-1",
+			vcaps:           []*csi.VolumeCapability{mountCap("xfs", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER)},
+			expectConfirmed: true,
+		},
+		{
+			name:            "singleNodeWriterDefaultFS",
+			volumeID:        "volume-1",
+			vcaps:           []*csi.VolumeCapability{mountCap("", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER)},
+			expectConfirmed: true,
+		},
+		{
+			name:     "block",
+			volumeID: "volume-1",
+			vcaps:    []*csi.VolumeCapability{blockCap},
+		},
+		{
+			name:     "ext4",
+			volumeID: "volume-1",
+			vcaps:    []*csi.VolumeCapability{mountCap("ext4", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER)},
+		},
+		{
+			name:     "multiNodeMultiWriter",
+			volumeID: "volume-1",
+			vcaps:    []*csi.VolumeCapability{mountCap("xfs", csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER)},
+		},
+		{
+			name:     "multiNodeReaderOnly",
+			volumeID: "volume-1",
+			vcaps: []*csi.VolumeCapability{
+				mountCap("xfs", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER),
+				mountCap("xfs", csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY),
+			},
+		},
+		{
+			name:         "missingVolumeID",
+			vcaps:        []*csi.VolumeCapability{mountCap("xfs", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER)},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "missingCapabilities",
+			volumeID:     "volume-1",
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "unknownVolume",
+			volumeID:     "volume-2",
+			vcaps:        []*csi.VolumeCapability{mountCap("xfs", csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER)},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	ctx := context.TODO()
+	cl := createFakeController()
+	cl.directcsiClient = fakedirect.NewSimpleClientset(testVol)
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			req := &csi.ValidateVolumeCapabilitiesRequest{
+				VolumeId:           tt.volumeID,
+				VolumeCapabilities: tt.vcaps,
+				VolumeContext:      map[string]string{"key": "value"},
+			}
+			res, err := cl.ValidateVolumeCapabilities(ctx, req)
+			if tt.expectedCode != codes.OK {
+				if status.Code(err) != tt.expectedCode {
+					t1.Fatalf("expected error code %v, got: %v", tt.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.expectConfirmed {
+				if res.GetConfirmed() != nil || res.GetMessage() == "" {
+					t1.Errorf("expected the capabilities to be rejected with a message, got: %v", res)
+				}
+				return
+			}
+			if !reflect.DeepEqual(res.GetConfirmed().GetVolumeCapabilities(), tt.vcaps) {
+				t1.Errorf("expected the confirmed capabilities %v, got: %v", tt.vcaps, res.GetConfirmed().GetVolumeCapabilities())
+			}
+			if !reflect.DeepEqual(res.GetConfirmed().GetVolumeContext(), req.GetVolumeContext()) {
+				t1.Errorf("expected the confirmed volume context %v, got: %v", req.GetVolumeContext(), res.GetConfirmed().GetVolumeContext())
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"k8s.io/klog/v2"
+)
+
+// DefaultDriveFinalizerGracePeriod is the duration after which the finalizers of a deleted
+// drive are removed if its node is gone or not ready
+const DefaultDriveFinalizerGracePeriod = time.Hour
+
+// orphanedDriveCheckInterval is how often the deleted drives are matched against the lost nodes,
+// it only delays the release by up to a minute past the grace period
+const orphanedDriveCheckInterval = time.Minute
+
+// isNodeLost checks if the node is deleted, or not ready for longer than the grace period
+func isNodeLost(node *corev1.Node, now time.Time, gracePeriod time.Duration) bool {
+	if node == nil {
+		return true
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status != corev1.ConditionTrue && now.Sub(condition.LastTransitionTime.Time) > gracePeriod
+		}
+	}
+	return false
+}
+
+// isDriveOrphaned checks if the drive is deleted for longer than the grace period while its node is lost,
+// its finalizers are never removed by the node then. A nil node is a deleted node.
+func isDriveOrphaned(drive *directcsi.DirectCSIDrive, node *corev1.Node, now time.Time, gracePeriod time.Duration) bool {
+	if drive.DeletionTimestamp == nil || now.Sub(drive.DeletionTimestamp.Time) <= gracePeriod {
+		return false
+	}
+	return isNodeLost(node, now, gracePeriod)
+}
+
+// removeDirectCSIFinalizers returns the finalizers of the drive without the ones of direct-csi
+func removeDirectCSIFinalizers(drive *directcsi.DirectCSIDrive) (finalizers, removed []string) {
+	for _, finalizer := range drive.GetFinalizers() {
+		if strings.HasPrefix(finalizer, directcsi.Group) {
+			removed = append(removed, finalizer)
+			continue
+		}
+		finalizers = append(finalizers, finalizer)
+	}
+	return finalizers, removed
+}
+
+// releaseOrphanedDrives removes the direct-csi finalizers of the orphaned drives so that they are garbage collected
+func releaseOrphanedDrives(ctx context.Context, gracePeriod time.Duration) error {
+	directCSIClient := utils.GetDirectCSIClient()
+	driveList, err := directCSIClient.DirectCSIDrives().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	nodes := map[string]*corev1.Node{}
+	for i := range driveList.Items {
+		drive := &driveList.Items[i]
+		if drive.DeletionTimestamp == nil || len(drive.GetFinalizers()) == 0 {
+			continue
+		}
+		node, found := nodes[drive.Status.NodeName]
+		if !found {
+			node, err = utils.GetKubeClient().CoreV1().Nodes().Get(ctx, drive.Status.NodeName, metav1.GetOptions{})
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					return err
+				}
+				node = nil
+			}
+			nodes[drive.Status.NodeName] = node
+		}
+		if !isDriveOrphaned(drive, node, now, gracePeriod) {
+			continue
+		}
+
+		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			latest, err := directCSIClient.DirectCSIDrives().Get(ctx, drive.Name, metav1.GetOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			})
+			if err != nil {
+				return err
+			}
+			finalizers, removed := removeDirectCSIFinalizers(latest)
+			if len(removed) == 0 {
+				return nil
+			}
+			latest.SetFinalizers(finalizers)
+			if _, err := directCSIClient.DirectCSIDrives().Update(ctx, latest, metav1.UpdateOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			}); err != nil {
+				return err
+			}
+			klog.Warningf("Removed the finalizers %v of drive %s deleted at %v, its node %s is lost",
+				removed, drive.Name, drive.DeletionTimestamp.Time, drive.Status.NodeName)
+			return nil
+		}); err != nil && !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to remove the finalizers of drive %s: %v", drive.Name, err)
+		}
+	}
+	return nil
+}
+
+// runOrphanedDriveReconciler periodically releases the orphaned drives until ctx is done, a zero grace period disables it
+func runOrphanedDriveReconciler(ctx context.Context, gracePeriod time.Duration) {
+	if gracePeriod <= 0 {
+		return
+	}
+	ticker := time.NewTicker(orphanedDriveCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := releaseOrphanedDrives(ctx, gracePeriod); err != nil {
+				klog.Errorf("Failed to check the deleted drives: %v", err)
+			}
+		}
+	}
+}
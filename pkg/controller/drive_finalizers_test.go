@@ -0,0 +1,95 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDriveOrphaned(t *testing.T) {
+	now := time.Now()
+	gracePeriod := time.Hour
+	newDrive := func(deletedAt *time.Time) *directcsi.DirectCSIDrive {
+		drive := &directcsi.DirectCSIDrive{}
+		if deletedAt != nil {
+			drive.DeletionTimestamp = &metav1.Time{Time: *deletedAt}
+		}
+		return drive
+	}
+	newNode := func(status corev1.ConditionStatus, since time.Time) *corev1.Node {
+		return &corev1.Node{
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             status,
+						LastTransitionTime: metav1.Time{Time: since},
+					},
+				},
+			},
+		}
+	}
+	longAgo := now.Add(-2 * gracePeriod)
+	recently := now.Add(-time.Minute)
+
+	testCases := []struct {
+		name     string
+		drive    *directcsi.DirectCSIDrive
+		node     *corev1.Node
+		expected bool
+	}{
+		{"not deleted", newDrive(nil), nil, false},
+		{"recently deleted on a deleted node", newDrive(&recently), nil, false},
+		{"deleted on a deleted node", newDrive(&longAgo), nil, true},
+		{"deleted on a ready node", newDrive(&longAgo), newNode(corev1.ConditionTrue, longAgo), false},
+		{"deleted on a recently not ready node", newDrive(&longAgo), newNode(corev1.ConditionFalse, recently), false},
+		{"deleted on a not ready node", newDrive(&longAgo), newNode(corev1.ConditionFalse, longAgo), true},
+		{"deleted on an unreachable node", newDrive(&longAgo), newNode(corev1.ConditionUnknown, longAgo), true},
+		{"deleted on a node without conditions", newDrive(&longAgo), &corev1.Node{}, false},
+	}
+	for _, testCase := range testCases {
+		if result := isDriveOrphaned(testCase.drive, testCase.node, now, gracePeriod); result != testCase.expected {
+			t.Errorf("case %s: expected: %v, got: %v", testCase.name, testCase.expected, result)
+		}
+	}
+}
+
+func TestRemoveDirectCSIFinalizers(t *testing.T) {
+	drive := &directcsi.DirectCSIDrive{
+		ObjectMeta: metav1.ObjectMeta{
+			Finalizers: []string{
+				directcsi.DirectCSIDriveFinalizerDataProtection,
+				directcsi.DirectCSIDriveFinalizerPrefix + "volume-1",
+				"example.com/protection",
+			},
+		},
+	}
+	finalizers, removed := removeDirectCSIFinalizers(drive)
+	if expected := []string{"example.com/protection"}; !reflect.DeepEqual(finalizers, expected) {
+		t.Errorf("expected finalizers: %v, got: %v", expected, finalizers)
+	}
+	if len(removed) != 2 {
+		t.Errorf("expected 2 removed finalizers, got: %v", removed)
+	}
+}
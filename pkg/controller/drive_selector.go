@@ -0,0 +1,126 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ProvisioningStrategy string
+
+// errNoDriveToSelect is returned by the drive selectors if no drive satisfies the request
+var errNoDriveToSelect = status.Error(codes.ResourceExhausted, "No csi drive satisfies the request")
+
+const (
+	// ProvisioningStrategyMostFree picks the drive with the most free capacity
+	ProvisioningStrategyMostFree ProvisioningStrategy = "most-free"
+	// ProvisioningStrategyLeastFree picks the drive with the least free capacity (bin-packing)
+	ProvisioningStrategyLeastFree ProvisioningStrategy = "least-free"
+	// ProvisioningStrategyRoundRobin cycles through the drives of each node
+	ProvisioningStrategyRoundRobin ProvisioningStrategy = "round-robin"
+)
+
+// DriveSelector picks the drive for a volume among the drives satisfying the request
+type DriveSelector interface {
+	SelectDrive(csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error)
+}
+
+// NewDriveSelector returns the drive selector of the provisioning strategy
+func NewDriveSelector(strategy ProvisioningStrategy) (DriveSelector, error) {
+	switch strategy {
+	case ProvisioningStrategyMostFree:
+		return &mostFreeSelector{}, nil
+	case ProvisioningStrategyLeastFree:
+		return &leastFreeSelector{}, nil
+	case ProvisioningStrategyRoundRobin:
+		return &roundRobinSelector{lastUsedDrives: map[string]string{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown provisioning strategy %q. should be one of [%s, %s, %s]", strategy, ProvisioningStrategyMostFree, ProvisioningStrategyLeastFree, ProvisioningStrategyRoundRobin)
+	}
+}
+
+type mostFreeSelector struct{}
+
+func (s *mostFreeSelector) SelectDrive(csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error) {
+	return selectDriveByFreeCapacity(csiDrives)
+}
+
+type leastFreeSelector struct{}
+
+func (s *leastFreeSelector) SelectDrive(csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error) {
+	return selectDriveByLeastFreeCapacity(csiDrives)
+}
+
+// roundRobinSelector cycles through the nodes and, within a node, through its drives
+type roundRobinSelector struct {
+	mutex sync.Mutex
+	// last used drive per node
+	lastUsedDrives map[string]string
+	lastUsedNode   string
+}
+
+func (s *roundRobinSelector) SelectDrive(csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error) {
+	if len(csiDrives) == 0 {
+		return directcsi.DirectCSIDrive{}, errNoDriveToSelect
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	drivesByNode := map[string][]directcsi.DirectCSIDrive{}
+	nodes := []string{}
+	for _, csiDrive := range csiDrives {
+		node := csiDrive.Status.NodeName
+		if _, ok := drivesByNode[node]; !ok {
+			nodes = append(nodes, node)
+		}
+		drivesByNode[node] = append(drivesByNode[node], csiDrive)
+	}
+	sort.Strings(nodes)
+
+	node := nodes[nextIndex(nodes, s.lastUsedNode)]
+	nodeDrives := drivesByNode[node]
+	sort.SliceStable(nodeDrives, func(i, j int) bool {
+		return nodeDrives[i].Name < nodeDrives[j].Name
+	})
+	driveNames := []string{}
+	for _, nodeDrive := range nodeDrives {
+		driveNames = append(driveNames, nodeDrive.Name)
+	}
+	selectedDrive := nodeDrives[nextIndex(driveNames, s.lastUsedDrives[node])]
+
+	s.lastUsedNode = node
+	s.lastUsedDrives[node] = selectedDrive.Name
+	return selectedDrive, nil
+}
+
+// nextIndex returns the index of the first sorted value after the last used one, wrapping around
+func nextIndex(sortedValues []string, lastUsed string) int {
+	for i, value := range sortedValues {
+		if value > lastUsed {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,213 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	humanize "github.com/dustin/go-humanize"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	selectedNodeAnnotation        = "volume.kubernetes.io/selected-node"
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+)
+
+// getClaimStorageClass returns the storage class of the claim, nil if the claim does not use one
+func getClaimStorageClass(ctx context.Context, pvc corev1.PersistentVolumeClaim) (*storagev1.StorageClass, error) {
+	scClient := utils.GetKubeClient().StorageV1().StorageClasses()
+	if pvc.Spec.StorageClassName != nil {
+		if *pvc.Spec.StorageClassName == "" {
+			return nil, nil
+		}
+		return scClient.Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+	}
+
+	// claims without a storage class name are assigned the default storage class
+	scList, err := scClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range scList.Items {
+		if scList.Items[i].Annotations[defaultStorageClassAnnotation] == "true" {
+			return &scList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// matchAllowedTopologies checks if the drive topology satisfies any of the allowed topology terms
+func matchAllowedTopologies(terms []corev1.TopologySelectorTerm, driveTopology map[string]string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	matchTerm := func(term corev1.TopologySelectorTerm) bool {
+		for _, expression := range term.MatchLabelExpressions {
+			value, ok := driveTopology[expression.Key]
+			if !ok {
+				return false
+			}
+			found := false
+			for _, v := range expression.Values {
+				if v == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	for _, term := range terms {
+		if matchTerm(term) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterDrivesByClaim - Filters the ready CSI drives which could hold a volume of the claim
+func FilterDrivesByClaim(pvc corev1.PersistentVolumeClaim, sc storagev1.StorageClass, csiDrives []directcsi.DirectCSIDrive) ([]directcsi.DirectCSIDrive, error) {
+	reservedPercent, err := utils.ParseReservedPercent(sc.Parameters[utils.ReservedPercentParameter])
+	if err != nil {
+		return nil, err
+	}
+	filteredDrives, err := FilterDrivesByParameters(sc.Parameters, FilterDrivesBySchedulable(FilterDrivesByRequestFormat(csiDrives)))
+	if err != nil {
+		return nil, err
+	}
+	filteredDrives = ReserveDrivesHeadroom(reservedPercent, filteredDrives)
+	filteredDrives = FilterDrivesByTenant(sc.Parameters[tenantParameter], filteredDrives)
+
+	selectedNode := pvc.Annotations[selectedNodeAnnotation]
+	filteredDriveList := []directcsi.DirectCSIDrive{}
+	for _, csiDrive := range filteredDrives {
+		if selectedNode != "" && csiDrive.Status.NodeName != selectedNode {
+			continue
+		}
+		if !matchAllowedTopologies(sc.AllowedTopologies, csiDrive.Status.Topology) {
+			continue
+		}
+		filteredDriveList = append(filteredDriveList, csiDrive)
+	}
+	return filteredDriveList, nil
+}
+
+// validateClaimCapacity rejects the claim if its requested size exceeds the capacity of every matching drive,
+// as a volume is never split across drives and the claim would otherwise stay pending forever
+func validateClaimCapacity(pvc corev1.PersistentVolumeClaim, csiDrives []directcsi.DirectCSIDrive, admissionReview *admissionv1.AdmissionReview) bool {
+	requested, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return true
+	}
+
+	// nothing can be told about the claim until matching drives are added
+	if len(csiDrives) == 0 {
+		return true
+	}
+
+	// the headroom reserved on a drive is never allocated to volumes
+	usableCapacity := func(csiDrive directcsi.DirectCSIDrive) int64 {
+		return csiDrive.Status.TotalCapacity - csiDrive.Status.ReservedCapacity
+	}
+	largestDrive := csiDrives[0]
+	for _, csiDrive := range csiDrives[1:] {
+		if usableCapacity(csiDrive) > usableCapacity(largestDrive) {
+			largestDrive = csiDrive
+		}
+	}
+	if requested.Value() <= usableCapacity(largestDrive) {
+		return true
+	}
+
+	admissionReview.Response.Allowed = false
+	admissionReview.Response.Result = &metav1.Status{
+		Status: FailureStatus,
+		Message: fmt.Sprintf("Requested size %s exceeds the capacity %s of the largest matching drive %s; volumes cannot span multiple drives",
+			requested.String(),
+			humanize.IBytes(uint64(usableCapacity(largestDrive))),
+			largestDrive.Name),
+	}
+	return false
+}
+
+// validatePVC rejects claims provisioned by direct-csi whose requested size does not fit into any matching drive
+func (vh *ValidationHandler) validatePVC(w http.ResponseWriter, r *http.Request) {
+
+	admissionReview, err := parseAdmissionReview(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to parse the body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	pvc := corev1.PersistentVolumeClaim{}
+	if err := json.Unmarshal(admissionReview.Request.Object.Raw, &pvc); err != nil {
+		http.Error(w, fmt.Sprintf("could not parse persistent volume claim: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	admissionReview.Response = &admissionv1.AdmissionResponse{
+		UID:     admissionReview.Request.UID,
+		Allowed: true,
+	}
+
+	csiDrives, err := vh.getClaimDrives(r.Context(), pvc)
+	if err != nil {
+		// the claim is let through as the provisioner reports the failure anyway
+		klog.Errorf("unable to validate the capacity of claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		writeSuccessResponse(admissionReview, w)
+		return
+	}
+	if csiDrives != nil && !validateClaimCapacity(pvc, csiDrives, &admissionReview) {
+		klog.V(3).Infof("rejected claim %s/%s: %s", pvc.Namespace, pvc.Name, admissionReview.Response.Result.Message)
+	}
+
+	writeSuccessResponse(admissionReview, w)
+}
+
+// getClaimDrives returns the drives matching the claim, nil if the claim is not provisioned by direct-csi
+func (vh *ValidationHandler) getClaimDrives(ctx context.Context, pvc corev1.PersistentVolumeClaim) ([]directcsi.DirectCSIDrive, error) {
+	sc, err := getClaimStorageClass(ctx, pvc)
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil || sc.Provisioner != vh.identity {
+		return nil, nil
+	}
+
+	driveList, err := utils.GetDirectCSIClient().DirectCSIDrives().List(ctx, metav1.ListOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FilterDrivesByClaim(pvc, *sc, driveList.Items)
+}
@@ -18,17 +18,50 @@ package controller
 
 import (
 	"crypto/rand"
+	"fmt"
 	"math/big"
 	"sort"
+	"strings"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	tenantParameter               = "direct-csi-min-io/tenant"
+	provisioningStrategyParameter = "direct-csi-min-io/provisioning-strategy"
+	// set by the external-provisioner with --extra-create-metadata
+	pvcNameParameter      = "csi.storage.k8s.io/pvc/name"
+	pvcNamespaceParameter = "csi.storage.k8s.io/pvc/namespace"
 )
 
+// validateVolumeCapability fails for the capabilities the node local xfs volumes
+// cannot provide, i.e. raw block access, other filesystems and multi-node access
+func validateVolumeCapability(vcap *csi.VolumeCapability) error {
+	if vcap.GetBlock() != nil {
+		return fmt.Errorf("unsupported access type: block")
+	}
+	if mount := vcap.GetMount(); mount != nil {
+		if fsType := mount.GetFsType(); fsType != "" && !strings.EqualFold(fsType, string(sys.FSTypeXFS)) {
+			return fmt.Errorf("unsupported filesystem type: %s", fsType)
+		}
+	}
+	if access := vcap.GetAccessMode(); access != nil {
+		if mode := access.GetMode(); mode != csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER {
+			return fmt.Errorf("unsupported access mode: %s", mode)
+		}
+	}
+	return nil
+}
+
 // FilterDrivesByVolumeRequest - Filters the CSI drives by create volume request
 func FilterDrivesByVolumeRequest(volReq *csi.CreateVolumeRequest, csiDrives []directcsi.DirectCSIDrive) ([]directcsi.DirectCSIDrive, error) {
 	capacityRange := volReq.GetCapacityRange()
@@ -43,6 +76,17 @@ func FilterDrivesByVolumeRequest(volReq *csi.CreateVolumeRequest, csiDrives []di
 		return []directcsi.DirectCSIDrive{}, status.Error(codes.FailedPrecondition, "No csi drives are been added. Please use `add drives` plugin command to add the drives")
 	}
 
+	filteredDrivesByFormat = FilterDrivesBySchedulable(filteredDrivesByFormat)
+	if len(filteredDrivesByFormat) == 0 {
+		return []directcsi.DirectCSIDrive{}, status.Error(codes.ResourceExhausted, "All the csi drives are cordoned")
+	}
+
+	reservedPercent, err := utils.ParseReservedPercent(volReq.GetParameters()[utils.ReservedPercentParameter])
+	if err != nil {
+		return []directcsi.DirectCSIDrive{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	filteredDrivesByFormat = ReserveDrivesHeadroom(reservedPercent, filteredDrivesByFormat)
+
 	capFilteredDrives := FilterDrivesByCapacityRange(capacityRange, filteredDrivesByFormat)
 	if len(capFilteredDrives) == 0 {
 		return []directcsi.DirectCSIDrive{}, status.Error(codes.OutOfRange, "Invalid capacity range")
@@ -61,7 +105,32 @@ func FilterDrivesByVolumeRequest(volReq *csi.CreateVolumeRequest, csiDrives []di
 		return []directcsi.DirectCSIDrive{}, status.Errorf(codes.InvalidArgument, "Cannot match any drives by the provided storage class parameters: %s", volReq.GetParameters())
 	}
 
-	return paramFilteredDrives, nil
+	tenant := volReq.GetParameters()[tenantParameter]
+	tenantFilteredDrives := FilterDrivesByTenant(tenant, paramFilteredDrives)
+	if len(tenantFilteredDrives) == 0 {
+		return []directcsi.DirectCSIDrive{}, status.Errorf(codes.InvalidArgument, "Cannot find any drives which are not reserved for other tenants. tenant: %q", tenant)
+	}
+
+	return tenantFilteredDrives, nil
+}
+
+// FilterDrivesByCapacityRequest - Selects the ready drives matching the topology and the parameters in the get capacity request
+func FilterDrivesByCapacityRequest(capReq *csi.GetCapacityRequest, csiDrives []directcsi.DirectCSIDrive) ([]directcsi.DirectCSIDrive, error) {
+	reservedPercent, err := utils.ParseReservedPercent(capReq.GetParameters()[utils.ReservedPercentParameter])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	filteredDrives, err := FilterDrivesByParameters(capReq.GetParameters(), FilterDrivesBySchedulable(FilterDrivesByRequestFormat(csiDrives)))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Error while filtering based on sc parameters: %v", err)
+	}
+	filteredDrives = ReserveDrivesHeadroom(reservedPercent, filteredDrives)
+	filteredDrives = FilterDrivesByTenant(capReq.GetParameters()[tenantParameter], filteredDrives)
+	if top := capReq.GetAccessibleTopology(); top != nil {
+		// no drives satisfy the topology if the error is set
+		filteredDrives, _ = selectDrivesByTopology(top, filteredDrives)
+	}
+	return filteredDrives, nil
 }
 
 // FilterDrivesByCapacityRange - Filters the CSI drives by capacity range in the create volume request
@@ -131,8 +200,57 @@ func FilterDrivesByAccessTier(accessTier directcsi.AccessTier, csiDrives []direc
 	return filteredDriveList
 }
 
-// FilterDrivesByTopologyRequirements - selects the CSI drive by topology in the create volume request
-func FilterDrivesByTopologyRequirements(volReq *csi.CreateVolumeRequest, csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error) {
+// FilterDrivesByTenant - Filters out the CSI drives reserved for tenants other than the given tenant
+func FilterDrivesByTenant(tenant string, csiDrives []directcsi.DirectCSIDrive) []directcsi.DirectCSIDrive {
+	filteredDriveList := []directcsi.DirectCSIDrive{}
+	for _, csiDrive := range csiDrives {
+		reservedFor := csiDrive.GetLabels()[utils.ReservedForLabel]
+		if reservedFor == "" || (tenant != "" && reservedFor == utils.SanitizeLabelV(tenant)) {
+			filteredDriveList = append(filteredDriveList, csiDrive)
+		}
+	}
+	return filteredDriveList
+}
+
+// FilterDrivesBySchedulable - Filters out the CSI drives cordoned by the admin, their existing volumes are not affected
+func FilterDrivesBySchedulable(csiDrives []directcsi.DirectCSIDrive) []directcsi.DirectCSIDrive {
+	filteredDriveList := []directcsi.DirectCSIDrive{}
+	for _, csiDrive := range csiDrives {
+		if csiDrive.GetLabels()[utils.UnschedulableLabel] != "true" {
+			filteredDriveList = append(filteredDriveList, csiDrive)
+		}
+	}
+	return filteredDriveList
+}
+
+// schedulableCapacity returns the free capacity of the drive left to the volumes of a storage class keeping
+// the reserved percentage of the drive free, in addition to the headroom reserved on the drive at format
+func schedulableCapacity(csiDrive *directcsi.DirectCSIDrive, reservedPercent int64) int64 {
+	free := csiDrive.Status.FreeCapacity
+	if reserved := utils.ReservedCapacity(csiDrive.Status.TotalCapacity, reservedPercent); reserved > csiDrive.Status.ReservedCapacity {
+		free -= reserved - csiDrive.Status.ReservedCapacity
+	}
+	if free < 0 {
+		return 0
+	}
+	return free
+}
+
+// ReserveDrivesHeadroom - Returns copies of the CSI drives whose free capacity is reduced to the capacity schedulable
+// for the reserved percentage of the storage class. The copies are only used to filter the drives and must not be
+// written back, the headroom of the storage class is not recorded on the drives
+func ReserveDrivesHeadroom(reservedPercent int64, csiDrives []directcsi.DirectCSIDrive) []directcsi.DirectCSIDrive {
+	reservedDriveList := []directcsi.DirectCSIDrive{}
+	for _, csiDrive := range csiDrives {
+		csiDrive.Status.FreeCapacity = schedulableCapacity(&csiDrive, reservedPercent)
+		reservedDriveList = append(reservedDriveList, csiDrive)
+	}
+	return reservedDriveList
+}
+
+// FilterDrivesByTopologyRequirements - selects the CSI drive by topology in the create volume request,
+// the selector picks the drive among the drives satisfying the topology
+func FilterDrivesByTopologyRequirements(volReq *csi.CreateVolumeRequest, csiDrives []directcsi.DirectCSIDrive, selector DriveSelector) (directcsi.DirectCSIDrive, error) {
 	tReq := volReq.GetAccessibilityRequirements()
 
 	preferredXs := tReq.GetPreferred()
@@ -142,18 +260,35 @@ func FilterDrivesByTopologyRequirements(volReq *csi.CreateVolumeRequest, csiDriv
 	// Ref: https://godoc.org/github.com/container-storage-interface/spec/lib/go/csi#TopologyRequirement
 	for _, preferredTop := range preferredXs {
 		if selectedDrives, err := selectDrivesByTopology(preferredTop, csiDrives); err == nil {
-			return selectDriveByFreeCapacity(selectedDrives)
+			// skip the preferred segments whose drives cannot fit the request anymore
+			if selectedDrives = FilterDrivesByCapacityRange(volReq.GetCapacityRange(), selectedDrives); len(selectedDrives) > 0 {
+				return selector.SelectDrive(selectedDrives)
+			}
 		}
 	}
 
+	// Consider the capacity across the whole requisite set instead of the first matching segment
+	requisiteDrives := []directcsi.DirectCSIDrive{}
+	requisiteDriveNames := map[string]struct{}{}
 	for _, requisiteTop := range requisiteXs {
-		if selectedDrives, err := selectDrivesByTopology(requisiteTop, csiDrives); err == nil {
-			return selectDriveByFreeCapacity(selectedDrives)
+		selectedDrives, err := selectDrivesByTopology(requisiteTop, csiDrives)
+		if err != nil {
+			continue
+		}
+		for _, selectedDrive := range selectedDrives {
+			if _, ok := requisiteDriveNames[selectedDrive.Name]; ok {
+				continue
+			}
+			requisiteDriveNames[selectedDrive.Name] = struct{}{}
+			requisiteDrives = append(requisiteDrives, selectedDrive)
 		}
 	}
+	if requisiteDrives = FilterDrivesByCapacityRange(volReq.GetCapacityRange(), requisiteDrives); len(requisiteDrives) > 0 {
+		return selector.SelectDrive(requisiteDrives)
+	}
 
 	if len(preferredXs) == 0 && len(requisiteXs) == 0 {
-		return selectDriveByFreeCapacity(csiDrives)
+		return selector.SelectDrive(csiDrives)
 	}
 
 	return directcsi.DirectCSIDrive{}, status.Error(codes.ResourceExhausted, "Cannot satisfy the topology constraint")
@@ -161,15 +296,32 @@ func FilterDrivesByTopologyRequirements(volReq *csi.CreateVolumeRequest, csiDriv
 
 func selectDriveByFreeCapacity(csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error) {
 	// Sort the drives by free capacity [Descending]
+	return selectDriveByCapacityOrder(csiDrives, func(c1, c2 int64) bool {
+		return c1 > c2
+	})
+}
+
+func selectDriveByLeastFreeCapacity(csiDrives []directcsi.DirectCSIDrive) (directcsi.DirectCSIDrive, error) {
+	// Sort the drives by free capacity [Ascending]
+	return selectDriveByCapacityOrder(csiDrives, func(c1, c2 int64) bool {
+		return c1 < c2
+	})
+}
+
+// selectDriveByCapacityOrder picks a random drive among the drives with the first free capacity in the given order
+func selectDriveByCapacityOrder(csiDrives []directcsi.DirectCSIDrive, less func(c1, c2 int64) bool) (directcsi.DirectCSIDrive, error) {
+	if len(csiDrives) == 0 {
+		return directcsi.DirectCSIDrive{}, errNoDriveToSelect
+	}
 	sort.SliceStable(csiDrives, func(i, j int) bool {
-		return csiDrives[i].Status.FreeCapacity > csiDrives[j].Status.FreeCapacity
+		return less(csiDrives[i].Status.FreeCapacity, csiDrives[j].Status.FreeCapacity)
 	})
 
 	groupByFreeCapacity := func() []directcsi.DirectCSIDrive {
-		maxFreeCapacity := csiDrives[0].Status.FreeCapacity
+		firstFreeCapacity := csiDrives[0].Status.FreeCapacity
 		groupedDrives := []directcsi.DirectCSIDrive{}
 		for _, csiDrive := range csiDrives {
-			if csiDrive.Status.FreeCapacity == maxFreeCapacity {
+			if csiDrive.Status.FreeCapacity == firstFreeCapacity {
 				groupedDrives = append(groupedDrives, csiDrive)
 			}
 		}
@@ -221,3 +373,67 @@ func matchSegments(topSegments, driveSegments map[string]string) bool {
 	}
 	return req == match
 }
+
+// getCSIVolume converts the volume to its CSI representation, the drive is nil if not found
+func getCSIVolume(volume *directcsi.DirectCSIVolume, drive *directcsi.DirectCSIDrive) *csi.Volume {
+	csiVolume := &csi.Volume{
+		VolumeId:      volume.Name,
+		CapacityBytes: volume.Status.TotalCapacity,
+	}
+	if drive != nil {
+		csiVolume.AccessibleTopology = []*csi.Topology{
+			{
+				Segments: drive.Status.Topology,
+			},
+		}
+	}
+	return csiVolume
+}
+
+// getPublishedNodeIDs returns the node of the volume if it is published
+func getPublishedNodeIDs(volume *directcsi.DirectCSIVolume) []string {
+	if volume.Status.NodeName == "" ||
+		!utils.IsConditionStatus(volume.Status.Conditions, string(directcsi.DirectCSIVolumeConditionPublished), metav1.ConditionTrue) {
+		return nil
+	}
+	return []string{volume.Status.NodeName}
+}
+
+// getVolumeCondition reports the volume as abnormal if its drive is missing or unhealthy,
+// or if the volume is staged but not ready
+func getVolumeCondition(volume *directcsi.DirectCSIVolume, drive *directcsi.DirectCSIDrive) *csi.VolumeCondition {
+	abnormal := func(format string, args ...interface{}) *csi.VolumeCondition {
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	if drive == nil {
+		return abnormal("drive %s of the volume is not found", volume.Status.Drive)
+	}
+	switch drive.Status.DriveStatus {
+	case directcsi.DriveStatusUnavailable, directcsi.DriveStatusTerminating, directcsi.DriveStatusReleased:
+		return abnormal("drive %s of the volume is %s", drive.Name, strings.ToLower(string(drive.Status.DriveStatus)))
+	}
+	if utils.IsConditionStatus(volume.Status.Conditions, string(directcsi.DirectCSIVolumeConditionStaged), metav1.ConditionTrue) &&
+		!utils.IsConditionStatus(volume.Status.Conditions, string(directcsi.DirectCSIVolumeConditionReady), metav1.ConditionTrue) {
+		return abnormal("volume is staged but not ready")
+	}
+
+	return &csi.VolumeCondition{
+		Abnormal: false,
+		Message:  "volume is healthy",
+	}
+}
+
+// getCSISnapshot converts the snapshot to its CSI representation
+func getCSISnapshot(snapshot *directcsi.DirectCSISnapshot) *csi.Snapshot {
+	return &csi.Snapshot{
+		SnapshotId:     snapshot.Name,
+		SourceVolumeId: snapshot.Status.SourceVolume,
+		SizeBytes:      snapshot.Status.SizeBytes,
+		CreationTime:   timestamppb.New(snapshot.CreationTimestamp.Time),
+		ReadyToUse:     snapshot.Status.ReadyToUse,
+	}
+}
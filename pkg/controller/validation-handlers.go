@@ -24,6 +24,7 @@ import (
 	"net/http"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -36,6 +37,8 @@ const (
 )
 
 type ValidationHandler struct {
+	// identity is the provisioner name of the storage classes served by the driver
+	identity string
 }
 
 func parseAdmissionReview(req *http.Request) (admissionv1.AdmissionReview, error) {
@@ -171,6 +174,37 @@ func validateRequestedFormat(directCSIDrive directcsi.DirectCSIDrive, admissionR
 		return false
 	}
 
+	// Label validation
+	// (*) Check if the label fits in the xfs superblock
+	if len(requestedFormat.Label) > xfs.MaxLabelLength {
+		admissionReview.Response.Allowed = false
+		admissionReview.Response.Result = &metav1.Status{
+			Status:  FailureStatus,
+			Message: fmt.Sprintf("Filesystem label must not exceed %d characters", xfs.MaxLabelLength),
+		}
+		return false
+	}
+
+	// mkfs validation
+	// (*) Check if the block size is supported by xfs
+	// (*) Allow only the allow-listed mkfs options
+	if err := xfs.ValidateBlockSize(requestedFormat.BlockSize); err != nil {
+		admissionReview.Response.Allowed = false
+		admissionReview.Response.Result = &metav1.Status{
+			Status:  FailureStatus,
+			Message: err.Error(),
+		}
+		return false
+	}
+	if err := xfs.ValidateMkfsOptions(requestedFormat.MkfsOptions); err != nil {
+		admissionReview.Response.Allowed = false
+		admissionReview.Response.Result = &metav1.Status{
+			Status:  FailureStatus,
+			Message: err.Error(),
+		}
+		return false
+	}
+
 	// All validations passed!
 	return true
 }
@@ -180,6 +214,8 @@ func validateRequestedFormat(directCSIDrive directcsi.DirectCSIDrive, admissionR
    - Check if directCSIOwned is not set to True or requestedFormat is set for root partitions (unavailable drives)
    - Check if requestedFormat is not set for a drive in-use
    - Check if force option is set if the drive has an existing filesystem or mountpoint
+   - Check if the requested filesystem label fits in the xfs superblock
+   - Check if the requested block size and mkfs options are supported
 */
 func (vh *ValidationHandler) validateDrive(w http.ResponseWriter, r *http.Request) {
 
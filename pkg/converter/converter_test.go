@@ -17,8 +17,10 @@
 package converter
 
 import (
+	stdjson "encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -352,3 +354,79 @@ request:
 		t.Errorf("expected status.partitionUUID = \"\", actual status.partitionUUID = %v", directCSIDrive.Status.PartitionUUID)
 	}
 }
+
+func TestVerifyConversion(t *testing.T) {
+	results := VerifyConversion()
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("%s %s -> %s: unexpected error: %v", result.Kind, result.FromVersion, result.ToVersion, result.Err)
+		}
+		if len(result.LostFields) > 0 {
+			t.Errorf("%s %s -> %s: lost fields %v", result.Kind, result.FromVersion, result.ToVersion, result.LostFields)
+		}
+		if result.ToVersion != versionV1Beta2 {
+			t.Errorf("expected toVersion = %s, actual toVersion = %s", versionV1Beta2, result.ToVersion)
+		}
+	}
+}
+
+func TestVerifyMigrationLostFields(t *testing.T) {
+	object, err := newSampleObject(DriveCRDKind, versionV1Beta1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := unstructured.SetNestedField(object.Object, "unknown", "status", "unknownField"); err != nil {
+		t.Fatal(err)
+	}
+
+	lostFields, err := VerifyMigration(object, versionV1Beta2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lostFields) != 1 || lostFields[0] != "status.unknownField" {
+		t.Errorf("expected lost fields = [status.unknownField], actual lost fields = %v", lostFields)
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	VersionHandler("v1.4.3")(rr, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var version WebhookVersion
+	if err := stdjson.Unmarshal(rr.Body.Bytes(), &version); err != nil {
+		t.Fatalf("unable to decode the version response: %v", err)
+	}
+	if version.Version != "v1.4.3" || !reflect.DeepEqual(version.GroupVersions, supportedVersions) {
+		t.Errorf("unexpected version response %+v", version)
+	}
+
+	rr = httptest.NewRecorder()
+	VersionHandler("v1.4.3")(rr, httptest.NewRequest(http.MethodPost, VersionPath, nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
+
+func TestWebhookVersionCheckCompatibility(t *testing.T) {
+	testCases := []struct {
+		groupVersions []string
+		versions      []string
+		expectErr     bool
+	}{
+		{groupVersions: supportedVersions, versions: []string{versionV1Alpha1, versionV1Beta1}},
+		{groupVersions: []string{versionV1Alpha1, versionV1Beta1}, versions: []string{versionV1Beta1}, expectErr: true},
+		{groupVersions: []string{versionV1Beta1, versionV1Beta2}, versions: []string{versionV1Alpha1}, expectErr: true},
+		{groupVersions: []string{versionV1Beta2}, versions: []string{versionV1Beta2}},
+	}
+	for i, testCase := range testCases {
+		err := WebhookVersion{Version: "dev", GroupVersions: testCase.groupVersions}.CheckCompatibility(testCase.versions)
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %d: expected error: %v, got: %v", i+1, testCase.expectErr, err)
+		}
+	}
+}
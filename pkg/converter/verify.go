@@ -0,0 +1,263 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	directv1alpha1 "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1alpha1"
+	directv1beta1 "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// VerificationResult - outcome of migrating a sample object to the latest version
+type VerificationResult struct {
+	Kind        CRDKind
+	FromVersion string
+	ToVersion   string
+	// LostFields holds the fields of the source object which were dropped
+	// or altered by the migration
+	LostFields []string
+	Err        error
+}
+
+// LatestVersion - returns the latest supported CRD version
+func LatestVersion() string {
+	return supportedVersions[len(supportedVersions)-1]
+}
+
+// VerifyConversion - migrates a sample object of every kind in each of the given
+// versions to the latest version and reports fields lost during the migration.
+// All the older supported versions are verified if no versions are provided.
+func VerifyConversion(versions ...string) []VerificationResult {
+	toVersion := LatestVersion()
+	if len(versions) == 0 {
+		versions = supportedVersions[:len(supportedVersions)-1]
+	}
+
+	results := []VerificationResult{}
+	for _, kind := range []CRDKind{DriveCRDKind, VolumeCRDKind} {
+		for _, fromVersion := range versions {
+			if fromVersion == toVersion {
+				continue
+			}
+			result := VerificationResult{
+				Kind:        kind,
+				FromVersion: fromVersion,
+				ToVersion:   toVersion,
+			}
+			object, err := newSampleObject(kind, fromVersion)
+			if err != nil {
+				result.Err = err
+				results = append(results, result)
+				continue
+			}
+			result.LostFields, result.Err = VerifyMigration(object, toVersion)
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// VerifyMigration - migrates a copy of the object to the given version and returns
+// the fields of the object which did not survive the migration
+func VerifyMigration(object *unstructured.Unstructured, toVersion string) ([]string, error) {
+	convertedObject := object.DeepCopy()
+	if err := Migrate(convertedObject, toVersion); err != nil {
+		return nil, err
+	}
+	convertedObject.SetAPIVersion(toVersion)
+
+	sourceFields := map[string]interface{}{}
+	flattenFields("", object.Object, sourceFields)
+	convertedFields := map[string]interface{}{}
+	flattenFields("", convertedObject.Object, convertedFields)
+
+	lostFields := []string{}
+	for field, value := range sourceFields {
+		if field == "apiVersion" {
+			continue
+		}
+		convertedValue, ok := convertedFields[field]
+		if !ok || !reflect.DeepEqual(value, convertedValue) {
+			lostFields = append(lostFields, field)
+		}
+	}
+	sort.Strings(lostFields)
+	return lostFields, nil
+}
+
+func flattenFields(prefix string, value interface{}, fields map[string]interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			flattenFields(join(key), val, fields)
+		}
+	case []interface{}:
+		for i, val := range v {
+			flattenFields(fmt.Sprintf("%s[%d]", prefix, i), val, fields)
+		}
+	default:
+		fields[prefix] = v
+	}
+}
+
+func newSampleObject(kind CRDKind, version string) (*unstructured.Unstructured, error) {
+	var object interface{}
+	typeMeta := metav1.TypeMeta{
+		APIVersion: version,
+		Kind:       string(kind),
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name: "verify-conversion",
+		Labels: map[string]string{
+			"direct.csi.min.io/node": "node-1",
+		},
+	}
+	condition := metav1.Condition{
+		Type:               "Owned",
+		Status:             metav1.ConditionTrue,
+		Reason:             "Added",
+		Message:            "verify",
+		LastTransitionTime: metav1.Now(),
+	}
+	topology := map[string]string{
+		"direct.csi.min.io/node": "node-1",
+	}
+
+	switch {
+	case kind == DriveCRDKind && version == versionV1Alpha1:
+		object = &directv1alpha1.DirectCSIDrive{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Spec: directv1alpha1.DirectCSIDriveSpec{
+				DirectCSIOwned: true,
+				RequestedFormat: &directv1alpha1.RequestedFormat{
+					Force:        true,
+					Filesystem:   "xfs",
+					MountOptions: []string{"rw"},
+				},
+				DriveTaint: map[string]string{"key": "value"},
+			},
+			Status: directv1alpha1.DirectCSIDriveStatus{
+				Path:              "/var/lib/direct-csi/devices/sdb",
+				AllocatedCapacity: 1024,
+				FreeCapacity:      1024,
+				RootPartition:     "sdb",
+				PartitionNum:      1,
+				Filesystem:        "xfs",
+				Mountpoint:        "/var/lib/direct-csi/mnt/verify-conversion",
+				MountOptions:      []string{"rw"},
+				NodeName:          "node-1",
+				DriveStatus:       directv1alpha1.DriveStatusReady,
+				ModelNumber:       "model",
+				SerialNumber:      "serial",
+				TotalCapacity:     2048,
+				PhysicalBlockSize: 512,
+				LogicalBlockSize:  512,
+				Topology:          topology,
+				Conditions:        []metav1.Condition{condition},
+			},
+		}
+	case kind == DriveCRDKind && version == versionV1Beta1:
+		object = &directv1beta1.DirectCSIDrive{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Spec: directv1beta1.DirectCSIDriveSpec{
+				DirectCSIOwned: true,
+				RequestedFormat: &directv1beta1.RequestedFormat{
+					Force:        true,
+					Filesystem:   "xfs",
+					MountOptions: []string{"rw"},
+				},
+				DriveTaint: map[string]string{"key": "value"},
+			},
+			Status: directv1beta1.DirectCSIDriveStatus{
+				Path:              "/var/lib/direct-csi/devices/sdb",
+				AllocatedCapacity: 1024,
+				FreeCapacity:      1024,
+				RootPartition:     "sdb",
+				PartitionNum:      1,
+				Filesystem:        "xfs",
+				Mountpoint:        "/var/lib/direct-csi/mnt/verify-conversion",
+				MountOptions:      []string{"rw"},
+				NodeName:          "node-1",
+				DriveStatus:       directv1beta1.DriveStatusReady,
+				ModelNumber:       "model",
+				SerialNumber:      "serial",
+				TotalCapacity:     2048,
+				PhysicalBlockSize: 512,
+				LogicalBlockSize:  512,
+				Topology:          topology,
+				AccessTier:        directv1beta1.AccessTierHot,
+				Conditions:        []metav1.Condition{condition},
+			},
+		}
+	case kind == VolumeCRDKind && version == versionV1Alpha1:
+		object = &directv1alpha1.DirectCSIVolume{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Status: directv1alpha1.DirectCSIVolumeStatus{
+				Drive:             "verify-conversion",
+				NodeName:          "node-1",
+				HostPath:          "/var/lib/direct-csi/mnt/verify-conversion/volume",
+				StagingPath:       "/var/lib/kubelet/staging",
+				ContainerPath:     "/var/lib/kubelet/container",
+				TotalCapacity:     1024,
+				AvailableCapacity: 512,
+				UsedCapacity:      512,
+				Conditions:        []metav1.Condition{condition},
+			},
+		}
+	case kind == VolumeCRDKind && version == versionV1Beta1:
+		object = &directv1beta1.DirectCSIVolume{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Status: directv1beta1.DirectCSIVolumeStatus{
+				Drive:             "verify-conversion",
+				NodeName:          "node-1",
+				HostPath:          "/var/lib/direct-csi/mnt/verify-conversion/volume",
+				StagingPath:       "/var/lib/kubelet/staging",
+				ContainerPath:     "/var/lib/kubelet/container",
+				TotalCapacity:     1024,
+				AvailableCapacity: 512,
+				UsedCapacity:      512,
+				Conditions:        []metav1.Condition{condition},
+			},
+		}
+	default:
+		return nil, fmt.Errorf("no sample object for %s in version %s", kind, version)
+	}
+
+	unstructuredObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: unstructuredObject}, nil
+}
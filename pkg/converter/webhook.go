@@ -19,11 +19,12 @@ package converter
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -33,9 +34,30 @@ const (
 	DriveHandlerPath  = "/convertdrive"
 	VolumeHandlerPath = "/convertvolume"
 	healthzPath       = "/healthz"
+	VersionPath       = "/version"
 )
 
-func ServeConversionWebhook(ctx context.Context) error {
+// WebhookVersion - the build version of the conversion webhook and the group versions it converts between
+type WebhookVersion struct {
+	Version       string   `json:"version"`
+	GroupVersions []string `json:"groupVersions"`
+}
+
+// CheckCompatibility fails if the webhook cannot convert the given versions to the latest version
+func (v WebhookVersion) CheckCompatibility(versions []string) error {
+	served := map[string]struct{}{}
+	for _, version := range v.GroupVersions {
+		served[version] = struct{}{}
+	}
+	for _, version := range append(append([]string{}, versions...), LatestVersion()) {
+		if _, ok := served[version]; !ok {
+			return fmt.Errorf("conversion webhook %s does not support %s", v.Version, version)
+		}
+	}
+	return nil
+}
+
+func ServeConversionWebhook(ctx context.Context, version string) error {
 	certs, err := tls.LoadX509KeyPair(certPath, keyPath)
 	if err != nil {
 		klog.Errorf("Filed to load key pair: %v", err)
@@ -55,6 +77,7 @@ func ServeConversionWebhook(ctx context.Context) error {
 	mux.HandleFunc(DriveHandlerPath, ServeDriveConversion)
 	mux.HandleFunc(VolumeHandlerPath, ServeVolumeConversion)
 	mux.HandleFunc(healthzPath, LivenessCheckHandler)
+	mux.HandleFunc(VersionPath, VersionHandler(version))
 	server.Handler = mux
 
 	lc := net.ListenConfig{}
@@ -81,3 +104,20 @@ func LivenessCheckHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// VersionHandler - Serves the build version and the supported group versions of the webhook
+func VersionHandler(version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(WebhookVersion{
+			Version:       version,
+			GroupVersions: append([]string{}, supportedVersions...),
+		}); err != nil {
+			klog.Errorf("Failed to write the version response: %v", err)
+		}
+	}
+}
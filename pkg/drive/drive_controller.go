@@ -18,21 +18,29 @@ package drive
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/clientset"
+	"github.com/minio/direct-csi/pkg/health"
 	"github.com/minio/direct-csi/pkg/listener"
 	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
 	"github.com/minio/direct-csi/pkg/utils"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/google/uuid"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 type DriveUpdateType int
@@ -40,12 +48,20 @@ type DriveUpdateType int
 const (
 	DriveUpdateTypeDelete DriveUpdateType = iota
 	DriveUpdateTypeOwnAndFormat
+	DriveUpdateTypeRelease
 	DriveUpdateTypeStorageSpace
 	DriveUpdateTypeDriveParams
 	DriveUpdateTypeVolumeDelete
 	DriveUpdateTypeUnknown
 )
 
+// driveUpdateTimeout bounds each update of a drive object, so that a slow apiserver
+// does not hold the drive controller after the device is configured
+const driveUpdateTimeout = 30 * time.Second
+
+// DefaultMaxConcurrentFormats is the default number of drives formatted and mounted at once in a node
+const DefaultMaxConcurrentFormats = 2
+
 type DirectCSIDriveListener struct {
 	kubeClient      kubeclientset.Interface
 	directcsiClient clientset.Interface
@@ -53,6 +69,10 @@ type DirectCSIDriveListener struct {
 	mounter         sys.DriveMounter
 	formatter       sys.DriveFormatter
 	statter         sys.DriveStatter
+	// formatSlots bounds the drives formatted and mounted at once, it is unbounded if nil
+	formatSlots chan struct{}
+	// detachBusyMounts lazily detaches the mount of a released drive which stays busy
+	detachBusyMounts bool
 }
 
 func (b *DirectCSIDriveListener) InitializeKubeClient(k kubeclientset.Interface) {
@@ -67,6 +87,141 @@ func (b *DirectCSIDriveListener) Add(ctx context.Context, obj *directcsi.DirectC
 	return nil
 }
 
+// acquireFormatSlot waits for one of the slots bounding the concurrent formats in the node, so that a bulk
+// format does not saturate the IO and the CPU of the node with mkfs. The returned func releases the slot.
+func (d *DirectCSIDriveListener) acquireFormatSlot(ctx context.Context) (func(), error) {
+	if d.formatSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case d.formatSlots <- struct{}{}:
+		return func() { <-d.formatSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// verifyDeviceNumbers checks that the device still has the major and minor numbers recorded at discovery,
+// a mismatch means that the device was replaced by another one taking the same name
+func (d *DirectCSIDriveListener) verifyDeviceNumbers(drive *directcsi.DirectCSIDrive) error {
+	// the sysfs entries are named by the kernel names, e.g. sdb1 for the partition sdb-part-1
+	devName := filepath.Base(sys.GetRootBlockPath(drive.Status.Path))
+	major, minor, err := d.statter.GetMajorMinor(devName)
+	if err != nil {
+		return fmt.Errorf("failed to read the device numbers of drive %s (%s): %v", drive.Name, drive.Status.Path, err)
+	}
+	if major != drive.Status.MajorNumber || minor != drive.Status.MinorNumber {
+		return fmt.Errorf("device numbers of drive %s (%s) changed from %d:%d to %d:%d; refusing to format a different device",
+			drive.Name, drive.Status.Path, drive.Status.MajorNumber, drive.Status.MinorNumber, major, minor)
+	}
+	return nil
+}
+
+// validateAdoption checks if the existing filesystem on the drive can be mounted as is
+func validateAdoption(drive *directcsi.DirectCSIDrive) error {
+	if drive.Status.Filesystem != string(sys.FSTypeXFS) {
+		return fmt.Errorf("cannot adopt drive %s with filesystem %q, only xfs drives can be adopted", drive.Name, drive.Status.Filesystem)
+	}
+	if drive.Status.FilesystemUUID == "" {
+		return fmt.Errorf("cannot adopt drive %s without a filesystem uuid", drive.Name)
+	}
+	if drive.Status.Mountpoint != "" {
+		return fmt.Errorf("drive %s is mounted at %s, unmount it before adopting", drive.Name, drive.Status.Mountpoint)
+	}
+	return nil
+}
+
+// classifyFailure returns DeviceBusy or ReadOnly if the error of a step is caused by a busy or a
+// read-only device, and the reason of the failed step otherwise
+func classifyFailure(err error, stepReason directcsi.DirectCSIDriveReason) directcsi.DirectCSIDriveReason {
+	switch {
+	case errors.Is(err, syscall.EBUSY):
+		return directcsi.DirectCSIDriveReasonDeviceBusy
+	case errors.Is(err, syscall.EROFS):
+		return directcsi.DirectCSIDriveReasonReadOnly
+	}
+	return stepReason
+}
+
+// isRetriableUpdateError returns true for the update errors which may succeed on a retry
+func isRetriableUpdateError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// updateDrive applies the changes of mutate onto the drive and updates it. On a conflict the
+// latest version of the drive is fetched and the changes are applied onto it again, so mutate
+// must only set the fields owned by the step that configured the device. The update is aborted
+// with the error of mutate, e.g. if the latest version no longer allows the change.
+func (d *DirectCSIDriveListener) updateDrive(ctx context.Context, drive *directcsi.DirectCSIDrive, mutate func(drive *directcsi.DirectCSIDrive) error) (*directcsi.DirectCSIDrive, error) {
+	drivesClient := d.directcsiClient.DirectV1beta2().DirectCSIDrives()
+	latest := drive.DeepCopy()
+	var updated *directcsi.DirectCSIDrive
+	retriable := func(err error) bool {
+		return ctx.Err() == nil && isRetriableUpdateError(err)
+	}
+	err := retry.OnError(retry.DefaultRetry, retriable, func() (err error) {
+		if err := mutate(latest); err != nil {
+			return err
+		}
+		updateCtx, cancel := context.WithTimeout(ctx, driveUpdateTimeout)
+		defer cancel()
+		updated, err = drivesClient.Update(updateCtx, latest, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		if apierrors.IsConflict(err) {
+			klog.V(3).Infof("drive %s was modified concurrently, retrying the update on its latest version", drive.Name)
+			current, gErr := drivesClient.Get(updateCtx, drive.Name, metav1.GetOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			})
+			if gErr != nil {
+				return gErr
+			}
+			latest = current
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// removeDataProtectionFinalizer releases the drive, the finalizers of the volumes are retained
+func removeDataProtectionFinalizer(drive *directcsi.DirectCSIDrive) error {
+	finalizers := []string{}
+	for _, finalizer := range drive.GetFinalizers() {
+		if finalizer != directcsi.DirectCSIDriveFinalizerDataProtection {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	drive.Finalizers = finalizers
+	return nil
+}
+
+// checkRelease checks if the drive can be released, i.e. if it is still ready and no volume was
+// scheduled on it since the release was requested
+func checkRelease(drive *directcsi.DirectCSIDrive) error {
+	if drive.Spec.DirectCSIOwned {
+		return fmt.Errorf("drive %s is requested to be owned again", drive.Name)
+	}
+	if drive.Status.DriveStatus != directcsi.DriveStatusReady {
+		return fmt.Errorf("drive %s is %s, only ready drives can be released", drive.Name, drive.Status.DriveStatus)
+	}
+	for _, finalizer := range drive.GetFinalizers() {
+		if strings.HasPrefix(finalizer, directcsi.DirectCSIDriveFinalizerPrefix) {
+			return fmt.Errorf("drive %s has volume %s", drive.Name, strings.TrimPrefix(finalizer, directcsi.DirectCSIDriveFinalizerPrefix))
+		}
+		if strings.HasPrefix(finalizer, directcsi.DirectCSIDriveFinalizerSnapshotPrefix) {
+			return fmt.Errorf("drive %s has snapshot %s", drive.Name, strings.TrimPrefix(finalizer, directcsi.DirectCSIDriveFinalizerSnapshotPrefix))
+		}
+	}
+	return nil
+}
+
 func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi.DirectCSIDrive) error {
 	var err error
 	directCSIClient := d.directcsiClient.DirectV1beta2()
@@ -89,6 +244,14 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 		return true
 	}
 
+	release := func(ctx context.Context, old, new *directcsi.DirectCSIDrive) bool {
+		// if directCSIOwned is cleared on a drive owned by direct-csi
+		if old.Spec.DirectCSIOwned == true && new.Spec.DirectCSIOwned == false {
+			return new.Status.DriveStatus == directcsi.DriveStatusReady
+		}
+		return false
+	}
+
 	ownAndFormat := func(ctx context.Context, old, new *directcsi.DirectCSIDrive) bool {
 		// if directCSIOwned is set to true
 		if new.Spec.DirectCSIOwned == true && old.Spec.DirectCSIOwned == false {
@@ -134,6 +297,9 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 		if deleting() {
 			return DriveUpdateTypeDelete
 		}
+		if release(ctx, old, new) {
+			return DriveUpdateTypeRelease
+		}
 		if ownAndFormat(ctx, old, new) {
 			return DriveUpdateTypeOwnAndFormat
 		}
@@ -172,9 +338,9 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 	switch driveUpdateType(ctx, old, new) {
 	case DriveUpdateTypeDelete:
 		if new.Status.DriveStatus != directcsi.DriveStatusTerminating {
-			new.Status.DriveStatus = directcsi.DriveStatusTerminating
-			if new, err = directCSIClient.DirectCSIDrives().Update(ctx, new, metav1.UpdateOptions{
-				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			if new, err = d.updateDrive(ctx, new, func(drive *directcsi.DirectCSIDrive) error {
+				drive.Status.DriveStatus = directcsi.DriveStatusTerminating
+				return nil
 			}); err != nil {
 				return err
 			}
@@ -194,14 +360,15 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 			return fmt.Errorf("invalid state reached. Please contact subnet.min.io")
 		}
 
-		if err := sys.SafeUnmount(filepath.Join(sys.MountRoot, new.Name), nil); err != nil {
+		unmountOpts := []sys.UnmountOption{}
+		if d.detachBusyMounts {
+			unmountOpts = append(unmountOpts, sys.UnmountOptionDetachOnBusy)
+		}
+		if err := sys.SafeUnmount(filepath.Join(sys.MountRoot, new.Name), unmountOpts); err != nil {
 			return err
 		}
 
-		new.Finalizers = []string{}
-		if new, err = directCSIClient.DirectCSIDrives().Update(ctx, new, metav1.UpdateOptions{
-			TypeMeta: utils.DirectCSIDriveTypeMeta(),
-		}); err != nil {
+		if _, err = d.updateDrive(ctx, new, removeDataProtectionFinalizer); err != nil {
 			return err
 		}
 	case DriveUpdateTypeOwnAndFormat:
@@ -212,6 +379,23 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 			}
 			return false
 		}()
+		label := func() string {
+			if new.Spec.RequestedFormat != nil && new.Spec.RequestedFormat.Label != "" {
+				return new.Spec.RequestedFormat.Label
+			}
+			// default to the drive name truncated to the maximum length supported by xfs
+			if len(new.Name) > xfs.MaxLabelLength {
+				return new.Name[:xfs.MaxLabelLength]
+			}
+			return new.Name
+		}()
+		// the reasons of the format or the mount step if it failed
+		var formatFailure, mountFailure directcsi.DirectCSIDriveReason
+		// adopted drives retain their filesystem and data
+		adopt := new.GetAnnotations()[directcsi.DirectCSIDriveAnnotationAdopt] == "true"
+		// the outcome of the check of an existing filesystem, if requested
+		repair := new.GetAnnotations()[directcsi.DirectCSIDriveAnnotationRepair]
+		var repairOutcome directcsi.DirectCSIDriveReason
 		mounted := new.Status.Mountpoint != ""
 		formatted := new.Status.Filesystem != ""
 
@@ -232,6 +416,16 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 			klog.V(3).Infof("rejected request to format a terminating drive %s", new.Name)
 			return nil
 		case directcsi.DriveStatusAvailable:
+			release, err := d.acquireFormatSlot(ctx)
+			if err != nil {
+				return err
+			}
+			defer release()
+			klog.V(3).Infof("initializing drive %s", new.Name)
+
+			if adopt {
+				updateErr = validateAdoption(new)
+			}
 			UUID := new.Status.FilesystemUUID
 			if UUID == "" {
 				UUID = uuid.New().String()
@@ -242,25 +436,42 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 					return err
 				}
 				if isDup {
-					UUID = uuid.New().String()
+					if adopt {
+						// the uuid cannot be changed without formatting the drive
+						updateErr = fmt.Errorf("cannot adopt drive %s, filesystem uuid %s is used by another drive", new.Name, UUID)
+					} else {
+						UUID = uuid.New().String()
+					}
 				}
 			}
-			new.Status.FilesystemUUID = UUID
+			// the device file of a refused drive is not created, it would replace the device file
+			// of the drive which already owns the uuid
+			if updateErr == nil {
+				new.Status.FilesystemUUID = UUID
+			}
 
 			directCSIPath := sys.GetDirectCSIPath(new.Status.FilesystemUUID)
 			directCSIMount := filepath.Join(sys.MountRoot, new.Status.FilesystemUUID)
-			if err := d.formatter.MakeBlockFile(directCSIPath, new.Status.MajorNumber, new.Status.MinorNumber); err != nil {
-				klog.Error(err)
-				updateErr = err
+			if updateErr == nil {
+				if err := d.formatter.MakeBlockFile(directCSIPath, new.Status.MajorNumber, new.Status.MinorNumber); err != nil {
+					klog.Error(err)
+					updateErr = err
+				}
 			}
 
 			source := directCSIPath
 			target := directCSIMount
 			mountOpts := new.Spec.RequestedFormat.MountOptions
-			if updateErr == nil {
+			if updateErr == nil && !adopt {
 				if !formatted || force {
-					if mounted {
+					if err := d.verifyDeviceNumbers(new); err != nil {
+						klog.Error(err)
+						updateErr = err
+					}
+
+					if updateErr == nil && mounted {
 						if err := d.mounter.UnmountDrive(source); err != nil {
+							mountFailure = classifyFailure(err, directcsi.DirectCSIDriveReasonMountFailed)
 							err = fmt.Errorf("failed to unmount drive: %s %v", new.Name, err)
 							klog.Error(err)
 							updateErr = err
@@ -270,22 +481,65 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 						}
 					}
 
+					// a device used outside direct-csi is never formatted, not even with force
+					if updateErr == nil {
+						if err := d.statter.CheckDeviceFree(source, new.Status.MajorNumber, new.Status.MinorNumber); err != nil {
+							formatFailure = classifyFailure(err, directcsi.DirectCSIDriveReasonFormatFailed)
+							err = fmt.Errorf("refusing to format drive: %s %v", new.Name, err)
+							klog.Error(err)
+							updateErr = err
+						}
+					}
+
 					if updateErr == nil {
-						if err := d.formatter.FormatDrive(ctx, new.Status.FilesystemUUID, source, force); err != nil {
+						blockSize := new.Spec.RequestedFormat.BlockSize
+						if err := d.formatter.FormatDrive(ctx, new.Status.FilesystemUUID, source, label, blockSize, new.Spec.RequestedFormat.MkfsOptions, force); err != nil {
+							formatFailure = classifyFailure(err, directcsi.DirectCSIDriveReasonFormatFailed)
 							err = fmt.Errorf("failed to format drive: %s %v", new.Name, err)
 							klog.Error(err)
 							updateErr = err
 						} else {
 							new.Status.Filesystem = string(sys.FSTypeXFS)
+							new.Status.FilesystemLabel = label
+							if blockSize == 0 {
+								blockSize = xfs.DefaultBlockSize
+							}
+							new.Status.FilesystemBlockSize = blockSize
 							new.Status.AllocatedCapacity = int64(0)
 							formatted = true
+							// a fresh filesystem needs no check
+							repair = ""
 						}
 					}
 				}
 			}
 
+			if updateErr == nil && !mounted && repair != "" {
+				fix := repair == directcsi.DirectCSIDriveRepairFix
+				corrupted, err := d.formatter.RepairDrive(ctx, source, fix)
+				switch {
+				case err != nil:
+					formatFailure = classifyFailure(err, directcsi.DirectCSIDriveReasonRepairFailed)
+					repairOutcome = formatFailure
+					err = fmt.Errorf("failed to check the filesystem of drive: %s %v", new.Name, err)
+					klog.Error(err)
+					updateErr = err
+				case corrupted && !fix:
+					formatFailure = directcsi.DirectCSIDriveReasonFilesystemCorrupted
+					repairOutcome = formatFailure
+					updateErr = fmt.Errorf("filesystem of drive %s has errors, repair it before mounting", new.Name)
+					klog.Error(updateErr)
+				case corrupted:
+					klog.V(3).Infof("repaired the filesystem of drive %s", new.Name)
+					repairOutcome = directcsi.DirectCSIDriveReasonRepaired
+				default:
+					repairOutcome = directcsi.DirectCSIDriveReasonFilesystemChecked
+				}
+			}
+
 			if updateErr == nil && !mounted {
-				if err := d.mounter.MountDrive(source, target, mountOpts); err != nil {
+				if err := d.mounter.MountDrive(ctx, source, target, mountOpts); err != nil {
+					mountFailure = classifyFailure(err, directcsi.DirectCSIDriveReasonMountFailed)
 					err = fmt.Errorf("failed to mount drive: %s %v", new.Name, err)
 					klog.Error(err)
 					updateErr = err
@@ -295,11 +549,17 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 					freeCapacity, sErr := d.statter.GetFreeCapacityFromStatfs(new.Status.Mountpoint)
 					if sErr != nil {
 						klog.Error(sErr)
+						mountFailure = directcsi.DirectCSIDriveReasonMountFailed
 						updateErr = sErr
 					} else {
 						mounted = true
-						new.Status.FreeCapacity = freeCapacity
-						new.Status.AllocatedCapacity = new.Status.TotalCapacity - new.Status.FreeCapacity
+						reserved := utils.ReservedCapacity(new.Status.TotalCapacity, new.Spec.RequestedFormat.ReservedPercent)
+						new.Status.ReservedCapacity = reserved
+						new.Status.AllocatedCapacity = new.Status.TotalCapacity - freeCapacity
+						new.Status.FreeCapacity = freeCapacity - reserved
+						if new.Status.FreeCapacity < 0 {
+							new.Status.FreeCapacity = 0
+						}
 					}
 				}
 			}
@@ -316,10 +576,20 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 					conditions[i].Message = ""
 					if updateErr != nil {
 						conditions[i].Message = updateErr.Error()
+						// the steps stop at the first failure
+						if formatFailure != "" {
+							conditions[i].Reason = string(formatFailure)
+						}
+						if mountFailure != "" {
+							conditions[i].Reason = string(mountFailure)
+						}
 					}
 				case string(directcsi.DirectCSIDriveConditionMounted):
 					conditions[i].Status = utils.BoolToCondition(mounted)
 					conditions[i].Reason = string(directcsi.DirectCSIDriveReasonAdded)
+					if mountFailure != "" {
+						conditions[i].Reason = string(mountFailure)
+					}
 					conditions[i].LastTransitionTime = metav1.Now()
 					conditions[i].Message = func() string {
 						if conditions[i].Status == metav1.ConditionTrue {
@@ -330,6 +600,12 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 				case string(directcsi.DirectCSIDriveConditionFormatted):
 					conditions[i].Status = utils.BoolToCondition(formatted)
 					conditions[i].Reason = string(directcsi.DirectCSIDriveReasonAdded)
+					if repairOutcome != "" {
+						conditions[i].Reason = string(repairOutcome)
+					}
+					if formatFailure != "" {
+						conditions[i].Reason = string(formatFailure)
+					}
 					conditions[i].LastTransitionTime = metav1.Now()
 					conditions[i].Message = func() string {
 						if conditions[i].Status == metav1.ConditionTrue {
@@ -340,20 +616,128 @@ func (d *DirectCSIDriveListener) Update(ctx context.Context, old, new *directcsi
 				}
 			}
 			if updateErr == nil {
-				new.Finalizers = []string{
-					directcsi.DirectCSIDriveFinalizerDataProtection,
-				}
 				new.Status.DriveStatus = directcsi.DriveStatusReady
-				new.Spec.RequestedFormat = nil
 			}
 
-			if new, err = directCSIClient.DirectCSIDrives().Update(ctx, new, metav1.UpdateOptions{
-				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			// the device is fully configured at this point, its state is committed in a single update
+			configured := new.Status
+			if _, err = d.updateDrive(ctx, new, func(drive *directcsi.DirectCSIDrive) error {
+				drive.Status.FilesystemUUID = configured.FilesystemUUID
+				drive.Status.Filesystem = configured.Filesystem
+				drive.Status.FilesystemLabel = configured.FilesystemLabel
+				drive.Status.FilesystemBlockSize = configured.FilesystemBlockSize
+				drive.Status.Mountpoint = configured.Mountpoint
+				drive.Status.MountOptions = configured.MountOptions
+				drive.Status.FreeCapacity = configured.FreeCapacity
+				drive.Status.AllocatedCapacity = configured.AllocatedCapacity
+				drive.Status.ReservedCapacity = configured.ReservedCapacity
+				drive.Status.Conditions = configured.Conditions
+				drive.Status.DriveStatus = configured.DriveStatus
+				if updateErr == nil {
+					drive.Finalizers = []string{
+						directcsi.DirectCSIDriveFinalizerDataProtection,
+					}
+					drive.Spec.RequestedFormat = nil
+					delete(drive.Annotations, directcsi.DirectCSIDriveAnnotationAdopt)
+					delete(drive.Annotations, directcsi.DirectCSIDriveAnnotationRepair)
+				} else if adopt {
+					// a failed adoption is not retried, the request would be taken for a format without the annotation
+					drive.Spec.DirectCSIOwned = false
+					drive.Spec.RequestedFormat = nil
+					delete(drive.Annotations, directcsi.DirectCSIDriveAnnotationAdopt)
+					delete(drive.Annotations, directcsi.DirectCSIDriveAnnotationRepair)
+				}
+				return nil
 			}); err != nil {
 				return err
 			}
 			return nil
 		}
+	case DriveUpdateTypeRelease:
+		klog.V(3).Infof("releasing drive %s", new.Name)
+		// the release is validated on the latest version before the drive is unmounted or wiped,
+		// a volume may have been scheduled on it since this update was queued
+		if new, err = directCSIClient.DirectCSIDrives().Get(ctx, new.Name, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		}); err != nil {
+			return err
+		}
+		if err := checkRelease(new); err != nil {
+			// the error is recorded as an event on the drive
+			return fmt.Errorf("refused to release drive %s: %v", new.Name, err)
+		}
+
+		wipe := new.GetAnnotations()[directcsi.DirectCSIDriveAnnotationWipe] == "true"
+		source := sys.GetDirectCSIPath(new.Status.FilesystemUUID)
+		if new.Status.Mountpoint != "" {
+			if err := d.mounter.UnmountDrive(source); err != nil {
+				err = fmt.Errorf("failed to unmount drive: %s %v", new.Name, err)
+				klog.Error(err)
+				return err
+			}
+			new.Status.Mountpoint = ""
+			new.Status.MountOptions = nil
+		}
+
+		if wipe {
+			if err := d.formatter.MakeBlockFile(source, new.Status.MajorNumber, new.Status.MinorNumber); err != nil {
+				klog.Error(err)
+				return err
+			}
+			if err := d.formatter.WipeDrive(ctx, source); err != nil {
+				err = fmt.Errorf("failed to wipe drive: %s %v", new.Name, err)
+				klog.Error(err)
+				return err
+			}
+			new.Status.Filesystem = ""
+			new.Status.FilesystemBlockSize = 0
+		}
+		formatted := new.Status.Filesystem != ""
+		formattedMessage := string(directcsi.DirectCSIDriveMessageNotFormatted)
+		if formatted {
+			formattedMessage = string(directcsi.DirectCSIDriveMessageFormatted)
+		}
+
+		released := new.Status
+		if _, err = d.updateDrive(ctx, new, func(drive *directcsi.DirectCSIDrive) error {
+			// the drive is re-validated on its latest version, a volume may have been added meanwhile
+			if err := checkRelease(drive); err != nil {
+				return fmt.Errorf("refused to release drive %s: %v", drive.Name, err)
+			}
+			if err := removeDataProtectionFinalizer(drive); err != nil {
+				return err
+			}
+			drive.Spec.RequestedFormat = nil
+			if wipe {
+				delete(drive.Annotations, directcsi.DirectCSIDriveAnnotationWipe)
+			}
+			drive.Status.Mountpoint = released.Mountpoint
+			drive.Status.MountOptions = released.MountOptions
+			drive.Status.Filesystem = released.Filesystem
+			drive.Status.FilesystemBlockSize = released.FilesystemBlockSize
+			utils.UpdateCondition(drive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionOwned),
+				metav1.ConditionFalse,
+				string(directcsi.DirectCSIDriveReasonNotAdded),
+				"")
+			utils.UpdateCondition(drive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionMounted),
+				metav1.ConditionFalse,
+				string(directcsi.DirectCSIDriveReasonNotAdded),
+				string(directcsi.DirectCSIDriveMessageNotMounted))
+			utils.UpdateCondition(drive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionFormatted),
+				utils.BoolToCondition(formatted),
+				string(directcsi.DirectCSIDriveReasonNotAdded),
+				formattedMessage)
+			drive.Status.AllocatedCapacity = int64(0)
+			drive.Status.FreeCapacity = drive.Status.TotalCapacity
+			drive.Status.ReservedCapacity = int64(0)
+			drive.Status.DriveStatus = directcsi.DriveStatusAvailable
+			return nil
+		}); err != nil {
+			return err
+		}
 	case DriveUpdateTypeStorageSpace:
 		// no-op
 	case DriveUpdateTypeDriveParams:
@@ -368,21 +752,34 @@ func (b *DirectCSIDriveListener) Delete(ctx context.Context, obj *directcsi.Dire
 	return nil
 }
 
-func StartDriveController(ctx context.Context, nodeID string) error {
+func StartDriveController(ctx context.Context, nodeID string, timings listener.ControllerTimings, maxConcurrentFormats int, detachBusyMounts bool) error {
 	hostname, err := os.Hostname()
 	if err != nil {
 		return err
 	}
-	ctrl, err := listener.NewDefaultDirectCSIController("drive-controller", hostname, 40)
-	if err != nil {
-		klog.Error(err)
-		return err
+	for {
+		ctrl, err := listener.NewDefaultDirectCSIController("drive-controller", hostname, 40, timings)
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		ctrl.AddDirectCSIDriveListener(&DirectCSIDriveListener{
+			nodeID:           nodeID,
+			mounter:          &sys.DefaultDriveMounter{},
+			formatter:        &sys.DefaultDriveFormatter{},
+			statter:          &sys.DefaultDriveStatter{},
+			formatSlots:      make(chan struct{}, maxConcurrentFormats),
+			detachBusyMounts: detachBusyMounts,
+		})
+		health.RegisterReadinessCheck("drive-controller", func() error {
+			if !ctrl.HasSynced() {
+				return errors.New("drive controller caches are not synced")
+			}
+			return nil
+		})
+		// contend for the leadership again if another replica took it over
+		if err := ctrl.Run(ctx); !errors.Is(err, listener.ErrLeadershipLost) {
+			return err
+		}
 	}
-	ctrl.AddDirectCSIDriveListener(&DirectCSIDriveListener{
-		nodeID:    nodeID,
-		mounter:   &sys.DefaultDriveMounter{},
-		formatter: &sys.DefaultDriveFormatter{},
-		statter:   &sys.DefaultDriveStatter{},
-	})
-	return ctrl.Run(ctx)
 }
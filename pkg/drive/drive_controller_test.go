@@ -18,17 +18,24 @@ package drive
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 const (
@@ -37,35 +44,76 @@ const (
 
 type fakeDriveStatter struct {
 	args struct {
-		path string
+		path    string
+		devName string
 	}
+	major        uint32
+	minor        uint32
+	freeCapacity int64
+	busyErr      error
 }
 
 func (c *fakeDriveStatter) GetFreeCapacityFromStatfs(path string) (int64, error) {
 	c.args.path = path
-	return 0, nil
+	return c.freeCapacity, nil
+}
+
+func (c *fakeDriveStatter) GetMajorMinor(devName string) (uint32, uint32, error) {
+	c.args.devName = devName
+	return c.major, c.minor, nil
+}
+
+func (c *fakeDriveStatter) CheckDeviceFree(path string, major, minor uint32) error {
+	return c.busyErr
 }
 
 type fakeDriveFormatter struct {
 	formatArgs struct {
-		uuid  string
-		path  string
-		force bool
+		uuid        string
+		path        string
+		label       string
+		blockSize   int64
+		mkfsOptions []string
+		force       bool
 	}
 	makeBlockFileArgs struct {
 		path  string
 		major uint32
 		minor uint32
 	}
+	wipeArgs struct {
+		path string
+	}
+	repairArgs struct {
+		path string
+		fix  bool
+	}
+	formatErr       error
+	repairCorrupted bool
+	repairErr       error
 }
 
-func (c *fakeDriveFormatter) FormatDrive(ctx context.Context, uuid, path string, force bool) error {
+func (c *fakeDriveFormatter) FormatDrive(ctx context.Context, uuid, path, label string, blockSize int64, mkfsOptions []string, force bool) error {
 	c.formatArgs.path = path
+	c.formatArgs.label = label
+	c.formatArgs.blockSize = blockSize
+	c.formatArgs.mkfsOptions = mkfsOptions
 	c.formatArgs.force = force
 	c.formatArgs.uuid = uuid
+	return c.formatErr
+}
+
+func (c *fakeDriveFormatter) WipeDrive(ctx context.Context, path string) error {
+	c.wipeArgs.path = path
 	return nil
 }
 
+func (c *fakeDriveFormatter) RepairDrive(ctx context.Context, path string, fix bool) (bool, error) {
+	c.repairArgs.path = path
+	c.repairArgs.fix = fix
+	return c.repairCorrupted, c.repairErr
+}
+
 func (c *fakeDriveFormatter) MakeBlockFile(path string, major, minor uint32) error {
 	c.makeBlockFileArgs.path = path
 	c.makeBlockFileArgs.major = major
@@ -82,18 +130,20 @@ type fakeDriveMounter struct {
 	unmountArgs struct {
 		source string
 	}
+	mountErr   error
+	unmountErr error
 }
 
-func (c *fakeDriveMounter) MountDrive(source, target string, mountOpts []string) error {
+func (c *fakeDriveMounter) MountDrive(ctx context.Context, source, target string, mountOpts []string) error {
 	c.mountArgs.source = source
 	c.mountArgs.target = target
 	c.mountArgs.mountOpts = mountOpts
-	return nil
+	return c.mountErr
 }
 
 func (c *fakeDriveMounter) UnmountDrive(path string) error {
 	c.unmountArgs.source = path
-	return nil
+	return c.unmountErr
 }
 
 func createFakeDriveListener() *DirectCSIDriveListener {
@@ -254,11 +304,18 @@ func TestDriveFormat(t *testing.T) {
 		// Step 3: Set RequestedFormat to enable formatting
 		newObj.Spec.DirectCSIOwned = true
 		force := true
+		mkfsOptions := []string{"su=64k", "sw=4"}
 		newObj.Spec.RequestedFormat = &directcsi.RequestedFormat{
-			Force:      force,
-			Filesystem: string(sys.FSTypeXFS),
+			Force:       force,
+			Filesystem:  string(sys.FSTypeXFS),
+			BlockSize:   8192,
+			MkfsOptions: mkfsOptions,
 		}
 
+		// Step 3.1: Report the device numbers recorded at discovery
+		dl.statter.(*fakeDriveStatter).major = dObj.Status.MajorNumber
+		dl.statter.(*fakeDriveStatter).minor = dObj.Status.MinorNumber
+
 		// Step 4: Execute the Update hook
 		if err := dl.Update(ctx, dObj, newObj); err != nil {
 			t.Errorf("Test case [%d]: Error while invoking the update listener: %+v", i, err)
@@ -285,6 +342,20 @@ func TestDriveFormat(t *testing.T) {
 		if dl.formatter.(*fakeDriveFormatter).formatArgs.force != force {
 			t.Errorf("Test case [%d]: Wrong force option provided for formatting. Expected: %v, Found: %v", i, force, dl.formatter.(*fakeDriveFormatter).formatArgs.force)
 		}
+		expectedLabel := dObj.Name
+		if len(expectedLabel) > xfs.MaxLabelLength {
+			expectedLabel = expectedLabel[:xfs.MaxLabelLength]
+		}
+		if dl.formatter.(*fakeDriveFormatter).formatArgs.label != expectedLabel {
+			t.Errorf("Test case [%d]: Wrong label provided for formatting. Expected: %s, Found: %s", i, expectedLabel, dl.formatter.(*fakeDriveFormatter).formatArgs.label)
+		}
+
+		if dl.formatter.(*fakeDriveFormatter).formatArgs.blockSize != 8192 {
+			t.Errorf("Test case [%d]: Wrong block size provided for formatting. Expected: 8192, Found: %d", i, dl.formatter.(*fakeDriveFormatter).formatArgs.blockSize)
+		}
+		if !reflect.DeepEqual(dl.formatter.(*fakeDriveFormatter).formatArgs.mkfsOptions, mkfsOptions) {
+			t.Errorf("Test case [%d]: Wrong mkfs options provided for formatting. Expected: %v, Found: %v", i, mkfsOptions, dl.formatter.(*fakeDriveFormatter).formatArgs.mkfsOptions)
+		}
 
 		// Step 4.2: Check if mount arguments passed are correct
 		if dl.mounter.(*fakeDriveMounter).mountArgs.source != sys.GetDirectCSIPath(dObj.Status.FilesystemUUID) {
@@ -326,6 +397,9 @@ func TestDriveFormat(t *testing.T) {
 		if csiDrive.Status.Filesystem != string(sys.FSTypeXFS) {
 			t.Errorf("Test case [%d]: Invalid filesystem after formatting: %s", i, string(csiDrive.Status.Filesystem))
 		}
+		if csiDrive.Status.FilesystemBlockSize != 8192 {
+			t.Errorf("Test case [%d]: Invalid filesystem block size after formatting: %d", i, csiDrive.Status.FilesystemBlockSize)
+		}
 
 		// Step 7: Check if the expected conditions are set
 		if !utils.IsCondition(csiDrive.Status.Conditions,
@@ -353,6 +427,599 @@ func TestDriveFormat(t *testing.T) {
 	}
 }
 
+func TestDriveFormatDeviceNumberDrift(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test_drive_drifted",
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:       testNodeID,
+			DriveStatus:    directcsi.DriveStatusAvailable,
+			Path:           "/dev/xvdb",
+			Mountpoint:     "/mnt/mp",
+			FilesystemUUID: "test_drive_drifted_uuid",
+			MajorNumber:    202,
+			MinorNumber:    16,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(directcsi.DirectCSIDriveConditionOwned),
+					Status:             metav1.ConditionFalse,
+					Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+					LastTransitionTime: metav1.Now(),
+				},
+				{
+					Type:               string(directcsi.DirectCSIDriveConditionMounted),
+					Status:             metav1.ConditionTrue,
+					Message:            "/mnt/mp",
+					Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+					LastTransitionTime: metav1.Now(),
+				},
+				{
+					Type:               string(directcsi.DirectCSIDriveConditionFormatted),
+					Status:             metav1.ConditionFalse,
+					Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+					LastTransitionTime: metav1.Now(),
+				},
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	dl := createFakeDriveListener()
+	dl.directcsiClient = fakedirect.NewSimpleClientset(testDriveObj)
+	directCSIClient := dl.directcsiClient.DirectV1beta2()
+
+	// Another device took the name after the discovery
+	dl.statter.(*fakeDriveStatter).major = 202
+	dl.statter.(*fakeDriveStatter).minor = 32
+
+	newObj, err := directCSIClient.DirectCSIDrives().Get(ctx, testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while getting the drive object: %+v", err)
+	}
+	newObj.Spec.DirectCSIOwned = true
+	newObj.Spec.RequestedFormat = &directcsi.RequestedFormat{
+		Force:      true,
+		Filesystem: string(sys.FSTypeXFS),
+	}
+
+	if err := dl.Update(ctx, testDriveObj, newObj); err != nil {
+		t.Fatalf("Error while invoking the update listener: %+v", err)
+	}
+
+	if devName := dl.statter.(*fakeDriveStatter).args.devName; devName != "xvdb" {
+		t.Errorf("Invalid device name provided for reading the device numbers. Expected: xvdb, Found: %s", devName)
+	}
+	if path := dl.formatter.(*fakeDriveFormatter).formatArgs.path; path != "" {
+		t.Errorf("Expected the drifted drive not to be formatted, but %s was formatted", path)
+	}
+	if source := dl.mounter.(*fakeDriveMounter).unmountArgs.source; source != "" {
+		t.Errorf("Expected the drifted drive not to be unmounted, but %s was unmounted", source)
+	}
+
+	csiDrive, err := directCSIClient.DirectCSIDrives().Get(ctx, newObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while fetching the drive object: %+v", err)
+	}
+	if csiDrive.Status.DriveStatus != directcsi.DriveStatusAvailable {
+		t.Errorf("Expected drive status %s, got %s", directcsi.DriveStatusAvailable, csiDrive.Status.DriveStatus)
+	}
+	for _, c := range csiDrive.Status.Conditions {
+		if c.Type != string(directcsi.DirectCSIDriveConditionOwned) {
+			continue
+		}
+		if c.Status != metav1.ConditionFalse {
+			t.Errorf("Expected condition %s to be false, got %s", c.Type, c.Status)
+		}
+		if !strings.Contains(c.Message, "changed from 202:16 to 202:32") {
+			t.Errorf("Expected the device number drift in the condition message, got %q", c.Message)
+		}
+	}
+}
+
+func TestDriveFormatPartition(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test_drive_partition",
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:       testNodeID,
+			DriveStatus:    directcsi.DriveStatusAvailable,
+			Path:           "/var/lib/direct-csi/devices/xvdb-part-1",
+			FilesystemUUID: "test_drive_partition_uuid",
+			PartitionNum:   1,
+			MajorNumber:    202,
+			MinorNumber:    17,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(directcsi.DirectCSIDriveConditionOwned),
+					Status:             metav1.ConditionFalse,
+					Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+					LastTransitionTime: metav1.Now(),
+				},
+				{
+					Type:               string(directcsi.DirectCSIDriveConditionMounted),
+					Status:             metav1.ConditionFalse,
+					Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+					LastTransitionTime: metav1.Now(),
+				},
+				{
+					Type:               string(directcsi.DirectCSIDriveConditionFormatted),
+					Status:             metav1.ConditionFalse,
+					Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+					LastTransitionTime: metav1.Now(),
+				},
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	dl := createFakeDriveListener()
+	dl.directcsiClient = fakedirect.NewSimpleClientset(testDriveObj)
+	directCSIClient := dl.directcsiClient.DirectV1beta2()
+	dl.statter.(*fakeDriveStatter).major = 202
+	dl.statter.(*fakeDriveStatter).minor = 17
+
+	newObj, err := directCSIClient.DirectCSIDrives().Get(ctx, testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while getting the drive object: %+v", err)
+	}
+	newObj.Spec.DirectCSIOwned = true
+	newObj.Spec.RequestedFormat = &directcsi.RequestedFormat{
+		Force:      true,
+		Filesystem: string(sys.FSTypeXFS),
+	}
+
+	if err := dl.Update(ctx, testDriveObj, newObj); err != nil {
+		t.Fatalf("Error while invoking the update listener: %+v", err)
+	}
+
+	// the device numbers of the partition are read from its kernel name
+	if devName := dl.statter.(*fakeDriveStatter).args.devName; devName != "xvdb1" {
+		t.Errorf("Invalid device name provided for reading the device numbers. Expected: xvdb1, Found: %s", devName)
+	}
+	if path := dl.formatter.(*fakeDriveFormatter).formatArgs.path; path == "" {
+		t.Errorf("Expected the partition to be formatted")
+	}
+}
+
+func TestDriveFormatFailureReasons(t1 *testing.T) {
+	testCases := []struct {
+		name              string
+		formatErr         error
+		mountErr          error
+		unmountErr        error
+		busyErr           error
+		mountpoint        string
+		force             bool
+		expectedOwned     directcsi.DirectCSIDriveReason
+		expectedFormatted directcsi.DirectCSIDriveReason
+		expectedMounted   directcsi.DirectCSIDriveReason
+	}{
+		{
+			name:              "formatfailed",
+			formatErr:         errors.New("mkfs.xfs: invalid option"),
+			expectedOwned:     directcsi.DirectCSIDriveReasonFormatFailed,
+			expectedFormatted: directcsi.DirectCSIDriveReasonFormatFailed,
+			expectedMounted:   directcsi.DirectCSIDriveReasonAdded,
+		},
+		{
+			name:              "devicebusy",
+			formatErr:         fmt.Errorf("mkfs.xfs: cannot open /dev/sdb: %w", syscall.EBUSY),
+			expectedOwned:     directcsi.DirectCSIDriveReasonDeviceBusy,
+			expectedFormatted: directcsi.DirectCSIDriveReasonDeviceBusy,
+			expectedMounted:   directcsi.DirectCSIDriveReasonAdded,
+		},
+		{
+			// only the errno classifies a failure, not the words of the message
+			name:              "busymessage",
+			formatErr:         errors.New("mkfs.xfs: agcount too large, the log is busy"),
+			expectedOwned:     directcsi.DirectCSIDriveReasonFormatFailed,
+			expectedFormatted: directcsi.DirectCSIDriveReasonFormatFailed,
+			expectedMounted:   directcsi.DirectCSIDriveReasonAdded,
+		},
+		{
+			name:              "deviceinuse",
+			busyErr:           fmt.Errorf("device sdb is opened by 1234 (postgres): %w", syscall.EBUSY),
+			force:             true,
+			expectedOwned:     directcsi.DirectCSIDriveReasonDeviceBusy,
+			expectedFormatted: directcsi.DirectCSIDriveReasonDeviceBusy,
+			expectedMounted:   directcsi.DirectCSIDriveReasonAdded,
+		},
+		{
+			name:              "mountfailed",
+			mountErr:          syscall.EINVAL,
+			expectedOwned:     directcsi.DirectCSIDriveReasonMountFailed,
+			expectedFormatted: directcsi.DirectCSIDriveReasonAdded,
+			expectedMounted:   directcsi.DirectCSIDriveReasonMountFailed,
+		},
+		{
+			name:              "unmountfailed",
+			unmountErr:        syscall.EINVAL,
+			mountpoint:        "/var/lib/direct-csi/mnt/sdb",
+			force:             true,
+			expectedOwned:     directcsi.DirectCSIDriveReasonMountFailed,
+			expectedFormatted: directcsi.DirectCSIDriveReasonAdded,
+			expectedMounted:   directcsi.DirectCSIDriveReasonMountFailed,
+		},
+		{
+			name:              "unmountbusy",
+			unmountErr:        syscall.EBUSY,
+			mountpoint:        "/var/lib/direct-csi/mnt/sdb",
+			force:             true,
+			expectedOwned:     directcsi.DirectCSIDriveReasonDeviceBusy,
+			expectedFormatted: directcsi.DirectCSIDriveReasonAdded,
+			expectedMounted:   directcsi.DirectCSIDriveReasonDeviceBusy,
+		},
+		{
+			name:              "readonly",
+			mountErr:          syscall.EROFS,
+			expectedOwned:     directcsi.DirectCSIDriveReasonReadOnly,
+			expectedFormatted: directcsi.DirectCSIDriveReasonAdded,
+			expectedMounted:   directcsi.DirectCSIDriveReasonReadOnly,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			testDriveObj := &directcsi.DirectCSIDrive{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test_drive_" + tt.name,
+				},
+				Spec: directcsi.DirectCSIDriveSpec{
+					DirectCSIOwned: true,
+					RequestedFormat: &directcsi.RequestedFormat{
+						Filesystem: string(sys.FSTypeXFS),
+						Force:      tt.force,
+					},
+				},
+				Status: directcsi.DirectCSIDriveStatus{
+					NodeName:    testNodeID,
+					DriveStatus: directcsi.DriveStatusAvailable,
+					Path:        "/dev/sdb",
+					Mountpoint:  tt.mountpoint,
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(directcsi.DirectCSIDriveConditionOwned),
+							Status: metav1.ConditionFalse,
+							Reason: string(directcsi.DirectCSIDriveReasonNotAdded),
+						},
+						{
+							Type:   string(directcsi.DirectCSIDriveConditionMounted),
+							Status: metav1.ConditionFalse,
+							Reason: string(directcsi.DirectCSIDriveReasonNotAdded),
+						},
+						{
+							Type:   string(directcsi.DirectCSIDriveConditionFormatted),
+							Status: metav1.ConditionFalse,
+							Reason: string(directcsi.DirectCSIDriveReasonNotAdded),
+						},
+					},
+				},
+			}
+
+			ctx := context.TODO()
+			dl := createFakeDriveListener()
+			dl.directcsiClient = fakedirect.NewSimpleClientset(testDriveObj)
+			dl.formatter.(*fakeDriveFormatter).formatErr = tt.formatErr
+			dl.mounter.(*fakeDriveMounter).mountErr = tt.mountErr
+			dl.mounter.(*fakeDriveMounter).unmountErr = tt.unmountErr
+			dl.statter.(*fakeDriveStatter).busyErr = tt.busyErr
+			directCSIClient := dl.directcsiClient.DirectV1beta2()
+
+			if err := dl.Update(ctx, testDriveObj, testDriveObj.DeepCopy()); err != nil {
+				t1.Fatalf("Test case name %s: Error while invoking the update listener: %+v", tt.name, err)
+			}
+			if path := dl.formatter.(*fakeDriveFormatter).formatArgs.path; tt.busyErr != nil && path != "" {
+				t1.Errorf("Test case name %s: Expected the busy drive not to be formatted, but %s was formatted", tt.name, path)
+			}
+
+			csiDrive, err := directCSIClient.DirectCSIDrives().Get(ctx, testDriveObj.Name, metav1.GetOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			})
+			if err != nil {
+				t1.Fatalf("Test case name %s: Error while fetching the drive object: %+v", tt.name, err)
+			}
+			expectedReasons := map[string]directcsi.DirectCSIDriveReason{
+				string(directcsi.DirectCSIDriveConditionOwned):     tt.expectedOwned,
+				string(directcsi.DirectCSIDriveConditionFormatted): tt.expectedFormatted,
+				string(directcsi.DirectCSIDriveConditionMounted):   tt.expectedMounted,
+			}
+			for _, c := range csiDrive.Status.Conditions {
+				if c.Reason != string(expectedReasons[c.Type]) {
+					t1.Errorf("Test case name %s: Expected reason %s for condition %s, got %s", tt.name, expectedReasons[c.Type], c.Type, c.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestDriveAdopt(t *testing.T) {
+	createTestDrive := func(name, fsType string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					directcsi.DirectCSIDriveAnnotationAdopt: "true",
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:       testNodeID,
+				DriveStatus:    directcsi.DriveStatusAvailable,
+				Path:           "/dev/xvdb",
+				Filesystem:     fsType,
+				FilesystemUUID: name + "_uuid",
+				TotalCapacity:  1000,
+				MajorNumber:    202,
+				MinorNumber:    16,
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(directcsi.DirectCSIDriveConditionOwned),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+						LastTransitionTime: metav1.Now(),
+					},
+					{
+						Type:               string(directcsi.DirectCSIDriveConditionMounted),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+						LastTransitionTime: metav1.Now(),
+					},
+					{
+						Type:               string(directcsi.DirectCSIDriveConditionFormatted),
+						Status:             metav1.ConditionTrue,
+						Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+						LastTransitionTime: metav1.Now(),
+					},
+				},
+			},
+		}
+	}
+
+	duplicateDrive := createTestDrive("test_drive_duplicate", string(sys.FSTypeXFS))
+	duplicateDrive.Status.FilesystemUUID = "test_drive_xfs_uuid"
+	duplicateDrive.Status.MinorNumber = 32
+
+	testCases := []struct {
+		name           string
+		drive          *directcsi.DirectCSIDrive
+		otherDrive     *directcsi.DirectCSIDrive
+		expectedStatus directcsi.DriveStatus
+		expectMount    bool
+	}{
+		{
+			name:           "xfs",
+			drive:          createTestDrive("test_drive_xfs", string(sys.FSTypeXFS)),
+			expectedStatus: directcsi.DriveStatusReady,
+			expectMount:    true,
+		},
+		{
+			name:           "ext4",
+			drive:          createTestDrive("test_drive_ext4", "ext4"),
+			expectedStatus: directcsi.DriveStatusAvailable,
+			expectMount:    false,
+		},
+		{
+			name:           "duplicateuuid",
+			drive:          duplicateDrive,
+			otherDrive:     createTestDrive("test_drive_xfs", string(sys.FSTypeXFS)),
+			expectedStatus: directcsi.DriveStatusAvailable,
+			expectMount:    false,
+		},
+	}
+
+	ctx := context.TODO()
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			dl := createFakeDriveListener()
+			objects := []runtime.Object{tt.drive}
+			if tt.otherDrive != nil {
+				objects = append(objects, tt.otherDrive)
+			}
+			dl.directcsiClient = fakedirect.NewSimpleClientset(objects...)
+			directCSIClient := dl.directcsiClient.DirectV1beta2()
+			dl.statter.(*fakeDriveStatter).major = tt.drive.Status.MajorNumber
+			dl.statter.(*fakeDriveStatter).minor = tt.drive.Status.MinorNumber
+			dl.statter.(*fakeDriveStatter).freeCapacity = 800
+
+			newObj, err := directCSIClient.DirectCSIDrives().Get(ctx, tt.drive.Name, metav1.GetOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			})
+			if err != nil {
+				t.Fatalf("Test case name %s: error while getting the drive object: %+v", tt.name, err)
+			}
+			newObj.Spec.DirectCSIOwned = true
+			// force must not make an adopted drive formatted
+			newObj.Spec.RequestedFormat = &directcsi.RequestedFormat{
+				Force:           true,
+				ReservedPercent: 10,
+			}
+
+			if err := dl.Update(ctx, tt.drive, newObj); err != nil {
+				t.Fatalf("Test case name %s: error while invoking the update listener: %+v", tt.name, err)
+			}
+
+			if path := dl.formatter.(*fakeDriveFormatter).formatArgs.path; path != "" {
+				t.Errorf("Test case name %s: expected the adopted drive not to be formatted, but %s was formatted", tt.name, path)
+			}
+			source := dl.mounter.(*fakeDriveMounter).mountArgs.source
+			if tt.expectMount && source != sys.GetDirectCSIPath(tt.drive.Status.FilesystemUUID) {
+				t.Errorf("Test case name %s: invalid source provided for mounting. Expected: %s, Found: %s", tt.name, sys.GetDirectCSIPath(tt.drive.Status.FilesystemUUID), source)
+			}
+			if !tt.expectMount && source != "" {
+				t.Errorf("Test case name %s: expected the drive not to be mounted, but %s was mounted", tt.name, source)
+			}
+			// the device file of the drive owning the uuid must not be replaced
+			if path := dl.formatter.(*fakeDriveFormatter).makeBlockFileArgs.path; tt.otherDrive != nil && path != "" {
+				t.Errorf("Test case name %s: expected no device file to be created, but %s was created", tt.name, path)
+			}
+
+			csiDrive, err := directCSIClient.DirectCSIDrives().Get(ctx, newObj.Name, metav1.GetOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			})
+			if err != nil {
+				t.Fatalf("Test case name %s: error while fetching the drive object: %+v", tt.name, err)
+			}
+			if csiDrive.Status.DriveStatus != tt.expectedStatus {
+				t.Errorf("Test case name %s: expected drive status %s, got %s", tt.name, tt.expectedStatus, csiDrive.Status.DriveStatus)
+			}
+			if csiDrive.Status.FilesystemUUID != tt.drive.Status.FilesystemUUID {
+				t.Errorf("Test case name %s: expected the filesystem uuid %s to be retained, got %s", tt.name, tt.drive.Status.FilesystemUUID, csiDrive.Status.FilesystemUUID)
+			}
+			// the reserved headroom is neither free nor allocated
+			if tt.expectMount && (csiDrive.Status.ReservedCapacity != 100 || csiDrive.Status.FreeCapacity != 700 || csiDrive.Status.AllocatedCapacity != 200) {
+				t.Errorf("Test case name %s: expected reserved 100, free 700 and allocated 200, got %d %d %d", tt.name, csiDrive.Status.ReservedCapacity, csiDrive.Status.FreeCapacity, csiDrive.Status.AllocatedCapacity)
+			}
+			// the adopt request is cleared whether it succeeded or not
+			if _, annotated := csiDrive.GetAnnotations()[directcsi.DirectCSIDriveAnnotationAdopt]; annotated {
+				t.Errorf("Test case name %s: unexpected adopt annotation %v on the drive", tt.name, csiDrive.GetAnnotations())
+			}
+			if csiDrive.Spec.RequestedFormat != nil || csiDrive.Spec.DirectCSIOwned != tt.expectMount {
+				t.Errorf("Test case name %s: expected owned %v without a requested format, got %v and %v", tt.name, tt.expectMount, csiDrive.Spec.DirectCSIOwned, csiDrive.Spec.RequestedFormat)
+			}
+		})
+	}
+}
+
+func TestDriveAdoptRepair(t1 *testing.T) {
+	createTestDrive := func(name, repair string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					directcsi.DirectCSIDriveAnnotationAdopt:  "true",
+					directcsi.DirectCSIDriveAnnotationRepair: repair,
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:       testNodeID,
+				DriveStatus:    directcsi.DriveStatusAvailable,
+				Path:           "/dev/xvdb",
+				Filesystem:     string(sys.FSTypeXFS),
+				FilesystemUUID: name + "_uuid",
+				MajorNumber:    202,
+				MinorNumber:    16,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(directcsi.DirectCSIDriveConditionOwned),
+						Status: metav1.ConditionFalse,
+						Reason: string(directcsi.DirectCSIDriveReasonNotAdded),
+					},
+					{
+						Type:   string(directcsi.DirectCSIDriveConditionMounted),
+						Status: metav1.ConditionFalse,
+						Reason: string(directcsi.DirectCSIDriveReasonNotAdded),
+					},
+					{
+						Type:   string(directcsi.DirectCSIDriveConditionFormatted),
+						Status: metav1.ConditionTrue,
+						Reason: string(directcsi.DirectCSIDriveReasonNotAdded),
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		repair         string
+		corrupted      bool
+		repairErr      error
+		expectedFix    bool
+		expectedStatus directcsi.DriveStatus
+		expectedReason directcsi.DirectCSIDriveReason
+	}{
+		{
+			name:           "check_clean",
+			repair:         directcsi.DirectCSIDriveRepairCheck,
+			expectedStatus: directcsi.DriveStatusReady,
+			expectedReason: directcsi.DirectCSIDriveReasonFilesystemChecked,
+		},
+		{
+			name:           "check_corrupted",
+			repair:         directcsi.DirectCSIDriveRepairCheck,
+			corrupted:      true,
+			expectedStatus: directcsi.DriveStatusAvailable,
+			expectedReason: directcsi.DirectCSIDriveReasonFilesystemCorrupted,
+		},
+		{
+			name:           "repair_corrupted",
+			repair:         directcsi.DirectCSIDriveRepairFix,
+			corrupted:      true,
+			expectedFix:    true,
+			expectedStatus: directcsi.DriveStatusReady,
+			expectedReason: directcsi.DirectCSIDriveReasonRepaired,
+		},
+		{
+			name:           "repair_failed",
+			repair:         directcsi.DirectCSIDriveRepairFix,
+			corrupted:      true,
+			repairErr:      errors.New("xfs_repair failed"),
+			expectedFix:    true,
+			expectedStatus: directcsi.DriveStatusAvailable,
+			expectedReason: directcsi.DirectCSIDriveReasonRepairFailed,
+		},
+	}
+
+	ctx := context.TODO()
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t *testing.T) {
+			drive := createTestDrive("test_drive_"+tt.name, tt.repair)
+			dl := createFakeDriveListener()
+			dl.directcsiClient = fakedirect.NewSimpleClientset(drive)
+			formatter := dl.formatter.(*fakeDriveFormatter)
+			formatter.repairCorrupted = tt.corrupted
+			formatter.repairErr = tt.repairErr
+
+			newObj := drive.DeepCopy()
+			newObj.Spec.DirectCSIOwned = true
+			newObj.Spec.RequestedFormat = &directcsi.RequestedFormat{}
+			if err := dl.Update(ctx, drive, newObj); err != nil {
+				t.Fatalf("error while invoking the update listener: %v", err)
+			}
+
+			source := sys.GetDirectCSIPath(drive.Status.FilesystemUUID)
+			if formatter.repairArgs.path != source {
+				t.Errorf("expected the filesystem of %s to be checked, got %q", source, formatter.repairArgs.path)
+			}
+			if formatter.repairArgs.fix != tt.expectedFix {
+				t.Errorf("expected fix %v, got %v", tt.expectedFix, formatter.repairArgs.fix)
+			}
+			mounted := dl.mounter.(*fakeDriveMounter).mountArgs.source != ""
+			if mounted != (tt.expectedStatus == directcsi.DriveStatusReady) {
+				t.Errorf("unexpected mount of the drive: %v", mounted)
+			}
+
+			csiDrive, err := dl.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(ctx, drive.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error while fetching the drive object: %v", err)
+			}
+			if csiDrive.Status.DriveStatus != tt.expectedStatus {
+				t.Errorf("expected drive status %s, got %s", tt.expectedStatus, csiDrive.Status.DriveStatus)
+			}
+			for _, c := range csiDrive.Status.Conditions {
+				if c.Type == string(directcsi.DirectCSIDriveConditionFormatted) && c.Reason != string(tt.expectedReason) {
+					t.Errorf("expected the formatted condition reason %s, got %s", tt.expectedReason, c.Reason)
+				}
+			}
+			if _, annotated := csiDrive.GetAnnotations()[directcsi.DirectCSIDriveAnnotationRepair]; annotated {
+				t.Errorf("unexpected repair annotation %v on the drive", csiDrive.GetAnnotations())
+			}
+		})
+	}
+}
+
 func TestUpdateDriveDelete(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -446,3 +1113,462 @@ func TestUpdateDriveDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateDriveRelease(t *testing.T) {
+	newReadyDrive := func(name string, finalizers []string, annotations map[string]string) directcsi.DirectCSIDrive {
+		return directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Finalizers:  finalizers,
+				Annotations: annotations,
+			},
+			Spec: directcsi.DirectCSIDriveSpec{
+				DirectCSIOwned: true,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          testNodeID,
+				DriveStatus:       directcsi.DriveStatusReady,
+				Path:              "/drive/path",
+				Filesystem:        string(sys.FSTypeXFS),
+				FilesystemUUID:    name + "_uuid",
+				Mountpoint:        filepath.Join(sys.MountRoot, name+"_uuid"),
+				TotalCapacity:     100 << 20,
+				AllocatedCapacity: 20 << 20,
+				FreeCapacity:      80 << 20,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(directcsi.DirectCSIDriveConditionOwned),
+						Status: metav1.ConditionTrue,
+						Reason: string(directcsi.DirectCSIDriveReasonAdded),
+					},
+					{
+						Type:    string(directcsi.DirectCSIDriveConditionMounted),
+						Status:  metav1.ConditionTrue,
+						Reason:  string(directcsi.DirectCSIDriveReasonAdded),
+						Message: string(directcsi.DirectCSIDriveMessageMounted),
+					},
+					{
+						Type:    string(directcsi.DirectCSIDriveConditionFormatted),
+						Status:  metav1.ConditionTrue,
+						Reason:  string(directcsi.DirectCSIDriveReasonAdded),
+						Message: string(directcsi.DirectCSIDriveMessageFormatted),
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                 string
+		driveObject          directcsi.DirectCSIDrive
+		expectedDriveStatus  directcsi.DriveStatus
+		expectedFilesystem   string
+		expectedFormatStatus metav1.ConditionStatus
+		expectWipe           bool
+	}{
+		{
+			name:                 "testReleaseWithoutWipe",
+			driveObject:          newReadyDrive("test_drive_1", []string{directcsi.DirectCSIDriveFinalizerDataProtection}, nil),
+			expectedDriveStatus:  directcsi.DriveStatusAvailable,
+			expectedFilesystem:   string(sys.FSTypeXFS),
+			expectedFormatStatus: metav1.ConditionTrue,
+		},
+		{
+			name: "testReleaseWithWipe",
+			driveObject: newReadyDrive("test_drive_2", []string{directcsi.DirectCSIDriveFinalizerDataProtection}, map[string]string{
+				directcsi.DirectCSIDriveAnnotationWipe: "true",
+			}),
+			expectedDriveStatus:  directcsi.DriveStatusAvailable,
+			expectedFilesystem:   "",
+			expectedFormatStatus: metav1.ConditionFalse,
+			expectWipe:           true,
+		},
+		{
+			name: "testReleaseWithVolumes",
+			driveObject: newReadyDrive("test_drive_3", []string{
+				directcsi.DirectCSIDriveFinalizerPrefix + "vol_id",
+				directcsi.DirectCSIDriveFinalizerDataProtection,
+			}, nil),
+			expectedDriveStatus:  directcsi.DriveStatusReady,
+			expectedFilesystem:   string(sys.FSTypeXFS),
+			expectedFormatStatus: metav1.ConditionTrue,
+		},
+	}
+
+	ctx := context.TODO()
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			dl := createFakeDriveListener()
+			dl.directcsiClient = fakedirect.NewSimpleClientset(&tt.driveObject)
+			directCSIClient := dl.directcsiClient.DirectV1beta2()
+
+			newObj := tt.driveObject.DeepCopy()
+			newObj.Spec.DirectCSIOwned = false
+			if err := dl.Update(ctx, &tt.driveObject, newObj); err != nil {
+				t.Fatalf("Error while invoking the update listener: %+v", err)
+			}
+
+			csiDrive, dErr := directCSIClient.DirectCSIDrives().Get(ctx, newObj.Name, metav1.GetOptions{
+				TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			})
+			if dErr != nil {
+				t.Fatalf("Error while fetching the drive object: %+v", dErr)
+			}
+
+			if csiDrive.Status.DriveStatus != tt.expectedDriveStatus {
+				t.Errorf("Expected drive status: %s but got: %s", tt.expectedDriveStatus, csiDrive.Status.DriveStatus)
+			}
+			if csiDrive.Status.Filesystem != tt.expectedFilesystem {
+				t.Errorf("Expected filesystem: %s but got: %s", tt.expectedFilesystem, csiDrive.Status.Filesystem)
+			}
+
+			wipePath := dl.formatter.(*fakeDriveFormatter).wipeArgs.path
+			if tt.expectWipe && wipePath != sys.GetDirectCSIPath(tt.driveObject.Status.FilesystemUUID) {
+				t.Errorf("Invalid path provided for wiping. Expected: %s, Found: %s", sys.GetDirectCSIPath(tt.driveObject.Status.FilesystemUUID), wipePath)
+			}
+			if !tt.expectWipe && wipePath != "" {
+				t.Errorf("Drive was wiped unexpectedly")
+			}
+
+			if tt.expectedDriveStatus != directcsi.DriveStatusAvailable {
+				if dl.mounter.(*fakeDriveMounter).unmountArgs.source != "" {
+					t.Errorf("Drive with volumes was unmounted unexpectedly")
+				}
+				return
+			}
+
+			if csiDrive.Status.Mountpoint != "" {
+				t.Errorf("Drive mountpoint not cleared: %s", csiDrive.Status.Mountpoint)
+			}
+			if csiDrive.Status.AllocatedCapacity != 0 {
+				t.Errorf("Expected allocated capacity to be reset but got: %d", csiDrive.Status.AllocatedCapacity)
+			}
+			if len(csiDrive.GetFinalizers()) != 0 {
+				t.Errorf("Expected no finalizers but got: %v", csiDrive.GetFinalizers())
+			}
+			if !utils.IsCondition(csiDrive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionOwned),
+				metav1.ConditionFalse,
+				string(directcsi.DirectCSIDriveReasonNotAdded),
+				"") {
+				t.Errorf("unexpected status.condition for %s = %v", string(directcsi.DirectCSIDriveConditionOwned), csiDrive.Status.Conditions)
+			}
+			if !utils.IsCondition(csiDrive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionMounted),
+				metav1.ConditionFalse,
+				string(directcsi.DirectCSIDriveReasonNotAdded),
+				string(directcsi.DirectCSIDriveMessageNotMounted)) {
+				t.Errorf("unexpected status.condition for %s = %v", string(directcsi.DirectCSIDriveConditionMounted), csiDrive.Status.Conditions)
+			}
+			if !utils.IsConditionStatus(csiDrive.Status.Conditions, string(directcsi.DirectCSIDriveConditionFormatted), tt.expectedFormatStatus) {
+				t.Errorf("unexpected status.condition for %s = %v", string(directcsi.DirectCSIDriveConditionFormatted), csiDrive.Status.Conditions)
+			}
+		})
+	}
+}
+
+func TestDriveFormatUpdateConflict(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test_drive_conflict",
+		},
+		Spec: directcsi.DirectCSIDriveSpec{
+			DirectCSIOwned: true,
+			RequestedFormat: &directcsi.RequestedFormat{
+				Filesystem: string(sys.FSTypeXFS),
+			},
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:      testNodeID,
+			DriveStatus:   directcsi.DriveStatusAvailable,
+			Path:          "/dev/sdb",
+			TotalCapacity: 100,
+		},
+	}
+
+	ctx := context.TODO()
+	dl := createFakeDriveListener()
+	fakeClient := fakedirect.NewSimpleClientset(testDriveObj)
+	dl.directcsiClient = fakeClient
+
+	// the discovery updates the drive while it is being formatted
+	conflicts := 0
+	fakeClient.PrependReactor("update", "directcsidrives", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		discovered := testDriveObj.DeepCopy()
+		discovered.Status.Path = "/dev/sdc"
+		if err := fakeClient.Tracker().Update(directcsi.SchemeGroupVersion.WithResource("directcsidrives"), discovered, ""); err != nil {
+			t.Fatalf("Error while updating the drive object: %v", err)
+		}
+		return true, nil, apierrors.NewConflict(directcsi.Resource("directcsidrives"), testDriveObj.Name, errors.New("object was modified"))
+	})
+
+	if err := dl.Update(ctx, testDriveObj, testDriveObj.DeepCopy()); err != nil {
+		t.Fatalf("Error while invoking the update listener: %+v", err)
+	}
+
+	csiDrive, err := fakeClient.DirectV1beta2().DirectCSIDrives().Get(ctx, testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while fetching the drive object: %+v", err)
+	}
+	if conflicts != 1 {
+		t.Errorf("Expected a single conflict, got %d", conflicts)
+	}
+	if csiDrive.Status.DriveStatus != directcsi.DriveStatusReady {
+		t.Errorf("Expected drive status %s, got %s", directcsi.DriveStatusReady, csiDrive.Status.DriveStatus)
+	}
+	if csiDrive.Status.Mountpoint == "" || csiDrive.Status.Filesystem != string(sys.FSTypeXFS) {
+		t.Errorf("Expected the drive to be formatted and mounted, got filesystem %q mountpoint %q", csiDrive.Status.Filesystem, csiDrive.Status.Mountpoint)
+	}
+	if !reflect.DeepEqual(csiDrive.Finalizers, []string{directcsi.DirectCSIDriveFinalizerDataProtection}) {
+		t.Errorf("Expected the data protection finalizer, got %v", csiDrive.Finalizers)
+	}
+	if csiDrive.Status.Path != "/dev/sdc" {
+		t.Errorf("Expected the concurrent update of the path to be retained, got %s", csiDrive.Status.Path)
+	}
+}
+
+func TestDriveReleaseUpdateConflict(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test_drive_release_conflict",
+			Finalizers: []string{directcsi.DirectCSIDriveFinalizerDataProtection},
+		},
+		Spec: directcsi.DirectCSIDriveSpec{
+			DirectCSIOwned: true,
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:       testNodeID,
+			DriveStatus:    directcsi.DriveStatusReady,
+			Path:           "/dev/sdb",
+			Filesystem:     string(sys.FSTypeXFS),
+			FilesystemUUID: "test_drive_release_conflict_uuid",
+			Mountpoint:     filepath.Join(sys.MountRoot, "test_drive_release_conflict_uuid"),
+			TotalCapacity:  100 << 20,
+			FreeCapacity:   100 << 20,
+		},
+	}
+	newObj := testDriveObj.DeepCopy()
+	newObj.Spec.DirectCSIOwned = false
+
+	ctx := context.TODO()
+	dl := createFakeDriveListener()
+	fakeClient := fakedirect.NewSimpleClientset(newObj)
+	dl.directcsiClient = fakeClient
+
+	// a volume is scheduled on the drive while it is being released
+	conflicts := 0
+	fakeClient.PrependReactor("update", "directcsidrives", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		inUse := newObj.DeepCopy()
+		inUse.Finalizers = append(inUse.Finalizers, directcsi.DirectCSIDriveFinalizerPrefix+"vol_id")
+		inUse.Status.DriveStatus = directcsi.DriveStatusInUse
+		inUse.Status.AllocatedCapacity = 20 << 20
+		if err := fakeClient.Tracker().Update(directcsi.SchemeGroupVersion.WithResource("directcsidrives"), inUse, ""); err != nil {
+			t.Fatalf("Error while updating the drive object: %v", err)
+		}
+		return true, nil, apierrors.NewConflict(directcsi.Resource("directcsidrives"), testDriveObj.Name, errors.New("object was modified"))
+	})
+
+	if err := dl.Update(ctx, testDriveObj, newObj.DeepCopy()); err == nil {
+		t.Fatalf("Expected the release of the drive with a volume to fail")
+	}
+
+	csiDrive, err := fakeClient.DirectV1beta2().DirectCSIDrives().Get(ctx, testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while fetching the drive object: %+v", err)
+	}
+	if csiDrive.Status.DriveStatus != directcsi.DriveStatusInUse {
+		t.Errorf("Expected drive status %s, got %s", directcsi.DriveStatusInUse, csiDrive.Status.DriveStatus)
+	}
+	if csiDrive.Status.AllocatedCapacity != 20<<20 {
+		t.Errorf("Expected the allocated capacity to be retained, got %d", csiDrive.Status.AllocatedCapacity)
+	}
+	expectedFinalizers := []string{
+		directcsi.DirectCSIDriveFinalizerDataProtection,
+		directcsi.DirectCSIDriveFinalizerPrefix + "vol_id",
+	}
+	if !reflect.DeepEqual(csiDrive.Finalizers, expectedFinalizers) {
+		t.Errorf("Expected finalizers %v, got %v", expectedFinalizers, csiDrive.Finalizers)
+	}
+}
+
+func TestDriveReleaseRefused(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test_drive_release_refused",
+			Finalizers: []string{
+				directcsi.DirectCSIDriveFinalizerDataProtection,
+				directcsi.DirectCSIDriveFinalizerPrefix + "vol_id",
+			},
+		},
+		Spec: directcsi.DirectCSIDriveSpec{
+			DirectCSIOwned: true,
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:       testNodeID,
+			DriveStatus:    directcsi.DriveStatusReady,
+			Path:           "/dev/sdb",
+			Filesystem:     string(sys.FSTypeXFS),
+			FilesystemUUID: "test_drive_release_refused_uuid",
+			Mountpoint:     filepath.Join(sys.MountRoot, "test_drive_release_refused_uuid"),
+			TotalCapacity:  100 << 20,
+			FreeCapacity:   100 << 20,
+		},
+	}
+	newObj := testDriveObj.DeepCopy()
+	newObj.Spec.DirectCSIOwned = false
+
+	ctx := context.TODO()
+	dl := createFakeDriveListener()
+	dl.directcsiClient = fakedirect.NewSimpleClientset(newObj)
+
+	// the refusal is returned so that it is recorded as an event on the drive
+	err := dl.Update(ctx, testDriveObj, newObj.DeepCopy())
+	if err == nil {
+		t.Fatalf("Expected the release of the drive with a volume to be refused")
+	}
+	if !strings.Contains(err.Error(), "vol_id") {
+		t.Errorf("Expected the refusal to name the volume on the drive, got %v", err)
+	}
+
+	csiDrive, err := dl.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(ctx, testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while fetching the drive object: %+v", err)
+	}
+	if csiDrive.Status.Mountpoint != testDriveObj.Status.Mountpoint {
+		t.Errorf("Expected the drive to stay mounted at %s, got %s", testDriveObj.Status.Mountpoint, csiDrive.Status.Mountpoint)
+	}
+	if !reflect.DeepEqual(csiDrive.Finalizers, testDriveObj.Finalizers) {
+		t.Errorf("Expected finalizers %v, got %v", testDriveObj.Finalizers, csiDrive.Finalizers)
+	}
+}
+
+func TestDriveReleaseRefusedOnLatest(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test_drive_release_latest",
+			Finalizers: []string{
+				directcsi.DirectCSIDriveFinalizerDataProtection,
+			},
+			Annotations: map[string]string{
+				directcsi.DirectCSIDriveAnnotationWipe: "true",
+			},
+		},
+		Spec: directcsi.DirectCSIDriveSpec{
+			DirectCSIOwned: true,
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:       testNodeID,
+			DriveStatus:    directcsi.DriveStatusReady,
+			Path:           "/dev/sdb",
+			Filesystem:     string(sys.FSTypeXFS),
+			FilesystemUUID: "test_drive_release_latest_uuid",
+			Mountpoint:     filepath.Join(sys.MountRoot, "test_drive_release_latest_uuid"),
+			TotalCapacity:  100 << 20,
+			FreeCapacity:   100 << 20,
+		},
+	}
+	newObj := testDriveObj.DeepCopy()
+	newObj.Spec.DirectCSIOwned = false
+
+	// a volume was scheduled on the drive after the release was queued
+	latest := newObj.DeepCopy()
+	latest.Finalizers = append(latest.Finalizers, directcsi.DirectCSIDriveFinalizerPrefix+"vol_id")
+	latest.Status.DriveStatus = directcsi.DriveStatusInUse
+
+	ctx := context.TODO()
+	dl := createFakeDriveListener()
+	dl.directcsiClient = fakedirect.NewSimpleClientset(latest)
+	mounter := &fakeDriveMounter{}
+	formatter := &fakeDriveFormatter{}
+	dl.mounter = mounter
+	dl.formatter = formatter
+
+	if err := dl.Update(ctx, testDriveObj, newObj); err == nil {
+		t.Fatalf("Expected the release of the drive with a new volume to be refused")
+	}
+	if mounter.unmountArgs.source != "" {
+		t.Errorf("Expected the drive to stay mounted, got unmounted %s", mounter.unmountArgs.source)
+	}
+	if formatter.wipeArgs.path != "" {
+		t.Errorf("Expected the drive not to be wiped, got wiped %s", formatter.wipeArgs.path)
+	}
+}
+
+func TestDriveFormatConcurrencyLimit(t *testing.T) {
+	testDriveObj := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test_drive_limit",
+		},
+		Spec: directcsi.DirectCSIDriveSpec{
+			DirectCSIOwned: true,
+			RequestedFormat: &directcsi.RequestedFormat{
+				Filesystem: string(sys.FSTypeXFS),
+			},
+		},
+		Status: directcsi.DirectCSIDriveStatus{
+			NodeName:      testNodeID,
+			DriveStatus:   directcsi.DriveStatusAvailable,
+			Path:          "/dev/sdb",
+			TotalCapacity: 100,
+		},
+	}
+
+	dl := createFakeDriveListener()
+	fakeClient := fakedirect.NewSimpleClientset(testDriveObj)
+	dl.directcsiClient = fakeClient
+	dl.formatSlots = make(chan struct{}, 1)
+
+	// another drive holds the only slot
+	dl.formatSlots <- struct{}{}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := dl.Update(ctx, testDriveObj, testDriveObj.DeepCopy()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the update to wait for a format slot, got %v", err)
+	}
+	csiDrive, err := fakeClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while fetching the drive object: %+v", err)
+	}
+	if csiDrive.Status.DriveStatus != directcsi.DriveStatusAvailable {
+		t.Errorf("Expected drive status %s, got %s", directcsi.DriveStatusAvailable, csiDrive.Status.DriveStatus)
+	}
+
+	<-dl.formatSlots
+	if err := dl.Update(context.TODO(), testDriveObj, testDriveObj.DeepCopy()); err != nil {
+		t.Fatalf("Error while invoking the update listener: %+v", err)
+	}
+	csiDrive, err = fakeClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), testDriveObj.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		t.Fatalf("Error while fetching the drive object: %+v", err)
+	}
+	if csiDrive.Status.DriveStatus != directcsi.DriveStatusReady {
+		t.Errorf("Expected drive status %s, got %s", directcsi.DriveStatusReady, csiDrive.Status.DriveStatus)
+	}
+	if len(dl.formatSlots) != 0 {
+		t.Errorf("Expected the format slot to be released, got %d in use", len(dl.formatSlots))
+	}
+}
@@ -0,0 +1,119 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package drive
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/clientset"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/smart"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"k8s.io/klog/v2"
+)
+
+// healthGetter reads the SMART health of the disk at the device path
+type healthGetter func(ctx context.Context, devicePath string) (*smart.Health, error)
+
+// collectSMART records the SMART health of the disks of this node in the status of their drives,
+// the partitions of a disk share its health. The drives are only updated if their health changed.
+func collectSMART(ctx context.Context, directcsiClient clientset.Interface, nodeID string, getHealth healthGetter) error {
+	drivesClient := directcsiClient.DirectV1beta2().DirectCSIDrives()
+	driveList, err := drivesClient.List(ctx, metav1.ListOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+
+	disks := map[string][]string{}
+	for _, drive := range driveList.Items {
+		if drive.Status.NodeName != nodeID || drive.DeletionTimestamp != nil || drive.Status.RootPartition == "" {
+			continue
+		}
+		disks[drive.Status.RootPartition] = append(disks[drive.Status.RootPartition], drive.Name)
+	}
+
+	for disk, driveNames := range disks {
+		health, err := getHealth(ctx, filepath.Join(sys.HostDevRoot, disk))
+		if err != nil {
+			if errors.Is(err, smart.ErrSmartctlNotFound) {
+				return err
+			}
+			klog.V(3).Infof("Unable to read the SMART health of disk %s: %v", disk, err)
+			continue
+		}
+		status := &directcsi.DirectCSIDriveSMART{
+			Passed:             health.Passed,
+			Temperature:        health.Temperature,
+			ReallocatedSectors: health.ReallocatedSectors,
+			PercentageUsed:     health.PercentageUsed,
+		}
+		for _, driveName := range driveNames {
+			if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				drive, err := drivesClient.Get(ctx, driveName, metav1.GetOptions{
+					TypeMeta: utils.DirectCSIDriveTypeMeta(),
+				})
+				if err != nil {
+					return err
+				}
+				if reflect.DeepEqual(drive.Status.SMART, status) {
+					return nil
+				}
+				drive.Status.SMART = status
+				_, err = drivesClient.Update(ctx, drive, metav1.UpdateOptions{
+					TypeMeta: utils.DirectCSIDriveTypeMeta(),
+				})
+				return err
+			}); err != nil && !apierrors.IsNotFound(err) {
+				klog.V(3).Infof("Unable to update the SMART health of drive %s: %v", driveName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// StartSMARTCollector periodically records the SMART health of the drives of this node until ctx is done,
+// it stops if smartctl is not installed
+func StartSMARTCollector(ctx context.Context, nodeID string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := collectSMART(ctx, utils.GetDirectClientset(), nodeID, smart.GetHealth); err != nil {
+			if errors.Is(err, smart.ErrSmartctlNotFound) {
+				klog.Warningf("SMART health of the drives is not collected: %v", err)
+				return
+			}
+			klog.V(3).Infof("Unable to collect the SMART health of the drives: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package drive
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/sys/smart"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCollectSMART(t *testing.T) {
+	newDrive := func(name, nodeName, rootPartition string) runtime.Object {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:      nodeName,
+				RootPartition: rootPartition,
+			},
+		}
+	}
+	client := fakedirect.NewSimpleClientset(
+		newDrive("sda", testNodeID, "sda"),
+		newDrive("sda1", testNodeID, "sda"),
+		newDrive("sdb", testNodeID, "sdb"),
+		newDrive("other-sda", "other-node", "sda"),
+	)
+
+	var devicePaths []string
+	getHealth := func(_ context.Context, devicePath string) (*smart.Health, error) {
+		devicePaths = append(devicePaths, devicePath)
+		if devicePath == "/dev/sdb" {
+			return nil, errors.New("SMART is not supported by the drive")
+		}
+		return &smart.Health{Passed: true, Temperature: 38, ReallocatedSectors: 2, PercentageUsed: -1}, nil
+	}
+	if err := collectSMART(context.Background(), client, testNodeID, getHealth); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(devicePaths) != 2 {
+		t.Errorf("expected the health of 2 disks to be read, got: %v", devicePaths)
+	}
+
+	expected := map[string]*directcsi.DirectCSIDriveSMART{
+		"sda":       {Passed: true, Temperature: 38, ReallocatedSectors: 2, PercentageUsed: -1},
+		"sda1":      {Passed: true, Temperature: 38, ReallocatedSectors: 2, PercentageUsed: -1},
+		"sdb":       nil,
+		"other-sda": nil,
+	}
+	for name, expectedSMART := range expected {
+		drive, err := client.DirectV1beta2().DirectCSIDrives().Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(drive.Status.SMART, expectedSMART) {
+			t.Errorf("drive %s: expected: %+v, got: %+v", name, expectedSMART, drive.Status.SMART)
+		}
+	}
+
+	notFound := func(_ context.Context, _ string) (*smart.Health, error) {
+		return nil, smart.ErrSmartctlNotFound
+	}
+	if err := collectSMART(context.Background(), client, testNodeID, notFound); !errors.Is(err, smart.ErrSmartctlNotFound) {
+		t.Errorf("expected: %v, got: %v", smart.ErrSmartctlNotFound, err)
+	}
+}
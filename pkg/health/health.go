@@ -0,0 +1,122 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+// ReadinessCheck returns an error while the component is not ready
+type ReadinessCheck func() error
+
+var (
+	checksMutex sync.RWMutex
+	checks      = map[string]ReadinessCheck{}
+)
+
+// RegisterReadinessCheck registers the named check of /readyz, an existing check of the same name is replaced
+func RegisterReadinessCheck(name string, check ReadinessCheck) {
+	checksMutex.Lock()
+	defer checksMutex.Unlock()
+	checks[name] = check
+}
+
+// livenessHandler reports that the process is up
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// readinessHandler reports the failing readiness checks, it succeeds only if all of them pass
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	checksMutex.RLock()
+	names := []string{}
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failures := []string{}
+	for _, name := range names {
+		if err := checks[name](); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	checksMutex.RUnlock()
+
+	if len(failures) > 0 {
+		klog.V(5).Infof("Readiness check failed: %s", strings.Join(failures, "; "))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, strings.Join(failures, "\n"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+func healthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(livenessPath, livenessHandler)
+	mux.HandleFunc(readinessPath, readinessHandler)
+	return mux
+}
+
+// ServeHealth serves the liveness and readiness endpoints until ctx is done
+func ServeHealth(ctx context.Context, port int) error {
+	server := &http.Server{
+		Handler: healthHandler(),
+	}
+
+	lc := net.ListenConfig{}
+	listener, lErr := lc.Listen(ctx, "tcp", fmt.Sprintf(":%v", port))
+	if lErr != nil {
+		return lErr
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	klog.V(2).Infof("Starting health server in port: %d", port)
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		klog.Errorf("Failed to listen and serve health server: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t1 *testing.T) {
+	discoveryDone := false
+	RegisterReadinessCheck("discovery", func() error {
+		if !discoveryDone {
+			return errors.New("drive discovery is in progress")
+		}
+		return nil
+	})
+	defer func() {
+		checks = map[string]ReadinessCheck{}
+	}()
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		discoveryDone  bool
+		expectedStatus int
+	}{
+		{
+			name:           "test1",
+			method:         http.MethodGet,
+			path:           livenessPath,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test2",
+			method:         http.MethodGet,
+			path:           readinessPath,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "test3",
+			method:         http.MethodGet,
+			path:           readinessPath,
+			discoveryDone:  true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "test4",
+			method:         http.MethodPost,
+			path:           readinessPath,
+			discoveryDone:  true,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	handler := healthHandler()
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			discoveryDone = tt.discoveryDone
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, httptest.NewRequest(tt.method, tt.path, nil))
+			if response.Code != tt.expectedStatus {
+				t1.Errorf("Test case name %s: expected status %d but got %d (%s)", tt.name, tt.expectedStatus, response.Code, response.Body.String())
+			}
+		})
+	}
+}
@@ -59,6 +59,7 @@ const (
 	volumeNameSysDir          = "sys-fs"
 	volumePathSysDir          = "/sys"
 	volumeNameCSIRootDir      = "direct-csi-common-root"
+	volumeNameMountRootDir    = "direct-csi-mount-root"
 	volumeNameMountpointDir   = "mountpoint-dir"
 	volumeNameRegistrationDir = "registration-dir"
 	volumeNamePluginDir       = "plugins-dir"
@@ -82,8 +83,18 @@ const (
 	healthZContainerPortProtocol = "TCP"
 	healthZContainerPortPath     = "/healthz"
 
+	// health server of the node daemon
+	readinessContainerPort     = 8081
+	readinessContainerPortName = "readyz"
+	readinessContainerPortPath = "/readyz"
+
 	kubeNodeNameEnvVar = "KUBE_NODE_NAME"
 	endpointEnvVarCSI  = "CSI_ENDPOINT"
+	// number of loopback devices reserved by the driver in loopback only mode
+	loopBackCountEnvVar = "LOOPBACK_DEVICE_COUNT"
+	// comma separated glob patterns of the device paths managed or ignored by the driver
+	includeDevicesEnvVar = "INCLUDE_DEVICES"
+	excludeDevicesEnvVar = "EXCLUDE_DEVICES"
 
 	kubeletDirPath = "/var/lib/kubelet"
 	csiRootPath    = "/var/lib/direct-csi/"
@@ -97,6 +108,7 @@ const (
 	validationControllerName       = "directcsi-validation-controller"
 	admissionControllerWebhookName = "validatinghook"
 	ValidationWebhookConfigName    = "drive.validation.controller"
+	pvcValidationWebhookName       = "pvc.validation.controller"
 	admissionControllerWebhookPort = 443
 	certsDir                       = "/etc/certs"
 	admissionWehookDNSName         = "directcsi-validation-controller.direct-csi-min-io.svc"
@@ -118,4 +130,9 @@ const (
 	conversionWebhookCertsSecret = "converionwebhookcertsecret"
 	caCertFileName               = "ca.pem"
 	caDir                        = "/etc/CAs"
+
+	// Metrics
+	metricsTokenVolume = "metrics-token"
+	metricsTokenDir    = "/etc/direct-csi/metrics"
+	metricsTokenKey    = "token"
 )
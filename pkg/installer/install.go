@@ -18,13 +18,16 @@ package installer
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/minio/direct-csi/pkg/converter"
 	"github.com/minio/direct-csi/pkg/topology"
 	"github.com/minio/direct-csi/pkg/utils"
 
@@ -35,6 +38,7 @@ import (
 	storagev1beta1 "k8s.io/api/storage/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
@@ -291,7 +295,21 @@ func CreateDaemonSet(ctx context.Context,
 	directCSIContainerImage string,
 	dryRun bool,
 	registry, org string,
+	imagePullSecrets []string,
+	imagePullPolicy corev1.PullPolicy,
 	loopBackOnly bool,
+	loopBackCount int,
+	includeDevices, excludeDevices []string,
+	minDriveSize, mountRoot string,
+	autoTier, allowRemovable bool,
+	topologyNodeLabels []string,
+	maxVolumesPerNode int64,
+	smartInterval time.Duration,
+	maxConcurrentFormats int,
+	reprobeInterval time.Duration,
+	metricsTokenSecret string,
+	metricsPVCLabels bool,
+	detachBusyMounts bool,
 	nodeSelector map[string]string,
 	tolerations []corev1.Toleration,
 	seccompProfileName, apparmorProfileName string) error {
@@ -362,29 +380,94 @@ func CreateDaemonSet(ctx context.Context,
 						fmt.Sprintf("--endpoint=$(%s)", endpointEnvVarCSI),
 						fmt.Sprintf("--node-id=$(%s)", kubeNodeNameEnvVar),
 						fmt.Sprintf("--conversion-webhook-url=%s", conversionWebhookURL),
+						fmt.Sprintf("--health-port=%d", readinessContainerPort),
 						"--driver",
 					}
 					if loopBackOnly {
 						args = append(args, "--loopback-only")
+						args = append(args, fmt.Sprintf("--loopback-count=$(%s)", loopBackCountEnvVar))
+					}
+					if len(includeDevices) > 0 {
+						args = append(args, fmt.Sprintf("--include-devices=$(%s)", includeDevicesEnvVar))
+					}
+					if len(excludeDevices) > 0 {
+						args = append(args, fmt.Sprintf("--exclude-devices=$(%s)", excludeDevicesEnvVar))
+					}
+					if mountRoot != "" {
+						args = append(args, fmt.Sprintf("--mount-root=%s", mountRoot))
+					}
+					if minDriveSize != "" {
+						args = append(args, fmt.Sprintf("--min-drive-size=%s", minDriveSize))
+					}
+					if autoTier {
+						args = append(args, "--auto-tier")
+					}
+					if allowRemovable {
+						args = append(args, "--allow-removable")
+					}
+					if len(topologyNodeLabels) > 0 {
+						args = append(args, fmt.Sprintf("--topology-node-labels=%s", strings.Join(topologyNodeLabels, ",")))
+					}
+					if maxVolumesPerNode > 0 {
+						args = append(args, fmt.Sprintf("--max-volumes-per-node=%d", maxVolumesPerNode))
+					}
+					if smartInterval > 0 {
+						args = append(args, fmt.Sprintf("--smart-interval=%v", smartInterval))
+					}
+					if maxConcurrentFormats > 0 {
+						args = append(args, fmt.Sprintf("--max-concurrent-formats=%d", maxConcurrentFormats))
+					}
+					if reprobeInterval > 0 {
+						args = append(args, fmt.Sprintf("--reprobe-interval=%v", reprobeInterval))
+					}
+					if metricsTokenSecret != "" {
+						args = append(args, fmt.Sprintf("--metrics-token-file=%s", filepath.Join(metricsTokenDir, metricsTokenKey)))
+					}
+					if metricsPVCLabels {
+						args = append(args, "--metrics-pvc-labels")
+					}
+					if detachBusyMounts {
+						args = append(args, "--detach-busy-mounts")
 					}
 					return args
 				}(),
 				SecurityContext: securityContext,
-				Env: []corev1.EnvVar{
-					{
-						Name: kubeNodeNameEnvVar,
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								APIVersion: "v1",
-								FieldPath:  "spec.nodeName",
+				Env: func() []corev1.EnvVar {
+					env := []corev1.EnvVar{
+						{
+							Name: kubeNodeNameEnvVar,
+							ValueFrom: &corev1.EnvVarSource{
+								FieldRef: &corev1.ObjectFieldSelector{
+									APIVersion: "v1",
+									FieldPath:  "spec.nodeName",
+								},
 							},
 						},
-					},
-					{
-						Name:  endpointEnvVarCSI,
-						Value: "unix:///csi/csi.sock",
-					},
-				},
+						{
+							Name:  endpointEnvVarCSI,
+							Value: "unix:///csi/csi.sock",
+						},
+					}
+					if loopBackOnly {
+						env = append(env, corev1.EnvVar{
+							Name:  loopBackCountEnvVar,
+							Value: strconv.Itoa(loopBackCount),
+						})
+					}
+					if len(includeDevices) > 0 {
+						env = append(env, corev1.EnvVar{
+							Name:  includeDevicesEnvVar,
+							Value: strings.Join(includeDevices, ","),
+						})
+					}
+					if len(excludeDevices) > 0 {
+						env = append(env, corev1.EnvVar{
+							Name:  excludeDevicesEnvVar,
+							Value: strings.Join(excludeDevices, ","),
+						})
+					}
+					return env
+				}(),
 				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				TerminationMessagePath:   "/var/log/driver-termination-log",
 				VolumeMounts: []corev1.VolumeMount{
@@ -401,6 +484,22 @@ func CreateDaemonSet(ctx context.Context,
 						Name:          "healthz",
 						Protocol:      corev1.ProtocolTCP,
 					},
+					{
+						ContainerPort: readinessContainerPort,
+						Name:          readinessContainerPortName,
+						Protocol:      corev1.ProtocolTCP,
+					},
+				},
+				ReadinessProbe: &corev1.Probe{
+					FailureThreshold: 3,
+					TimeoutSeconds:   5,
+					PeriodSeconds:    10,
+					Handler: corev1.Handler{
+						HTTPGet: &corev1.HTTPGetAction{
+							Path: readinessContainerPortPath,
+							Port: intstr.FromString(readinessContainerPortName),
+						},
+					},
 				},
 				LivenessProbe: &corev1.Probe{
 					FailureThreshold:    5,
@@ -432,6 +531,27 @@ func CreateDaemonSet(ctx context.Context,
 		NodeSelector: nodeSelector,
 		Tolerations:  tolerations,
 	}
+	setImagePullOptions(&podSpec, imagePullSecrets, imagePullPolicy)
+
+	// a mount root outside the common root is mounted into the driver on its own
+	if !isUnderCSIRoot(mountRoot) {
+		podSpec.Volumes = append(podSpec.Volumes, newHostPathVolume(volumeNameMountRootDir, mountRoot))
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Name == directCSIContainerName {
+				podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, newVolumeMount(volumeNameMountRootDir, mountRoot, true))
+			}
+		}
+	}
+
+	// the bearer token of the metrics endpoint is read from a user provided secret
+	if metricsTokenSecret != "" {
+		podSpec.Volumes = append(podSpec.Volumes, newSecretVolume(metricsTokenVolume, metricsTokenSecret))
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Name == directCSIContainerName {
+				podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, newVolumeMount(metricsTokenVolume, metricsTokenDir, false))
+			}
+		}
+	}
 
 	annotations := map[string]string{
 		CreatedByLabel: DirectCSIPluginName,
@@ -584,7 +704,7 @@ func CreateOrUpdateConversionCASecret(ctx context.Context, identity string, caCe
 	return nil
 }
 
-func CreateDeployment(ctx context.Context, identity string, directCSIContainerImage string, dryRun bool, registry, org string) error {
+func CreateDeployment(ctx context.Context, identity string, directCSIContainerImage string, dryRun bool, registry, org string, imagePullSecrets []string, imagePullPolicy corev1.PullPolicy) error {
 	name := sanitizeName(identity)
 	generatedSelectorValue := generateSanitizedUniqueNameFrom(name)
 	conversionWebhookURL := getConversionWebhookURL(identity)
@@ -609,6 +729,7 @@ func CreateDeployment(ctx context.Context, identity string, directCSIContainerIm
 					"--leader-election",
 					"--feature-gates=Topology=true",
 					"--strict-topology",
+					"--extra-create-metadata",
 				},
 				Env: []corev1.EnvVar{
 					{
@@ -686,6 +807,7 @@ func CreateDeployment(ctx context.Context, identity string, directCSIContainerIm
 			},
 		},
 	}
+	setImagePullOptions(&podSpec, imagePullSecrets, imagePullPolicy)
 
 	caCertBytes, publicCertBytes, privateKeyBytes, certErr := getCerts([]string{admissionWehookDNSName})
 	if certErr != nil {
@@ -773,6 +895,15 @@ func generateSanitizedUniqueNameFrom(name string) string {
 	return fmt.Sprintf("%s-%s", sanitizedName, shortUUID)
 }
 
+// isUnderCSIRoot checks if the path is already reachable through the common root volume
+func isUnderCSIRoot(path string) bool {
+	if path == "" {
+		return true
+	}
+	rel, err := filepath.Rel(csiRootPath, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
 func newHostPathVolume(name, path string) corev1.Volume {
 	hostPathType := corev1.HostPathDirectoryOrCreate
 	volumeSource := corev1.VolumeSource{
@@ -788,6 +919,21 @@ func newHostPathVolume(name, path string) corev1.Volume {
 	}
 }
 
+// setImagePullOptions sets the secrets to pull the images of the pod and the pull policy of its containers,
+// the defaults of kubernetes apply if they are empty
+func setImagePullOptions(podSpec *corev1.PodSpec, imagePullSecrets []string, imagePullPolicy corev1.PullPolicy) {
+	podSpec.ImagePullSecrets = nil
+	for _, secret := range imagePullSecrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	if imagePullPolicy == "" {
+		return
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].ImagePullPolicy = imagePullPolicy
+	}
+}
+
 func newSecretVolume(name, secretName string) corev1.Volume {
 	volumeSource := corev1.VolumeSource{
 		Secret: &corev1.SecretVolumeSource{
@@ -819,8 +965,7 @@ func newVolumeMount(name, path string, bidirectional bool) corev1.VolumeMount {
 func getDriveValidatingWebhookConfig(identity string) admissionv1.ValidatingWebhookConfiguration {
 
 	name := sanitizeName(identity)
-	getServiceRef := func() *admissionv1.ServiceReference {
-		path := "/validatedrive"
+	getServiceRef := func(path string) *admissionv1.ServiceReference {
 		return &admissionv1.ServiceReference{
 			Namespace: name,
 			Name:      validationControllerName,
@@ -828,9 +973,9 @@ func getDriveValidatingWebhookConfig(identity string) admissionv1.ValidatingWebh
 		}
 	}
 
-	getClientConfig := func() admissionv1.WebhookClientConfig {
+	getClientConfig := func(path string) admissionv1.WebhookClientConfig {
 		return admissionv1.WebhookClientConfig{
-			Service:  getServiceRef(),
+			Service:  getServiceRef(path),
 			CABundle: []byte(validationWebhookCaBundle),
 		}
 
@@ -849,17 +994,40 @@ func getDriveValidatingWebhookConfig(identity string) admissionv1.ValidatingWebh
 		}
 	}
 
+	getPVCValidationRules := func() []admissionv1.RuleWithOperations {
+		return []admissionv1.RuleWithOperations{
+			{
+				Operations: []admissionv1.OperationType{admissionv1.Create},
+				Rule: admissionv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"persistentvolumeclaims"},
+				},
+			},
+		}
+	}
+
 	getValidatingWebhooks := func() []admissionv1.ValidatingWebhook {
 		supportedReviewVersions := []string{"v1", "v1beta1", "v1beta2"}
 		sideEffectClass := admissionv1.SideEffectClassNone
+		// claims of other provisioners must not be blocked while the controller is unreachable
+		ignoreFailurePolicy := admissionv1.Ignore
 		return []admissionv1.ValidatingWebhook{
 			{
 				Name:                    ValidationWebhookConfigName,
-				ClientConfig:            getClientConfig(),
+				ClientConfig:            getClientConfig("/validatedrive"),
 				AdmissionReviewVersions: supportedReviewVersions,
 				SideEffects:             &sideEffectClass,
 				Rules:                   getValidationRules(),
 			},
+			{
+				Name:                    pvcValidationWebhookName,
+				ClientConfig:            getClientConfig("/validatepvc"),
+				AdmissionReviewVersions: supportedReviewVersions,
+				SideEffects:             &sideEffectClass,
+				FailurePolicy:           &ignoreFailurePolicy,
+				Rules:                   getPVCValidationRules(),
+			},
 		}
 	}
 
@@ -943,6 +1111,25 @@ func CreateOrUpdateConversionSecret(ctx context.Context, identity string, public
 	return nil
 }
 
+// GetConversionWebhookVersion fetches the version of the running conversion webhook through the apiserver service proxy
+func GetConversionWebhookVersion(ctx context.Context, identity string) (*converter.WebhookVersion, error) {
+	data, err := utils.GetKubeClient().CoreV1().Services(sanitizeName(identity)).ProxyGet(
+		"https",
+		conversionWebhookName,
+		strconv.Itoa(conversionWebhookPort),
+		converter.VersionPath,
+		nil,
+	).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version := &converter.WebhookVersion{}
+	if err := json.Unmarshal(data, version); err != nil {
+		return nil, fmt.Errorf("invalid version response of the conversion webhook: %v", err)
+	}
+	return version, nil
+}
+
 func CreateOrUpdateConversionService(ctx context.Context, generatedSelectorValue, identity string, dryRun bool) error {
 
 	servicesClient := utils.GetKubeClient().CoreV1().Services(sanitizeName(identity))
@@ -993,7 +1180,7 @@ func CreateOrUpdateConversionService(ctx context.Context, generatedSelectorValue
 	return nil
 }
 
-func CreateConversionDeployment(ctx context.Context, identity string, directCSIContainerImage string, dryRun bool, registry, org string) error {
+func CreateConversionDeployment(ctx context.Context, identity string, directCSIContainerImage string, dryRun bool, registry, org string, imagePullSecrets []string, imagePullPolicy corev1.PullPolicy) error {
 	name := sanitizeName(identity)
 	generatedSelectorValue := generateSanitizedUniqueNameFrom(name)
 	conversionWebhookDNSName := getConversionWebhookDNSName(identity)
@@ -1042,6 +1229,7 @@ func CreateConversionDeployment(ctx context.Context, identity string, directCSIC
 			},
 		},
 	}
+	setImagePullOptions(&podSpec, imagePullSecrets, imagePullPolicy)
 
 	caCertBytes, publicCertBytes, privateKeyBytes, certErr := getCerts([]string{conversionWebhookDNSName})
 	if certErr != nil {
@@ -1125,6 +1313,11 @@ func GetConversionCABundle(ctx context.Context, identity string, dryRun bool) ([
 		return conversionWebhookCaBundle, nil
 	}
 
+	if dryRun && len(conversionWebhookCaBundle) != 0 {
+		// the CRDs must trust the CA of the printed conversion secrets
+		return getCABundlerFromGlobal()
+	}
+
 	secret, err := utils.GetKubeClient().
 		CoreV1().
 		Secrets(sanitizeName(identity)).
@@ -1149,24 +1342,28 @@ func GetConversionServiceName() string {
 	return conversionWebhookName
 }
 
-func CreateOrUpdateConversionDeployment(ctx context.Context, identity string, directCSIContainerImage string, dryRun bool, registry, org string) error {
+func CreateOrUpdateConversionDeployment(ctx context.Context, identity string, directCSIContainerImage string, dryRun bool, registry, org string, imagePullSecrets []string, imagePullPolicy corev1.PullPolicy) error {
 	deploymentsClient := utils.GetKubeClient().
 		AppsV1().Deployments(sanitizeName(identity))
 
 	deployment, getErr := deploymentsClient.Get(ctx, conversionWebhookName, metav1.GetOptions{})
-	if getErr != nil {
-		if !kerr.IsNotFound(getErr) {
+	if getErr != nil || dryRun {
+		if getErr != nil && !kerr.IsNotFound(getErr) {
 			return getErr
 		}
-		if err := CreateConversionDeployment(ctx, identity, directCSIContainerImage, dryRun, registry, org); err != nil {
+		// the dry-run prints the whole conversion deployment along with its secrets and service
+		if err := CreateConversionDeployment(ctx, identity, directCSIContainerImage, dryRun, registry, org, imagePullSecrets, imagePullPolicy); err != nil {
 			return err
 		}
 		return nil
 	}
-	// Conversion deployment is already present. Just update the container version.
+	// Conversion deployment is already present. Just update the container version and the pull options.
 	deploymentImage := filepath.Join(registry, org, directCSIContainerImage)
-	if deployment.Spec.Template.Spec.Containers[0].Image != deploymentImage {
-		deployment.Spec.Template.Spec.Containers[0].Image = deploymentImage
+	podSpec := deployment.Spec.Template.Spec.DeepCopy()
+	podSpec.Containers[0].Image = deploymentImage
+	setImagePullOptions(podSpec, imagePullSecrets, imagePullPolicy)
+	if !equality.Semantic.DeepEqual(&deployment.Spec.Template.Spec, podSpec) {
+		deployment.Spec.Template.Spec = *podSpec
 		if dryRun {
 			deployment.TypeMeta.Kind = "Deployment"
 			deployment.TypeMeta.APIVersion = "apps/v1"
@@ -0,0 +1,100 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package installer
+
+import (
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewDrivePreviewPod returns a short-lived pod which prints the drives of the node that direct-csi would manage,
+// along with the checks applied to each drive if trace is set. The devices are selected like the node driver does.
+func NewDrivePreviewPod(name, namespace, nodeName string, directCSIContainerImage, registry, org string, includeDevices, excludeDevices []string, minDriveSize string, trace bool) *corev1.Pod {
+	privileged := true
+	hostPathType := corev1.HostPathDirectory
+
+	newHostPathVolume := func(name, path string) corev1.Volume {
+		return corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: path,
+					Type: &hostPathType,
+				},
+			},
+		}
+	}
+
+	args := []string{"--preview-drives"}
+	if len(includeDevices) > 0 {
+		args = append(args, "--include-devices="+strings.Join(includeDevices, ","))
+	}
+	if len(excludeDevices) > 0 {
+		args = append(args, "--exclude-devices="+strings.Join(excludeDevices, ","))
+	}
+	if minDriveSize != "" {
+		args = append(args, "--min-drive-size="+minDriveSize)
+	}
+	if trace {
+		args = append(args, "--preview-trace")
+	}
+
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sanitizeName(name),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				CreatedByLabel: DirectCSIPluginName,
+			},
+			Labels: map[string]string{
+				"app": DirectCSI,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			HostPID:       true,
+			Volumes: []corev1.Volume{
+				newHostPathVolume(volumeNameSysDir, volumePathSysDir),
+				newHostPathVolume(volumeNameDevDir, volumePathDevDir),
+			},
+			Containers: []corev1.Container{
+				{
+					Name:            directCSIContainerName,
+					Image:           filepath.Join(registry, org, directCSIContainerImage),
+					Args:            args,
+					SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+					VolumeMounts: []corev1.VolumeMount{
+						newVolumeMount(volumeNameSysDir, volumePathSysDir, false),
+						newVolumeMount(volumeNameDevDir, volumePathDevDir, false),
+					},
+				},
+			},
+			// the drives of tainted nodes should be previewed as well
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+		},
+	}
+}
@@ -27,7 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func createPodSecurityPolicy(ctx context.Context, identity string, dryRun bool) error {
+func createPodSecurityPolicy(ctx context.Context, identity, mountRoot string, dryRun bool) error {
 	psp := &policy.PodSecurityPolicy{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "policy/v1beta1",
@@ -59,6 +59,9 @@ func createPodSecurityPolicy(ctx context.Context, identity string, dryRun bool)
 			},
 		},
 	}
+	if !isUnderCSIRoot(mountRoot) {
+		psp.Spec.AllowedHostPaths = append(psp.Spec.AllowedHostPaths, policy.AllowedHostPath{PathPrefix: mountRoot})
+	}
 
 	if dryRun {
 		utils.LogYAML(psp)
@@ -104,14 +107,14 @@ func createPodSecurityPolicy(ctx context.Context, identity string, dryRun bool)
 	return err
 }
 
-func CreatePodSecurityPolicy(ctx context.Context, identity string, dryRun bool) error {
+func CreatePodSecurityPolicy(ctx context.Context, identity, mountRoot string, dryRun bool) error {
 	info, err := utils.GetGroupKindVersions("policy", "PodSecurityPolicy", "v1beta1")
 	if err != nil {
 		return err
 	}
 
 	if info.Version == "v1beta1" {
-		return createPodSecurityPolicy(ctx, identity, dryRun)
+		return createPodSecurityPolicy(ctx, identity, mountRoot, dryRun)
 	}
 
 	return ErrKubeVersionNotSupported
@@ -302,7 +302,7 @@ func createClusterRole(ctx context.Context, identity string, dryRun bool) error
 					clusterRoleVerbDelete,
 				},
 				Resources: []string{
-					"directcsidrives", "directcsivolumes",
+					"directcsidrives", "directcsivolumes", "directcsisnapshots",
 				},
 				APIGroups: []string{
 					"direct.csi.min.io",
@@ -0,0 +1,95 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package listener
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// jitter factor added to the backoff of the events, so that objects failing together spread out
+	eventBackoffJitter = 0.1
+)
+
+type eventBackoff struct {
+	delay time.Duration
+	next  time.Time
+}
+
+// eventThrottle backs off exponentially the events of an object which keeps failing for the same reason,
+// the events let through are further coalesced by the event correlator of the recorder
+type eventThrottle struct {
+	mutex     sync.Mutex
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	backoffs  map[string]*eventBackoff
+	now       func() time.Time
+}
+
+func newEventThrottle(baseDelay, maxDelay time.Duration) *eventThrottle {
+	return &eventThrottle{
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		backoffs:  map[string]*eventBackoff{},
+		now:       time.Now,
+	}
+}
+
+func eventKey(objectKey, reason string) string {
+	return objectKey + "/" + reason
+}
+
+// allow reports if the event of the object with the given reason may be emitted now
+func (t *eventThrottle) allow(objectKey, reason string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	key := eventKey(objectKey, reason)
+	backoff, ok := t.backoffs[key]
+	if !ok {
+		backoff = &eventBackoff{delay: t.baseDelay}
+		t.backoffs[key] = backoff
+	} else {
+		if now.Before(backoff.next) {
+			return false
+		}
+		backoff.delay *= 2
+		if backoff.delay > t.maxDelay {
+			backoff.delay = t.maxDelay
+		}
+	}
+	backoff.next = now.Add(wait.Jitter(backoff.delay, eventBackoffJitter))
+	return true
+}
+
+// forget resets the backoff of all the reasons of the object, once it is processed successfully
+func (t *eventThrottle) forget(objectKey string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	prefix := eventKey(objectKey, "")
+	for key := range t.backoffs {
+		if strings.HasPrefix(key, prefix) {
+			delete(t.backoffs, key)
+		}
+	}
+}
@@ -58,3 +58,16 @@ func (c *DirectCSIController) AddDirectCSIDriveListener(b DirectCSIDriveListener
 	c.initialized = true
 	c.DirectCSIDriveListener = b
 }
+
+type DirectCSISnapshotListener interface {
+	GenericListener
+
+	Add(ctx context.Context, b *directcsi.DirectCSISnapshot) error
+	Update(ctx context.Context, old *directcsi.DirectCSISnapshot, new *directcsi.DirectCSISnapshot) error
+	Delete(ctx context.Context, b *directcsi.DirectCSISnapshot) error
+}
+
+func (c *DirectCSIController) AddDirectCSISnapshotListener(b DirectCSISnapshotListener) {
+	c.initialized = true
+	c.DirectCSISnapshotListener = b
+}
@@ -18,12 +18,14 @@ package listener
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -31,6 +33,7 @@ import (
 	// objectstorage
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/clientset"
+	directcsischeme "github.com/minio/direct-csi/pkg/clientset/scheme"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	// k8s api
@@ -51,9 +54,18 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	// logging
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
+const (
+	// initial and maximum interval between the identical warning events of a failing object
+	eventBaseDelay = time.Minute
+	eventMaxDelay  = time.Hour
+)
+
+// ErrLeadershipLost is returned by Run when the controller loses the leadership to another replica
+var ErrLeadershipLost = errors.New("stopped leading")
+
 type addFunc func(ctx context.Context, obj interface{}) error
 type updateFunc func(ctx context.Context, old, new interface{}) error
 type deleteFunc func(ctx context.Context, obj interface{}) error
@@ -103,8 +115,9 @@ type DirectCSIController struct {
 	threadiness  int
 
 	// Listeners
-	DirectCSIVolumeListener DirectCSIVolumeListener
-	DirectCSIDriveListener  DirectCSIDriveListener
+	DirectCSIVolumeListener   DirectCSIVolumeListener
+	DirectCSIDriveListener    DirectCSIDriveListener
+	DirectCSISnapshotListener DirectCSISnapshotListener
 
 	// leader election
 	leaderLock string
@@ -117,17 +130,61 @@ type DirectCSIController struct {
 
 	locker     map[string]*sync.Mutex
 	lockerLock sync.Mutex
+
+	eventRecorder record.EventRecorder
+	eventThrottle *eventThrottle
+
+	// number of the listener caches to be synced and the ones already synced
+	cachesToSync int32
+	cachesSynced int32
+}
+
+// ControllerTimings are the resync period of the listener caches and the timings of the leader election
+type ControllerTimings struct {
+	ResyncPeriod  time.Duration
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// DefaultControllerTimings are used unless configured otherwise
+var DefaultControllerTimings = ControllerTimings{
+	ResyncPeriod:  60 * time.Second,
+	LeaseDuration: 60 * time.Second,
+	RenewDeadline: 10 * time.Second,
+	RetryPeriod:   5 * time.Second,
+}
+
+// Validate checks the timings against the constraints of the leader election
+func (t ControllerTimings) Validate() error {
+	if t.ResyncPeriod <= 0 {
+		return fmt.Errorf("resync period must be greater than zero, got %v", t.ResyncPeriod)
+	}
+	if t.RetryPeriod <= 0 {
+		return fmt.Errorf("retry period must be greater than zero, got %v", t.RetryPeriod)
+	}
+	if t.RenewDeadline >= t.LeaseDuration {
+		return fmt.Errorf("renew deadline (%v) must be less than the lease duration (%v)", t.RenewDeadline, t.LeaseDuration)
+	}
+	if t.RetryPeriod >= t.RenewDeadline {
+		return fmt.Errorf("retry period (%v) must be less than the renew deadline (%v)", t.RetryPeriod, t.RenewDeadline)
+	}
+	return nil
 }
 
-func NewDefaultDirectCSIController(identity string, leaderLockName string, threads int) (*DirectCSIController, error) {
+func NewDefaultDirectCSIController(identity string, leaderLockName string, threads int, timings ControllerTimings) (*DirectCSIController, error) {
 	rateLimit := workqueue.NewMaxOfRateLimiter(
 		workqueue.NewItemExponentialFailureRateLimiter(100*time.Millisecond, 600*time.Second),
 		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
 	)
-	return NewDirectCSIController(identity, leaderLockName, threads, rateLimit)
+	return NewDirectCSIController(identity, leaderLockName, threads, rateLimit, timings)
 }
 
-func NewDirectCSIController(identity string, leaderLockName string, threads int, limiter workqueue.RateLimiter) (*DirectCSIController, error) {
+func NewDirectCSIController(identity string, leaderLockName string, threads int, limiter workqueue.RateLimiter, timings ControllerTimings) (*DirectCSIController, error) {
+	if err := timings.Validate(); err != nil {
+		return nil, err
+	}
+
 	var err error
 	directcsiClient := utils.GetDirectClientset()
 	kubeClient := utils.GetKubeClient()
@@ -148,15 +205,25 @@ func NewDirectCSIController(identity string, leaderLockName string, threads int,
 		leaderLock:      leaderLockName,
 		queue:           workqueue.NewRateLimitingQueue(limiter),
 		threadiness:     threads,
+		eventThrottle:   newEventThrottle(eventBaseDelay, eventMaxDelay),
 
-		ResyncPeriod:  60 * time.Second,
-		LeaseDuration: 60 * time.Second,
-		RenewDeadline: 10 * time.Second,
-		RetryPeriod:   5 * time.Second,
+		ResyncPeriod:  timings.ResyncPeriod,
+		LeaseDuration: timings.LeaseDuration,
+		RenewDeadline: timings.RenewDeadline,
+		RetryPeriod:   timings.RetryPeriod,
 	}, nil
 }
 
+// NewEventScheme resolves the kinds of both the kubernetes and the direct-csi objects the events refer to
+func NewEventScheme() *runtime.Scheme {
+	eventScheme := runtime.NewScheme()
+	utilruntime.Must(scheme.AddToScheme(eventScheme))
+	utilruntime.Must(directcsischeme.AddToScheme(eventScheme))
+	return eventScheme
+}
+
 // Run - runs the controller. Note that ctx must be cancellable i.e. ctx.Done() should not return nil
+// Run returns ErrLeadershipLost if the leadership is lost before ctx is done
 func (c *DirectCSIController) Run(ctx context.Context) error {
 	if !c.initialized {
 		fmt.Errorf("Uninitialized controller. Atleast 1 listener should be added")
@@ -192,8 +259,12 @@ func (c *DirectCSIController) Run(ctx context.Context) error {
 	}
 
 	recorder := record.NewBroadcaster()
-	recorder.StartRecordingToSink(&corev1.EventSinkImpl{Interface: c.kubeClient.CoreV1().Events(ns)})
-	eRecorder := recorder.NewRecorder(scheme.Scheme, v1.EventSource{Component: leader})
+	defer recorder.Shutdown()
+	// the sink creates each event in its own namespace, which is default for the cluster scoped objects
+	recorder.StartRecordingToSink(&corev1.EventSinkImpl{Interface: c.kubeClient.CoreV1().Events("")})
+	eRecorder := recorder.NewRecorder(NewEventScheme(), v1.EventSource{Component: leader})
+	// the event correlator of the recorder coalesces the identical events into one with an increasing count
+	c.eventRecorder = eRecorder
 
 	rlConfig := resourcelock.ResourceLockConfig{
 		Identity:      sanitize(id),
@@ -205,18 +276,28 @@ func (c *DirectCSIController) Run(ctx context.Context) error {
 		return err
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	leadershipLost := false
 	leaderConfig := leaderelection.LeaderElectionConfig{
-		Lock:          l,
-		LeaseDuration: c.LeaseDuration,
-		RenewDeadline: c.RenewDeadline,
-		RetryPeriod:   c.RetryPeriod,
+		Lock:            l,
+		LeaseDuration:   c.LeaseDuration,
+		RenewDeadline:   c.RenewDeadline,
+		RetryPeriod:     c.RetryPeriod,
+		ReleaseOnCancel: true,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
 				klog.V(2).Info("became leader, starting")
 				c.runController(ctx)
 			},
 			OnStoppedLeading: func() {
-				klog.Fatal("stopped leading")
+				// also called on shutdown, leadership is lost only if ctx is not done yet
+				if ctx.Err() == nil {
+					klog.V(2).Info("stopped leading")
+					leadershipLost = true
+				}
+				cancel()
 			},
 			OnNewLeader: func(identity string) {
 				klog.V(3).Infof("new leader detected, current leader: %s", identity)
@@ -225,7 +306,16 @@ func (c *DirectCSIController) Run(ctx context.Context) error {
 	}
 
 	leaderelection.RunOrDie(ctx, leaderConfig)
-	return nil // should never reach here
+	if leadershipLost {
+		return ErrLeadershipLost
+	}
+	return nil
+}
+
+// HasSynced returns true once the caches of all the listeners are synced
+func (c *DirectCSIController) HasSynced() bool {
+	toSync := atomic.LoadInt32(&c.cachesToSync)
+	return toSync > 0 && atomic.LoadInt32(&c.cachesSynced) == toSync
 }
 
 func (c *DirectCSIController) runWorker(ctx context.Context) {
@@ -251,23 +341,31 @@ func (c *DirectCSIController) processNextItem(ctx context.Context) bool {
 	defer c.OpUnlock(op)
 
 	var opKind string
+	var reason string
 	var key string
+	var obj interface{}
 	var err error
 
 	switch o := op.(type) {
 	case addOp:
 		opKind = "add"
+		reason = "AddFailed"
 		key = o.Key
+		obj = o.Object
 		add := *o.AddFunc
 		err = add(ctx, o.Object)
 	case updateOp:
 		opKind = "update"
+		reason = "UpdateFailed"
 		key = o.Key
+		obj = o.NewObject
 		update := *o.UpdateFunc
 		err = update(ctx, o.OldObject, o.NewObject)
 	case deleteOp:
 		opKind = "delete"
+		reason = "DeleteFailed"
 		key = o.Key
+		obj = o.Object
 		delete := *o.DeleteFunc
 		err = delete(ctx, o.Object)
 	default:
@@ -276,9 +374,31 @@ func (c *DirectCSIController) processNextItem(ctx context.Context) bool {
 	if err != nil {
 		klog.Errorf("op: %s key: %s err: %v", opKind, key, err)
 	}
+	c.recordOpEvent(key, obj, reason, err)
 	return true
 }
 
+// recordOpEvent emits a warning event on the object of a failed op. The identical events of an object
+// are throttled with an exponential backoff instead of being recreated on every retry
+func (c *DirectCSIController) recordOpEvent(key string, obj interface{}, reason string, err error) {
+	if err == nil {
+		c.eventThrottle.forget(key)
+		return
+	}
+	if c.eventRecorder == nil {
+		return
+	}
+	// deleted objects may be in an unknown final state
+	object, ok := obj.(runtime.Object)
+	if !ok {
+		return
+	}
+	if !c.eventThrottle.allow(key, reason) {
+		return
+	}
+	c.eventRecorder.Event(object, v1.EventTypeWarning, reason, err.Error())
+}
+
 func (c *DirectCSIController) OpLock(op interface{}) {
 	c.GetOpLock(op).Lock()
 }
@@ -421,6 +541,7 @@ func (c *DirectCSIController) runController(ctx context.Context) {
 			utilruntime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
 			return
 		}
+		atomic.AddInt32(&c.cachesSynced, 1)
 
 		for i := 0; i < c.threadiness; i++ {
 			go wait.UntilWithContext(ctx, c.runWorker, time.Second)
@@ -430,6 +551,19 @@ func (c *DirectCSIController) runController(ctx context.Context) {
 		klog.V(3).Infof("Stopping %s controller", name)
 	}
 
+	atomic.StoreInt32(&c.cachesSynced, 0)
+	cachesToSync := int32(0)
+	if c.DirectCSIVolumeListener != nil {
+		cachesToSync++
+	}
+	if c.DirectCSIDriveListener != nil {
+		cachesToSync++
+	}
+	if c.DirectCSISnapshotListener != nil {
+		cachesToSync++
+	}
+	atomic.StoreInt32(&c.cachesToSync, cachesToSync)
+
 	if c.DirectCSIVolumeListener != nil {
 		c.DirectCSIVolumeListener.InitializeKubeClient(c.kubeClient)
 		c.DirectCSIVolumeListener.InitializeDirectCSIClient(c.directcsiClient)
@@ -458,6 +592,20 @@ func (c *DirectCSIController) runController(ctx context.Context) {
 		}
 		go controllerFor("DirectCSIDrives", &directcsi.DirectCSIDrive{}, addFunc, updateFunc, deleteFunc)
 	}
+	if c.DirectCSISnapshotListener != nil {
+		c.DirectCSISnapshotListener.InitializeKubeClient(c.kubeClient)
+		c.DirectCSISnapshotListener.InitializeDirectCSIClient(c.directcsiClient)
+		addFunc := func(ctx context.Context, obj interface{}) error {
+			return c.DirectCSISnapshotListener.Add(ctx, obj.(*directcsi.DirectCSISnapshot))
+		}
+		updateFunc := func(ctx context.Context, old interface{}, new interface{}) error {
+			return c.DirectCSISnapshotListener.Update(ctx, old.(*directcsi.DirectCSISnapshot), new.(*directcsi.DirectCSISnapshot))
+		}
+		deleteFunc := func(ctx context.Context, obj interface{}) error {
+			return c.DirectCSISnapshotListener.Delete(ctx, obj.(*directcsi.DirectCSISnapshot))
+		}
+		go controllerFor("DirectCSISnapshots", &directcsi.DirectCSISnapshot{}, addFunc, updateFunc, deleteFunc)
+	}
 
 	<-ctx.Done()
 }
@@ -0,0 +1,253 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testNamespace = "test-ns"
+
+func TestRunOnLeadershipLoss(t *testing.T) {
+	utils.SetFake()
+	os.Setenv("POD_NAMESPACE", testNamespace)
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	c, err := NewDirectCSIController("test-identity", "test-controller", 1, workqueue.DefaultControllerRateLimiter(), DefaultControllerTimings)
+	if err != nil {
+		t.Fatalf("Error while creating the controller: %v", err)
+	}
+	kubeClient := fakekube.NewSimpleClientset()
+	c.kubeClient = kubeClient
+	c.LeaseDuration = 2 * time.Second
+	c.RenewDeadline = 1 * time.Second
+	c.RetryPeriod = 100 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Run(ctx)
+	}()
+
+	leases := kubeClient.CoordinationV1().Leases(testNamespace)
+	leaseName := "test-controller-test-identity"
+	if err := wait.PollImmediate(100*time.Millisecond, 10*time.Second, func() (bool, error) {
+		_, err := leases.Get(ctx, leaseName, metav1.GetOptions{})
+		return err == nil, nil
+	}); err != nil {
+		t.Fatalf("Leadership was not acquired: %v", err)
+	}
+
+	// simulate another replica taking over the lease until the controller gives up
+	stealLease := func() {
+		lease, err := leases.Get(ctx, leaseName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Error while getting the lease: %v", err)
+		}
+		holder := "other-replica"
+		leaseDuration := int32(60)
+		now := metav1.NewMicroTime(time.Now())
+		lease.Spec.HolderIdentity = &holder
+		lease.Spec.LeaseDurationSeconds = &leaseDuration
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+		if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Error while updating the lease: %v", err)
+		}
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			stealLease()
+		case err := <-errCh:
+			if !errors.Is(err, ErrLeadershipLost) {
+				t.Fatalf("Expected error: %v but got: %v", ErrLeadershipLost, err)
+			}
+			return
+		case <-ctx.Done():
+			t.Fatalf("Run did not return after losing the leadership")
+		}
+	}
+}
+
+func TestRunOnContextCancel(t *testing.T) {
+	utils.SetFake()
+	os.Setenv("POD_NAMESPACE", testNamespace)
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	c, err := NewDirectCSIController("test-identity", "test-controller", 1, workqueue.DefaultControllerRateLimiter(), DefaultControllerTimings)
+	if err != nil {
+		t.Fatalf("Error while creating the controller: %v", err)
+	}
+	c.kubeClient = fakekube.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Run(ctx)
+	}()
+	time.Sleep(500 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Expected no error on shutdown but got: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Run did not return after the context was cancelled")
+	}
+}
+
+func TestControllerTimingsValidate(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		timings     ControllerTimings
+		expectedErr bool
+	}{
+		{
+			name:    "default",
+			timings: DefaultControllerTimings,
+		},
+		{
+			name:        "renewDeadlineNotLessThanLeaseDuration",
+			timings:     ControllerTimings{ResyncPeriod: time.Minute, LeaseDuration: 10 * time.Second, RenewDeadline: 10 * time.Second, RetryPeriod: time.Second},
+			expectedErr: true,
+		},
+		{
+			name:        "retryPeriodNotLessThanRenewDeadline",
+			timings:     ControllerTimings{ResyncPeriod: time.Minute, LeaseDuration: time.Minute, RenewDeadline: 5 * time.Second, RetryPeriod: 5 * time.Second},
+			expectedErr: true,
+		},
+		{
+			name:        "zeroResyncPeriod",
+			timings:     ControllerTimings{LeaseDuration: time.Minute, RenewDeadline: 10 * time.Second, RetryPeriod: time.Second},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if err := tt.timings.Validate(); (err != nil) != tt.expectedErr {
+				t1.Errorf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestEventThrottle(t1 *testing.T) {
+	now := time.Now()
+	throttle := newEventThrottle(time.Minute, 4*time.Minute)
+	throttle.now = func() time.Time { return now }
+
+	testCases := []struct {
+		name     string
+		after    time.Duration
+		key      string
+		reason   string
+		expected bool
+	}{
+		{name: "first_event", key: "drive-1", reason: "UpdateFailed", expected: true},
+		{name: "identical_event", key: "drive-1", reason: "UpdateFailed", expected: false},
+		{name: "other_reason", key: "drive-1", reason: "AddFailed", expected: true},
+		{name: "other_object", key: "drive-2", reason: "UpdateFailed", expected: true},
+		// the jitter delays the next event by at most 10% of the backoff
+		{name: "after_base_delay", after: 66 * time.Second, key: "drive-1", reason: "UpdateFailed", expected: true},
+		{name: "before_doubled_delay", after: 66 * time.Second, key: "drive-1", reason: "UpdateFailed", expected: false},
+		{name: "after_doubled_delay", after: 66 * time.Second, key: "drive-1", reason: "UpdateFailed", expected: true},
+		{name: "before_max_delay", after: 239 * time.Second, key: "drive-1", reason: "UpdateFailed", expected: false},
+		{name: "after_max_delay", after: 30 * time.Second, key: "drive-1", reason: "UpdateFailed", expected: true},
+		{name: "capped_at_max_delay", after: 265 * time.Second, key: "drive-1", reason: "UpdateFailed", expected: true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			now = now.Add(tt.after)
+			if allowed := throttle.allow(tt.key, tt.reason); allowed != tt.expected {
+				t1.Errorf("Test case name %s: expected allowed = %v, got %v", tt.name, tt.expected, allowed)
+			}
+		})
+	}
+
+	throttle.forget("drive-1")
+	if !throttle.allow("drive-1", "UpdateFailed") {
+		t1.Errorf("expected the event to be allowed once the object is processed successfully")
+	}
+	if throttle.allow("drive-1", "UpdateFailed") {
+		t1.Errorf("expected the backoff to restart once the object fails again")
+	}
+}
+
+func TestRecordOpEvent(t1 *testing.T) {
+	utils.SetFake()
+	c, err := NewDirectCSIController("test-identity", "test-controller", 1, workqueue.DefaultControllerRateLimiter(), DefaultControllerTimings)
+	if err != nil {
+		t1.Fatalf("Error while creating the controller: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	c.eventRecorder = recorder
+
+	drive := &directcsi.DirectCSIDrive{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "drive-1",
+		},
+	}
+	mountErr := errors.New("mount failed")
+
+	// repeated identical failures emit a single event
+	for i := 0; i < 5; i++ {
+		c.recordOpEvent("drive-1", drive, "UpdateFailed", mountErr)
+	}
+	if len(recorder.Events) != 1 {
+		t1.Fatalf("expected 1 event, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; event != "Warning UpdateFailed mount failed" {
+		t1.Errorf("unexpected event %q", event)
+	}
+
+	// a success resets the backoff
+	c.recordOpEvent("drive-1", drive, "UpdateFailed", nil)
+	c.recordOpEvent("drive-1", drive, "UpdateFailed", mountErr)
+	if len(recorder.Events) != 1 {
+		t1.Errorf("expected 1 event after the success, got %d", len(recorder.Events))
+	}
+
+	// objects in an unknown final state are skipped
+	c.recordOpEvent("drive-2", "unknown", "DeleteFailed", mountErr)
+	if len(recorder.Events) != 1 {
+		t1.Errorf("expected no event for an unknown object, got %d", len(recorder.Events))
+	}
+}
@@ -18,13 +18,15 @@ package metrics
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/minio/direct-csi/pkg/clientset"
+	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -32,7 +34,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-func newMetricsCollector(nodeID string) (*metricsCollector, error) {
+func newMetricsCollector(nodeID string, pvcLabels bool) (*metricsCollector, error) {
 	kubeConfig := utils.GetKubeConfig()
 	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
 	if err != nil {
@@ -51,6 +53,7 @@ func newMetricsCollector(nodeID string) (*metricsCollector, error) {
 		desc:            prometheus.NewDesc("directcsi_stats", "Statistics exposed by DirectCSI", nil, nil),
 		nodeID:          nodeID,
 		directcsiClient: directClientset,
+		pvcLabels:       pvcLabels,
 	}
 	prometheus.MustRegister(mc)
 	return mc, nil
@@ -60,6 +63,8 @@ type metricsCollector struct {
 	desc            *prometheus.Desc
 	nodeID          string
 	directcsiClient clientset.Interface
+	// pvcLabels labels the volume stats by the claims of the volumes
+	pvcLabels bool
 }
 
 // Describe sends the super-set of all possible descriptors of metrics
@@ -69,13 +74,16 @@ func (c *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect is called by the Prometheus registry when collecting metrics.
 func (c *metricsCollector) Collect(ch chan<- prometheus.Metric) {
-	c.volumeStatsEmitter(context.Background(), ch, getXFSVolumeStats)
+	c.volumeStatsEmitter(context.Background(), ch, GetVolumeStats)
+	c.driveStatsEmitter(context.Background(), ch, sys.ReadDiskStats)
+	c.tierStatsEmitter(context.Background(), ch)
+	c.driveHealthEmitter(context.Background(), ch)
 }
 
 func (c *metricsCollector) volumeStatsEmitter(
 	ctx context.Context,
 	ch chan<- prometheus.Metric,
-	volumeStatsGetter xfsVolumeStatsGetter) {
+	volumeStatsGetter volumeStatsGetter) {
 	volumeClient := c.directcsiClient.DirectV1beta2().DirectCSIVolumes()
 	volumeList, err := volumeClient.List(
 		context.Background(),
@@ -99,15 +107,105 @@ func (c *metricsCollector) volumeStatsEmitter(
 		if volume.Status.NodeName != c.nodeID || !isVolumePublished() {
 			continue
 		}
-		publishVolumeStats(ctx, &volume, ch, volumeStatsGetter)
+		publishVolumeStats(ctx, &volume, ch, volumeStatsGetter, c.pvcLabels)
 	}
 }
 
-func metricsHandler(nodeID string) http.Handler {
+// driveStatsEmitter publishes the I/O counters of the drives backing the volumes
+// in this node. Volumes are bind-mounted subdirectories of a drive, hence the
+// throughput is attributed to the physical drive.
+func (c *metricsCollector) driveStatsEmitter(
+	ctx context.Context,
+	ch chan<- prometheus.Metric,
+	diskStatsGetter diskStatsGetter) {
+	volumeList, err := c.directcsiClient.DirectV1beta2().DirectCSIVolumes().List(
+		ctx,
+		metav1.ListOptions{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		},
+	)
+	if err != nil {
+		klog.V(3).Infof("Error while listing DirectCSI Volumes: %v", err)
+		return
+	}
+	driveNames := map[string]struct{}{}
+	for _, volume := range volumeList.Items {
+		if volume.Status.NodeName == c.nodeID && volume.Status.Drive != "" {
+			driveNames[volume.Status.Drive] = struct{}{}
+		}
+	}
+	if len(driveNames) == 0 {
+		return
+	}
+
+	driveList, err := c.directcsiClient.DirectV1beta2().DirectCSIDrives().List(
+		ctx,
+		metav1.ListOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		},
+	)
+	if err != nil {
+		klog.V(3).Infof("Error while listing DirectCSI Drives: %v", err)
+		return
+	}
+
+	diskStats, err := diskStatsGetter()
+	if err != nil {
+		klog.V(3).Infof("Error while reading diskstats: %v", err)
+		return
+	}
+
+	for _, drive := range driveList.Items {
+		if drive.Status.NodeName != c.nodeID {
+			continue
+		}
+		if _, ok := driveNames[drive.Name]; !ok {
+			continue
+		}
+		publishDriveStats(&drive, diskStats, ch)
+	}
+}
+
+// tierStatsEmitter publishes the free capacity of the drives owned in this node per access tier
+func (c *metricsCollector) tierStatsEmitter(
+	ctx context.Context,
+	ch chan<- prometheus.Metric) {
+	driveList, err := c.directcsiClient.DirectV1beta2().DirectCSIDrives().List(
+		ctx,
+		metav1.ListOptions{
+			TypeMeta:      utils.DirectCSIDriveTypeMeta(),
+			LabelSelector: fmt.Sprintf("%s=%s", utils.NodeLabel, utils.SanitizeLabelV(c.nodeID)),
+		},
+	)
+	if err != nil {
+		klog.V(3).Infof("Error while listing DirectCSI Drives: %v", err)
+		return
+	}
+	publishTierStats(c.nodeID, driveList.Items, ch)
+}
+
+// driveHealthEmitter publishes the SMART health recorded in the status of the drives in this node
+func (c *metricsCollector) driveHealthEmitter(
+	ctx context.Context,
+	ch chan<- prometheus.Metric) {
+	driveList, err := c.directcsiClient.DirectV1beta2().DirectCSIDrives().List(
+		ctx,
+		metav1.ListOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		},
+	)
+	if err != nil {
+		klog.V(3).Infof("Error while listing DirectCSI Drives: %v", err)
+		return
+	}
+	publishDriveHealth(c.nodeID, driveList.Items, ch)
+}
+
+func metricsHandler(nodeID string, pvcLabels bool) http.Handler {
 
 	registry := prometheus.NewRegistry()
 
-	mc, err := newMetricsCollector(nodeID)
+	mc, err := newMetricsCollector(nodeID, pvcLabels)
 	if err != nil {
 		panic(err)
 	}
@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -25,7 +26,8 @@ import (
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
-	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/fs"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,7 +35,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 type metricType string
@@ -50,8 +52,11 @@ const (
 	mb30 = 30 * MB
 	mb10 = 10 * MB
 
-	metricStatsBytesUsed  metricType = "directcsi_stats_bytes_used"
-	metricStatsBytesTotal            = "directcsi_stats_bytes_total"
+	metricStatsBytesUsed     metricType = "directcsi_stats_bytes_used"
+	metricStatsBytesTotal               = "directcsi_stats_bytes_total"
+	metricStatsQuotaEnforced            = "directcsi_stats_quota_enforced"
+	metricStatsInodesUsed               = "directcsi_stats_inodes_used"
+	metricStatsInodesTotal              = "directcsi_stats_inodes_total"
 )
 
 func createFakeMetricsCollector() *metricsCollector {
@@ -84,7 +89,7 @@ func TestVolumeStatsEmitter(t *testing.T) {
 	testVolumeName20MB := "test_volume_20MB"
 	testVolumeName30MB := "test_volume_30MB"
 
-	createTestVolume := func(volName string, totalCap, usedCap int64) *directcsi.DirectCSIVolume {
+	createTestVolume := func(volName string, totalCap, usedCap int64, quotaUnenforced bool) *directcsi.DirectCSIVolume {
 		return &directcsi.DirectCSIVolume{
 			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
 			ObjectMeta: metav1.ObjectMeta{
@@ -94,26 +99,33 @@ func TestVolumeStatsEmitter(t *testing.T) {
 				},
 			},
 			Status: directcsi.DirectCSIVolumeStatus{
-				NodeName:      testNodeName,
-				Drive:         testDriveName,
-				TotalCapacity: totalCap,
-				ContainerPath: "/path/containerpath",
-				UsedCapacity:  usedCap,
+				NodeName:        testNodeName,
+				Drive:           testDriveName,
+				TotalCapacity:   totalCap,
+				ContainerPath:   "/path/containerpath",
+				UsedCapacity:    usedCap,
+				QuotaUnenforced: quotaUnenforced,
 			},
 		}
 	}
 
-	testStatsGetter := func(_ context.Context, vol *directcsi.DirectCSIVolume) (xfs.XFSVolumeStats, error) {
-		return xfs.XFSVolumeStats{
+	// only the 20MB volume has an inode limit
+	usedInodes := map[string]int64{testVolumeName20MB: 100, testVolumeName30MB: 200}
+	totalInodes := map[string]int64{testVolumeName20MB: 1000}
+
+	testStatsGetter := func(_ context.Context, vol *directcsi.DirectCSIVolume) (fs.VolumeStats, error) {
+		return fs.VolumeStats{
 			TotalBytes:     vol.Status.TotalCapacity,
 			UsedBytes:      vol.Status.UsedCapacity,
 			AvailableBytes: vol.Status.TotalCapacity - vol.Status.UsedCapacity,
+			TotalInodes:    totalInodes[vol.Name],
+			UsedInodes:     usedInodes[vol.Name],
 		}, nil
 	}
 
 	testObjects := []runtime.Object{
-		createTestVolume(testVolumeName20MB, mb20, mb10),
-		createTestVolume(testVolumeName30MB, mb30, mb20),
+		createTestVolume(testVolumeName20MB, mb20, mb10, false),
+		createTestVolume(testVolumeName30MB, mb30, mb20, true),
 	}
 
 	var wg sync.WaitGroup
@@ -123,8 +135,8 @@ func TestVolumeStatsEmitter(t *testing.T) {
 	directCSIClient := fmc.directcsiClient.DirectV1beta2()
 
 	metricChan := make(chan prometheus.Metric)
-	noOfMetricsExposedPerVolume := 2
-	expectedNoOfMetrics := len(testObjects) * noOfMetricsExposedPerVolume
+	noOfMetricsExposedPerVolume := 4
+	expectedNoOfMetrics := len(testObjects)*noOfMetricsExposedPerVolume + len(totalInodes)
 	noOfMetricsReceived := 0
 	wg.Add(1)
 	go func() {
@@ -163,6 +175,24 @@ func TestVolumeStatsEmitter(t *testing.T) {
 					if int64(volObj.Status.TotalCapacity) != int64(*metricOut.Gauge.Value) {
 						t.Errorf("Expected Total capacity: %v But got %v", int64(volObj.Status.TotalCapacity), int64(*metricOut.Gauge.Value))
 					}
+				case metricStatsQuotaEnforced:
+					volObj, gErr := directCSIClient.DirectCSIVolumes().Get(ctx, volumeName, metav1.GetOptions{
+						TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+					})
+					if gErr != nil {
+						t.Fatalf("[%s] Volume (%s) not found. Error: %v", volumeName, volumeName, gErr)
+					}
+					if enforced := *metricOut.Gauge.Value == 1; enforced == volObj.Status.QuotaUnenforced {
+						t.Errorf("Expected quota enforced: %v But got %v", !volObj.Status.QuotaUnenforced, enforced)
+					}
+				case metricStatsInodesUsed:
+					if usedInodes[volumeName] != int64(*metricOut.Gauge.Value) {
+						t.Errorf("Expected used inodes: %v But got %v", usedInodes[volumeName], int64(*metricOut.Gauge.Value))
+					}
+				case metricStatsInodesTotal:
+					if totalInodes[volumeName] != int64(*metricOut.Gauge.Value) {
+						t.Errorf("Expected total inodes: %v But got %v", totalInodes[volumeName], int64(*metricOut.Gauge.Value))
+					}
 				default:
 					t.Errorf("Invalid metric type caught")
 				}
@@ -179,3 +209,273 @@ func TestVolumeStatsEmitter(t *testing.T) {
 	wg.Wait()
 	cancel()
 }
+
+func TestDriveStatsEmitter(t *testing.T) {
+	createTestDrive := func(driveName, nodeName string, major, minor uint32) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: driveName,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:    nodeName,
+				MajorNumber: major,
+				MinorNumber: minor,
+			},
+		}
+	}
+	createTestVolume := func(volName, driveName, nodeName string) *directcsi.DirectCSIVolume {
+		return &directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: volName,
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				NodeName: nodeName,
+				Drive:    driveName,
+			},
+		}
+	}
+
+	testDiskStatsGetter := func() ([]sys.DiskStats, error) {
+		return []sys.DiskStats{
+			{Major: 8, Minor: 16, Name: "sdb", ReadBytes: mb10, WriteBytes: mb20},
+			{Major: 8, Minor: 32, Name: "sdc", ReadBytes: mb20, WriteBytes: mb30},
+		}, nil
+	}
+
+	testObjects := []runtime.Object{
+		createTestDrive(testDriveName, testNodeName, 8, 16),
+		createTestDrive("test-drive-2", testNodeName, 8, 32),
+		createTestDrive("test-drive-3", "test-node-2", 8, 16),
+		createTestVolume("test-volume-1", testDriveName, testNodeName),
+		createTestVolume("test-volume-2", testDriveName, testNodeName),
+		createTestVolume("test-volume-3", "test-drive-3", "test-node-2"),
+	}
+
+	fmc := createFakeMetricsCollector()
+	fmc.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+
+	metricChan := make(chan prometheus.Metric, 10)
+	fmc.driveStatsEmitter(context.TODO(), metricChan, testDiskStatsGetter)
+	close(metricChan)
+
+	expected := map[string]float64{
+		"directcsi_volume_read_bytes_total":  mb10,
+		"directcsi_volume_write_bytes_total": mb20,
+	}
+	noOfMetricsReceived := 0
+	for metric := range metricChan {
+		metricOut := dto.Metric{}
+		metric.Write(&metricOut)
+		for _, lp := range metricOut.GetLabel() {
+			if lp.GetName() == "drive" && lp.GetValue() != testDriveName {
+				t.Errorf("Expected metrics only for drive %s, got %s", testDriveName, lp.GetValue())
+			}
+		}
+		fqName := getFQNameFromDesc(metric.Desc().String())
+		value, ok := expected[fqName]
+		if !ok {
+			t.Errorf("Invalid metric type caught: %s", fqName)
+			continue
+		}
+		if value != metricOut.GetCounter().GetValue() {
+			t.Errorf("Expected %s: %v But got %v", fqName, value, metricOut.GetCounter().GetValue())
+		}
+		noOfMetricsReceived = noOfMetricsReceived + 1
+	}
+	if noOfMetricsReceived != len(expected) {
+		t.Errorf("Expected %d metrics, got %d", len(expected), noOfMetricsReceived)
+	}
+}
+
+func TestTierStatsEmitter(t *testing.T) {
+	createTestDrive := func(driveName, nodeName string, tier directcsi.AccessTier, driveStatus directcsi.DriveStatus, free int64) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: driveName,
+				Labels: map[string]string{
+					utils.NodeLabel: utils.SanitizeLabelV(nodeName),
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:     nodeName,
+				AccessTier:   tier,
+				DriveStatus:  driveStatus,
+				FreeCapacity: free,
+			},
+		}
+	}
+
+	testObjects := []runtime.Object{
+		createTestDrive(testDriveName, testNodeName, directcsi.AccessTierHot, directcsi.DriveStatusReady, mb10),
+		createTestDrive("test-drive-2", testNodeName, directcsi.AccessTierHot, directcsi.DriveStatusInUse, mb20),
+		createTestDrive("test-drive-3", testNodeName, directcsi.AccessTierCold, directcsi.DriveStatusReady, mb30),
+		createTestDrive("test-drive-4", testNodeName, directcsi.AccessTierCold, directcsi.DriveStatusAvailable, mb30),
+		createTestDrive("test-drive-5", "test-node-2", directcsi.AccessTierHot, directcsi.DriveStatusReady, mb30),
+		createTestDrive("test-drive-6", testNodeName, "", directcsi.DriveStatusReady, mb10),
+		createTestDrive("test-drive-7", testNodeName, directcsi.AccessTierUnknown, directcsi.DriveStatusInUse, mb20),
+	}
+
+	fmc := createFakeMetricsCollector()
+	fmc.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+
+	metricChan := make(chan prometheus.Metric, 10)
+	fmc.tierStatsEmitter(context.TODO(), metricChan)
+	close(metricChan)
+
+	expected := map[string]float64{
+		"hot":     mb10 + mb20,
+		"cold":    mb30,
+		"unknown": mb10 + mb20,
+	}
+	noOfMetricsReceived := 0
+	for metric := range metricChan {
+		if fqName := getFQNameFromDesc(metric.Desc().String()); fqName != "directcsi_tier_free_bytes" {
+			t.Errorf("Invalid metric type caught: %s", fqName)
+			continue
+		}
+		metricOut := dto.Metric{}
+		metric.Write(&metricOut)
+		var tier string
+		for _, lp := range metricOut.GetLabel() {
+			switch lp.GetName() {
+			case "tier":
+				tier = lp.GetValue()
+			case "node":
+				if lp.GetValue() != testNodeName {
+					t.Errorf("Expected metrics only for node %s, got %s", testNodeName, lp.GetValue())
+				}
+			}
+		}
+		value, ok := expected[tier]
+		if !ok {
+			t.Errorf("Unexpected tier %s", tier)
+			continue
+		}
+		if value != metricOut.GetGauge().GetValue() {
+			t.Errorf("Expected free bytes for tier %s: %v But got %v", tier, value, metricOut.GetGauge().GetValue())
+		}
+		noOfMetricsReceived = noOfMetricsReceived + 1
+	}
+	if noOfMetricsReceived != len(expected) {
+		t.Errorf("Expected %d metrics, got %d", len(expected), noOfMetricsReceived)
+	}
+}
+
+func TestDriveHealthEmitter(t *testing.T) {
+	createTestDrive := func(driveName, nodeName string, health *directcsi.DirectCSIDriveSMART) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: driveName,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName: nodeName,
+				SMART:    health,
+			},
+		}
+	}
+
+	testObjects := []runtime.Object{
+		createTestDrive(testDriveName, testNodeName, &directcsi.DirectCSIDriveSMART{Passed: true, Temperature: 40, PercentageUsed: 7}),
+		createTestDrive("test-drive-2", testNodeName, &directcsi.DirectCSIDriveSMART{Passed: false, Temperature: 50, ReallocatedSectors: 8, PercentageUsed: -1}),
+		createTestDrive("test-drive-3", testNodeName, nil),
+		createTestDrive("test-drive-4", "test-node-2", &directcsi.DirectCSIDriveSMART{Passed: true, Temperature: 30}),
+	}
+
+	fmc := createFakeMetricsCollector()
+	fmc.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+
+	metricChan := make(chan prometheus.Metric, 10)
+	fmc.driveHealthEmitter(context.TODO(), metricChan)
+	close(metricChan)
+
+	expected := map[string]float64{
+		testDriveName + "/directcsi_drive_smart_passed":        1,
+		testDriveName + "/directcsi_drive_temperature_celsius": 40,
+		testDriveName + "/directcsi_drive_reallocated_sectors": 0,
+		testDriveName + "/directcsi_drive_wear_percent":        7,
+		"test-drive-2/directcsi_drive_smart_passed":            0,
+		"test-drive-2/directcsi_drive_temperature_celsius":     50,
+		"test-drive-2/directcsi_drive_reallocated_sectors":     8,
+	}
+	noOfMetricsReceived := 0
+	for metric := range metricChan {
+		fqName := getFQNameFromDesc(metric.Desc().String())
+		metricOut := dto.Metric{}
+		metric.Write(&metricOut)
+		var drive string
+		for _, lp := range metricOut.GetLabel() {
+			if lp.GetName() == "drive" {
+				drive = lp.GetValue()
+			}
+		}
+		value, ok := expected[drive+"/"+fqName]
+		if !ok {
+			t.Errorf("Unexpected metric %s for drive %s", fqName, drive)
+			continue
+		}
+		if value != metricOut.GetGauge().GetValue() {
+			t.Errorf("Expected %s of drive %s: %v But got %v", fqName, drive, value, metricOut.GetGauge().GetValue())
+		}
+		noOfMetricsReceived = noOfMetricsReceived + 1
+	}
+	if noOfMetricsReceived != len(expected) {
+		t.Errorf("Expected %d metrics, got %d", len(expected), noOfMetricsReceived)
+	}
+}
+
+func TestVolumeStatsPVCLabels(t *testing.T) {
+	vol := &directcsi.DirectCSIVolume{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-volume-1",
+			Labels: map[string]string{
+				tenantLabel:             testTenantName,
+				utils.PVCNameLabel:      "data-minio-0",
+				utils.PVCNamespaceLabel: "minio",
+			},
+		},
+		Status: directcsi.DirectCSIVolumeStatus{
+			NodeName:      testNodeName,
+			TotalCapacity: mb20,
+			ContainerPath: "/path/containerpath",
+		},
+	}
+	testStatsGetter := func(_ context.Context, vol *directcsi.DirectCSIVolume) (fs.VolumeStats, error) {
+		return fs.VolumeStats{TotalBytes: vol.Status.TotalCapacity}, nil
+	}
+
+	testCases := []struct {
+		pvcLabels      bool
+		expectedLabels map[string]string
+	}{
+		{
+			pvcLabels:      false,
+			expectedLabels: map[string]string{"tenant": testTenantName, "volumeID": "test-volume-1", "node": testNodeName},
+		},
+		{
+			pvcLabels:      true,
+			expectedLabels: map[string]string{"tenant": testTenantName, "volumeID": "test-volume-1", "node": testNodeName, "pvc": "data-minio-0", "namespace": "minio"},
+		},
+	}
+
+	for i, testCase := range testCases {
+		metricChan := make(chan prometheus.Metric, 10)
+		publishVolumeStats(context.TODO(), vol, metricChan, testStatsGetter, testCase.pvcLabels)
+		close(metricChan)
+		for metric := range metricChan {
+			metricOut := dto.Metric{}
+			metric.Write(&metricOut)
+			labels := map[string]string{}
+			for _, lp := range metricOut.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if !reflect.DeepEqual(labels, testCase.expectedLabels) {
+				t.Errorf("case %v: expected labels: %v, got: %v", i+1, testCase.expectedLabels, labels)
+			}
+		}
+	}
+}
@@ -18,11 +18,17 @@ package metrics
 
 import (
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"strings"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -30,10 +36,99 @@ const (
 	metricsPath = "direct-csi/metrics"
 )
 
-func ServeMetrics(ctx context.Context, nodeId string) {
+// ServerConfig - the options of the metrics server, the scrapes are not
+// authenticated if neither a token file nor a client CA file is set
+type ServerConfig struct {
+	// TokenFile holds the bearer token of the scrapes
+	TokenFile string
+	// CertFile and KeyFile serve the metrics over TLS
+	CertFile string
+	KeyFile  string
+	// ClientCAFile verifies the client certificates of the scrapes, it requires TLS
+	ClientCAFile string
+	// PVCLabels labels the volume stats by the name and namespace of the claims
+	PVCLabels bool
+}
+
+// Validate checks that the TLS files are set together
+func (c ServerConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("the certificate and the key of the metrics server must be set together")
+	}
+	if c.ClientCAFile != "" && c.CertFile == "" {
+		return errors.New("the client CA of the metrics server requires its certificate and key")
+	}
+	return nil
+}
+
+// readToken reads the bearer token of the scrapes, it is empty if no token file is set
+func (c ServerConfig) readToken() (string, error) {
+	if c.TokenFile == "" {
+		return "", nil
+	}
+	data, err := ioutil.ReadFile(c.TokenFile)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", c.TokenFile)
+	}
+	return token, nil
+}
+
+// tlsConfig verifies the client certificates given by the scrapes against the client CA, the
+// scrapes without a certificate are left to the bearer token check of authHandler
+func (c ServerConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.ClientCAFile == "" {
+		return config, nil
+	}
+	data, err := ioutil.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", c.ClientCAFile)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
+// authHandler rejects with 401 the scrapes with neither a verified client certificate nor the bearer token
+func authHandler(next http.Handler, token string, clientCertAuth bool) http.Handler {
+	if token == "" && !clientCertAuth {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clientCertAuth && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if token != "" {
+			auth := r.Header.Get("Authorization")
+			if strings.HasPrefix(auth, "Bearer ") &&
+				subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="direct-csi"`)
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func ServeMetrics(ctx context.Context, nodeId string, config ServerConfig) {
+
+	token, err := config.readToken()
+	if err != nil {
+		panic(err)
+	}
 
 	server := &http.Server{
-		Handler: metricsHandler(nodeId),
+		Handler: authHandler(metricsHandler(nodeId, config.PVCLabels), token, config.ClientCAFile != ""),
 	}
 
 	lc := net.ListenConfig{}
@@ -42,8 +137,17 @@ func ServeMetrics(ctx context.Context, nodeId string) {
 		panic(lErr)
 	}
 
-	klog.V(2).Infof("Starting metrics exporter in port: %s", port)
-	if err := server.Serve(listener); err != nil {
+	if config.CertFile != "" {
+		if server.TLSConfig, err = config.tlsConfig(); err != nil {
+			panic(err)
+		}
+		klog.V(2).Infof("Starting metrics exporter over TLS in port: %s", port)
+		err = server.ServeTLS(listener, config.CertFile, config.KeyFile)
+	} else {
+		klog.V(2).Infof("Starting metrics exporter in port: %s", port)
+		err = server.Serve(listener)
+	}
+	if err != nil {
 		klog.Errorf("Failed to listen and serve metrics server: %v", err)
 		if err != http.ErrServerClosed {
 			panic(err)
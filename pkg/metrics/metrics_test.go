@@ -0,0 +1,93 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerConfigValidate(t *testing.T) {
+	testCases := []struct {
+		config      ServerConfig
+		expectedErr bool
+	}{
+		{config: ServerConfig{}, expectedErr: false},
+		{config: ServerConfig{TokenFile: "/token"}, expectedErr: false},
+		{config: ServerConfig{CertFile: "/cert.pem", KeyFile: "/key.pem"}, expectedErr: false},
+		{config: ServerConfig{CertFile: "/cert.pem", KeyFile: "/key.pem", ClientCAFile: "/ca.pem"}, expectedErr: false},
+		{config: ServerConfig{CertFile: "/cert.pem"}, expectedErr: true},
+		{config: ServerConfig{KeyFile: "/key.pem"}, expectedErr: true},
+		{config: ServerConfig{ClientCAFile: "/ca.pem"}, expectedErr: true},
+	}
+
+	for i, testCase := range testCases {
+		err := testCase.config.Validate()
+		if testCase.expectedErr != (err != nil) {
+			t.Errorf("case %v: expected error: %v, got: %v", i+1, testCase.expectedErr, err)
+		}
+	}
+}
+
+func TestAuthHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	verifiedTLS := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{&x509.Certificate{}}}}
+
+	testCases := []struct {
+		token          string
+		clientCertAuth bool
+		authorization  string
+		tlsState       *tls.ConnectionState
+		expectedStatus int
+	}{
+		// no authentication configured
+		{expectedStatus: http.StatusOK},
+		// bearer token
+		{token: "secret", authorization: "Bearer secret", expectedStatus: http.StatusOK},
+		{token: "secret", authorization: "Bearer wrong", expectedStatus: http.StatusUnauthorized},
+		{token: "secret", authorization: "secret", expectedStatus: http.StatusUnauthorized},
+		{token: "secret", expectedStatus: http.StatusUnauthorized},
+		// client certificate
+		{clientCertAuth: true, tlsState: verifiedTLS, expectedStatus: http.StatusOK},
+		{clientCertAuth: true, tlsState: &tls.ConnectionState{}, expectedStatus: http.StatusUnauthorized},
+		{clientCertAuth: true, expectedStatus: http.StatusUnauthorized},
+		// either of them
+		{token: "secret", clientCertAuth: true, tlsState: verifiedTLS, expectedStatus: http.StatusOK},
+		{token: "secret", clientCertAuth: true, tlsState: &tls.ConnectionState{}, authorization: "Bearer secret", expectedStatus: http.StatusOK},
+	}
+
+	for i, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/"+metricsPath, nil)
+		if testCase.authorization != "" {
+			req.Header.Set("Authorization", testCase.authorization)
+		}
+		req.TLS = testCase.tlsState
+		rec := httptest.NewRecorder()
+		authHandler(next, testCase.token, testCase.clientCertAuth).ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedStatus {
+			t.Errorf("case %v: expected status: %v, got: %v", i+1, testCase.expectedStatus, rec.Code)
+		}
+		if rec.Code == http.StatusUnauthorized && testCase.token != "" && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("case %v: expected WWW-Authenticate header", i+1)
+		}
+	}
+}
@@ -18,11 +18,14 @@ package metrics
 
 import (
 	"context"
+	"strings"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
-	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/sys/fs"
+	"github.com/minio/direct-csi/pkg/utils"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -31,27 +34,22 @@ const (
 	tenantLabel = "direct.csi.min.io/tenant"
 )
 
-type xfsVolumeStatsGetter func(context.Context, *directcsi.DirectCSIVolume) (xfs.XFSVolumeStats, error)
+type volumeStatsGetter func(context.Context, *directcsi.DirectCSIVolume) (fs.VolumeStats, error)
 
-func getXFSVolumeStats(ctx context.Context, vol *directcsi.DirectCSIVolume) (xfs.XFSVolumeStats, error) {
-	xfsQuota := &xfs.XFSQuota{
-		Path:      vol.Status.StagingPath,
-		ProjectID: vol.Name,
-	}
-	volStats, err := xfsQuota.GetVolumeStats(ctx)
-	if err != nil {
-		return xfs.XFSVolumeStats{}, err
-	}
-	return volStats, nil
-}
+type diskStatsGetter func() ([]sys.DiskStats, error)
 
-func publishVolumeStats(ctx context.Context, vol *directcsi.DirectCSIVolume, ch chan<- prometheus.Metric, xfsStatsFn xfsVolumeStatsGetter) {
-	volStats, err := xfsStatsFn(ctx, vol)
+// GetVolumeStats returns the usage of the volume from the project quota of its staging path
+func GetVolumeStats(ctx context.Context, vol *directcsi.DirectCSIVolume) (fs.VolumeStats, error) {
+	quota, err := sys.NewQuota(vol.Status.StagingPath, vol.Name)
 	if err != nil {
-		klog.V(3).Infof("Error while getting xfs volume stats: %v", err)
-		return
+		return fs.VolumeStats{}, err
 	}
+	return quota.GetVolumeStats(ctx)
+}
 
+// publishVolumeStats publishes the usage of the volume, labeled by the claim of the volume
+// if pvcLabels is set, which adds a series per claim to correlate the usage with the workloads
+func publishVolumeStats(ctx context.Context, vol *directcsi.DirectCSIVolume, ch chan<- prometheus.Metric, statsFn volumeStatsGetter, pvcLabels bool) {
 	getTenantName := func() string {
 		labels := vol.ObjectMeta.GetLabels()
 		for k, v := range labels {
@@ -63,21 +61,197 @@ func publishVolumeStats(ctx context.Context, vol *directcsi.DirectCSIVolume, ch
 	}
 	tenantName := getTenantName()
 
+	labelNames := []string{"tenant", "volumeID", "node"}
+	labelValues := []string{tenantName, vol.Name, vol.Status.NodeName}
+	if pvcLabels {
+		labelNames = append(labelNames, "pvc", "namespace")
+		labelValues = append(labelValues, vol.GetLabels()[utils.PVCNameLabel], vol.GetLabels()[utils.PVCNamespaceLabel])
+	}
+
+	// published regardless of the usage, which cannot be read for volumes without quota
+	quotaEnforced := float64(1)
+	if vol.Status.QuotaUnenforced {
+		quotaEnforced = 0
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(
+			prometheus.BuildFQName("directcsi", "stats", "quota_enforced"),
+			"Whether the capacity limit of the volume is enforced by a project quota",
+			labelNames, nil),
+		prometheus.GaugeValue,
+		quotaEnforced, labelValues...,
+	)
+
+	volStats, err := statsFn(ctx, vol)
+	if err != nil {
+		klog.V(3).Infof("Error while getting volume stats: %v", err)
+		return
+	}
+
 	ch <- prometheus.MustNewConstMetric(
 		prometheus.NewDesc(
 			prometheus.BuildFQName("directcsi", "stats", "bytes_used"),
 			"Total number of bytes used by the volume",
-			[]string{"tenant", "volumeID", "node"}, nil),
+			labelNames, nil),
 		prometheus.GaugeValue,
-		float64(volStats.UsedBytes), string(tenantName), vol.Name, vol.Status.NodeName,
+		float64(volStats.UsedBytes), labelValues...,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
 		prometheus.NewDesc(
 			prometheus.BuildFQName("directcsi", "stats", "bytes_total"),
 			"Total number of bytes allocated to the volume",
-			[]string{"tenant", "volumeID", "node"}, nil),
+			labelNames, nil),
 		prometheus.GaugeValue,
-		float64(volStats.TotalBytes), string(tenantName), vol.Name, vol.Status.NodeName,
+		float64(volStats.TotalBytes), labelValues...,
 	)
+
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(
+			prometheus.BuildFQName("directcsi", "stats", "inodes_used"),
+			"Total number of inodes used by the volume",
+			labelNames, nil),
+		prometheus.GaugeValue,
+		float64(volStats.UsedInodes), labelValues...,
+	)
+
+	// the inodes are only limited for the volumes of storage classes with an inode limit
+	if volStats.TotalInodes > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "stats", "inodes_total"),
+				"Total number of inodes allocated to the volume",
+				labelNames, nil),
+			prometheus.GaugeValue,
+			float64(volStats.TotalInodes), labelValues...,
+		)
+	}
+}
+
+func publishDriveStats(drive *directcsi.DirectCSIDrive, diskStats []sys.DiskStats, ch chan<- prometheus.Metric) {
+	for _, stat := range diskStats {
+		if stat.Major != drive.Status.MajorNumber || stat.Minor != drive.Status.MinorNumber {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "volume", "read_bytes_total"),
+				"Total number of bytes read from the drive backing the volumes",
+				[]string{"node", "drive"}, nil),
+			prometheus.CounterValue,
+			float64(stat.ReadBytes), drive.Status.NodeName, drive.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "volume", "write_bytes_total"),
+				"Total number of bytes written to the drive backing the volumes",
+				[]string{"node", "drive"}, nil),
+			prometheus.CounterValue,
+			float64(stat.WriteBytes), drive.Status.NodeName, drive.Name,
+		)
+		return
+	}
+	klog.V(3).Infof("No diskstats found for drive %s (%d:%d)", drive.Name, drive.Status.MajorNumber, drive.Status.MinorNumber)
+}
+
+func publishTierStats(nodeID string, drives []directcsi.DirectCSIDrive, ch chan<- prometheus.Metric) {
+	freeBytes := map[directcsi.AccessTier]int64{}
+	reservedBytes := map[directcsi.AccessTier]int64{}
+	for _, drive := range drives {
+		if drive.Status.NodeName != nodeID {
+			continue
+		}
+		// only the drives owned by direct-csi provide capacity for volumes
+		switch drive.Status.DriveStatus {
+		case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+		default:
+			continue
+		}
+		// the drives without a tier are published as "unknown" like in the drives list
+		tier := drive.Status.AccessTier
+		if tier == "" {
+			tier = directcsi.AccessTierUnknown
+		}
+		freeBytes[tier] += drive.Status.FreeCapacity
+		if drive.Status.ReservedCapacity > 0 {
+			reservedBytes[tier] += drive.Status.ReservedCapacity
+		}
+	}
+
+	for tier, free := range freeBytes {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "tier", "free_bytes"),
+				"Total number of free bytes in the drives of the access tier",
+				[]string{"tier", "node"}, nil),
+			prometheus.GaugeValue,
+			float64(free), strings.ToLower(string(tier)), nodeID,
+		)
+	}
+
+	// the headroom reserved at format is not free for volumes, it is only published for the tiers reserving it
+	for tier, reserved := range reservedBytes {
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "tier", "reserved_bytes"),
+				"Total number of bytes reserved as filesystem headroom in the drives of the access tier",
+				[]string{"tier", "node"}, nil),
+			prometheus.GaugeValue,
+			float64(reserved), strings.ToLower(string(tier)), nodeID,
+		)
+	}
+}
+
+func publishDriveHealth(nodeID string, drives []directcsi.DirectCSIDrive, ch chan<- prometheus.Metric) {
+	for _, drive := range drives {
+		// the health is only recorded if the SMART collector is enabled on the node
+		if drive.Status.NodeName != nodeID || drive.Status.SMART == nil {
+			continue
+		}
+
+		passed := float64(0)
+		if drive.Status.SMART.Passed {
+			passed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "drive", "smart_passed"),
+				"Whether the drive passed its SMART health self-assessment",
+				[]string{"node", "drive"}, nil),
+			prometheus.GaugeValue,
+			passed, nodeID, drive.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "drive", "temperature_celsius"),
+				"Current temperature of the drive",
+				[]string{"node", "drive"}, nil),
+			prometheus.GaugeValue,
+			float64(drive.Status.SMART.Temperature), nodeID, drive.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc(
+				prometheus.BuildFQName("directcsi", "drive", "reallocated_sectors"),
+				"Number of reallocated sectors or grown defects of the drive",
+				[]string{"node", "drive"}, nil),
+			prometheus.GaugeValue,
+			float64(drive.Status.SMART.ReallocatedSectors), nodeID, drive.Name,
+		)
+
+		// the wear is only reported by NVMe and SCSI SSDs
+		if drive.Status.SMART.PercentageUsed >= 0 {
+			ch <- prometheus.MustNewConstMetric(
+				prometheus.NewDesc(
+					prometheus.BuildFQName("directcsi", "drive", "wear_percent"),
+					"Estimated percentage of the endurance of the drive used",
+					[]string{"node", "drive"}, nil),
+				prometheus.GaugeValue,
+				float64(drive.Status.SMART.PercentageUsed), nodeID, drive.Name,
+			)
+		}
+	}
 }
@@ -30,6 +30,8 @@ import (
 	"github.com/minio/direct-csi/pkg/utils"
 	rest "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -37,13 +39,9 @@ import (
 	simd "github.com/minio/sha256-simd"
 )
 
-const (
-	loopBackDeviceCount = 4
-)
-
 var unknownDriveCounter int32
 
-func NewDiscovery(ctx context.Context, identity, nodeID, rack, zone, region string) (*Discovery, error) {
+func NewDiscovery(ctx context.Context, identity, nodeID, rack, zone, region string, nodeTopology map[string]string, autoTier, allowRemovable bool) (*Discovery, error) {
 	kubeConfig := utils.GetKubeConfig()
 	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
 	if err != nil {
@@ -54,6 +52,10 @@ func NewDiscovery(ctx context.Context, identity, nodeID, rack, zone, region stri
 	}
 
 	topologies := map[string]string{}
+	// the segments of the node labels are added first, the keys of direct-csi take precedence
+	for key, value := range nodeTopology {
+		topologies[key] = value
+	}
 	topologies[topology.TopologyDriverIdentity] = identity
 	topologies[topology.TopologyDriverRack] = rack
 	topologies[topology.TopologyDriverZone] = zone
@@ -68,6 +70,9 @@ func NewDiscovery(ctx context.Context, identity, nodeID, rack, zone, region stri
 		NodeID:          nodeID,
 		directcsiClient: directClientset,
 		driveTopology:   topologies,
+		driveMounter:    &sys.DefaultDriveMounter{},
+		autoTier:        autoTier,
+		allowRemovable:  allowRemovable,
 	}
 
 	if err := d.readRemoteDrives(ctx); err != nil {
@@ -117,13 +122,13 @@ func (d *Discovery) readRemoteDrives(ctx context.Context) error {
 	return nil
 }
 
-func (d *Discovery) Init(ctx context.Context, loopBackOnly bool) error {
-	localDrives, err := d.findLocalDrives(ctx, loopBackOnly)
+func (d *Discovery) Init(ctx context.Context, loopBackOnly bool, loopBackCount int, deviceFilter sys.DeviceFilter) error {
+	localDrives, err := d.findLocalDrives(ctx, loopBackOnly, loopBackCount)
 	if err != nil {
 		return err
 	}
 
-	localDriveStates := d.toDirectCSIDriveStatus(localDrives)
+	localDriveStates := d.filterDriveStates(d.toDirectCSIDriveStatus(localDrives), deviceFilter)
 	var unidentifedDriveStates []directcsi.DirectCSIDriveStatus
 	if len(d.remoteDrives) == 0 {
 		for _, localDriveState := range localDriveStates {
@@ -159,6 +164,9 @@ func (d *Discovery) Init(ctx context.Context, loopBackOnly bool) error {
 		}
 	}
 
+	// Retain the remote drives of the devices seen under another name
+	d.identifyAliasedDrives(localDrives)
+
 	// Delete the unmapped remote drives
 	if err := d.deleteUnmatchedRemoteDrives(ctx); err != nil {
 		return err
@@ -167,10 +175,47 @@ func (d *Discovery) Init(ctx context.Context, loopBackOnly bool) error {
 	return nil
 }
 
+// skipReason returns why the device is skipped by the discovery, it is empty for the selected devices
+func skipReason(deviceFilter sys.DeviceFilter, path, rootPartition string, major uint32, totalCapacity uint64) string {
+	switch {
+	case sys.IsRAMDisk(path, major):
+		return "it is RAM backed"
+	case !deviceFilter.MatchSize(totalCapacity):
+		return fmt.Sprintf("it is smaller than %d bytes", deviceFilter.MinSize)
+	case !deviceFilter.Match(sys.GetRootBlockPath(path), sys.GetRootBlockPath(rootPartition)):
+		return "it is excluded by the device filter"
+	}
+	return ""
+}
+
+// filterDriveStates drops the drives not selected by the device filter, the RAM
+// backed devices and the devices smaller than the minimum size of the filter. The
+// remote drives of the excluded devices are marked as matched, so that they are
+// left untouched instead of deleted.
+func (d *Discovery) filterDriveStates(driveStates []directcsi.DirectCSIDriveStatus, deviceFilter sys.DeviceFilter) []directcsi.DirectCSIDriveStatus {
+	filtered := []directcsi.DirectCSIDriveStatus{}
+	for _, driveState := range driveStates {
+		reason := skipReason(deviceFilter, driveState.Path, driveState.RootPartition, driveState.MajorNumber, uint64(driveState.TotalCapacity))
+		if reason == "" {
+			filtered = append(filtered, driveState)
+			continue
+		}
+		klog.V(3).Infof("Skipping the device %s as %s", driveState.Path, reason)
+		if _, err := d.Identify(driveState); err == nil {
+			klog.Warningf("Device %s is skipped as %s but is already managed as a drive", driveState.Path, reason)
+		}
+	}
+	return filtered
+}
+
 func (d *Discovery) createNewDrive(ctx context.Context, localDriveState directcsi.DirectCSIDriveStatus) error {
 	directCSIClient := d.directcsiClient.DirectV1beta2()
 	driveClient := directCSIClient.DirectCSIDrives()
 
+	// the tier is assigned only at registration, the tier of the existing drives is never overwritten
+	if d.autoTier && localDriveState.AccessTier == directcsi.AccessTierUnknown {
+		localDriveState.AccessTier = autoAccessTier(localDriveState)
+	}
 	newDrive := makeDirectCSIDrive(localDriveState, "")
 	if _, err := driveClient.Create(ctx, newDrive, metav1.CreateOptions{}); err != nil {
 		return err
@@ -179,6 +224,20 @@ func (d *Discovery) createNewDrive(ctx context.Context, localDriveState directcs
 	return nil
 }
 
+// autoAccessTier picks the access-tier of the drive, hot for the SSD and NVMe drives and cold for the spinning disks
+func autoAccessTier(driveStatus directcsi.DirectCSIDriveStatus) directcsi.AccessTier {
+	model := strings.ToUpper(driveStatus.ModelNumber)
+	switch {
+	case strings.HasPrefix(driveStatus.RootPartition, "nvme"), strings.Contains(model, "SSD"), strings.Contains(model, "NVME"):
+		// some controllers report their SSDs as rotational
+		return directcsi.AccessTierHot
+	case driveStatus.Rotational:
+		return directcsi.AccessTierCold
+	default:
+		return directcsi.AccessTierHot
+	}
+}
+
 func (d *Discovery) syncRemoteDrive(ctx context.Context, localDriveState directcsi.DirectCSIDriveStatus, remoteDrive *remoteDrive) error {
 	identifiedLegacyDrive := makeDirectCSIDrive(localDriveState, remoteDrive.Name)
 	if err := d.syncDrive(ctx, identifiedLegacyDrive); err != nil {
@@ -206,19 +265,28 @@ func makeDirectCSIDrive(driveStatus directcsi.DirectCSIDriveStatus, driveName st
 	}
 }
 
-func (d *Discovery) findLocalDrives(ctx context.Context, loopBackOnly bool) ([]sys.BlockDevice, error) {
+func (d *Discovery) findLocalDrives(ctx context.Context, loopBackOnly bool, loopBackCount int) ([]sys.BlockDevice, error) {
 	if loopBackOnly {
 		// Flush the existing loopback setups
 		if err := sys.FlushLoopBackReservations(); err != nil {
 			return []sys.BlockDevice{}, err
 		}
 		// Reserve loopbacks
-		if err := sys.ReserveLoopbackDevices(loopBackDeviceCount); err != nil {
+		if err := sys.ReserveLoopbackDevices(loopBackCount); err != nil {
 			return []sys.BlockDevice{}, err
 		}
 	}
 
-	devs, err := sys.FindDevices(ctx, loopBackOnly)
+	// the devices torn down during the walk are skipped by FindDevices, the failures
+	// of the whole walk are retried as they may be transient too
+	var devs []sys.BlockDevice
+	err := retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		klog.V(3).Infof("Retrying the discovery of the devices: %v", err)
+		return ctx.Err() == nil
+	}, func() (err error) {
+		devs, err = sys.FindDevices(ctx, loopBackOnly)
+		return err
+	})
 	if err != nil {
 		return []sys.BlockDevice{}, err
 	}
@@ -233,7 +301,7 @@ func (d *Discovery) toDirectCSIDriveStatus(localDrives []sys.BlockDevice) []dire
 		partitions := localDrive.GetPartitions()
 		if len(partitions) > 0 {
 			for _, partition := range partitions {
-				driveStatus := d.directCSIDriveStatusFromPartition(nodeID, partition, localDrive.Devname, localDrive.DeviceError)
+				driveStatus := d.directCSIDriveStatusFromPartition(nodeID, partition, localDrive.Devname, localDrive.DeviceError, localDrive.IsSystemDisk)
 				driveStatusList = append(driveStatusList, driveStatus)
 			}
 			continue
@@ -244,11 +312,99 @@ func (d *Discovery) toDirectCSIDriveStatus(localDrives []sys.BlockDevice) []dire
 	return driveStatusList
 }
 
-func (d *Discovery) directCSIDriveStatusFromPartition(nodeID string, partition sys.Partition, rootPartition string, blockErr error) directcsi.DirectCSIDriveStatus {
-	var fs, UUID string
+// isMountedAsRoot checks if the filesystem is mounted as the root filesystem of the node
+func isMountedAsRoot(fsInfo *sys.FSInfo) bool {
+	if fsInfo == nil {
+		return false
+	}
+	for _, m := range fsInfo.Mounts {
+		if m.Mountpoint == "/" {
+			return true
+		}
+	}
+	return false
+}
+
+// systemDiskReason is reported for the disks backing the root or boot filesystem or the active swap
+const systemDiskReason = "system-disk"
+
+// cryptMemberReason is reported for the LUKS containers and the devices backing an opened
+// dm-crypt mapping, the opened /dev/mapper device is discovered as a drive of its own
+const cryptMemberReason = "crypt-member"
+
+// raidMemberReason is reported for the members of an MD array, the assembled
+// /dev/mdX device is discovered as a drive of its own
+const raidMemberReason = "raid-member"
+
+// removableReason is reported for the removable media like USB sticks and SD cards
+// unless they are allowed explicitly, as they would be formatted as soon as they are plugged in
+const removableReason = "removable"
+
+// availabilityCheck is a check of the discovery which makes the drive unavailable if it fails
+type availabilityCheck struct {
+	name   string
+	failed bool
+	reason func() string
+}
+
+// reasonOf returns the static reason of a check
+func reasonOf(reason string) func() string {
+	return func() string { return reason }
+}
+
+// partitionChecks returns the availability checks of the partition in the order they are applied
+func partitionChecks(partition sys.Partition, blockErr error, systemDisk, allowRemovable bool) []availabilityCheck {
+	partitionType, isSystemPartition := gpt.SystemPartitionTypes[partition.TypeUUID]
+	return []availabilityCheck{
+		{name: "system disk", failed: systemDisk, reason: reasonOf(systemDiskReason)},
+		{name: "block probe", failed: blockErr != nil, reason: func() string { return fmt.Sprintf("failed to probe the drive: %v", blockErr) }},
+		{name: "crypt member", failed: partition.IsCryptMember, reason: reasonOf(cryptMemberReason)},
+		{name: "raid member", failed: partition.IsRAIDMember, reason: reasonOf(raidMemberReason)},
+		{name: "removable", failed: partition.Removable && !allowRemovable, reason: reasonOf(removableReason)},
+		{name: "system partition", failed: isSystemPartition, reason: func() string { return fmt.Sprintf("system partition (%s)", partitionType) }},
+		{name: "root filesystem", failed: isMountedAsRoot(partition.FSInfo), reason: reasonOf("mounted as the root filesystem")},
+	}
+}
+
+// rootChecks returns the availability checks of the drive in the order they are applied
+func rootChecks(blockDevice sys.BlockDevice, allowRemovable bool) []availabilityCheck {
+	return []availabilityCheck{
+		{name: "system disk", failed: blockDevice.IsSystemDisk, reason: reasonOf(systemDiskReason)},
+		{name: "block probe", failed: blockDevice.DeviceError != nil, reason: func() string { return fmt.Sprintf("failed to probe the drive: %v", blockDevice.DeviceError) }},
+		{name: "crypt member", failed: blockDevice.IsCryptMember, reason: reasonOf(cryptMemberReason)},
+		{name: "raid member", failed: blockDevice.IsRAIDMember, reason: reasonOf(raidMemberReason)},
+		{name: "removable", failed: blockDevice.Removable && !allowRemovable, reason: reasonOf(removableReason)},
+		{name: "kernel partitions", failed: blockDevice.HasKernelPartitions, reason: reasonOf("has partitions not listed in its partition table")},
+		{name: "root filesystem", failed: isMountedAsRoot(blockDevice.FSInfo), reason: reasonOf("mounted as the root filesystem")},
+	}
+}
+
+// firstFailure returns the reason of the first failed check, empty if all the checks passed
+func firstFailure(checks []availabilityCheck) string {
+	for _, check := range checks {
+		if check.failed {
+			return check.reason()
+		}
+	}
+	return ""
+}
+
+// partitionUnavailableReason returns the reason why the partition cannot be managed, empty if it is available
+func partitionUnavailableReason(partition sys.Partition, blockErr error, systemDisk, allowRemovable bool) string {
+	return firstFailure(partitionChecks(partition, blockErr, systemDisk, allowRemovable))
+}
+
+// rootUnavailableReason returns the reason why the drive cannot be managed, empty if it is available
+func rootUnavailableReason(blockDevice sys.BlockDevice, allowRemovable bool) string {
+	return firstFailure(rootChecks(blockDevice, allowRemovable))
+}
+
+func (d *Discovery) directCSIDriveStatusFromPartition(nodeID string, partition sys.Partition, rootPartition string, blockErr error, systemDisk bool) directcsi.DirectCSIDriveStatus {
+	var fs, UUID, label string
 	if partition.FSInfo != nil {
 		fs = string(partition.FSInfo.FSType)
 		UUID = string(partition.FSInfo.UUID)
+		label = partition.FSInfo.Label
 	}
 
 	var allocatedCapacity, freeCapacity, totalCapacity int64
@@ -266,21 +422,29 @@ func (d *Discovery) directCSIDriveStatusFromPartition(nodeID string, partition s
 	driveStatus = directcsi.DriveStatusAvailable
 	if partition.FSInfo != nil {
 		mounts = partition.FSInfo.Mounts
-		for _, m := range mounts {
-			if m.Mountpoint == "/" {
-				driveStatus = directcsi.DriveStatusUnavailable
-			}
-		}
 		if len(mounts) > 0 {
 			mountOptions = mounts[0].MountFlags
 			mountPoint = mounts[0].Mountpoint
 		}
 	}
-	_, ok := gpt.SystemPartitionTypes[partition.TypeUUID]
-	if ok || blockErr != nil {
+	if partitionUnavailableReason(partition, blockErr, systemDisk, d.allowRemovable) != "" {
 		driveStatus = directcsi.DriveStatusUnavailable
 	}
 
+	ownedReason := directcsi.DirectCSIDriveReasonNotAdded
+	if partition.Removable && !d.allowRemovable {
+		ownedReason = directcsi.DirectCSIDriveReasonRemovable
+	}
+	if partition.IsCryptMember {
+		ownedReason = directcsi.DirectCSIDriveReasonCryptMember
+	}
+	if partition.IsRAIDMember {
+		ownedReason = directcsi.DirectCSIDriveReasonRAIDMember
+	}
+	if systemDisk {
+		ownedReason = directcsi.DirectCSIDriveReasonSystemDisk
+	}
+
 	blockInitializationStatus := metav1.ConditionTrue
 	if blockErr != nil {
 		blockInitializationStatus = metav1.ConditionFalse
@@ -302,7 +466,7 @@ func (d *Discovery) directCSIDriveStatusFromPartition(nodeID string, partition s
 		FreeCapacity:      freeCapacity,
 		AllocatedCapacity: allocatedCapacity,
 		LogicalBlockSize:  int64(partition.LogicalBlockSize),
-		ModelNumber:       "", // Fix Me
+		ModelNumber:       partition.Model,
 		MountOptions:      mountOptions,
 		Mountpoint:        mountPoint,
 		NodeName:          nodeID,
@@ -312,15 +476,31 @@ func (d *Discovery) directCSIDriveStatusFromPartition(nodeID string, partition s
 		RootPartition:     rootPartition,
 		SerialNumber:      partition.SerialNumber,
 		TotalCapacity:     totalCapacity,
+		DeviceCapacity:    int64(partition.TotalCapacity),
 		FilesystemUUID:    UUID,
 		PartitionUUID:     partition.PartitionGUID,
 		MajorNumber:       partition.Major,
 		MinorNumber:       partition.Minor,
+		FirmwareRevision:  partition.FirmwareRevision,
+		NamespaceID:       partition.NamespaceID,
+		ControllerSerial:  partition.ControllerSerial,
+		SharedNamespace:   partition.SharedNamespace,
+		FilesystemLabel:   label,
+		Rotational:        partition.Rotational,
+		Removable:         partition.Removable,
+		ReadOnly:          partition.ReadOnly,
+		IOScheduler:       partition.IOScheduler,
+		NrRequests:        partition.NrRequests,
+		ReadAheadKB:       partition.ReadAheadKB,
+		ByIDPath:          partition.ByIDPath,
+		ByPath:            partition.ByPath,
+		Enclosure:         partition.Enclosure,
+		Slot:              partition.Slot,
 		Conditions: []metav1.Condition{
 			{
 				Type:               string(directcsi.DirectCSIDriveConditionOwned),
 				Status:             metav1.ConditionFalse,
-				Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+				Reason:             string(ownedReason),
 				LastTransitionTime: metav1.Now(),
 			},
 			{
@@ -355,10 +535,11 @@ func (d *Discovery) directCSIDriveStatusFromPartition(nodeID string, partition s
 }
 
 func (d *Discovery) directCSIDriveStatusFromRoot(nodeID string, blockDevice sys.BlockDevice) directcsi.DirectCSIDriveStatus {
-	var fs, UUID string
+	var fs, UUID, label string
 	if blockDevice.FSInfo != nil {
 		fs = string(blockDevice.FSInfo.FSType)
 		UUID = string(blockDevice.FSInfo.UUID)
+		label = blockDevice.FSInfo.Label
 	}
 
 	var freeCapacity, totalCapacity, allocatedCapacity int64
@@ -376,20 +557,35 @@ func (d *Discovery) directCSIDriveStatusFromRoot(nodeID string, blockDevice sys.
 	driveStatus = directcsi.DriveStatusAvailable
 	if blockDevice.FSInfo != nil {
 		mounts = blockDevice.FSInfo.Mounts
-		for _, m := range mounts {
-			if m.Mountpoint == "/" {
-				driveStatus = directcsi.DriveStatusUnavailable
-			}
-		}
 		if len(mounts) > 0 {
 			mountOptions = mounts[0].MountFlags
 			mountPoint = mounts[0].Mountpoint
 		}
 	}
+	if rootUnavailableReason(blockDevice, d.allowRemovable) != "" {
+		driveStatus = directcsi.DriveStatusUnavailable
+	}
+
+	// formatting the whole drive would destroy the data on its partitions
+	ownedReason := directcsi.DirectCSIDriveReasonNotAdded
+	if blockDevice.Removable && !d.allowRemovable {
+		ownedReason = directcsi.DirectCSIDriveReasonRemovable
+	}
+	if blockDevice.HasKernelPartitions {
+		ownedReason = directcsi.DirectCSIDriveReasonHasPartitions
+	}
+	if blockDevice.IsCryptMember {
+		ownedReason = directcsi.DirectCSIDriveReasonCryptMember
+	}
+	if blockDevice.IsRAIDMember {
+		ownedReason = directcsi.DirectCSIDriveReasonRAIDMember
+	}
+	if blockDevice.IsSystemDisk {
+		ownedReason = directcsi.DirectCSIDriveReasonSystemDisk
+	}
 
 	blockInitializationStatus := metav1.ConditionTrue
 	if blockDevice.DeviceError != nil {
-		driveStatus = directcsi.DriveStatusUnavailable
 		blockInitializationStatus = metav1.ConditionFalse
 	}
 
@@ -409,7 +605,7 @@ func (d *Discovery) directCSIDriveStatusFromRoot(nodeID string, blockDevice sys.
 		FreeCapacity:      freeCapacity,
 		AllocatedCapacity: allocatedCapacity,
 		LogicalBlockSize:  int64(blockDevice.LogicalBlockSize),
-		ModelNumber:       "", // Fix Me
+		ModelNumber:       blockDevice.Model,
 		MountOptions:      mountOptions,
 		Mountpoint:        mountPoint,
 		NodeName:          nodeID,
@@ -419,15 +615,32 @@ func (d *Discovery) directCSIDriveStatusFromRoot(nodeID string, blockDevice sys.
 		RootPartition:     blockDevice.Devname,
 		SerialNumber:      blockDevice.SerialNumber,
 		TotalCapacity:     totalCapacity,
+		DeviceCapacity:    int64(blockDevice.TotalCapacity),
 		FilesystemUUID:    UUID,
 		PartitionUUID:     "",
 		MajorNumber:       blockDevice.Major,
 		MinorNumber:       blockDevice.Minor,
+		FirmwareRevision:  blockDevice.FirmwareRevision,
+		NamespaceID:       blockDevice.NamespaceID,
+		ControllerSerial:  blockDevice.ControllerSerial,
+		SharedNamespace:   blockDevice.SharedNamespace,
+		FilesystemLabel:   label,
+		RAIDMembers:       blockDevice.RAIDMembers,
+		Rotational:        blockDevice.Rotational,
+		Removable:         blockDevice.Removable,
+		ReadOnly:          blockDevice.ReadOnly,
+		IOScheduler:       blockDevice.IOScheduler,
+		NrRequests:        blockDevice.NrRequests,
+		ReadAheadKB:       blockDevice.ReadAheadKB,
+		ByIDPath:          blockDevice.ByIDPath,
+		ByPath:            blockDevice.ByPath,
+		Enclosure:         blockDevice.Enclosure,
+		Slot:              blockDevice.Slot,
 		Conditions: []metav1.Condition{
 			{
 				Type:               string(directcsi.DirectCSIDriveConditionOwned),
 				Status:             metav1.ConditionFalse,
-				Reason:             string(directcsi.DirectCSIDriveReasonNotAdded),
+				Reason:             string(ownedReason),
 				LastTransitionTime: metav1.Now(),
 			},
 			{
@@ -0,0 +1,758 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDirectCSIDriveStatusFromRoot(t *testing.T) {
+	testCases := []struct {
+		name                string
+		blockDevice         sys.BlockDevice
+		expectedDriveStatus directcsi.DriveStatus
+		expectedReason      directcsi.DirectCSIDriveReason
+	}{
+		{
+			name: "unpartitioned",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdb",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdb"},
+			},
+			expectedDriveStatus: directcsi.DriveStatusAvailable,
+			expectedReason:      directcsi.DirectCSIDriveReasonNotAdded,
+		},
+		{
+			name: "partitionsNotInPartitionTable",
+			blockDevice: sys.BlockDevice{
+				Devname:             "sdc",
+				HasKernelPartitions: true,
+				DriveInfo:           &sys.DriveInfo{Path: "/dev/sdc"},
+			},
+			expectedDriveStatus: directcsi.DriveStatusUnavailable,
+			expectedReason:      directcsi.DirectCSIDriveReasonHasPartitions,
+		},
+		{
+			name: "systemDisk",
+			blockDevice: sys.BlockDevice{
+				Devname:      "sda",
+				IsSystemDisk: true,
+				DriveInfo:    &sys.DriveInfo{Path: "/dev/sda", FSInfo: &sys.FSInfo{FSType: "xfs"}},
+			},
+			expectedDriveStatus: directcsi.DriveStatusUnavailable,
+			expectedReason:      directcsi.DirectCSIDriveReasonSystemDisk,
+		},
+		{
+			name: "cryptMember",
+			blockDevice: sys.BlockDevice{
+				Devname:       "sdd",
+				IsCryptMember: true,
+				MasterInfo:    sys.MasterInfo{Master: "dm-0"},
+				DriveInfo:     &sys.DriveInfo{Path: "/dev/sdd"},
+			},
+			expectedDriveStatus: directcsi.DriveStatusUnavailable,
+			expectedReason:      directcsi.DirectCSIDriveReasonCryptMember,
+		},
+		{
+			name: "raidMember",
+			blockDevice: sys.BlockDevice{
+				Devname:      "sdf",
+				IsRAIDMember: true,
+				MasterInfo:   sys.MasterInfo{Master: "md0"},
+				DriveInfo:    &sys.DriveInfo{Path: "/dev/sdf"},
+			},
+			expectedDriveStatus: directcsi.DriveStatusUnavailable,
+			expectedReason:      directcsi.DirectCSIDriveReasonRAIDMember,
+		},
+		{
+			name: "raidArray",
+			blockDevice: sys.BlockDevice{
+				Devname:     "md0",
+				RAIDMembers: []string{"/dev/sdf", "/dev/sdg"},
+				DriveInfo:   &sys.DriveInfo{Path: "/dev/md0"},
+			},
+			expectedDriveStatus: directcsi.DriveStatusAvailable,
+			expectedReason:      directcsi.DirectCSIDriveReasonNotAdded,
+		},
+	}
+
+	d := &Discovery{NodeID: "test-node"}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			driveStatus := d.directCSIDriveStatusFromRoot(d.NodeID, tt.blockDevice)
+			if driveStatus.DriveStatus != tt.expectedDriveStatus {
+				t.Errorf("Test case name %s: Expected drive status = %s, got %s", tt.name, tt.expectedDriveStatus, driveStatus.DriveStatus)
+			}
+			if !utils.IsCondition(driveStatus.Conditions,
+				string(directcsi.DirectCSIDriveConditionOwned),
+				metav1.ConditionFalse,
+				string(tt.expectedReason),
+				"") {
+				t.Errorf("Test case name %s: unexpected status.condition for %s = %v", tt.name, string(directcsi.DirectCSIDriveConditionOwned), driveStatus.Conditions)
+			}
+			if !reflect.DeepEqual(driveStatus.RAIDMembers, tt.blockDevice.RAIDMembers) {
+				t.Errorf("Test case name %s: Expected raid members = %v, got %v", tt.name, tt.blockDevice.RAIDMembers, driveStatus.RAIDMembers)
+			}
+		})
+	}
+}
+
+func TestPreviewDrives(t *testing.T) {
+	testCases := []struct {
+		name             string
+		blockDevice      sys.BlockDevice
+		deviceFilter     sys.DeviceFilter
+		allowRemovable   bool
+		expectedPreviews []sys.DrivePreview
+	}{
+		{
+			name: "unpartitioned",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdb",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdb", TotalCapacity: 1024},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdb", TotalCapacity: 1024, DriveStatus: string(directcsi.DriveStatusAvailable)},
+			},
+		},
+		{
+			name: "probeError",
+			blockDevice: sys.BlockDevice{
+				Devname:     "sdc",
+				DeviceError: errors.New("device busy"),
+				DriveInfo:   &sys.DriveInfo{Path: "/dev/sdc"},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdc", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "failed to probe the drive: device busy"},
+			},
+		},
+		{
+			name: "bootDisk",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sda",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sda"},
+				Partitions: []sys.Partition{
+					{
+						PartitionNum: 1,
+						TypeUUID:     "C12A7328-F81F-11D2-BA4B-00A0C93EC93B",
+						DriveInfo:    &sys.DriveInfo{Path: "/dev/sda1"},
+					},
+					{
+						PartitionNum: 2,
+						DriveInfo: &sys.DriveInfo{
+							Path: "/dev/sda2",
+							FSInfo: &sys.FSInfo{
+								FSType: "ext4",
+								Mounts: []sys.MountInfo{{Mountpoint: "/"}},
+							},
+						},
+					},
+					{
+						PartitionNum: 3,
+						DriveInfo: &sys.DriveInfo{
+							Path:   "/dev/sda3",
+							FSInfo: &sys.FSInfo{FSType: "xfs", UUID: "d79dff9e-2884-46f2-8919-dada2eecb12d", Label: "backup"},
+						},
+					},
+				},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sda1", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "system partition (EFI System partition)"},
+				{Path: "/dev/sda2", Filesystem: "ext4", Mountpoint: "/", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "mounted as the root filesystem"},
+				{Path: "/dev/sda3", Filesystem: "xfs", FilesystemUUID: "d79dff9e-2884-46f2-8919-dada2eecb12d", Label: "backup", DriveStatus: string(directcsi.DriveStatusAvailable)},
+			},
+		},
+		{
+			name: "systemDisk",
+			blockDevice: sys.BlockDevice{
+				Devname:      "nvme0n1",
+				IsSystemDisk: true,
+				DriveInfo:    &sys.DriveInfo{Path: "/dev/nvme0n1"},
+				Partitions: []sys.Partition{
+					{
+						PartitionNum: 1,
+						DriveInfo: &sys.DriveInfo{
+							Path:   "/dev/nvme0n1p1",
+							FSInfo: &sys.FSInfo{FSType: "LVM2_member"},
+						},
+					},
+				},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/nvme0n1p1", Filesystem: "LVM2_member", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "system-disk"},
+			},
+		},
+		{
+			name: "cryptMemberPartition",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sde",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sde"},
+				Partitions: []sys.Partition{
+					{
+						PartitionNum:  1,
+						IsCryptMember: true,
+						MasterInfo:    sys.MasterInfo{Parent: "sde", Master: "dm-1"},
+						DriveInfo:     &sys.DriveInfo{Path: "/dev/sde1"},
+					},
+					{
+						PartitionNum: 2,
+						MasterInfo:   sys.MasterInfo{Parent: "sde"},
+						DriveInfo:    &sys.DriveInfo{Path: "/dev/sde2"},
+					},
+				},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sde1", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "crypt-member"},
+				{Path: "/dev/sde2", DriveStatus: string(directcsi.DriveStatusAvailable)},
+			},
+		},
+		{
+			name: "raidMemberPartition",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdg",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdg"},
+				Partitions: []sys.Partition{
+					{
+						PartitionNum: 1,
+						IsRAIDMember: true,
+						MasterInfo:   sys.MasterInfo{Parent: "sdg", Master: "md0"},
+						DriveInfo:    &sys.DriveInfo{Path: "/dev/sdg1"},
+					},
+				},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdg1", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "raid-member"},
+			},
+		},
+		{
+			name: "removable",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdh",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdh", Removable: true},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdh", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "removable"},
+			},
+		},
+		{
+			name: "removableAllowed",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdh",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdh", Removable: true},
+			},
+			allowRemovable: true,
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdh", DriveStatus: string(directcsi.DriveStatusAvailable)},
+			},
+		},
+		{
+			name: "removablePartition",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdi",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdi", Removable: true},
+				Partitions: []sys.Partition{
+					{
+						PartitionNum: 1,
+						MasterInfo:   sys.MasterInfo{Parent: "sdi"},
+						DriveInfo:    &sys.DriveInfo{Path: "/dev/sdi1", Removable: true},
+					},
+				},
+			},
+			expectedPreviews: []sys.DrivePreview{
+				{Path: "/dev/sdi1", DriveStatus: string(directcsi.DriveStatusUnavailable), Reason: "removable"},
+			},
+		},
+		{
+			name: "excluded",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdj",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdj", TotalCapacity: 1024},
+			},
+			deviceFilter:     sys.DeviceFilter{Exclude: []string{"/dev/sdj"}},
+			expectedPreviews: []sys.DrivePreview{},
+		},
+		{
+			name: "excludedPartition",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdk",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdk"},
+				Partitions: []sys.Partition{
+					{
+						PartitionNum: 1,
+						DriveInfo:    &sys.DriveInfo{Path: "/dev/sdk1", TotalCapacity: 1024},
+					},
+				},
+			},
+			deviceFilter:     sys.DeviceFilter{Exclude: []string{"/dev/sdk"}},
+			expectedPreviews: []sys.DrivePreview{},
+		},
+		{
+			name: "small",
+			blockDevice: sys.BlockDevice{
+				Devname:   "sdl",
+				DriveInfo: &sys.DriveInfo{Path: "/dev/sdl", TotalCapacity: 1024},
+			},
+			deviceFilter:     sys.DeviceFilter{MinSize: 2048},
+			expectedPreviews: []sys.DrivePreview{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			previews := previewDrives([]sys.BlockDevice{tt.blockDevice}, tt.deviceFilter, tt.allowRemovable, false)
+			if !reflect.DeepEqual(previews, tt.expectedPreviews) {
+				t.Errorf("Test case name %s: Expected previews = %+v, got %+v", tt.name, tt.expectedPreviews, previews)
+			}
+		})
+	}
+}
+
+func TestPreviewDrivesTrace(t *testing.T) {
+	blockDevice := sys.BlockDevice{
+		Devname:      "sdb",
+		IsRAIDMember: true,
+		DriveInfo:    &sys.DriveInfo{Path: "/dev/sdb", Major: 8, Minor: 16, TotalCapacity: 1024},
+	}
+
+	previews := previewDrives([]sys.BlockDevice{blockDevice}, sys.DeviceFilter{}, false, true)
+	if len(previews) != 1 {
+		t.Fatalf("Expected one preview, got %+v", previews)
+	}
+	expectedTrace := []string{
+		`attributes: dev=8:16 size=1024 rotational=false removable=false serial="" model="" wwid=""`,
+		"system disk: passed",
+		"block probe: passed",
+		"crypt member: passed",
+		"raid member: failed, raid-member",
+		"removable: passed",
+		"kernel partitions: passed",
+		"root filesystem: passed",
+	}
+	if !reflect.DeepEqual(previews[0].Trace, expectedTrace) {
+		t.Errorf("Expected trace = %q, got %q", expectedTrace, previews[0].Trace)
+	}
+	if previews[0].Reason != raidMemberReason {
+		t.Errorf("Expected reason %s, got %s", raidMemberReason, previews[0].Reason)
+	}
+}
+
+func TestAutoAccessTier(t *testing.T) {
+	testCases := []struct {
+		name         string
+		autoTier     bool
+		driveStatus  directcsi.DirectCSIDriveStatus
+		expectedTier directcsi.AccessTier
+	}{
+		{
+			name:         "disabled",
+			autoTier:     false,
+			driveStatus:  directcsi.DirectCSIDriveStatus{RootPartition: "sdb", Rotational: true, AccessTier: directcsi.AccessTierUnknown},
+			expectedTier: directcsi.AccessTierUnknown,
+		},
+		{
+			name:         "rotational",
+			autoTier:     true,
+			driveStatus:  directcsi.DirectCSIDriveStatus{RootPartition: "sdb", Rotational: true, AccessTier: directcsi.AccessTierUnknown},
+			expectedTier: directcsi.AccessTierCold,
+		},
+		{
+			name:         "nonRotational",
+			autoTier:     true,
+			driveStatus:  directcsi.DirectCSIDriveStatus{RootPartition: "sdc", AccessTier: directcsi.AccessTierUnknown},
+			expectedTier: directcsi.AccessTierHot,
+		},
+		{
+			name:         "nvme",
+			autoTier:     true,
+			driveStatus:  directcsi.DirectCSIDriveStatus{RootPartition: "nvme0n1", Rotational: true, AccessTier: directcsi.AccessTierUnknown},
+			expectedTier: directcsi.AccessTierHot,
+		},
+		{
+			name:         "ssdReportedAsRotational",
+			autoTier:     true,
+			driveStatus:  directcsi.DirectCSIDriveStatus{RootPartition: "sdd", ModelNumber: "Samsung SSD 860", Rotational: true, AccessTier: directcsi.AccessTierUnknown},
+			expectedTier: directcsi.AccessTierHot,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Discovery{
+				NodeID:          "test-node",
+				directcsiClient: fakedirect.NewSimpleClientset(),
+				autoTier:        tt.autoTier,
+			}
+			if err := d.createNewDrive(context.TODO(), tt.driveStatus); err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			driveList, err := d.directcsiClient.DirectV1beta2().DirectCSIDrives().List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if len(driveList.Items) != 1 {
+				t.Fatalf("Test case name %s: Expected 1 drive, got %d", tt.name, len(driveList.Items))
+			}
+			drive := driveList.Items[0]
+			if drive.Status.AccessTier != tt.expectedTier {
+				t.Errorf("Test case name %s: Expected access tier = %s, got %s", tt.name, tt.expectedTier, drive.Status.AccessTier)
+			}
+			if label := drive.Labels[directcsi.Group+"/access-tier"]; label != string(tt.expectedTier) {
+				t.Errorf("Test case name %s: Expected access-tier label = %s, got %s", tt.name, tt.expectedTier, label)
+			}
+		})
+	}
+}
+
+type fakeDriveMounter struct {
+	mountArgs []string
+	mountOpts []string
+}
+
+func (m *fakeDriveMounter) MountDrive(ctx context.Context, source, target string, mountOpts []string) error {
+	m.mountArgs = []string{source, target}
+	m.mountOpts = mountOpts
+	return nil
+}
+
+func (m *fakeDriveMounter) UnmountDrive(path string) error {
+	return nil
+}
+
+func TestSyncDriveRemount(t *testing.T) {
+	newDrive := func(driveStatus directcsi.DriveStatus, mountpoint string, mountOpts []string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: "test-drive"},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:       "test-node",
+				DriveStatus:    driveStatus,
+				Filesystem:     string(sys.FSTypeXFS),
+				FilesystemUUID: "d9877501-e1b5-4bac-b73f-178b29974ed5",
+				Mountpoint:     mountpoint,
+				MountOptions:   mountOpts,
+				Conditions: []metav1.Condition{
+					{Type: string(directcsi.DirectCSIDriveConditionMounted), Status: metav1.ConditionTrue},
+					{Type: string(directcsi.DirectCSIDriveConditionInitialized), Status: metav1.ConditionTrue},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		remoteDrive        *directcsi.DirectCSIDrive
+		mounts             []sys.MountInfo
+		expectedMountArgs  []string
+		expectedMountOpts  []string
+		expectedMountpoint string
+	}{
+		{
+			name:               "lostMount",
+			remoteDrive:        newDrive(directcsi.DriveStatusInUse, "/mnt/drive", []string{"rw", "relatime"}),
+			expectedMountArgs:  []string{"/var/lib/direct-csi/devices/d9877501-e1b5-4bac-b73f-178b29974ed5", "/mnt/drive"},
+			expectedMountOpts:  []string{"relatime"},
+			expectedMountpoint: "/mnt/drive",
+		},
+		{
+			name:               "lostMountWithPropagation",
+			remoteDrive:        newDrive(directcsi.DriveStatusInUse, "/mnt/drive", []string{"rw", "noatime", "shared"}),
+			expectedMountArgs:  []string{"/var/lib/direct-csi/devices/d9877501-e1b5-4bac-b73f-178b29974ed5", "/mnt/drive"},
+			expectedMountOpts:  []string{"noatime"},
+			expectedMountpoint: "/mnt/drive",
+		},
+		{
+			name:               "lostMountWithoutMountpoint",
+			remoteDrive:        newDrive(directcsi.DriveStatusReady, "", nil),
+			expectedMountArgs:  []string{"/var/lib/direct-csi/devices/d9877501-e1b5-4bac-b73f-178b29974ed5", "/var/lib/direct-csi/mnt/d9877501-e1b5-4bac-b73f-178b29974ed5"},
+			expectedMountOpts:  []string{},
+			expectedMountpoint: "/var/lib/direct-csi/mnt/d9877501-e1b5-4bac-b73f-178b29974ed5",
+		},
+		{
+			name:        "mounted",
+			remoteDrive: newDrive(directcsi.DriveStatusInUse, "/mnt/drive", []string{"rw", "noatime"}),
+			mounts: []sys.MountInfo{
+				{MountSource: "/var/lib/direct-csi/devices/d9877501-e1b5-4bac-b73f-178b29974ed5", Mountpoint: "/mnt/drive"},
+			},
+			expectedMountpoint: "/mnt/drive",
+		},
+		{
+			name:        "available",
+			remoteDrive: newDrive(directcsi.DriveStatusAvailable, "", nil),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mounter := &fakeDriveMounter{}
+			d := &Discovery{
+				NodeID:          "test-node",
+				directcsiClient: fakedirect.NewSimpleClientset(tt.remoteDrive),
+				driveMounter:    mounter,
+				mounts:          tt.mounts,
+			}
+			// the probed state of the drive only carries the mountpoint of a live mount
+			localDrive := tt.remoteDrive.DeepCopy()
+			localDrive.Status.Mountpoint = ""
+			localDrive.Status.MountOptions = nil
+			if len(tt.mounts) > 0 {
+				localDrive.Status.Mountpoint = tt.mounts[0].Mountpoint
+			}
+			if err := d.syncDrive(context.TODO(), localDrive); err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(mounter.mountArgs, tt.expectedMountArgs) {
+				t.Errorf("Test case name %s: Expected mount = %v, got %v", tt.name, tt.expectedMountArgs, mounter.mountArgs)
+			}
+			if tt.expectedMountArgs != nil && !reflect.DeepEqual(mounter.mountOpts, tt.expectedMountOpts) {
+				t.Errorf("Test case name %s: Expected mount options = %v, got %v", tt.name, tt.expectedMountOpts, mounter.mountOpts)
+			}
+			drive, err := d.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), "test-drive", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if drive.Status.Mountpoint != tt.expectedMountpoint {
+				t.Errorf("Test case name %s: Expected mountpoint = %s, got %s", tt.name, tt.expectedMountpoint, drive.Status.Mountpoint)
+			}
+		})
+	}
+}
+
+func TestSyncChangedDrive(t *testing.T) {
+	newDrive := func(driveStatus directcsi.DriveStatus, totalCapacity, deviceCapacity int64, readOnly bool) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: "test-drive"},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          "test-node",
+				Path:              "/dev/sdb",
+				DriveStatus:       driveStatus,
+				Filesystem:        string(sys.FSTypeXFS),
+				FilesystemUUID:    "d9877501-e1b5-4bac-b73f-178b29974ed5",
+				Mountpoint:        "/var/lib/direct-csi/mnt/d9877501-e1b5-4bac-b73f-178b29974ed5",
+				TotalCapacity:     totalCapacity,
+				DeviceCapacity:    deviceCapacity,
+				AllocatedCapacity: 100,
+				FreeCapacity:      totalCapacity - 100,
+				ReadOnly:          readOnly,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                   string
+		remoteDrive            *directcsi.DirectCSIDrive
+		probedDrive            *directcsi.DirectCSIDrive
+		expectedEvent          string
+		expectedFree           int64
+		expectedReadOnly       bool
+		expectedCapacity       int64
+		expectedDeviceCapacity int64
+		expectedNoChanges      bool
+	}{
+		{
+			name:                   "unchanged",
+			remoteDrive:            newDrive(directcsi.DriveStatusInUse, 1000, 1000, false),
+			probedDrive:            newDrive(directcsi.DriveStatusAvailable, 1000, 1000, false),
+			expectedFree:           900,
+			expectedCapacity:       1000,
+			expectedDeviceCapacity: 1000,
+			expectedNoChanges:      true,
+		},
+		{
+			// the free capacity does not grow before the filesystem is grown
+			name:                   "deviceExpanded",
+			remoteDrive:            newDrive(directcsi.DriveStatusInUse, 1000, 1000, false),
+			probedDrive:            newDrive(directcsi.DriveStatusAvailable, 1000, 2000, false),
+			expectedEvent:          "Normal DeviceChanged device size changed from 1000 B to 2.0 KiB",
+			expectedFree:           900,
+			expectedCapacity:       1000,
+			expectedDeviceCapacity: 2000,
+		},
+		{
+			name:                   "filesystemGrown",
+			remoteDrive:            newDrive(directcsi.DriveStatusInUse, 1000, 2000, false),
+			probedDrive:            newDrive(directcsi.DriveStatusAvailable, 2000, 2000, false),
+			expectedEvent:          "Normal DeviceChanged filesystem capacity changed from 1000 B to 2.0 KiB",
+			expectedFree:           1900,
+			expectedCapacity:       2000,
+			expectedDeviceCapacity: 2000,
+		},
+		{
+			name:                   "readOnly",
+			remoteDrive:            newDrive(directcsi.DriveStatusReady, 1000, 1000, false),
+			probedDrive:            newDrive(directcsi.DriveStatusAvailable, 1000, 1000, true),
+			expectedEvent:          "Warning DeviceChanged device turned read-only",
+			expectedFree:           900,
+			expectedReadOnly:       true,
+			expectedCapacity:       1000,
+			expectedDeviceCapacity: 1000,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			d := &Discovery{
+				NodeID:          "test-node",
+				directcsiClient: fakedirect.NewSimpleClientset(tt.remoteDrive),
+				driveMounter:    &fakeDriveMounter{},
+				mounts: []sys.MountInfo{
+					{MountSource: "/var/lib/direct-csi/devices/d9877501-e1b5-4bac-b73f-178b29974ed5", Mountpoint: tt.remoteDrive.Status.Mountpoint},
+				},
+				eventRecorder: recorder,
+			}
+			if err := d.readRemoteDrives(context.TODO()); err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			remoteDrive, err := d.Identify(tt.probedDrive.Status)
+			if err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if err := d.syncChangedDrive(context.TODO(), tt.probedDrive.Status, remoteDrive); err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if tt.expectedNoChanges || event != tt.expectedEvent {
+					t.Errorf("Test case name %s: Expected event %q, got %q", tt.name, tt.expectedEvent, event)
+				}
+			default:
+				if !tt.expectedNoChanges {
+					t.Errorf("Test case name %s: Expected event %q, got none", tt.name, tt.expectedEvent)
+				}
+			}
+
+			drive, err := d.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), "test-drive", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if drive.Status.TotalCapacity != tt.expectedCapacity || drive.Status.FreeCapacity != tt.expectedFree || drive.Status.ReadOnly != tt.expectedReadOnly {
+				t.Errorf("Test case name %s: Expected total %d, free %d, read-only %v, got %d, %d, %v", tt.name,
+					tt.expectedCapacity, tt.expectedFree, tt.expectedReadOnly,
+					drive.Status.TotalCapacity, drive.Status.FreeCapacity, drive.Status.ReadOnly)
+			}
+			if drive.Status.DeviceCapacity != tt.expectedDeviceCapacity {
+				t.Errorf("Test case name %s: Expected device capacity %d, got %d", tt.name, tt.expectedDeviceCapacity, drive.Status.DeviceCapacity)
+			}
+		})
+	}
+}
+
+func TestFilterDriveStatesKeepsRemoteDrives(t *testing.T) {
+	newDrive := func(name, path string, major uint32, totalCapacity int64, fsUUID string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:       "test-node",
+				Path:           path,
+				MajorNumber:    major,
+				DriveStatus:    directcsi.DriveStatusInUse,
+				Filesystem:     string(sys.FSTypeXFS),
+				FilesystemUUID: fsUUID,
+				TotalCapacity:  totalCapacity,
+			},
+		}
+	}
+
+	ramDrive := newDrive("ram-drive", "/dev/ram0", 1, 1<<30, "d9877501-e1b5-4bac-b73f-178b29974ed5")
+	smallDrive := newDrive("small-drive", "/dev/sdb", 8, 1<<20, "4b5a2e0c-2f7e-4c5b-9a8e-0f4a3c7b6e21")
+	excludedDrive := newDrive("excluded-drive", "/dev/sdc", 8, 1<<30, "7e3c1d2a-5b6f-4e8d-a9c0-1b2d3e4f5a6b")
+	selectedDrive := newDrive("selected-drive", "/dev/sdd", 8, 1<<30, "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d")
+
+	d := &Discovery{
+		NodeID:          "test-node",
+		directcsiClient: fakedirect.NewSimpleClientset(ramDrive, smallDrive, excludedDrive, selectedDrive),
+	}
+	if err := d.readRemoteDrives(context.TODO()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	deviceFilter := sys.DeviceFilter{Exclude: []string{"/dev/sdc"}, MinSize: 1 << 29}
+	filtered := d.filterDriveStates([]directcsi.DirectCSIDriveStatus{
+		ramDrive.Status, smallDrive.Status, excludedDrive.Status, selectedDrive.Status,
+	}, deviceFilter)
+	if len(filtered) != 1 || filtered[0].Path != "/dev/sdd" {
+		t.Fatalf("Expected only /dev/sdd to be selected, got %v", filtered)
+	}
+
+	// the selected drive is identified by Init
+	if _, err := d.Identify(filtered[0]); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := d.deleteUnmatchedRemoteDrives(context.TODO()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, name := range []string{"ram-drive", "small-drive", "excluded-drive", "selected-drive"} {
+		if _, err := d.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+			t.Errorf("Drive %s of a skipped device was deleted: %v", name, err)
+		}
+	}
+}
+
+func TestIdentifyAliasedDrives(t *testing.T) {
+	newDrive := func(name, path, rootPartition string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta:   utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:      "test-node",
+				Path:          path,
+				RootPartition: rootPartition,
+				DriveStatus:   directcsi.DriveStatusInUse,
+			},
+		}
+	}
+
+	aliasedDrive := newDrive("aliased-drive", "/var/lib/direct-csi/devices/sdc", "sdc")
+	aliasedPartition := newDrive("aliased-partition", "/var/lib/direct-csi/devices/sdc-part-1", "sdc")
+	removedDrive := newDrive("removed-drive", "/var/lib/direct-csi/devices/sdd", "sdd")
+
+	d := &Discovery{
+		NodeID:          "test-node",
+		directcsiClient: fakedirect.NewSimpleClientset(aliasedDrive, aliasedPartition, removedDrive),
+	}
+	if err := d.readRemoteDrives(context.TODO()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// sdc reports the same WWID as sdb and is skipped
+	d.identifyAliasedDrives([]sys.BlockDevice{{Devname: "sdb", Aliases: []string{"sdc"}}})
+	if err := d.deleteUnmatchedRemoteDrives(context.TODO()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, name := range []string{"aliased-drive", "aliased-partition"} {
+		if _, err := d.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+			t.Errorf("Drive %s of an aliased device was deleted: %v", name, err)
+		}
+	}
+	if _, err := d.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), "removed-drive", metav1.GetOptions{}); err == nil {
+		t.Errorf("Drive removed-drive of a removed device was not deleted")
+	}
+}
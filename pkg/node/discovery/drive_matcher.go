@@ -20,6 +20,8 @@ import (
 	"errors"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/sys"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -110,3 +112,20 @@ func (d *Discovery) identifyDriveByLegacyName(localDriveState directcsi.DirectCS
 	}
 	return nil, false, ErrNoMatchFound
 }
+
+// identifyAliasedDrives marks the remote drives of the devices skipped for reporting the same WWID
+// as a local drive as matched, so that they are left untouched instead of deleted
+func (d *Discovery) identifyAliasedDrives(localDrives []sys.BlockDevice) {
+	aliases := map[string]string{}
+	for _, localDrive := range localDrives {
+		for _, alias := range localDrive.Aliases {
+			aliases[alias] = localDrive.Devname
+		}
+	}
+	for i, remoteDrive := range d.remoteDrives {
+		if devName, found := aliases[remoteDrive.Status.RootPartition]; found && !remoteDrive.matched {
+			klog.Warningf("Drive %s is retained as its device %s reports the same WWID as %s", remoteDrive.Name, remoteDrive.Status.RootPartition, devName)
+			d.remoteDrives[i].matched = true
+		}
+	}
+}
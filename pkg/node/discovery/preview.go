@@ -0,0 +1,130 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/sys"
+
+	"k8s.io/klog/v2"
+)
+
+// Preview runs the drive discovery on this node without registering any drives. The
+// checks applied to each drive are recorded in the trace of its preview if trace is set.
+func Preview(ctx context.Context, loopBackOnly bool, loopBackCount int, deviceFilter sys.DeviceFilter, allowRemovable, trace bool) ([]sys.DrivePreview, error) {
+	d := &Discovery{}
+	localDrives, err := d.findLocalDrives(ctx, loopBackOnly, loopBackCount)
+	if err != nil {
+		return nil, err
+	}
+	return previewDrives(localDrives, deviceFilter, allowRemovable, trace), nil
+}
+
+// previewDrives describes the drives of the devices, the devices skipped by the discovery are left out
+func previewDrives(localDrives []sys.BlockDevice, deviceFilter sys.DeviceFilter, allowRemovable, trace bool) []sys.DrivePreview {
+	skipped := func(driveInfo *sys.DriveInfo, rootPartition string) bool {
+		if driveInfo == nil {
+			return false
+		}
+		reason := skipReason(deviceFilter, driveInfo.Path, rootPartition, driveInfo.Major, driveInfo.TotalCapacity)
+		if reason != "" {
+			klog.V(3).Infof("Skipping the device %s as %s", driveInfo.Path, reason)
+		}
+		return reason != ""
+	}
+
+	previews := []sys.DrivePreview{}
+	for _, localDrive := range localDrives {
+		partitions := localDrive.GetPartitions()
+		if len(partitions) > 0 {
+			for _, partition := range partitions {
+				if skipped(partition.DriveInfo, localDrive.Devname) {
+					continue
+				}
+				checks := partitionChecks(partition, localDrive.DeviceError, localDrive.IsSystemDisk, allowRemovable)
+				preview := newDrivePreview(partition.DriveInfo, firstFailure(checks))
+				if trace {
+					preview.Trace = probeTrace(partition.DriveInfo, checks)
+				}
+				previews = append(previews, preview)
+			}
+			continue
+		}
+		if skipped(localDrive.DriveInfo, localDrive.Devname) {
+			continue
+		}
+		checks := rootChecks(localDrive, allowRemovable)
+		preview := newDrivePreview(localDrive.DriveInfo, firstFailure(checks))
+		if trace {
+			preview.Trace = probeTrace(localDrive.DriveInfo, checks)
+		}
+		previews = append(previews, preview)
+	}
+	return previews
+}
+
+// probeTrace describes the attributes read for the drive and the outcome of every availability check
+func probeTrace(driveInfo *sys.DriveInfo, checks []availabilityCheck) []string {
+	trace := []string{}
+	if driveInfo != nil {
+		trace = append(trace, fmt.Sprintf("attributes: dev=%d:%d size=%d rotational=%v removable=%v serial=%q model=%q wwid=%q",
+			driveInfo.Major, driveInfo.Minor, driveInfo.TotalCapacity, driveInfo.Rotational, driveInfo.Removable,
+			driveInfo.SerialNumber, driveInfo.Model, driveInfo.WWID))
+		if driveInfo.FSInfo != nil {
+			mountpoints := []string{}
+			for _, mount := range driveInfo.FSInfo.Mounts {
+				mountpoints = append(mountpoints, mount.Mountpoint)
+			}
+			trace = append(trace, fmt.Sprintf("filesystem: type=%q uuid=%q mounts=%v", driveInfo.FSInfo.FSType, driveInfo.FSInfo.UUID, mountpoints))
+		}
+	}
+	for _, check := range checks {
+		if check.failed {
+			trace = append(trace, fmt.Sprintf("%s: failed, %s", check.name, check.reason()))
+			continue
+		}
+		trace = append(trace, fmt.Sprintf("%s: passed", check.name))
+	}
+	return trace
+}
+
+func newDrivePreview(driveInfo *sys.DriveInfo, reason string) sys.DrivePreview {
+	preview := sys.DrivePreview{
+		DriveStatus: string(directcsi.DriveStatusAvailable),
+		Reason:      reason,
+	}
+	if reason != "" {
+		preview.DriveStatus = string(directcsi.DriveStatusUnavailable)
+	}
+	if driveInfo == nil {
+		return preview
+	}
+	preview.Path = driveInfo.Path
+	preview.TotalCapacity = driveInfo.TotalCapacity
+	if driveInfo.FSInfo != nil {
+		preview.Filesystem = driveInfo.FSInfo.FSType
+		preview.FilesystemUUID = driveInfo.FSInfo.UUID
+		preview.Label = driveInfo.FSInfo.Label
+		if len(driveInfo.FSInfo.Mounts) > 0 {
+			preview.Mountpoint = driveInfo.FSInfo.Mounts[0].Mountpoint
+		}
+	}
+	return preview
+}
@@ -0,0 +1,166 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/listener"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// deviceChangedReason is the reason of the events recorded when a re-probe notices a change of the device of a drive
+const deviceChangedReason = "DeviceChanged"
+
+// deviceCacheMaxAge is how long the devices probed for one subsystem of the node are reused for the others
+const deviceCacheMaxAge = time.Second
+
+// deviceChanges describes the changes of the physical device of the drive noticed by a re-probe, the
+// changes are reported as warnings if the device turned read-only or lost the filesystem of a drive in use
+func deviceChanges(existing, probed directcsi.DirectCSIDriveStatus) (changes []string, warning bool) {
+	// the size of the device is not recorded on the drives probed by the earlier versions
+	if existing.DeviceCapacity != 0 && existing.DeviceCapacity != probed.DeviceCapacity {
+		changes = append(changes, fmt.Sprintf("device size changed from %s to %s",
+			humanize.IBytes(uint64(existing.DeviceCapacity)),
+			humanize.IBytes(uint64(probed.DeviceCapacity))))
+	}
+	// the capacity of a filesystem does not follow its device, it changes once the filesystem is grown
+	if probed.Filesystem != "" && existing.TotalCapacity != probed.TotalCapacity {
+		changes = append(changes, fmt.Sprintf("filesystem capacity changed from %s to %s",
+			humanize.IBytes(uint64(existing.TotalCapacity)),
+			humanize.IBytes(uint64(probed.TotalCapacity))))
+	}
+	if existing.ReadOnly != probed.ReadOnly {
+		if probed.ReadOnly {
+			changes = append(changes, "device turned read-only")
+			warning = true
+		} else {
+			changes = append(changes, "device turned writable")
+		}
+	}
+	switch existing.DriveStatus {
+	case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+		// the filesystem of the other drives changes as they are formatted or released
+		if existing.Filesystem != probed.Filesystem {
+			changes = append(changes, fmt.Sprintf("filesystem changed from %q to %q", existing.Filesystem, probed.Filesystem))
+			warning = true
+		}
+	}
+	return changes, warning
+}
+
+// Reprobe re-reads the block devices of the node and syncs the drives whose device changed, e.g. after an
+// online expansion of a LUN or if the device turned read-only. The new devices are left to the discovery at startup.
+func (d *Discovery) Reprobe(ctx context.Context, loopBackOnly bool) error {
+	if err := d.readRemoteDrives(ctx); err != nil {
+		return err
+	}
+	if err := d.readMounts(); err != nil {
+		return err
+	}
+	var devices []sys.BlockDevice
+	var err error
+	if d.deviceCache != nil {
+		devices, err = d.deviceCache.Get(ctx, deviceCacheMaxAge)
+	} else {
+		devices, err = sys.FindDevices(ctx, loopBackOnly)
+	}
+	if err != nil {
+		return err
+	}
+	for _, localDriveState := range d.toDirectCSIDriveStatus(devices) {
+		remoteDrive, err := d.Identify(localDriveState)
+		if err != nil {
+			continue
+		}
+		if err := d.syncChangedDrive(ctx, localDriveState, remoteDrive); err != nil {
+			klog.V(3).Infof("Unable to sync the changed device of drive %s: %v", remoteDrive.Name, err)
+		}
+	}
+	return nil
+}
+
+// syncChangedDrive syncs the drive if its device changed since it was last probed and records an event of the changes
+func (d *Discovery) syncChangedDrive(ctx context.Context, localDriveState directcsi.DirectCSIDriveStatus, remoteDrive *remoteDrive) error {
+	if remoteDrive.DeletionTimestamp != nil {
+		return nil
+	}
+	changes, warning := deviceChanges(remoteDrive.Status, localDriveState)
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := d.syncRemoteDrive(ctx, localDriveState, remoteDrive); err != nil {
+		return err
+	}
+	message := strings.Join(changes, "; ")
+	klog.Infof("Device %s of drive %s changed: %s", localDriveState.Path, remoteDrive.Name, message)
+	if d.eventRecorder != nil {
+		eventType := corev1.EventTypeNormal
+		if warning {
+			eventType = corev1.EventTypeWarning
+		}
+		d.eventRecorder.Event(&remoteDrive.DirectCSIDrive, eventType, deviceChangedReason, message)
+	}
+	return nil
+}
+
+// StartReprobe periodically re-probes the devices of the drives of this node until ctx is done,
+// each re-probe is bounded by timeout so that a stuck device cannot block the following ones
+func (d *Discovery) StartReprobe(ctx context.Context, interval, timeout time.Duration, loopBackOnly bool) {
+	broadcaster := record.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: utils.GetKubeClient().CoreV1().Events("")})
+	d.eventRecorder = broadcaster.NewRecorder(listener.NewEventScheme(), corev1.EventSource{Component: "directcsi-discovery", Host: d.NodeID})
+
+	d.deviceCache = sys.NewDeviceCache(loopBackOnly)
+	if err := d.deviceCache.InvalidateOnDeviceEvents(ctx); err != nil {
+		// the cache still expires after deviceCacheMaxAge
+		klog.V(3).Infof("Unable to watch the device events: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reprobeCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := d.Reprobe(reprobeCtx, loopBackOnly)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				klog.V(3).Infof("Drive re-probe did not finish within %v: %v", timeout, err)
+			} else {
+				klog.V(3).Infof("Unable to re-probe the drives: %v", err)
+			}
+		}
+	}
+}
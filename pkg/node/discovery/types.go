@@ -20,6 +20,8 @@ import (
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/clientset"
 	"github.com/minio/direct-csi/pkg/sys"
+
+	"k8s.io/client-go/tools/record"
 )
 
 type remoteDrive struct {
@@ -33,4 +35,13 @@ type Discovery struct {
 	remoteDrives    []*remoteDrive
 	driveTopology   map[string]string
 	mounts          []sys.MountInfo
+	driveMounter    sys.DriveMounter
+	// autoTier assigns the access-tier of the new drives from their rotational and model attributes
+	autoTier bool
+	// allowRemovable lets the removable media be managed like any other drive
+	allowRemovable bool
+	// eventRecorder records the changes of the devices noticed by the re-probes, if set
+	eventRecorder record.EventRecorder
+	// deviceCache shares the probed devices of the re-probes, invalidated by the device events, if set
+	deviceCache *sys.DeviceCache
 }
@@ -18,6 +18,7 @@ package discovery
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
@@ -26,17 +27,21 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/util/retry"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (d *Discovery) verifyDriveMount(existingDrive *directcsi.DirectCSIDrive) error {
-	driveMounter := &sys.DefaultDriveMounter{}
+// verifyDriveMount re-mounts the ready and in-use drives which lost their mount, e.g. after a reboot
+// of the node, at the mountpoint and with the mount options recorded before the discovery
+func (d *Discovery) verifyDriveMount(ctx context.Context, existingDrive *directcsi.DirectCSIDrive, recordedMountpoint string, recordedMountOpts []string) error {
 	switch existingDrive.Status.DriveStatus {
 	case directcsi.DriveStatusInUse, directcsi.DriveStatusReady:
 		mountSource := sys.GetDirectCSIPath(existingDrive.Status.FilesystemUUID)
-		mountTarget := filepath.Join(sys.MountRoot, existingDrive.Status.FilesystemUUID)
+		mountTarget := recordedMountpoint
+		if mountTarget == "" {
+			mountTarget = filepath.Join(sys.MountRoot, existingDrive.Status.FilesystemUUID)
+		}
 		// Check if the drive is mounted
 		isMounted := false
 		for _, mount := range d.mounts {
@@ -47,10 +52,23 @@ func (d *Discovery) verifyDriveMount(existingDrive *directcsi.DirectCSIDrive) er
 		}
 		// Mount if umounted
 		if !isMounted {
-			if err := driveMounter.MountDrive(mountSource, mountTarget, []string{}); err != nil {
+			// the drive is only mounted with the filesystem it was formatted with
+			if existingDrive.Status.Filesystem != string(sys.FSTypeXFS) {
+				return fmt.Errorf("cannot remount drive %s, expected filesystem %s, found %q", existingDrive.Name, sys.FSTypeXFS, existingDrive.Status.Filesystem)
+			}
+			// the recorded options are the flags of the lost mount, which include flags like "rw" not accepted by the mount
+			mountOpts := sys.DriveMountOptions(recordedMountOpts)
+			if err := d.driveMounter.MountDrive(ctx, mountSource, mountTarget, mountOpts); err != nil {
 				return err
 			}
+			klog.V(3).Infof("remounted drive %s at %s", existingDrive.Name, mountTarget)
 			existingDrive.Status.Mountpoint = mountTarget
+			existingDrive.Status.MountOptions = mountOpts
+			utils.UpdateCondition(existingDrive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionMounted),
+				metav1.ConditionTrue,
+				string(directcsi.DirectCSIDriveReasonAdded),
+				"")
 		}
 	}
 	return nil
@@ -59,12 +77,22 @@ func (d *Discovery) verifyDriveMount(existingDrive *directcsi.DirectCSIDrive) er
 func syncDriveStatesOnDiscovery(existingObj *directcsi.DirectCSIDrive, localDrive *directcsi.DirectCSIDrive) {
 
 	existingObjVersion := utils.GetLabelV(existingObj, utils.VersionLabel)
+	existingReservedFor := utils.GetLabelV(existingObj, utils.ReservedForLabel)
+	existingUnschedulable := utils.GetLabelV(existingObj, utils.UnschedulableLabel)
 	// overwrite existing object labels
 	existingObj.SetLabels(localDrive.GetLabels())
 	utils.UpdateLabels(existingObj,
 		utils.AccessTierLabel, string(existingObj.Status.AccessTier), // set access-tier labels
 		utils.VersionLabel, existingObjVersion, // set obj version labels
 	)
+	if existingReservedFor != "" {
+		// retain the reservation set by the admin
+		utils.UpdateLabels(existingObj, utils.ReservedForLabel, existingReservedFor)
+	}
+	if existingUnschedulable != "" {
+		// retain the cordon set by the admin
+		utils.UpdateLabels(existingObj, utils.UnschedulableLabel, existingUnschedulable)
+	}
 
 	// Sync the possible states
 	existingObj.Status.RootPartition = localDrive.Status.RootPartition
@@ -77,10 +105,28 @@ func syncDriveStatesOnDiscovery(existingObj *directcsi.DirectCSIDrive, localDriv
 	existingObj.Status.LogicalBlockSize = localDrive.Status.LogicalBlockSize
 	existingObj.Status.Path = localDrive.Status.Path
 	existingObj.Status.FilesystemUUID = localDrive.Status.FilesystemUUID
+	existingObj.Status.FilesystemLabel = localDrive.Status.FilesystemLabel
+	existingObj.Status.RAIDMembers = localDrive.Status.RAIDMembers
+	existingObj.Status.Rotational = localDrive.Status.Rotational
+	existingObj.Status.Removable = localDrive.Status.Removable
+	existingObj.Status.ReadOnly = localDrive.Status.ReadOnly
+	existingObj.Status.IOScheduler = localDrive.Status.IOScheduler
+	existingObj.Status.NrRequests = localDrive.Status.NrRequests
+	existingObj.Status.ReadAheadKB = localDrive.Status.ReadAheadKB
+	existingObj.Status.ByIDPath = localDrive.Status.ByIDPath
+	existingObj.Status.ByPath = localDrive.Status.ByPath
+	existingObj.Status.Enclosure = localDrive.Status.Enclosure
+	existingObj.Status.Slot = localDrive.Status.Slot
+	existingObj.Status.Topology = localDrive.Status.Topology
 	existingObj.Status.SerialNumber = localDrive.Status.SerialNumber
 	existingObj.Status.PartitionUUID = localDrive.Status.PartitionUUID
 	existingObj.Status.MajorNumber = localDrive.Status.MajorNumber
 	existingObj.Status.MinorNumber = localDrive.Status.MinorNumber
+	existingObj.Status.FirmwareRevision = localDrive.Status.FirmwareRevision
+	existingObj.Status.NamespaceID = localDrive.Status.NamespaceID
+	existingObj.Status.ControllerSerial = localDrive.Status.ControllerSerial
+	existingObj.Status.SharedNamespace = localDrive.Status.SharedNamespace
+	existingObj.Status.DeviceCapacity = localDrive.Status.DeviceCapacity
 	existingObj.Status.TotalCapacity = localDrive.Status.TotalCapacity
 	// Capacity sync
 	allocatedCapacity := localDrive.Status.AllocatedCapacity
@@ -88,7 +134,11 @@ func syncDriveStatesOnDiscovery(existingObj *directcsi.DirectCSIDrive, localDriv
 		// size reserved for allocated volumes
 		allocatedCapacity = existingObj.Status.AllocatedCapacity
 	}
-	existingObj.Status.FreeCapacity = localDrive.Status.TotalCapacity - allocatedCapacity
+	// the headroom reserved when the drive was formatted is never allocated
+	existingObj.Status.FreeCapacity = localDrive.Status.TotalCapacity - allocatedCapacity - existingObj.Status.ReservedCapacity
+	if existingObj.Status.FreeCapacity < 0 {
+		existingObj.Status.FreeCapacity = 0
+	}
 	existingObj.Status.AllocatedCapacity = allocatedCapacity
 }
 
@@ -104,16 +154,25 @@ func (d *Discovery) syncDrive(ctx context.Context, localDrive *directcsi.DirectC
 			return err
 		}
 
+		// the probed mountpoint is empty if the mount was lost
+		recordedMountpoint := existingDrive.Status.Mountpoint
+		recordedMountOpts := existingDrive.Status.MountOptions
+
 		// Sync remote drive states
 		syncDriveStatesOnDiscovery(existingDrive, localDrive)
 
 		// Verify mounts
-		if err := d.verifyDriveMount(existingDrive); err != nil {
+		if err := d.verifyDriveMount(ctx, existingDrive, recordedMountpoint, recordedMountOpts); err != nil {
 			utils.UpdateCondition(existingDrive.Status.Conditions,
 				string(directcsi.DirectCSIDriveConditionInitialized),
 				metav1.ConditionFalse,
 				string(directcsi.DirectCSIDriveReasonInitialized),
 				err.Error())
+			utils.UpdateCondition(existingDrive.Status.Conditions,
+				string(directcsi.DirectCSIDriveConditionMounted),
+				metav1.ConditionFalse,
+				string(directcsi.DirectCSIDriveReasonMountFailed),
+				err.Error())
 			klog.V(3).Infof("mounting failed with: %v", err)
 		}
 
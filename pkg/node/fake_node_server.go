@@ -19,7 +19,11 @@ package node
 import (
 	"context"
 
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/sys"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
 )
 
 const (
@@ -32,27 +36,101 @@ type fakeVolumeMounter struct {
 		destination string
 		volumeID    string
 		size        int64
+		inodeLimit  int64
 		readOnly    bool
 	}
+	propagationArgs struct {
+		target      string
+		propagation sys.MountPropagation
+	}
 	unmountArgs struct {
 		target string
 	}
+	remountArgs struct {
+		target   string
+		readOnly bool
+	}
+	ownershipArgs struct {
+		target  string
+		fsGroup int64
+		policy  sys.FSGroupChangePolicy
+	}
+	quotaUnsupported bool
+	// mounts maps the mounted targets to their sources
+	mounts     map[string]string
+	mountCount int
+	// readOnlyMounts holds the targets mounted read-only
+	readOnlyMounts map[string]bool
 }
 
-func (f *fakeVolumeMounter) MountVolume(_ context.Context, src, dest, vID string, size int64, readOnly bool) error {
+func (f *fakeVolumeMounter) MountVolume(_ context.Context, src, dest, vID string, size, inodeLimit int64, readOnly bool) (bool, error) {
 	f.mountArgs.source = src
 	f.mountArgs.destination = dest
 	f.mountArgs.volumeID = vID
 	f.mountArgs.size = size
+	f.mountArgs.inodeLimit = inodeLimit
 	f.mountArgs.readOnly = readOnly
+	if f.mounts == nil {
+		f.mounts = map[string]string{}
+	}
+	f.mounts[dest] = src
+	if f.readOnlyMounts == nil {
+		f.readOnlyMounts = map[string]bool{}
+	}
+	f.readOnlyMounts[dest] = readOnly
+	f.mountCount++
+	return !f.quotaUnsupported, nil
+}
+
+func (f *fakeVolumeMounter) SetMountPropagation(_ context.Context, target string, propagation sys.MountPropagation) error {
+	f.propagationArgs.target = target
+	f.propagationArgs.propagation = propagation
 	return nil
 }
 
 func (f *fakeVolumeMounter) UnmountVolume(targetPath string) error {
 	f.unmountArgs.target = targetPath
+	delete(f.mounts, targetPath)
+	delete(f.readOnlyMounts, targetPath)
 	return nil
 }
 
+func (f *fakeVolumeMounter) IsVolumeMounted(src, dest string) (bool, error) {
+	return f.mounts[dest] == src, nil
+}
+
+func (f *fakeVolumeMounter) RemountVolume(_ context.Context, target string, readOnly bool) error {
+	f.remountArgs.target = target
+	f.remountArgs.readOnly = readOnly
+	if f.readOnlyMounts == nil {
+		f.readOnlyMounts = map[string]bool{}
+	}
+	f.readOnlyMounts[target] = readOnly
+	return nil
+}
+
+func (f *fakeVolumeMounter) IsReadOnlyMount(target string) (bool, error) {
+	return f.readOnlyMounts[target], nil
+}
+
+func (f *fakeVolumeMounter) SetVolumeOwnership(_ context.Context, target string, fsGroup int64, policy sys.FSGroupChangePolicy) error {
+	f.ownershipArgs.target = target
+	f.ownershipArgs.fsGroup = fsGroup
+	f.ownershipArgs.policy = policy
+	return nil
+}
+
+func fakeVolumeUsage(_ context.Context, vol *directcsi.DirectCSIVolume) ([]*csi.VolumeUsage, error) {
+	return []*csi.VolumeUsage{
+		{
+			Available: vol.Status.AvailableCapacity,
+			Total:     vol.Status.TotalCapacity,
+			Used:      vol.Status.UsedCapacity,
+			Unit:      csi.VolumeUsage_BYTES,
+		},
+	}, nil
+}
+
 func createFakeNodeServer() *NodeServer {
 	return &NodeServer{
 		NodeID:          testNodeName,
@@ -62,5 +140,8 @@ func createFakeNodeServer() *NodeServer {
 		Region:          "test-region",
 		directcsiClient: fakedirect.NewSimpleClientset(),
 		mounter:         &fakeVolumeMounter{},
+		getVolumeUsage:  fakeVolumeUsage,
+		pathLocks:       newNSLockMap(),
+		lockTimings:     DefaultLockTimings,
 	}
 }
@@ -0,0 +1,104 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LockTimings configure how long publish and unpublish wait for an in-flight
+// operation on the same target path
+type LockTimings struct {
+	Timeout      time.Duration
+	PollInterval time.Duration
+}
+
+// DefaultLockTimings are used unless configured otherwise
+var DefaultLockTimings = LockTimings{
+	Timeout:      1 * time.Minute,
+	PollInterval: 2 * time.Second,
+}
+
+// Validate checks if the lock can be polled at least once before the timeout
+func (t LockTimings) Validate() error {
+	if t.PollInterval <= 0 {
+		return fmt.Errorf("lock poll interval must be greater than zero, got %v", t.PollInterval)
+	}
+	if t.PollInterval > t.Timeout {
+		return fmt.Errorf("lock poll interval (%v) must not be greater than the lock timeout (%v)", t.PollInterval, t.Timeout)
+	}
+	return nil
+}
+
+// nsLockMap serializes the operations on a path
+type nsLockMap struct {
+	mutex sync.Mutex
+	locks map[string]struct{}
+}
+
+func newNSLockMap() *nsLockMap {
+	return &nsLockMap{
+		locks: map[string]struct{}{},
+	}
+}
+
+func (m *nsLockMap) tryLock(path string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, found := m.locks[path]; found {
+		return false
+	}
+	m.locks[path] = struct{}{}
+	return true
+}
+
+func (m *nsLockMap) unlock(path string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.locks, path)
+}
+
+// lockLoop polls for the lock of the path until the timeout. The returned error is
+// codes.Aborted so that the kubelet retries the operation later.
+func (m *nsLockMap) lockLoop(ctx context.Context, path string, timeout, pollInterval time.Duration) error {
+	if m.tryLock(path) {
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.Errorf(codes.Aborted, "operation on %s is in progress: %v", path, ctx.Err())
+		case <-timer.C:
+			return status.Errorf(codes.Aborted, "operation on %s is in progress, timed out after %v", path, timeout)
+		case <-ticker.C:
+			if m.tryLock(path) {
+				return nil
+			}
+		}
+	}
+}
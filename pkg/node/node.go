@@ -18,26 +18,34 @@ package node
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/clientset"
 	"github.com/minio/direct-csi/pkg/drive"
+	"github.com/minio/direct-csi/pkg/listener"
 	"github.com/minio/direct-csi/pkg/metrics"
+	"github.com/minio/direct-csi/pkg/snapshot"
 	"github.com/minio/direct-csi/pkg/sys"
-	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
 	"github.com/minio/direct-csi/pkg/topology"
 	"github.com/minio/direct-csi/pkg/utils"
 	"github.com/minio/direct-csi/pkg/volume"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
-func NewNodeServer(ctx context.Context, identity, nodeID, rack, zone, region string) (*NodeServer, error) {
+func NewNodeServer(ctx context.Context, identity, nodeID, rack, zone, region string, nodeTopology map[string]string, maxVolumesPerNode int64, maxConcurrentFormats int, detachBusyMounts bool, kubeletDir string, metricsConfig metrics.ServerConfig, timings listener.ControllerTimings, lockTimings LockTimings) (*NodeServer, error) {
 
 	kubeConfig := utils.GetKubeConfig()
 	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
@@ -54,51 +62,78 @@ func NewNodeServer(ctx context.Context, identity, nodeID, rack, zone, region str
 	}
 
 	nodeServer := &NodeServer{
-		NodeID:          nodeID,
-		Identity:        identity,
-		Rack:            rack,
-		Zone:            zone,
-		Region:          region,
-		directcsiClient: directClientset,
-		mounter:         &sys.DefaultVolumeMounter{},
+		NodeID:            nodeID,
+		Identity:          identity,
+		Rack:              rack,
+		Zone:              zone,
+		Region:            region,
+		NodeTopology:      nodeTopology,
+		MaxVolumesPerNode: maxVolumesPerNode,
+		directcsiClient:   directClientset,
+		mounter:           &sys.DefaultVolumeMounter{},
+		getVolumeUsage:    getVolumeUsage,
+		pathLocks:         newNSLockMap(),
+		lockTimings:       lockTimings,
+		kubeletDir:        filepath.Clean(kubeletDir),
 	}
 
 	// Start background tasks
-	go drive.StartDriveController(ctx, nodeID)
-	go volume.StartVolumeController(ctx, nodeID)
-	go metrics.ServeMetrics(ctx, nodeID)
+	go drive.StartDriveController(ctx, nodeID, timings, maxConcurrentFormats, detachBusyMounts)
+	go volume.StartVolumeController(ctx, nodeID, timings)
+	go snapshot.StartSnapshotController(ctx, nodeID, timings)
+	go metrics.ServeMetrics(ctx, nodeID, metricsConfig)
 
 	return nodeServer, nil
 }
 
 type NodeServer struct {
-	NodeID          string
-	Identity        string
-	Rack            string
-	Zone            string
-	Region          string
-	directcsiClient clientset.Interface
-	mounter         sys.VolumeMounter
+	NodeID            string
+	Identity          string
+	Rack              string
+	Zone              string
+	Region            string
+	NodeTopology      map[string]string
+	MaxVolumesPerNode int64
+	directcsiClient   clientset.Interface
+	mounter           sys.VolumeMounter
+	getVolumeUsage    volumeUsageGetter
+	pathLocks         *nsLockMap
+	lockTimings       LockTimings
+	// kubeletDir bounds the staging and the target paths of the requests, they are not checked if empty
+	kubeletDir string
 }
 
 func (n *NodeServer) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoRequest) (*csi.NodeGetInfoResponse, error) {
+	segments := map[string]string{}
+	// the node segments must match the segments of the drives of the node
+	for key, value := range n.NodeTopology {
+		segments[key] = value
+	}
+	segments[topology.TopologyDriverIdentity] = n.Identity
+	segments[topology.TopologyDriverRack] = n.Rack
+	segments[topology.TopologyDriverZone] = n.Zone
+	segments[topology.TopologyDriverRegion] = n.Region
+	segments[topology.TopologyDriverNode] = n.NodeID
 	topology := &csi.Topology{
-		Segments: map[string]string{
-			topology.TopologyDriverIdentity: n.Identity,
-			topology.TopologyDriverRack:     n.Rack,
-			topology.TopologyDriverZone:     n.Zone,
-			topology.TopologyDriverRegion:   n.Region,
-			topology.TopologyDriverNode:     n.NodeID,
-		},
+		Segments: segments,
 	}
 
 	return &csi.NodeGetInfoResponse{
 		NodeId:             n.NodeID,
-		MaxVolumesPerNode:  int64(100),
+		MaxVolumesPerNode:  n.MaxVolumesPerNode,
 		AccessibleTopology: topology,
 	}, nil
 }
 
+// nodeCapabilities are the optional RPCs advertised to the kubelet, the kubelet skips the RPCs
+// not advertised. EXPAND_VOLUME is advertised once NodeExpandVolume is implemented.
+var nodeCapabilities = []csi.NodeServiceCapability_RPC_Type{
+	csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+	csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+	// the condition of the volume is reported by NodeGetVolumeStats
+	csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+}
+
 func (n *NodeServer) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
 	nodeCap := func(cap csi.NodeServiceCapability_RPC_Type) *csi.NodeServiceCapability {
 		klog.V(2).Infof("Using node capability %v", cap)
@@ -112,46 +147,111 @@ func (n *NodeServer) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCa
 		}
 	}
 
+	capabilities := []*csi.NodeServiceCapability{}
+	for _, cap := range nodeCapabilities {
+		capabilities = append(capabilities, nodeCap(cap))
+	}
 	return &csi.NodeGetCapabilitiesResponse{
-		Capabilities: []*csi.NodeServiceCapability{
-			nodeCap(csi.NodeServiceCapability_RPC_GET_VOLUME_STATS),
-			nodeCap(csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME),
-		},
+		Capabilities: capabilities,
 	}, nil
 }
 
+// volumeUsageGetter returns the byte and inode usage of a staged volume
+type volumeUsageGetter func(ctx context.Context, vol *directcsi.DirectCSIVolume) ([]*csi.VolumeUsage, error)
+
+// getVolumeUsage reads the usage from the project quota of the staging path of the volume
+func getVolumeUsage(ctx context.Context, vol *directcsi.DirectCSIVolume) ([]*csi.VolumeUsage, error) {
+	volStats, err := metrics.GetVolumeStats(ctx, vol)
+	if err != nil {
+		return nil, err
+	}
+	usage := []*csi.VolumeUsage{
+		{
+			Available: volStats.AvailableBytes,
+			Total:     volStats.TotalBytes,
+			Used:      volStats.UsedBytes,
+			Unit:      csi.VolumeUsage_BYTES,
+		},
+	}
+
+	// the inodes limited by the quota are reported over the inodes of the whole drive
+	if volStats.TotalInodes > 0 {
+		return append(usage, &csi.VolumeUsage{
+			Available: volStats.AvailableInodes,
+			Total:     volStats.TotalInodes,
+			Used:      volStats.UsedInodes,
+			Unit:      csi.VolumeUsage_INODES,
+		}), nil
+	}
+
+	totalInodes, freeInodes, err := sys.GetInodeStats(vol.Status.StagingPath)
+	if err != nil {
+		klog.V(5).Infof("Unable to read the inode stats of volume %s: %v", vol.Name, err)
+		return usage, nil
+	}
+	return append(usage, &csi.VolumeUsage{
+		Available: freeInodes,
+		Total:     totalInodes,
+		Used:      totalInodes - freeInodes,
+		Unit:      csi.VolumeUsage_INODES,
+	}), nil
+}
+
 func (ns *NodeServer) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
 	vID := req.GetVolumeId()
+	if vID == "" {
+		return nil, status.Error(codes.InvalidArgument, "volume ID missing in request")
+	}
 	volumePath := req.GetVolumePath()
-
 	if volumePath == "" {
-		return &csi.NodeGetVolumeStatsResponse{}, nil
+		return nil, status.Error(codes.InvalidArgument, "volumePath missing in request")
 	}
 
-	xfsQuota := &xfs.XFSQuota{
-		Path:      volumePath,
-		ProjectID: vID,
+	if _, err := os.Stat(volumePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, status.Errorf(codes.NotFound, "volume path %s not found", volumePath)
+		}
+		return nil, status.Errorf(codes.Internal, "Error while checking the volume path: %v", err)
 	}
-	volStats, err := xfsQuota.GetVolumeStats(ctx)
+
+	directCSIClient := ns.directcsiClient.DirectV1beta2()
+	vol, err := directCSIClient.DirectCSIVolumes().Get(ctx, vID, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "Error while getting xfs volume stats: %v", err)
+		if k8serrors.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if vol.Status.StagingPath == "" {
+		return nil, status.Errorf(codes.NotFound, "volume %s is not staged", vID)
 	}
 
-	volUsage := &csi.VolumeUsage{
-		Available: volStats.AvailableBytes,
-		Total:     volStats.TotalBytes,
-		Used:      volStats.UsedBytes,
-		Unit:      csi.VolumeUsage_BYTES,
+	usage, err := ns.getVolumeUsage(ctx, vol)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Error while getting volume stats: %v", err)
+	}
+
+	volumeCondition := &csi.VolumeCondition{}
+	csiDrive, err := directCSIClient.DirectCSIDrives().Get(ctx, vol.Status.Drive, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	switch {
+	case err == nil:
+		volumeCondition = getVolumeCondition(csiDrive)
+	case k8serrors.IsNotFound(err):
+		volumeCondition = &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("drive %s not found", vol.Status.Drive),
+		}
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return &csi.NodeGetVolumeStatsResponse{
-		Usage: []*csi.VolumeUsage{
-			volUsage,
-		},
-		VolumeCondition: &csi.VolumeCondition{
-			Abnormal: false,
-			Message:  "",
-		},
+		Usage:           usage,
+		VolumeCondition: volumeCondition,
 	}, nil
 }
 
@@ -17,11 +17,24 @@
 package node
 
 import (
-	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/topology"
+	"github.com/minio/direct-csi/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetLatestStatus(t1 *testing.T) {
@@ -364,3 +377,295 @@ func TestCheckStatusEquality(t2 *testing.T) {
 	}
 
 }
+
+func TestNodeGetInfo(t *testing.T) {
+	ns := &NodeServer{
+		NodeID:            "node-1",
+		Identity:          "direct-csi-min-io",
+		Rack:              "rack-1",
+		Zone:              "zone-1",
+		Region:            "region-1",
+		NodeTopology:      map[string]string{"topology.kubernetes.io/zone": "dc1"},
+		MaxVolumesPerNode: 50,
+	}
+	resp, err := ns.NodeGetInfo(context.TODO(), &csi.NodeGetInfoRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetNodeId() != ns.NodeID {
+		t.Errorf("expected node id %s, got %s", ns.NodeID, resp.GetNodeId())
+	}
+	if resp.GetMaxVolumesPerNode() != ns.MaxVolumesPerNode {
+		t.Errorf("expected max volumes per node %d, got %d", ns.MaxVolumesPerNode, resp.GetMaxVolumesPerNode())
+	}
+	expectedSegments := map[string]string{
+		"topology.kubernetes.io/zone":   "dc1",
+		topology.TopologyDriverIdentity: ns.Identity,
+		topology.TopologyDriverRack:     ns.Rack,
+		topology.TopologyDriverZone:     ns.Zone,
+		topology.TopologyDriverRegion:   ns.Region,
+		topology.TopologyDriverNode:     ns.NodeID,
+	}
+	if !reflect.DeepEqual(resp.GetAccessibleTopology().GetSegments(), expectedSegments) {
+		t.Errorf("expected segments %v, got %v", expectedSegments, resp.GetAccessibleTopology().GetSegments())
+	}
+}
+
+func TestNodeGetCapabilities(t *testing.T) {
+	ns := &NodeServer{}
+	ctx := context.TODO()
+
+	// the RPCs enabled by each capability, an RPC is implemented if it does not fail with Unimplemented
+	probes := map[csi.NodeServiceCapability_RPC_Type][]func() error{
+		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME: {
+			func() error { _, err := ns.NodeStageVolume(ctx, &csi.NodeStageVolumeRequest{}); return err },
+			func() error { _, err := ns.NodeUnstageVolume(ctx, &csi.NodeUnstageVolumeRequest{}); return err },
+		},
+		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS: {
+			func() error { _, err := ns.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{}); return err },
+		},
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION: {
+			func() error { _, err := ns.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{}); return err },
+		},
+		csi.NodeServiceCapability_RPC_EXPAND_VOLUME: {
+			func() error { _, err := ns.NodeExpandVolume(ctx, &csi.NodeExpandVolumeRequest{}); return err },
+		},
+	}
+
+	resp, err := ns.NodeGetCapabilities(ctx, &csi.NodeGetCapabilitiesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	advertised := map[csi.NodeServiceCapability_RPC_Type]bool{}
+	for _, capability := range resp.GetCapabilities() {
+		capType := capability.GetRpc().GetType()
+		if _, found := probes[capType]; !found {
+			t.Errorf("capability %v is advertised but its RPCs are not probed", capType)
+		}
+		advertised[capType] = true
+	}
+
+	for capType, rpcs := range probes {
+		for i, rpc := range rpcs {
+			implemented := status.Code(rpc()) != codes.Unimplemented
+			if implemented != advertised[capType] {
+				t.Errorf("capability %v: RPC %v implemented: %v, advertised: %v", capType, i+1, implemented, advertised[capType])
+			}
+		}
+	}
+}
+
+func TestNodeGetVolumeStats(t *testing.T) {
+	testVolumePath, err := ioutil.TempDir("", "test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testVolumePath)
+
+	createTestDrive := func(driveName string, driveStatus directcsi.DriveStatus) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: driveName,
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:    testNodeName,
+				DriveStatus: driveStatus,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(directcsi.DirectCSIDriveConditionMounted),
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+		}
+	}
+	createTestVolume := func(volumeName, driveName, stagingPath string) *directcsi.DirectCSIVolume {
+		return &directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: volumeName,
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				NodeName:          testNodeName,
+				Drive:             driveName,
+				StagingPath:       stagingPath,
+				TotalCapacity:     mb100,
+				AvailableCapacity: mb20,
+				UsedCapacity:      mb100 - mb20,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		volumeID         string
+		volumePath       string
+		expectedCode     codes.Code
+		expectedAbnormal bool
+	}{
+		{
+			name:         "path_not_found",
+			volumeID:     "healthy_volume",
+			volumePath:   filepath.Join(testVolumePath, "missing"),
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "volume_not_found",
+			volumeID:     "missing_volume",
+			volumePath:   testVolumePath,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "volume_not_staged",
+			volumeID:     "unstaged_volume",
+			volumePath:   testVolumePath,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "healthy_drive",
+			volumeID:     "healthy_volume",
+			volumePath:   testVolumePath,
+			expectedCode: codes.OK,
+		},
+		{
+			name:             "terminating_drive",
+			volumeID:         "terminating_volume",
+			volumePath:       testVolumePath,
+			expectedCode:     codes.OK,
+			expectedAbnormal: true,
+		},
+		{
+			name:             "missing_drive",
+			volumeID:         "orphaned_volume",
+			volumePath:       testVolumePath,
+			expectedCode:     codes.OK,
+			expectedAbnormal: true,
+		},
+	}
+
+	testObjects := []runtime.Object{
+		createTestDrive("healthy_drive", directcsi.DriveStatusInUse),
+		createTestDrive("terminating_drive", directcsi.DriveStatusTerminating),
+		createTestVolume("healthy_volume", "healthy_drive", testVolumePath),
+		createTestVolume("unstaged_volume", "healthy_drive", ""),
+		createTestVolume("terminating_volume", "terminating_drive", testVolumePath),
+		createTestVolume("orphaned_volume", "missing_drive", testVolumePath),
+	}
+
+	ctx := context.TODO()
+	ns := createFakeNodeServer()
+	ns.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ns.NodeGetVolumeStats(ctx, &csi.NodeGetVolumeStatsRequest{
+				VolumeId:   tt.volumeID,
+				VolumePath: tt.volumePath,
+			})
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("Test case name %s: Expected code = %v, got %v (%v)", tt.name, tt.expectedCode, code, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(resp.Usage) != 1 || resp.Usage[0].Total != mb100 || resp.Usage[0].Available != mb20 {
+				t.Errorf("Test case name %s: unexpected usage %v", tt.name, resp.Usage)
+			}
+			if resp.VolumeCondition.Abnormal != tt.expectedAbnormal {
+				t.Errorf("Test case name %s: Expected abnormal = %v, got %v (%s)", tt.name, tt.expectedAbnormal, resp.VolumeCondition.Abnormal, resp.VolumeCondition.Message)
+			}
+		})
+	}
+}
+
+func TestLockLoop(t1 *testing.T) {
+	locks := newNSLockMap()
+	ctx := context.TODO()
+
+	if err := locks.lockLoop(ctx, "/target", 20*time.Millisecond, 5*time.Millisecond); err != nil {
+		t1.Fatalf("unexpected error while locking a free path: %v", err)
+	}
+	if err := locks.lockLoop(ctx, "/other", 20*time.Millisecond, 5*time.Millisecond); err != nil {
+		t1.Fatalf("unexpected error while locking another path: %v", err)
+	}
+
+	err := locks.lockLoop(ctx, "/target", 20*time.Millisecond, 5*time.Millisecond)
+	if status.Code(err) != codes.Aborted {
+		t1.Fatalf("expected %v on timeout, got %v", codes.Aborted, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		locks.unlock("/target")
+	}()
+	if err := locks.lockLoop(ctx, "/target", time.Second, 5*time.Millisecond); err != nil {
+		t1.Fatalf("unexpected error while locking a released path: %v", err)
+	}
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := locks.lockLoop(cancelledCtx, "/target", time.Second, 5*time.Millisecond); status.Code(err) != codes.Aborted {
+		t1.Fatalf("expected %v on cancellation, got %v", codes.Aborted, err)
+	}
+}
+
+func TestLockTimingsValidate(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		timings     LockTimings
+		expectedErr bool
+	}{
+		{name: "default", timings: DefaultLockTimings},
+		{name: "zeropoll", timings: LockTimings{Timeout: time.Minute}, expectedErr: true},
+		{name: "pollgreaterthantimeout", timings: LockTimings{Timeout: time.Second, PollInterval: time.Minute}, expectedErr: true},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := tt.timings.Validate()
+			if tt.expectedErr != (err != nil) {
+				t1.Errorf("Test case name %s: expected error: %v, got: %v", tt.name, tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateKubeletPath(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		kubeletDir  string
+		path        string
+		expectedErr bool
+	}{
+		{name: "staging", kubeletDir: DefaultKubeletDir, path: "/var/lib/kubelet/plugins/kubernetes.io/csi/pv/pvc-1/globalmount"},
+		{name: "target", kubeletDir: DefaultKubeletDir, path: "/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pvc-1/mount"},
+		{name: "root", kubeletDir: DefaultKubeletDir, path: "/var/lib/kubelet", expectedErr: true},
+		{name: "outside", kubeletDir: DefaultKubeletDir, path: "/etc/kubernetes", expectedErr: true},
+		{name: "prefix", kubeletDir: DefaultKubeletDir, path: "/var/lib/kubelet-other/pods", expectedErr: true},
+		{name: "traversal", kubeletDir: DefaultKubeletDir, path: "/var/lib/kubelet/pods/../../../../etc", expectedErr: true},
+		{name: "relative", kubeletDir: DefaultKubeletDir, path: "var/lib/kubelet/pods/uid", expectedErr: true},
+		{name: "unchecked", path: "/etc/kubernetes"},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			ns := createFakeNodeServer()
+			ns.kubeletDir = tt.kubeletDir
+			err := ns.validateKubeletPath("containerPath", tt.path)
+			if tt.expectedErr != (err != nil) {
+				t1.Fatalf("Test case name %s: expected error: %v, got: %v", tt.name, tt.expectedErr, err)
+			}
+			if err != nil && status.Code(err) != codes.InvalidArgument {
+				t1.Errorf("Test case name %s: expected %v, got %v", tt.name, codes.InvalidArgument, err)
+			}
+		})
+	}
+
+	ns := createFakeNodeServer()
+	ns.kubeletDir = DefaultKubeletDir
+	_, err := ns.NodeStageVolume(context.TODO(), &csi.NodeStageVolumeRequest{
+		VolumeId:          "test-volume",
+		StagingTargetPath: "/etc/kubernetes",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t1.Errorf("expected %v for a staging path outside the kubelet directory, got %v", codes.InvalidArgument, err)
+	}
+}
@@ -19,6 +19,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -31,7 +32,7 @@ import (
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -72,13 +73,29 @@ func (n *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublish
 	if stagingTargetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "stagingTargetPath missing in request")
 	}
+	if err := n.validateKubeletPath("stagingTargetPath", stagingTargetPath); err != nil {
+		return nil, err
+	}
 
 	containerPath := req.GetTargetPath()
 	if containerPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "containerPath missing in request")
 	}
+	if err := n.validateKubeletPath("containerPath", containerPath); err != nil {
+		return nil, err
+	}
+
+	if err := n.pathLocks.lockLoop(ctx, containerPath, n.lockTimings.Timeout, n.lockTimings.PollInterval); err != nil {
+		return nil, err
+	}
+	defer n.pathLocks.unlock(containerPath)
 
 	readOnly := req.GetReadonly()
+	propagation, err := utils.ValidateMountPropagation(req.GetVolumeContext()[utils.MountPropagationParameter])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	directCSIClient := n.directcsiClient.DirectV1beta2()
 	vclient := directCSIClient.DirectCSIVolumes()
 
@@ -133,8 +150,30 @@ func (n *NodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublish
 		return nil, err
 	}
 
-	if err := n.mounter.MountVolume(ctx, stagingTargetPath, containerPath, vID, 0, readOnly); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed volume publish: %v", err)
+	mounted, err := n.mounter.IsVolumeMounted(stagingTargetPath, containerPath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to check the mounts of %s: %v", containerPath, err)
+	}
+	if mounted {
+		// the volume is published again at the same path, its read-only flag is changed to the requested one
+		mountedReadOnly, err := n.mounter.IsReadOnlyMount(containerPath)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to check the mount flags of %s: %v", containerPath, err)
+		}
+		if mountedReadOnly != readOnly {
+			klog.V(3).Infof("remounting volume %s at %s with readOnly %v", vID, containerPath, readOnly)
+			if err := n.mounter.RemountVolume(ctx, containerPath, readOnly); err != nil {
+				return nil, mountStatusError(err, "failed to remount the published volume")
+			}
+		}
+	} else {
+		if _, err := n.mounter.MountVolume(ctx, stagingTargetPath, containerPath, vID, 0, 0, readOnly); err != nil {
+			return nil, mountStatusError(err, "failed volume publish")
+		}
+	}
+
+	if err := n.mounter.SetMountPropagation(ctx, containerPath, propagation); err != nil {
+		return nil, mountStatusError(err, "failed to set mount propagation")
 	}
 
 	conditions := vol.Status.Conditions
@@ -168,6 +207,14 @@ func (n *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpub
 	if containerPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "containerPath missing in request")
 	}
+	if err := n.validateKubeletPath("containerPath", containerPath); err != nil {
+		return nil, err
+	}
+
+	if err := n.pathLocks.lockLoop(ctx, containerPath, n.lockTimings.Timeout, n.lockTimings.PollInterval); err != nil {
+		return nil, err
+	}
+	defer n.pathLocks.unlock(containerPath)
 
 	directCSIClient := n.directcsiClient.DirectV1beta2()
 	vclient := directCSIClient.DirectCSIVolumes()
@@ -184,6 +231,9 @@ func (n *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpub
 	if err := n.mounter.UnmountVolume(containerPath); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if err := n.removeEmptyTargetDir(vol.Status.StagingPath, containerPath); err != nil {
+		klog.Warningf("unable to remove the target path %s of volume %s: %v", containerPath, vID, err)
+	}
 
 	conditions := vol.Status.Conditions
 	for i, c := range conditions {
@@ -205,3 +255,36 @@ func (n *NodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpub
 
 	return &csi.NodeUnpublishVolumeResponse{}, nil
 }
+
+// removeEmptyTargetDir removes the target directory left behind by the unmount,
+// so that the directories do not pile up under the kubelet pods directory over
+// the pod restarts. The directory is kept if it is still mounted or not empty.
+func (n *NodeServer) removeEmptyTargetDir(stagingPath, targetPath string) error {
+	mounted, err := n.mounter.IsVolumeMounted(stagingPath, targetPath)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		return fmt.Errorf("%s is still mounted", targetPath)
+	}
+
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	_, err = dir.Readdirnames(1)
+	dir.Close()
+	switch err {
+	case io.EOF:
+	case nil:
+		return fmt.Errorf("%s is not empty", targetPath)
+	default:
+		return err
+	}
+
+	// rmdir fails with EBUSY if something else is still mounted on the directory
+	return os.Remove(targetPath)
+}
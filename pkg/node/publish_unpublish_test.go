@@ -20,9 +20,11 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
@@ -106,6 +108,9 @@ func TestPublishUnpublishVolume(t *testing.T) {
 			},
 		},
 		Readonly: false,
+		VolumeContext: map[string]string{
+			utils.MountPropagationParameter: string(sys.MountPropagationHostToContainer),
+		},
 	}
 
 	unpublishVolumeRequest := csi.NodeUnpublishVolumeRequest{
@@ -147,6 +152,13 @@ func TestPublishUnpublishVolume(t *testing.T) {
 		t.Errorf("Wrong readOnly argument passed for mounting. Expected: %v, Got: %v", publishVolumeRequest.GetReadonly(), ns.mounter.(*fakeVolumeMounter).mountArgs.readOnly)
 	}
 
+	if ns.mounter.(*fakeVolumeMounter).propagationArgs.target != testContainerPath {
+		t.Errorf("Wrong target argument passed for mount propagation. Expected: %v, Got: %v", testContainerPath, ns.mounter.(*fakeVolumeMounter).propagationArgs.target)
+	}
+	if ns.mounter.(*fakeVolumeMounter).propagationArgs.propagation != sys.MountPropagationHostToContainer {
+		t.Errorf("Wrong mount propagation. Expected: %v, Got: %v", sys.MountPropagationHostToContainer, ns.mounter.(*fakeVolumeMounter).propagationArgs.propagation)
+	}
+
 	// Check if status fields were set correctly
 	if volObj.Status.ContainerPath != testContainerPath {
 		t.Errorf("Wrong ContainerPath set in the volume object. Expected %v, Got: %v", testContainerPath, volObj.Status.ContainerPath)
@@ -174,6 +186,11 @@ func TestPublishUnpublishVolume(t *testing.T) {
 		t.Errorf("Wrong target argument passed for unmounting. Expected: %v, Got: %v", unpublishVolumeRequest.GetTargetPath(), ns.mounter.(*fakeVolumeMounter).unmountArgs.target)
 	}
 
+	// Check if the empty target directory was removed
+	if _, err := os.Stat(testContainerPath); !os.IsNotExist(err) {
+		t.Errorf("target path %s was not removed after unpublish. Error: %v", testContainerPath, err)
+	}
+
 	// Check if the status fields were unset
 	if volObj.Status.ContainerPath != "" {
 		t.Errorf("StagingPath was not set to empty. Got: %v", volObj.Status.ContainerPath)
@@ -184,3 +201,96 @@ func TestPublishUnpublishVolume(t *testing.T) {
 		t.Errorf("unexpected status.conditions after unstaging = %v", volObj.Status.Conditions)
 	}
 }
+
+func TestRepublishVolumeReadOnly(t1 *testing.T) {
+	testCases := []struct {
+		name            string
+		publishedRO     bool
+		requestedRO     bool
+		expectedRemount bool
+	}{
+		{name: "rw_to_ro", publishedRO: false, requestedRO: true, expectedRemount: true},
+		{name: "ro_to_rw", publishedRO: true, requestedRO: false, expectedRemount: true},
+		{name: "ro_to_ro", publishedRO: true, requestedRO: true},
+		{name: "rw_to_rw", publishedRO: false, requestedRO: false},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t *testing.T) {
+			testStagingPath := t.TempDir()
+			testContainerPath := t.TempDir()
+			testVol := &directcsi.DirectCSIVolume{
+				TypeMeta:   utils.DirectCSIVolumeTypeMeta(),
+				ObjectMeta: metav1.ObjectMeta{Name: "test_volume"},
+				Status: directcsi.DirectCSIVolumeStatus{
+					NodeName:    testNodeName,
+					StagingPath: testStagingPath,
+				},
+			}
+			ns := createFakeNodeServer()
+			ns.directcsiClient = fakedirect.NewSimpleClientset(testVol)
+			mounter := ns.mounter.(*fakeVolumeMounter)
+
+			request := &csi.NodePublishVolumeRequest{
+				VolumeId:          testVol.Name,
+				StagingTargetPath: testStagingPath,
+				TargetPath:        testContainerPath,
+				Readonly:          tt.publishedRO,
+			}
+			if _, err := ns.NodePublishVolume(context.TODO(), request); err != nil {
+				t.Fatalf("unable to publish the volume: %v", err)
+			}
+
+			request.Readonly = tt.requestedRO
+			if _, err := ns.NodePublishVolume(context.TODO(), request); err != nil {
+				t.Fatalf("unable to republish the volume: %v", err)
+			}
+			if mounter.mountCount != 1 {
+				t.Errorf("expected the volume to be mounted once, got %d mounts", mounter.mountCount)
+			}
+			if remounted := mounter.remountArgs.target == testContainerPath; remounted != tt.expectedRemount {
+				t.Errorf("expected remount: %v, got: %v", tt.expectedRemount, remounted)
+			}
+			if readOnly, _ := mounter.IsReadOnlyMount(testContainerPath); readOnly != tt.requestedRO {
+				t.Errorf("expected the volume to be mounted with readOnly %v, got %v", tt.requestedRO, readOnly)
+			}
+		})
+	}
+}
+
+func TestRemoveEmptyTargetDir(t *testing.T) {
+	testStagingPath := t.TempDir()
+
+	emptyDir := t.TempDir()
+	nonEmptyDir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(nonEmptyDir, "data"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mountedDir := t.TempDir()
+
+	ns := createFakeNodeServer()
+	ns.mounter.(*fakeVolumeMounter).mounts = map[string]string{mountedDir: testStagingPath}
+
+	testCases := []struct {
+		name        string
+		targetPath  string
+		expectErr   bool
+		expectExist bool
+	}{
+		{name: "empty", targetPath: emptyDir},
+		{name: "missing", targetPath: filepath.Join(emptyDir, "missing")},
+		{name: "notEmpty", targetPath: nonEmptyDir, expectErr: true, expectExist: true},
+		{name: "stillMounted", targetPath: mountedDir, expectErr: true, expectExist: true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ns.removeEmptyTargetDir(testStagingPath, tt.targetPath)
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+			_, statErr := os.Stat(tt.targetPath)
+			if exists := statErr == nil; exists != tt.expectExist {
+				t.Errorf("expected the target path to exist: %v, got: %v", tt.expectExist, exists)
+			}
+		})
+	}
+}
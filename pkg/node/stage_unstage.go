@@ -23,6 +23,7 @@ import (
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/utils"
+	"github.com/minio/direct-csi/pkg/volume"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +31,7 @@ import (
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 func (n *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
@@ -43,6 +44,27 @@ func (n *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolu
 	if stagingTargetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "stagingTargetPath missing in request")
 	}
+	if err := n.validateKubeletPath("stagingTargetPath", stagingTargetPath); err != nil {
+		return nil, err
+	}
+	inodeLimit, err := utils.ParseInodeLimit(req.GetVolumeContext()[utils.InodeLimitParameter])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	fsGroup, err := utils.ParseFSGroup(req.GetVolumeContext()[utils.FSGroupParameter])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	fsGroupChangePolicy, err := utils.ValidateFSGroupChangePolicy(req.GetVolumeContext()[utils.FSGroupChangePolicyParameter])
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// the data of the volume must not be staged while it is copied to another drive
+	if !volume.TryLockVolume(vID) {
+		return nil, status.Errorf(codes.Aborted, "an operation on volume %s is in progress", vID)
+	}
+	defer volume.UnlockVolume(vID)
 
 	directCSIClient := n.directcsiClient.DirectV1beta2()
 	dclient := directCSIClient.DirectCSIDrives()
@@ -54,6 +76,9 @@ func (n *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolu
 	if err != nil {
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
+	if volume.IsMigrationPending(vol) {
+		return nil, status.Errorf(codes.Aborted, "volume %s is being moved to drive %s", vID, vol.GetAnnotations()[directcsi.DirectCSIVolumeAnnotationMigrateTo])
+	}
 
 	drive, err := dclient.Get(ctx, vol.Status.Drive, metav1.GetOptions{
 		TypeMeta: utils.DirectCSIDriveTypeMeta(),
@@ -62,14 +87,38 @@ func (n *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolu
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
+	if err := checkVolumeTopology(vol, drive); err != nil {
+		return nil, err
+	}
+
 	path := filepath.Join(drive.Status.Mountpoint, vID)
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
 	}
 
 	size := vol.Status.TotalCapacity
-	if err := n.mounter.MountVolume(ctx, path, stagingTargetPath, vID, size, false); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed stage volume: %v", err)
+	mounted, err := n.mounter.IsVolumeMounted(path, stagingTargetPath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check the staging path %s: %v", stagingTargetPath, err)
+	}
+	// the staging path is left mounted by a previous stage whose response was lost
+	quotaEnforced := !vol.Status.QuotaUnenforced
+	if mounted {
+		klog.V(3).Infof("volume %s is already staged at %s", vID, stagingTargetPath)
+	} else {
+		if quotaEnforced, err = n.mounter.MountVolume(ctx, path, stagingTargetPath, vID, size, inodeLimit, false); err != nil {
+			return nil, mountStatusError(err, "failed stage volume")
+		}
+		if !quotaEnforced {
+			klog.Warningf("volume %s is staged without quota, usage is not limited to %d bytes", vID, size)
+		}
+	}
+
+	// non-root pods can write to the volume once it is owned by their fsGroup
+	if fsGroup >= 0 {
+		if err := n.mounter.SetVolumeOwnership(ctx, path, fsGroup, fsGroupChangePolicy); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to set the group ownership of volume %s: %v", vID, err)
+		}
 	}
 
 	conditions := vol.Status.Conditions
@@ -87,6 +136,7 @@ func (n *NodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolu
 
 	vol.Status.HostPath = path
 	vol.Status.StagingPath = stagingTargetPath
+	vol.Status.QuotaUnenforced = !quotaEnforced
 
 	if _, err := vclient.Update(ctx, vol, metav1.UpdateOptions{
 		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
@@ -107,6 +157,9 @@ func (n *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstage
 	if stagingTargetPath == "" {
 		return nil, status.Error(codes.InvalidArgument, "stagingTargetPath missing in request")
 	}
+	if err := n.validateKubeletPath("stagingTargetPath", stagingTargetPath); err != nil {
+		return nil, err
+	}
 
 	directCSIClient := n.directcsiClient.DirectV1beta2()
 	vclient := directCSIClient.DirectCSIVolumes()
@@ -138,6 +191,8 @@ func (n *NodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstage
 
 	vol.Status.HostPath = ""
 	vol.Status.StagingPath = ""
+	// QuotaUnenforced is kept until the volume is staged again, the cleanup of the volume
+	// does not remove a quota which was never set
 	if _, err := directCSIClient.DirectCSIVolumes().Update(ctx, vol, metav1.UpdateOptions{
 		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
 	}); err != nil {
@@ -24,7 +24,11 @@ import (
 	"testing"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
+	"github.com/minio/direct-csi/pkg/volume"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
@@ -122,6 +126,10 @@ func TestStageUnstageVolume(t *testing.T) {
 				Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
 			},
 		},
+		VolumeContext: map[string]string{
+			utils.InodeLimitParameter: "1000",
+			utils.FSGroupParameter:    "2000",
+		},
 	}
 
 	unstageVolumeRequest := csi.NodeUnstageVolumeRequest{
@@ -160,9 +168,15 @@ func TestStageUnstageVolume(t *testing.T) {
 	if ns.mounter.(*fakeVolumeMounter).mountArgs.size != volObj.Status.TotalCapacity {
 		t.Errorf("Wrong size argument passed for mounting. Expected: %v, Got: %v", volObj.Status.TotalCapacity, ns.mounter.(*fakeVolumeMounter).mountArgs.size)
 	}
+	if ns.mounter.(*fakeVolumeMounter).mountArgs.inodeLimit != 1000 {
+		t.Errorf("Wrong inodeLimit argument passed for mounting. Expected: 1000, Got: %v", ns.mounter.(*fakeVolumeMounter).mountArgs.inodeLimit)
+	}
 	if ns.mounter.(*fakeVolumeMounter).mountArgs.readOnly {
 		t.Errorf("Wrong readOnly argument passed for mounting. Expected: False, Got: %v", ns.mounter.(*fakeVolumeMounter).mountArgs.readOnly)
 	}
+	if ownershipArgs := ns.mounter.(*fakeVolumeMounter).ownershipArgs; ownershipArgs.target != hostPath || ownershipArgs.fsGroup != 2000 || ownershipArgs.policy != sys.FSGroupChangeOnRootMismatch {
+		t.Errorf("Wrong arguments passed for the group ownership. Expected: %v 2000 %v, Got: %+v", hostPath, sys.FSGroupChangeOnRootMismatch, ownershipArgs)
+	}
 
 	// Check if status fields were set correctly
 	if volObj.Status.HostPath != hostPath {
@@ -171,12 +185,23 @@ func TestStageUnstageVolume(t *testing.T) {
 	if volObj.Status.StagingPath != stageVolumeRequest.GetStagingTargetPath() {
 		t.Errorf("Wrong StagingPath set in the volume object. Expected %v, Got: %v", stageVolumeRequest.GetStagingTargetPath(), volObj.Status.StagingPath)
 	}
+	if volObj.Status.QuotaUnenforced {
+		t.Errorf("QuotaUnenforced was set in the volume object staged with quota")
+	}
 
 	// Check if conditions were toggled correctly
 	if !utils.IsCondition(volObj.Status.Conditions, string(directcsi.DirectCSIVolumeConditionStaged), metav1.ConditionTrue, string(directcsi.DirectCSIVolumeReasonInUse), "") {
 		t.Errorf("unexpected status.conditions after staging = %v", volObj.Status.Conditions)
 	}
 
+	// Duplicate Stage Volume test
+	if _, err := ns.NodeStageVolume(ctx, &stageVolumeRequest); err != nil {
+		t.Fatalf("[%s] Duplicate StageVolume failed. Error: %v", stageVolumeRequest.VolumeId, err)
+	}
+	if mountCount := ns.mounter.(*fakeVolumeMounter).mountCount; mountCount != 1 {
+		t.Errorf("Staging path was mounted again by the duplicate StageVolume. Mount count: %v", mountCount)
+	}
+
 	// Unstage Volume test
 	if _, err := ns.NodeUnstageVolume(ctx, &unstageVolumeRequest); err != nil {
 		t.Fatalf("[%s] UnstageVolume failed. Error: %v", unstageVolumeRequest.VolumeId, err)
@@ -206,4 +231,102 @@ func TestStageUnstageVolume(t *testing.T) {
 	if !utils.IsCondition(volObj.Status.Conditions, string(directcsi.DirectCSIVolumeConditionStaged), metav1.ConditionFalse, string(directcsi.DirectCSIVolumeReasonNotInUse), "") {
 		t.Errorf("unexpected status.conditions after unstaging = %v", volObj.Status.Conditions)
 	}
+
+	// Stage Volume test without project quota support
+	ns.mounter.(*fakeVolumeMounter).quotaUnsupported = true
+	if _, err := ns.NodeStageVolume(ctx, &stageVolumeRequest); err != nil {
+		t.Fatalf("[%s] StageVolume without quota failed. Error: %v", stageVolumeRequest.VolumeId, err)
+	}
+
+	volObj, gErr = directCSIClient.DirectCSIVolumes().Get(ctx, stageVolumeRequest.GetVolumeId(), metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if gErr != nil {
+		t.Fatalf("Volume (%s) not found. Error: %v", stageVolumeRequest.GetVolumeId(), gErr)
+	}
+	if !volObj.Status.QuotaUnenforced {
+		t.Errorf("QuotaUnenforced was not set in the volume object staged without quota")
+	}
+	if !utils.IsCondition(volObj.Status.Conditions, string(directcsi.DirectCSIVolumeConditionStaged), metav1.ConditionTrue, string(directcsi.DirectCSIVolumeReasonInUse), "") {
+		t.Errorf("unexpected status.conditions after staging without quota = %v", volObj.Status.Conditions)
+	}
+
+	// Stage Volume test while an operation holds the lock of the volume
+	if !volume.TryLockVolume(testVolumeName50MB) {
+		t.Fatalf("unable to lock volume %s", testVolumeName50MB)
+	}
+	_, err = ns.NodeStageVolume(ctx, &stageVolumeRequest)
+	volume.UnlockVolume(testVolumeName50MB)
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("StageVolume of a locked volume: expected code %v, got: %v", codes.Aborted, err)
+	}
+
+	// Stage Volume test while the volume is being moved to another drive
+	volObj.SetAnnotations(map[string]string{directcsi.DirectCSIVolumeAnnotationMigrateTo: "other_drive"})
+	if _, err := directCSIClient.DirectCSIVolumes().Update(ctx, volObj, metav1.UpdateOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	}); err != nil {
+		t.Fatalf("unable to update volume %s: %v", testVolumeName50MB, err)
+	}
+	mountCount := ns.mounter.(*fakeVolumeMounter).mountCount
+	if _, err := ns.NodeStageVolume(ctx, &stageVolumeRequest); status.Code(err) != codes.Aborted {
+		t.Errorf("StageVolume of a migrating volume: expected code %v, got: %v", codes.Aborted, err)
+	}
+	if ns.mounter.(*fakeVolumeMounter).mountCount != mountCount {
+		t.Errorf("migrating volume was mounted")
+	}
+}
+
+func TestCheckVolumeTopology(t1 *testing.T) {
+	testCases := []struct {
+		name           string
+		volumeTopology map[string]string
+		driveTopology  map[string]string
+		expectErr      bool
+	}{
+		{
+			name:           "test1",
+			volumeTopology: nil,
+			driveTopology:  map[string]string{"direct.csi.min.io/node": "N1"},
+			expectErr:      false,
+		},
+		{
+			name:           "test2",
+			volumeTopology: map[string]string{"direct.csi.min.io/node": "N1", "direct.csi.min.io/zone": "Z1"},
+			driveTopology:  map[string]string{"direct.csi.min.io/node": "N1", "direct.csi.min.io/zone": "Z1", "direct.csi.min.io/rack": "R1"},
+			expectErr:      false,
+		},
+		{
+			name:           "test3",
+			volumeTopology: map[string]string{"direct.csi.min.io/node": "N1", "direct.csi.min.io/zone": "Z1"},
+			driveTopology:  map[string]string{"direct.csi.min.io/node": "N1", "direct.csi.min.io/zone": "Z2"},
+			expectErr:      true,
+		},
+		{
+			name:           "test4",
+			volumeTopology: map[string]string{"direct.csi.min.io/node": "N1"},
+			driveTopology:  nil,
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			vol := &directcsi.DirectCSIVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "volume"},
+				Status:     directcsi.DirectCSIVolumeStatus{Topology: tt.volumeTopology},
+			}
+			drive := &directcsi.DirectCSIDrive{
+				ObjectMeta: metav1.ObjectMeta{Name: "drive"},
+				Status:     directcsi.DirectCSIDriveStatus{Topology: tt.driveTopology},
+			}
+			err := checkVolumeTopology(vol, drive)
+			if tt.expectErr && err == nil {
+				t1.Errorf("Test case name %s: expected error but got nil", tt.name)
+			}
+			if !tt.expectErr && err != nil {
+				t1.Errorf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+		})
+	}
 }
@@ -18,9 +18,13 @@ package node
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,9 +32,76 @@ import (
 	kexec "k8s.io/utils/exec"
 	"k8s.io/utils/mount"
 
-	"k8s.io/klog"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
 )
 
+// DefaultKubeletDir is the root directory of the kubelet holding the staging and the target paths of the volumes
+const DefaultKubeletDir = "/var/lib/kubelet"
+
+// validateKubeletPath fails with codes.InvalidArgument if the path requested by the CO is not within the
+// kubelet directory, so that the privileged node server does not create or mount at arbitrary host paths
+func (n *NodeServer) validateKubeletPath(name, path string) error {
+	if n.kubeletDir == "" {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		return status.Errorf(codes.InvalidArgument, "%s %s is not an absolute path", name, path)
+	}
+	rel, err := filepath.Rel(n.kubeletDir, filepath.Clean(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return status.Errorf(codes.InvalidArgument, "%s %s is not within the kubelet directory %s", name, path, n.kubeletDir)
+	}
+	return nil
+}
+
+// mountStatusError returns the cancellation of a mount as is, so that the
+// caller sees the expired RPC deadline; other errors are internal errors
+func mountStatusError(err error, msg string) error {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Canceled:
+		return err
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// getVolumeCondition reports the volume abnormal if its drive is not healthy
+func getVolumeCondition(drive *directcsi.DirectCSIDrive) *csi.VolumeCondition {
+	switch drive.Status.DriveStatus {
+	case directcsi.DriveStatusInUse, directcsi.DriveStatusReady:
+	default:
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("drive %s is %s", drive.Name, drive.Status.DriveStatus),
+		}
+	}
+	for _, condition := range drive.Status.Conditions {
+		switch condition.Type {
+		case string(directcsi.DirectCSIDriveConditionInitialized), string(directcsi.DirectCSIDriveConditionMounted):
+			if condition.Status != metav1.ConditionTrue {
+				return &csi.VolumeCondition{
+					Abnormal: true,
+					Message:  fmt.Sprintf("drive %s is not %s: %s", drive.Name, strings.ToLower(condition.Type), condition.Message),
+				}
+			}
+		}
+	}
+	return &csi.VolumeCondition{}
+}
+
+// checkVolumeTopology fails if the drive of the volume does not satisfy the
+// topology segments recorded on the volume at provisioning
+func checkVolumeTopology(vol *directcsi.DirectCSIVolume, drive *directcsi.DirectCSIDrive) error {
+	for key, value := range vol.Status.Topology {
+		if got, ok := drive.Status.Topology[key]; !ok || got != value {
+			return status.Errorf(codes.FailedPrecondition, "drive %s does not match the topology of volume %s; %s=%s, expected %s", drive.Name, vol.Name, key, got, value)
+		}
+	}
+	return nil
+}
+
 // GetLatestStatus gets the latest condition by time
 func GetLatestStatus(statusXs []metav1.Condition) metav1.Condition {
 	// Sort the drives by LastTransitionTime [Descending]
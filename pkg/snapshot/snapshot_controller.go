@@ -0,0 +1,267 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/clientset"
+	"github.com/minio/direct-csi/pkg/health"
+	"github.com/minio/direct-csi/pkg/listener"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/klog/v2"
+)
+
+// snapshotDir is the directory under the drive mountpoint holding the snapshots
+const snapshotDir = ".snapshots"
+
+type reflinkChecker func(ctx context.Context, mountPoint string) (bool, error)
+
+type reflinkCopier func(ctx context.Context, src, dest string) (string, error)
+
+type quotaSetter func(ctx context.Context, path, projectID string, limit int64) error
+
+type quotaRemover func(ctx context.Context, path, projectID string) error
+
+func setSnapshotQuota(ctx context.Context, path, projectID string, limit int64) error {
+	quota, err := sys.NewQuota(path, projectID)
+	if err != nil {
+		return err
+	}
+	return quota.SetQuota(ctx, limit, 0)
+}
+
+func removeSnapshotQuota(ctx context.Context, path, projectID string) error {
+	quota, err := sys.NewQuota(path, projectID)
+	if err != nil {
+		return err
+	}
+	return quota.RemoveQuota(ctx)
+}
+
+type DirectCSISnapshotListener struct {
+	kubeClient         kubeclientset.Interface
+	directcsiClient    clientset.Interface
+	nodeID             string
+	isReflinkSupported reflinkChecker
+	copy               reflinkCopier
+	setQuota           quotaSetter
+	removeQuota        quotaRemover
+}
+
+func (b *DirectCSISnapshotListener) InitializeKubeClient(k kubeclientset.Interface) {
+	b.kubeClient = k
+}
+
+func (b *DirectCSISnapshotListener) InitializeDirectCSIClient(bc clientset.Interface) {
+	b.directcsiClient = bc
+}
+
+func (b *DirectCSISnapshotListener) Add(ctx context.Context, obj *directcsi.DirectCSISnapshot) error {
+	return b.sync(ctx, obj)
+}
+
+func (b *DirectCSISnapshotListener) Update(ctx context.Context, old, new *directcsi.DirectCSISnapshot) error {
+	return b.sync(ctx, new)
+}
+
+func (b *DirectCSISnapshotListener) Delete(ctx context.Context, obj *directcsi.DirectCSISnapshot) error {
+	return nil
+}
+
+func (b *DirectCSISnapshotListener) sync(ctx context.Context, snapshot *directcsi.DirectCSISnapshot) error {
+	// Skip snapshots from other nodes
+	if snapshot.Status.NodeName != b.nodeID {
+		return nil
+	}
+
+	if !snapshot.GetDeletionTimestamp().IsZero() {
+		return b.cleanup(ctx, snapshot)
+	}
+
+	if snapshot.Status.ReadyToUse || snapshot.Status.Message != "" {
+		return nil
+	}
+
+	drive, err := b.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(ctx, snapshot.Status.Drive, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	if drive.Status.Mountpoint == "" {
+		return fmt.Errorf("drive %s of snapshot %s is not mounted", drive.Name, snapshot.Name)
+	}
+
+	supported, err := b.isReflinkSupported(ctx, drive.Status.Mountpoint)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		// a full copy would take long and double the usage of the drive, hence fail the snapshot
+		snapshot.Status.Message = fmt.Sprintf("reflink is not enabled on the filesystem of drive %s", drive.Name)
+		if err := b.releaseDrive(ctx, snapshot); err != nil {
+			return err
+		}
+		return b.updateSnapshot(ctx, snapshot)
+	}
+
+	snapshotPath := filepath.Join(drive.Status.Mountpoint, snapshotDir, snapshot.Name)
+	if err := os.MkdirAll(filepath.Dir(snapshotPath), 0755); err != nil {
+		return err
+	}
+	// remove the leftovers of an interrupted copy
+	if err := os.RemoveAll(snapshotPath); err != nil {
+		return err
+	}
+
+	sourcePath := filepath.Join(drive.Status.Mountpoint, snapshot.Status.SourceVolume)
+	if output, err := b.copy(ctx, sourcePath, snapshotPath); err != nil {
+		return fmt.Errorf("unable to copy volume %s to snapshot %s; %v: %v", snapshot.Status.SourceVolume, snapshot.Name, err, output)
+	}
+	// the copy gets a project quota of its own, limited to the size of the volume like the volume itself
+	if snapshot.Status.SizeBytes > 0 {
+		if err := b.setQuota(ctx, snapshotPath, snapshot.Name, snapshot.Status.SizeBytes); err != nil {
+			klog.Warningf("unable to set the quota of snapshot %s on drive %s: %v", snapshot.Name, drive.Name, err)
+		}
+	}
+
+	snapshot.Status.SnapshotPath = snapshotPath
+	snapshot.Status.ReadyToUse = true
+	return b.updateSnapshot(ctx, snapshot)
+}
+
+func (b *DirectCSISnapshotListener) cleanup(ctx context.Context, snapshot *directcsi.DirectCSISnapshot) error {
+	if snapshot.Status.SnapshotPath != "" {
+		if snapshot.Status.SizeBytes > 0 {
+			if err := b.removeQuota(ctx, snapshot.Status.SnapshotPath, snapshot.Name); err != nil {
+				// a stale quota does not limit the other volumes, it must not block the deletion
+				klog.Warningf("unable to remove the quota of snapshot %s: %v", snapshot.Name, err)
+			}
+		}
+		if err := os.RemoveAll(snapshot.Status.SnapshotPath); err != nil {
+			return err
+		}
+	}
+	if err := b.releaseDrive(ctx, snapshot); err != nil {
+		return err
+	}
+
+	finalizers := snapshot.GetFinalizers()
+	updatedFinalizers := []string{}
+	for _, f := range finalizers {
+		if f == directcsi.DirectCSISnapshotFinalizerDataProtection {
+			continue
+		}
+		updatedFinalizers = append(updatedFinalizers, f)
+	}
+	if len(updatedFinalizers) == len(finalizers) {
+		return nil
+	}
+	snapshot.SetFinalizers(updatedFinalizers)
+	return b.updateSnapshot(ctx, snapshot)
+}
+
+// releaseDrive removes the finalizer of the snapshot from the drive and releases the capacity charged for the copy
+func (b *DirectCSISnapshotListener) releaseDrive(ctx context.Context, snapshot *directcsi.DirectCSISnapshot) error {
+	dclient := b.directcsiClient.DirectV1beta2().DirectCSIDrives()
+	drive, err := dclient.Get(ctx, snapshot.Status.Drive, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		// the snapshots taken before the drives were charged do not hold their drive
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	sFinalizer := directcsi.DirectCSIDriveFinalizerSnapshotPrefix + snapshot.Name
+	finalizers := drive.GetFinalizers()
+	updatedFinalizers := []string{}
+	for _, f := range finalizers {
+		if f == sFinalizer {
+			continue
+		}
+		updatedFinalizers = append(updatedFinalizers, f)
+	}
+	// already released
+	if len(updatedFinalizers) == len(finalizers) {
+		return nil
+	}
+	if len(updatedFinalizers) == 1 && updatedFinalizers[0] == directcsi.DirectCSIDriveFinalizerDataProtection {
+		drive.Status.DriveStatus = directcsi.DriveStatusReady
+	}
+	drive.SetFinalizers(updatedFinalizers)
+
+	drive.Status.FreeCapacity = drive.Status.FreeCapacity + snapshot.Status.SizeBytes
+	drive.Status.AllocatedCapacity = drive.Status.TotalCapacity - drive.Status.FreeCapacity - drive.Status.ReservedCapacity
+
+	_, err = dclient.Update(ctx, drive, metav1.UpdateOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	return err
+}
+
+func (b *DirectCSISnapshotListener) updateSnapshot(ctx context.Context, snapshot *directcsi.DirectCSISnapshot) error {
+	_, err := b.directcsiClient.DirectV1beta2().DirectCSISnapshots().Update(ctx, snapshot, metav1.UpdateOptions{
+		TypeMeta: utils.DirectCSISnapshotTypeMeta(),
+	})
+	return err
+}
+
+func StartSnapshotController(ctx context.Context, nodeID string, timings listener.ControllerTimings) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	for {
+		ctrl, err := listener.NewDefaultDirectCSIController("snapshot-controller", hostname, 40, timings)
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		ctrl.AddDirectCSISnapshotListener(&DirectCSISnapshotListener{
+			nodeID:             nodeID,
+			isReflinkSupported: sys.IsReflinkSupported,
+			copy:               sys.ReflinkCopy,
+			setQuota:           setSnapshotQuota,
+			removeQuota:        removeSnapshotQuota,
+		})
+		health.RegisterReadinessCheck("snapshot-controller", func() error {
+			if !ctrl.HasSynced() {
+				return errors.New("snapshot controller caches are not synced")
+			}
+			return nil
+		})
+		// contend for the leadership again if another replica took it over
+		if err := ctrl.Run(ctx); !errors.Is(err, listener.ErrLeadershipLost) {
+			return err
+		}
+	}
+}
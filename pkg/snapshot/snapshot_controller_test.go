@@ -0,0 +1,210 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const testNodeName = "test-node"
+
+type fakeCopier struct {
+	copied map[string]string
+}
+
+func (c *fakeCopier) copy(ctx context.Context, src, dest string) (string, error) {
+	c.copied[dest] = src
+	return "", os.MkdirAll(dest, 0755)
+}
+
+type fakeQuota struct {
+	limits map[string]int64
+}
+
+func (q *fakeQuota) setQuota(ctx context.Context, path, projectID string, limit int64) error {
+	q.limits[path] = limit
+	return nil
+}
+
+func (q *fakeQuota) removeQuota(ctx context.Context, path, projectID string) error {
+	delete(q.limits, path)
+	return nil
+}
+
+func TestSnapshotListener(t1 *testing.T) {
+	mountPoint, err := ioutil.TempDir("", "snapshot-test-")
+	if err != nil {
+		t1.Fatalf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(mountPoint)
+
+	newSnapshot := func(name, drive string) *directcsi.DirectCSISnapshot {
+		return &directcsi.DirectCSISnapshot{
+			TypeMeta: utils.DirectCSISnapshotTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Finalizers: []string{directcsi.DirectCSISnapshotFinalizerDataProtection},
+			},
+			Status: directcsi.DirectCSISnapshotStatus{
+				SourceVolume: "volume-1",
+				Drive:        drive,
+				NodeName:     testNodeName,
+				SizeBytes:    1024 * 1024,
+			},
+		}
+	}
+	// the drive holds the volume and the snapshot charged by the controller
+	newDrive := func(name, snapshotName string) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Finalizers: []string{
+					directcsi.DirectCSIDriveFinalizerDataProtection,
+					directcsi.DirectCSIDriveFinalizerPrefix + "volume-1",
+					directcsi.DirectCSIDriveFinalizerSnapshotPrefix + snapshotName,
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          testNodeName,
+				Mountpoint:        filepath.Join(mountPoint, name),
+				DriveStatus:       directcsi.DriveStatusInUse,
+				TotalCapacity:     4 * 1024 * 1024,
+				FreeCapacity:      2 * 1024 * 1024,
+				AllocatedCapacity: 2 * 1024 * 1024,
+			},
+		}
+	}
+	checkDriveReleased := func(t1 *testing.T, listener *DirectCSISnapshotListener, name, driveName string) {
+		drive, err := listener.directcsiClient.DirectV1beta2().DirectCSIDrives().Get(context.TODO(), driveName, metav1.GetOptions{})
+		if err != nil {
+			t1.Fatalf("Test case name %s: unexpected error %v", name, err)
+		}
+		if len(drive.GetFinalizers()) != 2 {
+			t1.Errorf("Test case name %s: expected the snapshot finalizer to be removed, got %v", name, drive.GetFinalizers())
+		}
+		if drive.Status.FreeCapacity != 3*1024*1024 || drive.Status.AllocatedCapacity != 1024*1024 {
+			t1.Errorf("Test case name %s: expected the capacity of the snapshot to be released, got free %d and allocated %d",
+				name, drive.Status.FreeCapacity, drive.Status.AllocatedCapacity)
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		drive           string
+		reflinkEnabled  bool
+		expectedReady   bool
+		expectedMessage bool
+	}{
+		{
+			name:           "reflink_enabled",
+			drive:          "drive-1",
+			reflinkEnabled: true,
+			expectedReady:  true,
+		},
+		{
+			name:            "reflink_disabled",
+			drive:           "drive-2",
+			reflinkEnabled:  false,
+			expectedMessage: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			ctx := context.TODO()
+			snapshot := newSnapshot("snapshot-"+tt.name, tt.drive)
+			objects := []runtime.Object{newDrive(tt.drive, snapshot.Name), snapshot}
+			copier := &fakeCopier{copied: map[string]string{}}
+			quota := &fakeQuota{limits: map[string]int64{}}
+			listener := &DirectCSISnapshotListener{
+				directcsiClient: fakedirect.NewSimpleClientset(objects...),
+				nodeID:          testNodeName,
+				isReflinkSupported: func(ctx context.Context, mountPoint string) (bool, error) {
+					return tt.reflinkEnabled, nil
+				},
+				copy:        copier.copy,
+				setQuota:    quota.setQuota,
+				removeQuota: quota.removeQuota,
+			}
+			sclient := listener.directcsiClient.DirectV1beta2().DirectCSISnapshots()
+
+			if err := listener.Add(ctx, snapshot); err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			updated, err := sclient.Get(ctx, snapshot.Name, metav1.GetOptions{})
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if updated.Status.ReadyToUse != tt.expectedReady {
+				t1.Errorf("Test case name %s: expected ready = %v, got %v", tt.name, tt.expectedReady, updated.Status.ReadyToUse)
+			}
+			if (updated.Status.Message != "") != tt.expectedMessage {
+				t1.Errorf("Test case name %s: unexpected message %q", tt.name, updated.Status.Message)
+			}
+			if !tt.reflinkEnabled {
+				if len(copier.copied) != 0 {
+					t1.Errorf("Test case name %s: expected no copy without reflink, got %v", tt.name, copier.copied)
+				}
+				checkDriveReleased(t1, listener, tt.name, tt.drive)
+				return
+			}
+
+			expectedPath := filepath.Join(mountPoint, tt.drive, snapshotDir, snapshot.Name)
+			if updated.Status.SnapshotPath != expectedPath {
+				t1.Errorf("Test case name %s: expected snapshot path %s, got %s", tt.name, expectedPath, updated.Status.SnapshotPath)
+			}
+			if src := copier.copied[expectedPath]; src != filepath.Join(mountPoint, tt.drive, "volume-1") {
+				t1.Errorf("Test case name %s: unexpected copy source %s", tt.name, src)
+			}
+			if limit := quota.limits[expectedPath]; limit != snapshot.Status.SizeBytes {
+				t1.Errorf("Test case name %s: expected quota limit %d, got %d", tt.name, snapshot.Status.SizeBytes, limit)
+			}
+
+			now := metav1.Now()
+			updated.SetDeletionTimestamp(&now)
+			if err := listener.Update(ctx, updated, updated); err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if _, err := os.Stat(expectedPath); !os.IsNotExist(err) {
+				t1.Errorf("Test case name %s: expected snapshot path to be removed, got %v", tt.name, err)
+			}
+			if _, found := quota.limits[expectedPath]; found {
+				t1.Errorf("Test case name %s: expected quota of the snapshot to be removed", tt.name)
+			}
+			deleted, err := sclient.Get(ctx, snapshot.Name, metav1.GetOptions{})
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if len(deleted.GetFinalizers()) != 0 {
+				t1.Errorf("Test case name %s: expected finalizers to be removed, got %v", tt.name, deleted.GetFinalizers())
+			}
+			checkDriveReleased(t1, listener, tt.name, tt.drive)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+// DiskByPathDir holds the udev links of the disks by the port they are attached to,
+// e.g. pci-0000:00:1f.2-ata-1, which is traced by the cabling of the node
+const DiskByPathDir = "/dev/disk/by-path"
+
+// readByPathPaths returns the by-path link of each device linked from the by-path directory
+func readByPathPaths(byPathDir string) map[string]string {
+	return readDiskLinks(byPathDir, preferByPathLink)
+}
+
+// preferByPathLink checks if the by-path link a is preferred over the link b of the same device.
+// Recent udev adds a link with the ATA port and the device number, e.g. ata-1.0 next to ata-1,
+// the shorter link is preferred.
+func preferByPathLink(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// setByPathPaths sets the by-path links of the devices and their partitions
+func setByPathPaths(devices []BlockDevice, byPathPaths map[string]string) {
+	for i := range devices {
+		if devices[i].DriveInfo != nil {
+			devices[i].ByPath = byPathPaths[devices[i].Devname]
+		}
+		for j := range devices[i].Partitions {
+			if devices[i].Partitions[j].DriveInfo != nil {
+				devices[i].Partitions[j].ByPath = byPathPaths[partitionDevname(devices[i].Devname, devices[i].Partitions[j].PartitionNum)]
+			}
+		}
+	}
+}
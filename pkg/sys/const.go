@@ -24,10 +24,14 @@ const (
 	HostDevRoot      = "/dev"
 	DefaultProcFS    = "/proc"
 	DirectCSIRoot    = "/var/lib/direct-csi"
-	MountRoot        = "/var/lib/direct-csi/mnt"
+	DefaultMountRoot = "/var/lib/direct-csi/mnt"
 	DirectCSIDevRoot = "/var/lib/direct-csi/devices"
 )
 
+// MountRoot is the directory under which the drives are mounted. It can be
+// changed for the nodes where the default directory is not writable.
+var MountRoot = DefaultMountRoot
+
 type FSType string
 
 const (
@@ -61,6 +65,28 @@ const (
 	MountOptionMSSynchronous             = "sync"
 )
 
+// MountPropagation is the Kubernetes mount propagation mode of a published volume
+type MountPropagation string
+
+const (
+	// MountPropagationNone keeps the default private propagation of the bind mount
+	MountPropagationNone MountPropagation = "None"
+	// MountPropagationHostToContainer makes the bind mount rslave
+	MountPropagationHostToContainer MountPropagation = "HostToContainer"
+	// MountPropagationBidirectional makes the bind mount rshared
+	MountPropagationBidirectional MountPropagation = "Bidirectional"
+)
+
+// FSGroupChangePolicy is the Kubernetes policy to change the group ownership of a staged volume to its fsGroup
+type FSGroupChangePolicy string
+
+const (
+	// FSGroupChangeOnRootMismatch changes the ownership only if the root of the volume does not match the fsGroup
+	FSGroupChangeOnRootMismatch FSGroupChangePolicy = "OnRootMismatch"
+	// FSGroupChangeAlways changes the ownership on every stage of the volume
+	FSGroupChangeAlways FSGroupChangePolicy = "Always"
+)
+
 // Unmount options
 type UnmountOption string
 
@@ -68,6 +94,8 @@ const (
 	UnmountOptionForce  UnmountOption = "force"
 	UnmountOptionDetach               = "detach"
 	UnmountOptionExpire               = "expire"
+	// UnmountOptionDetachOnBusy lazily detaches the mount if it stays busy after a few retries
+	UnmountOptionDetachOnBusy UnmountOption = "detach-on-busy"
 )
 
 var (
@@ -77,6 +105,7 @@ var (
 	ErrNotModernStandardMBR = errors.New("Not a Modern Standard MBR partition")
 	ErrNotAAPMBR            = errors.New("Not a AAP MBR partition")
 	ErrNotPartition         = errors.New("Not a partitioned volume")
+	ErrMountpointInUse      = errors.New("mountpoint is already in use by another device")
 )
 
 // filesystem constants
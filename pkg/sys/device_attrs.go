@@ -0,0 +1,223 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func readFirstLine(filename string, ignoreNotExist bool) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if ignoreNotExist && errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		return "", err
+	}
+	defer file.Close()
+	s, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(s), nil
+}
+
+type drive struct {
+	name        string // from "/sys/class/block"
+	major       int    // from "/sys/class/block/${name}/dev"
+	minor       int    // from "/sys/class/block/${name}/dev"
+	partition   int    // from "/sys/class/block/${name}/partition"
+	dmName      string // from "/sys/class/block/${name}/dm/name"
+	dmUUID      string // from "/sys/class/block/${name}/dm/uuid"
+	mdUUID      string // from "/sys/class/block/${name}/md/uuid"
+	rotational  bool   // from "/sys/class/block/${name}/queue/rotational"
+	ioScheduler string // from "/sys/class/block/${name}/queue/scheduler"
+	nrRequests  int64  // from "/sys/class/block/${name}/queue/nr_requests"
+	readAheadKB int64  // from "/sys/class/block/${name}/queue/read_ahead_kb"
+	removable   bool   // from "/sys/class/block/${name}/removable"
+	readOnly    bool   // from "/sys/class/block/${name}/ro"
+	wwid        string // from "/sys/class/block/${name}/wwid" or "/sys/class/block/${name}/device/wwid"
+	parent      string // computed
+	master      string // computed
+}
+
+// attrSpec declares a sysfs attribute of a block device and how it is stored in the drive
+type attrSpec struct {
+	// path of the attribute relative to the device directory
+	path string
+	// optional attributes may be missing, e.g. dm/name of the devices which are not device-mapper devices
+	optional bool
+	set      func(d *drive, value string) error
+}
+
+// driveAttrs are the attributes read for every block device in the discovery
+var driveAttrs = []attrSpec{
+	{
+		path: "dev",
+		set: func(d *drive, value string) (err error) {
+			d.major, d.minor, err = parseDevNumbers(value)
+			return err
+		},
+	},
+	{
+		path:     "partition",
+		optional: true,
+		set: func(d *drive, value string) (err error) {
+			d.partition, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		path:     "dm/name",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.dmName = value
+			return nil
+		},
+	},
+	{
+		path:     "dm/uuid",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.dmUUID = value
+			return nil
+		},
+	},
+	{
+		path:     "md/uuid",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.mdUUID = value
+			return nil
+		},
+	},
+	{
+		path:     "queue/rotational",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.rotational = value == "1"
+			return nil
+		},
+	},
+	{
+		path:     "queue/scheduler",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.ioScheduler = parseIOScheduler(value)
+			return nil
+		},
+	},
+	{
+		path:     "queue/nr_requests",
+		optional: true,
+		set: func(d *drive, value string) (err error) {
+			d.nrRequests, err = strconv.ParseInt(value, 10, 64)
+			return err
+		},
+	},
+	{
+		path:     "queue/read_ahead_kb",
+		optional: true,
+		set: func(d *drive, value string) (err error) {
+			d.readAheadKB, err = strconv.ParseInt(value, 10, 64)
+			return err
+		},
+	},
+	{
+		path:     "removable",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.removable = value == "1"
+			return nil
+		},
+	},
+	{
+		path:     "ro",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.readOnly = value == "1"
+			return nil
+		},
+	},
+	{
+		// NVMe namespaces
+		path:     "wwid",
+		optional: true,
+		set: func(d *drive, value string) error {
+			d.wwid = value
+			return nil
+		},
+	},
+	{
+		// SCSI disks
+		path:     "device/wwid",
+		optional: true,
+		set: func(d *drive, value string) error {
+			if d.wwid == "" {
+				d.wwid = value
+			}
+			return nil
+		},
+	},
+}
+
+// parseDevNumbers parses the "major:minor" device number
+func parseDevNumbers(value string) (major, minor int, err error) {
+	tokens := strings.SplitN(value, ":", 2)
+	if len(tokens) != 2 {
+		return 0, 0, fmt.Errorf("unknown format of %v", value)
+	}
+	if major, err = strconv.Atoi(tokens[0]); err != nil {
+		return 0, 0, err
+	}
+	minor, err = strconv.Atoi(tokens[1])
+	return major, minor, err
+}
+
+// parseIOScheduler returns the active scheduler of the "queue/scheduler" list, e.g. "mq-deadline" of "[mq-deadline] kyber none"
+func parseIOScheduler(value string) string {
+	for _, token := range strings.Fields(value) {
+		if strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]") {
+			return strings.Trim(token, "[]")
+		}
+	}
+	return ""
+}
+
+// readDeviceAttrs reads the declared attributes of the device from the sysfs block directory in one pass
+func readDeviceAttrs(sysfsBlockDir, name string, attrs []attrSpec) (*drive, error) {
+	d := &drive{name: name}
+	for _, attr := range attrs {
+		value, err := readFirstLine(filepath.Join(sysfsBlockDir, name, attr.path), attr.optional)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" && attr.optional {
+			continue
+		}
+		if err := attr.set(d, value); err != nil {
+			return nil, fmt.Errorf("invalid %s of %s: %v", attr.path, name, err)
+		}
+	}
+	return d, nil
+}
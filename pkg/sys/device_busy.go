@@ -0,0 +1,119 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// readHolders returns the devices stacked on the device, e.g. the device-mapper
+// targets and the MD arrays built on it, from the sysfs block directory
+func readHolders(sysfsBlockDir, devname string) ([]string, error) {
+	// every block device has a holders directory, a missing one means that the device is unknown
+	entries, err := ioutil.ReadDir(filepath.Join(sysfsBlockDir, devname, "holders"))
+	if err != nil {
+		return nil, err
+	}
+	holders := []string{}
+	for _, entry := range entries {
+		holders = append(holders, entry.Name())
+	}
+	return holders, nil
+}
+
+// findDeviceOpeners returns the processes other than self holding an open file of the device,
+// e.g. a database on the raw device. rdevOf returns the device number of a block device file.
+func findDeviceOpeners(procFS string, self int, major, minor uint32, rdevOf func(path string) (uint32, uint32, bool)) ([]string, error) {
+	pidDirs, err := filepath.Glob(filepath.Join(procFS, "[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	openers := []string{}
+	for _, pidDir := range pidDirs {
+		pid := filepath.Base(pidDir)
+		if pid == fmt.Sprint(self) {
+			continue
+		}
+		fds, err := ioutil.ReadDir(filepath.Join(pidDir, "fd"))
+		if err != nil {
+			// the process might have exited already
+			continue
+		}
+		for _, fd := range fds {
+			fdMajor, fdMinor, isBlock := rdevOf(filepath.Join(pidDir, "fd", fd.Name()))
+			if !isBlock || fdMajor != major || fdMinor != minor {
+				continue
+			}
+			opener := pid
+			if comm, err := ioutil.ReadFile(filepath.Join(pidDir, "comm")); err == nil {
+				opener = fmt.Sprintf("%s (%s)", pid, strings.TrimSpace(string(comm)))
+			}
+			openers = append(openers, opener)
+			break
+		}
+	}
+	return openers, nil
+}
+
+// readDeviceHolders resolves the kernel name of the device from its device numbers and returns its holders
+func readDeviceHolders(sysfsBlockDir, sysDevBlockDir string, major, minor uint32) (string, []string, error) {
+	devname, err := readKernelName(sysDevBlockDir, major, minor)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to resolve the device %d:%d: %v", major, minor, err)
+	}
+	holders, err := readHolders(sysfsBlockDir, devname)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read the holders of device %s: %v", devname, err)
+	}
+	return devname, holders, nil
+}
+
+// checkDeviceFree fails with EBUSY if the device is used outside direct-csi, i.e. if it has holders,
+// cannot be opened exclusively or is opened by another process. A busy device is never formatted.
+func checkDeviceFree(sysfsBlockDir, sysDevBlockDir, procFS, path string, major, minor uint32) error {
+	devname, holders, err := readDeviceHolders(sysfsBlockDir, sysDevBlockDir, major, minor)
+	if err != nil {
+		return err
+	}
+	if len(holders) > 0 {
+		return fmt.Errorf("device %s is held by %s: %w", devname, strings.Join(holders, ", "), syscall.EBUSY)
+	}
+
+	// the kernel refuses the exclusive open of a mounted device or of a device claimed by another exclusive opener
+	if err := openExclusive(path); err != nil {
+		if errors.Is(err, syscall.EBUSY) {
+			return fmt.Errorf("device %s is mounted or opened exclusively by another process: %w", devname, err)
+		}
+		return err
+	}
+
+	openers, err := findDeviceOpeners(procFS, os.Getpid(), major, minor, blockDeviceNumber)
+	if err != nil {
+		return err
+	}
+	if len(openers) > 0 {
+		return fmt.Errorf("device %s is opened by %s: %w", devname, strings.Join(openers, ", "), syscall.EBUSY)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type deviceProber func(ctx context.Context, loopBackOnly bool) ([]BlockDevice, error)
+
+// DeviceCache holds the last probed devices so that the subsystems asking for
+// the drive info within a short interval share a single sysfs walk
+type DeviceCache struct {
+	mutex        sync.Mutex
+	loopBackOnly bool
+	probe        deviceProber
+	devices      []BlockDevice
+	probedAt     time.Time
+}
+
+// Get returns the cached devices, the devices are probed again if the cache is
+// older than maxAge or has been invalidated
+func (c *DeviceCache) Get(ctx context.Context, maxAge time.Duration) ([]BlockDevice, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.probedAt.IsZero() || time.Since(c.probedAt) > maxAge {
+		devices, err := c.probe(ctx, c.loopBackOnly)
+		if err != nil {
+			return nil, err
+		}
+		c.devices = devices
+		c.probedAt = time.Now()
+	}
+
+	devices := make([]BlockDevice, len(c.devices))
+	copy(devices, c.devices)
+	return devices, nil
+}
+
+// Invalidate forces the next Get to probe the devices, this should be called
+// on the device change events
+func (c *DeviceCache) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.probedAt = time.Time{}
+}
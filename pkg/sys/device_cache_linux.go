@@ -0,0 +1,44 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"context"
+)
+
+// NewDeviceCache returns a device cache backed by FindDevices
+func NewDeviceCache(loopBackOnly bool) *DeviceCache {
+	return &DeviceCache{
+		loopBackOnly: loopBackOnly,
+		probe:        FindDevices,
+	}
+}
+
+// InvalidateOnDeviceEvents invalidates the cache on every uevent of a block device until ctx is done,
+// so that the hotplugged, removed and resized devices are probed again without waiting for the cache to expire
+func (c *DeviceCache) InvalidateOnDeviceEvents(ctx context.Context) error {
+	deviceCh, err := WatchBlockDevices(ctx)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range deviceCh {
+			c.Invalidate()
+		}
+	}()
+	return nil
+}
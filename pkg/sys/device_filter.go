@@ -0,0 +1,86 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMinDriveSize is the default size below which the devices are not managed
+const DefaultMinDriveSize = 1024 * 1024 * 1024
+
+// ramDiskMajor is the major number of the /dev/ram* block devices
+const ramDiskMajor = 1
+
+// DeviceFilter selects the block devices to be managed by the driver using
+// path glob patterns, e.g. "/dev/sd[b-z]" or "/dev/nvme*".
+type DeviceFilter struct {
+	// Include patterns; all devices are included if empty
+	Include []string
+	// Exclude patterns; applied after the include patterns
+	Exclude []string
+	// MinSize is the size in bytes below which the devices are dropped; no limit if zero
+	MinSize uint64
+}
+
+// Validate checks if the include and exclude patterns are well formed
+func (f DeviceFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid device pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match reports if a device is selected by the filter. A device is identified by
+// one or more paths, e.g. the partition and its parent disk, and it is selected
+// if any of the paths is included and none of them is excluded.
+func (f DeviceFilter) Match(paths ...string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, paths) {
+		return false
+	}
+	return !matchAny(f.Exclude, paths)
+}
+
+// MatchSize reports if a device of the given size is large enough to be managed
+func (f DeviceFilter) MatchSize(size uint64) bool {
+	return size >= f.MinSize
+}
+
+// IsRAMDisk reports if the device is a ramdisk or a compressed RAM (zram) device,
+// which are never managed as their contents do not survive a reboot
+func IsRAMDisk(path string, major uint32) bool {
+	name := filepath.Base(path)
+	return major == ramDiskMajor || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram")
+}
+
+func matchAny(patterns, paths []string) bool {
+	for _, pattern := range patterns {
+		for _, path := range paths {
+			if path == "" {
+				continue
+			}
+			if matched, _ := filepath.Match(pattern, path); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysDevBlock links the device numbers of the block devices to their sysfs directories
+const sysDevBlock = "/sys/dev/block"
+
+// readKernelName resolves the kernel name of the device from its device numbers, e.g. sdb1 for the
+// partition which is named sdb-part-1 by direct-csi
+func readKernelName(sysDevBlockDir string, major, minor uint32) (string, error) {
+	target, err := os.Readlink(filepath.Join(sysDevBlockDir, fmt.Sprintf("%d:%d", major, minor)))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(target), nil
+}
+
+// readMajorMinor reads the current major and minor numbers of the device from the sysfs block directory
+func readMajorMinor(sysfsBlockDir, devname string) (major, minor uint32, err error) {
+	value, err := ioutil.ReadFile(filepath.Join(sysfsBlockDir, devname, "dev"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tokens := strings.Split(strings.TrimSpace(string(value)), ":")
+	if len(tokens) != 2 {
+		return 0, 0, fmt.Errorf("invalid device number %q of %s", strings.TrimSpace(string(value)), devname)
+	}
+	majorNum, err := strconv.ParseUint(tokens[0], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	minorNum, err := strconv.ParseUint(tokens[1], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint32(majorNum), uint32(minorNum), nil
+}
@@ -0,0 +1,92 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// diskstats always accounts the transferred data in 512 byte sectors
+// irrespective of the logical block size of the device
+const diskStatsSectorSize = 512
+
+// DiskStats - I/O statistics of a block device as reported by /proc/diskstats
+type DiskStats struct {
+	Major      uint32
+	Minor      uint32
+	Name       string
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// ReadDiskStats - reads the I/O statistics of all the block devices on the node
+func ReadDiskStats() ([]DiskStats, error) {
+	f, err := os.Open(filepath.Join(DefaultProcFS, "diskstats"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseDiskStats(f)
+}
+
+func parseDiskStats(r io.Reader) ([]DiskStats, error) {
+	stats := []DiskStats{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// major minor name reads merged sectors-read ms writes merged sectors-written ...
+		if len(fields) < 10 {
+			return nil, fmt.Errorf("invalid format of diskstats: %s", scanner.Text())
+		}
+		major, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the major number in diskstats: %v", err)
+		}
+		minor, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the minor number in diskstats: %v", err)
+		}
+		sectorsRead, err := strconv.ParseUint(fields[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the sectors read in diskstats: %v", err)
+		}
+		sectorsWritten, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the sectors written in diskstats: %v", err)
+		}
+		stats = append(stats, DiskStats{
+			Major:      uint32(major),
+			Minor:      uint32(minor),
+			Name:       fields[2],
+			ReadBytes:  sectorsRead * diskStatsSectorSize,
+			WriteBytes: sectorsWritten * diskStatsSectorSize,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -17,114 +17,84 @@
 package sys
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
 
 	"golang.org/x/sys/unix"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 
 	"github.com/minio/direct-csi/pkg/sys/loopback"
 	"github.com/minio/direct-csi/pkg/sys/smart"
 )
 
-func readFirstLine(filename string, ignoreNotExist bool) (string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		if ignoreNotExist && errors.Is(err, os.ErrNotExist) {
-			err = nil
-		}
-		return "", err
-	}
-	defer file.Close()
-	s, err := bufio.NewReader(file).ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(s), nil
-}
+// dmCryptUUIDPrefix prefixes the device-mapper UUID of the dm-crypt mappings
+const dmCryptUUIDPrefix = "CRYPT-"
 
-type drive struct {
-	name      string // from "/sys/class/block"
-	major     int    // from "/sys/class/block/${name}/dev"
-	minor     int    // from "/sys/class/block/${name}/dev"
-	partition int    // from "/sys/class/block/${name}/partition"
-	dmName    string // from "/sys/class/block/${name}/dm/name"
-	dmUUID    string // from "/sys/class/block/${name}/dm/uuid"
-	parent    string // computed
-	master    string // computed
+// isCryptMember checks if the device backs an opened dm-crypt mapping
+func isCryptMember(driveMap map[string]*drive, name string) bool {
+	d, found := driveMap[name]
+	if !found || d.master == "" {
+		return false
+	}
+	master, found := driveMap[d.master]
+	return found && strings.HasPrefix(master.dmUUID, dmCryptUUIDPrefix)
 }
 
-func getDevMajorMinor(name string) (major int, minor int, err error) {
-	var dev string
-	if dev, err = readFirstLine("/sys/class/block/"+name+"/dev", false); err != nil {
-		return
+// isRAIDMember checks if the device is a member of an assembled MD array
+func isRAIDMember(driveMap map[string]*drive, name string) bool {
+	d, found := driveMap[name]
+	if !found || d.master == "" {
+		return false
 	}
+	master, found := driveMap[d.master]
+	return found && master.mdUUID != ""
+}
 
-	tokens := strings.SplitN(dev, ":", 2)
-	if len(tokens) != 2 {
-		err = fmt.Errorf("unknown format of %v", dev)
-		return
+// getRAIDMembers returns the host paths of the members of the assembled MD array
+func getRAIDMembers(driveMap map[string]*drive, name string) []string {
+	if d, found := driveMap[name]; !found || d.mdUUID == "" {
+		return nil
 	}
-
-	if major, err = strconv.Atoi(tokens[0]); err != nil {
-		return
+	members := []string{}
+	for memberName, d := range driveMap {
+		if d.master == name {
+			members = append(members, getRootBlockFile(memberName))
+		}
 	}
-	minor, err = strconv.Atoi(tokens[1])
-	return
+	sort.Strings(members)
+	return members
 }
 
-func getPartition(name string) (int, error) {
-	s, err := readFirstLine("/sys/class/block/"+name+"/partition", true)
+// probeMDSuperblock checks if the device region has an MD superblock of an array which is not assembled
+func (b *BlockDevice) probeMDSuperblock(offset, size uint64) (bool, error) {
+	file, err := os.Open(b.HostDrivePath())
 	if err != nil {
-		return 0, err
-	}
-	if s == "" {
-		return 0, nil
+		return false, err
 	}
-	return strconv.Atoi(s)
-}
-
-func getDMName(name string) (string, error) {
-	return readFirstLine("/sys/class/block/"+name+"/dm/name", true)
+	defer file.Close()
+	return hasMDSuperblock(file, int64(offset), int64(size))
 }
 
-func getDMUUID(name string) (string, error) {
-	return readFirstLine("/sys/class/block/"+name+"/dm/uuid", true)
+// probeLUKSHeader checks if the device region starts with the LUKS header of a closed or opened container
+func (b *BlockDevice) probeLUKSHeader(offset uint64) (bool, error) {
+	file, err := os.Open(b.HostDrivePath())
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	return hasLUKSHeader(file, int64(offset))
 }
 
 func getDrive(name string) (*drive, error) {
-	major, minor, err := getDevMajorMinor(name)
-	if err != nil {
-		return nil, err
-	}
-	partition, err := getPartition(name)
-	if err != nil {
-		return nil, err
-	}
-	dmName, err := getDMName(name)
-	if err != nil {
-		return nil, err
-	}
-	dmUUID, err := getDMUUID(name)
-	if err != nil {
-		return nil, err
-	}
-	return &drive{
-		name:      name,
-		major:     major,
-		minor:     minor,
-		partition: partition,
-		dmName:    dmName,
-		dmUUID:    dmUUID,
-	}, nil
+	return readDeviceAttrs(sysClassBlock, name, driveAttrs)
 }
 
 func getParttiions(name string) ([]string, error) {
@@ -179,7 +149,12 @@ func readSysClassBlock() ([]string, error) {
 	return file.Readdirnames(-1)
 }
 
-func probeDrives() (map[string]*drive, error) {
+// isDeviceRemoved checks if the sysfs entries of the device vanished during the read
+func isDeviceRemoved(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ENODEV)
+}
+
+func probeDrives(ctx context.Context) (map[string]*drive, error) {
 	names, err := readSysClassBlock()
 	if err != nil {
 		return nil, err
@@ -187,9 +162,18 @@ func probeDrives() (map[string]*drive, error) {
 
 	driveMap := map[string]*drive{}
 	for _, name := range names {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		drive, err := getDrive(name)
 		if err != nil {
-			return nil, err
+			// a device torn down meanwhile must not hide the others, any other error fails
+			// the probe as the holders of the remaining devices would be unknown
+			if !isDeviceRemoved(err) {
+				return nil, err
+			}
+			klog.V(3).Infof("Skipping removed block device %s: %v", name, err)
+			continue
 		}
 		driveMap[name] = drive
 	}
@@ -199,9 +183,16 @@ func probeDrives() (map[string]*drive, error) {
 	}
 
 	for _, name := range names {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		partitions, err := getParttiions(name)
 		if err != nil {
-			return nil, err
+			if !isDeviceRemoved(err) {
+				return nil, err
+			}
+			klog.V(3).Infof("Skipping removed block device %s: %v", name, err)
+			continue
 		}
 		for _, partition := range partitions {
 			if _, found := driveMap[partition]; found {
@@ -223,8 +214,79 @@ func probeDrives() (map[string]*drive, error) {
 	return driveMap, nil
 }
 
+// findSystemDisks returns the names of the disks backing the root and boot filesystems or the active swap
+func findSystemDisks(driveMap map[string]*drive) (map[string]bool, error) {
+	mounts, err := ProbeMountInfo()
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := os.Open(filepath.Join(DefaultProcFS, "swaps"))
+	if err != nil {
+		return nil, err
+	}
+	defer swaps.Close()
+	swapDevices, err := parseSwaps(swaps)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, mount := range mounts {
+		if !isSystemMountpoint(mount.Mountpoint) {
+			continue
+		}
+		for name, d := range driveMap {
+			if d.major == int(mount.Major) && d.minor == int(mount.Minor) {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, device := range swapDevices {
+		if resolved, err := filepath.EvalSymlinks(device); err == nil {
+			device = resolved
+		}
+		device = filepath.Base(device)
+		for name, d := range driveMap {
+			if name == device || d.dmName == device {
+				names = append(names, name)
+			}
+		}
+	}
+
+	parents := map[string]string{}
+	slaves := map[string][]string{}
+	for name, d := range driveMap {
+		parents[name] = d.parent
+		if d.master != "" {
+			slaves[d.master] = append(slaves[d.master], name)
+		}
+	}
+	return collectDisks(names, parents, slaves), nil
+}
+
+// walkSysfs walks the sysfs tree under head. The paths vanished or turned unreadable during
+// the walk, e.g. of a device being torn down, are logged and skipped to still find the other
+// devices, only the errors of head fail the walk.
+func walkSysfs(head string, walkFn func(path string, info os.FileInfo) error) error {
+	return filepath.Walk(head, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == head {
+				return err
+			}
+			klog.V(3).Infof("Skipping %s: %v", path, err)
+			return nil
+		}
+		return walkFn(path, info)
+	})
+}
+
 func FindDevices(ctx context.Context, loopBackOnly bool) ([]BlockDevice, error) {
-	driveMap, err := probeDrives()
+	driveMap, err := probeDrives(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	systemDisks, err := findSystemDisks(driveMap)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +312,7 @@ func FindDevices(ctx context.Context, loopBackOnly bool) ([]BlockDevice, error)
 		}
 	}
 
-	return drives, filepath.Walk(head, func(path string, info os.FileInfo, err error) error {
+	err = walkSysfs(head, func(path string, info os.FileInfo) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
@@ -292,13 +354,31 @@ func FindDevices(ctx context.Context, loopBackOnly bool) ([]BlockDevice, error)
 		if subsystem != "block" {
 			return nil
 		}
+		if _, found := driveMap[drive.Devname]; !found {
+			// added after or skipped by probeDrives, it is found by the next discovery
+			klog.V(3).Infof("Skipping block device %s missing in %s", drive.Devname, sysClassBlock)
+			return nil
+		}
 		if err := drive.probeBlockDev(ctx, driveMap); err != nil {
+			// abort the walk instead of recording a half probed device
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			klog.Errorf("Error while probing block device: %v", err)
 		}
+		drive.IsSystemDisk = systemDisks[drive.Devname]
 
 		drives = append(drives, *drive)
 		return nil
 	})
+	if err != nil {
+		return drives, err
+	}
+
+	byIDTargets := setByIDPaths(drives, readByIDPaths(DiskByIDDir))
+	setByPathPaths(drives, readByPathPaths(DiskByPathDir))
+	setEnclosureSlots(drives, sysClassBlock, readEnclosureSlots(sysClassEnclosure))
+	return dedupByWWID(drives, byIDTargets), nil
 }
 
 func (b *BlockDevice) GetPartitions() []Partition {
@@ -342,6 +422,10 @@ func (b *BlockDevice) probeBlockDev(ctx context.Context, driveMap map[string]*dr
 	b.NumBlocks = numBlocks
 	b.EndBlock = numBlocks
 
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
 	var parts []Partition
 	parts, err = b.probePartitions(ctx)
 	if err != nil {
@@ -354,6 +438,16 @@ func (b *BlockDevice) probeBlockDev(ctx context.Context, driveMap map[string]*dr
 	b.DMUUID = driveMap[b.Devname].dmUUID
 	b.Parent = driveMap[b.Devname].parent
 	b.Master = driveMap[b.Devname].master
+	b.Rotational = driveMap[b.Devname].rotational
+	b.IOScheduler = driveMap[b.Devname].ioScheduler
+	b.NrRequests = driveMap[b.Devname].nrRequests
+	b.ReadAheadKB = driveMap[b.Devname].readAheadKB
+	b.Removable = driveMap[b.Devname].removable
+	b.ReadOnly = driveMap[b.Devname].readOnly
+	b.WWID = driveMap[b.Devname].wwid
+	b.IsCryptMember = isCryptMember(driveMap, b.Devname)
+	b.IsRAIDMember = isRAIDMember(driveMap, b.Devname)
+	b.RAIDMembers = getRAIDMembers(driveMap, b.Devname)
 	for i := range parts {
 		for name, drive := range driveMap {
 			if strings.HasPrefix(name, b.Devname) && drive.parent == b.Devname && drive.partition == int(parts[i].PartitionNum) {
@@ -361,14 +455,24 @@ func (b *BlockDevice) probeBlockDev(ctx context.Context, driveMap map[string]*dr
 				parts[i].DMUUID = drive.dmUUID
 				parts[i].Parent = drive.parent
 				parts[i].Master = drive.master
+				parts[i].IsCryptMember = isCryptMember(driveMap, name)
+				parts[i].IsRAIDMember = isRAIDMember(driveMap, name)
 			}
 		}
 	}
 
 	// Get the block device serial number
 	serialNumber := b.getSerialNumber()
+	nvmeInfo := b.getNVMeInfo()
 
 	if len(parts) == 0 {
+		for _, drive := range driveMap {
+			if drive.parent == b.Devname {
+				b.HasKernelPartitions = true
+				break
+			}
+		}
+
 		offsetBlocks := uint64(0)
 		var fsInfo *FSInfo
 		fsInfo, err = b.probeFS(offsetBlocks)
@@ -389,17 +493,33 @@ func (b *BlockDevice) probeBlockDev(ctx context.Context, driveMap map[string]*dr
 				return err
 			}
 		}
+		if fsInfo.FSType == "" && !b.IsCryptMember {
+			if b.IsCryptMember, err = b.probeLUKSHeader(0); err != nil {
+				return err
+			}
+		}
+		// a stale MD superblock left behind a newer filesystem does not make the drive a member
+		if fsInfo.FSType == "" && !b.IsRAIDMember && len(b.RAIDMembers) == 0 {
+			if b.IsRAIDMember, err = b.probeMDSuperblock(0, b.TotalCapacity); err != nil {
+				return err
+			}
+		}
 		var mounts []MountInfo
 		mounts, err = b.probeMountInfo(b.DriveInfo.Major, b.DriveInfo.Minor, driveMap)
 		if err != nil {
 			return err
 		}
 		fsInfo.Mounts = append(fsInfo.Mounts, mounts...)
+		updateCapacityFromMounts(fsInfo)
 		b.FSInfo = fsInfo
 		b.SerialNumber = serialNumber
+		b.setNVMeInfo(nvmeInfo)
 		return nil
 	}
 	for _, p := range parts {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
 		offsetBlocks := p.StartBlock
 		var fsInfo *FSInfo
 		fsInfo, err = b.probeFS(offsetBlocks)
@@ -423,19 +543,67 @@ func (b *BlockDevice) probeBlockDev(ctx context.Context, driveMap map[string]*dr
 			}
 		}
 
+		if fsInfo.FSType == "" && !p.IsCryptMember {
+			if p.IsCryptMember, err = b.probeLUKSHeader(offsetBlocks * b.LogicalBlockSize); err != nil {
+				return err
+			}
+		}
+
+		if fsInfo.FSType == "" && !p.IsRAIDMember {
+			if p.IsRAIDMember, err = b.probeMDSuperblock(offsetBlocks*b.LogicalBlockSize, p.TotalCapacity); err != nil {
+				return err
+			}
+		}
+
 		var mounts []MountInfo
 		mounts, err = b.probeMountInfo(p.DriveInfo.Major, p.DriveInfo.Minor, driveMap)
 		if err != nil {
 			return err
 		}
 		fsInfo.Mounts = append(fsInfo.Mounts, mounts...)
+		updateCapacityFromMounts(fsInfo)
 		p.FSInfo = fsInfo
 		p.SerialNumber = serialNumber
+		p.Rotational = b.Rotational
+		p.IOScheduler = b.IOScheduler
+		p.NrRequests = b.NrRequests
+		p.ReadAheadKB = b.ReadAheadKB
+		p.Removable = b.Removable
+		p.ReadOnly = b.ReadOnly
+		p.setNVMeInfo(nvmeInfo)
 		b.Partitions = append(b.Partitions, p)
 	}
 	return nil
 }
 
+// updateCapacityFromMounts sets the free capacity of a mounted filesystem from statfs as
+// the superblock counters are only synced to the disk when the filesystem is unmounted.
+// The total capacity is kept from the probe, so that it does not change with the mount
+// state, only the used capacity is taken from statfs. The mountpoints are of the host,
+// they are resolved under the root of pid 1.
+func updateCapacityFromMounts(fsInfo *FSInfo) {
+	if fsInfo.FSType == "" {
+		return
+	}
+	for _, mount := range fsInfo.Mounts {
+		totalCapacity, freeCapacity, err := getCapacityFromStatfs(mount.Mountpoint)
+		if err != nil {
+			klog.V(5).Infof("unable to statfs %s: %v", mount.Mountpoint, err)
+			continue
+		}
+		fsInfo.FreeCapacity = freeCapacityOf(fsInfo.TotalCapacity, totalCapacity-freeCapacity)
+		return
+	}
+}
+
+// freeCapacityOf returns the free capacity of the total capacity with the used capacity
+func freeCapacityOf(totalCapacity, usedCapacity uint64) uint64 {
+	if usedCapacity >= totalCapacity {
+		return 0
+	}
+	return totalCapacity - usedCapacity
+}
+
 func subsystem(path string) (string, error) {
 	dir := filepath.Dir(path)
 	link, err := os.Readlink(filepath.Join(dir, "subsystem"))
@@ -534,6 +702,22 @@ func (b *BlockDevice) getSerialNumber() string {
 	return sn
 }
 
+func (b *BlockDevice) getNVMeInfo() NVMeInfo {
+	nvmeInfo, err := readNVMeInfo(sysClassBlock, b.Devname)
+	if err != nil {
+		klog.V(4).Infof("Cannot read NVMe attributes for device: %v. Error: %v", b.Devname, err)
+	}
+	return nvmeInfo
+}
+
+func (d *DriveInfo) setNVMeInfo(nvmeInfo NVMeInfo) {
+	d.Model = nvmeInfo.Model
+	d.FirmwareRevision = nvmeInfo.FirmwareRevision
+	d.ControllerSerial = nvmeInfo.ControllerSerial
+	d.NamespaceID = nvmeInfo.NamespaceID
+	d.SharedNamespace = nvmeInfo.SharedNamespace
+}
+
 func (b *BlockDevice) getTotalCapacity() (uint64, error) {
 	devFile, err := os.OpenFile(b.HostDrivePath(), os.O_RDONLY, os.ModeDevice)
 	if err != nil {
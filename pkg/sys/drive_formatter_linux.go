@@ -18,17 +18,38 @@ package sys
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
 
-	"k8s.io/klog"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
+
+	"k8s.io/klog/v2"
 )
 
 // formatDrive - Idempotent function to format a DirectCSIDrive
-func formatDrive(ctx context.Context, uuid, path string, force bool) error {
-	output, err := Format(ctx, path, string(FSTypeXFS), []string{"-i", "maxpct=50"}, force)
+func formatDrive(ctx context.Context, uuid, path, label string, blockSize int64, mkfsOptions []string, force bool) error {
+	// the page size differs between the nodes, the kernel cannot mount a block size larger than its own
+	if pageSize := int64(os.Getpagesize()); blockSize > pageSize {
+		return fmt.Errorf("invalid block size %d; must not be larger than the page size %d of the node", blockSize, pageSize)
+	}
+	// the requested options are validated against the allow-list again, they reach the mkfs.xfs command line
+	mkfsArgs, err := xfs.MkfsArgs(blockSize, mkfsOptions)
+	if err != nil {
+		return err
+	}
+	options := append([]string{"-i", "maxpct=50"}, mkfsArgs...)
+	if label != "" {
+		options = append(options, "-L", label)
+	}
+	output, err := Format(ctx, path, string(FSTypeXFS), options, force)
 	if err != nil {
 		klog.Errorf("failed to format drive: %s", output)
-		return fmt.Errorf("error while formatting: %v output: %s", err, output)
+		return fmt.Errorf("error while formatting: %w output: %s", commandError(err, output), output)
 	}
 	if uuid != "" {
 		output, err = SetXFSUUID(ctx, uuid, path)
@@ -40,15 +61,114 @@ func formatDrive(ctx context.Context, uuid, path string, force bool) error {
 	return nil
 }
 
+// wipeDrive - Idempotent function to erase the filesystem signatures of a DirectCSIDrive
+func wipeDrive(ctx context.Context, path string) error {
+	output, err := Wipe(ctx, path)
+	if err != nil {
+		klog.Errorf("failed to wipe drive: %s", output)
+		return fmt.Errorf("error while wiping: %v output: %s", err, output)
+	}
+	return nil
+}
+
+// commandError wraps the errno of a busy or read-only device into the error of a command,
+// mkfs.xfs and xfs_repair only report it in their output
+func commandError(err error, output string) error {
+	output = strings.ToLower(output)
+	for _, errno := range []syscall.Errno{syscall.EBUSY, syscall.EROFS} {
+		if strings.Contains(output, errno.Error()) {
+			return fmt.Errorf("%v: %w", err, errno)
+		}
+	}
+	return err
+}
+
+// exit statuses of xfs_repair
+const (
+	xfsRepairCorrupted = 1
+	xfsRepairDirtyLog  = 2
+)
+
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// replayLog - replays the log of the xfs filesystem of a DirectCSIDrive by mounting and unmounting it,
+// xfs_repair reports the dirty log of an unclean shutdown as corruption and refuses to repair it
+func replayLog(ctx context.Context, path string) error {
+	target, err := ioutil.TempDir("", "direct-csi-replay-")
+	if err != nil {
+		return err
+	}
+
+	klog.V(3).Infof("replaying the filesystem log of drive %s", path)
+	if err := SafeMount(ctx, path, target, string(FSTypeXFS), []MountOption{}, []string{quotaOption}); err != nil {
+		// a log which cannot be replayed is reported by xfs_repair
+		klog.Errorf("failed to replay the filesystem log of drive %s: %v", path, err)
+		return os.Remove(target)
+	}
+	// xfs_repair must never run on the mounted filesystem
+	if err := SafeUnmount(target, []UnmountOption{}); err != nil {
+		return fmt.Errorf("unable to unmount drive %s from %s after replaying its log: %v", path, target, err)
+	}
+	return os.Remove(target)
+}
+
+// repairDrive - checks the xfs filesystem of a DirectCSIDrive for errors and repairs them if fix is set,
+// it returns true if errors were found
+func repairDrive(ctx context.Context, path string, fix bool) (bool, error) {
+	// the check only reads the drive, the log is replayed only to repair it
+	if fix {
+		if err := replayLog(ctx, path); err != nil {
+			return false, err
+		}
+	}
+	output, err := Repair(ctx, path, true)
+	if err == nil {
+		return false, nil
+	}
+	if exitCode(err) != xfsRepairCorrupted {
+		klog.Errorf("failed to check drive: %s", output)
+		return false, fmt.Errorf("error while checking the filesystem: %w output: %s", commandError(err, output), output)
+	}
+	if !fix {
+		klog.Errorf("filesystem errors found on drive %s: %s", path, output)
+		return true, nil
+	}
+	output, err = Repair(ctx, path, false)
+	if err != nil {
+		klog.Errorf("failed to repair drive: %s", output)
+		if exitCode(err) == xfsRepairDirtyLog {
+			return true, fmt.Errorf("the filesystem log must be replayed by mounting the drive before repairing it, output: %s", output)
+		}
+		return true, fmt.Errorf("error while repairing: %w output: %s", commandError(err, output), output)
+	}
+	return true, nil
+}
+
 type DriveFormatter interface {
-	FormatDrive(ctx context.Context, uuid, path string, force bool) error
+	FormatDrive(ctx context.Context, uuid, path, label string, blockSize int64, mkfsOptions []string, force bool) error
+	WipeDrive(ctx context.Context, path string) error
+	RepairDrive(ctx context.Context, path string, fix bool) (bool, error)
 	MakeBlockFile(path string, major, minor uint32) error
 }
 
 type DefaultDriveFormatter struct{}
 
-func (c *DefaultDriveFormatter) FormatDrive(ctx context.Context, uuid, path string, force bool) error {
-	return formatDrive(ctx, uuid, path, force)
+func (c *DefaultDriveFormatter) FormatDrive(ctx context.Context, uuid, path, label string, blockSize int64, mkfsOptions []string, force bool) error {
+	return formatDrive(ctx, uuid, path, label, blockSize, mkfsOptions, force)
+}
+
+func (c *DefaultDriveFormatter) WipeDrive(ctx context.Context, path string) error {
+	return wipeDrive(ctx, path)
+}
+
+func (c *DefaultDriveFormatter) RepairDrive(ctx context.Context, path string, fix bool) (bool, error) {
+	return repairDrive(ctx, path, fix)
 }
 
 func (c *DefaultDriveFormatter) MakeBlockFile(path string, major, minor uint32) error {
@@ -23,15 +23,25 @@ import (
 )
 
 type DriveFormatter interface {
-	FormatDrive(ctx context.Context, uuid, path string, force bool) error
+	FormatDrive(ctx context.Context, uuid, path, label string, blockSize int64, mkfsOptions []string, force bool) error
+	WipeDrive(ctx context.Context, path string) error
+	RepairDrive(ctx context.Context, path string, fix bool) (bool, error)
 }
 
 type DefaultDriveFormatter struct{}
 
-func (c *DefaultDriveFormatter) FormatDrive(ctx context.Context, uuid, path string, force bool) error {
+func (c *DefaultDriveFormatter) FormatDrive(ctx context.Context, uuid, path, label string, blockSize int64, mkfsOptions []string, force bool) error {
 	return nil
 }
 
+func (c *DefaultDriveFormatter) WipeDrive(ctx context.Context, path string) error {
+	return nil
+}
+
+func (c *DefaultDriveFormatter) RepairDrive(ctx context.Context, path string, fix bool) (bool, error) {
+	return false, nil
+}
+
 func (c *DefaultDriveFormatter) MakeBlockFile(path string, major, minor uint32) error {
 	return nil
 }
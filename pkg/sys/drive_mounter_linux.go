@@ -17,9 +17,10 @@
 package sys
 
 import (
+	"context"
 	"os"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -27,14 +28,27 @@ const (
 )
 
 // mountDrive - Idempotent function to mount a DirectCSIDrive
-func mountDrive(source, target string, mountOpts []string) error {
+func mountDrive(ctx context.Context, source, target string, mountOpts []string) error {
+	major, minor, err := GetMajorMinor(source)
+	if err != nil {
+		return err
+	}
+	mounts, err := ProbeMountInfo()
+	if err != nil {
+		return err
+	}
+	// Refuse to stack mounts on a mountpoint held by another device
+	if err := CheckMountpointFree(mounts, target, major, minor); err != nil {
+		return err
+	}
+
 	// Since pods will be consuming this target, be permissive
 	if err := os.MkdirAll(target, 0777); err != nil {
 		return err
 	}
 
 	klog.V(3).Infof("mounting drive %s at %s", source, target)
-	return SafeMount(source, target, string(FSTypeXFS), func(opts []string) []MountOption {
+	return SafeMount(ctx, source, target, string(FSTypeXFS), func(opts []string) []MountOption {
 		newOpts := []MountOption{}
 		for _, opt := range opts {
 			newOpts = append(newOpts, MountOption(opt))
@@ -61,14 +75,14 @@ func unmountDrive(path string) error {
 }
 
 type DriveMounter interface {
-	MountDrive(source, target string, mountOpts []string) error
+	MountDrive(ctx context.Context, source, target string, mountOpts []string) error
 	UnmountDrive(path string) error
 }
 
 type DefaultDriveMounter struct{}
 
-func (c *DefaultDriveMounter) MountDrive(source, target string, mountOpts []string) error {
-	return mountDrive(source, target, mountOpts)
+func (c *DefaultDriveMounter) MountDrive(ctx context.Context, source, target string, mountOpts []string) error {
+	return mountDrive(ctx, source, target, mountOpts)
 }
 
 func (c *DefaultDriveMounter) UnmountDrive(path string) error {
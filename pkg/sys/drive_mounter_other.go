@@ -18,14 +18,18 @@
 
 package sys
 
+import (
+	"context"
+)
+
 type DriveMounter interface {
-	MountDrive(source, target string, mountOpts []string) error
+	MountDrive(ctx context.Context, source, target string, mountOpts []string) error
 	UnmountDrive(path string) error
 }
 
 type DefaultDriveMounter struct{}
 
-func (c *DefaultDriveMounter) MountDrive(source, target string, mountOpts []string) error {
+func (c *DefaultDriveMounter) MountDrive(ctx context.Context, source, target string, mountOpts []string) error {
 	return nil
 }
 
@@ -17,9 +17,14 @@
 package sys
 
 import (
+	"path/filepath"
 	"syscall"
 )
 
+// hostRootPath is the root of the mount namespace of the host, the mountpoints read from the
+// mountinfo of pid 1 are resolved under it as they may not be visible in the namespace of the driver
+var hostRootPath = filepath.Join(DefaultProcFS, "1", "root")
+
 func getFreeCapacityFromStatfs(path string) (freeCapacity int64, err error) {
 	stat := &syscall.Statfs_t{}
 	err = syscall.Statfs(path, stat)
@@ -30,8 +35,29 @@ func getFreeCapacityFromStatfs(path string) (freeCapacity int64, err error) {
 	return
 }
 
+// getCapacityFromStatfs returns the total and free capacity of the filesystem mounted at mountpoint on the host
+func getCapacityFromStatfs(mountpoint string) (totalCapacity, freeCapacity uint64, err error) {
+	stat := &syscall.Statfs_t{}
+	if err = syscall.Statfs(filepath.Join(hostRootPath, mountpoint), stat); err != nil {
+		return
+	}
+	return uint64(stat.Frsize) * stat.Blocks, uint64(stat.Frsize) * stat.Bavail, nil
+}
+
+// GetInodeStats returns the total and free inodes of the filesystem at path,
+// limited by the project quota of the directory if any
+func GetInodeStats(path string) (total, free int64, err error) {
+	stat := &syscall.Statfs_t{}
+	if err = syscall.Statfs(path, stat); err != nil {
+		return
+	}
+	return int64(stat.Files), int64(stat.Ffree), nil
+}
+
 type DriveStatter interface {
 	GetFreeCapacityFromStatfs(path string) (freeCapacity int64, err error)
+	GetMajorMinor(devName string) (major, minor uint32, err error)
+	CheckDeviceFree(path string, major, minor uint32) error
 }
 
 type DefaultDriveStatter struct{}
@@ -39,3 +65,12 @@ type DefaultDriveStatter struct{}
 func (c *DefaultDriveStatter) GetFreeCapacityFromStatfs(path string) (int64, error) {
 	return getFreeCapacityFromStatfs(path)
 }
+
+func (c *DefaultDriveStatter) GetMajorMinor(devName string) (uint32, uint32, error) {
+	return readMajorMinor(sysClassBlock, devName)
+}
+
+// CheckDeviceFree fails with EBUSY if the device at path is used outside direct-csi
+func (c *DefaultDriveStatter) CheckDeviceFree(path string, major, minor uint32) error {
+	return checkDeviceFree(sysClassBlock, sysDevBlock, DefaultProcFS, path, major, minor)
+}
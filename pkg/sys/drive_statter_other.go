@@ -18,8 +18,19 @@
 
 package sys
 
+import (
+	"errors"
+)
+
+// GetInodeStats returns the total and free inodes of the filesystem at path
+func GetInodeStats(path string) (total, free int64, err error) {
+	return 0, 0, errors.New("reading inode stats is not supported on this platform")
+}
+
 type DriveStatter interface {
 	GetFreeCapacityFromStatfs(path string) (freeCapacity int64, err error)
+	GetMajorMinor(devName string) (major, minor uint32, err error)
+	CheckDeviceFree(path string, major, minor uint32) error
 }
 
 type DefaultDriveStatter struct{}
@@ -27,3 +38,11 @@ type DefaultDriveStatter struct{}
 func (c *DefaultDriveStatter) GetFreeCapacityFromStatfs(path string) (int64, error) {
 	return 0, nil
 }
+
+func (c *DefaultDriveStatter) GetMajorMinor(devName string) (uint32, uint32, error) {
+	return 0, 0, errors.New("reading device numbers is not supported on this platform")
+}
+
+func (c *DefaultDriveStatter) CheckDeviceFree(path string, major, minor uint32) error {
+	return errors.New("checking the users of devices is not supported on this platform")
+}
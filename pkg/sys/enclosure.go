@@ -0,0 +1,102 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// sysClassEnclosure holds the SCSI enclosure services (SES) enclosures and their components
+const sysClassEnclosure = "/sys/class/enclosure"
+
+// EnclosureSlot is the bay of an enclosure holding a disk
+type EnclosureSlot struct {
+	// Enclosure is the SCSI address of the enclosure, e.g. 2:0:28:0
+	Enclosure string
+	// Slot is the slot number of the bay, or the name of the component if the slot number is not reported
+	Slot string
+}
+
+// readEnclosureSlots returns the bay of each disk populated in the enclosures, keyed by its resolved
+// SCSI device directory. The components of an enclosure link their disk by their device link.
+func readEnclosureSlots(enclosureDir string) map[string]EnclosureSlot {
+	slots := map[string]EnclosureSlot{}
+	enclosures, err := ioutil.ReadDir(enclosureDir)
+	if err != nil {
+		klog.V(5).Infof("unable to read %s: %v", enclosureDir, err)
+		return slots
+	}
+	for _, enclosure := range enclosures {
+		components, err := ioutil.ReadDir(filepath.Join(enclosureDir, enclosure.Name()))
+		if err != nil {
+			klog.V(5).Infof("unable to read enclosure %s: %v", enclosure.Name(), err)
+			continue
+		}
+		for _, component := range components {
+			// the device link of the enclosure itself is not a component
+			if !component.IsDir() {
+				continue
+			}
+			componentDir := filepath.Join(enclosureDir, enclosure.Name(), component.Name())
+			// the components without a disk and the attributes of the enclosure have no device link
+			device, err := filepath.EvalSymlinks(filepath.Join(componentDir, "device"))
+			if err != nil {
+				continue
+			}
+			slot, err := readFirstLine(filepath.Join(componentDir, "slot"), true)
+			if err != nil || slot == "" {
+				slot = strings.TrimSpace(component.Name())
+			}
+			slots[device] = EnclosureSlot{
+				Enclosure: enclosure.Name(),
+				Slot:      slot,
+			}
+		}
+	}
+	return slots
+}
+
+// setEnclosureSlots sets the enclosure and the slot of the devices in the enclosures, the partitions share the slot of their disk
+func setEnclosureSlots(devices []BlockDevice, sysfsBlockDir string, slots map[string]EnclosureSlot) {
+	if len(slots) == 0 {
+		return
+	}
+	for i := range devices {
+		device, err := filepath.EvalSymlinks(filepath.Join(sysfsBlockDir, devices[i].Devname, "device"))
+		if err != nil {
+			continue
+		}
+		slot, found := slots[device]
+		if !found {
+			continue
+		}
+		if devices[i].DriveInfo != nil {
+			devices[i].Enclosure = slot.Enclosure
+			devices[i].Slot = slot.Slot
+		}
+		for j := range devices[i].Partitions {
+			if devices[i].Partitions[j].DriveInfo != nil {
+				devices[i].Partitions[j].Enclosure = slot.Enclosure
+				devices[i].Partitions[j].Slot = slot.Slot
+			}
+		}
+	}
+}
@@ -50,3 +50,21 @@ func SetXFSUUID(ctx context.Context, uuid, path string) (string, error) {
 	outputBytes, err := cmd.CombinedOutput()
 	return string(outputBytes), err
 }
+
+// Wipe erases all the filesystem signatures present on the device
+func Wipe(ctx context.Context, path string) (string, error) {
+	cmd := exec.CommandContext(ctx, "wipefs", "--all", "--force", path)
+	outputBytes, err := cmd.CombinedOutput()
+	return string(outputBytes), err
+}
+
+// Repair runs xfs_repair on the device, noModify only scans the filesystem for errors
+func Repair(ctx context.Context, path string, noModify bool) (string, error) {
+	args := []string{}
+	if noModify {
+		args = append(args, "-n")
+	}
+	cmd := exec.CommandContext(ctx, "xfs_repair", append(args, path)...)
+	outputBytes, err := cmd.CombinedOutput()
+	return string(outputBytes), err
+}
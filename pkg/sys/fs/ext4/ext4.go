@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"github.com/google/uuid"
+	"github.com/minio/direct-csi/pkg/sys/fs"
 	"math"
 	"os"
 	"strconv"
@@ -190,6 +191,10 @@ func (ext4 *EXT4) UUID() (string, error) {
 	return strconv.Itoa(int(ext4.SuperBlock.DefaultReserveUID)) + strconv.Itoa(int(ext4.SuperBlock.DefaultReserveGID)), nil
 }
 
+func (ext4 *EXT4) Label() string {
+	return fs.LabelFromBytes(ext4.SuperBlock.SVolumeName[:])
+}
+
 func (ext4 *EXT4) Type() string {
 	return FSTypeEXT4
 }
@@ -0,0 +1,156 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package ext4
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/minio/direct-csi/pkg/sys/fs"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	ErrProjNotFound = errors.New("ext4 project not found")
+)
+
+// EXT4Quota manages the project quota of a directory on an ext4 filesystem
+// mounted with the prjquota option
+type EXT4Quota struct {
+	Path       string
+	Mountpoint string
+	ProjectID  string
+}
+
+// SetQuota assigns the projectID to the path and sets the hardlimits of the project,
+// the inode count is not limited for a zero inodeLimit. The hardlimits of an existing
+// project are updated if either of them differs.
+func (ext4q *EXT4Quota) SetQuota(ctx context.Context, limit, inodeLimit int64) error {
+	// setquota takes the limits in 1KiB blocks
+	limitInBlocks := (limit + 1023) / 1024
+
+	stats, err := ext4q.GetVolumeStats(ctx)
+	// error getting quota value
+	if err != nil && err != ErrProjNotFound {
+		return err
+	}
+	// this means quota has already been set
+	if err == nil && stats.TotalBytes == limitInBlocks*1024 && stats.TotalInodes == inodeLimit {
+		return nil
+	}
+
+	limitInStr := strconv.FormatInt(limitInBlocks, 10)
+	pid := fs.GetProjectIDHash(ext4q.ProjectID)
+
+	klog.V(3).Infof("setting prjquota proj_id=%s path=%s", pid, ext4q.Path)
+
+	cmd := exec.CommandContext(ctx, "chattr", "-p", pid, "+P", ext4q.Path)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		klog.Errorf("could not set prjquota proj_id=%s path=%s err=%v", pid, ext4q.Path, err)
+		return fmt.Errorf("SetQuota failed for %s with error: (%v), output: (%s)", ext4q.ProjectID, err, out)
+	}
+
+	inodeLimitInStr := strconv.FormatInt(inodeLimit, 10)
+	cmd = exec.CommandContext(ctx, "setquota", "-P", pid, "0", limitInStr, "0", inodeLimitInStr, ext4q.Mountpoint)
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		klog.Errorf("could not set prjquota proj_id=%s path=%s err=%v", pid, ext4q.Path, err)
+		return fmt.Errorf("setquota failed with error: %v, output: %s", err, out)
+	}
+	klog.V(3).Infof("prjquota set successfully proj_id=%s path=%s", pid, ext4q.Path)
+
+	return nil
+}
+
+// RemoveQuota clears the hardlimits of the projectID
+func (ext4q *EXT4Quota) RemoveQuota(ctx context.Context) error {
+	pid := fs.GetProjectIDHash(ext4q.ProjectID)
+
+	klog.V(3).Infof("removing prjquota proj_id=%s path=%s", pid, ext4q.Path)
+
+	cmd := exec.CommandContext(ctx, "setquota", "-P", pid, "0", "0", "0", "0", ext4q.Mountpoint)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		klog.Errorf("could not remove prjquota proj_id=%s path=%s err=%v", pid, ext4q.Path, err)
+		return fmt.Errorf("RemoveQuota failed for %s with error: (%v), output: (%s)", ext4q.ProjectID, err, out)
+	}
+	klog.V(3).Infof("prjquota removed successfully proj_id=%s path=%s", pid, ext4q.Path)
+
+	return nil
+}
+
+// GetVolumeStats - Reads the repquota report of the projects
+func (ext4q *EXT4Quota) GetVolumeStats(ctx context.Context) (fs.VolumeStats, error) {
+	cmd := exec.CommandContext(ctx, "repquota", "-P", "-n", ext4q.Mountpoint)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fs.VolumeStats{}, fmt.Errorf("GetVolumeStats failed with error: %v, output: %s", err, out)
+	}
+	pid := fs.GetProjectIDHash(ext4q.ProjectID)
+	return ParseQuotaReport(string(out), pid)
+}
+
+// ParseQuotaReport - Parses the repquota output and extracts the volume stats
+func ParseQuotaReport(output, projectID string) (fs.VolumeStats, error) {
+	for _, line := range strings.Split(output, "\n") {
+		values := strings.Fields(line)
+		// #<projectID> <flags> <used> <soft> <hard> ...
+		if len(values) < 5 || values[0] != "#"+projectID {
+			continue
+		}
+		used, err := strconv.ParseInt(values[2], 10, 64)
+		if err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading ext4 limits: %v", err)
+		}
+		hard, err := strconv.ParseInt(values[4], 10, 64)
+		if err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading ext4 limits: %v", err)
+		}
+		usedInBytes, totalInBytes := used*1024, hard*1024
+		stats := fs.VolumeStats{
+			AvailableBytes: totalInBytes - usedInBytes,
+			TotalBytes:     totalInBytes,
+			UsedBytes:      usedInBytes,
+		}
+
+		// ... <files> <soft> <hard>, preceded by the block grace if the block softlimit is exceeded
+		i := 5
+		if strings.HasPrefix(values[1], "+") {
+			i++
+		}
+		if len(values) < i+3 {
+			return stats, nil
+		}
+		if stats.UsedInodes, err = strconv.ParseInt(values[i], 10, 64); err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading ext4 inode limits: %v", err)
+		}
+		if stats.TotalInodes, err = strconv.ParseInt(values[i+2], 10, 64); err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading ext4 inode limits: %v", err)
+		}
+		if stats.TotalInodes > 0 {
+			stats.AvailableInodes = stats.TotalInodes - stats.UsedInodes
+		}
+		return stats, nil
+	}
+	return fs.VolumeStats{}, ErrProjNotFound
+}
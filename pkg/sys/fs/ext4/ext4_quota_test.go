@@ -0,0 +1,96 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package ext4
+
+import (
+	"testing"
+)
+
+func TestParseQuotaReport(t1 *testing.T) {
+	output := `*** Report for project quotas on device /dev/xvdc
+Block grace time: 7days; Inode grace time: 7days
+                        Block limits                File limits
+Project         used    soft    hard  grace    used  soft  hard  grace
+----------------------------------------------------------------------
+#0        --      20       0       0              2     0     0
+#100      --       0       0    8192              1     0     0
+#101      +-   10244       0   10240  6days       3     0     0
+#102      --       4       0    1024            250     0  1000
+`
+
+	testCases := []struct {
+		name                string
+		projectID           string
+		expectedUsed        int64
+		expectedTotal       int64
+		expectedUsedInodes  int64
+		expectedTotalInodes int64
+		expectedErr         error
+	}{
+		{
+			name:               "test1",
+			projectID:          "100",
+			expectedUsed:       0,
+			expectedTotal:      8192 * 1024,
+			expectedUsedInodes: 1,
+		},
+		{
+			name:               "test2",
+			projectID:          "101",
+			expectedUsed:       10244 * 1024,
+			expectedTotal:      10240 * 1024,
+			expectedUsedInodes: 3,
+		},
+		{
+			name:                "test4",
+			projectID:           "102",
+			expectedUsed:        4 * 1024,
+			expectedTotal:       1024 * 1024,
+			expectedUsedInodes:  250,
+			expectedTotalInodes: 1000,
+		},
+		{
+			name:        "test3",
+			projectID:   "10",
+			expectedErr: ErrProjNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			stats, err := ParseQuotaReport(output, tt.projectID)
+			if err != tt.expectedErr {
+				t1.Fatalf("Test case name %s: expected error %v but got %v", tt.name, tt.expectedErr, err)
+			}
+			if stats.UsedBytes != tt.expectedUsed {
+				t1.Errorf("Test case name %s: expected used bytes %d but got %d", tt.name, tt.expectedUsed, stats.UsedBytes)
+			}
+			if stats.TotalBytes != tt.expectedTotal {
+				t1.Errorf("Test case name %s: expected total bytes %d but got %d", tt.name, tt.expectedTotal, stats.TotalBytes)
+			}
+			if stats.AvailableBytes != tt.expectedTotal-tt.expectedUsed {
+				t1.Errorf("Test case name %s: expected available bytes %d but got %d", tt.name, tt.expectedTotal-tt.expectedUsed, stats.AvailableBytes)
+			}
+			if stats.UsedInodes != tt.expectedUsedInodes {
+				t1.Errorf("Test case name %s: expected used inodes %d but got %d", tt.name, tt.expectedUsedInodes, stats.UsedInodes)
+			}
+			if stats.TotalInodes != tt.expectedTotalInodes {
+				t1.Errorf("Test case name %s: expected total inodes %d but got %d", tt.name, tt.expectedTotalInodes, stats.TotalInodes)
+			}
+		})
+	}
+}
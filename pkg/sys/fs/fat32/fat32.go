@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"github.com/minio/direct-csi/pkg/sys/fs"
 	simd "github.com/minio/sha256-simd"
 	"os"
 )
@@ -84,6 +85,10 @@ func (f32 *FAT32) UUID() (string, error) {
 	return fmt.Sprintf("%x", simd.Sum256([]byte(f32.SuperBlock.Serno[:]))), nil
 }
 
+func (f32 *FAT32) Label() string {
+	return fs.LabelFromBytes(f32.SuperBlock.Label[:])
+}
+
 func (f32 *FAT32) Type() string {
 	return FSTypeFAT32
 }
@@ -17,15 +17,56 @@
 package fs
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"strconv"
+
+	simd "github.com/minio/sha256-simd"
 )
 
 type Filesystem interface {
 	Type() string
 	ProbeFS(devicePath string, startOffset int64) (bool, error)
 	UUID() (string, error)
+	Label() string
 	FSBlockSize() uint64
 	TotalCapacity() uint64
 	FreeCapacity() uint64
 	ByteOrder() binary.ByteOrder
 }
+
+// VolumeStats represents the usage of a volume limited by a project quota
+type VolumeStats struct {
+	AvailableBytes int64
+	TotalBytes     int64
+	UsedBytes      int64
+	// the inode counts are only limited when the quota has an inode hardlimit,
+	// TotalInodes is zero otherwise
+	AvailableInodes int64
+	TotalInodes     int64
+	UsedInodes      int64
+}
+
+// Quota manages the project quota of a volume on a filesystem
+type Quota interface {
+	// SetQuota sets the byte hardlimit and, if non-zero, the inode hardlimit of the project
+	SetQuota(ctx context.Context, limit, inodeLimit int64) error
+	GetVolumeStats(ctx context.Context) (VolumeStats, error)
+	RemoveQuota(ctx context.Context) error
+}
+
+// LabelFromBytes returns the label stored in a fixed size superblock field, padded with NUL bytes or spaces
+func LabelFromBytes(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(bytes.TrimRight(b, " "))
+}
+
+// GetProjectIDHash returns the numeric project id used for the quota of the volume
+func GetProjectIDHash(id string) string {
+	h := simd.Sum256([]byte(id))
+	b := binary.LittleEndian.Uint32(h[:8])
+	return strconv.FormatUint(uint64(b), 10)
+}
@@ -22,5 +22,8 @@ import (
 
 const FSTypeXFS = "xfs"
 
+// MaxLabelLength is the maximum length of a xfs filesystem label
+const MaxLabelLength = 12
+
 var XFSMagicNum = uint32(0x58465342)
 var ErrNotXFS = errors.New("Not a xfs partition")
@@ -0,0 +1,103 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package xfs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultBlockSize is the block size used by mkfs.xfs if none is requested
+	DefaultBlockSize = 4096
+	// MinBlockSize and MaxBlockSize are the limits of the xfs block size
+	MinBlockSize = 1024
+	MaxBlockSize = 65536
+)
+
+// mkfsDataOptions are the data section options of mkfs.xfs which may be requested
+// for a drive, e.g. the stripe unit and width of a RAID backed LUN. Any other
+// option is rejected so that no arbitrary argument reaches mkfs.xfs.
+var mkfsDataOptions = map[string]bool{
+	"su":      true,
+	"sw":      true,
+	"sunit":   true,
+	"swidth":  true,
+	"agcount": true,
+}
+
+var mkfsOptionValue = regexp.MustCompile(`^[0-9]+[kmgKMG]?$`)
+
+// ValidateBlockSize fails if the block size is not a power of two within the limits of xfs, zero selects the default
+func ValidateBlockSize(blockSize int64) error {
+	if blockSize == 0 {
+		return nil
+	}
+	if blockSize < MinBlockSize || blockSize > MaxBlockSize || blockSize&(blockSize-1) != 0 {
+		return fmt.Errorf("invalid block size %d; must be a power of two between %d and %d", blockSize, MinBlockSize, MaxBlockSize)
+	}
+	return nil
+}
+
+// ValidateMkfsOptions fails for the options which are not of the form <option>=<number>[k|m|g]
+// with an allowed data section option
+func ValidateMkfsOptions(options []string) error {
+	seen := map[string]bool{}
+	for _, option := range options {
+		tokens := strings.SplitN(option, "=", 2)
+		if len(tokens) != 2 || !mkfsDataOptions[tokens[0]] {
+			return fmt.Errorf("unsupported mkfs option %q; must be one of %s", option, strings.Join(allowedMkfsOptions(), "|"))
+		}
+		if !mkfsOptionValue.MatchString(tokens[1]) {
+			return fmt.Errorf("invalid value of mkfs option %q", option)
+		}
+		if seen[tokens[0]] {
+			return fmt.Errorf("duplicate mkfs option %q", tokens[0])
+		}
+		seen[tokens[0]] = true
+	}
+	return nil
+}
+
+func allowedMkfsOptions() []string {
+	options := []string{}
+	for option := range mkfsDataOptions {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	return options
+}
+
+// MkfsArgs returns the mkfs.xfs arguments for the requested block size and data section options
+func MkfsArgs(blockSize int64, options []string) ([]string, error) {
+	if err := ValidateBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+	if err := ValidateMkfsOptions(options); err != nil {
+		return nil, err
+	}
+	args := []string{}
+	if blockSize > 0 {
+		args = append(args, "-b", fmt.Sprintf("size=%d", blockSize))
+	}
+	if len(options) > 0 {
+		args = append(args, "-d", strings.Join(options, ","))
+	}
+	return args, nil
+}
@@ -0,0 +1,62 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package xfs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMkfsArgs(t1 *testing.T) {
+	testCases := []struct {
+		name         string
+		blockSize    int64
+		options      []string
+		expectedArgs []string
+		expectErr    bool
+	}{
+		{name: "defaults", expectedArgs: []string{}},
+		{name: "blockSize", blockSize: 4096, expectedArgs: []string{"-b", "size=4096"}},
+		{name: "stripe", blockSize: 4096, options: []string{"su=64k", "sw=4"}, expectedArgs: []string{"-b", "size=4096", "-d", "su=64k,sw=4"}},
+		{name: "agcount", options: []string{"agcount=32"}, expectedArgs: []string{"-d", "agcount=32"}},
+		{name: "blockSizeNotPowerOfTwo", blockSize: 3000, expectErr: true},
+		{name: "blockSizeTooSmall", blockSize: 512, expectErr: true},
+		{name: "blockSizeTooLarge", blockSize: 131072, expectErr: true},
+		{name: "unknownOption", options: []string{"crc=0"}, expectErr: true},
+		{name: "otherSection", options: []string{"-n"}, expectErr: true},
+		{name: "injectedValue", options: []string{"su=64k /dev/sda"}, expectErr: true},
+		{name: "injectedSuboption", options: []string{"su=64k,file=1"}, expectErr: true},
+		{name: "duplicate", options: []string{"su=64k", "su=128k"}, expectErr: true},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			args, err := MkfsArgs(tt.blockSize, tt.options)
+			if tt.expectErr {
+				if err == nil {
+					t1.Fatalf("expected error, got args %v", args)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t1.Errorf("expected args %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"github.com/google/uuid"
+	"github.com/minio/direct-csi/pkg/sys/fs"
 	"os"
 )
 
@@ -95,6 +96,10 @@ func (xfs *XFS) UUID() (string, error) {
 	return uid.String(), nil
 }
 
+func (xfs *XFS) Label() string {
+	return fs.LabelFromBytes(xfs.SuperBlock.FilesystemName[:])
+}
+
 func (xfs *XFS) ByteOrder() binary.ByteOrder {
 	return binary.BigEndian
 }
@@ -18,16 +18,15 @@ package xfs
 
 import (
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
-	"math"
 	"os/exec"
 	"strconv"
 	"strings"
 
-	simd "github.com/minio/sha256-simd"
-	"k8s.io/klog"
+	"github.com/minio/direct-csi/pkg/sys/fs"
+
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -39,45 +38,38 @@ type XFSQuota struct {
 	ProjectID string
 }
 
-type XFSVolumeStats struct {
-	AvailableBytes int64
-	TotalBytes     int64
-	UsedBytes      int64
-}
-
-func getProjectIDHash(id string) string {
-	h := simd.Sum256([]byte(id))
-	b := binary.LittleEndian.Uint32(h[:8])
-	return strconv.FormatUint(uint64(b), 10)
-}
+// SetQuota creates a projectID and sets the hardlimits for the path, the inode count is not limited for a zero inodeLimit.
+// The hardlimits of an existing projectID are updated if either of them differs.
+func (xfsq *XFSQuota) SetQuota(ctx context.Context, limit, inodeLimit int64) error {
 
-// SetQuota creates a projectID and sets the hardlimit for the path
-func (xfsq *XFSQuota) SetQuota(ctx context.Context, limit int64) error {
-
-	_, err := xfsq.GetVolumeStats(ctx)
+	stats, err := xfsq.GetVolumeStats(ctx)
 	// error getting quota value
 	if err != nil && err != ErrProjNotFound {
 		return err
 	}
 	// this means quota has already been set
-	if err == nil {
+	if err == nil && quotaLimitsMatch(stats, limit, inodeLimit) {
 		return nil
 	}
 
 	limitInStr := strconv.FormatInt(limit, 10)
-	pid := getProjectIDHash(xfsq.ProjectID)
+	pid := fs.GetProjectIDHash(xfsq.ProjectID)
 
-	klog.V(3).Infof("setting prjquota proj_id=%s path=%s", pid, xfsq.Path)
+	if err == ErrProjNotFound {
+		klog.V(3).Infof("setting prjquota proj_id=%s path=%s", pid, xfsq.Path)
 
-	cmd := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", fmt.Sprintf("project -d 0 -s -p %s %s", xfsq.Path, pid))
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		klog.Errorf("could not set prjquota proj_id=%s path=%s err=%v", pid, xfsq.Path, err)
-		return fmt.Errorf("SetQuota failed for %s with error: (%v), output: (%s)", xfsq.ProjectID, err, out)
+		cmd := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", fmt.Sprintf("project -d 0 -s -p %s %s", xfsq.Path, pid))
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			klog.Errorf("could not set prjquota proj_id=%s path=%s err=%v", pid, xfsq.Path, err)
+			return fmt.Errorf("SetQuota failed for %s with error: (%v), output: (%s)", xfsq.ProjectID, err, out)
+		}
 	}
 
-	cmd = exec.CommandContext(ctx, "xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=%s %s", limitInStr, pid), xfsq.Path)
-	out, err = cmd.CombinedOutput()
+	// a zero ihard clears the inode limit of an existing projectID
+	limits := "bhard=" + limitInStr + " ihard=" + strconv.FormatInt(inodeLimit, 10)
+	cmd := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", fmt.Sprintf("limit -p %s %s", limits, pid), xfsq.Path)
+	out, err := cmd.CombinedOutput()
 	if err != nil {
 		klog.Errorf("could not set prjquota proj_id=%s path=%s err=%v", pid, xfsq.Path, err)
 		return fmt.Errorf("xfs_quota failed with error: %v, output: %s", err, out)
@@ -87,96 +79,87 @@ func (xfsq *XFSQuota) SetQuota(ctx context.Context, limit int64) error {
 	return nil
 }
 
-func dehumanize(size string) (float64, error) {
-	if size == "0" {
-		return 0.0, nil
-	}
-	f, unit := size[:len(size)-1], size[len(size)-1:]
-	num, err := strconv.ParseFloat(f, 64)
-	if err != nil {
-		return 0.0, err
-	}
+// quotaLimitsMatch checks if the hardlimits of the quota report are the requested ones. The byte
+// limit of the report is humanized, it may differ by its rounding in which case the limits are set again.
+func quotaLimitsMatch(stats fs.VolumeStats, limit, inodeLimit int64) bool {
+	return stats.TotalBytes == limit && stats.TotalInodes == inodeLimit
+}
 
-	suffixes := map[string]int{"": 0, "K": 1, "M": 2, "G": 3, "T": 4, "P": 5, "E": 6, "Z": 7}
-	suffix := "B"
-	var prefix string
+// RemoveQuota clears the hardlimits of the projectID, path should be the mountpoint of the filesystem
+func (xfsq *XFSQuota) RemoveQuota(ctx context.Context) error {
+	pid := fs.GetProjectIDHash(xfsq.ProjectID)
 
-	if strings.HasSuffix(unit, suffix) {
-		prefix = unit[:len(unit)-1]
-	} else {
-		prefix = unit
-	}
-	prefix = strings.ToUpper(prefix)
+	klog.V(3).Infof("removing prjquota proj_id=%s path=%s", pid, xfsq.Path)
 
-	if s, ok := suffixes[prefix]; ok {
-		value := num * math.Pow(1024.0, float64(s))
-		return value, nil
-	} else {
-		return 0.0, fmt.Errorf("Unknown unit: '%s'", prefix)
+	cmd := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", fmt.Sprintf("limit -p bhard=0 ihard=0 %s", pid), xfsq.Path)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		klog.Errorf("could not remove prjquota proj_id=%s path=%s err=%v", pid, xfsq.Path, err)
+		return fmt.Errorf("RemoveQuota failed for %s with error: (%v), output: (%s)", xfsq.ProjectID, err, out)
 	}
+	klog.V(3).Infof("prjquota removed successfully proj_id=%s path=%s", pid, xfsq.Path)
+
+	return nil
 }
 
 // GetVolumeStats - Reads the xfs_quota report
-func (xfsq *XFSQuota) GetVolumeStats(ctx context.Context) (XFSVolumeStats, error) {
-	cmd := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", fmt.Sprint("report -h"), xfsq.Path)
+func (xfsq *XFSQuota) GetVolumeStats(ctx context.Context) (fs.VolumeStats, error) {
+	// the raw counts are reported, the humanized values are rounded
+	cmd := exec.CommandContext(ctx, "xfs_quota", "-x", "-c", "report -N -n -b -i", xfsq.Path)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return XFSVolumeStats{}, fmt.Errorf("GetVolumeStats failed with error: %v, output: %s", err, out)
+		return fs.VolumeStats{}, fmt.Errorf("GetVolumeStats failed with error: %v, output: %s", err, out)
 	}
 	output := string(out)
-	pid := getProjectIDHash(xfsq.ProjectID)
+	pid := fs.GetProjectIDHash(xfsq.ProjectID)
 	return ParseQuotaList(output, pid)
 }
 
-// ParseQuotaList - Parses the quota output and extracts the volume stats
-func ParseQuotaList(output, projectID string) (XFSVolumeStats, error) {
-	var usedInBytes, totalInBytes int64
-	var pErr error
-	var f float64
-	lines := strings.Split(output, "\n")
-	prjFound := false
-	for _, l := range lines {
-		line := strings.TrimSpace(l)
-		if len(line) == 0 {
+// ParseQuotaList - Parses the quota output and extracts the volume stats, the blocks are
+// reported in 1KiB units and the inodes as counts
+func ParseQuotaList(output, projectID string) (fs.VolumeStats, error) {
+	for _, line := range strings.Split(output, "\n") {
+		values := strings.Fields(line)
+		// #<projectID> <used> <soft> <hard> <warn> [<grace>] [<iused> <isoft> <ihard> <iwarn> [<igrace>]]
+		if len(values) < 4 || values[0] != "#"+projectID {
 			continue
 		}
-		if !strings.HasPrefix(line, "#"+projectID) {
-			continue
+
+		usedBlocks, err := strconv.ParseInt(values[1], 10, 64)
+		if err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading xfs limits: %v", err)
 		}
-		prjFound = true
-
-		splits := strings.Split(line, " ")
-		var values []string
-		for _, split := range splits {
-			tSplit := strings.TrimSpace(split)
-			if tSplit == "" {
-				continue
-			}
-			values = append(values, tSplit)
+		hardBlocks, err := strconv.ParseInt(values[3], 10, 64)
+		if err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading xfs limits: %v", err)
 		}
-
-		if values[0] == "#"+projectID {
-			f, pErr = dehumanize(values[1])
-			if pErr != nil {
-				return XFSVolumeStats{}, fmt.Errorf("Error while reading xfs limits: %v", pErr)
-			}
-			usedInBytes = int64(f)
-			f, pErr = dehumanize(values[3])
-			if pErr != nil {
-				return XFSVolumeStats{}, fmt.Errorf("Error while reading xfs limits: %v", pErr)
-			}
-			totalInBytes = int64(f)
-			break
+		usedInBytes := usedBlocks * 1024
+		totalInBytes := hardBlocks * 1024
+		stats := fs.VolumeStats{
+			AvailableBytes: totalInBytes - usedInBytes,
+			TotalBytes:     totalInBytes,
+			UsedBytes:      usedInBytes,
 		}
-		break
-	}
 
-	if !prjFound {
-		return XFSVolumeStats{}, ErrProjNotFound
+		// the grace of the blocks may contain spaces, e.g. [7 days], the inode columns follow it
+		i := 5
+		for i < len(values) && !strings.HasSuffix(values[i], "]") {
+			i++
+		}
+		if i+3 >= len(values) {
+			return stats, nil
+		}
+		inodes := values[i+1:]
+		if stats.UsedInodes, err = strconv.ParseInt(inodes[0], 10, 64); err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading xfs inode limits: %v", err)
+		}
+		if stats.TotalInodes, err = strconv.ParseInt(inodes[2], 10, 64); err != nil {
+			return fs.VolumeStats{}, fmt.Errorf("Error while reading xfs inode limits: %v", err)
+		}
+		if stats.TotalInodes > 0 {
+			stats.AvailableInodes = stats.TotalInodes - stats.UsedInodes
+		}
+		return stats, nil
 	}
-	return XFSVolumeStats{
-		AvailableBytes: totalInBytes - usedInBytes,
-		TotalBytes:     totalInBytes,
-		UsedBytes:      usedInBytes,
-	}, nil
+	return fs.VolumeStats{}, ErrProjNotFound
 }
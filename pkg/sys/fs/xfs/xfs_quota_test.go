@@ -18,43 +18,104 @@ package xfs
 
 import (
 	"testing"
+
+	"github.com/minio/direct-csi/pkg/sys/fs"
 )
 
 func TestParseQuotaList(t1 *testing.T) {
-	output := `Project quota on /tmp/c333 (/dev/xvdc)
-					                   Blocks              
-			   Project ID   Used   Soft   Hard Warn/Grace   
-			   ---------- --------------------------------- 
-			   #0              0      0      0  00 [------]
-			   #100            0      0     8T  00 [------]
-			   #101            0      0    10M  00 [------]
-			   #200           4K      0  20.4M  00 [------]`
+	// the output of report -N -n -b -i
+	output := `#0                   0          0          0  00 [--------]          3          0          0  00 [--------]
+#100                 0          0 8589934592  00 [--------]          1          0          0  00 [--------]
+#101                 0          0      10240  00 [--------]          2          0       1000  00 [--------]
+#200                 4          0      20480  00 [--------]       1537          0      10000  00 [--------]
+#300                 4          1          8  01 [7 days]           5          0         10  00 [--------]
+`
 
 	testCases := []struct {
-		name      string
-		projectID string
+		name                string
+		projectID           string
+		expectedUsed        int64
+		expectedTotal       int64
+		expectedUsedInodes  int64
+		expectedTotalInodes int64
 	}{
 		{
-			name:      "test1",
-			projectID: "100",
+			name:               "test1",
+			projectID:          "100",
+			expectedTotal:      8 * 1024 * 1024 * 1024 * 1024,
+			expectedUsedInodes: 1,
+		},
+		{
+			name:                "test2",
+			projectID:           "101",
+			expectedTotal:       10 * 1024 * 1024,
+			expectedUsedInodes:  2,
+			expectedTotalInodes: 1000,
 		},
 		{
-			name:      "test2",
-			projectID: "101",
+			name:                "test3",
+			projectID:           "200",
+			expectedUsed:        4 * 1024,
+			expectedTotal:       20 * 1024 * 1024,
+			expectedUsedInodes:  1537,
+			expectedTotalInodes: 10000,
 		},
 		{
-			name:      "test3",
-			projectID: "200",
+			name:                "test4",
+			projectID:           "300",
+			expectedUsed:        4 * 1024,
+			expectedTotal:       8 * 1024,
+			expectedUsedInodes:  5,
+			expectedTotalInodes: 10,
 		},
 	}
 
 	for _, tt := range testCases {
 		t1.Run(tt.name, func(t1 *testing.T) {
-			_, err := ParseQuotaList(output, tt.projectID)
+			stats, err := ParseQuotaList(output, tt.projectID)
 			if err != nil {
-				t1.Error(err)
+				t1.Fatal(err)
+			}
+			if stats.UsedBytes != tt.expectedUsed {
+				t1.Errorf("Test case name %s: expected used bytes %d but got %d", tt.name, tt.expectedUsed, stats.UsedBytes)
+			}
+			if stats.TotalBytes != tt.expectedTotal {
+				t1.Errorf("Test case name %s: expected total bytes %d but got %d", tt.name, tt.expectedTotal, stats.TotalBytes)
+			}
+			if stats.UsedInodes != tt.expectedUsedInodes {
+				t1.Errorf("Test case name %s: expected used inodes %d but got %d", tt.name, tt.expectedUsedInodes, stats.UsedInodes)
+			}
+			if stats.TotalInodes != tt.expectedTotalInodes {
+				t1.Errorf("Test case name %s: expected total inodes %d but got %d", tt.name, tt.expectedTotalInodes, stats.TotalInodes)
 			}
 		})
 	}
 
+	if _, err := ParseQuotaList(output, "10"); err != ErrProjNotFound {
+		t1.Errorf("expected error %v but got %v", ErrProjNotFound, err)
+	}
+}
+
+func TestQuotaLimitsMatch(t1 *testing.T) {
+	testCases := []struct {
+		name       string
+		stats      fs.VolumeStats
+		limit      int64
+		inodeLimit int64
+		expected   bool
+	}{
+		{"match", fs.VolumeStats{TotalBytes: 20 << 20}, 20 << 20, 0, true},
+		{"inodematch", fs.VolumeStats{TotalBytes: 20 << 20, TotalInodes: 1000}, 20 << 20, 1000, true},
+		{"bytes", fs.VolumeStats{TotalBytes: 10 << 20}, 20 << 20, 0, false},
+		{"inodes", fs.VolumeStats{TotalBytes: 20 << 20}, 20 << 20, 1000, false},
+		{"inodescleared", fs.VolumeStats{TotalBytes: 20 << 20, TotalInodes: 1000}, 20 << 20, 0, false},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if match := quotaLimitsMatch(tt.stats, tt.limit, tt.inodeLimit); match != tt.expected {
+				t1.Errorf("Test case name %s: expected match = %v but got %v", tt.name, tt.expected, match)
+			}
+		})
+	}
 }
@@ -59,6 +59,7 @@ func (b *BlockDevice) probeFS(offsetBlocks uint64) (*FSInfo, error) {
 	fsInfo := &FSInfo{
 		UUID:          uuid,
 		FSType:        fs.Type(),
+		Label:         fs.Label(),
 		FSBlockSize:   fs.FSBlockSize(),
 		TotalCapacity: fs.TotalCapacity(),
 		FreeCapacity:  fs.FreeCapacity(),
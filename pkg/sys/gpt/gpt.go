@@ -16,14 +16,28 @@
 
 package gpt
 
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
 var GPTSignature = [8]byte{0x45, 0x46, 0x49, 0x20, 0x50, 0x41, 0x52, 0x54}
 
+// gptLBASize is the size of GPTLBA, the leading part of a partition entry
+const gptLBASize = 48
+
+// maxPartitionEntryArraySize caps the partition entry array read from a corrupted header,
+// it is far above the 16 KiB array of 128 entries of 128 bytes created by the partitioning tools
+const maxPartitionEntryArraySize = 1 << 20
+
+// GPTHeader is located at LBA1, right after the protective MBR in LBA0
 type GPTHeader struct {
-	_                      [512]byte // LBA0: 512-byte MBR
-	Signature              [8]byte   `json:"signature,omitempty"`
-	Revision               [4]byte   `json:"revision,omitempty"`
-	HeaderSize             uint32    `json:"HeaderSize,omitempty"`
-	CRC32                  uint32    `json:"crc32,omitempty"`
+	Signature              [8]byte `json:"signature,omitempty"`
+	Revision               [4]byte `json:"revision,omitempty"`
+	HeaderSize             uint32  `json:"HeaderSize,omitempty"`
+	CRC32                  uint32  `json:"crc32,omitempty"`
 	_                      uint32
 	CurrentLBA             uint64   `json:"currentLBA,omitempty"`     // address of current LBA
 	BackupLBA              uint64   `json:"backupLBA,omitempty"`      // address of backup LBA
@@ -63,6 +77,69 @@ func (l GPTLBA) Is() bool {
 	return false
 }
 
+// PartitionEntry is a valid entry of the partition entry array
+type PartitionEntry struct {
+	GPTLBA
+	// Number is the 1-based index of the entry in the partition entry array
+	Number uint32
+}
+
+// ReadHeader reads the GPT header from LBA1. blockSize is the logical block
+// size of the device i.e. 512 bytes or 4096 bytes for 4Kn drives.
+func ReadHeader(r io.ReadSeeker, blockSize uint64) (*GPTHeader, error) {
+	if _, err := r.Seek(int64(blockSize), io.SeekStart); err != nil {
+		return nil, err
+	}
+	header := &GPTHeader{}
+	if err := binary.Read(r, binary.LittleEndian, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// ReadPartitionEntries reads all the valid entries of the partition entry array
+// described by the header. Unused entries in between are skipped.
+func ReadPartitionEntries(r io.ReadSeeker, header *GPTHeader, blockSize uint64) ([]PartitionEntry, error) {
+	entrySize := uint64(header.PartitionEntrySize)
+	if entrySize < gptLBASize {
+		return nil, fmt.Errorf("invalid partition entry size %d in GPT header", entrySize)
+	}
+	arraySize := entrySize * uint64(header.NumPartitionEntries)
+	if arraySize > maxPartitionEntryArraySize {
+		return nil, fmt.Errorf("GPT partition entry array of %d bytes exceeds %d bytes", arraySize, maxPartitionEntryArraySize)
+	}
+	if header.FirstUsableLBA > header.PartitionEntryStartLBA {
+		// the partition entry array must fit before the first usable LBA
+		if maxSize := (header.FirstUsableLBA - header.PartitionEntryStartLBA) * blockSize; arraySize > maxSize {
+			return nil, fmt.Errorf("GPT partition entry array of %d bytes exceeds %d bytes", arraySize, maxSize)
+		}
+	}
+
+	if _, err := r.Seek(int64(header.PartitionEntryStartLBA*blockSize), io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, arraySize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	entries := []PartitionEntry{}
+	for i := uint64(0); i < uint64(header.NumPartitionEntries); i++ {
+		lba := GPTLBA{}
+		if err := binary.Read(bytes.NewReader(data[i*entrySize:]), binary.LittleEndian, &lba); err != nil {
+			return nil, err
+		}
+		if !lba.Is() {
+			continue
+		}
+		entries = append(entries, PartitionEntry{
+			GPTLBA: lba,
+			Number: uint32(i + 1),
+		})
+	}
+	return entries, nil
+}
+
 var PartitionTypes = map[string]string{
 	// N/A
 	"00000000-0000-0000-0000-000000000000": "Unused entry",
@@ -0,0 +1,152 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gpt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func newTestGPTImage(t *testing.T, blockSize uint64, numEntries uint32, lbas map[uint32]GPTLBA) []byte {
+	const entrySize = 128
+	arrayBlocks := (uint64(numEntries)*entrySize + blockSize - 1) / blockSize
+	image := make([]byte, (2+arrayBlocks)*blockSize)
+
+	header := GPTHeader{
+		Signature:              GPTSignature,
+		HeaderSize:             92,
+		CurrentLBA:             1,
+		FirstUsableLBA:         2 + arrayBlocks,
+		PartitionEntryStartLBA: 2,
+		NumPartitionEntries:    numEntries,
+		PartitionEntrySize:     entrySize,
+	}
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, header); err != nil {
+		t.Fatal(err)
+	}
+	copy(image[blockSize:], buf.Bytes())
+
+	for number, lba := range lbas {
+		buf := new(bytes.Buffer)
+		if err := binary.Write(buf, binary.LittleEndian, lba); err != nil {
+			t.Fatal(err)
+		}
+		copy(image[2*blockSize+uint64(number-1)*entrySize:], buf.Bytes())
+	}
+	return image
+}
+
+func TestReadPartitionEntries(t1 *testing.T) {
+	linuxData := [16]byte{0xAF, 0x3D, 0xC6, 0x0F, 0x83, 0x84, 0x72, 0x47, 0x8E, 0x79, 0x3D, 0x69, 0xD8, 0x47, 0x7D, 0xE4}
+
+	testCases := []struct {
+		name       string
+		blockSize  uint64
+		numEntries uint32
+		lbas       map[uint32]GPTLBA
+	}{
+		{
+			name:       "test1",
+			blockSize:  512,
+			numEntries: 128,
+			lbas: map[uint32]GPTLBA{
+				1: {PartitionType: linuxData, Start: 2048, End: 4095},
+				2: {PartitionType: linuxData, Start: 4096, End: 8191},
+			},
+		},
+		{
+			name:       "test2",
+			blockSize:  4096,
+			numEntries: 128,
+			lbas: map[uint32]GPTLBA{
+				1: {PartitionType: linuxData, Start: 256, End: 511},
+				3: {PartitionType: linuxData, Start: 1024, End: 2047},
+			},
+		},
+		{
+			name:       "test3",
+			blockSize:  4096,
+			numEntries: 256,
+			lbas: map[uint32]GPTLBA{
+				1:   {PartitionType: linuxData, Start: 256, End: 511},
+				130: {PartitionType: linuxData, Start: 1024, End: 2047},
+				256: {PartitionType: linuxData, Start: 4096, End: 8191},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			image := newTestGPTImage(t1, tt.blockSize, tt.numEntries, tt.lbas)
+			r := bytes.NewReader(image)
+
+			header, err := ReadHeader(r, tt.blockSize)
+			if err != nil {
+				t1.Fatalf("Test case name %s: Unexpected error: %v", tt.name, err)
+			}
+			if !header.Is() {
+				t1.Fatalf("Test case name %s: GPT signature not found", tt.name)
+			}
+
+			entries, err := ReadPartitionEntries(r, header, tt.blockSize)
+			if err != nil {
+				t1.Fatalf("Test case name %s: Unexpected error: %v", tt.name, err)
+			}
+			if len(entries) != len(tt.lbas) {
+				t1.Fatalf("Test case name %s: Expected %d entries, got %d", tt.name, len(tt.lbas), len(entries))
+			}
+			for _, entry := range entries {
+				lba, ok := tt.lbas[entry.Number]
+				if !ok {
+					t1.Fatalf("Test case name %s: Unexpected entry %d", tt.name, entry.Number)
+				}
+				if entry.GPTLBA != lba {
+					t1.Errorf("Test case name %s: Expected entry %d = %v, got %v", tt.name, entry.Number, lba, entry.GPTLBA)
+				}
+			}
+		})
+	}
+}
+
+func TestReadPartitionEntriesCorruptHeader(t1 *testing.T) {
+	image := newTestGPTImage(t1, 512, 128, nil)
+	r := bytes.NewReader(image)
+	header, err := ReadHeader(r, 512)
+	if err != nil {
+		t1.Fatal(err)
+	}
+
+	header.NumPartitionEntries = 1 << 20
+	if _, err := ReadPartitionEntries(r, header, 512); err == nil {
+		t1.Errorf("Expected error for oversized partition entry array but succeeded")
+	}
+
+	// the array is capped even if the first usable LBA does not bound it
+	header.FirstUsableLBA = 0
+	header.NumPartitionEntries = 1 << 20
+	if _, err := ReadPartitionEntries(r, header, 512); err == nil {
+		t1.Errorf("Expected error for partition entry array above %d bytes but succeeded", maxPartitionEntryArraySize)
+	}
+
+	header.NumPartitionEntries = 128
+	header.PartitionEntrySize = 16
+	if _, err := ReadPartitionEntries(r, header, 512); err == nil {
+		t1.Errorf("Expected error for invalid partition entry size but succeeded")
+	}
+}
@@ -34,4 +34,12 @@ const (
 	GetStatus64 = 0x4C05
 
 	oneMB = 1048576
+
+	// DefaultDeviceCount is the number of loopback devices reserved per node by default
+	DefaultDeviceCount = 4
+
+	sysModuleLoopParametersDir = "/sys/module/loop/parameters"
+	sysBlockDir                = "/sys/block"
+	// number of bits of the minor numbers in the kernel
+	minorBits = 20
 )
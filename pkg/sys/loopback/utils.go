@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"math/bits"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -78,3 +79,71 @@ func getLoopDeviceNumber(devName string) (uint64, error) {
 	}
 	return num, nil
 }
+
+func readLoopParameter(parametersDir, name string) (int, error) {
+	value, err := ioutil.ReadFile(filepath.Join(parametersDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(value)))
+}
+
+// getMaxLoopDevices returns the number of loop devices the kernel can create, each loop device
+// takes 2^fls(max_part) minors and max_loop caps the number of devices if set
+func getMaxLoopDevices(parametersDir string) (int, error) {
+	maxLoop, err := readLoopParameter(parametersDir, "max_loop")
+	if err != nil {
+		return 0, err
+	}
+	maxPart, err := readLoopParameter(parametersDir, "max_part")
+	if err != nil {
+		return 0, err
+	}
+
+	maxDevices := 1 << (minorBits - bits.Len(uint(maxPart)))
+	if maxLoop > 0 && maxLoop < maxDevices {
+		maxDevices = maxLoop
+	}
+	return maxDevices, nil
+}
+
+// countAttachedLoopDevices returns the number of loop devices backed by a file
+func countAttachedLoopDevices(blockDir string) (int, error) {
+	devices, err := filepath.Glob(filepath.Join(blockDir, "loop*"))
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, device := range devices {
+		if _, err := os.Stat(filepath.Join(device, "loop", "backing_file")); err == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func validateDeviceCount(count int, parametersDir, blockDir string) error {
+	if count < 1 {
+		return fmt.Errorf("invalid loopback device count %d, should be at least 1", count)
+	}
+	maxDevices, err := getMaxLoopDevices(parametersDir)
+	if err != nil {
+		return fmt.Errorf("could not read the loop module parameters: %v", err)
+	}
+	attached, err := countAttachedLoopDevices(blockDir)
+	if err != nil {
+		return fmt.Errorf("could not count the attached loop devices: %v", err)
+	}
+	if available := maxDevices - attached; count > available {
+		return fmt.Errorf("cannot reserve %d loopback devices, only %d loop device minors are available", count, available)
+	}
+	return nil
+}
+
+// ValidateDeviceCount checks if the given number of loop devices can be created on this node
+func ValidateDeviceCount(count int) error {
+	return validateDeviceCount(count, sysModuleLoopParametersDir, sysBlockDir)
+}
@@ -0,0 +1,108 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package loopback
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateDeviceCount(t1 *testing.T) {
+	writeFile := func(t1 *testing.T, path, value string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t1.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+			t1.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		maxLoop         string
+		maxPart         string
+		attachedDevices int
+		count           int
+		expectErr       bool
+	}{
+		{
+			name:  "test1",
+			count: DefaultDeviceCount,
+		},
+		{
+			name:      "test2",
+			count:     0,
+			expectErr: true,
+		},
+		{
+			name:            "test3",
+			maxLoop:         "8\n",
+			attachedDevices: 4,
+			count:           4,
+		},
+		{
+			name:            "test4",
+			maxLoop:         "8\n",
+			attachedDevices: 5,
+			count:           4,
+			expectErr:       true,
+		},
+		{
+			name:    "test5",
+			maxPart: "65535\n",
+			count:   16,
+		},
+		{
+			name:      "test6",
+			maxPart:   "65535\n",
+			count:     17,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			parametersDir := filepath.Join(t1.TempDir(), "parameters")
+			if err := os.MkdirAll(parametersDir, 0755); err != nil {
+				t1.Fatal(err)
+			}
+			if tt.maxLoop != "" {
+				writeFile(t1, filepath.Join(parametersDir, "max_loop"), tt.maxLoop)
+			}
+			if tt.maxPart != "" {
+				writeFile(t1, filepath.Join(parametersDir, "max_part"), tt.maxPart)
+			}
+			blockDir := filepath.Join(t1.TempDir(), "block")
+			for i := 0; i < tt.attachedDevices; i++ {
+				writeFile(t1, filepath.Join(blockDir, fmt.Sprintf("loop%d", i), "loop", "backing_file"), "/var/lib/direct-csi/loop/loop\n")
+			}
+			// detached loop devices do not take any of the available minors
+			writeFile(t1, filepath.Join(blockDir, "loop100", "dev"), "7:100\n")
+
+			err := validateDeviceCount(tt.count, parametersDir, blockDir)
+			if tt.expectErr && err == nil {
+				t1.Fatalf("Test case name %s: expected error but got none", tt.name)
+			}
+			if !tt.expectErr && err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+		})
+	}
+}
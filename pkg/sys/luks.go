@@ -0,0 +1,45 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// luksMagic is the magic of the LUKS1 and LUKS2 headers, stored at the start of the device
+var luksMagic = []byte{'L', 'U', 'K', 'S', 0xba, 0xbe}
+
+// hasLUKSHeader checks if the device region starting at the offset is a LUKS container,
+// whether it is opened or not
+func hasLUKSHeader(reader io.ReaderAt, offset int64) (bool, error) {
+	buf := make([]byte, len(luksMagic)+2)
+	if _, err := reader.ReadAt(buf, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !bytes.Equal(buf[:len(luksMagic)], luksMagic) {
+		return false, nil
+	}
+	// the big endian version follows the magic
+	version := binary.BigEndian.Uint16(buf[len(luksMagic):])
+	return version == 1 || version == 2, nil
+}
@@ -0,0 +1,97 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// mdMagic is the magic number of the Linux MD RAID superblocks
+const mdMagic = 0xa92b4efc
+
+// mdSuperblockOffsets returns the offsets of the MD superblock versions 0.90, 1.0, 1.1 and 1.2
+// in a device of the given size
+func mdSuperblockOffsets(size int64) []int64 {
+	offsets := []int64{0, 4 * 1024}
+	if size >= 64*1024 {
+		// v0.90 is stored in the last 64K aligned block of the device
+		offsets = append(offsets, (size&^(64*1024-1))-64*1024)
+	}
+	if size >= 8*1024 {
+		// v1.0 is stored 8K from the end, aligned to 4K
+		offsets = append(offsets, (((size>>9)-16)&^7)<<9)
+	}
+	return offsets
+}
+
+// mdSuperblockSize is the size of the v0.90 superblock and the maximum size of the v1.x superblocks
+const mdSuperblockSize = 4096
+
+// mdChecksum folds the 64-bit sum of the little endian words of the superblock into 32 bits,
+// counting the checksum field at csumOffset as zero
+func mdChecksum(sb []byte, csumOffset int) uint32 {
+	var sum uint64
+	for i := 0; i+4 <= len(sb); i += 4 {
+		if i != csumOffset {
+			sum += uint64(binary.LittleEndian.Uint32(sb[i:]))
+		}
+	}
+	if len(sb)%4 == 2 {
+		sum += uint64(binary.LittleEndian.Uint16(sb[len(sb)-2:]))
+	}
+	return uint32(sum&0xffffffff) + uint32(sum>>32)
+}
+
+// isValidMDSuperblock checks the version and the checksum of the superblock read from the device
+func isValidMDSuperblock(sb []byte) bool {
+	if len(sb) < 256 || binary.LittleEndian.Uint32(sb) != mdMagic {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(sb[4:]) {
+	case 0:
+		// v0.90 keeps the checksum in word 38 of the 4K superblock
+		if len(sb) < mdSuperblockSize || binary.LittleEndian.Uint32(sb[8:]) != 90 {
+			return false
+		}
+		return binary.LittleEndian.Uint32(sb[152:]) == mdChecksum(sb[:mdSuperblockSize], 152)
+	case 1:
+		// v1.x checksums the 256 byte header and the roles of max_dev devices
+		maxDev := binary.LittleEndian.Uint32(sb[220:])
+		if maxDev > (mdSuperblockSize-256)/2 || len(sb) < 256+int(maxDev)*2 {
+			return false
+		}
+		return binary.LittleEndian.Uint32(sb[216:]) == mdChecksum(sb[:256+maxDev*2], 216)
+	}
+	return false
+}
+
+// hasMDSuperblock checks if the device region starting at the offset is a member of an MD array
+func hasMDSuperblock(reader io.ReaderAt, offset, size int64) (bool, error) {
+	buf := make([]byte, mdSuperblockSize)
+	for _, sbOffset := range mdSuperblockOffsets(size) {
+		n, err := reader.ReadAt(buf, offset+sbOffset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, err
+		}
+		if isValidMDSuperblock(buf[:n]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,56 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// findMountHolders returns the processes whose mount namespace still holds the mount of the device and root
+func findMountHolders(procFS string, major, minor uint32, mountRoot string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(procFS, "[0-9]*", "mountinfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	devNum := fmt.Sprintf("%d:%d", major, minor)
+	holders := []string{}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			// the process might have exited already
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 5 || fields[2] != devNum || unescapeMountInfoField(fields[3]) != mountRoot {
+				continue
+			}
+			pidDir := filepath.Dir(file)
+			holder := filepath.Base(pidDir)
+			if comm, err := ioutil.ReadFile(filepath.Join(pidDir, "comm")); err == nil {
+				holder = fmt.Sprintf("%s (%s)", holder, strings.TrimSpace(string(comm)))
+			}
+			holders = append(holders, holder)
+			break
+		}
+	}
+	return holders, nil
+}
@@ -19,14 +19,24 @@
 package sys
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"syscall"
+	"time"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
-func SafeMount(source, target, fsType string, mountOpts []MountOption, superblockOpts []string) error {
+const (
+	unmountBusyRetries       = 3
+	unmountBusyRetryInterval = time.Second
+)
+
+// SafeMount mounts the source at the target unless it is already mounted there with the same flags.
+// It returns a DeadlineExceeded status error if the context expires while the mount hangs.
+func SafeMount(ctx context.Context, source, target, fsType string, mountOpts []MountOption, superblockOpts []string) error {
 	mounts, err := ProbeMountInfo()
 	if err != nil {
 		return err
@@ -61,7 +71,9 @@ func SafeMount(source, target, fsType string, mountOpts []MountOption, superbloc
 			break
 		}
 	}
-	return Mount(source, target, fsType, mountOpts, superblockOpts)
+	return runWithContext(ctx, func() error {
+		return Mount(source, target, fsType, mountOpts, superblockOpts)
+	})
 }
 
 func Mount(source, target, fsType string, mountOpts []MountOption, superblockOpts []string) error {
@@ -86,6 +98,8 @@ func Mount(source, target, fsType string, mountOpts []MountOption, superblockOpt
 			case MountOptionMSRelatime:
 			case MountOptionMSReadOnly:
 			case MountOptionMSStrictATime:
+			case MountOptionMSBind:
+			case MountOptionMSRemount:
 			default:
 				return fmt.Errorf("unsupported flag for remount operation: %s", opt)
 			}
@@ -95,8 +109,12 @@ func Mount(source, target, fsType string, mountOpts []MountOption, superblockOpt
 	verifyBindMount := func(mountOpts []MountOption) error {
 		bindMount := false
 		for _, opt := range mountOpts {
-			if opt == MountOptionMSBind {
+			switch opt {
+			case MountOptionMSBind:
 				bindMount = true
+			case MountOptionMSRemount:
+				// the flags of a bind mount are changed by a remount, verified by verifyRemount
+				return nil
 			}
 		}
 		if !bindMount {
@@ -220,22 +238,58 @@ func SafeUnmount(target string, opts []UnmountOption) error {
 		return err
 	}
 
-	targetMountFound := false
-	for _, m := range mounts {
+	var targetMount *MountInfo
+	for i := range mounts {
 		// idempotency check
-		if m.Mountpoint == target {
-			targetMountFound = true
+		if mounts[i].Mountpoint == target {
+			targetMount = &mounts[i]
 			break
 		}
 	}
 
 	// if no mounts were found at the given path
-	if !targetMountFound {
+	if targetMount == nil {
 		klog.V(3).Infof("drive already unmounted: %s", target)
 		return nil
 	}
-	return Unmount(target, opts)
 
+	detachOnBusy := false
+	unmountOpts := []UnmountOption{}
+	for _, opt := range opts {
+		if opt == UnmountOptionDetachOnBusy {
+			detachOnBusy = true
+			continue
+		}
+		unmountOpts = append(unmountOpts, opt)
+	}
+
+	err = Unmount(target, unmountOpts)
+	if !errors.Is(err, syscall.EBUSY) {
+		return err
+	}
+	logMountHolders(*targetMount)
+	if !detachOnBusy {
+		return err
+	}
+
+	for i := 0; i < unmountBusyRetries; i++ {
+		time.Sleep(unmountBusyRetryInterval)
+		if err = Unmount(target, unmountOpts); !errors.Is(err, syscall.EBUSY) {
+			return err
+		}
+	}
+	klog.Warningf("%s is still busy after %d retries, detaching it lazily", target, unmountBusyRetries)
+	return Unmount(target, append(unmountOpts, UnmountOptionDetach))
+}
+
+// logMountHolders logs the processes still holding the busy mount
+func logMountHolders(mount MountInfo) {
+	holders, err := findMountHolders(DefaultProcFS, mount.Major, mount.Minor, mount.MountRoot)
+	if err != nil {
+		klog.V(3).Infof("unable to find the processes holding %s: %v", mount.Mountpoint, err)
+		return
+	}
+	klog.Infof("%s is busy, held by the processes: %s", mount.Mountpoint, strings.Join(holders, ", "))
 }
 
 func SafeUnmountAll(path string, opts []UnmountOption) error {
@@ -0,0 +1,227 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MountInfoPath is the mountinfo of the host mount namespace, i.e. of PID 1 when
+// running with hostPID. It can be changed for runtimes with a different PID setup.
+var MountInfoPath = filepath.Join(DefaultProcFS, "1", "mountinfo")
+
+// ProbeMountInfo - fetches the list of mounted filesystems on particular node
+func ProbeMountInfo() ([]MountInfo, error) {
+	f, err := os.Open(MountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseMountInfo(f, MountInfoPath)
+}
+
+// IsBindMountOf checks if the target is the bind mount of the source directory
+func IsBindMountOf(source, target string) (bool, error) {
+	mounts, err := ProbeMountInfo()
+	if err != nil {
+		return false, err
+	}
+	return isBindMountOf(mounts, source, target), nil
+}
+
+// isBindMountOf checks if the filesystem mounted at the target is rooted at the
+// source, i.e. the source path is reachable through another mount of the same device
+func isBindMountOf(mounts []MountInfo, source, target string) bool {
+	source = filepath.Clean(source)
+	for _, t := range mounts {
+		if t.Mountpoint != filepath.Clean(target) {
+			continue
+		}
+		for _, m := range mounts {
+			if m.Major != t.Major || m.Minor != t.Minor || m.Mountpoint == t.Mountpoint {
+				continue
+			}
+			rel, err := filepath.Rel(m.MountRoot, t.MountRoot)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+				continue
+			}
+			if filepath.Join(m.Mountpoint, rel) == source {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasProjectQuota checks if the path is on a xfs filesystem mounted with project quotas, the
+// filesystem of the path is the one mounted at the longest mountpoint containing it
+func hasProjectQuota(mounts []MountInfo, path string) bool {
+	path = filepath.Clean(path)
+	var mount *MountInfo
+	for i, m := range mounts {
+		rel, err := filepath.Rel(m.Mountpoint, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		// the later entries are mounted on top of the earlier ones
+		if mount == nil || len(m.Mountpoint) >= len(mount.Mountpoint) {
+			mount = &mounts[i]
+		}
+	}
+	if mount == nil || mount.FSType != string(FSTypeXFS) {
+		return false
+	}
+	for _, opt := range mount.SuperblockOptions {
+		switch opt {
+		case "prjquota", "pquota", "pqnoenforce":
+			return true
+		}
+	}
+	return false
+}
+
+// DriveMountOptions keeps the mount options recorded from the mountinfo which can be passed to Mount
+// for a drive, e.g. "rw" or the propagation flags are dropped
+func DriveMountOptions(mountOpts []string) []string {
+	driveMountOpts := []string{}
+	for _, opt := range mountOpts {
+		switch MountOption(opt) {
+		case MountOptionMSDirSync,
+			MountOptionMSMandLock,
+			MountOptionMSNoATime,
+			MountOptionMSNoDev,
+			MountOptionMSNoDirATime,
+			MountOptionMSNoExec,
+			MountOptionMSNoSUID,
+			MountOptionMSReadOnly,
+			MountOptionMSRelatime,
+			MountOptionMSStrictATime,
+			MountOptionMSSynchronous:
+			driveMountOpts = append(driveMountOpts, opt)
+		}
+	}
+	return driveMountOpts
+}
+
+// unescapeMountInfoField decodes the octal escapes of space, tab, newline and
+// backslash in the paths of mountinfo
+func unescapeMountInfoField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var sb strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(field[i])
+	}
+	return sb.String()
+}
+
+func parseMountInfo(r io.Reader, mountinfoFile string) ([]MountInfo, error) {
+	mounts := []MountInfo{}
+	fbuf := bufio.NewReader(r)
+
+	for {
+		line, err := fbuf.ReadString(byte('\n'))
+		if err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+			if line == "" {
+				break
+			}
+		}
+		// the optional fields are terminated by a single hyphen
+		fields := strings.Fields(line)
+		separator := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				separator = i
+				break
+			}
+		}
+		if separator == -1 {
+			return nil, fmt.Errorf("invalid format of %s", mountinfoFile)
+		}
+		firstParts, secondParts := fields[:separator], fields[separator+1:]
+		mID, err := strconv.ParseUint(firstParts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format of %s", mountinfoFile)
+		}
+		mountID := uint32(mID)
+
+		pID, err := strconv.ParseUint(firstParts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format of %s", mountinfoFile)
+		}
+		parentID := uint32(pID)
+
+		majorMinorParts := strings.Split(firstParts[2], ":")
+		if len(majorMinorParts) != 2 {
+			return nil, fmt.Errorf("invalid 'major:minor' format in %s", mountinfoFile)
+		}
+		majorNumber, err := strconv.ParseUint(majorMinorParts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse the major number in %s", mountinfoFile)
+		}
+		minorNumber, err := strconv.ParseUint(majorMinorParts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse the minor number in %s", mountinfoFile)
+		}
+
+		mountRoot := unescapeMountInfoField(firstParts[3])
+		mountPoint := unescapeMountInfoField(firstParts[4])
+		mountOptions := firstParts[5]
+		optionalFields := firstParts[6:]
+		mountFlags := strings.Split(mountOptions, ",")
+
+		if len(secondParts) < 3 {
+			return nil, fmt.Errorf("invalid format of %s", mountinfoFile)
+		}
+
+		fsType := secondParts[0]
+		mountSource := unescapeMountInfoField(secondParts[1])
+		superblockOptions := strings.Split(secondParts[2], ",")
+
+		mounts = append(mounts, MountInfo{
+			Mountpoint:        mountPoint,
+			MountFlags:        mountFlags,
+			MountRoot:         mountRoot,
+			MountID:           mountID,
+			ParentID:          parentID,
+			MountSource:       mountSource,
+			SuperblockOptions: superblockOptions,
+			FSType:            fsType,
+			OptionalFields:    optionalFields,
+			Major:             uint32(majorNumber),
+			Minor:             uint32(minorNumber),
+		})
+	}
+	return mounts, nil
+}
@@ -0,0 +1,97 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const sysClassBlock = "/sys/class/block"
+
+// NVMeInfo holds the attributes of an NVMe namespace and its controller
+type NVMeInfo struct {
+	Model            string
+	FirmwareRevision string
+	ControllerSerial string
+	NamespaceID      int
+	// SharedNamespace is set if the namespace is reachable through more than one controller (multi-path)
+	SharedNamespace bool
+}
+
+// readNVMeInfo reads the NVMe attributes of the device from the sysfs block directory,
+// the attributes are empty for the devices which are not NVMe namespaces
+func readNVMeInfo(sysfsBlockDir, devname string) (NVMeInfo, error) {
+	if !strings.HasPrefix(devname, "nvme") {
+		return NVMeInfo{}, nil
+	}
+
+	readAttribute := func(paths ...string) (string, error) {
+		for _, path := range paths {
+			value, err := ioutil.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return "", err
+			}
+			return strings.TrimSpace(string(value)), nil
+		}
+		return "", nil
+	}
+
+	deviceDir := filepath.Join(sysfsBlockDir, devname, "device")
+	model, err := readAttribute(filepath.Join(deviceDir, "model"))
+	if err != nil {
+		return NVMeInfo{}, err
+	}
+	firmwareRevision, err := readAttribute(filepath.Join(deviceDir, "firmware_rev"))
+	if err != nil {
+		return NVMeInfo{}, err
+	}
+	controllerSerial, err := readAttribute(filepath.Join(deviceDir, "serial"))
+	if err != nil {
+		return NVMeInfo{}, err
+	}
+	nsid, err := readAttribute(filepath.Join(sysfsBlockDir, devname, "nsid"), filepath.Join(deviceDir, "nsid"))
+	if err != nil {
+		return NVMeInfo{}, err
+	}
+	namespaceID := 0
+	if nsid != "" {
+		if namespaceID, err = strconv.Atoi(nsid); err != nil {
+			return NVMeInfo{}, err
+		}
+	}
+
+	// the native NVMe multipath links the paths of a shared namespace, e.g. nvme0c0n1 and nvme0c1n1, under the namespace
+	paths, err := ioutil.ReadDir(filepath.Join(sysfsBlockDir, devname, "multipath"))
+	if err != nil && !os.IsNotExist(err) {
+		return NVMeInfo{}, err
+	}
+
+	return NVMeInfo{
+		Model:            model,
+		FirmwareRevision: firmwareRevision,
+		ControllerSerial: controllerSerial,
+		NamespaceID:      namespaceID,
+		SharedNamespace:  len(paths) > 1,
+	}, nil
+}
@@ -23,7 +23,7 @@ import (
 	"os"
 
 	"golang.org/x/sys/unix"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 
 	"github.com/minio/direct-csi/pkg/sys/gpt"
 	"github.com/minio/direct-csi/pkg/sys/mbr"
@@ -225,8 +225,7 @@ func (b *BlockDevice) probeGPT(ctx context.Context) ([]Partition, error) {
 	}
 	defer devFile.Close()
 
-	gptPart := &gpt.GPTHeader{}
-	err = binary.Read(devFile, binary.LittleEndian, gptPart)
+	gptPart, err := gpt.ReadHeader(devFile, b.LogicalBlockSize)
 	if err != nil {
 		return nil, err
 	}
@@ -235,56 +234,33 @@ func (b *BlockDevice) probeGPT(ctx context.Context) ([]Partition, error) {
 		return nil, ErrNotGPT
 	}
 
-	// Skip 420 bytes of reserved space
-	_, err = devFile.Seek(int64(420), os.SEEK_CUR)
+	entries, err := gpt.ReadPartitionEntries(devFile, gptPart, b.LogicalBlockSize)
 	if err != nil {
-		klog.Errorf("GPT header is corrupt")
+		klog.Errorf("GPT partition entries are corrupt: %v", err)
 		return nil, err
 	}
 
-	var offset int64
-	pSize := gptPart.PartitionEntrySize
-	lbaSize := 48 // manually calculated based on struct definition
-	if int64(pSize) > int64(lbaSize) {
-		offset = int64(pSize) - int64(lbaSize)
-	}
-
 	partitions := []Partition{}
-	for i := uint32(0); i < gptPart.NumPartitionEntries; i++ {
-		lba := &gpt.GPTLBA{}
-		err = binary.Read(devFile, binary.LittleEndian, lba)
-		if err != nil {
-			return nil, err
-		}
-
-		_, err := devFile.Seek(offset, os.SEEK_CUR)
-		if err != nil {
-			klog.Errorf("LBA data is corrupt")
-			return nil, err
-		}
-
-		// Only true after all the valid partition entries have been read
-		if !lba.Is() {
-			break
-		}
-
-		partTypeUUID := stringifyUUID(lba.PartitionType)
+	for _, entry := range entries {
+		partTypeUUID := stringifyUUID(entry.PartitionType)
 		partType := gpt.PartitionTypes[partTypeUUID]
 		if partType == "" {
 			partType = partTypeUUID
 		}
 
-		partNum := b.Minor + uint32(i+1)
-		partitionPath := fmt.Sprintf("%s%s%d", b.Path, DirectCSIPartitionInfix, i+1)
+		partNum := b.Minor + entry.Number
+		partitionPath := fmt.Sprintf("%s%s%d", b.Path, DirectCSIPartitionInfix, entry.Number)
 
+		// the ending LBA is inclusive
+		numBlocks := entry.End - entry.Start + 1
 		part := Partition{
 			DriveInfo: &DriveInfo{
 				LogicalBlockSize:  b.LogicalBlockSize,
 				PhysicalBlockSize: b.PhysicalBlockSize,
-				StartBlock:        lba.Start,
-				EndBlock:          lba.End,
-				TotalCapacity:     (lba.End - lba.Start) * b.LogicalBlockSize,
-				NumBlocks:         lba.End - lba.Start,
+				StartBlock:        entry.Start,
+				EndBlock:          entry.End,
+				TotalCapacity:     numBlocks * b.LogicalBlockSize,
+				NumBlocks:         numBlocks,
 				Path:              partitionPath,
 				Major:             b.DriveInfo.Major,
 				Minor:             uint32(partNum),
@@ -292,7 +268,7 @@ func (b *BlockDevice) probeGPT(ctx context.Context) ([]Partition, error) {
 			PartitionNum:  uint32(partNum),
 			Type:          partType,
 			TypeUUID:      partTypeUUID,
-			PartitionGUID: stringifyUUID(lba.PartitionGUID),
+			PartitionGUID: stringifyUUID(entry.PartitionGUID),
 			DiskGUID:      stringifyUUID(gptPart.DiskGUID),
 		}
 		partitions = append(partitions, part)
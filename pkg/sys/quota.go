@@ -0,0 +1,60 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minio/direct-csi/pkg/sys/fs"
+	"github.com/minio/direct-csi/pkg/sys/fs/ext4"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
+)
+
+// getQuota picks the quota implementation of the filesystem the path is mounted on
+func getQuota(mounts []MountInfo, path, projectID string) (fs.Quota, error) {
+	var mountInfo *MountInfo
+	for i := range mounts {
+		mountpoint := mounts[i].Mountpoint
+		if path != mountpoint && !strings.HasPrefix(path, strings.TrimSuffix(mountpoint, "/")+"/") {
+			continue
+		}
+		// the innermost mount decides the filesystem
+		if mountInfo == nil || len(mountpoint) >= len(mountInfo.Mountpoint) {
+			mountInfo = &mounts[i]
+		}
+	}
+	if mountInfo == nil {
+		return nil, fmt.Errorf("no mount found for %s", path)
+	}
+
+	switch FSType(mountInfo.FSType) {
+	case FSTypeXFS:
+		return &xfs.XFSQuota{
+			Path:      path,
+			ProjectID: projectID,
+		}, nil
+	case FSTypeEXT4:
+		return &ext4.EXT4Quota{
+			Path:       path,
+			Mountpoint: mountInfo.Mountpoint,
+			ProjectID:  projectID,
+		}, nil
+	default:
+		return nil, fmt.Errorf("quota is not supported on %s filesystem", mountInfo.FSType)
+	}
+}
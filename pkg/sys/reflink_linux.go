@@ -0,0 +1,46 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// IsReflinkSupported reports if the XFS filesystem mounted at mountPoint can share extents between files
+func IsReflinkSupported(ctx context.Context, mountPoint string) (bool, error) {
+	outputBytes, err := exec.CommandContext(ctx, "xfs_info", mountPoint).CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("unable to read xfs info of %v; %v: %v", mountPoint, err, string(outputBytes))
+	}
+	return parseXFSReflink(string(outputBytes)), nil
+}
+
+// ReflinkCopy clones the directory src to dest by sharing the extents, it fails instead of falling back to a full copy
+func ReflinkCopy(ctx context.Context, src, dest string) (string, error) {
+	cmd := exec.CommandContext(ctx, "cp", "-a", "--reflink=always", src, dest)
+	outputBytes, err := cmd.CombinedOutput()
+	return string(outputBytes), err
+}
+
+// CopyDir copies the contents of the directory src into dest preserving the ownership, modes and timestamps
+func CopyDir(ctx context.Context, src, dest string) (string, error) {
+	cmd := exec.CommandContext(ctx, "cp", "-a", src+"/.", dest)
+	outputBytes, err := cmd.CombinedOutput()
+	return string(outputBytes), err
+}
@@ -0,0 +1,36 @@
+// +build !linux
+
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"context"
+	"errors"
+)
+
+func IsReflinkSupported(ctx context.Context, mountPoint string) (bool, error) {
+	return false, nil
+}
+
+func ReflinkCopy(ctx context.Context, src, dest string) (string, error) {
+	return "", errors.New("reflink is not supported on this platform")
+}
+
+func CopyDir(ctx context.Context, src, dest string) (string, error) {
+	return "", errors.New("copying directories is not supported on this platform")
+}
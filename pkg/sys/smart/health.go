@@ -0,0 +1,139 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+const (
+	// smartctl exit status bits of a command line which did not parse and of a device which could not be opened,
+	// the other bits report the health of the drive along with a valid output
+	smartctlParseFailed = 1 << 0
+	smartctlOpenFailed  = 1 << 1
+
+	// reallocatedSectorCountID is the ATA attribute of the count of reallocated sectors
+	reallocatedSectorCountID = 5
+
+	// smartctlTimeout bounds a smartctl run so that a hung device does not block the collection of the other drives
+	smartctlTimeout = 30 * time.Second
+)
+
+// ErrSmartctlNotFound is returned when smartctl is not installed
+var ErrSmartctlNotFound = errors.New("smartctl not found")
+
+// Health is the health self-assessment, temperature and wear of a drive read by smartctl
+type Health struct {
+	Passed bool
+	// Temperature is the current temperature in Celsius
+	Temperature int64
+	// ReallocatedSectors is the count of reallocated sectors of ATA drives or the grown defects of SCSI drives
+	ReallocatedSectors int64
+	// PercentageUsed is the estimated percentage of the endurance used of NVMe and SCSI SSDs, -1 if not reported
+	PercentageUsed int64
+}
+
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int64 `json:"current"`
+	} `json:"temperature"`
+	ATASmartAttributes struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeSmartHealthInformationLog *struct {
+		PercentageUsed int64 `json:"percentage_used"`
+	} `json:"nvme_smart_health_information_log"`
+	SCSIGrownDefectList         int64  `json:"scsi_grown_defect_list"`
+	SCSIPercentageUsedIndicator *int64 `json:"scsi_percentage_used_endurance_indicator"`
+}
+
+// parseSmartctlOutput reads the health from the JSON output of smartctl
+func parseSmartctlOutput(output []byte) (*Health, error) {
+	var out smartctlOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return nil, fmt.Errorf("unable to parse smartctl output; %v", err)
+	}
+	if out.Smartctl.ExitStatus&(smartctlParseFailed|smartctlOpenFailed) != 0 {
+		for _, message := range out.Smartctl.Messages {
+			if message.Severity == "error" {
+				return nil, fmt.Errorf("smartctl failed; %v", message.String)
+			}
+		}
+		return nil, fmt.Errorf("smartctl failed with exit status %v", out.Smartctl.ExitStatus)
+	}
+	if out.SmartStatus == nil {
+		return nil, errors.New("SMART is not supported by the drive")
+	}
+
+	health := &Health{
+		Passed:             out.SmartStatus.Passed,
+		Temperature:        out.Temperature.Current,
+		ReallocatedSectors: out.SCSIGrownDefectList,
+		PercentageUsed:     -1,
+	}
+	for _, attribute := range out.ATASmartAttributes.Table {
+		if attribute.ID == reallocatedSectorCountID {
+			health.ReallocatedSectors = attribute.Raw.Value
+		}
+	}
+	switch {
+	case out.NVMeSmartHealthInformationLog != nil:
+		health.PercentageUsed = out.NVMeSmartHealthInformationLog.PercentageUsed
+	case out.SCSIPercentageUsedIndicator != nil:
+		health.PercentageUsed = *out.SCSIPercentageUsedIndicator
+	}
+	return health, nil
+}
+
+// GetHealth reads the health of the drive by smartctl, ErrSmartctlNotFound is returned if it is not installed
+func GetHealth(ctx context.Context, devicePath string) (*Health, error) {
+	bin, err := exec.LookPath("smartctl")
+	if err != nil {
+		return nil, ErrSmartctlNotFound
+	}
+	ctx, cancel := context.WithTimeout(ctx, smartctlTimeout)
+	defer cancel()
+	// the exit status is a bit mask which is also reported in the output
+	output, err := exec.CommandContext(ctx, bin, "--json", "--health", "--attributes", devicePath).Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("smartctl did not finish within %v", smartctlTimeout)
+	}
+	if err != nil && len(output) == 0 {
+		return nil, err
+	}
+	return parseSmartctlOutput(output)
+}
@@ -0,0 +1,86 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package smart
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSmartctlOutput(t *testing.T) {
+	testCases := []struct {
+		name         string
+		output       string
+		expected     *Health
+		expectedFail bool
+	}{
+		{
+			name: "ata",
+			output: `{"smartctl": {"exit_status": 0}, "smart_status": {"passed": true}, "temperature": {"current": 34},
+				"ata_smart_attributes": {"table": [{"id": 1, "raw": {"value": 7}}, {"id": 5, "raw": {"value": 12}}]}}`,
+			expected: &Health{Passed: true, Temperature: 34, ReallocatedSectors: 12, PercentageUsed: -1},
+		},
+		{
+			name: "nvme",
+			output: `{"smartctl": {"exit_status": 0}, "smart_status": {"passed": true}, "temperature": {"current": 41},
+				"nvme_smart_health_information_log": {"percentage_used": 3}}`,
+			expected: &Health{Passed: true, Temperature: 41, PercentageUsed: 3},
+		},
+		{
+			name: "scsi",
+			output: `{"smartctl": {"exit_status": 0}, "smart_status": {"passed": true}, "temperature": {"current": 29},
+				"scsi_grown_defect_list": 4, "scsi_percentage_used_endurance_indicator": 10}`,
+			expected: &Health{Passed: true, Temperature: 29, ReallocatedSectors: 4, PercentageUsed: 10},
+		},
+		{
+			name:     "failing",
+			output:   `{"smartctl": {"exit_status": 8}, "smart_status": {"passed": false}, "temperature": {"current": 55}}`,
+			expected: &Health{Passed: false, Temperature: 55, PercentageUsed: -1},
+		},
+		{
+			name:         "openFailed",
+			output:       `{"smartctl": {"exit_status": 2, "messages": [{"string": "Permission denied", "severity": "error"}]}}`,
+			expectedFail: true,
+		},
+		{
+			name:         "unsupported",
+			output:       `{"smartctl": {"exit_status": 4}}`,
+			expectedFail: true,
+		},
+		{
+			name:         "invalid",
+			output:       `smartctl 6.6`,
+			expectedFail: true,
+		},
+	}
+	for _, testCase := range testCases {
+		health, err := parseSmartctlOutput([]byte(testCase.output))
+		if testCase.expectedFail {
+			if err == nil {
+				t.Errorf("case %v: expected error, got none", testCase.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %v: unexpected error: %v", testCase.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(health, testCase.expected) {
+			t.Errorf("case %v: expected: %+v, got: %+v", testCase.name, testCase.expected, health)
+		}
+	}
+}
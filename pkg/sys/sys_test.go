@@ -17,7 +17,24 @@
 package sys
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/minio/direct-csi/pkg/sys/fs/ext4"
+	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetBlockFile(t1 *testing.T) {
@@ -179,3 +196,1603 @@ func TestGetRootBlockFile(t1 *testing.T) {
 	}
 
 }
+
+func TestCheckMountpointFree(t1 *testing.T) {
+	mounts := []MountInfo{
+		{Mountpoint: "/var/lib/direct-csi/mnt/drive-1", Major: 8, Minor: 16},
+		{Mountpoint: "/var/lib/direct-csi/mnt/drive-2", Major: 8, Minor: 32},
+	}
+
+	testCases := []struct {
+		name        string
+		target      string
+		major       uint32
+		minor       uint32
+		expectedErr bool
+	}{
+		{
+			name:        "test1",
+			target:      "/var/lib/direct-csi/mnt/drive-3",
+			major:       8,
+			minor:       48,
+			expectedErr: false,
+		},
+		{
+			name:        "test2",
+			target:      "/var/lib/direct-csi/mnt/drive-1",
+			major:       8,
+			minor:       16,
+			expectedErr: false,
+		},
+		{
+			name:        "test3",
+			target:      "/var/lib/direct-csi/mnt/drive-1/",
+			major:       8,
+			minor:       32,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := CheckMountpointFree(mounts, tt.target, tt.major, tt.minor)
+			if tt.expectedErr {
+				if !errors.Is(err, ErrMountpointInUse) {
+					t1.Errorf("Test case name %s: Expected error %v but got %v", tt.name, ErrMountpointInUse, err)
+				}
+			} else if err != nil {
+				t1.Errorf("Test case name %s: Unexpected error %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestParseDiskStats(t1 *testing.T) {
+	diskstats := `   8       0 sda 4961 1403 466198 2375 3155 2441 62962 2768 0 3716 5144 0 0 0 0
+   8       1 sda1 4883 1403 462390 2358 3155 2441 62962 2768 0 3696 5127 0 0 0 0
+ 259       0 nvme0n1 117 0 6442 24 8 0 64 1 0 40 25
+`
+	stats, err := parseDiskStats(strings.NewReader(diskstats))
+	if err != nil {
+		t1.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []DiskStats{
+		{Major: 8, Minor: 0, Name: "sda", ReadBytes: 466198 * 512, WriteBytes: 62962 * 512},
+		{Major: 8, Minor: 1, Name: "sda1", ReadBytes: 462390 * 512, WriteBytes: 62962 * 512},
+		{Major: 259, Minor: 0, Name: "nvme0n1", ReadBytes: 6442 * 512, WriteBytes: 64 * 512},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t1.Errorf("Expected diskstats = %v, got %v", expected, stats)
+	}
+
+	if _, err := parseDiskStats(strings.NewReader("8 0 sda 1 2 3\n")); err == nil {
+		t1.Errorf("Expected error for truncated diskstats but succeeded")
+	}
+}
+
+func TestGetQuota(t1 *testing.T) {
+	mounts := []MountInfo{
+		{Mountpoint: "/", FSType: "overlay"},
+		{Mountpoint: "/var/lib/direct-csi/mnt/xfs-drive", FSType: "xfs"},
+		{Mountpoint: "/var/lib/direct-csi/mnt/ext4-drive", FSType: "ext4"},
+		{Mountpoint: "/var/lib/kubelet/plugins/volume-1", FSType: "ext4"},
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		expectedQuota interface{}
+		expectErr     bool
+	}{
+		{
+			name:          "test1",
+			path:          "/var/lib/direct-csi/mnt/xfs-drive/volume-1",
+			expectedQuota: &xfs.XFSQuota{Path: "/var/lib/direct-csi/mnt/xfs-drive/volume-1", ProjectID: "volume-1"},
+		},
+		{
+			name: "test2",
+			path: "/var/lib/direct-csi/mnt/ext4-drive/volume-1",
+			expectedQuota: &ext4.EXT4Quota{
+				Path:       "/var/lib/direct-csi/mnt/ext4-drive/volume-1",
+				Mountpoint: "/var/lib/direct-csi/mnt/ext4-drive",
+				ProjectID:  "volume-1",
+			},
+		},
+		{
+			name: "test3",
+			path: "/var/lib/kubelet/plugins/volume-1",
+			expectedQuota: &ext4.EXT4Quota{
+				Path:       "/var/lib/kubelet/plugins/volume-1",
+				Mountpoint: "/var/lib/kubelet/plugins/volume-1",
+				ProjectID:  "volume-1",
+			},
+		},
+		{
+			name:      "test4",
+			path:      "/var/lib/direct-csi/mnt/xfs-drive-2/volume-1",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			quota, err := getQuota(mounts, tt.path, "volume-1")
+			if tt.expectErr {
+				if err == nil {
+					t1.Errorf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(quota, tt.expectedQuota) {
+				t1.Errorf("Test case name %s: expected quota %+v but got %+v", tt.name, tt.expectedQuota, quota)
+			}
+		})
+	}
+}
+
+func TestDeviceCache(t1 *testing.T) {
+	probes := 0
+	cache := &DeviceCache{
+		probe: func(ctx context.Context, loopBackOnly bool) ([]BlockDevice, error) {
+			probes++
+			return []BlockDevice{{Devname: fmt.Sprintf("sd%d", probes)}}, nil
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		invalidate      bool
+		maxAge          time.Duration
+		expectedProbes  int
+		expectedDevname string
+	}{
+		{
+			name:            "test1",
+			maxAge:          time.Hour,
+			expectedProbes:  1,
+			expectedDevname: "sd1",
+		},
+		{
+			name:            "test2",
+			maxAge:          time.Hour,
+			expectedProbes:  1,
+			expectedDevname: "sd1",
+		},
+		{
+			name:            "test3",
+			invalidate:      true,
+			maxAge:          time.Hour,
+			expectedProbes:  2,
+			expectedDevname: "sd2",
+		},
+		{
+			name:            "test4",
+			maxAge:          -1,
+			expectedProbes:  3,
+			expectedDevname: "sd3",
+		},
+	}
+
+	for _, tt := range testCases {
+		if tt.invalidate {
+			cache.Invalidate()
+		}
+		devices, err := cache.Get(context.Background(), tt.maxAge)
+		if err != nil {
+			t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+		}
+		if probes != tt.expectedProbes {
+			t1.Errorf("Test case name %s: expected %d probes but got %d", tt.name, tt.expectedProbes, probes)
+		}
+		if len(devices) != 1 || devices[0].Devname != tt.expectedDevname {
+			t1.Errorf("Test case name %s: expected device %s but got %v", tt.name, tt.expectedDevname, devices)
+		}
+	}
+}
+
+func TestReadNVMeInfo(t1 *testing.T) {
+	sysfsBlockDir := t1.TempDir()
+	writeAttribute := func(path, value string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t1.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme0n1", "device", "model"), "Samsung SSD 970 EVO Plus 1TB           \n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme0n1", "device", "firmware_rev"), "2B2QEXM7\n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme0n1", "device", "serial"), "S4EWNX0R123456A     \n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme0n1", "nsid"), "1\n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme1n2", "device", "model"), "INTEL SSDPE2KX010T8\n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme1n2", "device", "nsid"), "2\n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme1n2", "multipath", "nvme1c1n2"), "")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme1n2", "multipath", "nvme1c2n2"), "")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme2n1", "device", "nsid"), "1\n")
+	writeAttribute(filepath.Join(sysfsBlockDir, "nvme2n1", "multipath", "nvme2c2n1"), "")
+	writeAttribute(filepath.Join(sysfsBlockDir, "sda", "device", "model"), "QEMU HARDDISK\n")
+
+	testCases := []struct {
+		name         string
+		devname      string
+		expectedInfo NVMeInfo
+	}{
+		{
+			name:    "test1",
+			devname: "nvme0n1",
+			expectedInfo: NVMeInfo{
+				Model:            "Samsung SSD 970 EVO Plus 1TB",
+				FirmwareRevision: "2B2QEXM7",
+				ControllerSerial: "S4EWNX0R123456A",
+				NamespaceID:      1,
+			},
+		},
+		{
+			name:    "test2",
+			devname: "nvme1n2",
+			expectedInfo: NVMeInfo{
+				Model:           "INTEL SSDPE2KX010T8",
+				NamespaceID:     2,
+				SharedNamespace: true,
+			},
+		},
+		{
+			name:    "test4",
+			devname: "nvme2n1",
+			expectedInfo: NVMeInfo{
+				NamespaceID: 1,
+			},
+		},
+		{
+			name:         "test3",
+			devname:      "sda",
+			expectedInfo: NVMeInfo{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			nvmeInfo, err := readNVMeInfo(sysfsBlockDir, tt.devname)
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(nvmeInfo, tt.expectedInfo) {
+				t1.Errorf("Test case name %s: expected %+v but got %+v", tt.name, tt.expectedInfo, nvmeInfo)
+			}
+		})
+	}
+}
+
+func TestReadMajorMinor(t1 *testing.T) {
+	sysfsBlockDir := t1.TempDir()
+	writeDev := func(devname, value string) {
+		if err := os.MkdirAll(filepath.Join(sysfsBlockDir, devname), 0755); err != nil {
+			t1.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(sysfsBlockDir, devname, "dev"), []byte(value), 0644); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	writeDev("xvdb", "202:16\n")
+	writeDev("nvme0n1p1", "259:1\n")
+	writeDev("sdz", "invalid\n")
+
+	testCases := []struct {
+		name          string
+		devname       string
+		expectedMajor uint32
+		expectedMinor uint32
+		expectErr     bool
+	}{
+		{
+			name:          "test1",
+			devname:       "xvdb",
+			expectedMajor: 202,
+			expectedMinor: 16,
+		},
+		{
+			name:          "test2",
+			devname:       "nvme0n1p1",
+			expectedMajor: 259,
+			expectedMinor: 1,
+		},
+		{
+			name:      "test3",
+			devname:   "sdz",
+			expectErr: true,
+		},
+		{
+			name:      "test4",
+			devname:   "sdy",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			major, minor, err := readMajorMinor(sysfsBlockDir, tt.devname)
+			if tt.expectErr {
+				if err == nil {
+					t1.Fatalf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if major != tt.expectedMajor || minor != tt.expectedMinor {
+				t1.Errorf("Test case name %s: expected %d:%d but got %d:%d", tt.name, tt.expectedMajor, tt.expectedMinor, major, minor)
+			}
+		})
+	}
+}
+
+func TestParseSwaps(t1 *testing.T) {
+	swaps := `Filename				Type		Size		Used		Priority
+/dev/sda3                               partition	8388604		0		-2
+/swapfile                               file		2097148		0		-3
+/dev/dm-1                               partition	4194300		0		-4
+`
+	devices, err := parseSwaps(strings.NewReader(swaps))
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+	expectedDevices := []string{"/dev/sda3", "/dev/dm-1"}
+	if !reflect.DeepEqual(devices, expectedDevices) {
+		t1.Errorf("expected %v but got %v", expectedDevices, devices)
+	}
+}
+
+func TestCollectDisks(t1 *testing.T) {
+	parents := map[string]string{
+		"sda":       "",
+		"sda1":      "sda",
+		"sda2":      "sda",
+		"sdb":       "",
+		"nvme0n1":   "",
+		"nvme0n1p1": "nvme0n1",
+		"nvme1n1":   "",
+		"dm-0":      "",
+	}
+	slaves := map[string][]string{
+		"dm-0": {"nvme0n1p1", "nvme1n1"},
+	}
+
+	testCases := []struct {
+		name          string
+		names         []string
+		expectedDisks map[string]bool
+	}{
+		{
+			name:          "test1",
+			names:         []string{"sda2"},
+			expectedDisks: map[string]bool{"sda": true},
+		},
+		{
+			name:          "test2",
+			names:         []string{"sdb", "sda1"},
+			expectedDisks: map[string]bool{"sda": true, "sdb": true},
+		},
+		{
+			name:          "test3",
+			names:         []string{"dm-0"},
+			expectedDisks: map[string]bool{"nvme0n1": true, "nvme1n1": true},
+		},
+		{
+			name:          "test4",
+			names:         []string{},
+			expectedDisks: map[string]bool{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			disks := collectDisks(tt.names, parents, slaves)
+			if !reflect.DeepEqual(disks, tt.expectedDisks) {
+				t1.Errorf("Test case name %s: expected %v but got %v", tt.name, tt.expectedDisks, disks)
+			}
+		})
+	}
+}
+
+func TestFindMountHolders(t1 *testing.T) {
+	procFS, err := ioutil.TempDir("", "proc-")
+	if err != nil {
+		t1.Fatalf("unable to create temporary directory; %v", err)
+	}
+	defer os.RemoveAll(procFS)
+
+	processes := []struct {
+		pid       string
+		comm      string
+		mountinfo string
+	}{
+		{
+			pid:       "1",
+			comm:      "systemd",
+			mountinfo: "26 1 259:2 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p2 rw\n120 26 8:16 / /var/lib/direct-csi/mnt/abc rw,relatime shared:60 - xfs /dev/sdb rw\n",
+		},
+		{
+			pid:       "4242",
+			comm:      "nginx",
+			mountinfo: "300 299 0:52 / / rw,relatime - overlay overlay rw\n310 300 8:16 / /data rw,relatime - xfs /dev/sdb rw\n",
+		},
+		{
+			pid:       "4343",
+			comm:      "bash",
+			mountinfo: "400 399 0:60 / / rw,relatime - overlay overlay rw\n410 400 8:16 /volume /data rw,relatime - xfs /dev/sdb rw\n",
+		},
+	}
+	for _, process := range processes {
+		pidDir := filepath.Join(procFS, process.pid)
+		if err := os.MkdirAll(pidDir, 0755); err != nil {
+			t1.Fatalf("unable to create %s; %v", pidDir, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(pidDir, "comm"), []byte(process.comm+"\n"), 0644); err != nil {
+			t1.Fatalf("unable to write comm; %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(pidDir, "mountinfo"), []byte(process.mountinfo), 0644); err != nil {
+			t1.Fatalf("unable to write mountinfo; %v", err)
+		}
+	}
+
+	holders, err := findMountHolders(procFS, 8, 16, "/")
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+	expectedHolders := []string{"1 (systemd)", "4242 (nginx)"}
+	if !reflect.DeepEqual(holders, expectedHolders) {
+		t1.Errorf("expected %v but got %v", expectedHolders, holders)
+	}
+}
+
+func TestRunWithContext(t1 *testing.T) {
+	errMount := errors.New("mount failed")
+	hung := make(chan struct{})
+	defer close(hung)
+
+	testCases := []struct {
+		name         string
+		timeout      time.Duration
+		fn           func() error
+		expectedErr  error
+		expectedCode codes.Code
+	}{
+		{"success", time.Second, func() error { return nil }, nil, codes.OK},
+		{"failure", time.Second, func() error { return errMount }, errMount, codes.Unknown},
+		{"hung", 10 * time.Millisecond, func() error { <-hung; return nil }, nil, codes.DeadlineExceeded},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			err := runWithContext(ctx, tt.fn)
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t1.Errorf("Test case name %s: expected error %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if code := status.Code(err); code != tt.expectedCode {
+				t1.Errorf("Test case name %s: expected code %v, got %v", tt.name, tt.expectedCode, code)
+			}
+		})
+	}
+}
+
+func TestParseXFSReflink(t1 *testing.T) {
+	testCases := []struct {
+		name     string
+		xfsInfo  string
+		expected bool
+	}{
+		{
+			name: "reflink_enabled",
+			xfsInfo: `meta-data=/dev/sdb               isize=512    agcount=4, agsize=655360 blks
+         =                       sectsz=512   attr=2, projid32bit=1
+         =                       crc=1        finobt=1, sparse=1, rmapbt=0
+         =                       reflink=1    bigtime=0 inobtcount=0
+data     =                       bsize=4096   blocks=2621440, imaxpct=25
+`,
+			expected: true,
+		},
+		{
+			name: "reflink_disabled",
+			xfsInfo: `meta-data=/dev/sdb               isize=512    agcount=4, agsize=655360 blks
+         =                       crc=1        finobt=1, sparse=1, rmapbt=0
+         =                       reflink=0
+`,
+			expected: false,
+		},
+		{
+			name:     "reflink_unknown",
+			xfsInfo:  `meta-data=/dev/sdb               isize=256    agcount=4, agsize=655360 blks`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if supported := parseXFSReflink(tt.xfsInfo); supported != tt.expected {
+				t1.Errorf("Test case name %s: expected reflink support = %v, got %v", tt.name, tt.expected, supported)
+			}
+		})
+	}
+}
+
+func TestDeviceFilterMatch(t1 *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   DeviceFilter
+		paths    []string
+		expected bool
+	}{
+		{
+			name:     "empty_filter",
+			filter:   DeviceFilter{},
+			paths:    []string{"/dev/sdb"},
+			expected: true,
+		},
+		{
+			name:     "included",
+			filter:   DeviceFilter{Include: []string{"/dev/sd[b-z]"}},
+			paths:    []string{"/dev/sdc"},
+			expected: true,
+		},
+		{
+			name:     "not_included",
+			filter:   DeviceFilter{Include: []string{"/dev/sd[b-z]"}},
+			paths:    []string{"/dev/sda"},
+			expected: false,
+		},
+		{
+			name:     "partition_included_by_disk",
+			filter:   DeviceFilter{Include: []string{"/dev/sdb"}},
+			paths:    []string{"/dev/sdb1", "/dev/sdb"},
+			expected: true,
+		},
+		{
+			name:     "excluded",
+			filter:   DeviceFilter{Exclude: []string{"/dev/nvme0n1*"}},
+			paths:    []string{"/dev/nvme0n1p2", "/dev/nvme0n1"},
+			expected: false,
+		},
+		{
+			name:     "exclude_overrides_include",
+			filter:   DeviceFilter{Include: []string{"/dev/sd*"}, Exclude: []string{"/dev/sdb"}},
+			paths:    []string{"/dev/sdb"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if matched := tt.filter.Match(tt.paths...); matched != tt.expected {
+				t1.Errorf("Test case name %s: expected match = %v, got %v", tt.name, tt.expected, matched)
+			}
+		})
+	}
+
+	if err := (DeviceFilter{Include: []string{"/dev/sd["}}).Validate(); err == nil {
+		t1.Errorf("expected an error for the malformed pattern")
+	}
+
+	filter := DeviceFilter{MinSize: DefaultMinDriveSize}
+	if filter.MatchSize(1024 * 1024) {
+		t1.Errorf("expected the 1MiB device to be dropped by the minimum size")
+	}
+	if !filter.MatchSize(DefaultMinDriveSize) {
+		t1.Errorf("expected the device of the minimum size to be matched")
+	}
+	if !(DeviceFilter{}).MatchSize(0) {
+		t1.Errorf("expected any size to be matched without a minimum size")
+	}
+}
+
+func TestIsRAMDisk(t1 *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		major    uint32
+		expected bool
+	}{
+		{name: "ramdisk", path: "/dev/ram0", major: 1, expected: true},
+		{name: "zram", path: "/dev/zram0", major: 252, expected: true},
+		{name: "ramdisk_major", path: "/dev/foo", major: 1, expected: true},
+		{name: "disk", path: "/dev/sdb", major: 8, expected: false},
+		{name: "loop", path: "/dev/loop0", major: 7, expected: false},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if ramDisk := IsRAMDisk(tt.path, tt.major); ramDisk != tt.expected {
+				t1.Errorf("Test case name %s: expected RAM disk = %v, got %v", tt.name, tt.expected, ramDisk)
+			}
+		})
+	}
+}
+
+func TestHasMDSuperblock(t1 *testing.T) {
+	const size = 1024 * 1024
+	withSuperblock := func(offset int64, major uint32) []byte {
+		data := make([]byte, size)
+		sb := data[offset : offset+mdSuperblockSize]
+		binary.LittleEndian.PutUint32(sb, mdMagic)
+		binary.LittleEndian.PutUint32(sb[4:], major)
+		if major == 0 {
+			binary.LittleEndian.PutUint32(sb[8:], 90)
+			binary.LittleEndian.PutUint32(sb[152:], mdChecksum(sb, 152))
+		} else {
+			binary.LittleEndian.PutUint32(sb[220:], 4)
+			binary.LittleEndian.PutUint32(sb[216:], mdChecksum(sb[:256+4*2], 216))
+		}
+		return data
+	}
+	withMagic := func(offset int64) []byte {
+		data := make([]byte, size)
+		binary.LittleEndian.PutUint32(data[offset:], mdMagic)
+		return data
+	}
+	badChecksum := withSuperblock(4*1024, 1)
+	badChecksum[4*1024+100]++
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected bool
+	}{
+		{
+			name:     "no_superblock",
+			data:     make([]byte, size),
+			expected: false,
+		},
+		{
+			name:     "v1.1",
+			data:     withSuperblock(0, 1),
+			expected: true,
+		},
+		{
+			name:     "v1.2",
+			data:     withSuperblock(4*1024, 1),
+			expected: true,
+		},
+		{
+			name:     "v1.0",
+			data:     withSuperblock(size-8*1024, 1),
+			expected: true,
+		},
+		{
+			name:     "v0.90",
+			data:     withSuperblock(size-64*1024, 0),
+			expected: true,
+		},
+		{
+			name:     "magic_only",
+			data:     withMagic(4 * 1024),
+			expected: false,
+		},
+		{
+			name:     "bad_checksum",
+			data:     badChecksum,
+			expected: false,
+		},
+		{
+			name:     "unknown_version",
+			data:     withSuperblock(0, 2),
+			expected: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			found, err := hasMDSuperblock(bytes.NewReader(tt.data), 0, size)
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if found != tt.expected {
+				t1.Errorf("Test case name %s: expected md superblock = %v, got %v", tt.name, tt.expected, found)
+			}
+		})
+	}
+}
+
+func TestHasLUKSHeader(t1 *testing.T) {
+	withHeader := func(offset int64, version uint16) []byte {
+		data := make([]byte, 64*1024)
+		copy(data[offset:], luksMagic)
+		binary.BigEndian.PutUint16(data[offset+int64(len(luksMagic)):], version)
+		return data
+	}
+	testCases := []struct {
+		name     string
+		data     []byte
+		offset   int64
+		expected bool
+	}{
+		{name: "no_header", data: make([]byte, 64*1024), expected: false},
+		{name: "luks1", data: withHeader(0, 1), expected: true},
+		{name: "luks2", data: withHeader(0, 2), expected: true},
+		{name: "partition", data: withHeader(4096, 2), offset: 4096, expected: true},
+		{name: "unknown_version", data: withHeader(0, 3), expected: false},
+		{name: "short_device", data: luksMagic[:4], expected: false},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			found, err := hasLUKSHeader(bytes.NewReader(tt.data), tt.offset)
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if found != tt.expected {
+				t1.Errorf("Test case name %s: expected LUKS header = %v, got %v", tt.name, tt.expected, found)
+			}
+		})
+	}
+}
+
+func TestReadDeviceAttrs(t1 *testing.T) {
+	sysfsBlockDir := t1.TempDir()
+	writeAttr := func(devname, path, value string) {
+		attrPath := filepath.Join(sysfsBlockDir, devname, path)
+		if err := os.MkdirAll(filepath.Dir(attrPath), 0755); err != nil {
+			t1.Fatal(err)
+		}
+		if err := ioutil.WriteFile(attrPath, []byte(value), 0644); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	writeAttr("sda", "dev", "8:0\n")
+	writeAttr("sda", "queue/rotational", "1\n")
+	writeAttr("sda1", "dev", "8:1\n")
+	writeAttr("sda1", "partition", "1\n")
+	writeAttr("dm-0", "dev", "253:0\n")
+	writeAttr("dm-0", "dm/name", "luks-data\n")
+	writeAttr("dm-0", "dm/uuid", "CRYPT-LUKS2-data\n")
+	writeAttr("dm-0", "queue/rotational", "0\n")
+	writeAttr("md0", "dev", "9:0\n")
+	writeAttr("md0", "md/uuid", "5d1e6f2c:b8d6a9b0:1f3e4c2a:7a8b9c0d\n")
+	writeAttr("sdb", "dev", "8:16\n")
+	writeAttr("sdb", "device/wwid", "naa.5000c500a1b2c3d4\n")
+	writeAttr("nvme0n1", "dev", "259:0\n")
+	writeAttr("nvme0n1", "wwid", "eui.0025385b71b07e2f\n")
+	writeAttr("nvme1n1", "dev", "259:1\n")
+	writeAttr("nvme1n1", "queue/scheduler", "[none] mq-deadline kyber\n")
+	writeAttr("nvme1n1", "queue/nr_requests", "1023\n")
+	writeAttr("nvme1n1", "queue/read_ahead_kb", "128\n")
+	writeAttr("sdx", "dev", "8:32\n")
+	writeAttr("sdx", "queue/nr_requests", "many\n")
+	writeAttr("sdz", "dev", "invalid\n")
+	writeAttr("sdy", "partition", "1\n")
+
+	testCases := []struct {
+		name          string
+		devname       string
+		expectedDrive *drive
+		expectErr     bool
+	}{
+		{
+			name:          "rotational_disk",
+			devname:       "sda",
+			expectedDrive: &drive{name: "sda", major: 8, minor: 0, rotational: true},
+		},
+		{
+			name:          "partition",
+			devname:       "sda1",
+			expectedDrive: &drive{name: "sda1", major: 8, minor: 1, partition: 1},
+		},
+		{
+			name:          "dm_device",
+			devname:       "dm-0",
+			expectedDrive: &drive{name: "dm-0", major: 253, minor: 0, dmName: "luks-data", dmUUID: "CRYPT-LUKS2-data"},
+		},
+		{
+			name:          "md_device",
+			devname:       "md0",
+			expectedDrive: &drive{name: "md0", major: 9, minor: 0, mdUUID: "5d1e6f2c:b8d6a9b0:1f3e4c2a:7a8b9c0d"},
+		},
+		{
+			name:          "scsi_wwid",
+			devname:       "sdb",
+			expectedDrive: &drive{name: "sdb", major: 8, minor: 16, wwid: "naa.5000c500a1b2c3d4"},
+		},
+		{
+			name:          "nvme_wwid",
+			devname:       "nvme0n1",
+			expectedDrive: &drive{name: "nvme0n1", major: 259, minor: 0, wwid: "eui.0025385b71b07e2f"},
+		},
+		{
+			name:          "queue_tunables",
+			devname:       "nvme1n1",
+			expectedDrive: &drive{name: "nvme1n1", major: 259, minor: 1, ioScheduler: "none", nrRequests: 1023, readAheadKB: 128},
+		},
+		{
+			name:      "invalid_nr_requests",
+			devname:   "sdx",
+			expectErr: true,
+		},
+		{
+			name:      "invalid_dev",
+			devname:   "sdz",
+			expectErr: true,
+		},
+		{
+			name:      "missing_dev",
+			devname:   "sdy",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			d, err := readDeviceAttrs(sysfsBlockDir, tt.devname, driveAttrs)
+			if tt.expectErr {
+				if err == nil {
+					t1.Fatalf("Test case name %s: expected error but got none", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if !reflect.DeepEqual(d, tt.expectedDrive) {
+				t1.Errorf("Test case name %s: expected %+v but got %+v", tt.name, tt.expectedDrive, d)
+			}
+		})
+	}
+}
+
+func TestProbeMountInfo(t1 *testing.T) {
+	mountinfo := `26 1 259:2 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p2 rw,errors=remount-ro
+120 26 8:16 / /var/lib/direct-csi/mnt/abc rw,relatime shared:60 - xfs /dev/sdb rw,attr2,inode64,prjquota
+130 26 8:32 /my\040data /mnt/with\040space\011tab rw,noatime - xfs /dev/sdc rw
+140 26 8:48 / /mnt/back\134slash rw - ext4 /dev/sdd rw`
+
+	file, err := ioutil.TempFile("", "mountinfo-")
+	if err != nil {
+		t1.Fatalf("unable to create temporary file; %v", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(mountinfo); err != nil {
+		t1.Fatalf("unable to write mountinfo; %v", err)
+	}
+	file.Close()
+
+	defer func(path string) { MountInfoPath = path }(MountInfoPath)
+	MountInfoPath = file.Name()
+
+	mounts, err := ProbeMountInfo()
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+	expectedMounts := []MountInfo{
+		{
+			Mountpoint:        "/",
+			MountFlags:        []string{"rw", "relatime"},
+			MountRoot:         "/",
+			MountID:           26,
+			ParentID:          1,
+			MountSource:       "/dev/nvme0n1p2",
+			SuperblockOptions: []string{"rw", "errors=remount-ro"},
+			FSType:            "ext4",
+			OptionalFields:    []string{"shared:1"},
+			Major:             259,
+			Minor:             2,
+		},
+		{
+			Mountpoint:        "/var/lib/direct-csi/mnt/abc",
+			MountFlags:        []string{"rw", "relatime"},
+			MountRoot:         "/",
+			MountID:           120,
+			ParentID:          26,
+			MountSource:       "/dev/sdb",
+			SuperblockOptions: []string{"rw", "attr2", "inode64", "prjquota"},
+			FSType:            "xfs",
+			OptionalFields:    []string{"shared:60"},
+			Major:             8,
+			Minor:             16,
+		},
+		{
+			Mountpoint:        "/mnt/with space\ttab",
+			MountFlags:        []string{"rw", "noatime"},
+			MountRoot:         "/my data",
+			MountID:           130,
+			ParentID:          26,
+			MountSource:       "/dev/sdc",
+			SuperblockOptions: []string{"rw"},
+			FSType:            "xfs",
+			OptionalFields:    []string{},
+			Major:             8,
+			Minor:             32,
+		},
+		{
+			Mountpoint:        `/mnt/back\slash`,
+			MountFlags:        []string{"rw"},
+			MountRoot:         "/",
+			MountID:           140,
+			ParentID:          26,
+			MountSource:       "/dev/sdd",
+			SuperblockOptions: []string{"rw"},
+			FSType:            "ext4",
+			OptionalFields:    []string{},
+			Major:             8,
+			Minor:             48,
+		},
+	}
+	if !reflect.DeepEqual(mounts, expectedMounts) {
+		t1.Errorf("expected %+v but got %+v", expectedMounts, mounts)
+	}
+
+	if _, err := parseMountInfo(strings.NewReader("26 1 259:2 / / rw\n"), "mountinfo"); err == nil {
+		t1.Errorf("expected error for a line without the separator")
+	}
+}
+
+func TestParseMountInfoSeparator(t1 *testing.T) {
+	testCases := []struct {
+		name               string
+		line               string
+		expectedMountpoint string
+		expectedOptional   []string
+		expectedSource     string
+		expectedErr        bool
+	}{
+		{
+			name:               "nooptionalfields",
+			line:               "120 26 8:16 / /mnt/my\\040drive rw,relatime - xfs /dev/sdb rw",
+			expectedMountpoint: "/mnt/my drive",
+			expectedOptional:   []string{},
+			expectedSource:     "/dev/sdb",
+		},
+		{
+			name:               "multipleoptionalfields",
+			line:               "120 26 8:16 / /mnt/my\\011drive rw,relatime shared:60 master:1 propagate_from:2 - xfs /dev/sdb rw",
+			expectedMountpoint: "/mnt/my\tdrive",
+			expectedOptional:   []string{"shared:60", "master:1", "propagate_from:2"},
+			expectedSource:     "/dev/sdb",
+		},
+		{
+			name:               "hyphenatedmountpoint",
+			line:               "120 26 8:16 / /mnt/my\\040-\\040drive rw shared:60 - xfs /dev/mapper/vg-lv rw",
+			expectedMountpoint: "/mnt/my - drive",
+			expectedOptional:   []string{"shared:60"},
+			expectedSource:     "/dev/mapper/vg-lv",
+		},
+		{
+			name:        "noseparator",
+			line:        "120 26 8:16 / /mnt/drive rw shared:60 xfs /dev/sdb rw",
+			expectedErr: true,
+		},
+		{
+			name:        "shortsuffix",
+			line:        "120 26 8:16 / /mnt/drive rw - xfs",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			mounts, err := parseMountInfo(strings.NewReader(tt.line+"\n"), "mountinfo")
+			if tt.expectedErr {
+				if err == nil {
+					t1.Errorf("Test case name %s: expected error but got %+v", tt.name, mounts)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error %v", tt.name, err)
+			}
+			if len(mounts) != 1 {
+				t1.Fatalf("Test case name %s: expected one mount but got %+v", tt.name, mounts)
+			}
+			if mounts[0].Mountpoint != tt.expectedMountpoint {
+				t1.Errorf("Test case name %s: expected mountpoint %q but got %q", tt.name, tt.expectedMountpoint, mounts[0].Mountpoint)
+			}
+			if !reflect.DeepEqual(mounts[0].OptionalFields, tt.expectedOptional) {
+				t1.Errorf("Test case name %s: expected optional fields %v but got %v", tt.name, tt.expectedOptional, mounts[0].OptionalFields)
+			}
+			if mounts[0].MountSource != tt.expectedSource {
+				t1.Errorf("Test case name %s: expected mount source %s but got %s", tt.name, tt.expectedSource, mounts[0].MountSource)
+			}
+		})
+	}
+}
+
+func TestIsBindMountOf(t1 *testing.T) {
+	mountInfo := `26 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw
+120 26 8:16 / /var/lib/direct-csi/mnt/drive1 rw,relatime - xfs /dev/sdb rw,prjquota
+121 26 8:16 /pvc-1 /var/lib/kubelet/plugins/pv/pvc-1/globalmount rw,relatime - xfs /dev/sdb rw,prjquota
+122 26 8:32 /pvc-2 /var/lib/kubelet/plugins/pv/pvc-2/globalmount rw,relatime - xfs /dev/sdc rw,prjquota
+`
+	mounts, err := parseMountInfo(strings.NewReader(mountInfo), "mountinfo")
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		source   string
+		target   string
+		expected bool
+	}{
+		{
+			name:     "bindmounted",
+			source:   "/var/lib/direct-csi/mnt/drive1/pvc-1",
+			target:   "/var/lib/kubelet/plugins/pv/pvc-1/globalmount",
+			expected: true,
+		},
+		{
+			name:     "otherdirectory",
+			source:   "/var/lib/direct-csi/mnt/drive1/pvc-3",
+			target:   "/var/lib/kubelet/plugins/pv/pvc-1/globalmount",
+			expected: false,
+		},
+		{
+			name:     "otherdevice",
+			source:   "/var/lib/direct-csi/mnt/drive1/pvc-2",
+			target:   "/var/lib/kubelet/plugins/pv/pvc-2/globalmount",
+			expected: false,
+		},
+		{
+			name:     "notmounted",
+			source:   "/var/lib/direct-csi/mnt/drive1/pvc-1",
+			target:   "/var/lib/kubelet/plugins/pv/pvc-4/globalmount",
+			expected: false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if bindMounted := isBindMountOf(mounts, tt.source, tt.target); bindMounted != tt.expected {
+				t1.Errorf("Test case name %s: expected bind mount = %v, got %v", tt.name, tt.expected, bindMounted)
+			}
+		})
+	}
+}
+
+func TestHasProjectQuota(t1 *testing.T) {
+	mountInfo := `26 1 8:1 / / rw,relatime - ext4 /dev/sda1 rw
+120 26 8:16 / /var/lib/direct-csi/mnt/drive1 rw,relatime - xfs /dev/sdb rw,attr2,inode64,prjquota
+121 26 8:32 / /var/lib/direct-csi/mnt/drive2 rw,relatime - xfs /dev/sdc rw,attr2,inode64,noquota
+122 26 8:48 / /var/lib/direct-csi/mnt/drive3 rw,relatime - ext4 /dev/sdd rw,prjquota
+123 26 8:64 / /var/lib/direct-csi/mnt/drive4 rw,relatime - xfs /dev/sde rw,prjquota
+124 123 8:80 / /var/lib/direct-csi/mnt/drive4 rw,relatime - xfs /dev/sdf rw,noquota
+`
+	mounts, err := parseMountInfo(strings.NewReader(mountInfo), "mountinfo")
+	if err != nil {
+		t1.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected bool
+	}{
+		{"prjquota", "/var/lib/direct-csi/mnt/drive1/pvc-1", true},
+		{"mountpoint", "/var/lib/direct-csi/mnt/drive1", true},
+		{"noquota", "/var/lib/direct-csi/mnt/drive2/pvc-1", false},
+		{"ext4", "/var/lib/direct-csi/mnt/drive3/pvc-1", false},
+		{"notmounted", "/var/lib/direct-csi/mnt/drive10/pvc-1", false},
+		{"overmounted", "/var/lib/direct-csi/mnt/drive4/pvc-1", false},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			if quota := hasProjectQuota(mounts, tt.path); quota != tt.expected {
+				t1.Errorf("Test case name %s: expected project quota = %v, got %v", tt.name, tt.expected, quota)
+			}
+		})
+	}
+}
+
+func TestDedupByWWID(t1 *testing.T) {
+	device := func(name, wwid string) BlockDevice {
+		return BlockDevice{Devname: name, DriveInfo: &DriveInfo{WWID: wwid}}
+	}
+	names := func(devices []BlockDevice) []string {
+		result := []string{}
+		for _, d := range devices {
+			result = append(result, d.Devname)
+		}
+		return result
+	}
+
+	aliases := func(devices []BlockDevice) map[string][]string {
+		result := map[string][]string{}
+		for _, d := range devices {
+			if len(d.Aliases) > 0 {
+				result[d.Devname] = d.Aliases
+			}
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name            string
+		devices         []BlockDevice
+		byIDTargets     map[string]bool
+		expected        []string
+		expectedAliases map[string][]string
+	}{
+		{
+			name:     "unique",
+			devices:  []BlockDevice{device("sdb", "naa.1"), device("sdc", "naa.2")},
+			expected: []string{"sdb", "sdc"},
+		},
+		{
+			name:     "nowwid",
+			devices:  []BlockDevice{device("sdb", ""), device("sdc", ""), {Devname: "sdd"}},
+			expected: []string{"sdb", "sdc", "sdd"},
+		},
+		{
+			name:            "duplicate_by_name",
+			devices:         []BlockDevice{device("sdc", "naa.1"), device("sdb", "naa.1"), device("sdd", "naa.2")},
+			expected:        []string{"sdb", "sdd"},
+			expectedAliases: map[string][]string{"sdb": {"sdc"}},
+		},
+		{
+			name:            "triplicate",
+			devices:         []BlockDevice{device("sdc", "naa.1"), device("sdb", "naa.1"), device("sdd", "naa.1")},
+			expected:        []string{"sdb"},
+			expectedAliases: map[string][]string{"sdb": {"sdc", "sdd"}},
+		},
+		{
+			name:            "duplicate_by_id",
+			devices:         []BlockDevice{device("sdb", "naa.1"), device("sdc", "naa.1")},
+			byIDTargets:     map[string]bool{"sdc": true},
+			expected:        []string{"sdc"},
+			expectedAliases: map[string][]string{"sdc": {"sdb"}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			deduped := dedupByWWID(tt.devices, tt.byIDTargets)
+			if !reflect.DeepEqual(names(deduped), tt.expected) {
+				t1.Errorf("Test case name %s: expected %v but got %v", tt.name, tt.expected, names(deduped))
+			}
+			expectedAliases := tt.expectedAliases
+			if expectedAliases == nil {
+				expectedAliases = map[string][]string{}
+			}
+			if !reflect.DeepEqual(aliases(deduped), expectedAliases) {
+				t1.Errorf("Test case name %s: expected aliases %v but got %v", tt.name, expectedAliases, aliases(deduped))
+			}
+		})
+	}
+
+	byIDDir := t1.TempDir()
+	target := filepath.Join(t1.TempDir(), "sdb")
+	if err := ioutil.WriteFile(target, nil, 0644); err != nil {
+		t1.Fatal(err)
+	}
+	partTarget := filepath.Join(filepath.Dir(target), "sdb1")
+	if err := ioutil.WriteFile(partTarget, nil, 0644); err != nil {
+		t1.Fatal(err)
+	}
+	links := map[string]string{
+		"ata-ST4000NM0035_ZC1234AB":       target,
+		"wwn-0x5000c500a1b2c3d4":          target,
+		"wwn-0x5000c500a1b2c3d4-part1":    partTarget,
+		"scsi-35000c500a1b2c3d4":          target,
+		"ata-ST4000NM0035_ZC1234AB-part1": partTarget,
+	}
+	for name, linkTarget := range links {
+		if err := os.Symlink(linkTarget, filepath.Join(byIDDir, name)); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	expectedPaths := map[string]string{
+		"sdb":  filepath.Join(byIDDir, "wwn-0x5000c500a1b2c3d4"),
+		"sdb1": filepath.Join(byIDDir, "wwn-0x5000c500a1b2c3d4-part1"),
+	}
+	byIDPaths := readByIDPaths(byIDDir)
+	if !reflect.DeepEqual(byIDPaths, expectedPaths) {
+		t1.Errorf("expected the by-id paths %v but got %v", expectedPaths, byIDPaths)
+	}
+
+	devices := []BlockDevice{
+		{
+			Devname:   "sdb",
+			DriveInfo: &DriveInfo{Path: "/var/lib/direct-csi/devices/sdb"},
+			Partitions: []Partition{
+				{PartitionNum: 1, DriveInfo: &DriveInfo{Path: "/var/lib/direct-csi/devices/sdb-part-1"}},
+			},
+		},
+		{Devname: "sdc", DriveInfo: &DriveInfo{Path: "/var/lib/direct-csi/devices/sdc"}},
+	}
+	if targets := setByIDPaths(devices, byIDPaths); !reflect.DeepEqual(targets, map[string]bool{"sdb": true}) {
+		t1.Errorf("expected the by-id target sdb but got %v", targets)
+	}
+	if devices[0].ByIDPath != expectedPaths["sdb"] || devices[0].Partitions[0].ByIDPath != expectedPaths["sdb1"] || devices[1].ByIDPath != "" {
+		t1.Errorf("unexpected by-id paths %s %s %s", devices[0].ByIDPath, devices[0].Partitions[0].ByIDPath, devices[1].ByIDPath)
+	}
+}
+
+func TestPartitionDevname(t1 *testing.T) {
+	testCases := []struct {
+		devName      string
+		partitionNum uint32
+		expected     string
+	}{
+		{"sdb", 1, "sdb1"},
+		{"xvdb", 12, "xvdb12"},
+		{"nvme0n1", 1, "nvme0n1p1"},
+		{"mmcblk0", 2, "mmcblk0p2"},
+	}
+	for _, tt := range testCases {
+		if devName := partitionDevname(tt.devName, tt.partitionNum); devName != tt.expected {
+			t1.Errorf("expected the partition %s but got %s", tt.expected, devName)
+		}
+	}
+}
+
+func TestParseIOScheduler(t1 *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "[mq-deadline] kyber bfq none", expected: "mq-deadline"},
+		{value: "mq-deadline kyber [bfq] none", expected: "bfq"},
+		{value: "[none]", expected: "none"},
+		{value: "none mq-deadline", expected: ""},
+		{value: "", expected: ""},
+	}
+	for _, tt := range testCases {
+		if got := parseIOScheduler(tt.value); got != tt.expected {
+			t1.Errorf("parseIOScheduler(%q): expected %q but got %q", tt.value, tt.expected, got)
+		}
+	}
+}
+
+func TestSetVolumeOwnership(t1 *testing.T) {
+	gid := int64(os.Getgid())
+	target := t1.TempDir()
+	if err := os.MkdirAll(filepath.Join(target, "dir"), 0700); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(target, "dir", "file"), []byte("data"), 0600); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.Symlink("dir/file", filepath.Join(target, "link")); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+
+	checkMode := func(name string, expected os.FileMode) {
+		info, err := os.Lstat(filepath.Join(target, name))
+		if err != nil {
+			t1.Fatalf("unexpected error: %v", err)
+		}
+		if mode := info.Mode() & (os.ModePerm | os.ModeSetgid); mode != expected {
+			t1.Errorf("%s: expected mode: %v, got: %v", name, expected, mode)
+		}
+	}
+
+	if err := setVolumeOwnership(context.Background(), target, gid, FSGroupChangeOnRootMismatch); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	checkMode("dir", 0770|os.ModeSetgid)
+	checkMode("dir/file", 0660)
+
+	// the root already matches the fsGroup
+	if err := os.Chmod(filepath.Join(target, "dir", "file"), 0600); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	if err := setVolumeOwnership(context.Background(), target, gid, FSGroupChangeOnRootMismatch); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	checkMode("dir/file", 0600)
+	if err := setVolumeOwnership(context.Background(), target, gid, FSGroupChangeAlways); err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	checkMode("dir/file", 0660)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := setVolumeOwnership(ctx, target, gid, FSGroupChangeAlways); !errors.Is(err, context.Canceled) {
+		t1.Errorf("expected error: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestEnclosureSlots(t1 *testing.T) {
+	root := t1.TempDir()
+	mkdir := func(path string) {
+		if err := os.MkdirAll(filepath.Join(root, path), 0755); err != nil {
+			t1.Fatalf("unexpected error: %v", err)
+		}
+	}
+	symlink := func(target, link string) {
+		if err := os.Symlink(filepath.Join(root, target), filepath.Join(root, link)); err != nil {
+			t1.Fatalf("unexpected error: %v", err)
+		}
+	}
+	writeFile := func(path, data string) {
+		if err := ioutil.WriteFile(filepath.Join(root, path), []byte(data), 0644); err != nil {
+			t1.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	mkdir("devices/0:0:12:0")
+	mkdir("devices/0:0:13:0")
+	mkdir("devices/0:0:14:0")
+	mkdir("devices/1:0:0:0")
+	mkdir("block/sdq")
+	mkdir("block/sdr")
+	mkdir("block/sda")
+	symlink("devices/0:0:13:0", "block/sdq/device")
+	symlink("devices/0:0:14:0", "block/sdr/device")
+	symlink("devices/1:0:0:0", "block/sda/device")
+	mkdir("enclosure/0:0:12:0/Slot 17")
+	mkdir("enclosure/0:0:12:0/ArrayDevice18")
+	mkdir("enclosure/0:0:12:0/Slot 19")
+	symlink("devices/0:0:12:0", "enclosure/0:0:12:0/device")
+	symlink("devices/0:0:13:0", "enclosure/0:0:12:0/Slot 17/device")
+	writeFile("enclosure/0:0:12:0/Slot 17/slot", "17\n")
+	symlink("devices/0:0:14:0", "enclosure/0:0:12:0/ArrayDevice18/device")
+
+	devices := []BlockDevice{
+		{
+			Devname:   "sdq",
+			DriveInfo: &DriveInfo{},
+			Partitions: []Partition{
+				{PartitionNum: 1, DriveInfo: &DriveInfo{}},
+			},
+		},
+		{Devname: "sdr", DriveInfo: &DriveInfo{}},
+		{Devname: "sda", DriveInfo: &DriveInfo{}},
+	}
+	setEnclosureSlots(devices, filepath.Join(root, "block"), readEnclosureSlots(filepath.Join(root, "enclosure")))
+
+	expected := []EnclosureSlot{
+		{Enclosure: "0:0:12:0", Slot: "17"},
+		{Enclosure: "0:0:12:0", Slot: "ArrayDevice18"},
+		{},
+	}
+	for i, device := range devices {
+		if slot := (EnclosureSlot{Enclosure: device.Enclosure, Slot: device.Slot}); slot != expected[i] {
+			t1.Errorf("%s: expected: %+v, got: %+v", device.Devname, expected[i], slot)
+		}
+	}
+	if partition := devices[0].Partitions[0]; partition.Enclosure != "0:0:12:0" || partition.Slot != "17" {
+		t1.Errorf("sdq1: expected the slot of its disk, got: %s %s", partition.Enclosure, partition.Slot)
+	}
+}
+
+func TestWalkSysfs(t1 *testing.T) {
+	head := t1.TempDir()
+	for _, dir := range []string{"sda", "sdb", "sdc"} {
+		if err := os.MkdirAll(filepath.Join(head, dir), 0755); err != nil {
+			t1.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(head, dir, "uevent"), []byte{}, 0644); err != nil {
+			t1.Fatal(err)
+		}
+	}
+
+	// sdb is torn down while sda is walked, the walk goes on with sdc
+	visited := []string{}
+	err := walkSysfs(head, func(path string, info os.FileInfo) error {
+		if info.Name() != "uevent" {
+			return nil
+		}
+		dir := filepath.Base(filepath.Dir(path))
+		if dir == "sda" {
+			if err := os.RemoveAll(filepath.Join(head, "sdb")); err != nil {
+				t1.Fatal(err)
+			}
+		}
+		visited = append(visited, dir)
+		return nil
+	})
+	if err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(visited, []string{"sda", "sdc"}) {
+		t1.Errorf("expected visited: [sda sdc], got: %v", visited)
+	}
+
+	// the errors of the head fail the walk
+	if err := walkSysfs(filepath.Join(head, "missing"), func(path string, info os.FileInfo) error {
+		return nil
+	}); !errors.Is(err, os.ErrNotExist) {
+		t1.Errorf("expected: %v, got: %v", os.ErrNotExist, err)
+	}
+
+	// the errors of the callback fail the walk
+	errCallback := errors.New("callback error")
+	if err := walkSysfs(head, func(path string, info os.FileInfo) error {
+		return errCallback
+	}); err != errCallback {
+		t1.Errorf("expected: %v, got: %v", errCallback, err)
+	}
+}
+
+func TestByPathPaths(t1 *testing.T) {
+	byPathDir := t1.TempDir()
+	target := filepath.Join(t1.TempDir(), "sdb")
+	if err := ioutil.WriteFile(target, nil, 0644); err != nil {
+		t1.Fatal(err)
+	}
+	partTarget := filepath.Join(filepath.Dir(target), "sdb1")
+	if err := ioutil.WriteFile(partTarget, nil, 0644); err != nil {
+		t1.Fatal(err)
+	}
+	links := map[string]string{
+		"pci-0000:00:1f.2-ata-1.0":       target,
+		"pci-0000:00:1f.2-ata-1":         target,
+		"pci-0000:00:1f.2-ata-1.0-part1": partTarget,
+		"pci-0000:00:1f.2-ata-1-part1":   partTarget,
+	}
+	for name, linkTarget := range links {
+		if err := os.Symlink(linkTarget, filepath.Join(byPathDir, name)); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	expectedPaths := map[string]string{
+		"sdb":  filepath.Join(byPathDir, "pci-0000:00:1f.2-ata-1"),
+		"sdb1": filepath.Join(byPathDir, "pci-0000:00:1f.2-ata-1-part1"),
+	}
+	byPathPaths := readByPathPaths(byPathDir)
+	if !reflect.DeepEqual(byPathPaths, expectedPaths) {
+		t1.Errorf("expected the by-path paths %v but got %v", expectedPaths, byPathPaths)
+	}
+
+	devices := []BlockDevice{
+		{
+			Devname:   "sdb",
+			DriveInfo: &DriveInfo{Path: "/var/lib/direct-csi/devices/sdb"},
+			Partitions: []Partition{
+				{PartitionNum: 1, DriveInfo: &DriveInfo{Path: "/var/lib/direct-csi/devices/sdb-part-1"}},
+			},
+		},
+		{Devname: "sdc", DriveInfo: &DriveInfo{Path: "/var/lib/direct-csi/devices/sdc"}},
+	}
+	setByPathPaths(devices, byPathPaths)
+	if devices[0].ByPath != expectedPaths["sdb"] || devices[0].Partitions[0].ByPath != expectedPaths["sdb1"] || devices[1].ByPath != "" {
+		t1.Errorf("unexpected by-path paths %s %s %s", devices[0].ByPath, devices[0].Partitions[0].ByPath, devices[1].ByPath)
+	}
+}
+
+func TestReadHolders(t1 *testing.T) {
+	sysfsBlockDir := t1.TempDir()
+	if err := os.MkdirAll(filepath.Join(sysfsBlockDir, "sdb", "holders", "dm-0"), 0755); err != nil {
+		t1.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(sysfsBlockDir, "sdc", "holders"), 0755); err != nil {
+		t1.Fatal(err)
+	}
+
+	testCases := []struct {
+		devname     string
+		expected    []string
+		expectedErr bool
+	}{
+		{devname: "sdb", expected: []string{"dm-0"}},
+		{devname: "sdc", expected: []string{}},
+		// an unknown device is never reported as free
+		{devname: "sdd", expectedErr: true},
+	}
+	for i, testCase := range testCases {
+		holders, err := readHolders(sysfsBlockDir, testCase.devname)
+		if testCase.expectedErr {
+			if err == nil {
+				t1.Errorf("case %v: expected error, got holders: %v", i+1, holders)
+			}
+			continue
+		}
+		if err != nil {
+			t1.Fatalf("case %v: unexpected error: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(holders, testCase.expected) {
+			t1.Errorf("case %v: expected holders: %v, got: %v", i+1, testCase.expected, holders)
+		}
+	}
+}
+
+func TestReadDeviceHolders(t1 *testing.T) {
+	sysfsBlockDir := t1.TempDir()
+	sysDevBlockDir := t1.TempDir()
+	// the partition sdb1 is named /var/lib/direct-csi/devices/sdb-part-1 by direct-csi
+	devices := map[string]string{
+		"8:16": "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sdb",
+		"8:17": "../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sdb/sdb1",
+	}
+	for devnum, target := range devices {
+		if err := os.Symlink(target, filepath.Join(sysDevBlockDir, devnum)); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(sysfsBlockDir, "sdb", "holders"), 0755); err != nil {
+		t1.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(sysfsBlockDir, "sdb1", "holders", "md0"), 0755); err != nil {
+		t1.Fatal(err)
+	}
+
+	testCases := []struct {
+		major, minor    uint32
+		expectedDevname string
+		expected        []string
+		expectedErr     bool
+	}{
+		{major: 8, minor: 16, expectedDevname: "sdb", expected: []string{}},
+		{major: 8, minor: 17, expectedDevname: "sdb1", expected: []string{"md0"}},
+		{major: 8, minor: 18, expectedErr: true},
+	}
+	for i, testCase := range testCases {
+		devname, holders, err := readDeviceHolders(sysfsBlockDir, sysDevBlockDir, testCase.major, testCase.minor)
+		if testCase.expectedErr {
+			if err == nil {
+				t1.Errorf("case %v: expected error, got device %s with holders: %v", i+1, devname, holders)
+			}
+			continue
+		}
+		if err != nil {
+			t1.Fatalf("case %v: unexpected error: %v", i+1, err)
+		}
+		if devname != testCase.expectedDevname || !reflect.DeepEqual(holders, testCase.expected) {
+			t1.Errorf("case %v: expected %s with holders: %v, got: %s with holders: %v", i+1, testCase.expectedDevname, testCase.expected, devname, holders)
+		}
+	}
+}
+
+func TestFindDeviceOpeners(t1 *testing.T) {
+	procFS := t1.TempDir()
+	// the fd links are resolved by the fake rdevOf from their names
+	fds := map[string][]string{
+		"1":    {"8:16"},
+		"1234": {"0:0", "8:16", "8:16"},
+		"5678": {"8:32"},
+		"self": {"8:16"},
+	}
+	for pid, links := range fds {
+		for i, link := range links {
+			if err := os.MkdirAll(filepath.Join(procFS, pid, "fd"), 0755); err != nil {
+				t1.Fatal(err)
+			}
+			if err := os.Symlink(link, filepath.Join(procFS, pid, "fd", fmt.Sprint(i))); err != nil {
+				t1.Fatal(err)
+			}
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(procFS, "1234", "comm"), []byte("postgres\n"), 0644); err != nil {
+		t1.Fatal(err)
+	}
+	rdevOf := func(path string) (uint32, uint32, bool) {
+		target, err := os.Readlink(path)
+		if err != nil || target == "0:0" {
+			return 0, 0, false
+		}
+		var major, minor uint32
+		if _, err := fmt.Sscanf(target, "%d:%d", &major, &minor); err != nil {
+			return 0, 0, false
+		}
+		return major, minor, true
+	}
+
+	// pid 1 is the process itself
+	openers, err := findDeviceOpeners(procFS, 1, 8, 16, rdevOf)
+	if err != nil {
+		t1.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"1234 (postgres)"}; !reflect.DeepEqual(openers, expected) {
+		t1.Errorf("expected openers: %v, got: %v", expected, openers)
+	}
+}
+
+func TestFreeCapacityOf(t1 *testing.T) {
+	testCases := []struct {
+		totalCapacity uint64
+		usedCapacity  uint64
+		expected      uint64
+	}{
+		{totalCapacity: 100 << 20, usedCapacity: 0, expected: 100 << 20},
+		{totalCapacity: 100 << 20, usedCapacity: 30 << 20, expected: 70 << 20},
+		{totalCapacity: 100 << 20, usedCapacity: 100 << 20, expected: 0},
+		{totalCapacity: 100 << 20, usedCapacity: 120 << 20, expected: 0},
+	}
+	for _, tt := range testCases {
+		if freeCapacity := freeCapacityOf(tt.totalCapacity, tt.usedCapacity); freeCapacity != tt.expected {
+			t1.Errorf("expected the free capacity %d of %d used of %d but got %d", tt.expected, tt.usedCapacity, tt.totalCapacity, freeCapacity)
+		}
+	}
+}
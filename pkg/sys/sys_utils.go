@@ -20,7 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -74,6 +74,20 @@ func makeBlockDeviceName(devName string) string {
 	return strings.Join([]string{dName, partNumStr}, DirectCSIPartitionInfix)
 }
 
+// partitionDevname returns the kernel name of the partition of the device, e.g. sdb1 or nvme0n1p1
+// as the kernel separates the partition number by a "p" if the device name ends with a digit
+func partitionDevname(devName string, partitionNum uint32) string {
+	if last := devName[len(devName)-1]; last >= '0' && last <= '9' {
+		return fmt.Sprintf("%s%s%d", devName, HostPartitionInfix, partitionNum)
+	}
+	return fmt.Sprintf("%s%d", devName, partitionNum)
+}
+
+// GetRootBlockPath returns the host path of the device, e.g. /dev/sdb1
+func GetRootBlockPath(devName string) string {
+	return getRootBlockFile(devName)
+}
+
 func getRootBlockFile(devName string) string {
 	if strings.Contains(devName, DirectCSIDevRoot) {
 		return getRootBlockFile(filepath.Base(devName))
@@ -174,6 +188,9 @@ func FlushLoopBackReservations() error {
 }
 
 func ReserveLoopbackDevices(devCount int) error {
+	if err := loopback.ValidateDeviceCount(devCount); err != nil {
+		return err
+	}
 	for i := 1; i <= devCount; i++ {
 		dev, err := loopback.CreateLoopbackDevice()
 		if err != nil {
@@ -183,3 +200,18 @@ func ReserveLoopbackDevices(devCount int) error {
 	}
 	return nil
 }
+
+// CheckMountpointFree - verifies that the target is either not mounted or is
+// mounted by the device identified by the given major/minor numbers
+func CheckMountpointFree(mounts []MountInfo, target string, major, minor uint32) error {
+	target = filepath.Clean(target)
+	for _, m := range mounts {
+		if filepath.Clean(m.Mountpoint) != target {
+			continue
+		}
+		if m.Major != major || m.Minor != minor {
+			return fmt.Errorf("%w: %s is mounted by %d:%d", ErrMountpointInUse, target, m.Major, m.Minor)
+		}
+	}
+	return nil
+}
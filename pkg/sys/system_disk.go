@@ -0,0 +1,76 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// isSystemMountpoint returns true if the mountpoint holds the root or the boot filesystem
+func isSystemMountpoint(mountpoint string) bool {
+	return mountpoint == "/" || mountpoint == "/boot" || strings.HasPrefix(mountpoint, "/boot/")
+}
+
+// parseSwaps returns the device paths of the active swap partitions listed in /proc/swaps
+//
+// Swap files are skipped as they live on a filesystem whose device is already covered by its mount.
+func parseSwaps(r io.Reader) ([]string, error) {
+	devices := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] == "Filename" {
+			continue
+		}
+		if fields[1] != "partition" {
+			continue
+		}
+		devices = append(devices, fields[0])
+	}
+	return devices, scanner.Err()
+}
+
+// collectDisks walks from the named devices to the whole disks backing them by following
+// partitions to their parent and device mapper devices to their slaves
+func collectDisks(names []string, parents map[string]string, slaves map[string][]string) map[string]bool {
+	disks := map[string]bool{}
+	visited := map[string]bool{}
+	var walk func(name string)
+	walk = func(name string) {
+		if name == "" || visited[name] {
+			return
+		}
+		visited[name] = true
+		if parent, found := parents[name]; found && parent != "" {
+			walk(parent)
+			return
+		}
+		if len(slaves[name]) > 0 {
+			for _, slave := range slaves[name] {
+				walk(slave)
+			}
+			return
+		}
+		disks[name] = true
+	}
+	for _, name := range names {
+		walk(name)
+	}
+	return disks
+}
@@ -28,6 +28,18 @@ type BlockDevice struct {
 	Devtype     string      `json:"devType,omitempty"`
 	Partitions  []Partition `json:"partitions,omitempty"`
 	DeviceError error       `json:"error, omitempty"`
+	// HasKernelPartitions is set if the kernel reports partitions that are not in the probed partition table
+	HasKernelPartitions bool `json:"hasKernelPartitions,omitempty"`
+	// IsSystemDisk is set if the disk backs the root or boot filesystem or the active swap
+	IsSystemDisk bool `json:"isSystemDisk,omitempty"`
+	// IsCryptMember is set if the disk backs an opened dm-crypt mapping or has a LUKS header
+	IsCryptMember bool `json:"isCryptMember,omitempty"`
+	// IsRAIDMember is set if the disk is a member of an MD array
+	IsRAIDMember bool `json:"isRAIDMember,omitempty"`
+	// RAIDMembers lists the member devices if the disk is an assembled MD array
+	RAIDMembers []string `json:"raidMembers,omitempty"`
+	// Aliases lists the devices skipped as they report the same WWID as the disk
+	Aliases []string `json:"aliases,omitempty"`
 
 	MasterInfo
 	*DriveInfo `json:"driveInfo,omitempty"`
@@ -39,6 +51,10 @@ type Partition struct {
 	TypeUUID      string `json:"partitionTypeUUID,omitempty"`
 	PartitionGUID string `json:"partitionGUID,omitempty"`
 	DiskGUID      string `json:"diskGUID,omitempty"`
+	// IsCryptMember is set if the partition backs an opened dm-crypt mapping or has a LUKS header
+	IsCryptMember bool `json:"isCryptMember,omitempty"`
+	// IsRAIDMember is set if the partition is a member of an MD array
+	IsRAIDMember bool `json:"isRAIDMember,omitempty"`
 
 	MasterInfo
 	*DriveInfo `json:"driveInfo,omitempty"`
@@ -55,6 +71,31 @@ type DriveInfo struct {
 	Major             uint32 `json:"major,omitempty"`
 	Minor             uint32 `json:"minor",omitempty`
 	SerialNumber      string `json:"serialNumber",omitempty`
+	// NVMe namespace attributes
+	Model            string `json:"model,omitempty"`
+	FirmwareRevision string `json:"firmwareRevision,omitempty"`
+	ControllerSerial string `json:"controllerSerial,omitempty"`
+	NamespaceID      int    `json:"namespaceID,omitempty"`
+	SharedNamespace  bool   `json:"sharedNamespace,omitempty"`
+	// Rotational is set for the spinning disks, it is read from queue/rotational of the disk
+	Rotational bool `json:"rotational,omitempty"`
+	// IOScheduler, NrRequests and ReadAheadKB are the block layer tunables read from queue/ of the disk
+	IOScheduler string `json:"ioScheduler,omitempty"`
+	NrRequests  int64  `json:"nrRequests,omitempty"`
+	ReadAheadKB int64  `json:"readAheadKB,omitempty"`
+	// Removable is set for the removable media like USB sticks and SD cards, it is read from removable of the disk
+	Removable bool `json:"removable,omitempty"`
+	// ReadOnly is set if the kernel reports the disk read-only, it is read from ro of the disk
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// WWID is the world wide identifier of the LUN or the NVMe namespace, if reported
+	WWID string `json:"wwid,omitempty"`
+	// ByIDPath is the stable /dev/disk/by-id link of the device, the kernel name may change across reboots
+	ByIDPath string `json:"byIDPath,omitempty"`
+	// ByPath is the /dev/disk/by-path link of the device, it locates the port the disk is attached to
+	ByPath string `json:"byPath,omitempty"`
+	// Enclosure and Slot locate the bay holding the disk in an SES enclosure, if reported
+	Enclosure string `json:"enclosure,omitempty"`
+	Slot      string `json:"slot,omitempty"`
 
 	*FSInfo `json:"fsInfo,omitempty"`
 }
@@ -62,6 +103,7 @@ type DriveInfo struct {
 type FSInfo struct {
 	UUID          string      `json:"uuid,omitempty"`
 	FSType        string      `json:"fsType,omitempty"`
+	Label         string      `json:"label,omitempty"`
 	TotalCapacity uint64      `json:"totalCapacity,omitempty"`
 	FreeCapacity  uint64      `json:"freeCapacity,omitempty"`
 	FSBlockSize   uint64      `json:"fsBlockSize,omitempty"`
@@ -82,6 +124,21 @@ type MountInfo struct {
 	Minor             uint32   `json:"minor,omitempty"`
 }
 
+// DrivePreview describes a drive found by the discovery and whether it can be managed
+type DrivePreview struct {
+	Path          string `json:"path"`
+	TotalCapacity uint64 `json:"totalCapacity,omitempty"`
+	Filesystem    string `json:"filesystem,omitempty"`
+	Mountpoint    string `json:"mountpoint,omitempty"`
+	DriveStatus   string `json:"driveStatus"`
+	Reason        string `json:"reason,omitempty"`
+	// FilesystemUUID and Label identify an existing filesystem, e.g. a data partition of another application
+	FilesystemUUID string `json:"filesystemUUID,omitempty"`
+	Label          string `json:"label,omitempty"`
+	// Trace lists the attributes read and the checks applied by the discovery, if requested
+	Trace []string `json:"trace,omitempty"`
+}
+
 type SuperBlock interface {
 	Is() bool
 }
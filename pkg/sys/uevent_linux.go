@@ -192,23 +192,30 @@ func EventsCh(ctx context.Context, c *Conn, maxRetriesOnError int) <-chan Uevent
 		defer close(ueventCh)
 		for {
 			if errC > maxRetriesOnError {
-				log.Fatal("Retry limit exceeded. Stopping uevent listener")
-				break
+				log.Print("Retry limit exceeded. Stopping uevent listener")
+				return
 			}
 
 			evt, err := dec.ReadAndDecode()
 			if err != nil {
-				log.Fatal(err)
+				if ctx.Err() != nil {
+					// the connection is closed on interrupt
+					return
+				}
+				log.Printf("Error while reading uevent: %v", err)
 				errC = errC + 1
 				continue
 			}
 
 			errC = 0
+			// the kernel messages are not decoded, only the ones rebroadcast by udev
+			if evt == nil {
+				continue
+			}
 			select {
 			case ueventCh <- *evt:
 				// Receive next event
 			case <-ctx.Done():
-				log.Fatal("Exiting on interrupt")
 				return
 			}
 		}
@@ -229,6 +236,11 @@ func WatchBlockDevices(ctx context.Context) (<-chan BlockDevice, error) {
 	eCh := EventsCh(ctx, c, 10)
 	go func() {
 		defer close(blockDeviceCh)
+		defer func() {
+			if err := c.Close(); err != nil {
+				log.Printf("Error while closing reader %s", err.Error())
+			}
+		}()
 		for {
 			select {
 			case evt, ok := <-eCh:
@@ -239,12 +251,13 @@ func WatchBlockDevices(ctx context.Context) (<-chan BlockDevice, error) {
 				// Filter subsystem
 				if evt.Subsystem == "block" {
 					// To-Do: Construct block device data
-					blockDeviceCh <- BlockDevice{}
+					select {
+					case blockDeviceCh <- BlockDevice{}:
+					case <-ctx.Done():
+						return
+					}
 				}
 			case <-ctx.Done():
-				if err := c.Close(); err != nil {
-					log.Printf("Error while closing reader %s", err.Error())
-				}
 				return
 			}
 		}
@@ -18,41 +18,118 @@ package sys
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"syscall"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"k8s.io/klog"
-
-	"github.com/minio/direct-csi/pkg/sys/fs/xfs"
+	"k8s.io/klog/v2"
 )
 
+// isQuotaUnsupported returns true if the mount was rejected because the filesystem of the
+// source does not support project quotas, i.e. it is not a xfs filesystem mounted with prjquota
+func isQuotaUnsupported(err error, src string) bool {
+	if !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.EOPNOTSUPP) {
+		return false
+	}
+	mounts, mErr := ProbeMountInfo()
+	if mErr != nil {
+		klog.V(3).Infof("unable to probe the mounts of %s: %v", src, mErr)
+		return false
+	}
+	return !hasProjectQuota(mounts, src)
+}
+
 // Idempotent function to bind mount a xfs filesystem with limits
-func mountVolume(ctx context.Context, src, dest, vID string, size int64, readOnly bool) error {
+func mountVolume(ctx context.Context, src, dest, vID string, size, inodeLimit int64, readOnly bool) (bool, error) {
 	klog.V(5).Infof("[mountVolume] source: %v destination: %v", src, dest)
-	if err := SafeMount(src, dest, string(FSTypeXFS),
-		func() []MountOption {
-			mOpts := []MountOption{
-				MountOptionMSBind,
-			}
-			if readOnly {
-				mOpts = append(mOpts, MountOptionMSReadOnly)
-			}
-			return mOpts
-		}(), []string{quotaOption}); err != nil {
-		return err
+	mountOpts := []MountOption{
+		MountOptionMSBind,
 	}
 
-	if size > 0 {
-		xfsQuota := &xfs.XFSQuota{
-			Path:      dest,
-			ProjectID: vID,
+	quotaEnforced := true
+	if err := SafeMount(ctx, src, dest, string(FSTypeXFS), mountOpts, []string{quotaOption}); err != nil {
+		if !isQuotaUnsupported(err, src) {
+			return false, err
+		}
+		klog.Warningf("mount with %s failed for %s: %v; retrying without quota, usage limits will not be enforced", quotaOption, dest, err)
+		if err := SafeMount(ctx, src, dest, string(FSTypeXFS), mountOpts, nil); err != nil {
+			return false, err
 		}
-		if err := xfsQuota.SetQuota(ctx, size); err != nil {
-			return status.Errorf(codes.Internal, "Error while setting xfs limits: %v", err)
+		quotaEnforced = false
+	}
+
+	// the read-only flag is ignored while creating a bind mount, it is set by remounting it
+	if readOnly {
+		if err := remountVolume(ctx, dest, true); err != nil {
+			return false, err
 		}
 	}
 
-	return nil
+	if size > 0 && quotaEnforced {
+		quota, err := NewQuota(dest, vID)
+		if err != nil {
+			return false, status.Errorf(codes.Internal, "Error while getting volume quota: %v", err)
+		}
+		if err := quota.SetQuota(ctx, size, inodeLimit); err != nil {
+			return false, status.Errorf(codes.Internal, "Error while setting quota limits: %v", err)
+		}
+	}
+
+	return quotaEnforced, nil
+}
+
+// setMountPropagation changes the propagation of the mount at the target, the mount is left as is for MountPropagationNone
+func setMountPropagation(ctx context.Context, target string, propagation MountPropagation) error {
+	var mountOpts []MountOption
+	switch propagation {
+	case "", MountPropagationNone:
+		return nil
+	case MountPropagationHostToContainer:
+		mountOpts = []MountOption{MountOptionMSRecursive, MountOptionMSSlave}
+	case MountPropagationBidirectional:
+		mountOpts = []MountOption{MountOptionMSRecursive, MountOptionMSShared}
+	default:
+		return status.Errorf(codes.InvalidArgument, "unsupported mount propagation %s", propagation)
+	}
+	klog.V(5).Infof("[setMountPropagation] target: %v propagation: %v", target, propagation)
+	return runWithContext(ctx, func() error {
+		return Mount("none", target, "", mountOpts, nil)
+	})
+}
+
+// remountVolume sets or clears the read-only flag of the bind mount at the target
+func remountVolume(ctx context.Context, target string, readOnly bool) error {
+	mountOpts := []MountOption{MountOptionMSRemount, MountOptionMSBind}
+	if readOnly {
+		mountOpts = append(mountOpts, MountOptionMSReadOnly)
+	}
+	klog.V(5).Infof("[remountVolume] target: %v readOnly: %v", target, readOnly)
+	return runWithContext(ctx, func() error {
+		return Mount("none", target, "", mountOpts, nil)
+	})
+}
+
+// isReadOnlyMount checks if the topmost mount at the target is read-only
+func isReadOnlyMount(target string) (bool, error) {
+	mounts, err := ProbeMountInfo()
+	if err != nil {
+		return false, err
+	}
+	readOnly := false
+	for _, m := range mounts {
+		if m.Mountpoint != filepath.Clean(target) {
+			continue
+		}
+		readOnly = false
+		for _, flag := range m.MountFlags {
+			if flag == string(MountOptionMSReadOnly) {
+				readOnly = true
+			}
+		}
+	}
+	return readOnly, nil
 }
 
 func unmountVolume(targetPath string) error {
@@ -60,16 +137,41 @@ func unmountVolume(targetPath string) error {
 }
 
 type VolumeMounter interface {
-	MountVolume(ctx context.Context, src, dest, vID string, size int64, readOnly bool) error
+	MountVolume(ctx context.Context, src, dest, vID string, size, inodeLimit int64, readOnly bool) (quotaEnforced bool, err error)
+	SetMountPropagation(ctx context.Context, target string, propagation MountPropagation) error
 	UnmountVolume(targetPath string) error
+	IsVolumeMounted(src, dest string) (bool, error)
+	RemountVolume(ctx context.Context, target string, readOnly bool) error
+	IsReadOnlyMount(target string) (bool, error)
+	SetVolumeOwnership(ctx context.Context, target string, fsGroup int64, policy FSGroupChangePolicy) error
 }
 
 type DefaultVolumeMounter struct{}
 
-func (c *DefaultVolumeMounter) MountVolume(ctx context.Context, src, dest, vID string, size int64, readOnly bool) error {
-	return mountVolume(ctx, src, dest, vID, size, readOnly)
+func (c *DefaultVolumeMounter) MountVolume(ctx context.Context, src, dest, vID string, size, inodeLimit int64, readOnly bool) (bool, error) {
+	return mountVolume(ctx, src, dest, vID, size, inodeLimit, readOnly)
+}
+
+func (c *DefaultVolumeMounter) SetMountPropagation(ctx context.Context, target string, propagation MountPropagation) error {
+	return setMountPropagation(ctx, target, propagation)
 }
 
 func (c *DefaultVolumeMounter) UnmountVolume(targetPath string) error {
 	return unmountVolume(targetPath)
 }
+
+func (c *DefaultVolumeMounter) IsVolumeMounted(src, dest string) (bool, error) {
+	return IsBindMountOf(src, dest)
+}
+
+func (c *DefaultVolumeMounter) RemountVolume(ctx context.Context, target string, readOnly bool) error {
+	return remountVolume(ctx, target, readOnly)
+}
+
+func (c *DefaultVolumeMounter) IsReadOnlyMount(target string) (bool, error) {
+	return isReadOnlyMount(target)
+}
+
+func (c *DefaultVolumeMounter) SetVolumeOwnership(ctx context.Context, target string, fsGroup int64, policy FSGroupChangePolicy) error {
+	return setVolumeOwnership(ctx, target, fsGroup, policy)
+}
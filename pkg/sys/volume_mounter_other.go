@@ -23,16 +23,41 @@ import (
 )
 
 type VolumeMounter interface {
-	MountVolume(ctx context.Context, src, dest, vID string, size int64, readOnly bool) error
+	MountVolume(ctx context.Context, src, dest, vID string, size, inodeLimit int64, readOnly bool) (quotaEnforced bool, err error)
+	SetMountPropagation(ctx context.Context, target string, propagation MountPropagation) error
 	UnmountVolume(targetPath string) error
+	IsVolumeMounted(src, dest string) (bool, error)
+	RemountVolume(ctx context.Context, target string, readOnly bool) error
+	IsReadOnlyMount(target string) (bool, error)
+	SetVolumeOwnership(ctx context.Context, target string, fsGroup int64, policy FSGroupChangePolicy) error
 }
 
 type DefaultVolumeMounter struct{}
 
-func (c *DefaultVolumeMounter) MountVolume(ctx context.Context, src, dest, vID string, size int64, readOnly bool) error {
+func (c *DefaultVolumeMounter) MountVolume(ctx context.Context, src, dest, vID string, size, inodeLimit int64, readOnly bool) (bool, error) {
+	return true, nil
+}
+
+func (c *DefaultVolumeMounter) SetMountPropagation(ctx context.Context, target string, propagation MountPropagation) error {
 	return nil
 }
 
 func (c *DefaultVolumeMounter) UnmountVolume(targetPath string) error {
 	return nil
 }
+
+func (c *DefaultVolumeMounter) IsVolumeMounted(src, dest string) (bool, error) {
+	return false, nil
+}
+
+func (c *DefaultVolumeMounter) RemountVolume(ctx context.Context, target string, readOnly bool) error {
+	return nil
+}
+
+func (c *DefaultVolumeMounter) IsReadOnlyMount(target string) (bool, error) {
+	return false, nil
+}
+
+func (c *DefaultVolumeMounter) SetVolumeOwnership(ctx context.Context, target string, fsGroup int64, policy FSGroupChangePolicy) error {
+	return nil
+}
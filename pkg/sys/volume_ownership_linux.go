@@ -0,0 +1,83 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// the files of the volume are made readable and writable by the group
+	fsGroupFileMode = 0660
+	// the directories are also made searchable, their new files inherit the group by setgid
+	fsGroupDirMode = 0770 | os.ModeSetgid
+)
+
+// isOwnedByFSGroup checks if the file is owned by the fsGroup with the permissions set by setVolumeOwnership
+func isOwnedByFSGroup(info os.FileInfo, fsGroup int64) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int64(stat.Gid) != fsGroup {
+		return false
+	}
+	mode := os.FileMode(fsGroupFileMode)
+	if info.IsDir() {
+		mode = fsGroupDirMode
+	}
+	return info.Mode()&mode == mode
+}
+
+// setVolumeOwnership recursively changes the group of the files under the target to the fsGroup and makes them
+// accessible by the group, the symlinks are not followed. The walk stops once ctx is done.
+func setVolumeOwnership(ctx context.Context, target string, fsGroup int64, policy FSGroupChangePolicy) error {
+	if policy != FSGroupChangeAlways {
+		info, err := os.Lstat(target)
+		if err != nil {
+			return err
+		}
+		if isOwnedByFSGroup(info, fsGroup) {
+			klog.V(5).Infof("[setVolumeOwnership] target: %v is already owned by group %v", target, fsGroup)
+			return nil
+		}
+	}
+
+	klog.V(5).Infof("[setVolumeOwnership] target: %v fsGroup: %v", target, fsGroup)
+	return filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := os.Lchown(path, -1, int(fsGroup)); err != nil {
+			return err
+		}
+		// the permissions of a symlink are not used
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		mode := os.FileMode(fsGroupFileMode)
+		if info.IsDir() {
+			mode = fsGroupDirMode
+		}
+		return os.Chmod(path, info.Mode()|mode)
+	})
+}
@@ -0,0 +1,122 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package sys
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// DiskByIDDir holds the stable udev links of the disks, e.g. wwn-0x5000c500a1b2c3d4
+const DiskByIDDir = "/dev/disk/by-id"
+
+// readByIDPaths returns the by-id link of each device linked from the by-id directory. Of the
+// links of a device, the wwn- link is preferred as it is printed on the label of the disk
+func readByIDPaths(byIDDir string) map[string]string {
+	return readDiskLinks(byIDDir, preferByIDLink)
+}
+
+// readDiskLinks returns the link of each device linked from the udev directory, of the links of
+// a device the one preferred by prefer is returned
+func readDiskLinks(dir string, prefer func(a, b string) bool) map[string]string {
+	paths := map[string]string{}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		klog.V(5).Infof("unable to read %s: %v", dir, err)
+		return paths
+	}
+	for _, entry := range entries {
+		link := filepath.Join(dir, entry.Name())
+		target, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			continue
+		}
+		devName := filepath.Base(target)
+		if current, found := paths[devName]; !found || prefer(entry.Name(), filepath.Base(current)) {
+			paths[devName] = link
+		}
+	}
+	return paths
+}
+
+// preferByIDLink checks if the by-id link a is preferred over the link b of the same device
+func preferByIDLink(a, b string) bool {
+	aWWN, bWWN := strings.HasPrefix(a, "wwn-"), strings.HasPrefix(b, "wwn-")
+	if aWWN != bWWN {
+		return aWWN
+	}
+	return a < b
+}
+
+// setByIDPaths sets the by-id links of the devices and their partitions, and returns the names of the linked devices
+func setByIDPaths(devices []BlockDevice, byIDPaths map[string]string) map[string]bool {
+	targets := map[string]bool{}
+	for i := range devices {
+		if path, found := byIDPaths[devices[i].Devname]; found {
+			targets[devices[i].Devname] = true
+			if devices[i].DriveInfo != nil {
+				devices[i].ByIDPath = path
+			}
+		}
+		for j := range devices[i].Partitions {
+			if devices[i].Partitions[j].DriveInfo != nil {
+				devices[i].Partitions[j].ByIDPath = byIDPaths[partitionDevname(devices[i].Devname, devices[i].Partitions[j].PartitionNum)]
+			}
+		}
+	}
+	return targets
+}
+
+// dedupByWWID collapses the devices reporting the same WWID, i.e. the same LUN seen
+// under two names while the SAN is rescanned, into one device. The device linked from
+// /dev/disk/by-id is kept, the first device by name otherwise. The names of the skipped
+// devices are recorded in the aliases of the kept device.
+func dedupByWWID(devices []BlockDevice, byIDTargets map[string]bool) []BlockDevice {
+	kept := map[string]int{}
+	deduped := []BlockDevice{}
+	for _, device := range devices {
+		if device.DriveInfo == nil || device.WWID == "" {
+			deduped = append(deduped, device)
+			continue
+		}
+		i, found := kept[device.WWID]
+		if !found {
+			kept[device.WWID] = len(deduped)
+			deduped = append(deduped, device)
+			continue
+		}
+		keep, drop := deduped[i], device
+		if preferDevice(device, keep, byIDTargets) {
+			keep, drop = device, keep
+		}
+		klog.V(3).Infof("Skipping the device %s reporting the same WWID %s as %s", drop.Devname, device.WWID, keep.Devname)
+		keep.Aliases = append(append(keep.Aliases, drop.Aliases...), drop.Devname)
+		deduped[i] = keep
+	}
+	return deduped
+}
+
+// preferDevice checks if the device a is preferred over the device b of the same WWID
+func preferDevice(a, b BlockDevice, byIDTargets map[string]bool) bool {
+	if byIDTargets[a.Devname] != byIDTargets[b.Devname] {
+		return byIDTargets[a.Devname]
+	}
+	return a.Devname < b.Devname
+}
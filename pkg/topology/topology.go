@@ -16,6 +16,17 @@
 
 package topology
 
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// driverKeyPrefix is the prefix of the topology keys set by direct-csi, the node labels cannot override them
+const driverKeyPrefix = "direct.csi.min.io/"
+
 const (
 	TopologyDriverIdentity = "direct.csi.min.io/identity"
 	TopologyDriverNode     = "direct.csi.min.io/node"
@@ -42,3 +53,26 @@ func (in *TopologyConstraint) DeepCopyInto(out *TopologyConstraint) {
 	out.DriverZone = in.DriverZone
 	out.DriverRegion = in.DriverRegion
 }
+
+// NodeLabelTopology returns the values of the given labels of the node, e.g. topology.kubernetes.io/zone,
+// as topology segments so that the drives of the node can be matched by them. The labels missing on
+// the node and the labels with the keys of direct-csi are skipped.
+func NodeLabelTopology(ctx context.Context, kubeClient kubernetes.Interface, nodeName string, labels []string) (map[string]string, error) {
+	segments := map[string]string{}
+	if len(labels) == 0 {
+		return segments, nil
+	}
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, label := range labels {
+		if strings.HasPrefix(label, driverKeyPrefix) {
+			continue
+		}
+		if value, ok := node.Labels[label]; ok {
+			segments[label] = value
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,92 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package topology
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeLabelTopology(t1 *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				"topology.kubernetes.io/zone":   "dc1",
+				"topology.kubernetes.io/region": "eu-west",
+				TopologyDriverRack:              "rack-from-label",
+			},
+		},
+	})
+
+	testCases := []struct {
+		name             string
+		nodeName         string
+		labels           []string
+		expectedSegments map[string]string
+		expectErr        bool
+	}{
+		{
+			name:             "noLabels",
+			nodeName:         "node-1",
+			expectedSegments: map[string]string{},
+		},
+		{
+			name:     "selectedLabels",
+			nodeName: "node-1",
+			labels:   []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/region"},
+			expectedSegments: map[string]string{
+				"topology.kubernetes.io/zone":   "dc1",
+				"topology.kubernetes.io/region": "eu-west",
+			},
+		},
+		{
+			name:             "missingAndReservedLabels",
+			nodeName:         "node-1",
+			labels:           []string{"example.com/room", TopologyDriverRack},
+			expectedSegments: map[string]string{},
+		},
+		{
+			name:      "unknownNode",
+			nodeName:  "node-2",
+			labels:    []string{"topology.kubernetes.io/zone"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			segments, err := NodeLabelTopology(context.TODO(), kubeClient, tt.nodeName, tt.labels)
+			if tt.expectErr {
+				if err == nil {
+					t1.Fatalf("expected error, got segments %v", segments)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(segments, tt.expectedSegments) {
+				t1.Errorf("expected segments %v, got %v", tt.expectedSegments, segments)
+			}
+		})
+	}
+}
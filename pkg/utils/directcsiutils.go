@@ -32,10 +32,17 @@ var (
 	PodNameLabel      = NewDirectCSILabel("pod.name")
 	PodNamespaceLabel = NewDirectCSILabel("pod.namespace")
 
-	NodeLabel       = NewDirectCSILabel("node")
-	DriveLabel      = NewDirectCSILabel("drive")
-	DrivePathLabel  = NewDirectCSILabel("path")
-	AccessTierLabel = NewDirectCSILabel("access-tier")
+	NodeLabel        = NewDirectCSILabel("node")
+	DriveLabel       = NewDirectCSILabel("drive")
+	DrivePathLabel   = NewDirectCSILabel("path")
+	AccessTierLabel  = NewDirectCSILabel("access-tier")
+	ReservedForLabel = NewDirectCSILabel("reserved-for")
+	TenantLabel      = NewDirectCSILabel("tenant")
+	// PVCNameLabel and PVCNamespaceLabel are the claim a volume is provisioned for
+	PVCNameLabel      = NewDirectCSILabel("pvc.name")
+	PVCNamespaceLabel = NewDirectCSILabel("pvc.namespace")
+	// UnschedulableLabel cordons a drive, no new volumes are scheduled on it
+	UnschedulableLabel = NewDirectCSILabel("unschedulable")
 
 	VersionLabel   = NewDirectCSILabel("version")
 	CreatedByLabel = NewDirectCSILabel("created-by")
@@ -80,3 +87,7 @@ func DirectCSIDriveTypeMeta() metav1.TypeMeta {
 func DirectCSIVolumeTypeMeta() metav1.TypeMeta {
 	return NewTypeMeta(DirectCSIGroupVersion, "DirectCSIVolume")
 }
+
+func DirectCSISnapshotTypeMeta() metav1.TypeMeta {
+	return NewTypeMeta(DirectCSIGroupVersion, "DirectCSISnapshot")
+}
@@ -0,0 +1,77 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grpc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	schemeUnix = "unix"
+	schemeTCP  = "tcp"
+)
+
+// ParseEndpoint returns the network and the address of the CSI endpoint. The supported endpoints are
+//
+//	unix:///path/to/csi.sock    unix socket at the path
+//	unix://@name, @name         linux abstract unix socket
+//	tcp://host:port             tcp socket, only meant for testing and debugging
+func ParseEndpoint(endpoint string) (network, address string, err error) {
+	if strings.HasPrefix(endpoint, "@") {
+		endpoint = schemeUnix + "://" + endpoint
+	}
+	parts := strings.SplitN(endpoint, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid endpoint %q; must be one of unix:///path, unix://@name, @name or tcp://host:port", endpoint)
+	}
+	scheme, address := strings.ToLower(parts[0]), parts[1]
+	switch scheme {
+	case schemeUnix:
+		if address == "@" {
+			return "", "", fmt.Errorf("invalid endpoint %q; abstract socket name is missing", endpoint)
+		}
+	case schemeTCP:
+		if !strings.Contains(address, ":") {
+			return "", "", fmt.Errorf("invalid endpoint %q; port is missing", endpoint)
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported scheme %q of endpoint %q; must be one of %s or %s", scheme, endpoint, schemeUnix, schemeTCP)
+	}
+	return scheme, address, nil
+}
+
+// isSocketFile returns true for the addresses of unix sockets backed by a file
+func isSocketFile(network, address string) bool {
+	return network == schemeUnix && !strings.HasPrefix(address, "@")
+}
+
+// removeStaleSocket removes the socket file left by a previous run, other files are not touched
+func removeStaleSocket(address string) error {
+	fi, err := os.Lstat(address)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("cannot listen on %s; file exists and is not a socket", address)
+	}
+	return os.Remove(address)
+}
@@ -0,0 +1,97 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grpc
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEndpoint(t1 *testing.T) {
+	testCases := []struct {
+		name            string
+		endpoint        string
+		expectedNetwork string
+		expectedAddress string
+		expectErr       bool
+	}{
+		{"unix", "unix:///csi/csi.sock", "unix", "/csi/csi.sock", false},
+		{"unix_relative", "unix://csi/csi.sock", "unix", "csi/csi.sock", false},
+		{"abstract", "unix://@direct-csi", "unix", "@direct-csi", false},
+		{"abstract_short", "@direct-csi", "unix", "@direct-csi", false},
+		{"tcp", "tcp://127.0.0.1:10000", "tcp", "127.0.0.1:10000", false},
+		{"tcp_upper", "TCP://:10000", "tcp", ":10000", false},
+		{"tcp_no_port", "tcp://localhost", "", "", true},
+		{"abstract_no_name", "@", "", "", true},
+		{"no_scheme", "/csi/csi.sock", "", "", true},
+		{"empty_address", "unix://", "", "", true},
+		{"unknown_scheme", "udp://127.0.0.1:10000", "", "", true},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t *testing.T) {
+			network, address, err := ParseEndpoint(tt.endpoint)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error for endpoint %q", tt.endpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if network != tt.expectedNetwork || address != tt.expectedAddress {
+				t.Errorf("expected %s %s, got %s %s", tt.expectedNetwork, tt.expectedAddress, network, address)
+			}
+		})
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	socket := filepath.Join(dir, "csi.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("unable to listen on %s: %v", socket, err)
+	}
+	// leave the socket file behind as a crashed driver would
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	if err := removeStaleSocket(socket); err != nil {
+		t.Fatalf("unable to remove the stale socket: %v", err)
+	}
+	if _, err := os.Lstat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected the stale socket to be removed, got %v", err)
+	}
+
+	if err := removeStaleSocket(socket); err != nil {
+		t.Errorf("unexpected error for a missing socket: %v", err)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte{}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeStaleSocket(file); err == nil {
+		t.Errorf("expected an error for a regular file")
+	}
+	if _, err := os.Lstat(file); err != nil {
+		t.Errorf("expected the regular file to be retained, got %v", err)
+	}
+}
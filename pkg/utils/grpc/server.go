@@ -19,33 +19,30 @@ package grpc
 import (
 	"context"
 	"net"
-	"net/url"
 	"os"
 
 	"google.golang.org/grpc"
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/kubernetes-csi/csi-lib-utils/protosanitizer"
 )
 
 func Run(ctx context.Context, endpoint string, identity csi.IdentityServer, controller csi.ControllerServer, node csi.NodeServer) error {
-	parsedURL, err := url.Parse(endpoint)
+	network, address, err := ParseEndpoint(endpoint)
 	if err != nil {
 		return err
 	}
 
 	klog.V(5).Infof("listening on: %v", endpoint)
-	if parsedURL.Scheme == "unix" {
-		if err := os.Remove(parsedURL.RequestURI()); err != nil {
-			if !os.IsNotExist(err) {
-				return err
-			}
+	if isSocketFile(network, address) {
+		if err := removeStaleSocket(address); err != nil {
+			return err
 		}
 	}
 
 	lc := &net.ListenConfig{}
-	listener, err := lc.Listen(ctx, parsedURL.Scheme, parsedURL.RequestURI())
+	listener, err := lc.Listen(ctx, network, address)
 	if err != nil {
 		return err
 	}
@@ -58,7 +55,9 @@ func Run(ctx context.Context, endpoint string, identity csi.IdentityServer, cont
 	go func() {
 		<-ctx.Done()
 		server.GracefulStop()
-		os.Remove(parsedURL.Host)
+		if isSocketFile(network, address) {
+			os.Remove(address)
+		}
 	}()
 
 	if identity != nil {
@@ -60,7 +60,7 @@ func Init() {
 	}
 
 	kubeConfig := GetKubeConfig()
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfig)
+	config, err := buildConfig(kubeConfig, GetKubeContext())
 	if err != nil {
 		config, err = rest.InClusterConfig()
 		if err != nil {
@@ -125,6 +125,17 @@ func GetKubeConfig() string {
 	return kubeConfig
 }
 
+// GetKubeContext returns the kubeconfig context to be used, empty value refers to the current context
+func GetKubeContext() string {
+	return viper.GetString("context")
+}
+
+func buildConfig(kubeConfig, kubeContext string) (*rest.Config, error) {
+	loadingRules := &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfig}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
 func GetGroupKindVersions(group, kind string, versions ...string) (*schema.GroupVersionKind, error) {
 	discoveryClient := GetDiscoveryClient()
 	apiGroupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
@@ -0,0 +1,155 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	jsonFormatter "encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+)
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// jsonLogger is a logr.Logger which emits every entry as a JSON line. klog hands
+// the entries over to it before writing any text output, hence every entry is
+// emitted once whatever the klog flags are.
+type jsonLogger struct {
+	out       io.Writer
+	mutex     *sync.Mutex
+	name      string
+	level     int
+	callDepth int
+	values    []interface{}
+}
+
+// NewJSONLogger returns a logr.Logger which writes the entries as JSON lines to out
+func NewJSONLogger(out io.Writer) logr.Logger {
+	return &jsonLogger{
+		out:   out,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Enabled is always true, the verbosity is filtered by klog before the entries reach the logger
+func (l *jsonLogger) Enabled() bool {
+	return true
+}
+
+func (l *jsonLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.write("info", nil, msg, keysAndValues)
+}
+
+func (l *jsonLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.write("error", err, msg, keysAndValues)
+}
+
+func (l *jsonLogger) V(level int) logr.Logger {
+	logger := *l
+	logger.level += level
+	return &logger
+}
+
+func (l *jsonLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	logger := *l
+	logger.values = append(append([]interface{}{}, l.values...), keysAndValues...)
+	return &logger
+}
+
+func (l *jsonLogger) WithName(name string) logr.Logger {
+	logger := *l
+	if logger.name != "" {
+		name = logger.name + "/" + name
+	}
+	logger.name = name
+	return &logger
+}
+
+func (l *jsonLogger) WithCallDepth(depth int) logr.Logger {
+	logger := *l
+	logger.callDepth += depth
+	return &logger
+}
+
+func (l *jsonLogger) write(level string, err error, msg string, keysAndValues []interface{}) {
+	entry := map[string]interface{}{
+		"ts":    time.Now().UTC().Format(time.RFC3339Nano),
+		"level": level,
+		// klog terminates the messages with a newline
+		"msg": strings.TrimSuffix(msg, "\n"),
+	}
+	// skip write() and Info() or Error()
+	if _, file, line, ok := runtime.Caller(l.callDepth + 2); ok {
+		entry["caller"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	if l.level > 0 {
+		entry["v"] = l.level
+	}
+	if l.name != "" {
+		entry["logger"] = l.name
+	}
+	if err != nil {
+		entry["error"] = err.Error()
+	}
+	keysAndValues = append(append([]interface{}{}, l.values...), keysAndValues...)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		value := keysAndValues[i+1]
+		if valueErr, ok := value.(error); ok {
+			value = valueErr.Error()
+		}
+		entry[fmt.Sprint(keysAndValues[i])] = value
+	}
+
+	data, mErr := jsonFormatter.Marshal(entry)
+	if mErr != nil {
+		// fall back to the printed values for the values which cannot be encoded
+		for i := 0; i+1 < len(keysAndValues); i += 2 {
+			entry[fmt.Sprint(keysAndValues[i])] = fmt.Sprintf("%+v", keysAndValues[i+1])
+		}
+		if data, mErr = jsonFormatter.Marshal(entry); mErr != nil {
+			return
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.out.Write(append(data, '\n'))
+}
+
+// SetLogFormat switches the klog output to the given format. In the JSON format
+// all the entries are routed to a JSON logger instead of the klog text output.
+func SetLogFormat(format string) error {
+	switch format {
+	case LogFormatText:
+	case LogFormatJSON:
+		klog.SetLogger(NewJSONLogger(os.Stderr))
+	default:
+		return fmt.Errorf("unsupported log format '%s'. should be one of %s|%s", format, LogFormatText, LogFormatJSON)
+	}
+	return nil
+}
@@ -19,9 +19,12 @@ package utils
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/sys"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -47,6 +50,94 @@ func ValidateAccessTier(at string) (directcsi.AccessTier, error) {
 	}
 }
 
+// MountPropagationParameter is the storage class parameter to set the mount propagation of the published volumes
+const MountPropagationParameter = "direct-csi-min-io/mount-propagation"
+
+// ValidateMountPropagation validates the value against the mount propagation modes allowed by Kubernetes,
+// an empty value defaults to None
+func ValidateMountPropagation(value string) (sys.MountPropagation, error) {
+	switch propagation := sys.MountPropagation(value); propagation {
+	case "":
+		return sys.MountPropagationNone, nil
+	case sys.MountPropagationNone, sys.MountPropagationHostToContainer, sys.MountPropagationBidirectional:
+		return propagation, nil
+	default:
+		return sys.MountPropagationNone, fmt.Errorf("Invalid mount propagation %q, Please set any one among ['None','HostToContainer','Bidirectional']", value)
+	}
+}
+
+// InodeLimitParameter is the storage class parameter to limit the number of inodes of the volumes by their project quota
+const InodeLimitParameter = "direct-csi-min-io/inode-limit"
+
+// ParseInodeLimit parses the inode limit of the volumes, an empty value does not limit the inodes
+func ParseInodeLimit(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("Invalid inode limit %q, Please set a positive number of inodes", value)
+	}
+	return limit, nil
+}
+
+// ReservedPercentParameter is the storage class parameter to keep a percentage of the capacity of the drives free of volumes
+const ReservedPercentParameter = "direct-csi-min-io/reserved-percent"
+
+// MaxReservedPercent limits the headroom reserved on the drives, which is never allocated to volumes
+const MaxReservedPercent = 50
+
+// ParseReservedPercent parses the percentage of the drive capacity reserved as headroom, an empty value reserves none
+func ParseReservedPercent(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	percent, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || percent < 0 || percent > MaxReservedPercent {
+		return 0, fmt.Errorf("Invalid reserved percent %q, Please set a percentage between 0 and %d", value, MaxReservedPercent)
+	}
+	return percent, nil
+}
+
+// ReservedCapacity returns the headroom kept free on a drive of the total capacity for the reserved percentage
+func ReservedCapacity(totalCapacity, reservedPercent int64) int64 {
+	if reservedPercent <= 0 {
+		return 0
+	}
+	return totalCapacity / 100 * reservedPercent
+}
+
+// FSGroupParameter is the storage class parameter to set the group owning the files of the staged volumes
+const FSGroupParameter = "direct-csi-min-io/fs-group"
+
+// FSGroupChangePolicyParameter is the storage class parameter to set when the group ownership of the volumes is changed
+const FSGroupChangePolicyParameter = "direct-csi-min-io/fs-group-change-policy"
+
+// ParseFSGroup parses the group id owning the volumes, an empty value leaves the ownership as is and returns -1
+func ParseFSGroup(value string) (int64, error) {
+	if value == "" {
+		return -1, nil
+	}
+	gid, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || gid < 0 {
+		return -1, fmt.Errorf("Invalid fs group %q, Please set a non-negative group id", value)
+	}
+	return gid, nil
+}
+
+// ValidateFSGroupChangePolicy validates the value against the fsGroupChangePolicy values allowed by Kubernetes,
+// an empty value defaults to OnRootMismatch
+func ValidateFSGroupChangePolicy(value string) (sys.FSGroupChangePolicy, error) {
+	switch policy := sys.FSGroupChangePolicy(value); policy {
+	case "":
+		return sys.FSGroupChangeOnRootMismatch, nil
+	case sys.FSGroupChangeOnRootMismatch, sys.FSGroupChangeAlways:
+		return policy, nil
+	default:
+		return sys.FSGroupChangeOnRootMismatch, fmt.Errorf("Invalid fs group change policy %q, Please set any one among ['OnRootMismatch','Always']", value)
+	}
+}
+
 func defaultIfZero(left, right interface{}) interface{} {
 	lval := reflect.ValueOf(left)
 	if lval.IsZero() {
@@ -17,9 +17,21 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/direct-csi/pkg/sys"
 )
 
 func TestVolumeStatusTransitions(t1 *testing.T) {
@@ -74,3 +86,275 @@ func TestVolumeStatusTransitions(t1 *testing.T) {
 	}
 
 }
+
+func TestValidateMountPropagation(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    sys.MountPropagation
+		expectedErr bool
+	}{
+		{name: "test1", value: "", expected: sys.MountPropagationNone},
+		{name: "test2", value: "None", expected: sys.MountPropagationNone},
+		{name: "test3", value: "HostToContainer", expected: sys.MountPropagationHostToContainer},
+		{name: "test4", value: "Bidirectional", expected: sys.MountPropagationBidirectional},
+		{name: "test5", value: "rshared", expected: sys.MountPropagationNone, expectedErr: true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			propagation, err := ValidateMountPropagation(tt.value)
+			if (err != nil) != tt.expectedErr {
+				t1.Fatalf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if propagation != tt.expected {
+				t1.Errorf("Test case name %s: Expected propagation = %s, got %s", tt.name, tt.expected, propagation)
+			}
+		})
+	}
+}
+
+func TestParseInodeLimit(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    int64
+		expectedErr bool
+	}{
+		{name: "test1", value: "", expected: 0},
+		{name: "test2", value: "1000", expected: 1000},
+		{name: "test3", value: "0", expectedErr: true},
+		{name: "test4", value: "-1", expectedErr: true},
+		{name: "test5", value: "1k", expectedErr: true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			limit, err := ParseInodeLimit(tt.value)
+			if (err != nil) != tt.expectedErr {
+				t1.Fatalf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if limit != tt.expected {
+				t1.Errorf("Test case name %s: Expected limit = %d, got %d", tt.name, tt.expected, limit)
+			}
+		})
+	}
+}
+
+func TestParseReservedPercent(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    int64
+		expectedErr bool
+	}{
+		{name: "test1", value: "", expected: 0},
+		{name: "test2", value: "10", expected: 10},
+		{name: "test3", value: "0", expected: 0},
+		{name: "test4", value: "51", expectedErr: true},
+		{name: "test5", value: "-1", expectedErr: true},
+		{name: "test6", value: "10%", expectedErr: true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			percent, err := ParseReservedPercent(tt.value)
+			if (err != nil) != tt.expectedErr {
+				t1.Fatalf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if percent != tt.expected {
+				t1.Errorf("Test case name %s: Expected percent = %d, got %d", tt.name, tt.expected, percent)
+			}
+		})
+	}
+}
+
+func TestParseFSGroup(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    int64
+		expectedErr bool
+	}{
+		{name: "test1", value: "", expected: -1},
+		{name: "test2", value: "2000", expected: 2000},
+		{name: "test3", value: "0", expected: 0},
+		{name: "test4", value: "-1", expected: -1, expectedErr: true},
+		{name: "test5", value: "staff", expected: -1, expectedErr: true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			gid, err := ParseFSGroup(tt.value)
+			if (err != nil) != tt.expectedErr {
+				t1.Fatalf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if gid != tt.expected {
+				t1.Errorf("Test case name %s: Expected fs group = %d, got %d", tt.name, tt.expected, gid)
+			}
+		})
+	}
+}
+
+func TestValidateFSGroupChangePolicy(t1 *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    sys.FSGroupChangePolicy
+		expectedErr bool
+	}{
+		{name: "test1", value: "", expected: sys.FSGroupChangeOnRootMismatch},
+		{name: "test2", value: "OnRootMismatch", expected: sys.FSGroupChangeOnRootMismatch},
+		{name: "test3", value: "Always", expected: sys.FSGroupChangeAlways},
+		{name: "test4", value: "always", expected: sys.FSGroupChangeOnRootMismatch, expectedErr: true},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			policy, err := ValidateFSGroupChangePolicy(tt.value)
+			if (err != nil) != tt.expectedErr {
+				t1.Fatalf("Test case name %s: Expected error = %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			if policy != tt.expected {
+				t1.Errorf("Test case name %s: Expected policy = %s, got %s", tt.name, tt.expected, policy)
+			}
+		})
+	}
+}
+
+func TestJSONLogger(t1 *testing.T) {
+	testCases := []struct {
+		name     string
+		log      func(logger logr.Logger)
+		expected map[string]interface{}
+	}{
+		{
+			name: "test1",
+			log: func(logger logr.Logger) {
+				logger.Info("drive mounted\n", "drive", "sdb")
+			},
+			expected: map[string]interface{}{"level": "info", "msg": "drive mounted", "drive": "sdb"},
+		},
+		{
+			name: "test2",
+			log: func(logger logr.Logger) {
+				logger.Error(errors.New("device busy"), "failed to mount drive")
+			},
+			expected: map[string]interface{}{"level": "error", "msg": "failed to mount drive", "error": "device busy"},
+		},
+		{
+			name: "test3",
+			log: func(logger logr.Logger) {
+				logger.V(3).WithName("node").WithValues("drive", "sdb").Info("drive probed")
+			},
+			expected: map[string]interface{}{"level": "info", "msg": "drive probed", "v": float64(3), "logger": "node", "drive": "sdb"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			var buf bytes.Buffer
+			tt.log(NewJSONLogger(&buf))
+			if strings.Count(buf.String(), "\n") != 1 {
+				t1.Fatalf("Test case name %s: Expected a single line, got %s", tt.name, buf.String())
+			}
+			entry := map[string]interface{}{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t1.Fatalf("Test case name %s: Invalid JSON output %s: %v", tt.name, buf.String(), err)
+			}
+			if !strings.HasPrefix(fmt.Sprint(entry["caller"]), "utils_test.go:") {
+				t1.Errorf("Test case name %s: Expected the caller in utils_test.go, got %v", tt.name, entry["caller"])
+			}
+			delete(entry, "ts")
+			delete(entry, "caller")
+			if !reflect.DeepEqual(entry, tt.expected) {
+				t1.Errorf("Test case name %s: Expected %v, got %v", tt.name, tt.expected, entry)
+			}
+		})
+	}
+}
+
+func TestBuildConfig(t1 *testing.T) {
+	kubeConfigData := `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-1
+  cluster:
+    server: https://cluster-1:6443
+- name: cluster-2
+  cluster:
+    server: https://cluster-2:6443
+users:
+- name: user
+  user:
+    token: token
+contexts:
+- name: context-1
+  context:
+    cluster: cluster-1
+    user: user
+- name: context-2
+  context:
+    cluster: cluster-2
+    user: user
+current-context: context-1
+`
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t1.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	kubeConfig := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(kubeConfig, []byte(kubeConfigData), 0644); err != nil {
+		t1.Fatalf("unable to write kubeconfig: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		kubeConfig   string
+		kubeContext  string
+		expectedHost string
+		expectErr    bool
+	}{
+		{
+			name:         "currentContext",
+			kubeConfig:   kubeConfig,
+			expectedHost: "https://cluster-1:6443",
+		},
+		{
+			name:         "overriddenContext",
+			kubeConfig:   kubeConfig,
+			kubeContext:  "context-2",
+			expectedHost: "https://cluster-2:6443",
+		},
+		{
+			name:        "invalidContext",
+			kubeConfig:  kubeConfig,
+			kubeContext: "context-3",
+			expectErr:   true,
+		},
+		{
+			name:       "missingKubeConfig",
+			kubeConfig: filepath.Join(dir, "missing"),
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			config, err := buildConfig(tt.kubeConfig, tt.kubeContext)
+			if tt.expectErr {
+				if err == nil {
+					t1.Errorf("Test case name %s: expected error, got host %s", tt.name, config.Host)
+				}
+				return
+			}
+			if err != nil {
+				t1.Fatalf("Test case name %s: unexpected error: %v", tt.name, err)
+			}
+			if config.Host != tt.expectedHost {
+				t1.Errorf("Test case name %s: expected host = %s, got %s", tt.name, tt.expectedHost, config.Host)
+			}
+		})
+	}
+}
@@ -38,11 +38,14 @@ func ToYAML(obj interface{}) (string, error) {
 	return string(formattedObj), nil
 }
 
+// LogYAML prints the object as a YAML document, the documents printed one after another
+// form a stream which can be applied with 'kubectl apply -f'
 func LogYAML(obj interface{}) error {
 	y, err := ToYAML(obj)
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(y))
+	fmt.Println("---")
+	fmt.Print(y)
 	return nil
 }
@@ -18,20 +18,23 @@ package volume
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
 	"github.com/minio/direct-csi/pkg/clientset"
+	"github.com/minio/direct-csi/pkg/health"
 	"github.com/minio/direct-csi/pkg/listener"
+	"github.com/minio/direct-csi/pkg/sys"
 	"github.com/minio/direct-csi/pkg/utils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/util/retry"
 
-	"k8s.io/klog"
+	"k8s.io/klog/v2"
 )
 
 type VolumeUpdateType int
@@ -40,13 +43,27 @@ const (
 	directCSIVolumeKind = "DirectCSIVolume"
 
 	VolumeUpdateTypeDeleting VolumeUpdateType = iota
+	VolumeUpdateTypeMigrate
 	VolumeUpdateTypeUnknown
 )
 
+type quotaRemover func(ctx context.Context, path, projectID string) error
+
+func removeVolumeQuota(ctx context.Context, path, projectID string) error {
+	quota, err := sys.NewQuota(path, projectID)
+	if err != nil {
+		return err
+	}
+	return quota.RemoveQuota(ctx)
+}
+
 type DirectCSIVolumeListener struct {
 	kubeClient      kubeclientset.Interface
 	directcsiClient clientset.Interface
 	nodeID          string
+	removeQuota     quotaRemover
+	setQuota        quotaSetter
+	copyDir         dirCopier
 }
 
 func (b *DirectCSIVolumeListener) InitializeKubeClient(k kubeclientset.Interface) {
@@ -63,7 +80,6 @@ func (b *DirectCSIVolumeListener) Add(ctx context.Context, obj *directcsi.Direct
 
 func (b *DirectCSIVolumeListener) Update(ctx context.Context, old, new *directcsi.DirectCSIVolume) error {
 	directCSIClient := b.directcsiClient.DirectV1beta2()
-	dclient := directCSIClient.DirectCSIDrives()
 	vclient := directCSIClient.DirectCSIVolumes()
 
 	// Skip volumes from other nodes
@@ -71,62 +87,33 @@ func (b *DirectCSIVolumeListener) Update(ctx context.Context, old, new *directcs
 		return nil
 	}
 
-	rmVolFromDrive := func(driveName string, volumeName string, capacity int64) error {
-		drive, err := dclient.Get(ctx, driveName, metav1.GetOptions{
+	cleanupVolume := func(vol *directcsi.DirectCSIVolume) error {
+		drive, err := directCSIClient.DirectCSIDrives().Get(ctx, vol.Status.Drive, metav1.GetOptions{
 			TypeMeta: utils.DirectCSIDriveTypeMeta(),
 		})
 		if err != nil {
 			return err
 		}
-		vFinalizer := directcsi.DirectCSIDriveFinalizerPrefix + volumeName
-
-		dfinalizers := drive.GetFinalizers()
-
-		// check if finalizer has already been removed
-		found := false
-		for _, df := range dfinalizers {
-			if df == vFinalizer {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return nil
+		// the volume directory is created under the drive mountpoint, the host path is
+		// cleared by NodeUnstageVolume and cannot be relied on
+		mountpoint := drive.Status.Mountpoint
+		if mountpoint == "" && vol.Status.HostPath != "" {
+			mountpoint = filepath.Dir(vol.Status.HostPath)
 		}
-
-		// if not, remove finalizer
-		updatedFinalizers := []string{}
-		for _, df := range dfinalizers {
-			if df == vFinalizer {
-				continue
+		if mountpoint != "" {
+			// no quota is set for the volumes staged without project quota support
+			if !vol.Status.QuotaUnenforced {
+				if err := b.removeQuota(ctx, mountpoint, vol.Name); err != nil {
+					// a stale quota does not limit the other volumes, it must not block the deletion
+					klog.Warningf("unable to remove the quota of volume %s on drive %s: %v", vol.Name, drive.Name, err)
+				}
 			}
-			updatedFinalizers = append(updatedFinalizers, df)
-		}
-		if len(updatedFinalizers) == 1 {
-			if updatedFinalizers[0] == directcsi.DirectCSIDriveFinalizerDataProtection {
-				drive.Status.DriveStatus = directcsi.DriveStatusReady
+			if err := os.RemoveAll(filepath.Join(mountpoint, vol.Name)); err != nil {
+				return err
 			}
 		}
-		drive.SetFinalizers(updatedFinalizers)
 
-		drive.Status.FreeCapacity = drive.Status.FreeCapacity + capacity
-		drive.Status.AllocatedCapacity = drive.Status.TotalCapacity - drive.Status.FreeCapacity
-
-		_, err = dclient.Update(ctx, drive, metav1.UpdateOptions{
-			TypeMeta: utils.DirectCSIDriveTypeMeta(),
-		})
-		if err != nil {
-			return err
-		}
-		return nil
-	}
-
-	cleanupVolume := func(vol *directcsi.DirectCSIVolume) error {
-		if err := os.RemoveAll(vol.Status.HostPath); err != nil {
-			return err
-		}
-
-		return rmVolFromDrive(vol.Status.Drive, vol.Name, vol.Status.TotalCapacity)
+		return b.rmVolFromDrive(ctx, vol.Status.Drive, vol.Name, vol.Status.TotalCapacity)
 	}
 
 	deleting := func() bool {
@@ -140,6 +127,9 @@ func (b *DirectCSIVolumeListener) Update(ctx context.Context, old, new *directcs
 		if deleting() {
 			return VolumeUpdateTypeDeleting
 		}
+		if _, found := new.GetAnnotations()[directcsi.DirectCSIVolumeAnnotationMigrateTo]; found {
+			return VolumeUpdateTypeMigrate
+		}
 		return VolumeUpdateTypeUnknown
 	}
 	switch volumeUpdateType() {
@@ -189,28 +179,99 @@ func (b *DirectCSIVolumeListener) Update(ctx context.Context, old, new *directcs
 		if err != nil {
 			return err
 		}
+	case VolumeUpdateTypeMigrate:
+		return b.migrateVolume(ctx, new, new.GetAnnotations()[directcsi.DirectCSIVolumeAnnotationMigrateTo])
 	case VolumeUpdateTypeUnknown:
 	}
 
 	return nil
 }
 
+// rmVolFromDrive removes the finalizer of the volume from the drive and releases its capacity
+func (b *DirectCSIVolumeListener) rmVolFromDrive(ctx context.Context, driveName string, volumeName string, capacity int64) error {
+	dclient := b.directcsiClient.DirectV1beta2().DirectCSIDrives()
+	drive, err := dclient.Get(ctx, driveName, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	vFinalizer := directcsi.DirectCSIDriveFinalizerPrefix + volumeName
+
+	dfinalizers := drive.GetFinalizers()
+
+	// check if finalizer has already been removed
+	found := false
+	for _, df := range dfinalizers {
+		if df == vFinalizer {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	// if not, remove finalizer
+	updatedFinalizers := []string{}
+	for _, df := range dfinalizers {
+		if df == vFinalizer {
+			continue
+		}
+		updatedFinalizers = append(updatedFinalizers, df)
+	}
+	if len(updatedFinalizers) == 1 {
+		if updatedFinalizers[0] == directcsi.DirectCSIDriveFinalizerDataProtection {
+			drive.Status.DriveStatus = directcsi.DriveStatusReady
+		}
+	}
+	drive.SetFinalizers(updatedFinalizers)
+
+	drive.Status.FreeCapacity = drive.Status.FreeCapacity + capacity
+	// the headroom reserved at format is neither free nor allocated
+	drive.Status.AllocatedCapacity = drive.Status.TotalCapacity - drive.Status.FreeCapacity - drive.Status.ReservedCapacity
+
+	_, err = dclient.Update(ctx, drive, metav1.UpdateOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (b *DirectCSIVolumeListener) Delete(ctx context.Context, obj *directcsi.DirectCSIVolume) error {
 	return nil
 }
 
-func StartVolumeController(ctx context.Context, nodeID string) error {
+func StartVolumeController(ctx context.Context, nodeID string, timings listener.ControllerTimings) error {
 	hostname, err := os.Hostname()
 	if err != nil {
 		return err
 	}
-	ctrl, err := listener.NewDefaultDirectCSIController("volume-controller", hostname, 40)
-	if err != nil {
-		klog.Error(err)
-		return err
+	for {
+		ctrl, err := listener.NewDefaultDirectCSIController("volume-controller", hostname, 40, timings)
+		if err != nil {
+			klog.Error(err)
+			return err
+		}
+		ctrl.AddDirectCSIVolumeListener(&DirectCSIVolumeListener{
+			nodeID:      nodeID,
+			removeQuota: removeVolumeQuota,
+			setQuota:    setVolumeQuota,
+			copyDir:     copyVolumeDir,
+		})
+		health.RegisterReadinessCheck("volume-controller", func() error {
+			if !ctrl.HasSynced() {
+				return errors.New("volume controller caches are not synced")
+			}
+			return nil
+		})
+		// contend for the leadership again if another replica took it over
+		if err := ctrl.Run(ctx); !errors.Is(err, listener.ErrLeadershipLost) {
+			return err
+		}
 	}
-	ctrl.AddDirectCSIVolumeListener(&DirectCSIVolumeListener{nodeID: nodeID})
-	return ctrl.Run(ctx)
 }
 
 func SyncVolumes(ctx context.Context, nodeID string) {
@@ -18,6 +18,10 @@ package volume
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/minio/direct-csi/pkg/utils"
@@ -40,14 +44,28 @@ const (
 	testNodeName = "test-node"
 )
 
+type fakeQuotaRemover struct {
+	removed map[string]string
+	err     error
+}
+
+func (r *fakeQuotaRemover) removeQuota(ctx context.Context, path, projectID string) error {
+	r.removed[projectID] = path
+	return r.err
+}
+
 func createFakeVolumeListener() *DirectCSIVolumeListener {
 	utils.SetFake()
 	fakeKubeClnt := utils.GetKubeClient()
 	fakeDirectCSIClnt := utils.GetDirectClientset()
+	fakeRemover := &fakeQuotaRemover{removed: map[string]string{}}
 	return &DirectCSIVolumeListener{
 		kubeClient:      fakeKubeClnt,
 		directcsiClient: fakeDirectCSIClnt,
 		nodeID:          testNodeName,
+		removeQuota:     fakeRemover.removeQuota,
+		setQuota:        func(ctx context.Context, path, projectID string, limit, inodeLimit int64) error { return nil },
+		copyDir:         func(ctx context.Context, src, dest string) error { return nil },
 	}
 }
 func TestUpdateVolumeDelete(t *testing.T) {
@@ -201,6 +219,120 @@ func TestUpdateVolumeDelete(t *testing.T) {
 	}
 }
 
+func TestUpdateVolumeDeleteCleanup(t1 *testing.T) {
+	testCases := []struct {
+		name            string
+		staged          bool
+		quotaUnenforced bool
+		quotaErr        error
+	}{
+		{name: "staged", staged: true},
+		// the host path is cleared by the unstage
+		{name: "unstaged"},
+		// the failures to remove the quota do not block the deletion
+		{name: "quotaerror", quotaErr: errors.New("quota not found")},
+		// no quota is removed for the volumes staged without quota
+		{name: "quotaunenforced", staged: true, quotaUnenforced: true},
+	}
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t *testing.T) {
+			testUpdateVolumeDeleteCleanup(t, tt.staged, tt.quotaUnenforced, tt.quotaErr)
+		})
+	}
+}
+
+func testUpdateVolumeDeleteCleanup(t *testing.T, staged, quotaUnenforced bool, quotaErr error) {
+	testDriveName := "test_drive"
+	testVolumeName := "test_volume"
+
+	mountpoint, err := ioutil.TempDir("", "test_drive_")
+	if err != nil {
+		t.Fatalf("Error while creating the drive mountpoint: %v", err)
+	}
+	defer os.RemoveAll(mountpoint)
+	volumeDir := filepath.Join(mountpoint, testVolumeName)
+	if err := os.Mkdir(volumeDir, 0755); err != nil {
+		t.Fatalf("Error while creating the volume directory: %v", err)
+	}
+	hostPath := ""
+	if staged {
+		hostPath = volumeDir
+	}
+
+	testObjects := []runtime.Object{
+		&directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testDriveName,
+				Finalizers: []string{
+					string(directcsi.DirectCSIDriveFinalizerDataProtection),
+					directcsi.DirectCSIDriveFinalizerPrefix + testVolumeName,
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          testNodeName,
+				DriveStatus:       directcsi.DriveStatusInUse,
+				Mountpoint:        mountpoint,
+				FreeCapacity:      mb50,
+				AllocatedCapacity: mb50,
+				TotalCapacity:     mb100,
+			},
+		},
+		&directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testVolumeName,
+				Finalizers: []string{
+					string(directcsi.DirectCSIVolumeFinalizerPurgeProtection),
+				},
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				NodeName:        testNodeName,
+				HostPath:        hostPath,
+				Drive:           testDriveName,
+				TotalCapacity:   mb20,
+				QuotaUnenforced: quotaUnenforced,
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	vl := createFakeVolumeListener()
+	fakeRemover := &fakeQuotaRemover{removed: map[string]string{}, err: quotaErr}
+	vl.removeQuota = fakeRemover.removeQuota
+	vl.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+	directCSIClient := vl.directcsiClient.DirectV1beta2()
+
+	vObj := testObjects[1].(*directcsi.DirectCSIVolume)
+	newObj := vObj.DeepCopy()
+	now := metav1.Now()
+	newObj.ObjectMeta.DeletionTimestamp = &now
+	if err := vl.Update(ctx, vObj, newObj); err != nil {
+		t.Fatalf("Error while invoking the volume update listener: %+v", err)
+	}
+
+	if _, err := os.Stat(volumeDir); !os.IsNotExist(err) {
+		t.Errorf("Volume directory %s is not removed: %v", volumeDir, err)
+	}
+	if quotaUnenforced {
+		if _, ok := fakeRemover.removed[testVolumeName]; ok {
+			t.Errorf("Quota removed for %s staged without quota. Removed quotas: %v", testVolumeName, fakeRemover.removed)
+		}
+	} else if path, ok := fakeRemover.removed[testVolumeName]; !ok || path != mountpoint {
+		t.Errorf("Quota not removed for %s at %s. Removed quotas: %v", testVolumeName, mountpoint, fakeRemover.removed)
+	}
+
+	driveObj, dErr := directCSIClient.DirectCSIDrives().Get(ctx, testDriveName, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if dErr != nil {
+		t.Fatalf("Error while getting the drive object: %+v", dErr)
+	}
+	if driveObj.Status.AllocatedCapacity != mb30 {
+		t.Errorf("Unexpected allocated capacity set. Expected: %d, Got: %d", mb30, driveObj.Status.AllocatedCapacity)
+	}
+}
+
 func TestAddAndDeleteVolumeNoOp(t *testing.T) {
 	vl := createFakeVolumeListener()
 	b := directcsi.DirectCSIVolume{
@@ -0,0 +1,294 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package volume
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	"github.com/minio/direct-csi/pkg/sys"
+	"github.com/minio/direct-csi/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"k8s.io/klog/v2"
+)
+
+// volumeLocks keep NodeStageVolume and the migration of a volume from running at the same time,
+// both run in the node server
+var volumeLocks = struct {
+	sync.Mutex
+	names map[string]struct{}
+}{names: map[string]struct{}{}}
+
+// TryLockVolume takes the lock of the volume, it returns false if the lock is held
+func TryLockVolume(name string) bool {
+	volumeLocks.Lock()
+	defer volumeLocks.Unlock()
+	if _, found := volumeLocks.names[name]; found {
+		return false
+	}
+	volumeLocks.names[name] = struct{}{}
+	return true
+}
+
+// UnlockVolume releases the lock taken by TryLockVolume
+func UnlockVolume(name string) {
+	volumeLocks.Lock()
+	defer volumeLocks.Unlock()
+	delete(volumeLocks.names, name)
+}
+
+// IsMigrationPending checks if the volume is requested to be moved to another drive
+func IsMigrationPending(vol *directcsi.DirectCSIVolume) bool {
+	target, found := vol.GetAnnotations()[directcsi.DirectCSIVolumeAnnotationMigrateTo]
+	return found && target != vol.Status.Drive
+}
+
+type quotaSetter func(ctx context.Context, path, projectID string, limit, inodeLimit int64) error
+
+type dirCopier func(ctx context.Context, src, dest string) error
+
+func setVolumeQuota(ctx context.Context, path, projectID string, limit, inodeLimit int64) error {
+	quota, err := sys.NewQuota(path, projectID)
+	if err != nil {
+		return err
+	}
+	return quota.SetQuota(ctx, limit, inodeLimit)
+}
+
+// volumeInodeLimit returns the inode limit the volume was provisioned with, the parameters of the
+// storage class are recorded in the volume attributes of its persistent volume
+func (b *DirectCSIVolumeListener) volumeInodeLimit(ctx context.Context, vol *directcsi.DirectCSIVolume) (int64, error) {
+	pv, err := b.kubeClient.CoreV1().PersistentVolumes().Get(ctx, vol.Name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	if pv.Spec.CSI == nil {
+		return 0, nil
+	}
+	return utils.ParseInodeLimit(pv.Spec.CSI.VolumeAttributes[utils.InodeLimitParameter])
+}
+
+func copyVolumeDir(ctx context.Context, src, dest string) error {
+	if output, err := sys.CopyDir(ctx, src, dest); err != nil {
+		return fmt.Errorf("unable to copy %s to %s; %v: %s", src, dest, err, output)
+	}
+	return nil
+}
+
+// isVolumeInUse checks if the volume is staged or published by the kubelet
+func isVolumeInUse(vol *directcsi.DirectCSIVolume) bool {
+	for _, c := range vol.Status.Conditions {
+		switch c.Type {
+		case string(directcsi.DirectCSIVolumeConditionStaged), string(directcsi.DirectCSIVolumeConditionPublished):
+			if c.Status == metav1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateMigration checks if the volume can be moved to the target drive
+func ValidateMigration(vol *directcsi.DirectCSIVolume, target *directcsi.DirectCSIDrive) error {
+	if isVolumeInUse(vol) {
+		return fmt.Errorf("volume %s is in use, it can be moved only when no pod is using it", vol.Name)
+	}
+	if target.Name == vol.Status.Drive {
+		return fmt.Errorf("volume %s is already on drive %s", vol.Name, target.Name)
+	}
+	if target.Status.NodeName != vol.Status.NodeName {
+		return fmt.Errorf("drive %s is on node %s, volume %s can be moved only to a drive on node %s", target.Name, target.Status.NodeName, vol.Name, vol.Status.NodeName)
+	}
+	switch target.Status.DriveStatus {
+	case directcsi.DriveStatusReady, directcsi.DriveStatusInUse:
+	default:
+		return fmt.Errorf("drive %s is %s, volumes can be moved only to ready or in-use drives", target.Name, target.Status.DriveStatus)
+	}
+	if isVolumeOnDrive(vol.Name, target) {
+		// the capacity was reserved by an earlier attempt of the migration
+		return nil
+	}
+	if target.Status.FreeCapacity < vol.Status.TotalCapacity {
+		return fmt.Errorf("drive %s has %d bytes free, volume %s needs %d bytes", target.Name, target.Status.FreeCapacity, vol.Name, vol.Status.TotalCapacity)
+	}
+	return nil
+}
+
+// isVolumeOnDrive checks if the capacity of the volume is reserved on the drive
+func isVolumeOnDrive(volumeName string, drive *directcsi.DirectCSIDrive) bool {
+	vFinalizer := directcsi.DirectCSIDriveFinalizerPrefix + volumeName
+	for _, df := range drive.GetFinalizers() {
+		if df == vFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// addVolToDrive reserves the capacity of the volume on the drive
+func (b *DirectCSIVolumeListener) addVolToDrive(ctx context.Context, driveName string, volumeName string, capacity int64) error {
+	dclient := b.directcsiClient.DirectV1beta2().DirectCSIDrives()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		drive, err := dclient.Get(ctx, driveName, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		if err != nil {
+			return err
+		}
+		if isVolumeOnDrive(volumeName, drive) {
+			// already reserved by an earlier attempt
+			return nil
+		}
+		drive.SetFinalizers(append(drive.GetFinalizers(), directcsi.DirectCSIDriveFinalizerPrefix+volumeName))
+		drive.Status.DriveStatus = directcsi.DriveStatusInUse
+		drive.Status.FreeCapacity = drive.Status.FreeCapacity - capacity
+		drive.Status.AllocatedCapacity = drive.Status.TotalCapacity - drive.Status.FreeCapacity - drive.Status.ReservedCapacity
+		_, err = dclient.Update(ctx, drive, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+		})
+		return err
+	})
+}
+
+// migrateVolume copies the data and the quota of the volume to the target drive, switches the
+// volume to the target drive in a single update and then cleans up the source drive
+func (b *DirectCSIVolumeListener) migrateVolume(ctx context.Context, vol *directcsi.DirectCSIVolume, targetDriveName string) error {
+	directCSIClient := b.directcsiClient.DirectV1beta2()
+
+	if targetDriveName == vol.Status.Drive {
+		// nothing to move, only drop the request
+		delete(vol.Annotations, directcsi.DirectCSIVolumeAnnotationMigrateTo)
+		_, err := directCSIClient.DirectCSIVolumes().Update(ctx, vol, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		})
+		return err
+	}
+
+	// NodeStageVolume refuses the volume while the migration is pending, and holds the lock
+	// until it is done if it started before the migration was requested
+	if !TryLockVolume(vol.Name) {
+		return fmt.Errorf("unable to migrate volume %s: an operation on the volume is in progress", vol.Name)
+	}
+	defer UnlockVolume(vol.Name)
+	vol, err := directCSIClient.DirectCSIVolumes().Get(ctx, vol.Name, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	if !IsMigrationPending(vol) {
+		return nil
+	}
+
+	target, err := directCSIClient.DirectCSIDrives().Get(ctx, targetDriveName, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := ValidateMigration(vol, target); err != nil {
+		return fmt.Errorf("unable to migrate volume: %v", err)
+	}
+	if target.Status.Mountpoint == "" {
+		return fmt.Errorf("unable to migrate volume: drive %s is not mounted", target.Name)
+	}
+
+	source, err := directCSIClient.DirectCSIDrives().Get(ctx, vol.Status.Drive, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if err != nil {
+		return err
+	}
+	srcPath := vol.Status.HostPath
+	if srcPath == "" {
+		srcPath = filepath.Join(source.Status.Mountpoint, vol.Name)
+	}
+	destPath := filepath.Join(target.Status.Mountpoint, vol.Name)
+
+	klog.V(3).Infof("migrating volume %s from drive %s to drive %s", vol.Name, source.Name, target.Name)
+	if err := os.MkdirAll(destPath, 0755); err != nil {
+		return err
+	}
+	if _, err := os.Stat(srcPath); err == nil {
+		if err := b.copyDir(ctx, srcPath, destPath); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if !vol.Status.QuotaUnenforced && vol.Status.TotalCapacity > 0 {
+		// the quota is set again when the volume is staged
+		inodeLimit, err := b.volumeInodeLimit(ctx, vol)
+		if err != nil {
+			klog.Warningf("unable to read the inode limit of volume %s: %v", vol.Name, err)
+		}
+		if err := b.setQuota(ctx, destPath, vol.Name, vol.Status.TotalCapacity, inodeLimit); err != nil {
+			klog.Warningf("unable to set the quota of volume %s on drive %s: %v", vol.Name, target.Name, err)
+		}
+	}
+
+	if err := b.addVolToDrive(ctx, target.Name, vol.Name, vol.Status.TotalCapacity); err != nil {
+		return err
+	}
+
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := directCSIClient.DirectCSIVolumes().Get(ctx, vol.Name, metav1.GetOptions{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		})
+		if err != nil {
+			return err
+		}
+		latest.Status.Drive = target.Name
+		latest.Status.HostPath = destPath
+		delete(latest.Annotations, directcsi.DirectCSIVolumeAnnotationMigrateTo)
+		labels := latest.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[directcsi.Group+"/drive"] = utils.SanitizeLabelV(target.Name)
+		labels[directcsi.Group+"/drive-path"] = utils.SanitizeLabelV(filepath.Base(target.Status.Path))
+		latest.SetLabels(labels)
+		_, err = directCSIClient.DirectCSIVolumes().Update(ctx, latest, metav1.UpdateOptions{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+		})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// the volume is on the target drive now, failures to clean up the source only leave stale data behind
+	if source.Status.Mountpoint != "" {
+		if err := b.removeQuota(ctx, source.Status.Mountpoint, vol.Name); err != nil {
+			klog.Warningf("unable to remove the quota of volume %s on drive %s: %v", vol.Name, source.Name, err)
+		}
+	}
+	if err := os.RemoveAll(srcPath); err != nil {
+		klog.Warningf("unable to remove the data of volume %s on drive %s: %v", vol.Name, source.Name, err)
+	}
+	if err := b.rmVolFromDrive(ctx, source.Name, vol.Name, vol.Status.TotalCapacity); err != nil {
+		return err
+	}
+	klog.V(3).Infof("migrated volume %s to drive %s", vol.Name, target.Name)
+	return nil
+}
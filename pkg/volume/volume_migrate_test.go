@@ -0,0 +1,302 @@
+// This file is part of MinIO Direct CSI
+// Copyright (c) 2021 MinIO, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package volume
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/direct-csi/pkg/utils"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	directcsi "github.com/minio/direct-csi/pkg/apis/direct.csi.min.io/v1beta2"
+	fakedirect "github.com/minio/direct-csi/pkg/clientset/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateMigration(t1 *testing.T) {
+	newVolume := func(conditions ...metav1.Condition) *directcsi.DirectCSIVolume {
+		return &directcsi.DirectCSIVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "volume"},
+			Status: directcsi.DirectCSIVolumeStatus{
+				NodeName:      testNodeName,
+				Drive:         "source",
+				TotalCapacity: mb20,
+				Conditions:    conditions,
+			},
+		}
+	}
+	newDrive := func(name, node string, status directcsi.DriveStatus, free int64) *directcsi.DirectCSIDrive {
+		return &directcsi.DirectCSIDrive{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:     node,
+				DriveStatus:  status,
+				FreeCapacity: free,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		volume      *directcsi.DirectCSIVolume
+		drive       *directcsi.DirectCSIDrive
+		expectedErr bool
+	}{
+		{
+			name:   "ready",
+			volume: newVolume(),
+			drive:  newDrive("target", testNodeName, directcsi.DriveStatusReady, mb50),
+		},
+		{
+			name:   "inuse",
+			volume: newVolume(),
+			drive:  newDrive("target", testNodeName, directcsi.DriveStatusInUse, mb20),
+		},
+		{
+			name: "published",
+			volume: newVolume(metav1.Condition{
+				Type:   string(directcsi.DirectCSIVolumeConditionPublished),
+				Status: metav1.ConditionTrue,
+			}),
+			drive:       newDrive("target", testNodeName, directcsi.DriveStatusReady, mb50),
+			expectedErr: true,
+		},
+		{
+			name: "staged",
+			volume: newVolume(metav1.Condition{
+				Type:   string(directcsi.DirectCSIVolumeConditionStaged),
+				Status: metav1.ConditionTrue,
+			}),
+			drive:       newDrive("target", testNodeName, directcsi.DriveStatusReady, mb50),
+			expectedErr: true,
+		},
+		{
+			name:        "samedrive",
+			volume:      newVolume(),
+			drive:       newDrive("source", testNodeName, directcsi.DriveStatusInUse, mb50),
+			expectedErr: true,
+		},
+		{
+			name:        "othernode",
+			volume:      newVolume(),
+			drive:       newDrive("target", "other-node", directcsi.DriveStatusReady, mb50),
+			expectedErr: true,
+		},
+		{
+			name:        "available",
+			volume:      newVolume(),
+			drive:       newDrive("target", testNodeName, directcsi.DriveStatusAvailable, mb50),
+			expectedErr: true,
+		},
+		{
+			name:        "nocapacity",
+			volume:      newVolume(),
+			drive:       newDrive("target", testNodeName, directcsi.DriveStatusReady, mb20-1),
+			expectedErr: true,
+		},
+		{
+			name:   "reserved",
+			volume: newVolume(),
+			drive: func() *directcsi.DirectCSIDrive {
+				// the capacity was reserved by an earlier attempt
+				drive := newDrive("target", testNodeName, directcsi.DriveStatusInUse, 0)
+				drive.SetFinalizers([]string{directcsi.DirectCSIDriveFinalizerPrefix + "volume"})
+				return drive
+			}(),
+		},
+	}
+
+	for _, tt := range testCases {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := ValidateMigration(tt.volume, tt.drive)
+			if tt.expectedErr && err == nil {
+				t1.Errorf("Test case name %s: expected error but got none", tt.name)
+			}
+			if !tt.expectedErr && err != nil {
+				t1.Errorf("Test case name %s: unexpected error: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestUpdateVolumeMigrate(t *testing.T) {
+	testVolumeName := "test_volume"
+
+	sourceMountpoint, err := ioutil.TempDir("", "test_source_")
+	if err != nil {
+		t.Fatalf("Error while creating the source mountpoint: %v", err)
+	}
+	defer os.RemoveAll(sourceMountpoint)
+	targetMountpoint, err := ioutil.TempDir("", "test_target_")
+	if err != nil {
+		t.Fatalf("Error while creating the target mountpoint: %v", err)
+	}
+	defer os.RemoveAll(targetMountpoint)
+	hostPath := filepath.Join(sourceMountpoint, testVolumeName)
+	if err := os.Mkdir(hostPath, 0755); err != nil {
+		t.Fatalf("Error while creating the volume directory: %v", err)
+	}
+
+	testObjects := []runtime.Object{
+		&directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "source",
+				Finalizers: []string{
+					string(directcsi.DirectCSIDriveFinalizerDataProtection),
+					directcsi.DirectCSIDriveFinalizerPrefix + testVolumeName,
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:          testNodeName,
+				DriveStatus:       directcsi.DriveStatusInUse,
+				Mountpoint:        sourceMountpoint,
+				Path:              "/dev/sdb",
+				FreeCapacity:      mb30,
+				AllocatedCapacity: mb20,
+				TotalCapacity:     mb50,
+			},
+		},
+		&directcsi.DirectCSIDrive{
+			TypeMeta: utils.DirectCSIDriveTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "target",
+				Finalizers: []string{
+					string(directcsi.DirectCSIDriveFinalizerDataProtection),
+				},
+			},
+			Status: directcsi.DirectCSIDriveStatus{
+				NodeName:      testNodeName,
+				DriveStatus:   directcsi.DriveStatusReady,
+				Mountpoint:    targetMountpoint,
+				Path:          "/dev/sdc",
+				FreeCapacity:  mb50,
+				TotalCapacity: mb50,
+			},
+		},
+		&directcsi.DirectCSIVolume{
+			TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testVolumeName,
+				Annotations: map[string]string{
+					directcsi.DirectCSIVolumeAnnotationMigrateTo: "target",
+				},
+				Labels: map[string]string{
+					directcsi.Group + "/drive":      "source",
+					directcsi.Group + "/drive-path": "sdb",
+				},
+			},
+			Status: directcsi.DirectCSIVolumeStatus{
+				NodeName:      testNodeName,
+				HostPath:      hostPath,
+				Drive:         "source",
+				TotalCapacity: mb20,
+			},
+		},
+	}
+
+	ctx := context.TODO()
+	vl := createFakeVolumeListener()
+	fakeRemover := &fakeQuotaRemover{removed: map[string]string{}}
+	vl.removeQuota = fakeRemover.removeQuota
+	copied := map[string]string{}
+	vl.copyDir = func(ctx context.Context, src, dest string) error {
+		copied[src] = dest
+		return nil
+	}
+	inodeLimits := map[string]int64{}
+	vl.setQuota = func(ctx context.Context, path, projectID string, limit, inodeLimit int64) error {
+		inodeLimits[path] = inodeLimit
+		return nil
+	}
+	vl.kubeClient = fakekube.NewSimpleClientset(&corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: testVolumeName},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					VolumeAttributes: map[string]string{utils.InodeLimitParameter: "1000"},
+				},
+			},
+		},
+	})
+	vl.directcsiClient = fakedirect.NewSimpleClientset(testObjects...)
+	directCSIClient := vl.directcsiClient.DirectV1beta2()
+
+	vObj := testObjects[2].(*directcsi.DirectCSIVolume)
+	if err := vl.Update(ctx, vObj, vObj.DeepCopy()); err != nil {
+		t.Fatalf("Error while invoking the volume update listener: %+v", err)
+	}
+
+	targetPath := filepath.Join(targetMountpoint, testVolumeName)
+	if dest, ok := copied[hostPath]; !ok || dest != targetPath {
+		t.Errorf("Volume data not copied from %s to %s. Copied: %v", hostPath, targetPath, copied)
+	}
+	if _, err := os.Stat(hostPath); !os.IsNotExist(err) {
+		t.Errorf("Volume directory %s is not removed: %v", hostPath, err)
+	}
+	if inodeLimit := inodeLimits[targetPath]; inodeLimit != 1000 {
+		t.Errorf("Unexpected inode limit of the migrated volume. Expected: 1000, Got: %d", inodeLimit)
+	}
+	if path, ok := fakeRemover.removed[testVolumeName]; !ok || path != sourceMountpoint {
+		t.Errorf("Quota not removed for %s at %s. Removed quotas: %v", testVolumeName, sourceMountpoint, fakeRemover.removed)
+	}
+
+	volObj, vErr := directCSIClient.DirectCSIVolumes().Get(ctx, testVolumeName, metav1.GetOptions{
+		TypeMeta: utils.DirectCSIVolumeTypeMeta(),
+	})
+	if vErr != nil {
+		t.Fatalf("Error while getting the volume object: %+v", vErr)
+	}
+	if volObj.Status.Drive != "target" || volObj.Status.HostPath != targetPath {
+		t.Errorf("Volume not moved. Drive: %s, HostPath: %s", volObj.Status.Drive, volObj.Status.HostPath)
+	}
+	if _, ok := volObj.GetAnnotations()[directcsi.DirectCSIVolumeAnnotationMigrateTo]; ok {
+		t.Errorf("Migration annotation not removed: %v", volObj.GetAnnotations())
+	}
+	if label := volObj.GetLabels()[directcsi.Group+"/drive-path"]; label != "sdc" {
+		t.Errorf("Unexpected drive-path label. Expected: sdc, Got: %s", label)
+	}
+
+	sourceObj, dErr := directCSIClient.DirectCSIDrives().Get(ctx, "source", metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if dErr != nil {
+		t.Fatalf("Error while getting the drive object: %+v", dErr)
+	}
+	if sourceObj.Status.FreeCapacity != mb50 || sourceObj.Status.DriveStatus != directcsi.DriveStatusReady {
+		t.Errorf("Source drive not released. Free capacity: %d, Status: %s", sourceObj.Status.FreeCapacity, sourceObj.Status.DriveStatus)
+	}
+
+	targetObj, dErr := directCSIClient.DirectCSIDrives().Get(ctx, "target", metav1.GetOptions{
+		TypeMeta: utils.DirectCSIDriveTypeMeta(),
+	})
+	if dErr != nil {
+		t.Fatalf("Error while getting the drive object: %+v", dErr)
+	}
+	if targetObj.Status.FreeCapacity != mb30 || targetObj.Status.AllocatedCapacity != mb20 {
+		t.Errorf("Target drive not reserved. Free capacity: %d, Allocated capacity: %d", targetObj.Status.FreeCapacity, targetObj.Status.AllocatedCapacity)
+	}
+	if targetObj.Status.DriveStatus != directcsi.DriveStatusInUse {
+		t.Errorf("Unexpected target drive status. Expected: %s, Got: %s", directcsi.DriveStatusInUse, targetObj.Status.DriveStatus)
+	}
+}